@@ -0,0 +1,97 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// decryptCmd represents the decrypt command
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt PATTERN [VALUE...]",
+	Short: "Recover original values from a format preserving encryption pattern",
+	Long: `Decrypt values anonymized by a format preserving encryption (fpe)
+pattern, printing the original values one per line. Values are read from
+the arguments, or, if there are none, one per line from standard input.
+The pattern's key must be available, as it is when anonymizing.
+
+Only fpe patterns can be decrypted; the values of all other patterns
+cannot be recovered.
+
+Example:
+  pgedge-anonymizer decrypt ACCOUNT_FPE 8901801106
+  pgedge-anonymizer decrypt ACCOUNT_FPE --patterns my-patterns.yaml < ids.txt`,
+
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return decrypt(args[0], args[1:])
+	},
+}
+
+var decryptPatternPath string
+
+func init() {
+	rootCmd.AddCommand(decryptCmd)
+
+	decryptCmd.Flags().StringVar(&decryptPatternPath, "patterns", "",
+		"Path to user patterns file (overrides config)")
+}
+
+func decrypt(name string, values []string) error {
+	mgr, _, err := loadPatternManager(decryptPatternPath)
+	if err != nil {
+		return err
+	}
+	defer mgr.Close()
+
+	gen, ok := mgr.Get(name)
+	if !ok {
+		return fmt.Errorf("unknown pattern %q (see pgedge-anonymizer "+
+			"patterns list)", name)
+	}
+	rev, ok := gen.(generator.ReversibleGenerator)
+	if !ok {
+		return fmt.Errorf("pattern %s cannot be decrypted; only fpe "+
+			"patterns are reversible", name)
+	}
+
+	if len(values) > 0 {
+		for _, value := range values {
+			if err := printDecrypted(rev, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if err := printDecrypted(rev, scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func printDecrypted(rev generator.ReversibleGenerator, value string) error {
+	original, err := rev.Reverse(value)
+	if err != nil {
+		return err
+	}
+	fmt.Println(original)
+	return nil
+}
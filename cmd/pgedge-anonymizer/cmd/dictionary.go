@@ -0,0 +1,269 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/sealed"
+)
+
+// dictionaryCmd represents the dictionary command
+var dictionaryCmd = &cobra.Command{
+	Use:   "dictionary",
+	Short: "Export and import the mappings of a kept dictionary",
+	Long: `Export the value mappings of the dictionary kept by dictionary.path to
+a portable file of JSON lines, or import such a file into it, so that
+mappings can be reviewed, reused to anonymize another environment
+consistently, or archived and destroyed on a schedule.
+
+Files whose names end in .gz (or .gz.enc) are compressed. With --key-env
+or --key-file, the export is encrypted with AES-256-GCM using a 256-bit
+key in hexadecimal; importing an encrypted file requires the same key.`,
+}
+
+// dictionaryExportCmd represents the dictionary export command
+var dictionaryExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a kept dictionary's mappings to a file",
+	Long: `Write every value mapping and profile identity of the kept dictionary
+to a file of JSON lines, one record per line.
+
+Example:
+  pgedge-anonymizer dictionary export --out mappings.jsonl.gz
+  pgedge-anonymizer dictionary export --out mappings.jsonl.gz.enc --key-env DICT_KEY`,
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportDictionary()
+	},
+}
+
+// dictionaryImportCmd represents the dictionary import command
+var dictionaryImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import mappings into a kept dictionary",
+	Long: `Add the mappings and identities of an exported file to the kept
+dictionary, creating it if it does not exist, and replacing any mappings
+of the same values. Compressed and encrypted files are recognized by
+their contents.
+
+Example:
+  pgedge-anonymizer dictionary import --in mappings.jsonl.gz
+  pgedge-anonymizer dictionary import --in mappings.jsonl.gz.enc --key-file dict.key`,
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importDictionary()
+	},
+}
+
+var (
+	dictionaryPath    string
+	dictionaryOut     string
+	dictionaryIn      string
+	dictionaryKeyEnv  string
+	dictionaryKeyFile string
+)
+
+func init() {
+	rootCmd.AddCommand(dictionaryCmd)
+	dictionaryCmd.AddCommand(dictionaryExportCmd, dictionaryImportCmd)
+
+	dictionaryCmd.PersistentFlags().StringVar(&dictionaryPath, "dictionary",
+		"", "Path to the dictionary file (overrides dictionary.path)")
+	dictionaryCmd.PersistentFlags().StringVar(&dictionaryKeyEnv, "key-env",
+		"", "Environment variable holding the hexadecimal encryption key")
+	dictionaryCmd.PersistentFlags().StringVar(&dictionaryKeyFile, "key-file",
+		"", "File holding the hexadecimal encryption key")
+	dictionaryCmd.MarkFlagsMutuallyExclusive("key-env", "key-file")
+
+	dictionaryExportCmd.Flags().StringVar(&dictionaryOut, "out", "",
+		"File to export the mappings to")
+	_ = dictionaryExportCmd.MarkFlagRequired("out")
+	dictionaryImportCmd.Flags().StringVar(&dictionaryIn, "in", "",
+		"File to import the mappings from")
+	_ = dictionaryImportCmd.MarkFlagRequired("in")
+}
+
+// keptDictionaryPath returns the path of the dictionary to export or
+// import: the --dictionary flag, or the configuration's dictionary.path.
+func keptDictionaryPath() (string, error) {
+	if dictionaryPath != "" {
+		return dictionaryPath, nil
+	}
+	if configLoadErr == nil {
+		cfg, err := config.LoadFromViper()
+		if err != nil {
+			return "", err
+		}
+		if cfg.Dictionary.Path != "" {
+			return cfg.Dictionary.Path, nil
+		}
+	}
+	return "", fmt.Errorf("no dictionary is kept; set dictionary.path in " +
+		"the configuration, or give --dictionary")
+}
+
+// dictionaryKey returns the encryption key given by the flags, or nil if
+// neither is set.
+func dictionaryKey() ([]byte, error) {
+	if dictionaryKeyEnv == "" && dictionaryKeyFile == "" {
+		return nil, nil
+	}
+	return sealed.ReadKey(dictionaryKeyEnv, dictionaryKeyFile)
+}
+
+func exportDictionary() error {
+	path, err := keptDictionaryPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to open dictionary: %w", err)
+	}
+	key, err := dictionaryKey()
+	if err != nil {
+		return err
+	}
+
+	dict, err := anonymizer.NewDictionaryWithOptions(
+		anonymizer.DictionaryOptions{Path: path})
+	if err != nil {
+		return fmt.Errorf("failed to open dictionary: %w", err)
+	}
+	defer dict.Close()
+
+	// The mappings identify the original values, so the file is readable
+	// by its owner only
+	f, err := os.OpenFile(dictionaryOut, os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
+		0600)
+	if err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+
+	// Data is compressed, then encrypted; the writers are closed in
+	// reverse order
+	var w io.Writer = f
+	var closers []io.Closer
+	if key != nil {
+		sw, err := sealed.NewWriter(w, key)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		w = sw
+		closers = append(closers, sw)
+	}
+	if strings.HasSuffix(strings.TrimSuffix(dictionaryOut, ".enc"), ".gz") {
+		zw := gzip.NewWriter(w)
+		w = zw
+		closers = append(closers, zw)
+	}
+
+	count, err := dict.Export(w)
+	for i := len(closers) - 1; i >= 0; i-- {
+		if closeErr := closers[i].Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dictionaryOut)
+		return fmt.Errorf("failed to export dictionary: %w", err)
+	}
+
+	if !quiet {
+		fmt.Printf("Exported %d records to %s\n", count, dictionaryOut)
+	}
+	return nil
+}
+
+func importDictionary() error {
+	path, err := keptDictionaryPath()
+	if err != nil {
+		return err
+	}
+	key, err := dictionaryKey()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(dictionaryIn)
+	if err != nil {
+		return fmt.Errorf("failed to open import: %w", err)
+	}
+	defer f.Close()
+
+	r, err := openExport(bufio.NewReader(f), key)
+	if err != nil {
+		return fmt.Errorf("failed to open import: %w", err)
+	}
+
+	dict, err := anonymizer.NewDictionaryWithOptions(
+		anonymizer.DictionaryOptions{Path: path})
+	if err != nil {
+		return fmt.Errorf("failed to open dictionary: %w", err)
+	}
+	defer dict.Close()
+
+	count, err := dict.Import(r)
+	if err != nil {
+		return fmt.Errorf("failed to import dictionary: %w", err)
+	}
+
+	if !quiet {
+		fmt.Printf("Imported %d records into %s\n", count, path)
+	}
+	return nil
+}
+
+// gzipMagic begins every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// openExport returns a reader of the JSON lines of an export, decrypting
+// and decompressing it as its contents show it needs.
+func openExport(br *bufio.Reader, key []byte) (io.Reader, error) {
+	var r io.Reader = br
+	if header, _ := br.Peek(sealed.HeaderSize); sealed.IsSealed(header) {
+		if key == nil {
+			return nil, fmt.Errorf("the file is encrypted; give --key-env " +
+				"or --key-file")
+		}
+		sr, err := sealed.NewReader(br, key)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(sr)
+		r = br
+	}
+
+	if header, _ := br.Peek(len(gzipMagic)); bytes.Equal(header, gzipMagic) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		r = zr
+	}
+	return r, nil
+}
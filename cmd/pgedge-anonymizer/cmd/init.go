@@ -0,0 +1,145 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/scaffold"
+)
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate a starter configuration from a database",
+	Long: `Connect to a database, list the columns of its tables, and write a
+starter configuration file with the connection settings and a
+commented-out entry for each column whose name suggests it holds
+personal data, with a suggested pattern. Review the entries, uncomment
+those to anonymize, and check their patterns before running.
+
+Connection settings not given with flags are taken from the libpq
+environment variables, such as PGHOST and PGUSER. The password is never
+written to the file.
+
+Example:
+  pgedge-anonymizer init --database mydb
+  pgedge-anonymizer init --database mydb --schema app --output config.yaml`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInit()
+	},
+}
+
+var (
+	initDB     config.DatabaseConfig
+	initSchema string
+	initOutput string
+	initForce  bool
+)
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringVar(&initDB.URL, "dburl", "",
+		"PostgreSQL connection URI (written without its password)")
+	initCmd.Flags().StringVar(&initDB.Service, "service", "",
+		"Service in the libpq service file")
+	initCmd.Flags().StringVar(&initDB.Host, "host", "",
+		"PostgreSQL host")
+	initCmd.Flags().IntVar(&initDB.Port, "port", 0,
+		"PostgreSQL port")
+	initCmd.Flags().StringVar(&initDB.Database, "database", "",
+		"Database name")
+	initCmd.Flags().StringVar(&initDB.User, "user", "",
+		"Database user")
+	initCmd.Flags().StringVar(&initDB.Password, "password", "",
+		"Database password (not written to the configuration)")
+	initCmd.Flags().StringVar(&initDB.SSLMode, "sslmode", "",
+		"SSL mode")
+	initCmd.Flags().StringVar(&initSchema, "schema", "*",
+		"List only the tables in schemas matching this pattern")
+	initCmd.Flags().StringVarP(&initOutput, "output", "o", "",
+		"Write the configuration to this file rather than standard output")
+	initCmd.Flags().BoolVar(&initForce, "force", false,
+		"Overwrite the output file if it exists")
+}
+
+func runInit() error {
+	ctx := context.Background()
+
+	connector := database.NewConnector(&initDB)
+	if err := connector.Connect(ctx); err != nil {
+		return err
+	}
+	defer connector.Close()
+
+	validator := database.NewSchemaValidator(connector.DB())
+	columns, err := validator.ListColumns(ctx, initSchema)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("no tables found in schemas matching %q", initSchema)
+	}
+
+	// Only suggest patterns that are available
+	mgr, _, err := loadPatternManager("")
+	if err != nil {
+		return err
+	}
+	defer mgr.Close()
+
+	suggested := 0
+	suggest := func(tc database.TableColumn) string {
+		name := scaffold.Suggest(tc.Column.Column, tc.DataType)
+		if _, ok := mgr.Get(name); !ok {
+			return ""
+		}
+		suggested++
+		return name
+	}
+
+	var w io.Writer = os.Stdout
+	if initOutput != "" {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+		if initForce {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		}
+		f, err := os.OpenFile(initOutput, flags, 0600)
+		if os.IsExist(err) {
+			return fmt.Errorf("%s already exists; use --force to overwrite it",
+				initOutput)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create configuration file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := scaffold.Write(w, initDB, columns, suggest); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+
+	if initOutput != "" && !quiet {
+		fmt.Printf("Wrote %s with %d suggested column entries out of %d columns\n",
+			initOutput, suggested, len(columns))
+	}
+	return nil
+}
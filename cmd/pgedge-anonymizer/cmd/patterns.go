@@ -0,0 +1,174 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+	"github.com/pgedge/pgedge-anonymizer/internal/pattern"
+)
+
+// patternSamples is the number of sample values shown for each pattern.
+const patternSamples = 3
+
+// patternsCmd represents the patterns command
+var patternsCmd = &cobra.Command{
+	Use:   "patterns",
+	Short: "Inspect the available patterns",
+}
+
+// patternsListCmd represents the patterns list command
+var patternsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the available patterns with sample values",
+	Long: `List the built-in generators and the patterns loaded from pattern
+files and plugins, with the category and description of each, and sample
+values it generates. Patterns are loaded as configured in the
+configuration file, if one is found, and otherwise from the default
+patterns. No samples are shown for exec patterns, as generating them runs
+an external command.
+
+Example:
+  pgedge-anonymizer patterns list
+  pgedge-anonymizer patterns list --filter PHONE
+  pgedge-anonymizer patterns list --filter UK_`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listPatterns()
+	},
+}
+
+var patternsFilter string
+
+func init() {
+	rootCmd.AddCommand(patternsCmd)
+	patternsCmd.AddCommand(patternsListCmd)
+
+	patternsListCmd.Flags().StringVar(&patternsFilter, "filter", "",
+		"List only patterns whose names start with this prefix, or whose "+
+			"category matches it")
+}
+
+func listPatterns() error {
+	mgr, registry, err := loadPatternManager("")
+	if err != nil {
+		return err
+	}
+	defer mgr.Close()
+
+	names := mgr.List()
+	sort.Strings(names)
+
+	listed := 0
+	for _, name := range names {
+		gen, _ := mgr.Get(name)
+		info := generator.Describe(gen)
+		if !matchesPatternFilter(info, patternsFilter) {
+			continue
+		}
+
+		// Pattern file notes describe built-in generators
+		input := ""
+		if p, ok := registry.Get(name); ok {
+			if p.Note != "" {
+				info.Description = p.Note
+			}
+			input = p.Replacement
+		}
+
+		fmt.Printf("%s [%s]\n", info.Name, info.Category)
+		if info.Description != "" {
+			fmt.Printf("  %s\n", info.Description)
+		}
+		// Generating exec samples would run the command, and fpe and
+		// hash patterns need their key
+		if info.Category != generator.CategoryExec &&
+			info.Category != generator.CategoryFPE &&
+			info.Category != generator.CategoryHash {
+			samples := make([]string, patternSamples)
+			for i := range samples {
+				samples[i] = fmt.Sprintf("%q", gen.Generate(input))
+			}
+			fmt.Printf("  Examples: %s\n", strings.Join(samples, ", "))
+		}
+		fmt.Println()
+		listed++
+	}
+
+	if listed == 0 && patternsFilter != "" {
+		return fmt.Errorf("no patterns match %q", patternsFilter)
+	}
+	if err := mgr.Err(); err != nil {
+		return fmt.Errorf("failed to generate samples: %w", err)
+	}
+	return nil
+}
+
+// matchesPatternFilter returns true if the pattern's name starts with the
+// filter, or its category is the filter, ignoring case.
+func matchesPatternFilter(info generator.Info, filter string) bool {
+	return filter == "" ||
+		strings.HasPrefix(strings.ToUpper(info.Name), strings.ToUpper(filter)) ||
+		strings.EqualFold(info.Category, filter)
+}
+
+// loadPatternManager loads the patterns and plugins as set in the
+// configuration file, if one was found, or the default patterns, and
+// returns a manager with a generator registered for each. A userPath
+// overrides the user patterns file of the configuration. The caller must
+// close the manager.
+func loadPatternManager(userPath string) (*generator.Manager,
+	*pattern.Registry, error) {
+	// The configuration is optional; without one, the defaults are loaded
+	cfg := &config.Config{}
+	if configLoadErr == nil {
+		loaded, err := config.LoadFromViper()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		cfg = loaded
+	}
+	if userPath != "" {
+		cfg.Patterns.UserPath = userPath
+	}
+
+	registry, err := pattern.LoadPatterns(
+		config.FindDefaultPatternsFile(cfg.Patterns.DefaultPath),
+		cfg.Patterns.UserPath,
+		cfg.Patterns.DisableDefaults,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load patterns: %w", err)
+	}
+
+	plugins, err := generator.LoadPlugins(cfg.Patterns.Plugins)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load plugins: %w", err)
+	}
+
+	mgr := generator.NewManager()
+	if err := anonymizer.RegisterPatternGenerators(mgr, registry); err != nil {
+		mgr.Close()
+		return nil, nil, fmt.Errorf("failed to register patterns: %w", err)
+	}
+	for _, gen := range plugins {
+		mgr.Register(gen)
+	}
+
+	return mgr, registry, nil
+}
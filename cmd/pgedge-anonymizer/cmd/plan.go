@@ -0,0 +1,130 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+	"github.com/pgedge/pgedge-anonymizer/internal/pattern"
+	"github.com/pgedge/pgedge-anonymizer/internal/plan"
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show what a run would do without changing the database",
+	Long: `Connect to the database in read-only sessions and print the plan of the
+run the configuration describes: the columns in processing order, those
+the run would skip, such as CASCADE targets, and for each column its data
+type, whether a unique constraint covers it, how its values are replaced,
+the strategy its rows are read with, the transaction it is committed in,
+and the planner's estimate of its rows. With --analyze-columns, or
+analyze_columns set, the distinct values of each column anonymized with
+a pattern are estimated, and the strategy and batch size chosen from
+them are shown.
+
+The duration is estimated from each column's expected_rows_per_second, or
+its rate over earlier runs when throughput.learn is set, and otherwise
+from an assumed rate, so treat it as a rough guide.
+
+Example:
+  pgedge-anonymizer plan
+  pgedge-anonymizer plan --config myconfig.yaml --report-format json
+  pgedge-anonymizer plan --analyze-columns`,
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPlan()
+	},
+}
+
+var (
+	planFormat         string
+	planAnalyzeColumns bool
+)
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+
+	planCmd.Flags().StringVar(&planFormat, "report-format", plan.FormatText,
+		"Plan format: text or json")
+	planCmd.Flags().BoolVar(&planAnalyzeColumns, "analyze-columns", false,
+		"Estimate each column's distinct values to choose its strategy and batch size")
+}
+
+func runPlan() error {
+	// Check that a config file was loaded
+	if err := CheckConfigLoaded(); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFromViper()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if planAnalyzeColumns {
+		cfg.ApplyOverrides(config.CLIOverrides{
+			AnalyzeColumns: &planAnalyzeColumns,
+		})
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if planFormat != plan.FormatText && planFormat != plan.FormatJSON {
+		return fmt.Errorf("invalid report format %q: must be text or json",
+			planFormat)
+	}
+
+	registry, err := pattern.LoadPatterns(
+		config.FindDefaultPatternsFile(cfg.Patterns.DefaultPath),
+		cfg.Patterns.UserPath,
+		cfg.Patterns.DisableDefaults,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to load patterns: %w", err)
+	}
+	plugins, err := generator.LoadPlugins(cfg.Patterns.Plugins)
+	if err != nil {
+		return fmt.Errorf("failed to load plugins: %w", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt,
+		syscall.SIGTERM)
+	defer cancel()
+
+	var plans []*plan.Plan
+	for _, target := range cfg.ResolveTargets() {
+		p, err := anonymizer.Plan(ctx, anonymizer.Options{
+			Config:     cfg.ForTarget(target),
+			Patterns:   registry,
+			Quiet:      quiet,
+			Generators: plugins,
+		})
+		if err != nil {
+			if cfg.HasTargets() {
+				return fmt.Errorf("target %s: %w", target.Name, err)
+			}
+			return fmt.Errorf("planning failed: %w", err)
+		}
+		plans = append(plans, p)
+	}
+
+	return plan.Write(os.Stdout, plans, planFormat)
+}
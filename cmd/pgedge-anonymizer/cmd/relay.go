@@ -0,0 +1,157 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+	"github.com/pgedge/pgedge-anonymizer/internal/pattern"
+	"github.com/pgedge/pgedge-anonymizer/internal/relay"
+)
+
+// relayCmd represents the relay command
+var relayCmd = &cobra.Command{
+	Use:   "relay",
+	Short: "Keep an anonymized copy up to date from a replication slot",
+	Long: `Read the changes made to the database from the logical replication
+slot in the configuration's relay section, anonymize the configured
+columns of the changed rows, and apply the changes to the database in
+copy_to, keeping an anonymized copy made by the run command up to date.
+
+Create the slot with --create-slot before making the copy, so that no
+change made in between is missed. The relay then runs until interrupted,
+applying each transaction once it is committed; with --once, it applies
+the changes waiting in the slot and exits.
+
+Example:
+  pgedge-anonymizer relay --config staging.yaml --create-slot
+  pgedge-anonymizer run --config staging.yaml
+  pgedge-anonymizer relay --config staging.yaml`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRelay()
+	},
+}
+
+var (
+	relayCreateSlot bool
+	relayOnce       bool
+)
+
+func init() {
+	rootCmd.AddCommand(relayCmd)
+
+	relayCmd.Flags().BoolVar(&relayCreateSlot, "create-slot", false,
+		"Create the replication slot and exit")
+	relayCmd.Flags().BoolVar(&relayOnce, "once", false,
+		"Apply the changes waiting in the slot and exit")
+}
+
+func runRelay() error {
+	if err := CheckConfigLoaded(); err != nil {
+		return err
+	}
+	cfg, err := config.LoadFromViper()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Relay == nil {
+		return fmt.Errorf("the configuration has no relay section")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	mgr, err := relayGenerators(cfg)
+	if err != nil {
+		return err
+	}
+	defer mgr.Close()
+
+	dict, err := anonymizer.NewDictionary(anonymizer.DefaultCacheSize)
+	if err != nil {
+		return fmt.Errorf("failed to create dictionary: %w", err)
+	}
+	defer dict.Close()
+
+	r := relay.New(relay.Options{
+		Config:     cfg,
+		Generators: mgr,
+		Dictionary: dict,
+		Once:       relayOnce,
+		Quiet:      quiet,
+	})
+	defer r.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt,
+		syscall.SIGTERM)
+	defer cancel()
+
+	if relayCreateSlot {
+		if err := r.CreateSlot(ctx); err != nil {
+			return err
+		}
+		if !quiet {
+			fmt.Printf("Created replication slot %s\n", cfg.Relay.Slot)
+		}
+		return nil
+	}
+
+	if err := r.Run(ctx); err != nil {
+		return err
+	}
+	if !quiet {
+		stats := r.Stats()
+		fmt.Printf("Relayed %d changes in %d transactions\n", stats.Changes,
+			stats.Transactions)
+	}
+	return nil
+}
+
+// relayGenerators returns the generators of the configuration's patterns,
+// set up as a run sets them up.
+func relayGenerators(cfg *config.Config) (*generator.Manager, error) {
+	registry, err := pattern.LoadPatterns(
+		config.FindDefaultPatternsFile(cfg.Patterns.DefaultPath),
+		cfg.Patterns.UserPath,
+		cfg.Patterns.DisableDefaults,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load patterns: %w", err)
+	}
+	plugins, err := generator.LoadPlugins(cfg.Patterns.Plugins)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugins: %w", err)
+	}
+
+	mgr := generator.NewManager()
+	if err := mgr.SetLocale(cfg.Locale.Weights()); err != nil {
+		mgr.Close()
+		return nil, fmt.Errorf("invalid locale: %w", err)
+	}
+	if err := anonymizer.RegisterPatternGenerators(mgr, registry); err != nil {
+		mgr.Close()
+		return nil, fmt.Errorf("failed to register patterns: %w", err)
+	}
+	for _, gen := range plugins {
+		mgr.Register(gen)
+	}
+	return mgr, nil
+}
@@ -2,6 +2,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +10,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	anonerrors "github.com/pgedge/pgedge-anonymizer/internal/errors"
 	"github.com/pgedge/pgedge-anonymizer/internal/version"
 )
 
@@ -31,17 +35,82 @@ pattern-based anonymization while maintaining data consistency across tables.`,
 	SilenceUsage: true,
 }
 
+// Exit statuses of the process, distinct for each kind of failure so that
+// schedulers can tell a run to retry from one to fix.
+const (
+	ExitFailure = 1 // The command failed
+	ExitConfig  = 2 // The configuration or patterns are invalid
+
+	// ExitMaxDuration means a run reached its max_duration and stopped,
+	// with the tables committed before then recorded in its checkpoint.
+	ExitMaxDuration = 3
+
+	// ExitValidation means the database does not match the
+	// configuration, such as a configured column it does not have.
+	ExitValidation = 4
+
+	// ExitDatabase means connecting to or querying a database failed.
+	ExitDatabase = 5
+)
+
+// Kinds of failure, as reported in a run's summary.
+const (
+	FailureError       = "error"
+	FailureConfig      = "config"
+	FailureMaxDuration = "max_duration"
+	FailureValidation  = "validation"
+	FailureDatabase    = "database"
+)
+
+// failureExitCodes are the exit statuses of the kinds of failure.
+var failureExitCodes = map[string]int{
+	FailureError:       ExitFailure,
+	FailureConfig:      ExitConfig,
+	FailureMaxDuration: ExitMaxDuration,
+	FailureValidation:  ExitValidation,
+	FailureDatabase:    ExitDatabase,
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() error {
 	return rootCmd.Execute()
 }
 
+// ExitCode returns the status to exit the process with after a command
+// failed with err.
+func ExitCode(err error) int {
+	return failureExitCodes[FailureKind(err)]
+}
+
+// FailureKind returns the kind of failure of err.
+func FailureKind(err error) string {
+	var stopped *anonymizer.MaxDurationError
+	var configErr *anonerrors.ConfigError
+	var patternErr *anonerrors.PatternError
+	var validationErr *anonerrors.ValidationError
+	var databaseErr *anonerrors.DatabaseError
+
+	switch {
+	case errors.As(err, &stopped):
+		return FailureMaxDuration
+	case errors.As(err, &configErr), errors.As(err, &patternErr):
+		return FailureConfig
+	case errors.As(err, &validationErr):
+		return FailureValidation
+	case errors.As(err, &databaseErr):
+		return FailureDatabase
+	default:
+		return FailureError
+	}
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "",
-		"config file (default: ./pgedge-anonymizer.yaml or /etc/pgedge/pgedge-anonymizer.yaml)")
+		"config file (default: pgedge-anonymizer.yaml in the current, "+
+			"configuration or binary directory)")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false,
 		"suppress progress output")
 
@@ -69,13 +138,9 @@ func initConfig() {
 		configName := "pgedge-anonymizer.yaml"
 		searchPaths := []string{"."}
 
-		// Add /etc/pgedge
-		searchPaths = append(searchPaths, "/etc/pgedge")
-
-		// Add directory containing the binary
-		if exe, err := os.Executable(); err == nil {
-			searchPaths = append(searchPaths, filepath.Dir(exe))
-		}
+		// Add the user and system configuration directories, and the
+		// directory containing the binary
+		searchPaths = append(searchPaths, config.SearchDirs()...)
 
 		// Search for config file in each path
 		var foundConfig string
@@ -113,15 +178,17 @@ func CheckConfigLoaded() error {
 	if configLoadErr != nil {
 		if _, ok := configLoadErr.(viper.ConfigFileNotFoundError); ok {
 			if cfgFile != "" {
-				return fmt.Errorf("config file not found: %s", cfgFile)
+				return anonerrors.NewConfigError(cfgFile,
+					"config file not found", configLoadErr)
 			}
-			return fmt.Errorf("no config file found. Create pgedge-anonymizer.yaml or specify one with --config")
+			return anonerrors.NewConfigError("", "no config file found. "+
+				"Create pgedge-anonymizer.yaml or specify one with --config",
+				configLoadErr)
 		}
 		// Include which file caused the error if available
-		if file := viper.ConfigFileUsed(); file != "" {
-			return fmt.Errorf("error reading config file %s: %w", file, configLoadErr)
-		}
-		return fmt.Errorf("error reading config file: %w", configLoadErr)
+		return anonerrors.NewConfigError(viper.ConfigFileUsed(),
+			fmt.Sprintf("error reading config file: %v", configLoadErr),
+			configLoadErr)
 	}
 	return nil
 }
@@ -13,21 +13,25 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
 	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
 	"github.com/pgedge/pgedge-anonymizer/internal/pattern"
 	"github.com/pgedge/pgedge-anonymizer/internal/stats"
 )
 
 var (
 	// Database connection flags
+	dbURL      string
 	dbHost     string
 	dbPort     int
 	dbName     string
@@ -37,6 +41,34 @@ var (
 	// Pattern flags
 	patternsPath string
 	noDefaults   bool
+
+	// Report flags
+	reportFormat string
+	reportFile   string
+	summaryPath  string
+
+	// Trace flags
+	recordPath string
+	replayPath string
+
+	// Batch flags
+	batchSize       int
+	fetchSize       int
+	updateChunkSize int
+
+	// Lock flags
+	lockTimeout      string
+	statementTimeout string
+
+	// Throttle flags
+	maxRowsPerSecond    int
+	sleepBetweenBatches string
+
+	// Time limit flags
+	maxDuration string
+
+	// Analysis flags
+	analyzeColumns bool
 )
 
 // runCmd represents the run command
@@ -47,15 +79,26 @@ var runCmd = &cobra.Command{
 
 This command connects to the PostgreSQL database, validates the configured
 columns exist, analyzes foreign key relationships, and then anonymizes the
-data within a single transaction.
+data within a single transaction, or one for each table as the
+transaction_mode setting selects.
 
 Example:
   pgedge-anonymizer run
   pgedge-anonymizer run --config myconfig.yaml
-  pgedge-anonymizer run --host localhost --database mydb --user admin`,
+  pgedge-anonymizer run --host localhost --database mydb --user admin
+  pgedge-anonymizer run --max-duration 2h
+  pgedge-anonymizer run --record run.trace
+  pgedge-anonymizer run --replay run.trace`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runAnonymization()
+		summary := stats.NewSummary(time.Now())
+		err := runAnonymization(summary)
+		if summaryPath != "" {
+			if writeErr := writeSummary(summary, err); err == nil {
+				err = writeErr
+			}
+		}
+		return err
 	},
 }
 
@@ -63,6 +106,8 @@ func init() {
 	rootCmd.AddCommand(runCmd)
 
 	// Database flags
+	runCmd.Flags().StringVar(&dbURL, "dburl", "",
+		"PostgreSQL connection URI, such as postgres://user@host/db (overrides config)")
 	runCmd.Flags().StringVar(&dbHost, "host", "",
 		"PostgreSQL host (overrides config)")
 	runCmd.Flags().IntVar(&dbPort, "port", 0,
@@ -80,7 +125,51 @@ func init() {
 	runCmd.Flags().BoolVar(&noDefaults, "no-defaults", false,
 		"Disable default patterns")
 
+	// Report flags
+	runCmd.Flags().StringVar(&reportFormat, "report-format", stats.FormatText,
+		"Statistics report format: text, json, or csv")
+	runCmd.Flags().StringVar(&reportFile, "report-file", "",
+		"Write the statistics report to a file instead of stdout")
+	runCmd.Flags().StringVar(&summaryPath, "summary", "",
+		"Write a JSON summary of the run's outcome to a file, or - for stdout")
+
+	// Trace flags
+	runCmd.Flags().StringVar(&recordPath, "record", "",
+		"Record the generated values to a trace file")
+	runCmd.Flags().StringVar(&replayPath, "replay", "",
+		"Replay the values recorded in a trace file")
+	runCmd.MarkFlagsMutuallyExclusive("record", "replay")
+
+	// Batch flags
+	runCmd.Flags().IntVar(&batchSize, "batch-size", 0,
+		"Rows anonymized per batch (overrides config)")
+	runCmd.Flags().IntVar(&fetchSize, "fetch-size", 0,
+		"Rows read by each FETCH (overrides config)")
+	runCmd.Flags().IntVar(&updateChunkSize, "update-chunk-size", 0,
+		"Most rows written by each update statement (overrides config)")
+
+	// Lock flags
+	runCmd.Flags().StringVar(&lockTimeout, "lock-timeout", "",
+		"Longest wait for a lock, such as 30s, before failing (overrides config)")
+	runCmd.Flags().StringVar(&statementTimeout, "statement-timeout", "",
+		"Longest a statement may take, such as 10m, before failing (overrides config)")
+
+	// Throttle flags
+	runCmd.Flags().IntVar(&maxRowsPerSecond, "max-rows-per-second", 0,
+		"Most rows anonymized per second (overrides config)")
+	runCmd.Flags().StringVar(&sleepBetweenBatches, "sleep-between-batches", "",
+		"Pause after each batch, such as 100ms (overrides config)")
+
+	// Time limit flags
+	runCmd.Flags().StringVar(&maxDuration, "max-duration", "",
+		"Longest the run may take, such as 2h, before stopping at a checkpoint (overrides config)")
+
+	// Analysis flags
+	runCmd.Flags().BoolVar(&analyzeColumns, "analyze-columns", false,
+		"Estimate each column's distinct values to choose its strategy and batch size")
+
 	// Bind flags to viper
+	_ = viper.BindPFlag("database.url", runCmd.Flags().Lookup("dburl"))
 	_ = viper.BindPFlag("database.host", runCmd.Flags().Lookup("host"))
 	_ = viper.BindPFlag("database.port", runCmd.Flags().Lookup("port"))
 	_ = viper.BindPFlag("database.database", runCmd.Flags().Lookup("database"))
@@ -90,7 +179,7 @@ func init() {
 	_ = viper.BindPFlag("patterns.disable_defaults", runCmd.Flags().Lookup("no-defaults"))
 }
 
-func runAnonymization() error {
+func runAnonymization(summary *stats.Summary) error {
 	// Check that a config file was loaded
 	if err := CheckConfigLoaded(); err != nil {
 		return err
@@ -101,9 +190,14 @@ func runAnonymization() error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	summary.Columns = len(cfg.Columns)
+	summary.Targets = len(cfg.Targets)
 
 	// Apply CLI overrides
 	overrides := config.CLIOverrides{}
+	if dbURL != "" {
+		overrides.URL = &dbURL
+	}
 	if dbHost != "" {
 		overrides.Host = &dbHost
 	}
@@ -125,19 +219,45 @@ func runAnonymization() error {
 	if noDefaults {
 		overrides.DisableDefaults = &noDefaults
 	}
+	if batchSize != 0 {
+		overrides.BatchSize = &batchSize
+	}
+	if fetchSize != 0 {
+		overrides.FetchSize = &fetchSize
+	}
+	if updateChunkSize != 0 {
+		overrides.UpdateChunkSize = &updateChunkSize
+	}
+	if lockTimeout != "" {
+		overrides.LockTimeout = &lockTimeout
+	}
+	if statementTimeout != "" {
+		overrides.StatementTimeout = &statementTimeout
+	}
+	if maxRowsPerSecond != 0 {
+		overrides.MaxRowsPerSecond = &maxRowsPerSecond
+	}
+	if sleepBetweenBatches != "" {
+		overrides.SleepBetweenBatches = &sleepBetweenBatches
+	}
+	if maxDuration != "" {
+		overrides.MaxDuration = &maxDuration
+	}
+	if analyzeColumns {
+		overrides.AnalyzeColumns = &analyzeColumns
+	}
 	cfg.ApplyOverrides(overrides)
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
+	if err := stats.ValidateFormat(reportFormat); err != nil {
+		return err
+	}
 
 	// Load patterns
 	defaultPath := config.FindDefaultPatternsFile(cfg.Patterns.DefaultPath)
-	if defaultPath == "" && !cfg.Patterns.DisableDefaults {
-		fmt.Fprintln(os.Stderr,
-			"Warning: default patterns file not found")
-	}
 
 	registry, err := pattern.LoadPatterns(
 		defaultPath,
@@ -148,9 +268,21 @@ func runAnonymization() error {
 		return fmt.Errorf("failed to load patterns: %w", err)
 	}
 
+	// Load generator plugins
+	plugins, err := generator.LoadPlugins(cfg.Patterns.Plugins)
+	if err != nil {
+		return fmt.Errorf("failed to load plugins: %w", err)
+	}
+
 	if !quiet {
 		fmt.Printf("Loaded %d patterns\n", registry.Count())
+		if len(plugins) > 0 {
+			fmt.Printf("Loaded %d plugin generators\n", len(plugins))
+		}
 		fmt.Printf("Processing %d columns\n", len(cfg.Columns))
+		if cfg.HasTargets() {
+			fmt.Printf("Processing %d target databases\n", len(cfg.Targets))
+		}
 	}
 
 	// Setup context with cancellation
@@ -168,23 +300,100 @@ func runAnonymization() error {
 
 	// Create and run anonymizer
 	anon, err := anonymizer.New(anonymizer.Options{
-		Config:   cfg,
-		Patterns: registry,
-		Quiet:    quiet,
+		Config:     cfg,
+		Patterns:   registry,
+		Quiet:      quiet,
+		Generators: plugins,
+		RecordPath: recordPath,
+		ReplayPath: replayPath,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create anonymizer: %w", err)
 	}
 	defer anon.Close()
 
+	if cfg.HasTargets() {
+		results, err := anon.RunTargets(ctx)
+		for _, r := range results {
+			summary.Add(r.Stats)
+		}
+
+		// Report the targets that were committed, even if a later one
+		// failed
+		var reportErr error
+		if len(results) > 0 {
+			reportErr = writeReport(func(r *stats.Reporter, w io.Writer) error {
+				return r.WriteTargets(results, reportFormat, w)
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("anonymization failed after %d of %d targets: %w",
+				len(results), len(cfg.Targets), err)
+		}
+		return reportErr
+	}
+
 	result, err := anon.Run(ctx)
 	if err != nil {
 		return fmt.Errorf("anonymization failed: %w", err)
 	}
+	summary.Add(result)
 
 	// Report results
+	return writeReport(func(r *stats.Reporter, w io.Writer) error {
+		return r.Write(result, reportFormat, w)
+	})
+}
+
+// writeReport writes the statistics report to the report file, or to
+// stdout if none was given.
+func writeReport(write func(r *stats.Reporter, w io.Writer) error) error {
 	reporter := stats.NewReporter()
-	reporter.Report(result, os.Stdout)
 
+	if reportFile == "" {
+		return write(reporter, os.Stdout)
+	}
+
+	f, err := os.Create(reportFile)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := write(reporter, f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// writeSummary records the error the run ended with, if any, in its
+// summary, and writes the summary to the summary file, or to stdout for
+// "-".
+func writeSummary(summary *stats.Summary, runErr error) error {
+	if runErr != nil {
+		status := stats.StatusFailed
+		if ExitCode(runErr) == ExitMaxDuration {
+			status = stats.StatusStopped
+		}
+		summary.Fail(runErr, FailureKind(runErr), ExitCode(runErr), status)
+	}
+
+	if summaryPath == "-" {
+		return summary.Write(os.Stdout, time.Now())
+	}
+
+	f, err := os.Create(summaryPath)
+	if err != nil {
+		return fmt.Errorf("failed to create summary file: %w", err)
+	}
+	if err := summary.Write(f, time.Now()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
 	return nil
 }
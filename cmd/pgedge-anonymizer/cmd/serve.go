@@ -0,0 +1,145 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/server"
+)
+
+// defaultServeAddress is the Unix socket the server listens on by default.
+const defaultServeAddress = "/tmp/pgedge-anonymizer.sock"
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the generators to SQL functions in the database",
+	Long: `Serve the pattern generators over HTTP on a Unix socket or TCP
+address, so that they can be called from within the database by the
+functions that the "serve sql" command creates, such as
+anon_generate('EMAIL', email). Equal original values are mapped to the
+same anonymized value for as long as the server runs.
+
+Patterns are loaded as configured in the configuration file, if one is
+found, and otherwise from the default patterns. The server runs until it
+is interrupted. The server does not authenticate its clients, so it only
+listens on a loopback TCP address, such as localhost:8765, unless
+--allow-remote is given.
+
+Example:
+  pgedge-anonymizer serve
+  pgedge-anonymizer serve --listen /var/run/postgresql/anonymizer.sock
+  pgedge-anonymizer serve --listen localhost:8765
+  pgedge-anonymizer serve --listen 10.0.0.5:8765 --allow-remote`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServer()
+	},
+}
+
+// serveSQLCmd represents the serve sql command
+var serveSQLCmd = &cobra.Command{
+	Use:   "sql",
+	Short: "Print SQL creating functions that call the server",
+	Long: `Print SQL that creates the anon_generate(pattern, original) and
+anon_patterns() functions, which call a server started with the serve
+command at the same address. The functions are written in PL/Python, so
+the plpython3u language must be available, and the SQL must be run by a
+superuser.
+
+Example:
+  pgedge-anonymizer serve sql | psql -d mydb
+  pgedge-anonymizer serve sql --listen localhost:8765 --schema anon`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sql, err := server.ExtensionSQL(serveAddress, serveSchema)
+		if err != nil {
+			return err
+		}
+		fmt.Print(sql)
+		return nil
+	},
+}
+
+var (
+	serveAddress     string
+	serveSchema      string
+	serveAllowRemote bool
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.AddCommand(serveSQLCmd)
+
+	serveCmd.PersistentFlags().StringVar(&serveAddress, "listen",
+		defaultServeAddress, "Unix socket path, or TCP host:port, to listen on")
+	serveCmd.Flags().BoolVar(&serveAllowRemote, "allow-remote", false,
+		"Listen on a TCP address other than a loopback address, to which anyone who can reach it may connect")
+	serveSQLCmd.Flags().StringVar(&serveSchema, "schema",
+		server.DefaultSchema, "Schema in which to create the functions")
+}
+
+func runServer() error {
+	mgr, _, err := loadPatternManager("")
+	if err != nil {
+		return err
+	}
+	defer mgr.Close()
+
+	dict, err := anonymizer.NewDictionary(0)
+	if err != nil {
+		return err
+	}
+	defer dict.Close()
+
+	listener, err := server.Listen(serveAddress, serveAllowRemote)
+	if errors.Is(err, server.ErrNotLoopback) {
+		return fmt.Errorf("%w; use --allow-remote to listen on %s anyway",
+			err, serveAddress)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", serveAddress, err)
+	}
+
+	srv := &http.Server{
+		Handler:  server.New(mgr, dict).Handler(),
+		ErrorLog: log.Default(),
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt,
+		syscall.SIGTERM)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	if !quiet {
+		fmt.Printf("Serving patterns on %s\n", serveAddress)
+	}
+	if err := srv.Serve(listener); err != nil &&
+		!errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// testPatternCmd represents the test-pattern command
+var testPatternCmd = &cobra.Command{
+	Use:   "test-pattern PATTERN",
+	Short: "Print values generated by a pattern",
+	Long: `Generate values with a pattern and print them, one per line, to try
+out custom format patterns before running against a database. Patterns
+are loaded as configured in the configuration file, if one is found, and
+otherwise from the default patterns.
+
+The input is the original value passed to the generator, which some
+generators use to shape their output; it defaults to the pattern's
+replacement value.
+
+Example:
+  pgedge-anonymizer test-pattern EMAIL --input "bob@corp.com" --count 10
+  pgedge-anonymizer test-pattern ORDER_REF --patterns my-patterns.yaml`,
+
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return testPattern(cmd, args[0])
+	},
+}
+
+var (
+	testPatternInput string
+	testPatternCount int
+	testPatternPath  string
+)
+
+func init() {
+	rootCmd.AddCommand(testPatternCmd)
+
+	testPatternCmd.Flags().StringVar(&testPatternInput, "input", "",
+		"Original value to anonymize")
+	testPatternCmd.Flags().IntVar(&testPatternCount, "count", 10,
+		"Number of values to generate")
+	testPatternCmd.Flags().StringVar(&testPatternPath, "patterns", "",
+		"Path to user patterns file (overrides config)")
+}
+
+func testPattern(cmd *cobra.Command, name string) error {
+	if testPatternCount < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+
+	mgr, registry, err := loadPatternManager(testPatternPath)
+	if err != nil {
+		return err
+	}
+	defer mgr.Close()
+
+	gen, ok := mgr.Get(name)
+	if !ok {
+		return fmt.Errorf("unknown pattern %q (see pgedge-anonymizer "+
+			"patterns list)", name)
+	}
+
+	input := testPatternInput
+	if !cmd.Flags().Changed("input") {
+		if p, ok := registry.Get(name); ok {
+			input = p.Replacement
+		}
+	}
+
+	for i := 0; i < testPatternCount; i++ {
+		fmt.Println(gen.Generate(input))
+	}
+
+	if err := mgr.Err(); err != nil {
+		return fmt.Errorf("pattern %s failed: %w", name, err)
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
 	"github.com/pgedge/pgedge-anonymizer/internal/config"
 	"github.com/pgedge/pgedge-anonymizer/internal/database"
 	"github.com/pgedge/pgedge-anonymizer/internal/generator"
@@ -37,17 +38,28 @@ This command checks:
   - Column existence in the database
   - Foreign key relationship analysis
 
+With --offline-check, it also fails if the run would depend on any file
+or command other than the configuration file and the database, such as a
+patterns file, plugin, or exec pattern; use this to confirm the binary is
+self-contained before running it in an isolated environment.
+
 Example:
   pgedge-anonymizer validate
-  pgedge-anonymizer validate --config myconfig.yaml`,
+  pgedge-anonymizer validate --config myconfig.yaml
+  pgedge-anonymizer validate --offline-check`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runValidation()
 	},
 }
 
+var offlineCheck bool
+
 func init() {
 	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().BoolVar(&offlineCheck, "offline-check", false,
+		"fail if the run depends on files or commands outside the binary")
 }
 
 func runValidation() error {
@@ -73,8 +85,8 @@ func runValidation() error {
 
 	// Load patterns
 	defaultPath := config.FindDefaultPatternsFile(cfg.Patterns.DefaultPath)
-	if defaultPath == "" && !cfg.Patterns.DisableDefaults {
-		fmt.Println("  Warning: default patterns file not found")
+	if !cfg.Patterns.DisableDefaults {
+		fmt.Printf("  Default patterns: %s\n", defaultPath)
 	}
 
 	registry, err := pattern.LoadPatterns(
@@ -87,21 +99,122 @@ func runValidation() error {
 	}
 	fmt.Printf("  Patterns loaded: %d\n", registry.Count())
 
+	// Check for dependencies outside the binary before loading plugins
+	if offlineCheck {
+		deps := cfg.ExternalDependencies(defaultPath, registry)
+		if len(deps) > 0 {
+			fmt.Println("\n  External dependencies:")
+			for _, dep := range deps {
+				fmt.Printf("    - %s\n", dep)
+			}
+			return fmt.Errorf("offline check failed: %d external dependencies",
+				len(deps))
+		}
+		fmt.Println("  Offline check: OK")
+	}
+
+	// Load generator plugins
+	plugins, err := generator.LoadPlugins(cfg.Patterns.Plugins)
+	if err != nil {
+		return fmt.Errorf("plugin loading error: %w", err)
+	}
+	if len(plugins) > 0 {
+		fmt.Printf("  Plugin generators loaded: %d\n", len(plugins))
+	}
+
 	// Verify all configured patterns exist
 	genMgr := generator.NewManager()
+	if err := genMgr.SetLocale(cfg.Locale.Weights()); err != nil {
+		return fmt.Errorf("invalid locale: %w", err)
+	}
+	for _, gen := range plugins {
+		genMgr.Register(gen)
+	}
 	for _, col := range cfg.Columns {
-		if _, ok := registry.Get(col.Pattern); !ok {
-			// Check if it's a built-in generator
-			if _, ok := genMgr.Get(col.Pattern); !ok {
-				return fmt.Errorf("unknown pattern %q for column %s",
-					col.Pattern, col.Column)
+		for _, name := range col.PatternNames() {
+			if _, ok := registry.Get(name); !ok {
+				// Check if it's a built-in generator
+				if _, ok := genMgr.Get(name); !ok {
+					return fmt.Errorf("unknown pattern %q for column %s",
+						name, col.Column)
+				}
 			}
 		}
 	}
 	fmt.Println("  Pattern references: OK")
 
-	// Test database connection
-	fmt.Println("\nValidating database connection...")
+	// In copy mode, check the database copied from, and validate the one
+	// copied to as the database anonymized
+	if cfg.IsCopyMode() {
+		fmt.Println("\nValidating source database connection...")
+		if err := validateSource(cfg); err != nil {
+			return err
+		}
+		cfg = cfg.ForCopy()
+	}
+
+	// Test each database
+	for _, target := range cfg.ResolveTargets() {
+		if cfg.HasTargets() {
+			fmt.Printf("\nValidating target %s...\n", target.Name)
+		} else {
+			fmt.Println("\nValidating database connection...")
+		}
+		if err := validateDatabase(cfg.ForTarget(target), genMgr); err != nil {
+			if cfg.HasTargets() {
+				return fmt.Errorf("target %s: %w", target.Name, err)
+			}
+			return err
+		}
+	}
+
+	fmt.Println("\nValidation complete. Configuration is valid.")
+	return nil
+}
+
+// validateSource checks that the database copied from in copy mode has
+// the configured columns.
+func validateSource(cfg *config.Config) error {
+	connector := database.NewConnector(&cfg.Database)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := connector.Connect(ctx); err != nil {
+		return fmt.Errorf("source database connection error: %w", err)
+	}
+	defer connector.Close()
+	fmt.Println("  Database connection: OK")
+
+	validator := database.NewSchemaValidator(connector.DB())
+	colConfigs, err := validator.ExpandWildcards(ctx, cfg.Columns)
+	if err != nil {
+		return fmt.Errorf("wildcard expansion error: %w", err)
+	}
+	columns, err := cfg.WithColumns(colConfigs).GetColumnRefs()
+	if err != nil {
+		return fmt.Errorf("column parsing error: %w", err)
+	}
+	missing, err := validator.ValidateColumns(ctx, columns)
+	if err != nil {
+		return fmt.Errorf("column validation error: %w", err)
+	}
+	if len(missing) > 0 {
+		fmt.Println("\n  Missing columns:")
+		for _, col := range missing {
+			fmt.Printf("    - %s\n", col.String())
+		}
+		return fmt.Errorf("%d columns not found in the source database",
+			len(missing))
+	}
+	fmt.Printf("  Column validation: OK (%d columns)\n", len(columns))
+	return nil
+}
+
+// validateDatabase checks that the configured columns exist in the
+// database and reports constraint warnings, foreign keys and the
+// processing order.
+func validateDatabase(cfg *config.Config, genMgr *generator.Manager) error {
 	connector := database.NewConnector(&cfg.Database)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -113,14 +226,56 @@ func runValidation() error {
 	defer connector.Close()
 	fmt.Println("  Database connection: OK")
 
+	// Expand wildcard column entries
+	validator := database.NewSchemaValidator(connector.DB())
+	colConfigs, err := validator.ExpandWildcards(ctx, cfg.Columns)
+	if err != nil {
+		return fmt.Errorf("wildcard expansion error: %w", err)
+	}
+	if len(colConfigs) != len(cfg.Columns) {
+		fmt.Printf("  Wildcard expansion: %d entries -> %d columns\n",
+			len(cfg.Columns), len(colConfigs))
+	}
+	colConfigs, resolved, err := validator.ResolveViewColumns(ctx, colConfigs)
+	if err != nil {
+		return fmt.Errorf("view column error: %w", err)
+	}
+	for _, r := range resolved {
+		fmt.Printf("  View column: %s -> %s\n", r.View.String(),
+			r.Table.String())
+	}
+	colConfigs, err = anonymizer.ResolvePartitions(ctx, validator, cfg,
+		colConfigs)
+	if err != nil {
+		return fmt.Errorf("partition error: %w", err)
+	}
+	colConfigs, propagated, err := anonymizer.PropagateReferences(ctx, cfg,
+		database.NewFKAnalyzer(connector.DB()), colConfigs)
+	if err != nil {
+		return fmt.Errorf("reference propagation error: %w", err)
+	}
+	for _, rc := range propagated {
+		fmt.Printf("  Propagation: %s -> %s (%s)\n", rc.Parent.String(),
+			rc.Child.String(), rc.Via())
+	}
+	inherited, _, err := validator.ExpandInheritance(ctx, colConfigs)
+	if err != nil {
+		return fmt.Errorf("inheritance expansion error: %w", err)
+	}
+	if len(inherited) != len(colConfigs) {
+		fmt.Printf("  Inherited tables: %d columns -> %d columns\n",
+			len(colConfigs), len(inherited))
+	}
+	cfg = cfg.WithColumns(inherited)
+
 	// Validate columns exist
 	columns, err := cfg.GetColumnRefs()
 	if err != nil {
 		return fmt.Errorf("column parsing error: %w", err)
 	}
 
-	validator := database.NewSchemaValidator(connector.DB())
-	missing, err := validator.ValidateColumns(ctx, columns)
+	missing, err := validator.ValidateColumns(ctx,
+		append(cfg.ReferencedColumns(), columns...))
 	if err != nil {
 		return fmt.Errorf("column validation error: %w", err)
 	}
@@ -131,10 +286,69 @@ func runValidation() error {
 		}
 		return fmt.Errorf("%d columns not found in database", len(missing))
 	}
+	if err := anonymizer.CheckColumnTypes(ctx, validator, genMgr,
+		cfg.Columns); err != nil {
+		return err
+	}
+	if err := anonymizer.CheckProfileKeys(ctx, validator,
+		cfg.Columns); err != nil {
+		return err
+	}
+	if err := anonymizer.CheckSubsetTables(columns, cfg.Tables); err != nil {
+		return err
+	}
 	fmt.Printf("  Column validation: OK (%d columns)\n", len(columns))
 
+	coverage, err := anonymizer.MeasureCoverage(ctx, validator, columns)
+	if err != nil {
+		return fmt.Errorf("coverage error: %w", err)
+	}
+	fmt.Printf("  PII coverage: %s\n", coverage)
+	if len(coverage.Uncovered) > 0 {
+		fmt.Println("\n  Likely personal data not configured:")
+		for _, col := range coverage.Uncovered {
+			fmt.Printf("    - %s\n", col.String())
+		}
+		fmt.Println()
+	}
+
+	// Check for constraints anonymized values may violate
+	constraintWarnings := 0
+	for i, ref := range columns {
+		warnings, err := anonymizer.CheckConstraints(ctx, validator, genMgr,
+			ref, cfg.Columns[i])
+		if err != nil {
+			return fmt.Errorf("constraint check error: %w", err)
+		}
+		for _, w := range warnings {
+			if constraintWarnings == 0 {
+				fmt.Println("\n  Constraint warnings:")
+			}
+			fmt.Printf("    - %s: %s\n", ref.String(), w)
+			constraintWarnings++
+		}
+	}
+	if constraintWarnings == 0 {
+		fmt.Println("  Constraint check: OK")
+	}
+
+	// Check for columns whose values the database generates
+	generated, err := anonymizer.CheckGeneratedColumns(ctx, validator,
+		columns)
+	if err != nil {
+		return fmt.Errorf("generated column check error: %w", err)
+	}
+	if len(generated) > 0 {
+		fmt.Printf("\n  Generated columns (will be skipped): %d\n",
+			len(generated))
+		for _, s := range generated {
+			fmt.Printf("    - %s: %s\n", s.Column.String(), s.Warning)
+		}
+	}
+
 	// Analyze foreign keys
 	fkAnalyzer := database.NewFKAnalyzer(connector.DB())
+	fkAnalyzer.SetReferences(cfg.DeclaredReferences())
 	fks, err := fkAnalyzer.Analyze(ctx, columns)
 	if err != nil {
 		return fmt.Errorf("foreign key analysis error: %w", err)
@@ -165,9 +379,14 @@ func runValidation() error {
 				break
 			}
 		}
+		for _, s := range generated {
+			if s.Column.String() == col.String() {
+				skip = fmt.Sprintf(" (%s - will skip)", s.Reason)
+				break
+			}
+		}
 		fmt.Printf("    %d. %s%s\n", i+1, col.String(), skip)
 	}
 
-	fmt.Println("\nValidation complete. Configuration is valid.")
 	return nil
 }
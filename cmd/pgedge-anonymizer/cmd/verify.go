@@ -0,0 +1,162 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/fingerprint"
+	"github.com/pgedge/pgedge-anonymizer/internal/verify"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that no original values remain after a run",
+	Long: `Check the configured columns for values that remain from before
+anonymization, and report whether each column passes.
+
+Values are compared with the fingerprints of the original values that a
+run records when verify.fingerprints is set in the configuration, and
+checked against the regular expressions in verify.pii_patterns. The
+command exits with an error if any column fails.
+
+Example:
+  pgedge-anonymizer verify
+  pgedge-anonymizer verify --fingerprints run.fingerprints --sample 10000
+  pgedge-anonymizer verify --report-format json > verification.json`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runVerification(cmd)
+	},
+}
+
+var (
+	verifyFingerprints string
+	verifySample       int64
+	verifyFormat       string
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVar(&verifyFingerprints, "fingerprints", "",
+		"Fingerprint file recorded by the run (overrides verify.fingerprints)")
+	verifyCmd.Flags().Int64Var(&verifySample, "sample", 0,
+		"Number of rows to check in each column (overrides verify.sample)")
+	verifyCmd.Flags().StringVar(&verifyFormat, "report-format",
+		verify.FormatText, "Report format: text or json")
+}
+
+func runVerification(cmd *cobra.Command) error {
+	// Check that a config file was loaded
+	if err := CheckConfigLoaded(); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFromViper()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if verifyFingerprints != "" {
+		cfg.Verify.Fingerprints = verifyFingerprints
+	}
+	if cmd.Flags().Changed("sample") {
+		cfg.Verify.Sample = verifySample
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if verifyFormat != verify.FormatText && verifyFormat != verify.FormatJSON {
+		return fmt.Errorf("invalid report format %q: must be text or json",
+			verifyFormat)
+	}
+	if cfg.Verify.Fingerprints == "" && len(cfg.Verify.PIIPatterns) == 0 {
+		return fmt.Errorf("nothing to verify: set verify.fingerprints " +
+			"(or --fingerprints) or verify.pii_patterns")
+	}
+
+	opts := verify.Options{
+		Sample:       cfg.Verify.Sample,
+		MaxMatchRate: cfg.Verify.MaxMatchRate,
+		PIIPatterns:  cfg.Verify.PIIPatterns,
+	}
+	if cfg.Verify.Fingerprints != "" {
+		if opts.Fingerprints, err = fingerprint.Load(
+			cfg.Verify.Fingerprints); err != nil {
+			return err
+		}
+	}
+	verifier, err := verify.New(opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt,
+		syscall.SIGTERM)
+	defer cancel()
+
+	// In copy mode, the anonymized rows are in the database copied to
+	if cfg.IsCopyMode() {
+		cfg = cfg.ForCopy()
+	}
+
+	var reports []*verify.Report
+	for _, target := range cfg.ResolveTargets() {
+		report, err := verifyDatabase(ctx, cfg.ForTarget(target), verifier)
+		if err != nil {
+			if cfg.HasTargets() {
+				return fmt.Errorf("target %s: %w", target.Name, err)
+			}
+			return err
+		}
+		reports = append(reports, report)
+	}
+
+	if err := verify.Write(os.Stdout, reports, verifyFormat); err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		if !r.Passed {
+			return fmt.Errorf("verification failed: original values remain " +
+				"in anonymized columns")
+		}
+	}
+	return nil
+}
+
+// verifyDatabase checks the configured columns of a database.
+func verifyDatabase(ctx context.Context, cfg *config.Config,
+	verifier *verify.Verifier) (*verify.Report, error) {
+
+	connector := database.NewConnector(&cfg.Database)
+	if err := connector.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("database connection error: %w", err)
+	}
+	defer connector.Close()
+
+	validator := database.NewSchemaValidator(connector.DB())
+	colConfigs, err := validator.ExpandWildcards(ctx, cfg.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("wildcard expansion error: %w", err)
+	}
+
+	return verifier.Run(ctx, cfg.Database.DatabaseName(), validator, colConfigs)
+}
@@ -0,0 +1,24 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package pgedgeanonymizer embeds files from the top of the source tree
+// that are also shipped alongside the binary, so that it can run without
+// them.
+package pgedgeanonymizer
+
+import (
+	_ "embed"
+)
+
+// DefaultPatterns is the default patterns file,
+// pgedge-anonymizer-patterns.yaml.
+//
+//go:embed pgedge-anonymizer-patterns.yaml
+var DefaultPatterns []byte
@@ -0,0 +1,154 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+const (
+	// analyzeSampleRows is the number of rows sampled to estimate the
+	// distinct values of a column without planner statistics, as many as
+	// ANALYZE samples by default.
+	analyzeSampleRows = 30000
+
+	// analyzeMaxDistinct is the most distinct values an analyzed column
+	// may have for them to be mapped up front, if each is held by at
+	// least analyzeRowsPerValue rows on average, so that mapping them
+	// saves most of the writes.
+	analyzeMaxDistinct  = 100000
+	analyzeRowsPerValue = 10
+
+	// analyzeBatchBytes is the size of the values read in each batch of
+	// an analyzed column, whose batches hold from analyzeMinBatchSize to
+	// analyzeMaxBatchSize rows.
+	analyzeBatchBytes   = 8 << 20
+	analyzeMinBatchSize = 1000
+	analyzeMaxBatchSize = 100000
+)
+
+// columnAnalysis is how the analysis phase chose to process a column,
+// from the estimate of its rows and distinct values.
+type columnAnalysis struct {
+	database.ColumnEstimate
+	rows int64
+
+	// distinctLimit is the most distinct values mapped up front, or zero
+	// to process the column row by row
+	distinctLimit int
+
+	// batchSize is the number of rows read at a time, or zero for the
+	// run's batch size
+	batchSize int
+}
+
+// String describes the estimate and the choices made from it.
+func (c *columnAnalysis) String() string {
+	estimate := fmt.Sprintf("~%d distinct of ~%d rows", c.Distinct, c.rows)
+	if c.Sampled {
+		estimate += " (sampled)"
+	}
+	strategy := "row by row"
+	if c.distinctLimit > 0 {
+		strategy = "distinct values"
+	}
+	if c.batchSize > 0 {
+		strategy += fmt.Sprintf(", batches of %d", c.batchSize)
+	}
+	return estimate + ", " + strategy
+}
+
+// analyzeColumns analyzes the columns a run anonymizes with a single
+// pattern, other than those it skips, and reports the choices made,
+// returning them keyed by column.
+func (a *Anonymizer) analyzeColumns(ctx context.Context,
+	validator *database.SchemaValidator, columns []errors.ColumnRef,
+	skipSet map[string]string,
+	columnConfigMap map[string]config.ColumnConfig) (
+	map[string]*columnAnalysis, error) {
+
+	analysis := make(map[string]*columnAnalysis)
+	for _, col := range columns {
+		if skipSet[col.String()] != "" {
+			continue
+		}
+		c, err := a.analyzeColumn(ctx, validator, col,
+			columnConfigMap[col.String()])
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			continue
+		}
+		analysis[col.String()] = c
+		if !a.quiet {
+			fmt.Printf("Analyzed %s: %s\n", col.String(), c)
+		}
+	}
+	return analysis, nil
+}
+
+// analyzeColumn estimates the rows and distinct values of a column, and
+// chooses to map its values up front if it has few enough distinct
+// values for their number of rows, and the size of its batches from the
+// width of its values, unless a batch size is configured. It returns nil
+// for the columns that are not anonymized with a single pattern by the
+// column processor, and those whose values must be generated row by row:
+// sampled, partitioned or traced columns.
+func (a *Anonymizer) analyzeColumn(ctx context.Context,
+	validator *database.SchemaValidator, col errors.ColumnRef,
+	colConfig config.ColumnConfig) (*columnAnalysis, error) {
+
+	dataType, err := validator.GetColumnDataType(ctx, col)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data type for %s: %w",
+			col.String(), err)
+	}
+	if _, simple := planMethod(colConfig, dataType); !simple ||
+		colConfig.SamplePercent > 0 || colConfig.PartitionBy != "" ||
+		a.recorder != nil || a.replay != nil {
+		return nil, nil
+	}
+
+	c := &columnAnalysis{}
+	if c.rows, err = validator.GetTableRowEstimate(ctx, col.Schema,
+		col.Table); err != nil {
+		return nil, err
+	}
+	if c.ColumnEstimate, err = validator.EstimateColumn(ctx, col, c.rows,
+		analyzeSampleRows); err != nil {
+		return nil, err
+	}
+
+	// The estimate may be low, so more values are allowed for; a column
+	// with more is still processed row by row
+	threshold := a.lowCardinalityThreshold()
+	switch {
+	case threshold < 0:
+		// Values are never mapped up front
+	case c.Distinct <= int64(threshold):
+		c.distinctLimit = threshold
+	case c.Distinct <= analyzeMaxDistinct &&
+		c.rows >= c.Distinct*analyzeRowsPerValue:
+		c.distinctLimit = int(min(2*c.Distinct, analyzeMaxDistinct))
+	}
+
+	if a.batchSize <= 0 && colConfig.BatchSize <= 0 && c.AvgWidth > 0 {
+		c.batchSize = min(max(analyzeBatchBytes/c.AvgWidth,
+			analyzeMinBatchSize), analyzeMaxBatchSize)
+	}
+	return c, nil
+}
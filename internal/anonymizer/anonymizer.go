@@ -14,14 +14,19 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/pgedge/pgedge-anonymizer/internal/checkpoint"
 	"github.com/pgedge/pgedge-anonymizer/internal/config"
 	"github.com/pgedge/pgedge-anonymizer/internal/database"
 	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/fingerprint"
 	"github.com/pgedge/pgedge-anonymizer/internal/generator"
 	"github.com/pgedge/pgedge-anonymizer/internal/pattern"
 	"github.com/pgedge/pgedge-anonymizer/internal/stats"
+	"github.com/pgedge/pgedge-anonymizer/internal/trace"
 )
 
 // Anonymizer orchestrates the complete anonymization process.
@@ -31,7 +36,33 @@ type Anonymizer struct {
 	generators *generator.Manager
 	connector  *database.Connector
 	dictionary *Dictionary
+	dictOpts   DictionaryOptions
+	batchSize  int
+	throttle   *database.Throttle
 	quiet      bool
+	hooks      map[HookPoint][]Hook
+	recorder   *trace.Recorder
+	replay     *trace.Replay
+
+	// source is the database copied from in copy mode, in which the
+	// connector's database is the one copied to
+	source *database.Connector
+
+	fingerprints *fingerprint.Writer
+
+	// onlyTables are the tables, keyed by schema.table, that other
+	// tables inherit from, whose own rows are processed apart from those
+	// of the inheriting tables
+	onlyTables map[string]bool
+
+	// analysis is how the analysis phase chose to process the columns
+	// it analyzed, keyed by column, if analyze_columns is set
+	analysis map[string]*columnAnalysis
+
+	// foreignKeys are the foreign key constraints, keyed by
+	// schema.table.name, dropped while the values they reference are
+	// propagated to their columns
+	foreignKeys map[string]bool
 }
 
 // Options configures the anonymizer.
@@ -39,45 +70,226 @@ type Options struct {
 	Config       *config.Config
 	Patterns     *pattern.Registry
 	Quiet        bool
-	BatchSize    int
-	CacheSize    int
+	BatchSize    int // Overrides the configured batch size if set
+	CacheSize    int // Overrides the configured cache size if set
 	DefaultsPath string
 	UserPath     string
+
+	// Generators are custom generators registered in addition to the
+	// built-in and format-based ones; they take precedence on name clashes.
+	Generators []generator.Generator
+
+	// RecordPath is a trace file to record the generated values to, and
+	// ReplayPath a trace file whose values are replayed.
+	RecordPath string
+	ReplayPath string
 }
 
 // New creates a new anonymizer with the given options.
 func New(opts Options) (*Anonymizer, error) {
-	// Create dictionary
-	dict, err := NewDictionary(opts.CacheSize)
+	// Create dictionary, with the cache size of the options over the
+	// configured one
+	dictOpts := DictionaryOptions{
+		CacheSize:   opts.Config.Dictionary.CacheSize,
+		InMemory:    opts.Config.Dictionary.InMemory,
+		JournalMode: opts.Config.Dictionary.JournalMode,
+		Synchronous: opts.Config.Dictionary.Synchronous,
+		Path:        opts.Config.Dictionary.Path,
+	}
+	if opts.CacheSize > 0 {
+		dictOpts.CacheSize = opts.CacheSize
+	}
+	dict, err := NewDictionaryWithOptions(dictOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create dictionary: %w", err)
 	}
 
-	// Create generator manager
-	genManager := generator.NewManager()
+	genManager, err := newGenerators(opts)
+	if err != nil {
+		dict.Close()
+		return nil, err
+	}
 
-	// Register format patterns from the pattern registry
-	if opts.Patterns != nil {
-		if err := registerFormatPatterns(genManager, opts.Patterns); err != nil {
-			return nil, fmt.Errorf("failed to register format patterns: %w", err)
-		}
+	batchSize, throttle := batchSettings(opts)
+
+	// In copy mode, the database section is only read, and the run
+	// anonymizes the database copied to
+	cfg := opts.Config
+	var source *database.Connector
+	if cfg.IsCopyMode() {
+		source = database.NewConnector(&cfg.Database)
+		source.SetQuiet(opts.Quiet)
+		cfg = cfg.ForCopy()
 	}
 
-	return &Anonymizer{
-		config:     opts.Config,
+	a := &Anonymizer{
+		config:     cfg,
 		patterns:   opts.Patterns,
 		generators: genManager,
-		connector:  database.NewConnector(&opts.Config.Database),
+		connector:  database.NewConnector(&cfg.Database),
+		source:     source,
 		dictionary: dict,
+		dictOpts:   dictOpts,
+		batchSize:  batchSize,
+		throttle:   throttle,
 		quiet:      opts.Quiet,
-	}, nil
+	}
+	a.connector.SetQuiet(opts.Quiet)
+	a.addCommandHooks(opts.Config.Hooks)
+
+	if err := a.openTrace(opts); err != nil {
+		a.Close()
+		return nil, err
+	}
+	if err := a.openFingerprints(); err != nil {
+		a.Close()
+		return nil, err
+	}
+
+	return a, nil
 }
 
-// registerFormatPatterns registers format-based generators from the pattern registry.
-func registerFormatPatterns(mgr *generator.Manager, registry *pattern.Registry) error {
+// newGenerators creates the generator manager, with the generators of the
+// pattern registry and the custom generators of the options.
+func newGenerators(opts Options) (*generator.Manager, error) {
+	// Weight countries as the locale sets before any generators it
+	// replaces can be overridden
+	genManager := generator.NewManager()
+	if err := genManager.SetLocale(opts.Config.Locale.Weights()); err != nil {
+		return nil, fmt.Errorf("invalid locale: %w", err)
+	}
+
+	// Register format, exec and script patterns from the pattern registry
+	if opts.Patterns != nil {
+		if err := RegisterPatternGenerators(genManager, opts.Patterns); err != nil {
+			return nil, fmt.Errorf("failed to register patterns: %w", err)
+		}
+	}
+
+	// Register custom generators last so they take precedence
+	for _, gen := range opts.Generators {
+		genManager.Register(gen)
+	}
+	return genManager, nil
+}
+
+// batchSettings returns the batch size, that of the options over the
+// configured one, and the throttle of the run.
+func batchSettings(opts Options) (int, *database.Throttle) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = opts.Config.BatchSize
+	}
+	return batchSize, database.NewThrottle(opts.Config.MaxRowsPerSecond,
+		opts.Config.BatchPause())
+}
+
+// RegisterPatternGenerators registers format-based, command-based,
+// script-based, encryption, hash, redaction, suppression, epoch
+// timestamp and geolocation generators from the pattern registry.
+func RegisterPatternGenerators(mgr *generator.Manager,
+	registry *pattern.Registry) error {
 	for _, name := range registry.List() {
 		p, _ := registry.Get(name)
-		if p.IsFormatPattern() {
+		if p.IsScriptPattern() {
+			cfg := generator.ScriptPatternConfig{
+				Name:   p.Name,
+				Script: p.Script,
+			}
+			if err := mgr.RegisterScriptPattern(cfg); err != nil {
+				return fmt.Errorf("failed to register pattern %s: %w", p.Name, err)
+			}
+		} else if p.IsFPEPattern() {
+			cfg := generator.FPEPatternConfig{
+				Name:    p.Name,
+				KeyEnv:  p.FPE.KeyEnv,
+				KeyFile: p.FPE.KeyFile,
+				Tweak:   p.FPE.Tweak,
+			}
+			if err := mgr.RegisterFPEPattern(cfg); err != nil {
+				return fmt.Errorf("failed to register pattern %s: %w", p.Name, err)
+			}
+		} else if p.IsHashPattern() {
+			cfg := generator.HashPatternConfig{
+				Name:            p.Name,
+				KeyEnv:          p.Hash.KeyEnv,
+				KeyFile:         p.Hash.KeyFile,
+				Length:          p.Hash.Length,
+				Format:          p.Hash.Format,
+				CaseInsensitive: p.Hash.CaseInsensitive,
+			}
+			if err := mgr.RegisterHashPattern(cfg); err != nil {
+				return fmt.Errorf("failed to register pattern %s: %w", p.Name, err)
+			}
+		} else if p.IsRedactPattern() {
+			cfg := generator.RedactPatternConfig{
+				Name:       p.Name,
+				KeepFirst:  p.Redact.KeepFirst,
+				KeepLast:   p.Redact.KeepLast,
+				KeepDomain: p.Redact.KeepDomain,
+				Char:       p.Redact.Char,
+			}
+			if err := mgr.RegisterRedactPattern(cfg); err != nil {
+				return fmt.Errorf("failed to register pattern %s: %w", p.Name, err)
+			}
+		} else if p.IsSuppressPattern() {
+			cfg := generator.SuppressPatternConfig{
+				Name:   p.Name,
+				Values: p.Suppress.Values,
+			}
+			if err := mgr.RegisterSuppressPattern(cfg); err != nil {
+				return fmt.Errorf("failed to register pattern %s: %w", p.Name, err)
+			}
+		} else if p.IsEpochPattern() {
+			cfg := generator.EpochPatternConfig{
+				Name:      p.Name,
+				Mode:      p.Epoch.Mode,
+				ShiftDays: p.Epoch.ShiftDays,
+				From:      p.Epoch.From,
+				To:        p.Epoch.To,
+			}
+			if err := mgr.RegisterEpochPattern(cfg); err != nil {
+				return fmt.Errorf("failed to register pattern %s: %w", p.Name, err)
+			}
+		} else if p.IsGeoPattern() {
+			cfg := generator.GeoPatternConfig{
+				Name:         p.Name,
+				Type:         p.Geo.Type,
+				BoundingBox:  p.Geo.BoundingBox,
+				JitterMeters: p.Geo.JitterMeters,
+			}
+			if err := mgr.RegisterGeoPattern(cfg); err != nil {
+				return fmt.Errorf("failed to register pattern %s: %w", p.Name, err)
+			}
+		} else if p.IsIPPattern() {
+			cfg := generator.IPPatternConfig{
+				Name:       p.Name,
+				IPv4Prefix: p.IP.IPv4Prefix,
+				IPv6Prefix: p.IP.IPv6Prefix,
+			}
+			if err := mgr.RegisterIPPattern(cfg); err != nil {
+				return fmt.Errorf("failed to register pattern %s: %w", p.Name, err)
+			}
+		} else if p.IsNoisePattern() {
+			cfg := generator.NoisePatternConfig{
+				Name:        p.Name,
+				Epsilon:     p.Noise.Epsilon,
+				Sensitivity: p.Noise.Sensitivity,
+				NonNegative: p.Noise.NonNegative,
+			}
+			if err := mgr.RegisterNoisePattern(cfg); err != nil {
+				return fmt.Errorf("failed to register pattern %s: %w", p.Name, err)
+			}
+		} else if p.IsExecPattern() {
+			cfg := generator.ExecPatternConfig{
+				Name:    p.Name,
+				Command: p.Exec,
+				Args:    p.Args,
+			}
+			if err := mgr.RegisterExecPattern(cfg); err != nil {
+				return fmt.Errorf("failed to register pattern %s: %w", p.Name, err)
+			}
+		} else if p.IsFormatPattern() {
 			cfg := generator.FormatPatternConfig{
 				Name:    p.Name,
 				Format:  p.Format,
@@ -98,6 +310,32 @@ func registerFormatPatterns(mgr *generator.Manager, registry *pattern.Registry)
 // Run executes the complete anonymization process.
 func (a *Anonymizer) Run(ctx context.Context) (*stats.Stats, error) {
 	defer a.dictionary.Close()
+	defer a.generators.Close()
+
+	result, err := a.runAudited(ctx)
+	if closeErr := a.closeFiles(); closeErr != nil && err == nil {
+		return nil, fmt.Errorf("anonymization was committed, but %w",
+			closeErr)
+	}
+	return result, err
+}
+
+// run anonymizes the configured database, in a single transaction or, as
+// the transaction mode selects, in one for each table.
+func (a *Anonymizer) run(ctx context.Context) (*stats.Stats, error) {
+	// Limit the whole run, including its checks, to max_duration
+	unitCtx := ctx
+	if d := a.config.MaxRunDuration(); d > 0 {
+		var cancel context.CancelFunc
+		unitCtx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	// Resume after the tables an earlier run committed
+	ckpt, err := a.openCheckpoint()
+	if err != nil {
+		return nil, err
+	}
 
 	// Connect to database
 	if err := a.connector.Connect(ctx); err != nil {
@@ -105,14 +343,67 @@ func (a *Anonymizer) Run(ctx context.Context) (*stats.Stats, error) {
 	}
 	defer a.connector.Close()
 
+	// Expand wildcard column entries before validation
+	validator := database.NewSchemaValidator(a.connector.DB())
+	colConfigs, err := validator.ExpandWildcards(ctx, a.config.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anonymize the table columns views read rather than the views'
+	colConfigs, resolved, err := validator.ResolveViewColumns(ctx, colConfigs)
+	if err != nil {
+		return nil, err
+	}
+	if !a.quiet {
+		for _, r := range resolved {
+			fmt.Printf("Anonymizing %s for view column %s\n", r.Table.String(),
+				r.View.String())
+		}
+	}
+
+	// Scope the mappings of the tables with the partition column
+	colConfigs, err = ResolvePartitions(ctx, validator, a.config, colConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anonymize the columns referencing anonymized ones alike
+	fkAnalyzer := database.NewFKAnalyzer(a.connector.DB())
+	fkAnalyzer.SetReferences(a.config.DeclaredReferences())
+	colConfigs, propagated, err := PropagateReferences(ctx, a.config,
+		fkAnalyzer, colConfigs)
+	if err != nil {
+		return nil, err
+	}
+	a.foreignKeys = make(map[string]bool)
+	for _, rc := range propagated {
+		if rc.Constraint != "" {
+			a.foreignKeys[rc.Constraint] = true
+		}
+		if !a.quiet {
+			fmt.Printf("Propagating %s to %s (%s)\n", rc.Parent.String(),
+				rc.Child.String(), rc.Via())
+		}
+	}
+
+	// Anonymize the tables inheriting from configured ones, such as
+	// partitions, each on its own
+	colConfigs, a.onlyTables, err = validator.ExpandInheritance(ctx,
+		colConfigs)
+	if err != nil {
+		return nil, err
+	}
+	cfg := a.config.WithColumns(colConfigs)
+
 	// Validate columns exist
-	columns, err := a.config.GetColumnRefs()
+	columns, err := cfg.GetColumnRefs()
 	if err != nil {
 		return nil, err
 	}
 
-	validator := database.NewSchemaValidator(a.connector.DB())
-	missing, err := validator.ValidateColumns(ctx, columns)
+	missing, err := validator.ValidateColumns(ctx,
+		append(cfg.ReferencedColumns(), columns...))
 	if err != nil {
 		return nil, err
 	}
@@ -121,8 +412,29 @@ func (a *Anonymizer) Run(ctx context.Context) (*stats.Stats, error) {
 			"columns not found in database", missing)
 	}
 
+	// Clean generated values for the database's encoding
+	if err := a.setSanitizer(ctx, validator); err != nil {
+		return nil, err
+	}
+	if err := CheckColumnTypes(ctx, validator, a.generators,
+		cfg.Columns); err != nil {
+		return nil, err
+	}
+	if err := CheckProfileKeys(ctx, validator, cfg.Columns); err != nil {
+		return nil, err
+	}
+	if err := CheckSubsetTables(columns, cfg.Tables); err != nil {
+		return nil, err
+	}
+	if a.source != nil {
+		closeSource, err := a.openSource(ctx, columns)
+		if err != nil {
+			return nil, err
+		}
+		defer closeSource()
+	}
+
 	// Analyze foreign keys and get processing order
-	fkAnalyzer := database.NewFKAnalyzer(a.connector.DB())
 	orderedColumns, err := fkAnalyzer.GetProcessingOrder(ctx, columns)
 	if err != nil {
 		return nil, err
@@ -133,40 +445,276 @@ func (a *Anonymizer) Run(ctx context.Context) (*stats.Stats, error) {
 	if err != nil {
 		return nil, err
 	}
-	skipSet := make(map[string]bool)
+	skipSet := make(map[string]string)
 	for _, col := range cascadeTargets {
-		skipSet[col.String()] = true
+		skipSet[col.String()] = "CASCADE target"
+	}
+
+	// Skip the columns whose values the database generates
+	generated, err := CheckGeneratedColumns(ctx, validator, columns)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range generated {
+		skipSet[s.Column.String()] = s.Reason
+		if !a.quiet {
+			log.Printf("Warning: %s: %s", s.Column.String(), s.Warning)
+		}
 	}
 
 	// Build column-to-config mapping
 	columnConfigMap := make(map[string]config.ColumnConfig)
-	for _, cc := range a.config.Columns {
+	for _, cc := range cfg.Columns {
 		columnConfigMap[cc.Column] = cc
 	}
 
-	// Start transaction
-	tx, err := a.connector.BeginTx(ctx)
+	// Derive columns once the columns they are derived from are anonymized
+	orderedColumns = orderDerived(orderedColumns, columnConfigMap)
+	orderedColumns = resumeColumns(orderedColumns, ckpt)
+
+	// Choose how to process each column from the estimate of its
+	// distinct values, before any data is changed
+	if a.config.AnalyzeColumns {
+		a.analysis, err = a.analyzeColumns(ctx, validator, orderedColumns,
+			skipSet, columnConfigMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Warn about constraints anonymized values may violate, before any
+	// data is changed
+	if !a.quiet {
+		for _, col := range orderedColumns {
+			if skipSet[col.String()] != "" {
+				continue
+			}
+			warnings, err := CheckConstraints(ctx, validator, a.generators, col,
+				columnConfigMap[col.String()])
+			if err != nil {
+				return nil, err
+			}
+			for _, w := range warnings {
+				log.Printf("Warning: %s: %s", col.String(), w)
+			}
+		}
+	}
+
+	// Measure the coverage of likely personal data before any is changed;
+	// it is only reported, so a failure does not stop the run
+	coverage, err := MeasureCoverage(ctx, validator, columns)
+	if err != nil {
+		log.Printf("Warning: failed to measure PII coverage: %v", err)
+	}
+
+	// Find the materialized views that would keep the original values
+	matviews, err := a.dependentMatviews(ctx, validator, columns)
 	if err != nil {
 		return nil, err
 	}
 
+	// Capture the planner statistics to check they are replaced
+	statistics := a.captureStatistics(ctx, validator, orderedColumns,
+		columnConfigMap)
+
+	// Group the columns into the units committed together
+	mode := a.transactionMode()
+	units := [][]errors.ColumnRef{orderedColumns}
+	if mode != config.TransactionSingle {
+		fks, err := fkAnalyzer.Analyze(ctx, columns)
+		if err != nil {
+			return nil, err
+		}
+		units = transactionUnits(orderedColumns, fks)
+	}
+
+	// Process each unit in a transaction of its own
+	collector := stats.NewCollector()
+	startTime := time.Now()
+	var committed []string
+
+	for i, unit := range units {
+		tables := unitTables(unit)
+		t := &unitTransaction{a: a}
+		commitBatches := mode == config.TransactionPerBatch && len(tables) == 1
+
+		err := unitCtx.Err()
+		if err == nil {
+			err = a.retryUnit(unitCtx, t, unit, commitBatches, validator,
+				skipSet, columnConfigMap, collector)
+		}
+		if err != nil {
+			// Stop at max_duration, keeping the tables committed
+			if unitCtx.Err() != nil && ctx.Err() == nil {
+				stopped := &MaxDurationError{
+					MaxDuration: a.config.MaxRunDuration(),
+					Committed:   committed,
+					Checkpoint:  a.config.CheckpointFile,
+				}
+				if t.batchCommits > 0 {
+					stopped.Partial = tables
+				}
+				for _, u := range units[i:] {
+					stopped.Remaining = append(stopped.Remaining,
+						unitTables(u)...)
+				}
+				return nil, stopped
+			}
+
+			err = a.lockTimeoutError(ctx, validator, t.table, err)
+
+			// Report the changes that stay committed
+			if len(committed) == 0 && t.batchCommits == 0 {
+				return nil, err
+			}
+			partial := &PartialCommitError{Committed: committed, Err: err}
+			if t.batchCommits > 0 {
+				partial.Partial = tables
+			}
+			return nil, partial
+		}
+
+		// Start the next unit on a connection known to work
+		a.connector.Release()
+
+		committed = append(committed, tables...)
+		if mode != config.TransactionSingle && !a.quiet {
+			fmt.Printf("Committed %s\n", strings.Join(tables, ", "))
+		}
+
+		if ckpt != nil {
+			if err := ckpt.Commit(a.config.CheckpointFile,
+				tables...); err != nil {
+				return nil, &PartialCommitError{Committed: committed, Err: err}
+			}
+		}
+	}
+
+	if err := a.refreshMatviews(ctx, matviews); err != nil {
+		return nil, &PartialCommitError{Committed: committed, Err: err}
+	}
+
+	a.verifyStatistics(ctx, validator, statistics)
+
+	// The run is complete, leaving nothing to resume
+	if ckpt != nil {
+		if err := checkpoint.Remove(a.config.CheckpointFile); err != nil {
+			return nil, fmt.Errorf("anonymization was committed, but %w", err)
+		}
+	}
+
+	// Finalize statistics
+	finalStats := collector.Finalize(time.Since(startTime))
+	finalStats.Coverage = coverage
+	finalStats.Dictionary = a.dictionary.Stats()
+
+	return finalStats, nil
+}
+
+// retryUnit anonymizes a unit, starting it again on a new connection
+// after a transient error, such as a lost connection, as connecting is
+// retried, unless it has committed batches or recorded a trace. The
+// statistics of an attempt are collected once it commits.
+func (a *Anonymizer) retryUnit(
+	ctx context.Context,
+	t *unitTransaction,
+	unit []errors.ColumnRef,
+	commitBatches bool,
+	validator *database.SchemaValidator,
+	skipSet map[string]string,
+	columnConfigMap map[string]config.ColumnConfig,
+	collector *stats.Collector,
+) error {
+	var err error
+	op := "anonymize " + strings.Join(unitTables(unit), ", ")
+	retryErr := a.connector.Retry(ctx, op, func() error {
+		*t = unitTransaction{a: a}
+		attempt := stats.NewCollector()
+		err = a.anonymizeUnit(ctx, t, unit, commitBatches, validator,
+			skipSet, columnConfigMap, attempt)
+		if err == nil {
+			collector.Merge(attempt)
+			return nil
+		}
+
+		// A unit that committed batches, or recorded its values, cannot
+		// be started again
+		if t.batchCommits > 0 || a.recorder != nil {
+			return nil
+		}
+		a.connector.Release()
+		return err
+	})
+	if retryErr != nil {
+		return retryErr
+	}
+	return err
+}
+
+// anonymizeUnit anonymizes the columns of a unit, in processing order, in
+// a transaction of its own, and commits it. If commitBatches is set, the
+// transaction is also committed after each batch.
+func (a *Anonymizer) anonymizeUnit(
+	ctx context.Context,
+	t *unitTransaction,
+	unit []errors.ColumnRef,
+	commitBatches bool,
+	validator *database.SchemaValidator,
+	skipSet map[string]string,
+	columnConfigMap map[string]config.ColumnConfig,
+	collector *stats.Collector,
+) error {
+	tx, err := a.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	t.tx = tx
+
 	// Ensure rollback on error
 	committed := false
 	defer func() {
 		if !committed {
-			_ = tx.Rollback()
+			_ = t.tx.Rollback()
 		}
 	}()
 
-	// Process each column
-	collector := stats.NewCollector()
-	startTime := time.Now()
+	var commit database.CommitFunc
+	if commitBatches {
+		commit = t.commitBatch
+	}
+
+	// Copy the tables in copy mode, and keep only their subsets, before
+	// anonymizing them
+	if err := a.copyTables(ctx, t.tx, unit); err != nil {
+		return err
+	}
+	if err := t.disableTriggers(ctx, unitTables(unit)); err != nil {
+		return err
+	}
+	if err := t.dropIndexes(ctx, unit, skipSet); err != nil {
+		return err
+	}
+	if err := t.dropForeignKeys(ctx, unitTables(unit)); err != nil {
+		return err
+	}
+	if err := a.subsetTables(ctx, t.tx, unit); err != nil {
+		return err
+	}
 
-	for _, col := range orderedColumns {
-		// Skip CASCADE targets
-		if skipSet[col.String()] {
+	var anonymized []errors.ColumnRef
+
+	// Progress is reported for each table, over its consecutive columns
+	runs := make(map[string][]errors.ColumnRef)
+	for _, run := range tableRuns(unit, skipSet) {
+		runs[run[0].String()] = run
+	}
+	var progress *tableProgress
+
+	for _, col := range unit {
+		// Skip CASCADE targets and generated columns
+		if reason := skipSet[col.String()]; reason != "" {
 			if !a.quiet {
-				fmt.Printf("Skipping %s (CASCADE target)\n", col.String())
+				fmt.Printf("Skipping %s (%s)\n", col.String(), reason)
 			}
 			continue
 		}
@@ -174,67 +722,171 @@ func (a *Anonymizer) Run(ctx context.Context) (*stats.Stats, error) {
 		// Get column config
 		colConfig, ok := columnConfigMap[col.String()]
 		if !ok {
-			return nil, fmt.Errorf("no config found for column %s", col.String())
+			return fmt.Errorf("no config found for column %s", col.String())
+		}
+
+		// Lock each table before its first column
+		if err := t.lockTable(ctx, col, collector); err != nil {
+			return err
 		}
 
 		// Get column data type for proper casting
 		dataType, err := validator.GetColumnDataType(ctx, col)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get data type for %s: %w",
+			return fmt.Errorf("failed to get data type for %s: %w",
 				col.String(), err)
 		}
 
-		if !a.quiet {
-			estimate, _ := validator.GetTableRowEstimate(ctx,
-				col.Schema, col.Table)
-			fmt.Printf("Processing %s (est. %d rows)...\n",
-				col.String(), estimate)
+		if run, ok := runs[col.String()]; ok {
+			progress = a.startTable(ctx, validator, run)
+		}
+		progress.startColumn(col)
+
+		if err := a.runHooks(ctx, HookEvent{
+			Point:  HookBeforeColumn,
+			Column: col,
+		}); err != nil {
+			return err
 		}
 
-		// Process column - different handling for JSON vs simple columns
+		// Process column - different handling for JSON, XML, composite, and
+		// simple columns
 		colStart := time.Now()
 		var result *ProcessResult
 
+		tuning, err := a.batchTuning(ctx, validator, col, colConfig)
+		if err != nil {
+			return err
+		}
+		tuning.commit = commit
+
 		if colConfig.IsJSONColumn() {
 			// JSON column: process with JSON path extraction
-			result, err = a.processJSONColumn(ctx, tx, col, dataType, colConfig)
+			result, err = a.processJSONColumn(ctx, t.tx, col, dataType, colConfig,
+				tuning, progress.update)
+		} else if colConfig.IsXMLColumn() {
+			// XML column: process with XPath selection
+			result, err = a.processXMLColumn(ctx, t.tx, col, dataType, colConfig,
+				tuning, progress.update)
+		} else if colConfig.IsCompositeColumn() {
+			// Composite column: process individual fields of the row value
+			result, err = a.processCompositeColumn(ctx, t.tx, col, colConfig,
+				validator, tuning, progress.update)
+		} else if colConfig.IsDerivedColumn() {
+			// Derived column: compute from the row's anonymized columns
+			result, err = a.processDeriveColumn(ctx, t.tx, col, dataType,
+				colConfig, tuning, progress.update)
+		} else if colConfig.IsProfileColumn() {
+			// Profile column: fill from the persona of each row's person
+			result, err = a.processProfileColumn(ctx, t.tx, col, dataType,
+				*colConfig.Profile, validator, tuning, progress.update)
+		} else if colConfig.IsFillColumn() {
+			// Nullified or constant column: set every value at once
+			result, err = a.processFillColumn(ctx, t.tx, col, dataType,
+				colConfig.Constant, validator, tuning, progress.update)
+		} else if colConfig.IsShuffleColumn() {
+			// Shuffled column: permute the values among the rows
+			result, err = a.processShuffleColumn(ctx, t.tx, col, dataType,
+				colConfig.ShuffleBy, validator, tuning, progress.update)
+		} else if colConfig.IsDateShiftColumn() {
+			// Date shift column: shift by the offset of each row's entity
+			result, err = a.processDateShiftColumn(ctx, t.tx, col, dataType,
+				colConfig.Entity, tuning, progress.update)
+		} else if colConfig.IsLargeObjectColumn() {
+			// Large object column: anonymize the objects it references
+			result, err = a.processLargeObjectColumn(ctx, t.tx, col, dataType,
+				colConfig.Pattern, tuning, progress.update)
+		} else if colConfig.IsDistributionColumn() {
+			// Distribution column: draw from the original distribution
+			result, err = a.processDistributionColumn(ctx, t.tx, col,
+				dataType, validator, tuning, progress.update)
+		} else if colConfig.IsDocumentColumn() {
+			// Document column: anonymize the text of each document
+			result, err = a.processDocumentColumn(ctx, t.tx, col, dataType,
+				colConfig.Pattern, tuning, progress.update)
 		} else {
 			// Simple column: process with single pattern
-			result, err = a.processSimpleColumn(ctx, tx, col, dataType,
-				colConfig.Pattern, validator)
+			dict := a.columnDictionary(colConfig, colConfig.Pattern)
+			result, err = a.processSimpleColumn(ctx, t.tx, col, dataType,
+				colConfig.Pattern, dict, newCollisionPolicy(colConfig),
+				colConfig.PartitionBy, validator, tuning, progress.update)
 		}
 
+		if err == nil {
+			// A failed external generator yields empty values, so the
+			// transaction must not be committed
+			err = a.generators.Err()
+		}
+		if err == nil {
+			err = a.traceErr()
+		}
+		if err == nil {
+			err = a.fingerprintErr()
+		}
 		if err != nil {
-			return nil, errors.NewAnonymizationError(col, 0, "",
+			return errors.NewAnonymizationError(col, 0, "",
 				fmt.Sprintf("processing failed: %v", err), err)
 		}
 
+		tuning.oversized.report(a.quiet)
+
 		// Record statistics
+		duration := time.Since(colStart)
 		collector.RecordColumn(stats.ColumnStats{
-			Column:           col,
-			RowsProcessed:    result.RowsProcessed,
-			ValuesAnonymized: result.ValuesAnonymized,
-			UniqueValues:     result.UniqueValues,
-			Duration:         time.Since(colStart),
+			Column:             col,
+			RowsProcessed:      result.RowsProcessed,
+			ValuesAnonymized:   result.ValuesAnonymized,
+			UniqueValues:       result.UniqueValues,
+			Collisions:         result.Collisions,
+			CollisionFallbacks: result.CollisionFallbacks,
+			MaxStatementBytes:  result.MaxStatementBytes,
+			Duration:           duration,
+			Phases: stats.NewPhases(duration, result.Phases.Fetch,
+				result.Phases.Update, result.Phases.Wait),
 		})
+		anonymized = append(anonymized, col)
 
-		if !a.quiet {
-			fmt.Printf("  Completed: %d rows, %d values anonymized\n",
-				result.RowsProcessed, result.ValuesAnonymized)
+		if err := a.runHooks(ctx, HookEvent{
+			Point:            HookAfterColumn,
+			Column:           col,
+			Rows:             result.RowsProcessed,
+			ValuesAnonymized: result.ValuesAnonymized,
+		}); err != nil {
+			return err
 		}
+
+		progress.finishColumn(result)
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return nil, errors.NewDatabaseError("commit",
-			fmt.Sprintf("failed to commit transaction: %v", err), err)
+	// Bring derived tsvector columns up to date
+	if err := a.refreshDerivedColumns(ctx, t.tx, validator, anonymized,
+		collector); err != nil {
+		return err
 	}
-	committed = true
 
-	// Finalize statistics
-	finalStats := collector.Finalize(time.Since(startTime))
+	// Create the indexes dropped before the columns were updated
+	if err := t.recreateIndexes(ctx, collector); err != nil {
+		return err
+	}
 
-	return finalStats, nil
+	// Add the foreign keys dropped to propagate values again, checking
+	// that each row still references an existing one
+	if err := database.RestoreForeignKeys(ctx, t.tx,
+		t.foreignKeys); err != nil {
+		return err
+	}
+
+	// Replace the planner statistics, which hold original values, along
+	// with the data
+	if err := a.analyzeTables(ctx, t.tx, unit); err != nil {
+		return err
+	}
+
+	if err := t.commit(ctx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
 }
 
 // Close releases resources held by the anonymizer.
@@ -242,10 +894,23 @@ func (a *Anonymizer) Close() error {
 	if a.dictionary != nil {
 		a.dictionary.Close()
 	}
+	if a.generators != nil {
+		a.generators.Close()
+	}
 	if a.connector != nil {
 		a.connector.Close()
 	}
-	return nil
+	return a.closeFiles()
+}
+
+// closeFiles closes the trace and fingerprint files written during runs,
+// returning the first error.
+func (a *Anonymizer) closeFiles() error {
+	traceErr := a.closeTrace()
+	if err := a.closeFingerprints(); err != nil && traceErr == nil {
+		return err
+	}
+	return traceErr
 }
 
 // processSimpleColumn processes a column with a single pattern.
@@ -255,15 +920,42 @@ func (a *Anonymizer) processSimpleColumn(
 	col errors.ColumnRef,
 	dataType string,
 	patternName string,
+	dict *Dictionary,
+	collision *collisionPolicy,
+	partitionBy string,
 	validator *database.SchemaValidator,
+	tuning batchTuning,
+	progress func(processed int64),
 ) (*ProcessResult, error) {
-	// Get generator for pattern
-	gen, ok := a.generators.Get(patternName)
+	// Get generator for pattern, fitted to the column
+	maxLength, err := validator.GetColumnMaxLength(ctx, col)
+	if err != nil {
+		return nil, err
+	}
+	gen, ok := a.generators.GetForContext(patternName, generator.ColumnInfo{
+		Column:    col,
+		DataType:  dataType,
+		MaxLength: maxLength,
+	})
 	if !ok {
 		return nil, fmt.Errorf("unknown pattern %q for column %s",
 			patternName, col.String())
 	}
 
+	// Regenerate or repair values that would violate CHECK constraints,
+	// domain constraints, or the labels of an enum type
+	checks, enum, err := columnConstraints(ctx, validator, col)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check constraints for %s: %w",
+			col.String(), err)
+	}
+	gen = newConstrainedGenerator(gen, enforceableConstraints(col, checks,
+		enum))
+
+	// Record or replay the generated values
+	tr := a.newColumnTrace(col)
+	gen = tr.wrap(gen, patternName)
+
 	// Check if column has a unique constraint
 	hasUnique, err := validator.HasUniqueConstraint(ctx, col)
 	if err != nil {
@@ -271,16 +963,154 @@ func (a *Anonymizer) processSimpleColumn(
 			col.String(), err)
 	}
 
-	processor := NewColumnProcessor(tx, col, dataType, gen, a.dictionary,
-		database.DefaultBatchSize, hasUnique)
+	processor := NewColumnProcessor(tx, col, dataType, gen, dict,
+		tuning.batchSize, hasUnique)
 
-	var lastProgress int64
-	return processor.Process(ctx, func(processed int64) {
-		if !a.quiet && processed-lastProgress >= 10000 {
-			fmt.Printf("  %d rows processed\n", processed)
-			lastProgress = processed
+	// Map the values of a low-cardinality column up front, unless values
+	// are traced, which is done row by row, only a sample of the rows is
+	// anonymized, as every row holding a value would be updated, or the
+	// values are mapped apart in each partition
+	if tr == nil && tuning.samplePercent == 0 && partitionBy == "" {
+		if processor.distinctLimit, err = a.distinctLimit(ctx, validator,
+			col); err != nil {
+			return nil, err
 		}
-	})
+	}
+
+	processor.batchHook = a.batchHook(col)
+	processor.trace = tr
+	processor.tuning = tuning
+	processor.collision = collision
+	processor.partitionBy = partitionBy
+
+	return processor.Process(ctx, progress)
+}
+
+// columnDictionary returns the dictionary namespace the values of a column
+// anonymized with a pattern are mapped in.
+func (a *Anonymizer) columnDictionary(colConfig config.ColumnConfig,
+	pattern string) *Dictionary {
+
+	return a.dictionary.Namespace(ColumnNamespace(a.config, colConfig,
+		pattern))
+}
+
+// ColumnNamespace returns the name of the dictionary namespace the values
+// of a column anonymized with a pattern are mapped in: its consistency
+// group's, or else the pattern's, the column's, or the global namespace,
+// as the dictionary scope selects.
+func ColumnNamespace(cfg *config.Config, colConfig config.ColumnConfig,
+	pattern string) string {
+
+	switch {
+	case colConfig.ConsistencyGroup != "":
+		return "group:" + colConfig.ConsistencyGroup
+	case cfg.DictionaryScope == config.DictionaryScopePattern:
+		return PatternNamespace(pattern)
+	case cfg.DictionaryScope == config.DictionaryScopeColumn:
+		return "column:" + colConfig.Column
+	}
+	return ""
+}
+
+// PatternNamespace returns the name of the dictionary namespace the values
+// anonymized with a pattern are mapped in when they are kept apart by
+// pattern.
+func PatternNamespace(pattern string) string {
+	return "pattern:" + pattern
+}
+
+// batchTuning returns the settings tuning how a column's batches are read
+// and written, taking the column's own batch size and strategy over the
+// run's, and the batch size the analysis chose over the default, and
+// looking up the primary key to page through the table by for
+// the keyset strategy.
+func (a *Anonymizer) batchTuning(ctx context.Context,
+	validator *database.SchemaValidator, col errors.ColumnRef,
+	colConfig config.ColumnConfig) (batchTuning, error) {
+
+	tuning := batchTuning{
+		batchSize:         a.batchSize,
+		throttle:          a.throttle,
+		fetchSize:         a.config.FetchSize,
+		updateChunkSize:   a.config.UpdateChunkSize,
+		maxStatementBytes: a.config.MaxStatementBytes,
+		strategy:          a.config.Strategy,
+		samplePercent:     colConfig.SamplePercent,
+		only:              a.onlyTables[col.Schema+"."+col.Table],
+		oversized:         newOversizedValues(a.config, col),
+	}
+	if c, ok := a.analysis[col.String()]; ok && c.batchSize > 0 {
+		tuning.batchSize = c.batchSize
+	}
+	if colConfig.BatchSize > 0 {
+		tuning.batchSize = colConfig.BatchSize
+	}
+	if colConfig.Strategy != "" && !colConfig.IsShuffleColumn() &&
+		!colConfig.IsNullifyColumn() {
+		tuning.strategy = colConfig.Strategy
+	}
+
+	switch tuning.strategy {
+	case config.StrategyKeyset:
+		key, err := validator.GetPrimaryKey(ctx, col.Schema, col.Table)
+		if err != nil {
+			return tuning, err
+		}
+		if len(key) == 0 {
+			return tuning, errors.NewValidationError(
+				"the keyset strategy requires a primary key",
+				[]errors.ColumnRef{col})
+		}
+		for _, kc := range key {
+			if kc.Name == col.Column {
+				return tuning, errors.NewValidationError(
+					"the keyset strategy cannot page by the anonymized column",
+					[]errors.ColumnRef{col})
+			}
+		}
+		tuning.keyColumns = key
+	case config.StrategyCopy:
+		tuning.copier = a.connector
+	}
+
+	return tuning, nil
+}
+
+// distinctLimit returns the most distinct values a column may have for
+// them to be mapped up front, or zero if the planner statistics show the
+// column has more distinct values than the configured threshold, or if
+// the column has no statistics. An analyzed column's limit is the one
+// the analysis chose.
+func (a *Anonymizer) distinctLimit(ctx context.Context,
+	validator *database.SchemaValidator, col errors.ColumnRef) (int, error) {
+
+	if c, ok := a.analysis[col.String()]; ok {
+		return c.distinctLimit, nil
+	}
+	threshold := a.lowCardinalityThreshold()
+	if threshold < 0 {
+		return 0, nil
+	}
+
+	estimate, err := validator.GetDistinctEstimate(ctx, col)
+	if err != nil {
+		return 0, err
+	}
+	if estimate < 0 || estimate > int64(threshold) {
+		return 0, nil
+	}
+	return threshold, nil
+}
+
+// lowCardinalityThreshold returns the configured low-cardinality
+// threshold, or the default if it is not set; a negative threshold
+// disables mapping values up front.
+func (a *Anonymizer) lowCardinalityThreshold() int {
+	if a.config.LowCardinalityThreshold == 0 {
+		return defaultLowCardinalityThreshold
+	}
+	return a.config.LowCardinalityThreshold
 }
 
 // processJSONColumn processes a JSON/JSONB column with multiple path patterns.
@@ -290,27 +1120,407 @@ func (a *Anonymizer) processJSONColumn(
 	col errors.ColumnRef,
 	dataType string,
 	colConfig config.ColumnConfig,
+	tuning batchTuning,
+	progress func(processed int64),
 ) (*ProcessResult, error) {
 	// Build generator map for each JSON path
+	tr := a.newColumnTrace(col)
 	generators := make(map[string]generator.Generator)
+	dictionaries := make(map[string]*Dictionary)
 	for _, jp := range colConfig.JSONPaths {
-		gen, ok := a.generators.Get(jp.Pattern)
+		gen, ok := a.generators.GetForColumn(jp.Pattern, col)
 		if !ok {
 			return nil, fmt.Errorf("unknown pattern %q for JSON path %s in column %s",
 				jp.Pattern, jp.Path, col.String())
 		}
-		generators[jp.Path] = gen
+		generators[jp.Path] = tr.wrap(gen, jp.Pattern)
+		dictionaries[jp.Path] = a.columnDictionary(colConfig, jp.Pattern)
 	}
 
 	processor := NewJSONColumnProcessor(
 		tx, col, dataType, colConfig.JSONPaths, generators,
-		a.dictionary, database.DefaultBatchSize, a.quiet)
+		dictionaries, tuning.batchSize, a.quiet)
+
+	processor.batchHook = a.batchHook(col)
+	processor.trace = tr
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
 
-	var lastProgress int64
-	return processor.Process(ctx, func(processed int64) {
-		if !a.quiet && processed-lastProgress >= 10000 {
-			fmt.Printf("  %d rows processed\n", processed)
-			lastProgress = processed
+// processXMLColumn processes an XML column with multiple XPath patterns.
+func (a *Anonymizer) processXMLColumn(
+	ctx context.Context,
+	tx *sql.Tx,
+	col errors.ColumnRef,
+	dataType string,
+	colConfig config.ColumnConfig,
+	tuning batchTuning,
+	progress func(processed int64),
+) (*ProcessResult, error) {
+	// Build generator map for each XPath expression
+	tr := a.newColumnTrace(col)
+	generators := make(map[string]generator.Generator)
+	dictionaries := make(map[string]*Dictionary)
+	for _, xp := range colConfig.XMLPaths {
+		gen, ok := a.generators.GetForColumn(xp.Pattern, col)
+		if !ok {
+			return nil, fmt.Errorf("unknown pattern %q for XPath %s in column %s",
+				xp.Pattern, xp.Path, col.String())
 		}
-	})
+		generators[xp.Path] = tr.wrap(gen, xp.Pattern)
+		dictionaries[xp.Path] = a.columnDictionary(colConfig, xp.Pattern)
+	}
+
+	processor := NewXMLColumnProcessor(
+		tx, col, dataType, colConfig.XMLPaths, generators,
+		dictionaries, tuning.batchSize, a.quiet)
+
+	processor.batchHook = a.batchHook(col)
+	processor.trace = tr
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
+
+// processCompositeColumn processes a composite-typed column with a pattern
+// per field.
+func (a *Anonymizer) processCompositeColumn(
+	ctx context.Context,
+	tx *sql.Tx,
+	col errors.ColumnRef,
+	colConfig config.ColumnConfig,
+	validator *database.SchemaValidator,
+	tuning batchTuning,
+	progress func(processed int64),
+) (*ProcessResult, error) {
+	typeName, fields, err := validator.GetCompositeType(ctx, col)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build generator map keyed by the position of each field in the type
+	tr := a.newColumnTrace(col)
+	generators := make(map[int]generator.Generator)
+	dictionaries := make(map[int]*Dictionary)
+	for _, name := range colConfig.FieldNames() {
+		index := compositeFieldIndex(fields, name)
+		if index < 0 {
+			return nil, fmt.Errorf("field %q not found in composite type %s of column %s",
+				name, typeName, col.String())
+		}
+
+		patternName := colConfig.Fields[name]
+		gen, ok := a.generators.GetForColumn(patternName, col)
+		if !ok {
+			return nil, fmt.Errorf("unknown pattern %q for field %s in column %s",
+				patternName, name, col.String())
+		}
+		generators[index] = tr.wrap(gen, patternName)
+		dictionaries[index] = a.columnDictionary(colConfig, patternName)
+	}
+
+	processor := NewCompositeColumnProcessor(
+		tx, col, typeName, generators,
+		dictionaries, tuning.batchSize, a.quiet)
+
+	processor.batchHook = a.batchHook(col)
+	processor.trace = tr
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
+
+// processProfileColumn processes a column filled from a profile's
+// personas.
+func (a *Anonymizer) processProfileColumn(
+	ctx context.Context,
+	tx *sql.Tx,
+	col errors.ColumnRef,
+	dataType string,
+	profile config.ProfileColumn,
+	validator *database.SchemaValidator,
+	tuning batchTuning,
+	progress func(processed int64),
+) (*ProcessResult, error) {
+	keyColumns, err := ProfileKeyColumns(ctx, validator, col, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	processor := NewProfileColumnProcessor(tx, col, dataType, profile,
+		keyColumns, a.generators, a.dictionary, tuning.batchSize)
+
+	processor.batchHook = a.batchHook(col)
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
+
+// processFillColumn processes a column whose values are all set to a
+// constant, or to NULL if value is nil.
+func (a *Anonymizer) processFillColumn(
+	ctx context.Context,
+	tx *sql.Tx,
+	col errors.ColumnRef,
+	dataType string,
+	value *string,
+	validator *database.SchemaValidator,
+	tuning batchTuning,
+	progress func(processed int64),
+) (*ProcessResult, error) {
+	if value == nil {
+		notNull, err := validator.IsNotNull(ctx, col)
+		if err != nil {
+			return nil, err
+		}
+		if notNull {
+			return nil, fmt.Errorf("column %s is NOT NULL, and cannot be "+
+				"nullified", col.String())
+		}
+	} else {
+		// More than one row would hold the constant
+		hasUnique, err := validator.HasUniqueConstraint(ctx, col)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check unique constraint for "+
+				"%s: %w", col.String(), err)
+		}
+		if hasUnique {
+			return nil, fmt.Errorf("column %s has a unique constraint, and "+
+				"cannot be set to a constant", col.String())
+		}
+	}
+
+	processor := NewFillColumnProcessor(tx, col, dataType, value)
+
+	processor.batchHook = a.batchHook(col)
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
+
+// processShuffleColumn processes a column whose values are permuted among
+// its rows.
+func (a *Anonymizer) processShuffleColumn(
+	ctx context.Context,
+	tx *sql.Tx,
+	col errors.ColumnRef,
+	dataType string,
+	shuffleBy string,
+	validator *database.SchemaValidator,
+	tuning batchTuning,
+	progress func(processed int64),
+) (*ProcessResult, error) {
+	// Rows are updated a batch at a time, so a value may be written to
+	// one row before it is moved from another
+	hasUnique, err := validator.HasUniqueConstraint(ctx, col)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check unique constraint for %s: %w",
+			col.String(), err)
+	}
+	if hasUnique {
+		return nil, fmt.Errorf("column %s has a unique constraint, and "+
+			"cannot be shuffled", col.String())
+	}
+
+	processor := NewShuffleColumnProcessor(tx, col, dataType, shuffleBy,
+		tuning.batchSize)
+
+	processor.batchHook = a.batchHook(col)
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
+
+// processDateShiftColumn processes a column whose dates are shifted by
+// an offset of each row's entity.
+func (a *Anonymizer) processDateShiftColumn(
+	ctx context.Context,
+	tx *sql.Tx,
+	col errors.ColumnRef,
+	dataType string,
+	entity string,
+	tuning batchTuning,
+	progress func(processed int64),
+) (*ProcessResult, error) {
+	gen, _ := a.generators.Get(generator.DateShiftPattern)
+	shifter, ok := gen.(*generator.DateShiftGenerator)
+	if !ok {
+		return nil, fmt.Errorf("pattern %s has been replaced, and cannot "+
+			"shift dates by entity", generator.DateShiftPattern)
+	}
+
+	processor := NewDateShiftColumnProcessor(tx, col, dataType, entity,
+		shifter, a.dictionary, tuning.batchSize)
+
+	processor.batchHook = a.batchHook(col)
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
+
+// Histogram settings of columns whose distribution is preserved: the
+// number of buckets of equal numbers of rows, and the most rows, about,
+// read to find their bounds.
+const (
+	distributionBuckets    = 100
+	distributionSampleRows = 1_000_000
+)
+
+// processDistributionColumn processes a numeric column whose values are
+// replaced with values drawn from a histogram of its original values.
+func (a *Anonymizer) processDistributionColumn(
+	ctx context.Context,
+	tx *sql.Tx,
+	col errors.ColumnRef,
+	dataType string,
+	validator *database.SchemaValidator,
+	tuning batchTuning,
+	progress func(processed int64),
+) (*ProcessResult, error) {
+	// Find the bounds of the histogram's buckets, from a sample of a
+	// large table
+	rows, err := validator.GetTableRowEstimate(ctx, col.Schema, col.Table)
+	if err != nil {
+		return nil, err
+	}
+	samplePercent := 0.0
+	if rows > distributionSampleRows {
+		samplePercent = 100 * float64(distributionSampleRows) / float64(rows)
+	}
+	reader := database.NewBatchProcessor(tx, col, dataType, tuning.batchSize)
+	reader.SetOnly(tuning.only)
+	quantiles, err := reader.FetchQuantiles(ctx, distributionBuckets,
+		samplePercent)
+	if err != nil {
+		return nil, err
+	}
+	if len(quantiles) == 0 {
+		// Every value is NULL
+		return &ProcessResult{}, nil
+	}
+
+	histogram, err := generator.NewHistogramGenerator(
+		"preserve_distribution", quantiles)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", col.String(), err)
+	}
+	gen := histogram.BindContext(generator.ColumnInfo{Column: col,
+		DataType: dataType})
+
+	// Regenerate or repair values that would violate CHECK constraints
+	checks, enum, err := columnConstraints(ctx, validator, col)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check constraints for %s: %w",
+			col.String(), err)
+	}
+	gen = newConstrainedGenerator(gen, enforceableConstraints(col, checks,
+		enum))
+	tr := a.newColumnTrace(col)
+
+	processor := NewDistributionColumnProcessor(tx, col, dataType,
+		tr.wrap(gen, "preserve_distribution"), tuning.batchSize)
+
+	processor.batchHook = a.batchHook(col)
+	processor.trace = tr
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
+
+// processDocumentColumn processes a bytea column holding text documents.
+func (a *Anonymizer) processDocumentColumn(
+	ctx context.Context,
+	tx *sql.Tx,
+	col errors.ColumnRef,
+	dataType string,
+	pattern string,
+	tuning batchTuning,
+	progress func(processed int64),
+) (*ProcessResult, error) {
+	gen, ok := a.generators.GetForColumn(pattern, col)
+	if !ok {
+		return nil, fmt.Errorf("unknown pattern %q for column %s", pattern,
+			col.String())
+	}
+	tr := a.newColumnTrace(col)
+
+	processor := NewDocumentColumnProcessor(tx, col, dataType,
+		tr.wrap(gen, pattern), tuning.batchSize, a.quiet)
+
+	processor.batchHook = a.batchHook(col)
+	processor.trace = tr
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
+
+// processLargeObjectColumn processes a column referencing large objects
+// holding text documents.
+func (a *Anonymizer) processLargeObjectColumn(
+	ctx context.Context,
+	tx *sql.Tx,
+	col errors.ColumnRef,
+	dataType string,
+	pattern string,
+	tuning batchTuning,
+	progress func(processed int64),
+) (*ProcessResult, error) {
+	gen, ok := a.generators.GetForColumn(pattern, col)
+	if !ok {
+		return nil, fmt.Errorf("unknown pattern %q for column %s", pattern,
+			col.String())
+	}
+	tr := a.newColumnTrace(col)
+
+	processor := NewLargeObjectColumnProcessor(tx, col, dataType,
+		tr.wrap(gen, pattern), tuning.batchSize, a.quiet)
+
+	processor.batchHook = a.batchHook(col)
+	processor.trace = tr
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
+
+// processDeriveColumn processes a column derived from other columns of
+// the same row.
+func (a *Anonymizer) processDeriveColumn(
+	ctx context.Context,
+	tx *sql.Tx,
+	col errors.ColumnRef,
+	dataType string,
+	colConfig config.ColumnConfig,
+	tuning batchTuning,
+	progress func(processed int64),
+) (*ProcessResult, error) {
+	derivation, err := colConfig.Derivation()
+	if err != nil {
+		return nil, err
+	}
+
+	processor := NewDeriveColumnProcessor(tx, col, dataType, derivation,
+		a.generators, a.dictionary, tuning.batchSize)
+
+	processor.batchHook = a.batchHook(col)
+	processor.tuning = tuning
+
+	return processor.Process(ctx, progress)
+}
+
+// compositeFieldIndex returns the position of a field in a composite type,
+// preferring an exact match and falling back to a case-insensitive one
+// since configuration keys may be lowercased. Returns -1 if not found.
+func compositeFieldIndex(fields []string, name string) int {
+	for i, f := range fields {
+		if f == name {
+			return i
+		}
+	}
+	for i, f := range fields {
+		if strings.EqualFold(f, name) {
+			return i
+		}
+	}
+	return -1
 }
@@ -0,0 +1,64 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/audit"
+	"github.com/pgedge/pgedge-anonymizer/internal/stats"
+)
+
+// runAudited anonymizes the configured database and, if auditing is
+// enabled, records the outcome whether or not the run succeeded. A
+// committed run whose record cannot be written is reported as an error,
+// since the evidence of it is missing. Columns anonymized much more slowly
+// than expected are warned of, with the expected rates learned from prior
+// runs before this one is recorded.
+func (a *Anonymizer) runAudited(ctx context.Context) (*stats.Stats, error) {
+	expected := a.expectedThroughput(ctx)
+
+	if !a.config.Audit.Enabled() {
+		result, err := a.run(ctx)
+		if err == nil {
+			a.checkThroughput(result, expected)
+		}
+		return result, err
+	}
+
+	rec, err := audit.NewRecord(a.config, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result, runErr := a.run(ctx)
+	rec.Finish(result, runErr, time.Now())
+	if runErr == nil {
+		a.checkThroughput(result, expected)
+	}
+
+	// Record cancelled runs too
+	auditErr := audit.Write(context.WithoutCancel(ctx), a.config.Audit,
+		&a.config.Database, rec)
+
+	switch {
+	case auditErr == nil:
+		return result, runErr
+	case runErr != nil:
+		return nil, fmt.Errorf("%w (writing the audit record also failed: %v)",
+			runErr, auditErr)
+	default:
+		return nil, fmt.Errorf("anonymization was committed, but the audit "+
+			"record could not be written: %w", auditErr)
+	}
+}
@@ -0,0 +1,141 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+)
+
+// rowTransform anonymizes the value of a row, returning the value to write
+// in its place and the number of values anonymized in it; nothing is
+// written for a row in which none are. An error stops the column.
+type rowTransform func(row database.RowData) (string, int, error)
+
+// batchLoop reads the rows of a column in batches, anonymizes each with a
+// transform, and writes their new values, for the processors that
+// anonymize each row on its own.
+type batchLoop struct {
+	batch     *database.BatchProcessor
+	batchHook batchHookFunc
+	trace     *columnTrace
+
+	// inPlace is set for columns whose values are left as they are, as
+	// the transform anonymizes what they refer to, such as large objects
+	inPlace bool
+}
+
+// run opens the cursor of the loop's batch processor, which must be set
+// up, and passes each row with a value to transform, writing the values
+// it returns after each batch. The batch hooks are called around each
+// batch, and progress is reported after it. Any updates staged by the
+// copy strategy are written once every row has been read.
+func (l *batchLoop) run(ctx context.Context, transform rowTransform,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	result := &ProcessResult{}
+	err := fetchBatches(ctx, l.batch, func(rows []database.RowData) error {
+		if err := l.batchHook.call(ctx, HookBeforeBatch, len(rows)); err != nil {
+			return err
+		}
+
+		updates := make(map[string]string)
+		for _, row := range rows {
+			l.trace.setRow(row.CTID)
+
+			// Skip empty values
+			if row.Value == "" {
+				continue
+			}
+
+			value, anonymized, err := transform(row)
+			if err != nil {
+				return err
+			}
+			if anonymized > 0 {
+				if !l.inPlace {
+					updates[row.CTID] = value
+				}
+				result.ValuesAnonymized += int64(anonymized)
+			}
+		}
+
+		// Apply batch updates
+		if len(updates) > 0 {
+			if err := l.batch.UpdateBatch(ctx, updates); err != nil {
+				return err
+			}
+		}
+
+		result.RowsProcessed += int64(len(rows))
+
+		if err := l.batchHook.call(ctx, HookAfterBatch, len(rows)); err != nil {
+			return err
+		}
+		if err := l.batch.EndBatch(ctx); err != nil {
+			return err
+		}
+
+		// Report progress
+		if progress != nil {
+			progress(result.RowsProcessed)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Write any updates staged by the copy strategy
+	if err := l.batch.Finish(ctx); err != nil {
+		return nil, err
+	}
+
+	result.MaxStatementBytes = l.batch.MaxStatementBytes()
+	result.Phases = l.batch.PhaseTimes()
+	return result, nil
+}
+
+// fetchBatches opens the cursor of a batch processor and passes each batch
+// of rows read from it to fn, until every row has been read, fn fails, or
+// the context is cancelled. The cursor is closed once the rows are read.
+func fetchBatches(ctx context.Context, batch *database.BatchProcessor,
+	fn func(rows []database.RowData) error) error {
+
+	if err := batch.OpenCursor(ctx); err != nil {
+		return err
+	}
+	defer func() { _ = batch.CloseCursor(ctx) }()
+
+	for {
+		// Check for cancellation
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		rows, err := batch.FetchBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			break // No more rows
+		}
+
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+
+	return batch.CloseCursor(ctx)
+}
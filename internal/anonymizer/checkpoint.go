@@ -0,0 +1,108 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/audit"
+	"github.com/pgedge/pgedge-anonymizer/internal/checkpoint"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// MaxDurationError is returned when a run reaches its max_duration before
+// it finishes. The tables committed before then stay anonymized, and are
+// recorded in the checkpoint file, so that running the same configuration
+// again resumes with the remaining tables.
+type MaxDurationError struct {
+	MaxDuration time.Duration
+
+	// Committed lists the tables committed by the run, and Partial those
+	// some of whose batches were committed.
+	Committed []string
+	Partial   []string
+
+	// Remaining lists the tables left to anonymize, including any
+	// partially committed.
+	Remaining []string
+
+	// Checkpoint is the checkpoint file the committed tables are recorded
+	// in.
+	Checkpoint string
+}
+
+// Error describes where the run stopped and how to resume it.
+func (e *MaxDurationError) Error() string {
+	msg := fmt.Sprintf("stopped after reaching the max_duration of %s "+
+		"with %d tables committed", e.MaxDuration, len(e.Committed))
+	if len(e.Partial) > 0 {
+		msg += " and " + strings.Join(e.Partial, ", ") + " partially committed"
+	}
+	return fmt.Sprintf("%s; run again to resume from %s with the remaining "+
+		"tables: %s", msg, e.Checkpoint, strings.Join(e.Remaining, ", "))
+}
+
+// openCheckpoint returns the checkpoint of an earlier run to resume, or a
+// new one, if a checkpoint file is configured. A checkpoint written with
+// another database or configuration is an error, as its tables may not
+// have been anonymized as configured.
+func (a *Anonymizer) openCheckpoint() (*checkpoint.Checkpoint, error) {
+	path := a.config.CheckpointFile
+	if path == "" {
+		return nil, nil
+	}
+
+	// The time limit may differ between the runs
+	cfg := *a.config
+	cfg.MaxDuration = ""
+	hash, err := audit.ConfigHash(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := checkpoint.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return checkpoint.New(a.config.Database.DatabaseName(), hash), nil
+	}
+	if c.Database != a.config.Database.DatabaseName() || c.ConfigHash != hash {
+		return nil, fmt.Errorf("checkpoint file %s was written by a run with "+
+			"another database or configuration; remove it to start again",
+			path)
+	}
+
+	if !a.quiet && len(c.Committed) > 0 {
+		fmt.Printf("Resuming from %s, skipping %d committed tables\n", path,
+			len(c.Committed))
+	}
+	return c, nil
+}
+
+// resumeColumns returns the columns, in processing order, of the tables
+// the checkpoint does not record as committed.
+func resumeColumns(ordered []errors.ColumnRef,
+	c *checkpoint.Checkpoint) []errors.ColumnRef {
+
+	if c == nil {
+		return ordered
+	}
+	var remaining []errors.ColumnRef
+	for _, col := range ordered {
+		if !c.IsCommitted(col.Schema + "." + col.Table) {
+			remaining = append(remaining, col)
+		}
+	}
+	return remaining
+}
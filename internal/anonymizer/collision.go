@@ -0,0 +1,108 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+)
+
+// collisionPolicy is how a column with a unique constraint makes a
+// generated value unique when it has already been given to another value.
+type collisionPolicy struct {
+	retries  int    // Times a fresh value is generated
+	strategy string // A config.Collision value, applied after the retries
+
+	// sequence is the last number taken from the column's sequence
+	sequence int64
+}
+
+// newCollisionPolicy returns the collision policy a column configures.
+func newCollisionPolicy(col config.ColumnConfig) *collisionPolicy {
+	policy := &collisionPolicy{
+		retries:  config.DefaultCollisionRetries,
+		strategy: col.OnCollision,
+	}
+	if col.CollisionRetries != nil {
+		policy.retries = *col.CollisionRetries
+	}
+	if policy.strategy == "" {
+		policy.strategy = config.CollisionSuffix
+	}
+	return policy
+}
+
+// setUnique maps an original value to a generated one in the dictionary,
+// making the generated value unique if it collides: by generating fresh
+// values up to the policy's number of retries, then by the policy's
+// strategy. It returns the value mapped, and counts collisions in result.
+func (p *ColumnProcessor) setUnique(value, anonymized string,
+	result *ProcessResult) (string, error) {
+
+	if p.dictionary.SetUnique(value, anonymized) {
+		return anonymized, nil
+	}
+	result.Collisions++
+
+	policy := p.collision
+	if policy == nil {
+		policy = newCollisionPolicy(config.ColumnConfig{})
+		p.collision = policy
+	}
+
+	for range policy.retries {
+		anonymized = p.generator.Generate(value)
+		if p.dictionary.SetUnique(value, anonymized) {
+			return anonymized, nil
+		}
+	}
+	result.CollisionFallbacks++
+
+	base := anonymized
+	switch policy.strategy {
+	case config.CollisionError:
+		return "", fmt.Errorf("generated value collides with another after "+
+			"%d retries, and on_collision is '%s'", policy.retries,
+			config.CollisionError)
+	case config.CollisionSequence:
+		for range maxCollisionRetries {
+			policy.sequence++
+			anonymized = sequenceValue(base, policy.sequence)
+			if p.dictionary.SetUnique(value, anonymized) {
+				return anonymized, nil
+			}
+		}
+	default:
+		for i := 1; i <= maxCollisionRetries; i++ {
+			anonymized = addUniqueSuffix(base, i)
+			if p.dictionary.SetUnique(value, anonymized) {
+				return anonymized, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("failed to generate unique value after %d attempts",
+		maxCollisionRetries)
+}
+
+// sequenceValue returns a number of a column's sequence, in place of a
+// colliding value: zero-padded to the value's length if the value is a
+// number, so that it keeps its form, and otherwise as it is.
+func sequenceValue(value string, n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if value != "" && strings.Trim(value, "0123456789") == "" &&
+		len(s) < len(value) {
+		return strings.Repeat("0", len(value)-len(s)) + s
+	}
+	return s
+}
@@ -0,0 +1,235 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// typeSampleSize is the number of values generated to test whether a
+// pattern's values suit a column's data type.
+const typeSampleSize = 20
+
+// CheckColumnTypes checks that the configured columns are of data types
+// their patterns can be written to, so that a misconfigured column is
+// reported before any data is changed rather than failing part way
+// through a run. A column with json_paths must be of type json or jsonb,
+// or be a text column with treat_as_json set. A column with a pattern
+// must accept the pattern's values: a date or timestamp column needs a
+// pattern generating dates, a numeric column one generating numbers, and
+// an inet or cidr column one generating IP addresses. A column whose
+// distribution is preserved must be numeric, without a unique constraint,
+// as values drawn alike for several rows would collide. A column of
+// documents must be of type bytea. It returns a validation error listing
+// the columns and patterns that do not match.
+func CheckColumnTypes(ctx context.Context,
+	validator *database.SchemaValidator, generators *generator.Manager,
+	colConfigs []config.ColumnConfig) error {
+
+	var invalidJSON, invalidDistribution, invalidDocument []errors.ColumnRef
+	var mismatches []string
+	for _, colConfig := range colConfigs {
+		if colConfig.IsDistributionColumn() {
+			col, err := errors.ParseColumnRef(colConfig.Column)
+			if err != nil {
+				return err
+			}
+			ok, err := distributionSuitsColumn(ctx, validator, col)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				invalidDistribution = append(invalidDistribution, col)
+			}
+			continue
+		}
+		if !colConfig.IsJSONColumn() && colConfig.Pattern == "" {
+			continue
+		}
+
+		col, err := errors.ParseColumnRef(colConfig.Column)
+		if err != nil {
+			return err
+		}
+		dataType, err := validator.GetColumnDataType(ctx, col)
+		if err != nil {
+			return err
+		}
+
+		if colConfig.IsJSONColumn() {
+			if !jsonColumnType(dataType, colConfig.TreatAsJSON) {
+				invalidJSON = append(invalidJSON, col)
+			}
+			continue
+		}
+		if colConfig.IsDocumentColumn() {
+			if dataType != "bytea" {
+				invalidDocument = append(invalidDocument, col)
+			}
+			continue
+		}
+
+		gen, ok := generators.Get(colConfig.Pattern)
+		if !ok {
+			continue
+		}
+		if !patternSuitsType(gen, dataType,
+			generators.IsPure(colConfig.Pattern)) {
+			mismatches = append(mismatches, fmt.Sprintf("%s (%s) with %s",
+				col.String(), dataType, colConfig.Pattern))
+		}
+	}
+
+	if len(invalidJSON) > 0 {
+		return errors.NewValidationError("json_paths require a column of "+
+			"type json or jsonb, or a text column with treat_as_json set",
+			invalidJSON)
+	}
+	if len(invalidDistribution) > 0 {
+		return errors.NewValidationError("preserve_distribution requires a "+
+			"numeric column without a unique constraint", invalidDistribution)
+	}
+	if len(invalidDocument) > 0 {
+		return errors.NewValidationError("document requires a column of "+
+			"type bytea", invalidDocument)
+	}
+	if len(mismatches) > 0 {
+		return errors.NewValidationError(
+			"patterns generate values the columns' data types do not accept: "+
+				strings.Join(mismatches, ", "), nil)
+	}
+	return nil
+}
+
+// jsonColumnType returns true if json_paths may be used on a column of the
+// given data type.
+func jsonColumnType(dataType string, treatAsJSON bool) bool {
+	switch dataType {
+	case "json", "jsonb":
+		return true
+	case "text", "character varying", "character":
+		return treatAsJSON
+	default:
+		return false
+	}
+}
+
+// distributionSuitsColumn returns true if a column's distribution can be
+// preserved: it is of a numeric type, and no unique constraint covers it.
+func distributionSuitsColumn(ctx context.Context,
+	validator *database.SchemaValidator, col errors.ColumnRef) (bool, error) {
+
+	dataType, err := validator.GetColumnDataType(ctx, col)
+	if err != nil {
+		return false, err
+	}
+	switch dataType {
+	case "smallint", "integer", "bigint", "numeric", "real",
+		"double precision":
+	default:
+		return false, nil
+	}
+	unique, err := validator.HasUniqueConstraint(ctx, col)
+	if err != nil {
+		return false, err
+	}
+	return !unique, nil
+}
+
+// patternSuitsType returns true if the values of a generator can be
+// written to a column of the given data type. Only date, numeric, and
+// network types are checked; any value can be written to a text column,
+// and other types, such as enums and domains, cannot be checked in
+// advance. Only pure generators are sampled, as generating values may
+// otherwise run commands, record errors, or use up values; others are
+// assumed to suit the column.
+func patternSuitsType(gen generator.Generator, dataType string,
+	pure bool) bool {
+
+	var parse func(string) bool
+	switch dataType {
+	case "date", "timestamp without time zone", "timestamp with time zone":
+		if _, ok := gen.(*generator.EpochGenerator); ok {
+			// Epoch timestamps are integers, not dates
+			return false
+		}
+		if info := generator.Describe(gen); info.Category == generator.CategoryDate {
+			return true
+		}
+		if f, ok := gen.(*generator.FormatGenerator); ok {
+			// The date format must suit the server's DateStyle, which
+			// is not known here
+			return f.GeneratesDates()
+		}
+		parse = isDate
+	case "smallint", "integer", "bigint":
+		parse = isInteger
+	case "numeric", "real", "double precision":
+		parse = isNumber
+	case "inet", "cidr":
+		parse = isIPAddress
+	default:
+		return true
+	}
+
+	if !pure {
+		return true
+	}
+	for i := 0; i < typeSampleSize; i++ {
+		if !parse(gen.Generate("")) {
+			return false
+		}
+	}
+	return true
+}
+
+// isDate returns true if s is an ISO 8601 date or timestamp.
+func isDate(s string) bool {
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04:05",
+		time.RFC3339} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isInteger returns true if s is an integer.
+func isInteger(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+// isNumber returns true if s is a number.
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// isIPAddress returns true if s is an IP address, with or without a
+// netmask.
+func isIPAddress(s string) bool {
+	if net.ParseIP(s) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
@@ -0,0 +1,191 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// TestTypeParsers tests the checks of the values generated for date,
+// numeric, and network columns
+func TestTypeParsers(t *testing.T) {
+	tests := []struct {
+		value   string
+		date    bool
+		integer bool
+		number  bool
+		ip      bool
+	}{
+		{value: "2024-02-29", date: true},
+		{value: "2024-02-30"},
+		{value: "2024-02-29 13:45:00", date: true},
+		{value: "2024-02-29T13:45:00Z", date: true},
+		{value: "2024-02-29T13:45:00+02:00", date: true},
+		{value: "29/02/2024"},
+		{value: "42", integer: true, number: true},
+		{value: "-9223372036854775808", integer: true, number: true},
+		{value: "9223372036854775808", number: true},
+		{value: "3.14", number: true},
+		{value: "-1e10", number: true},
+		{value: "192.168.0.1", ip: true},
+		{value: "10.0.0.0/8", ip: true},
+		{value: "2001:db8::1", ip: true},
+		{value: "2001:db8::/32", ip: true},
+		{value: "192.168.0.256"},
+		{value: "123-45-6789"},
+		{value: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := isDate(tt.value); got != tt.date {
+				t.Errorf("isDate(%q) = %v, expected %v", tt.value, got, tt.date)
+			}
+			if got := isInteger(tt.value); got != tt.integer {
+				t.Errorf("isInteger(%q) = %v, expected %v", tt.value, got,
+					tt.integer)
+			}
+			if got := isNumber(tt.value); got != tt.number {
+				t.Errorf("isNumber(%q) = %v, expected %v", tt.value, got,
+					tt.number)
+			}
+			if got := isIPAddress(tt.value); got != tt.ip {
+				t.Errorf("isIPAddress(%q) = %v, expected %v", tt.value, got,
+					tt.ip)
+			}
+		})
+	}
+}
+
+// countingGenerator generates words, counting the values it generates.
+type countingGenerator struct {
+	calls atomic.Int32
+}
+
+func (g *countingGenerator) Name() string { return "COUNTING" }
+
+func (g *countingGenerator) Generate(input string) string {
+	g.calls.Add(1)
+	return "word"
+}
+
+// TestCheckColumnTypes tests that the columns whose patterns generate
+// values their data types do not accept are reported, and that only pure
+// generators are sampled
+func TestCheckColumnTypes(t *testing.T) {
+	generators := generator.NewManager()
+	counting := &countingGenerator{}
+	generators.Register(counting)
+
+	tests := []struct {
+		column   string
+		dataType string
+		pattern  string
+		mismatch bool
+	}{
+		{"public.users.born", "date", "EMAIL", true},
+		{"public.users.joined", "timestamp with time zone", "DOB", false},
+		{"public.users.age", "integer", "PERSON_NAME", true},
+		{"public.users.score", "numeric", "IPV4_ADDRESS", true},
+		{"public.users.ip", "inet", "IPV4_ADDRESS", false},
+		{"public.users.net", "cidr", "PERSON_NAME", true},
+		{"public.users.email", "text", "EMAIL", false},
+		{"public.users.total", "bigint", "COUNTING", false},
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	var colConfigs []config.ColumnConfig
+	var want []string
+	for _, tt := range tests {
+		colConfigs = append(colConfigs, config.ColumnConfig{Column: tt.column,
+			Pattern: tt.pattern})
+		parts := strings.Split(tt.column, ".")
+		mock.ExpectQuery(`FROM information_schema.columns`).
+			WithArgs(parts[0], parts[1], parts[2]).
+			WillReturnRows(sqlmock.NewRows([]string{"data_type"}).
+				AddRow(tt.dataType))
+		if tt.mismatch {
+			want = append(want, tt.column+" ("+tt.dataType+") with "+
+				tt.pattern)
+		}
+	}
+
+	err = CheckColumnTypes(context.Background(),
+		database.NewSchemaValidator(db), generators, colConfigs)
+	if err == nil {
+		t.Fatal("expected the mismatched columns to be reported")
+	}
+	if !strings.Contains(err.Error(), strings.Join(want, ", ")) {
+		t.Errorf("expected mismatches %q, got: %v", want, err)
+	}
+	if calls := counting.calls.Load(); calls != 0 {
+		t.Errorf("expected a custom generator not to be sampled, got %d "+
+			"values generated", calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestCheckDocumentColumns tests that columns of documents must be of
+// type bytea, while other bytea columns may use any pattern
+func TestCheckDocumentColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		dataType string
+		document bool
+		wantErr  bool
+	}{
+		{"document", "bytea", true, false},
+		{"document of text", "text", true, true},
+		{"bytea without document", "bytea", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to open sqlmock: %v", err)
+			}
+			defer db.Close()
+			mock.ExpectQuery(`FROM information_schema.columns`).
+				WillReturnRows(sqlmock.NewRows([]string{"data_type"}).
+					AddRow(tt.dataType))
+
+			err = CheckColumnTypes(context.Background(),
+				database.NewSchemaValidator(db), generator.NewManager(),
+				[]config.ColumnConfig{{Column: "public.letters.body",
+					Pattern: "TEXT_SCRUB", Document: tt.document}})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(),
+					"document requires a column of type bytea") {
+					t.Errorf("expected a document type error, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/composite"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// CompositeColumnProcessor processes a composite-typed column for
+// anonymization. It parses each row literal, anonymizes the configured
+// fields, and writes the re-serialized literal back.
+type CompositeColumnProcessor struct {
+	tx         *sql.Tx
+	column     errors.ColumnRef
+	typeName   string
+	generators map[int]generator.Generator // field index -> generator
+	batchSize  int
+	quiet      bool
+	batchHook  batchHookFunc
+	trace      *columnTrace
+
+	// dictionaries maps each field index to the dictionary namespace its
+	// values are kept in
+	dictionaries map[int]*Dictionary
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
+}
+
+// NewCompositeColumnProcessor creates a new composite column processor.
+// typeName is the quoted, schema-qualified composite type, used to cast
+// the updated literals; generators and dictionaries are keyed by the index
+// of each field to anonymize.
+func NewCompositeColumnProcessor(
+	tx *sql.Tx,
+	column errors.ColumnRef,
+	typeName string,
+	generators map[int]generator.Generator,
+	dictionaries map[int]*Dictionary,
+	batchSize int,
+	quiet bool,
+) *CompositeColumnProcessor {
+	return &CompositeColumnProcessor{
+		tx:           tx,
+		column:       column,
+		typeName:     typeName,
+		generators:   generators,
+		dictionaries: dictionaries,
+		batchSize:    batchSize,
+		quiet:        quiet,
+	}
+}
+
+// Process anonymizes the configured fields of every value in the column.
+func (p *CompositeColumnProcessor) Process(ctx context.Context,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	// Values are fetched as row literals
+	batch := database.NewBatchProcessor(p.tx, p.column, p.typeName, p.batchSize)
+	p.tuning.apply(batch)
+
+	loop := &batchLoop{batch: batch, batchHook: p.batchHook, trace: p.trace}
+	return loop.run(ctx, p.anonymize, progress)
+}
+
+// anonymize anonymizes the configured fields of a row literal, returning
+// the literal re-serialized and the number of fields anonymized.
+func (p *CompositeColumnProcessor) anonymize(row database.RowData) (string,
+	int, error) {
+
+	fields, err := composite.Parse(row.Value)
+	if err != nil {
+		// Log error but continue processing other rows
+		if !p.quiet {
+			log.Printf("Warning: failed to process composite value at %s (ctid=%s): %v",
+				p.column, row.CTID, err)
+		}
+		return "", 0, nil
+	}
+
+	valuesAnonymized := 0
+	for i, gen := range p.generators {
+		// Skip NULL and empty fields, like empty simple values
+		if i >= len(fields) || fields[i] == nil || *fields[i] == "" {
+			continue
+		}
+
+		// Check dictionary for existing mapping
+		original := *fields[i]
+		dict := p.dictionaries[i]
+		anonymized, exists := dict.Get(original)
+		if !exists {
+			anonymized = gen.Generate(original)
+			dict.Set(original, anonymized)
+		}
+
+		fields[i] = &anonymized
+		valuesAnonymized++
+	}
+
+	if valuesAnonymized == 0 {
+		return "", 0, nil
+	}
+	return composite.Format(fields), valuesAnonymized, nil
+}
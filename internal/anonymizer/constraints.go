@@ -0,0 +1,245 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/constraint"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// constraintSampleSize is the number of existing values anonymized to test
+// a column's CHECK constraints.
+const constraintSampleSize = 20
+
+// maxConstraintRetries is the number of times a value that violates a
+// CHECK constraint is regenerated before it is repaired.
+const maxConstraintRetries = 10
+
+// constrainedGenerator wraps a generator so that its values satisfy the
+// CHECK constraints on a column, those of its domain, and the labels of
+// its enum type: values that violate a constraint are
+// regenerated and, failing that, repaired (for example truncated to a
+// maximum length). If no satisfying value can be found, the first such
+// failure is reported by Err.
+type constrainedGenerator struct {
+	generator.Generator
+	constraints []*constraint.Constraint
+	err         error
+}
+
+// newConstrainedGenerator wraps a generator with the constraints it must
+// satisfy, returning it unchanged if there are none.
+func newConstrainedGenerator(gen generator.Generator,
+	constraints []*constraint.Constraint) generator.Generator {
+	if len(constraints) == 0 {
+		return gen
+	}
+	return &constrainedGenerator{Generator: gen, constraints: constraints}
+}
+
+// Generate returns a value satisfying the constraints, if one is found.
+func (g *constrainedGenerator) Generate(input string) string {
+	value := g.Generator.Generate(input)
+	for i := 0; i < maxConstraintRetries && g.violated(value) != nil; i++ {
+		value = g.Generator.Generate(input)
+	}
+
+	if g.violated(value) != nil {
+		for _, c := range g.constraints {
+			value = c.Repair(value)
+		}
+	}
+
+	if c := g.violated(value); c != nil && g.err == nil {
+		g.err = fmt.Errorf("could not generate a value satisfying %s "+
+			"(last value %q)", c, value)
+	}
+	return value
+}
+
+// violated returns the first constraint the value violates, or nil.
+func (g *constrainedGenerator) violated(value string) *constraint.Constraint {
+	for _, c := range g.constraints {
+		if !c.Check(value) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Err returns the first constraint failure, or an error from the wrapped
+// generator.
+func (g *constrainedGenerator) Err() error {
+	if g.err != nil {
+		return g.err
+	}
+	if f, ok := g.Generator.(generator.FallibleGenerator); ok {
+		return f.Err()
+	}
+	return nil
+}
+
+// columnConstraints returns the CHECK constraints on a column, followed
+// by those of its domain, and its enum type, if any.
+func columnConstraints(ctx context.Context, validator *database.SchemaValidator,
+	col errors.ColumnRef) ([]database.CheckConstraint, *database.EnumType,
+	error) {
+	constraints, err := validator.GetCheckConstraints(ctx, col)
+	if err != nil {
+		return nil, nil, err
+	}
+	domain, err := validator.GetDomainConstraints(ctx, col)
+	if err != nil {
+		return nil, nil, err
+	}
+	enum, err := validator.GetEnumType(ctx, col)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(constraints, domain...), enum, nil
+}
+
+// enforceableConstraints returns the constraints on a column that can be
+// evaluated while generating values: CHECK constraints that reference
+// only the column and use simple predicates, and the labels of its enum
+// type.
+func enforceableConstraints(col errors.ColumnRef,
+	constraints []database.CheckConstraint,
+	enum *database.EnumType) []*constraint.Constraint {
+	var enforced []*constraint.Constraint
+	for _, con := range constraints {
+		if con.ColumnCount != 1 {
+			continue
+		}
+		column := col.Column
+		if con.Domain != "" {
+			column = "VALUE"
+		}
+		if c, err := constraint.Parse(con.QualifiedName(), con.Expression,
+			column); err == nil {
+			enforced = append(enforced, c)
+		}
+	}
+	if enum != nil {
+		enforced = append(enforced, constraint.NewEnum(enum.Name, enum.Labels))
+	}
+	return enforced
+}
+
+// CheckConstraints looks for CHECK constraints, domain constraints, enum
+// types, and unique expression indexes on a column that anonymized values
+// may violate, and returns a warning for each. For columns anonymized with
+// a single pattern, a sample of the column's values is anonymized, as it
+// would be during a run, and tested against single-column CHECK
+// constraints and the enum's labels, so that violations are reported
+// before any data is changed rather than part way through a run.
+func CheckConstraints(
+	ctx context.Context,
+	validator *database.SchemaValidator,
+	generators *generator.Manager,
+	col errors.ColumnRef,
+	colConfig config.ColumnConfig,
+) ([]string, error) {
+	var warnings []string
+
+	constraints, enum, err := columnConstraints(ctx, validator, col)
+	if err != nil {
+		return nil, err
+	}
+	constrained := len(constraints) > 0 || enum != nil
+
+	var samples []string
+	gen, hasGen := generators.GetForColumn(colConfig.Pattern, col)
+	if constrained && colConfig.IsConstantColumn() {
+		samples = []string{*colConfig.Constant}
+	} else if constrained && colConfig.Pattern != "" && hasGen {
+		values, err := validator.GetSampleValues(ctx, col, constraintSampleSize)
+		if err != nil {
+			return nil, err
+		}
+		gen = newConstrainedGenerator(gen,
+			enforceableConstraints(col, constraints, enum))
+		for _, value := range values {
+			samples = append(samples, gen.Generate(value))
+		}
+	}
+
+	for _, con := range constraints {
+		switch {
+		case colConfig.IsProfileColumn():
+			warnings = append(warnings, fmt.Sprintf(
+				"CHECK constraint %s cannot be tested against values of "+
+					"profile %s: %s", con.QualifiedName(), colConfig.Profile.Profile,
+				con.Expression))
+		case colConfig.IsNullifyColumn():
+			// NULL satisfies every CHECK constraint
+		case colConfig.IsShuffleColumn() && con.ColumnCount <= 1:
+			// Shuffled values are the column's own, which satisfy it
+		case colConfig.IsDistributionColumn() && con.ColumnCount <= 1:
+			// Values are drawn within the range of the column's own, and
+			// regenerated if it rejects them
+		case colConfig.IsDerivedColumn():
+			warnings = append(warnings, fmt.Sprintf(
+				"CHECK constraint %s cannot be tested against values "+
+					"derived from other columns: %s", con.QualifiedName(), con.Expression))
+		case colConfig.Pattern == "" && !colConfig.IsConstantColumn():
+			warnings = append(warnings, fmt.Sprintf(
+				"CHECK constraint %s cannot be tested against anonymized "+
+					"document or field values: %s", con.QualifiedName(), con.Expression))
+		case con.ColumnCount > 1:
+			warnings = append(warnings, fmt.Sprintf(
+				"CHECK constraint %s references other columns and cannot be "+
+					"tested: %s", con.Name, con.Expression))
+		default:
+			violations, err := validator.FindCheckViolations(ctx, col, con,
+				samples)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf(
+					"anonymized values could not be tested against CHECK "+
+						"constraint %s: %v", con.QualifiedName(), err))
+			} else if len(violations) > 0 {
+				warnings = append(warnings, fmt.Sprintf(
+					"CHECK constraint %s (%s) rejects anonymized values such "+
+						"as %q", con.QualifiedName(), con.Expression, violations[0]))
+			}
+		}
+	}
+
+	if enum != nil {
+		labels := constraint.NewEnum(enum.Name, enum.Labels)
+		for _, value := range samples {
+			if !labels.Check(value) {
+				warnings = append(warnings, fmt.Sprintf(
+					"anonymized values such as %q are not labels of %s",
+					value, labels))
+				break
+			}
+		}
+	}
+
+	indexes, err := validator.GetUniqueExpressionIndexes(ctx, col)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range indexes {
+		warnings = append(warnings, fmt.Sprintf(
+			"unique index %s may reject anonymized values that collide "+
+				"once its expression is applied: %s", idx.Name, idx.Definition))
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,82 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// openSource connects to the database copied from in copy mode, checks
+// that it has the columns to anonymize, and starts the read-only
+// transaction every table is copied in, so that the tables copied are
+// consistent with each other. It returns a function closing it.
+func (a *Anonymizer) openSource(ctx context.Context,
+	columns []errors.ColumnRef) (func(), error) {
+
+	if err := a.source.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	missing, err := database.NewSchemaValidator(a.source.DB()).
+		ValidateColumns(ctx, columns)
+	if err != nil {
+		a.source.Close()
+		return nil, err
+	}
+	if len(missing) > 0 {
+		a.source.Close()
+		return nil, errors.NewValidationError(
+			"columns not found in the database copied from", missing)
+	}
+
+	tx, err := a.source.BeginReadOnly(ctx)
+	if err != nil {
+		a.source.Close()
+		return nil, err
+	}
+	return func() {
+		_ = tx.Rollback()
+		a.source.Close()
+	}, nil
+}
+
+// copyTables copies the rows of the tables of a unit from the database
+// copied from, before their columns are anonymized in the same
+// transaction, so that no row is committed before it is anonymized.
+func (a *Anonymizer) copyTables(ctx context.Context, tx *sql.Tx,
+	unit []errors.ColumnRef) error {
+
+	if a.source == nil {
+		return nil
+	}
+	for _, name := range unitTables(unit) {
+		schema, table, _ := strings.Cut(name, ".")
+		c, err := database.PrepareCopy(ctx, tx, schema, table,
+			a.config.CopyTo.Truncate)
+		if err != nil {
+			return err
+		}
+		copied, err := database.CopyTable(ctx, a.source, a.connector, c)
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", name, err)
+		}
+		if !a.quiet {
+			fmt.Printf("Copied %d rows of %s\n", copied, name)
+		}
+	}
+	return nil
+}
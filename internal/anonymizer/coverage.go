@@ -0,0 +1,40 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/scaffold"
+	"github.com/pgedge/pgedge-anonymizer/internal/stats"
+)
+
+// MeasureCoverage returns how many of the database's columns likely to
+// hold personal data, judged from their names as the init command does,
+// are among the configured columns.
+func MeasureCoverage(ctx context.Context, validator *database.SchemaValidator,
+	configured []errors.ColumnRef) (*stats.Coverage, error) {
+
+	columns, err := validator.ListColumns(ctx, "*")
+	if err != nil {
+		return nil, err
+	}
+
+	var likely []errors.ColumnRef
+	for _, tc := range columns {
+		if scaffold.Suggest(tc.Column.Column, tc.DataType) != "" {
+			likely = append(likely, tc.Column)
+		}
+	}
+	return stats.NewCoverage(likely, configured), nil
+}
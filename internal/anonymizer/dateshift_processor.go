@@ -0,0 +1,120 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// dateShiftNamespace is the identity namespace the offsets of entities
+// are stored in.
+const dateShiftNamespace = "date_shift"
+
+// DateShiftColumnProcessor processes a column whose dates are shifted by
+// an offset of the entity each row belongs to, named by the row's entity
+// column. Every date of an entity, in any table, is shifted by the same
+// number of days, so that the intervals between its events are kept.
+type DateShiftColumnProcessor struct {
+	tx         *sql.Tx
+	column     errors.ColumnRef
+	dataType   string
+	entity     string // Column identifying each row's entity
+	shifter    *generator.DateShiftGenerator
+	dictionary *Dictionary
+	batchSize  int
+	batchHook  batchHookFunc
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
+}
+
+// NewDateShiftColumnProcessor creates a new date shift column processor.
+func NewDateShiftColumnProcessor(
+	tx *sql.Tx,
+	column errors.ColumnRef,
+	dataType string,
+	entity string,
+	shifter *generator.DateShiftGenerator,
+	dict *Dictionary,
+	batchSize int,
+) *DateShiftColumnProcessor {
+	return &DateShiftColumnProcessor{
+		tx:         tx,
+		column:     column,
+		dataType:   dataType,
+		entity:     entity,
+		shifter:    shifter,
+		dictionary: dict,
+		batchSize:  batchSize,
+	}
+}
+
+// Process shifts every date in the column by its entity's offset.
+func (p *DateShiftColumnProcessor) Process(ctx context.Context,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
+	p.tuning.apply(batch)
+	batch.SetIdentity(p.entity)
+
+	entities := make(map[string]bool)
+	loop := &batchLoop{batch: batch, batchHook: p.batchHook}
+	result, err := loop.run(ctx, func(row database.RowData) (string, int, error) {
+		days, err := p.offset(row.Identity)
+		if err != nil {
+			return "", 0, err
+		}
+		shifted, err := p.shifter.Shift(row.Value, days)
+		if err != nil {
+			return "", 0, err
+		}
+		p.dictionary.RecordOriginal(row.Value)
+
+		entities[row.Identity] = true
+		return shifted, 1, nil
+	}, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	result.UniqueValues = int64(len(entities))
+	return result, nil
+}
+
+// offset returns the number of days an entity's dates are shifted by,
+// choosing and storing one if the entity has none yet. A row whose entity
+// is NULL belongs to no known entity, and is shifted by an offset of its
+// own.
+func (p *DateShiftColumnProcessor) offset(entity string) (int, error) {
+	if entity == "" {
+		return p.shifter.Offset(), nil
+	}
+
+	if stored, ok := p.dictionary.GetIdentity(dateShiftNamespace,
+		entity); ok {
+		days, err := strconv.Atoi(stored)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode date offset: %w", err)
+		}
+		return days, nil
+	}
+
+	days := p.shifter.Offset()
+	p.dictionary.SetIdentity(dateShiftNamespace, entity, strconv.Itoa(days))
+	return days, nil
+}
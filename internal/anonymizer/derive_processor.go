@@ -0,0 +1,130 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// DeriveColumnProcessor processes a column whose values are derived from
+// other columns of the same row, such as an email address made from a
+// first and last name. The source columns are anonymized first, so each
+// row's value is derived from their anonymized values.
+type DeriveColumnProcessor struct {
+	tx         *sql.Tx
+	column     errors.ColumnRef
+	dataType   string
+	derivation config.Derivation
+	generators *generator.Manager
+	dictionary *Dictionary
+	batchSize  int
+	batchHook  batchHookFunc
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
+}
+
+// NewDeriveColumnProcessor creates a new derived column processor.
+func NewDeriveColumnProcessor(
+	tx *sql.Tx,
+	column errors.ColumnRef,
+	dataType string,
+	derivation config.Derivation,
+	generators *generator.Manager,
+	dict *Dictionary,
+	batchSize int,
+) *DeriveColumnProcessor {
+	return &DeriveColumnProcessor{
+		tx:         tx,
+		column:     column,
+		dataType:   dataType,
+		derivation: derivation,
+		generators: generators,
+		dictionary: dict,
+		batchSize:  batchSize,
+	}
+}
+
+// Process replaces every value in the column with one derived from the
+// row's source columns.
+func (p *DeriveColumnProcessor) Process(ctx context.Context,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
+	p.tuning.apply(batch)
+	batch.SetSources(p.derivation.From...)
+
+	derived := make(map[string]bool)
+	loop := &batchLoop{batch: batch, batchHook: p.batchHook}
+	result, err := loop.run(ctx, func(row database.RowData) (string, int, error) {
+		value, err := p.generators.Derive(p.derivation.Kind, row.Sources,
+			row.Value)
+		if err != nil {
+			return "", 0, err
+		}
+		p.dictionary.RecordOriginal(row.Value)
+
+		derived[value] = true
+		return value, 1, nil
+	}, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	result.UniqueValues = int64(len(derived))
+	return result, nil
+}
+
+// orderDerived moves each derived column to just after the last of its
+// source columns in the processing order, so that it is derived from
+// their anonymized values. Sources are never derived themselves, so one
+// pass suffices.
+func orderDerived(ordered []errors.ColumnRef,
+	configs map[string]config.ColumnConfig) []errors.ColumnRef {
+
+	result := make([]errors.ColumnRef, 0, len(ordered))
+	var derived []errors.ColumnRef
+	for _, col := range ordered {
+		if configs[col.String()].IsDerivedColumn() {
+			derived = append(derived, col)
+		} else {
+			result = append(result, col)
+		}
+	}
+
+	for _, col := range derived {
+		d, _ := configs[col.String()].Derivation()
+		at := -1
+		for i, c := range result {
+			if c.Schema == col.Schema && c.Table == col.Table &&
+				slices.Contains(d.From, c.Column) {
+				at = i
+			}
+		}
+		if at < 0 {
+			// No sources configured; keep it with its table
+			for i, c := range result {
+				if c.Schema == col.Schema && c.Table == col.Table {
+					at = i
+				}
+			}
+		}
+		result = slices.Insert(result, at+1, col)
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/stats"
+)
+
+// refreshDerivedColumns brings tsvector columns derived from anonymized
+// columns up to date, so that full text search cannot be used to recover
+// the original values. Generated columns and columns maintained by an
+// enabled tsvector trigger are updated by PostgreSQL as the sources are
+// anonymized; columns whose trigger is disabled, or kept from firing by
+// disable_triggers, are recomputed here; and
+// columns whose derivation is unknown are reported as possibly stale.
+func (a *Anonymizer) refreshDerivedColumns(
+	ctx context.Context,
+	tx *sql.Tx,
+	validator *database.SchemaValidator,
+	anonymized []errors.ColumnRef,
+	collector *stats.Collector,
+) error {
+	// Group anonymized columns by table, keeping processing order
+	type tableRef struct{ schema, table string }
+	var tables []tableRef
+	columnsByTable := make(map[tableRef]map[string]bool)
+	for _, col := range anonymized {
+		t := tableRef{col.Schema, col.Table}
+		if columnsByTable[t] == nil {
+			columnsByTable[t] = make(map[string]bool)
+			tables = append(tables, t)
+		}
+		columnsByTable[t][col.Column] = true
+	}
+
+	for _, t := range tables {
+		tsvColumns, err := validator.GetTSVectorColumns(ctx, t.schema, t.table)
+		if err != nil {
+			return err
+		}
+
+		columns := columnsByTable[t]
+		for _, tsv := range tsvColumns {
+			if columns[tsv.Column.Column] {
+				continue // Anonymized directly
+			}
+
+			derived := stats.DerivedColumnStats{Column: tsv.Column}
+			switch {
+			case len(tsv.Sources) == 0:
+				derived.Status = "tsvector source unknown, may be stale"
+				derived.Stale = true
+			case !anySource(tsv.Sources, columns):
+				continue // Not derived from anonymized columns
+			case tsv.Generated:
+				derived.Status = "regenerated (generated column)"
+			case tsv.TriggerEnabled && a.config.TriggerMode() == "":
+				derived.Status = fmt.Sprintf("updated by trigger %s", tsv.Trigger)
+			default:
+				count, err := database.RefreshTSVector(ctx, tx, tsv)
+				if err != nil {
+					return err
+				}
+				derived.Status = fmt.Sprintf(
+					"refreshed %d rows (trigger %s is disabled)", count, tsv.Trigger)
+			}
+
+			if derived.Stale && !a.quiet {
+				log.Printf("Warning: %s is a tsvector column in a table with "+
+					"anonymized columns, but is not maintained by a trigger or "+
+					"generated; it may still contain original values",
+					tsv.Column.String())
+			}
+			collector.RecordDerived(derived)
+		}
+	}
+
+	return nil
+}
+
+// anySource returns true if any of the source columns was anonymized.
+func anySource(sources []string, anonymized map[string]bool) bool {
+	for _, s := range sources {
+		if anonymized[s] {
+			return true
+		}
+	}
+	return false
+}
@@ -16,15 +16,25 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	_ "modernc.org/sqlite" // SQLite driver
+
+	"github.com/pgedge/pgedge-anonymizer/internal/bloom"
+	"github.com/pgedge/pgedge-anonymizer/internal/fingerprint"
+	"github.com/pgedge/pgedge-anonymizer/internal/stats"
 )
 
 // DefaultCacheSize is the default number of entries in the LRU cache.
 const DefaultCacheSize = 1000000 // 1 million entries
 
+// usedFalsePositiveRate is the rate at which the filter of used values
+// sends a lookup of an unused value to the disk cache.
+const usedFalsePositiveRate = 0.01
+
 // Dictionary maintains consistent value mappings for anonymization.
 // It uses a two-tier strategy:
 //   - Tier 1: LRU in-memory cache for fast lookups
@@ -32,49 +42,239 @@ const DefaultCacheSize = 1000000 // 1 million entries
 //
 // It also tracks reverse mappings (anonymized → original) to ensure
 // uniqueness when columns have unique constraints.
+//
+// Mappings are kept in namespaces: a value is mapped consistently by the
+// columns sharing a namespace, and independently of the others. The
+// Dictionary created by NewDictionary uses the global namespace, and
+// Namespace returns a view of the same dictionary using another.
 type Dictionary struct {
+	*dictionaryState
+
+	// namespace prefixes the keys of mappings; empty for the global
+	// namespace
+	namespace string
+}
+
+// dictionaryState is the state shared by the namespaces of a dictionary.
+type dictionaryState struct {
 	mu       sync.RWMutex
 	cache    *lru.Cache[string, string]
 	reverse  map[string]bool // tracks used anonymized values
 	diskDB   *sql.DB
 	diskPath string
+
+	// used is a Bloom filter over the anonymized values stored on disk,
+	// so that a value not in use, as most newly generated values are not,
+	// is known to be unused without querying the disk cache
+	used *bloom.Filter
+
+	// identities maps a profile's identities to their personas, kept
+	// apart from the value mappings as they are not anonymized values
+	identities *lru.Cache[string, string]
+
+	// fingerprints, if set, records each original value mapped
+	fingerprints *fingerprint.Writer
+
+	// inMemory is true if the spillover database is kept in memory
+	inMemory bool
+
+	// keep is true if the database file is kept after the dictionary is
+	// closed
+	keep bool
+
+	// Lookups answered from memory, from disk, and of unmapped values,
+	// and mappings evicted from memory
+	cacheHits atomic.Int64
+	diskHits  atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// DictionaryOptions tunes the memory and disk a dictionary uses.
+type DictionaryOptions struct {
+	// CacheSize is the number of mappings kept in memory; zero or less
+	// uses DefaultCacheSize.
+	CacheSize int
+
+	// InMemory keeps the spillover database in memory rather than in a
+	// temporary file.
+	InMemory bool
+
+	// JournalMode and Synchronous set the SQLite pragmas of the
+	// spillover file, if not empty.
+	JournalMode string
+	Synchronous string
+
+	// Path is a file to keep the mappings in, opened with the mappings
+	// of earlier runs if it exists, and kept when the dictionary is
+	// closed; empty uses a temporary file.
+	Path string
 }
 
 // NewDictionary creates a new value dictionary.
 func NewDictionary(cacheSize int) (*Dictionary, error) {
-	if cacheSize <= 0 {
-		cacheSize = DefaultCacheSize
+	return NewDictionaryWithOptions(DictionaryOptions{CacheSize: cacheSize})
+}
+
+// NewDictionaryWithOptions creates a new value dictionary tuned by the
+// options.
+func NewDictionaryWithOptions(opts DictionaryOptions) (*Dictionary, error) {
+	if opts.CacheSize <= 0 {
+		opts.CacheSize = DefaultCacheSize
 	}
+	cacheSize := opts.CacheSize
 
-	cache, err := lru.New[string, string](cacheSize)
+	state := &dictionaryState{inMemory: opts.InMemory}
+	cache, err := lru.NewWithEvict(cacheSize, func(string, string) {
+		state.evictions.Add(1)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create LRU cache: %w", err)
 	}
 
-	d := &Dictionary{
-		cache:   cache,
-		reverse: make(map[string]bool),
+	identities, err := lru.New[string, string](cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LRU cache: %w", err)
 	}
 
+	state.cache = cache
+	state.reverse = make(map[string]bool)
+	state.used = bloom.New(cacheSize, usedFalsePositiveRate)
+	state.identities = identities
+	d := &Dictionary{dictionaryState: state}
+
 	// Initialize SQLite spillover database
-	if err := d.initDiskCache(); err != nil {
+	if err := d.initDiskCache(opts); err != nil {
 		return nil, err
 	}
 
 	return d, nil
 }
 
-// initDiskCache creates a temporary SQLite database for spillover.
-func (d *Dictionary) initDiskCache() error {
-	// Create temp file for SQLite
-	tmpDir := os.TempDir()
-	d.diskPath = filepath.Join(tmpDir,
-		fmt.Sprintf("pgedge-anon-%d.db", os.Getpid()))
+// Namespace returns a view of the dictionary whose mappings are kept in
+// the named namespace, apart from those of the global namespace and of
+// other namespaces; an empty name is the global namespace. Anonymized
+// values in use are tracked across all namespaces.
+func (d *Dictionary) Namespace(name string) *Dictionary {
+	return &Dictionary{dictionaryState: d.dictionaryState, namespace: name}
+}
+
+// Partition returns a view of the dictionary whose mappings are kept apart
+// for each value of a partition column, such as a tenant, within the
+// dictionary's namespace, so that an equal value is given a different
+// replacement in each partition.
+func (d *Dictionary) Partition(value string) *Dictionary {
+	return d.Namespace(d.namespace + "\x00partition:" + value)
+}
+
+// key returns the key an original value is mapped under in the
+// dictionary's namespace.
+func (d *Dictionary) key(original string) string {
+	if d.namespace == "" {
+		return original
+	}
+	return d.namespace + "\x00" + original
+}
+
+// tempDirs returns the directories tried, in order, for the disk cache:
+// the system temporary directory (TMPDIR, or TMP or TEMP on Windows), then
+// the user's cache directory, for services on Windows whose temporary
+// directory may not be writable.
+func tempDirs() []string {
+	dirs := []string{os.TempDir()}
+	if dir, err := os.UserCacheDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "pgedge-anonymizer"))
+	}
+	return dirs
+}
+
+// createDiskFile creates an empty file for the disk cache in the first
+// writable temporary directory.
+func createDiskFile() (string, error) {
+	var errs []string
+	for _, dir := range tempDirs() {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		f, err := os.CreateTemp(dir, "pgedge-anon-*.db")
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		f.Close()
+		return f.Name(), nil
+	}
+	return "", fmt.Errorf("failed to create disk cache file: %s",
+		strings.Join(errs, "; "))
+}
+
+// keepPrivate creates the file a dictionary is kept in, if it does not
+// exist, and restricts it and any journal files beside it to their owner,
+// as they hold the original values. SQLite creates journal files with
+// the permissions of the database file.
+func keepPrivate(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create dictionary: %w", err)
+	}
+	f.Close()
+
+	for _, file := range []string{path, path + "-wal", path + "-shm",
+		path + "-journal"} {
+		if err := os.Chmod(file, 0600); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to restrict dictionary: %w", err)
+		}
+	}
+	return nil
+}
+
+// initDiskCache creates a temporary SQLite database for spillover, in
+// memory if the options keep it there, or opens the file the options
+// keep the mappings in.
+func (d *dictionaryState) initDiskCache(opts DictionaryOptions) error {
+	dsn := ":memory:"
+	switch {
+	case opts.Path != "":
+		if err := keepPrivate(opts.Path); err != nil {
+			return err
+		}
+		d.diskPath = opts.Path
+		d.keep = true
+		dsn = opts.Path
+	case !opts.InMemory:
+		// Create temp file for SQLite, with a unique name so that several
+		// dictionaries, or runs, can be open at once
+		path, err := createDiskFile()
+		if err != nil {
+			return err
+		}
+		d.diskPath = path
+		dsn = path
+	}
 
-	db, err := sql.Open("sqlite", d.diskPath)
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open disk cache: %w", err)
 	}
+	if opts.InMemory {
+		// Each connection to :memory: opens a database of its own
+		db.SetMaxOpenConns(1)
+	}
+
+	for _, pragma := range []struct{ name, value string }{
+		{"journal_mode", opts.JournalMode},
+		{"synchronous", opts.Synchronous},
+	} {
+		if pragma.value == "" {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf("PRAGMA %s = %s", pragma.name,
+			pragma.value)); err != nil {
+			db.Close()
+			return fmt.Errorf("failed to set %s: %w", pragma.name, err)
+		}
+	}
 
 	// Create table for value mappings
 	_, err = db.Exec(`
@@ -106,17 +306,68 @@ func (d *Dictionary) initDiskCache() error {
 		return fmt.Errorf("failed to create anonymized index: %w", err)
 	}
 
+	// Create table for profile identities
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS identities (
+            identity TEXT PRIMARY KEY,
+            persona TEXT NOT NULL
+        )
+    `)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("failed to create identities table: %w", err)
+	}
+
 	d.diskDB = db
+
+	// Values mapped by earlier runs are in use
+	if d.keep {
+		if err := d.loadUsed(opts.CacheSize); err != nil {
+			db.Close()
+			return err
+		}
+	}
 	return nil
 }
 
+// loadUsed adds the anonymized values of the mappings already on disk to
+// the filter of used values, sized for cacheSize values, resizing it if
+// there are more.
+func (d *dictionaryState) loadUsed(cacheSize int) error {
+	var count int
+	if err := d.diskDB.QueryRow(
+		"SELECT COUNT(*) FROM mappings").Scan(&count); err != nil {
+		return fmt.Errorf("failed to read dictionary: %w", err)
+	}
+	if count > cacheSize {
+		d.used = bloom.New(count+cacheSize, usedFalsePositiveRate)
+	}
+
+	rows, err := d.diskDB.Query("SELECT anonymized FROM mappings")
+	if err != nil {
+		return fmt.Errorf("failed to read dictionary: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var anonymized string
+		if err := rows.Scan(&anonymized); err != nil {
+			return fmt.Errorf("failed to read dictionary: %w", err)
+		}
+		d.used.Add(anonymized)
+	}
+	return rows.Err()
+}
+
 // Get retrieves an anonymized value for the given original.
 // Returns the anonymized value and true if found, empty string and false if not.
 func (d *Dictionary) Get(original string) (string, bool) {
+	key := d.key(original)
+
 	d.mu.RLock()
 	// Check LRU cache first (fast path)
-	if val, ok := d.cache.Get(original); ok {
+	if val, ok := d.cache.Get(key); ok {
 		d.mu.RUnlock()
+		d.cacheHits.Add(1)
 		return val, true
 	}
 	d.mu.RUnlock()
@@ -126,7 +377,8 @@ func (d *Dictionary) Get(original string) (string, bool) {
 	defer d.mu.Unlock()
 
 	// Double-check LRU after acquiring write lock
-	if val, ok := d.cache.Get(original); ok {
+	if val, ok := d.cache.Get(key); ok {
+		d.cacheHits.Add(1)
 		return val, true
 	}
 
@@ -134,19 +386,22 @@ func (d *Dictionary) Get(original string) (string, bool) {
 	var anonymized string
 	err := d.diskDB.QueryRow(
 		"SELECT anonymized FROM mappings WHERE original = ?",
-		original,
+		key,
 	).Scan(&anonymized)
 
 	if err == sql.ErrNoRows {
+		d.misses.Add(1)
 		return "", false
 	}
 	if err != nil {
 		// Log error but don't fail - treat as not found
+		d.misses.Add(1)
 		return "", false
 	}
 
 	// Promote to LRU cache
-	d.cache.Add(original, anonymized)
+	d.diskHits.Add(1)
+	d.cache.Add(key, anonymized)
 	return anonymized, true
 }
 
@@ -160,19 +415,87 @@ func (d *Dictionary) Set(original, anonymized string) {
 
 // setInternal stores a mapping (caller must hold lock).
 func (d *Dictionary) setInternal(original, anonymized string) {
+	key := d.key(original)
+
 	// Add to LRU cache
-	d.cache.Add(original, anonymized)
+	d.cache.Add(key, anonymized)
 
 	// Track in reverse map
 	d.reverse[anonymized] = true
+	d.used.Add(anonymized)
+
+	if d.fingerprints != nil {
+		d.fingerprints.Add(original)
+	}
 
 	// Always store in disk cache for durability
 	_, _ = d.diskDB.Exec(
 		"INSERT OR REPLACE INTO mappings (original, anonymized) VALUES (?, ?)",
-		original, anonymized,
+		key, anonymized,
+	)
+}
+
+// GetIdentity retrieves the value stored for an identity, such as the
+// persona, encoded as JSON, generated for a profile's identity.
+func (d *Dictionary) GetIdentity(profile, identity string) (string, bool) {
+	key := profile + "\x00" + identity
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if val, ok := d.identities.Get(key); ok {
+		return val, true
+	}
+
+	var persona string
+	err := d.diskDB.QueryRow(
+		"SELECT persona FROM identities WHERE identity = ?",
+		key,
+	).Scan(&persona)
+	if err != nil {
+		return "", false
+	}
+
+	// Promote to LRU cache
+	d.identities.Add(key, persona)
+	return persona, true
+}
+
+// SetIdentity stores the value for an identity, such as the persona,
+// encoded as JSON, generated for a profile's identity.
+func (d *Dictionary) SetIdentity(profile, identity, persona string) {
+	key := profile + "\x00" + identity
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.identities.Add(key, persona)
+	_, _ = d.diskDB.Exec(
+		"INSERT OR REPLACE INTO identities (identity, persona) VALUES (?, ?)",
+		key, persona,
 	)
 }
 
+// RecordOriginal records the fingerprint of an original value replaced
+// without a mapping, as profile columns' values are.
+func (d *Dictionary) RecordOriginal(original string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.fingerprints != nil {
+		d.fingerprints.Add(original)
+	}
+}
+
+// RecordFingerprints records the fingerprint of each original value
+// subsequently mapped, so that it can later be verified that none remain.
+func (d *Dictionary) RecordFingerprints(w *fingerprint.Writer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.fingerprints = w
+}
+
 // IsUsed checks if an anonymized value is already in use.
 func (d *Dictionary) IsUsed(anonymized string) bool {
 	d.mu.RLock()
@@ -181,6 +504,12 @@ func (d *Dictionary) IsUsed(anonymized string) bool {
 		d.mu.RUnlock()
 		return true
 	}
+
+	// A value missing from the filter is not on disk either
+	if !d.used.MayContain(anonymized) {
+		d.mu.RUnlock()
+		return false
+	}
 	d.mu.RUnlock()
 
 	// Check disk cache
@@ -222,24 +551,29 @@ func (d *Dictionary) SetUnique(original, anonymized string) bool {
 	// Check if this anonymized value is already used
 	if d.reverse[anonymized] {
 		// Check if it's used by the same original (that's ok)
-		if existing, ok := d.cache.Get(original); ok && existing == anonymized {
+		if existing, ok := d.cache.Get(d.key(original)); ok &&
+			existing == anonymized {
 			return true
 		}
 		return false
 	}
 
-	// Check disk cache for existing usage
+	// Check disk cache for existing usage, unless the filter shows the
+	// value is not there
 	var existingOriginal string
-	err := d.diskDB.QueryRow(
-		"SELECT original FROM mappings WHERE anonymized = ?",
-		anonymized,
-	).Scan(&existingOriginal)
+	err := sql.ErrNoRows
+	if d.used.MayContain(anonymized) {
+		err = d.diskDB.QueryRow(
+			"SELECT original FROM mappings WHERE anonymized = ?",
+			anonymized,
+		).Scan(&existingOriginal)
+	}
 
 	if err == nil {
 		// Found in disk - mark in reverse map
 		d.reverse[anonymized] = true
 		// It's ok if same original
-		if existingOriginal == original {
+		if existingOriginal == d.key(original) {
 			return true
 		}
 		return false
@@ -276,6 +610,28 @@ func (d *Dictionary) DiskSize() (int64, error) {
 	return count, err
 }
 
+// Stats returns how the dictionary has used memory and disk.
+func (d *Dictionary) Stats() *stats.DictionaryStats {
+	s := &stats.DictionaryStats{
+		CacheHits: d.cacheHits.Load(),
+		DiskHits:  d.diskHits.Load(),
+		Misses:    d.misses.Load(),
+		Evictions: d.evictions.Load(),
+		InMemory:  d.inMemory,
+	}
+	s.Mappings, _ = d.DiskSize()
+
+	// A file in WAL mode holds recent writes in a file of its own
+	if d.diskPath != "" {
+		for _, path := range []string{d.diskPath, d.diskPath + "-wal"} {
+			if info, err := os.Stat(path); err == nil {
+				s.DiskBytes += info.Size()
+			}
+		}
+	}
+	return s
+}
+
 // Close cleans up the dictionary resources.
 func (d *Dictionary) Close() error {
 	d.mu.Lock()
@@ -285,9 +641,11 @@ func (d *Dictionary) Close() error {
 		d.diskDB.Close()
 	}
 
-	// Remove the temporary SQLite file
-	if d.diskPath != "" {
-		os.Remove(d.diskPath)
+	// Remove the temporary SQLite file, and any left by its journal
+	if d.diskPath != "" && !d.keep {
+		for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+			os.Remove(d.diskPath + suffix)
+		}
 	}
 
 	return nil
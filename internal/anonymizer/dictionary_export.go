@@ -0,0 +1,160 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Types of the records of an exported dictionary.
+const (
+	exportMapping  = "mapping"
+	exportIdentity = "identity"
+)
+
+// exportRecord is a line of an exported dictionary: the mapping of an
+// original value, in a namespace, to its anonymized value, or the persona
+// of a profile's identity.
+type exportRecord struct {
+	Type       string `json:"type"`
+	Namespace  string `json:"namespace,omitempty"`
+	Original   string `json:"original,omitempty"`
+	Anonymized string `json:"anonymized,omitempty"`
+	Profile    string `json:"profile,omitempty"`
+	Identity   string `json:"identity,omitempty"`
+	Persona    string `json:"persona,omitempty"`
+}
+
+// Export writes every mapping and identity of the dictionary, in all
+// namespaces, as JSON lines, returning the number of records written.
+func (d *Dictionary) Export(w io.Writer) (int64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	out := bufio.NewWriter(w)
+	enc := json.NewEncoder(out)
+	var count int64
+
+	rows, err := d.diskDB.Query(
+		"SELECT original, anonymized FROM mappings ORDER BY original")
+	if err != nil {
+		return count, fmt.Errorf("failed to read mappings: %w", err)
+	}
+	for rows.Next() {
+		var key, anonymized string
+		if err := rows.Scan(&key, &anonymized); err != nil {
+			rows.Close()
+			return count, fmt.Errorf("failed to read mappings: %w", err)
+		}
+		rec := exportRecord{Type: exportMapping, Original: key,
+			Anonymized: anonymized}
+
+		// Original values cannot hold a NUL, but namespaces may, as a
+		// partition's does even in the global namespace
+		if i := strings.LastIndexByte(key, 0); i >= 0 {
+			rec.Namespace, rec.Original = key[:i], key[i+1:]
+		}
+		if err := enc.Encode(rec); err != nil {
+			rows.Close()
+			return count, err
+		}
+		count++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("failed to read mappings: %w", err)
+	}
+
+	rows, err = d.diskDB.Query(
+		"SELECT identity, persona FROM identities ORDER BY identity")
+	if err != nil {
+		return count, fmt.Errorf("failed to read identities: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, persona string
+		if err := rows.Scan(&key, &persona); err != nil {
+			return count, fmt.Errorf("failed to read identities: %w", err)
+		}
+		profile, identity, _ := strings.Cut(key, "\x00")
+		if err := enc.Encode(exportRecord{Type: exportIdentity,
+			Profile: profile, Identity: identity, Persona: persona}); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("failed to read identities: %w", err)
+	}
+
+	return count, out.Flush()
+}
+
+// Import adds the mappings and identities of a dictionary exported by
+// Export, replacing any of the same values, returning the number of
+// records read. Nothing is imported if a record cannot be read.
+func (d *Dictionary) Import(r io.Reader) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.diskDB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to import dictionary: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var count int64
+	for {
+		var rec exportRecord
+		err := dec.Decode(&rec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("invalid record %d: %w", count+1, err)
+		}
+
+		switch rec.Type {
+		case exportMapping:
+			key := d.Namespace(rec.Namespace).key(rec.Original)
+			_, err = tx.Exec("INSERT OR REPLACE INTO mappings "+
+				"(original, anonymized) VALUES (?, ?)", key, rec.Anonymized)
+			d.cache.Remove(key)
+
+			// A value left in the filter by a failed import only costs
+			// a lookup
+			d.used.Add(rec.Anonymized)
+		case exportIdentity:
+			key := rec.Profile + "\x00" + rec.Identity
+			_, err = tx.Exec("INSERT OR REPLACE INTO identities "+
+				"(identity, persona) VALUES (?, ?)", key, rec.Persona)
+			d.identities.Remove(key)
+		default:
+			return 0, fmt.Errorf("invalid record %d: unknown type %q",
+				count+1, rec.Type)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to import record %d: %w", count+1,
+				err)
+		}
+		count++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to import dictionary: %w", err)
+	}
+	return count, nil
+}
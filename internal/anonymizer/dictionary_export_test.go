@@ -0,0 +1,79 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestDictionaryExportRoundTrip tests that the mappings of every
+// namespace and partition, and the identities, of an exported dictionary
+// are found again once it is imported
+func TestDictionaryExportRoundTrip(t *testing.T) {
+	views := []struct {
+		name string
+		view func(d *Dictionary) *Dictionary
+	}{
+		{"global", func(d *Dictionary) *Dictionary { return d }},
+		{"namespace", func(d *Dictionary) *Dictionary {
+			return d.Namespace("public.users.name")
+		}},
+		{"partition", func(d *Dictionary) *Dictionary {
+			return d.Partition("t1")
+		}},
+		{"partition of a namespace", func(d *Dictionary) *Dictionary {
+			return d.Namespace("public.users.name").Partition("t1")
+		}},
+	}
+
+	src, err := NewDictionary(0)
+	if err != nil {
+		t.Fatalf("failed to create dictionary: %v", err)
+	}
+	defer src.Close()
+	for _, v := range views {
+		v.view(src).Set("alice", "anon-"+v.name)
+	}
+	src.SetIdentity("customer", "42", `{"name":"Bob"}`)
+
+	var buf bytes.Buffer
+	exported, err := src.Export(&buf)
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+
+	dst, err := NewDictionary(0)
+	if err != nil {
+		t.Fatalf("failed to create dictionary: %v", err)
+	}
+	defer dst.Close()
+	imported, err := dst.Import(&buf)
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	if imported != exported || exported != int64(len(views)+1) {
+		t.Errorf("exported %d records, imported %d", exported, imported)
+	}
+
+	for _, v := range views {
+		t.Run(v.name, func(t *testing.T) {
+			got, ok := v.view(dst).Get("alice")
+			if !ok || got != "anon-"+v.name {
+				t.Errorf("expected %q, got %q, %v", "anon-"+v.name, got, ok)
+			}
+		})
+	}
+	if got, ok := dst.GetIdentity("customer", "42"); !ok ||
+		got != `{"name":"Bob"}` {
+		t.Errorf("unexpected identity %q, %v", got, ok)
+	}
+}
@@ -0,0 +1,61 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestKeptDictionaryMode tests that a kept dictionary, and its journal
+// files, are readable by their owner only, whether the dictionary is
+// created or was left readable by others
+func TestKeptDictionaryMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+
+	for _, name := range []string{"new.db", "existing.db"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if name == "existing.db" {
+				if err := os.WriteFile(path, nil, 0644); err != nil {
+					t.Fatalf("failed to create file: %v", err)
+				}
+			}
+
+			d, err := NewDictionaryWithOptions(DictionaryOptions{
+				Path: path, JournalMode: "WAL"})
+			if err != nil {
+				t.Fatalf("failed to open dictionary: %v", err)
+			}
+			defer d.Close()
+			d.Set("alice", "bob")
+
+			for _, file := range []string{path, path + "-wal",
+				path + "-shm"} {
+				info, err := os.Stat(file)
+				if os.IsNotExist(err) {
+					continue
+				}
+				if err != nil {
+					t.Fatalf("failed to stat %s: %v", file, err)
+				}
+				if mode := info.Mode().Perm(); mode != 0600 {
+					t.Errorf("expected %s to have mode 0600, got %o",
+						filepath.Base(file), mode)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// DistributionColumnProcessor processes a numeric column whose values are
+// replaced with values drawn from the distribution of its original
+// values. Each row is given a value of its own, without a dictionary, as
+// equal original values given equal replacements would skew the
+// distribution of a column with few distinct values.
+type DistributionColumnProcessor struct {
+	tx        *sql.Tx
+	column    errors.ColumnRef
+	dataType  string
+	generator generator.Generator
+	batchSize int
+	batchHook batchHookFunc
+	trace     *columnTrace
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
+}
+
+// NewDistributionColumnProcessor creates a new distribution column
+// processor, drawing values with a generator following the column's
+// distribution.
+func NewDistributionColumnProcessor(
+	tx *sql.Tx,
+	column errors.ColumnRef,
+	dataType string,
+	gen generator.Generator,
+	batchSize int,
+) *DistributionColumnProcessor {
+	return &DistributionColumnProcessor{
+		tx:        tx,
+		column:    column,
+		dataType:  dataType,
+		generator: gen,
+		batchSize: batchSize,
+	}
+}
+
+// Process replaces every non-empty value in the column.
+func (p *DistributionColumnProcessor) Process(ctx context.Context,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
+	p.tuning.apply(batch)
+
+	loop := &batchLoop{batch: batch, batchHook: p.batchHook, trace: p.trace}
+	return loop.run(ctx, func(row database.RowData) (string, int, error) {
+		return p.generator.Generate(row.Value), 1, nil
+	}, progress)
+}
@@ -0,0 +1,213 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/document"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// scrubDocument anonymizes the text of a stored document with a
+// generator, writing it back in the document's encoding. It returns false
+// if the document is binary data, which is left unchanged.
+func scrubDocument(gen generator.Generator, data []byte) ([]byte, bool) {
+	doc, ok := document.Decode(data)
+	if !ok {
+		return nil, false
+	}
+	return doc.Encode(gen.Generate(doc.Text)), true
+}
+
+// DocumentColumnProcessor processes a bytea column holding text
+// documents, anonymizing the text of each with a pattern, in the
+// encoding it was stored in. Values that are not text, such as images,
+// are left unchanged.
+type DocumentColumnProcessor struct {
+	tx        *sql.Tx
+	column    errors.ColumnRef
+	dataType  string
+	generator generator.Generator
+	batchSize int
+	quiet     bool
+	batchHook batchHookFunc
+	trace     *columnTrace
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
+}
+
+// NewDocumentColumnProcessor creates a new document column processor.
+func NewDocumentColumnProcessor(
+	tx *sql.Tx,
+	column errors.ColumnRef,
+	dataType string,
+	gen generator.Generator,
+	batchSize int,
+	quiet bool,
+) *DocumentColumnProcessor {
+	return &DocumentColumnProcessor{
+		tx:        tx,
+		column:    column,
+		dataType:  dataType,
+		generator: gen,
+		batchSize: batchSize,
+		quiet:     quiet,
+	}
+}
+
+// Process anonymizes the text of every document in the column.
+func (p *DocumentColumnProcessor) Process(ctx context.Context,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	// Values are read and written in the hex format, whatever the
+	// server's default
+	if err := database.SetLocal(ctx, p.tx, "bytea_output", "hex"); err != nil {
+		return nil, err
+	}
+
+	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
+	p.tuning.apply(batch)
+
+	var binary int64
+	loop := &batchLoop{batch: batch, batchHook: p.batchHook, trace: p.trace}
+	result, err := loop.run(ctx, func(row database.RowData) (string, int, error) {
+		// Skip empty documents
+		if row.Value == `\x` {
+			return "", 0, nil
+		}
+
+		data, err := hex.DecodeString(strings.TrimPrefix(row.Value, `\x`))
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to decode bytea value "+
+				"(ctid=%s): %w", row.CTID, err)
+		}
+		scrubbed, ok := scrubDocument(p.generator, data)
+		if !ok {
+			binary++
+			return "", 0, nil
+		}
+		return `\x` + hex.EncodeToString(scrubbed), 1, nil
+	}, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	if binary > 0 && !p.quiet {
+		log.Printf("Warning: %d values of %s are not text documents, and "+
+			"were left unchanged", binary, p.column)
+	}
+	return result, nil
+}
+
+// LargeObjectColumnProcessor processes a column holding the OIDs of large
+// objects, anonymizing the text of each large object with a pattern, in
+// place, so that the column's values do not change. A large object
+// referenced by several rows is anonymized once.
+type LargeObjectColumnProcessor struct {
+	tx        *sql.Tx
+	column    errors.ColumnRef
+	dataType  string
+	generator generator.Generator
+	batchSize int
+	quiet     bool
+	batchHook batchHookFunc
+	trace     *columnTrace
+
+	// Settings tuning how batches are read
+	tuning batchTuning
+}
+
+// NewLargeObjectColumnProcessor creates a new large object column
+// processor.
+func NewLargeObjectColumnProcessor(
+	tx *sql.Tx,
+	column errors.ColumnRef,
+	dataType string,
+	gen generator.Generator,
+	batchSize int,
+	quiet bool,
+) *LargeObjectColumnProcessor {
+	return &LargeObjectColumnProcessor{
+		tx:        tx,
+		column:    column,
+		dataType:  dataType,
+		generator: gen,
+		batchSize: batchSize,
+		quiet:     quiet,
+	}
+}
+
+// Process anonymizes the text of every large object the column references.
+func (p *LargeObjectColumnProcessor) Process(ctx context.Context,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
+	p.tuning.apply(batch)
+
+	seen := make(map[string]bool)
+	var binary, missing int64
+
+	// The column's values are left as they are
+	loop := &batchLoop{batch: batch, batchHook: p.batchHook, trace: p.trace,
+		inPlace: true}
+	result, err := loop.run(ctx, func(row database.RowData) (string, int, error) {
+		// Skip large objects already done
+		if seen[row.Value] {
+			return "", 0, nil
+		}
+		seen[row.Value] = true
+
+		data, ok, err := database.ReadLargeObject(ctx, p.tx, row.Value)
+		if err != nil {
+			return "", 0, err
+		}
+		if !ok {
+			missing++
+			return "", 0, nil
+		}
+		scrubbed, ok := scrubDocument(p.generator, data)
+		if !ok {
+			binary++
+			return "", 0, nil
+		}
+		if err := database.WriteLargeObject(ctx, p.tx, row.Value,
+			scrubbed); err != nil {
+			return "", 0, err
+		}
+		return "", 1, nil
+	}, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.quiet {
+		if binary > 0 {
+			log.Printf("Warning: %d large objects referenced by %s are not "+
+				"text documents, and were left unchanged", binary, p.column)
+		}
+		if missing > 0 {
+			log.Printf("Warning: %d large objects referenced by %s do not "+
+				"exist", missing, p.column)
+		}
+	}
+
+	result.UniqueValues = int64(len(seen))
+	return result, nil
+}
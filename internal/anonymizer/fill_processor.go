@@ -0,0 +1,89 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// FillColumnProcessor processes a column whose values are all set to NULL,
+// or to the same constant, for columns that need no synthetic values. As
+// no value depends on the original, the column is written with a single
+// statement, as one batch, without reading its rows.
+type FillColumnProcessor struct {
+	tx        *sql.Tx
+	column    errors.ColumnRef
+	dataType  string
+	value     *string // Constant, or nil for NULL
+	batchHook batchHookFunc
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
+}
+
+// NewFillColumnProcessor creates a new processor setting a column's values
+// to value, or to NULL if value is nil.
+func NewFillColumnProcessor(
+	tx *sql.Tx,
+	column errors.ColumnRef,
+	dataType string,
+	value *string,
+) *FillColumnProcessor {
+	return &FillColumnProcessor{
+		tx:       tx,
+		column:   column,
+		dataType: dataType,
+		value:    value,
+	}
+}
+
+// Process sets every non-NULL value of the column.
+func (p *FillColumnProcessor) Process(ctx context.Context,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, 0)
+	p.tuning.apply(batch)
+
+	rows, err := batch.CountValues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.batchHook.call(ctx, HookBeforeBatch, int(rows)); err != nil {
+		return nil, err
+	}
+
+	updated, err := batch.UpdateAll(ctx, p.value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.batchHook.call(ctx, HookAfterBatch, int(rows)); err != nil {
+		return nil, err
+	}
+	if err := batch.EndBatch(ctx); err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		progress(rows)
+	}
+
+	return &ProcessResult{
+		RowsProcessed:     rows,
+		ValuesAnonymized:  updated,
+		MaxStatementBytes: batch.MaxStatementBytes(),
+		Phases:            batch.PhaseTimes(),
+	}, nil
+}
@@ -0,0 +1,49 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"github.com/pgedge/pgedge-anonymizer/internal/fingerprint"
+)
+
+// openFingerprints creates the fingerprint file configured for
+// verification, to which the dictionary records each original value.
+func (a *Anonymizer) openFingerprints() error {
+	if a.config.Verify.Fingerprints == "" {
+		return nil
+	}
+
+	w, err := fingerprint.Create(a.config.Verify.Fingerprints)
+	if err != nil {
+		return err
+	}
+	a.fingerprints = w
+	a.dictionary.RecordFingerprints(w)
+	return nil
+}
+
+// closeFingerprints closes the fingerprint file.
+func (a *Anonymizer) closeFingerprints() error {
+	if a.fingerprints == nil {
+		return nil
+	}
+	err := a.fingerprints.Close()
+	a.fingerprints = nil
+	return err
+}
+
+// fingerprintErr returns the first error writing the fingerprint file.
+func (a *Anonymizer) fingerprintErr() error {
+	if a.fingerprints == nil {
+		return nil
+	}
+	return a.fingerprints.Err()
+}
@@ -0,0 +1,83 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// SkippedColumn is a configured column the run leaves unchanged.
+type SkippedColumn struct {
+	Column errors.ColumnRef
+
+	// Reason is why the column is skipped, in a few words, and Warning
+	// explains it, with what to anonymize instead
+	Reason  string
+	Warning string
+}
+
+// CheckGeneratedColumns returns the columns that are stored generated
+// columns or identity columns, which the database generates the values of
+// and rejects updates to, so that the run skips them rather than fail. A
+// generated column's values are computed again when the columns it is
+// computed from are anonymized, so the warning names those not
+// configured.
+func CheckGeneratedColumns(ctx context.Context,
+	validator *database.SchemaValidator,
+	columns []errors.ColumnRef) ([]SkippedColumn, error) {
+
+	generated, err := validator.GetGeneratedColumns(ctx, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	configured := make(map[string]bool)
+	for _, col := range columns {
+		configured[col.String()] = true
+	}
+
+	var skipped []SkippedColumn
+	for _, gc := range generated {
+		s := SkippedColumn{Column: gc.Column}
+		switch gc.Kind {
+		case database.GeneratedStored:
+			s.Reason = "generated column"
+			var missing []string
+			for _, source := range gc.Sources {
+				ref := errors.ColumnRef{Schema: gc.Column.Schema,
+					Table: gc.Column.Table, Column: source}
+				if !configured[ref.String()] {
+					missing = append(missing, ref.String())
+				}
+			}
+			if len(missing) == 0 {
+				s.Warning = fmt.Sprintf("generated column computed as %s, "+
+					"from anonymized columns only; it is computed again "+
+					"from them, so it is skipped", gc.Expression)
+			} else {
+				s.Warning = fmt.Sprintf("generated column computed as %s "+
+					"cannot be updated, so it is skipped; anonymize %s "+
+					"instead", gc.Expression, strings.Join(missing, ", "))
+			}
+		default:
+			s.Reason = "identity column"
+			s.Warning = fmt.Sprintf("%s column is generated by a sequence, "+
+				"so it is skipped", gc.Kind)
+		}
+		skipped = append(skipped, s)
+	}
+	return skipped, nil
+}
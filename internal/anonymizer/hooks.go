@@ -0,0 +1,162 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// HookPoint identifies the point in a run at which a hook is called.
+type HookPoint string
+
+// Hook points, in the order they occur for each column.
+const (
+	HookBeforeColumn HookPoint = "before_column"
+	HookBeforeBatch  HookPoint = "before_batch"
+	HookAfterBatch   HookPoint = "after_batch"
+	HookAfterColumn  HookPoint = "after_column"
+)
+
+// HookEvent describes the point at which a hook is called.
+type HookEvent struct {
+	Point HookPoint
+
+	// Database is the name of the database being anonymized.
+	Database string
+
+	// Column is the column being anonymized.
+	Column errors.ColumnRef
+
+	// Batch is the number of the batch within the column, starting at 1;
+	// it is zero for column hooks.
+	Batch int
+
+	// Rows is the number of rows in the batch for batch hooks, and the
+	// number of rows processed in the column for after_column hooks.
+	Rows int64
+
+	// ValuesAnonymized is the number of values changed in the column; it
+	// is only set for after_column hooks.
+	ValuesAnonymized int64
+}
+
+// Hook is a function called at a hook point. Hooks run inside the
+// anonymization transaction, so changes made since it was last committed
+// (at the start of the run, unless the transaction mode commits each
+// table or batch) are not visible to other connections. Returning an
+// error stops the run and rolls the transaction back.
+type Hook func(ctx context.Context, event HookEvent) error
+
+// AddHook registers a hook to be called at the given point. Hooks at the
+// same point are called in the order they were added.
+func (a *Anonymizer) AddHook(point HookPoint, hook Hook) {
+	if a.hooks == nil {
+		a.hooks = make(map[HookPoint][]Hook)
+	}
+	a.hooks[point] = append(a.hooks[point], hook)
+}
+
+// addCommandHooks registers the external commands from the hooks section
+// of the configuration.
+func (a *Anonymizer) addCommandHooks(cfg config.HooksConfig) {
+	for _, h := range []struct {
+		point    HookPoint
+		commands []config.HookCommand
+	}{
+		{HookBeforeColumn, cfg.BeforeColumn},
+		{HookBeforeBatch, cfg.BeforeBatch},
+		{HookAfterBatch, cfg.AfterBatch},
+		{HookAfterColumn, cfg.AfterColumn},
+	} {
+		for _, cmd := range h.commands {
+			a.AddHook(h.point, newCommandHook(cmd))
+		}
+	}
+}
+
+// runHooks calls the hooks registered for the event's point.
+func (a *Anonymizer) runHooks(ctx context.Context, event HookEvent) error {
+	event.Database = a.config.Database.DatabaseName()
+	for _, hook := range a.hooks[event.Point] {
+		if err := hook(ctx, event); err != nil {
+			return fmt.Errorf("%s hook for %s failed: %w", event.Point,
+				event.Column.String(), err)
+		}
+	}
+	return nil
+}
+
+// batchHook returns the function processors call before and after each
+// batch of a column, or nil if no batch hooks are registered.
+func (a *Anonymizer) batchHook(col errors.ColumnRef) batchHookFunc {
+	if len(a.hooks[HookBeforeBatch]) == 0 && len(a.hooks[HookAfterBatch]) == 0 {
+		return nil
+	}
+
+	batch := 0
+	return func(ctx context.Context, point HookPoint, rows int) error {
+		if point == HookBeforeBatch {
+			batch++
+		}
+		return a.runHooks(ctx, HookEvent{
+			Point:  point,
+			Column: col,
+			Batch:  batch,
+			Rows:   int64(rows),
+		})
+	}
+}
+
+// batchHookFunc is called by column processors with the number of rows
+// fetched, before the batch is anonymized and after it is written.
+type batchHookFunc func(ctx context.Context, point HookPoint, rows int) error
+
+// call invokes the function, if set.
+func (f batchHookFunc) call(ctx context.Context, point HookPoint,
+	rows int) error {
+	if f == nil {
+		return nil
+	}
+	return f(ctx, point, rows)
+}
+
+// newCommandHook returns a hook that runs an external command, passing
+// the event in PGEDGE_ANONYMIZER_* environment variables. The command's
+// output is passed through, and a non-zero exit status fails the hook.
+func newCommandHook(cfg config.HookCommand) Hook {
+	return func(ctx context.Context, event HookEvent) error {
+		cmd := exec.CommandContext(ctx, cfg.Exec, cfg.Args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(),
+			"PGEDGE_ANONYMIZER_HOOK="+string(event.Point),
+			"PGEDGE_ANONYMIZER_DATABASE="+event.Database,
+			"PGEDGE_ANONYMIZER_SCHEMA="+event.Column.Schema,
+			"PGEDGE_ANONYMIZER_TABLE="+event.Column.Table,
+			"PGEDGE_ANONYMIZER_COLUMN="+event.Column.Column,
+			"PGEDGE_ANONYMIZER_BATCH="+strconv.Itoa(event.Batch),
+			"PGEDGE_ANONYMIZER_ROWS="+strconv.FormatInt(event.Rows, 10),
+			"PGEDGE_ANONYMIZER_VALUES_ANONYMIZED="+
+				strconv.FormatInt(event.ValuesAnonymized, 10),
+		)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", cfg.Exec, err)
+		}
+		return nil
+	}
+}
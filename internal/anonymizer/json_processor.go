@@ -15,6 +15,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/pgedge/pgedge-anonymizer/internal/config"
 	"github.com/pgedge/pgedge-anonymizer/internal/database"
@@ -23,6 +24,13 @@ import (
 	"github.com/pgedge/pgedge-anonymizer/internal/jsonpath"
 )
 
+// StreamingThreshold is the document size in bytes above which JSON values
+// are processed with the streaming tokenizer rather than parsed into a tree.
+// Parsed trees typically take several times the size of the raw document,
+// so streaming saves the cost of building one; the fetched document and
+// its rewritten copy are still held in memory in full.
+const StreamingThreshold = 1 << 20
+
 // JSONColumnProcessor processes a JSON/JSONB column for anonymization.
 // It extracts values at specified JSON paths, anonymizes them, and
 // updates the JSON with the anonymized values.
@@ -32,10 +40,19 @@ type JSONColumnProcessor struct {
 	dataType   string
 	jsonPaths  []config.JSONPathConfig
 	generators map[string]generator.Generator // path -> generator
-	dictionary *Dictionary
 	batchSize  int
 	processor  *jsonpath.Processor
 	quiet      bool
+	matched    map[string]bool // paths that resolved in any document
+	batchHook  batchHookFunc
+	trace      *columnTrace
+
+	// dictionaries maps each path to the dictionary namespace its values
+	// are kept in
+	dictionaries map[string]*Dictionary
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
 }
 
 // NewJSONColumnProcessor creates a new JSON column processor.
@@ -45,36 +62,40 @@ func NewJSONColumnProcessor(
 	dataType string,
 	jsonPaths []config.JSONPathConfig,
 	generators map[string]generator.Generator,
-	dict *Dictionary,
+	dictionaries map[string]*Dictionary,
 	batchSize int,
 	quiet bool,
 ) *JSONColumnProcessor {
 	return &JSONColumnProcessor{
-		tx:         tx,
-		column:     column,
-		dataType:   dataType,
-		jsonPaths:  jsonPaths,
-		generators: generators,
-		dictionary: dict,
-		batchSize:  batchSize,
-		processor:  jsonpath.NewProcessor(quiet),
-		quiet:      quiet,
+		tx:           tx,
+		column:       column,
+		dataType:     dataType,
+		jsonPaths:    jsonPaths,
+		generators:   generators,
+		dictionaries: dictionaries,
+		batchSize:    batchSize,
+		processor:    jsonpath.NewProcessor(quiet),
+		quiet:        quiet,
+		matched:      make(map[string]bool),
 	}
 }
 
 // Process anonymizes all JSON values in the column at the specified paths.
+// When the column is jsonb and every path is concrete, values are extracted
+// and replaced server-side; otherwise whole documents are processed in Go.
 func (p *JSONColumnProcessor) Process(ctx context.Context,
 	progress func(processed int64)) (*ProcessResult, error) {
 
-	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
-
-	// Open cursor - for JSON columns we fetch the full JSON value
-	if err := batch.OpenCursor(ctx); err != nil {
-		return nil, err
+	// Values are only replaced server-side with the cursor strategy
+	if p.tuning.usesCursor() {
+		if pgPaths, ok := p.serverSidePaths(); ok {
+			return p.processServerSide(ctx, pgPaths, progress)
+		}
 	}
-	defer func() { _ = batch.CloseCursor(ctx) }()
 
-	result := &ProcessResult{}
+	// For JSON columns we fetch the full JSON value
+	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
+	p.tuning.apply(batch)
 
 	// Collect all path expressions for batch extraction
 	pathExprs := make([]string, len(p.jsonPaths))
@@ -82,66 +103,41 @@ func (p *JSONColumnProcessor) Process(ctx context.Context,
 		pathExprs[i] = jp.Path
 	}
 
-	for {
-		// Check for cancellation
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
-
-		// Fetch next batch
-		rows, err := batch.FetchBatch(ctx)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(rows) == 0 {
-			break // No more rows
-		}
-
-		// Process batch
-		updates := make(map[string]string)
-
-		for _, row := range rows {
-			// Skip empty values
-			if row.Value == "" {
-				continue
-			}
+	// Large documents are streamed when every path can be evaluated from
+	// the location of a value alone
+	canStream := jsonpath.CanStream(pathExprs)
 
-			// Process this JSON value
-			modifiedJSON, valuesAnonymized, err := p.processJSONValue(
+	loop := &batchLoop{batch: batch, batchHook: p.batchHook, trace: p.trace}
+	result, err := loop.run(ctx, func(row database.RowData) (string, int, error) {
+		var modifiedJSON string
+		var valuesAnonymized int
+		var err error
+		if canStream && len(row.Value) >= StreamingThreshold {
+			modifiedJSON, valuesAnonymized, err = p.streamJSONValue(
+				row.Value, pathExprs)
+		} else {
+			var parsed []byte
+			parsed, valuesAnonymized, err = p.processJSONValue(
 				row.CTID, []byte(row.Value), pathExprs)
-			if err != nil {
-				// Log error but continue processing other rows
-				if !p.quiet {
-					log.Printf("Warning: failed to process JSON at %s (ctid=%s): %v",
-						p.column, row.CTID, err)
-				}
-				continue
-			}
-
-			if valuesAnonymized > 0 {
-				updates[row.CTID] = string(modifiedJSON)
-				result.ValuesAnonymized += int64(valuesAnonymized)
-			}
+			modifiedJSON = string(parsed)
 		}
-
-		// Apply batch updates
-		if len(updates) > 0 {
-			if err := batch.UpdateBatch(ctx, updates); err != nil {
-				return nil, err
+		if err != nil {
+			// Log error but continue processing other rows
+			if !p.quiet {
+				log.Printf("Warning: failed to process JSON at %s (ctid=%s): %v",
+					p.column, row.CTID, err)
 			}
+			return "", 0, nil
 		}
-
-		result.RowsProcessed += int64(len(rows))
-
-		// Report progress
-		if progress != nil {
-			progress(result.RowsProcessed)
-		}
+		return modifiedJSON, valuesAnonymized, nil
+	}, progress)
+	if err != nil {
+		return nil, err
 	}
 
+	if err := p.checkMissing(result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
@@ -163,6 +159,10 @@ func (p *JSONColumnProcessor) processJSONValue(
 		return jsonData, 0, nil // No matching paths in this JSON
 	}
 
+	for pathExpr := range allMatches {
+		p.matched[pathExpr] = true
+	}
+
 	// Build replacement map: concrete path -> anonymized value
 	replacements := make(map[string]string)
 	valuesAnonymized := 0
@@ -172,14 +172,15 @@ func (p *JSONColumnProcessor) processJSONValue(
 		if !ok {
 			continue // No generator for this path (shouldn't happen)
 		}
+		dict := p.dictionaries[pathExpr]
 
 		for _, match := range matches {
 			// Check dictionary for existing mapping
-			anonymized, exists := p.dictionary.Get(match.Value)
+			anonymized, exists := dict.Get(match.Value)
 			if !exists {
 				// Generate new anonymized value
 				anonymized = gen.Generate(match.Value)
-				p.dictionary.Set(match.Value, anonymized)
+				dict.Set(match.Value, anonymized)
 			}
 
 			replacements[match.Path] = anonymized
@@ -199,3 +200,220 @@ func (p *JSONColumnProcessor) processJSONValue(
 
 	return modifiedJSON, valuesAnonymized, nil
 }
+
+// streamJSONValue anonymizes values at all paths by streaming the document
+// token by token, so that it is never held in memory as a parsed tree.
+// The document is read from the fetched string and written to a builder
+// whose string is returned, without copying either. Returns the modified
+// JSON and count of values anonymized.
+func (p *JSONColumnProcessor) streamJSONValue(
+	jsonData string,
+	pathExprs []string,
+) (string, int, error) {
+
+	var out strings.Builder
+	out.Grow(len(jsonData))
+
+	res, err := p.processor.StreamReplace(strings.NewReader(jsonData), &out,
+		pathExprs, func(pathExpr, value string) string {
+			gen, ok := p.generators[pathExpr]
+			if !ok {
+				return value // No generator for this path (shouldn't happen)
+			}
+
+			// Check dictionary for existing mapping
+			dict := p.dictionaries[pathExpr]
+			anonymized, exists := dict.Get(value)
+			if !exists {
+				anonymized = gen.Generate(value)
+				dict.Set(value, anonymized)
+			}
+			return anonymized
+		})
+	if err != nil {
+		return "", 0, err
+	}
+
+	for pathExpr := range res.Matched {
+		p.matched[pathExpr] = true
+	}
+
+	return out.String(), res.Replaced, nil
+}
+
+// serverSidePaths returns the configured paths in PostgreSQL text[] form if
+// the column can be processed with jsonb_set, i.e. it is jsonb and every
+// path addresses a single concrete location.
+func (p *JSONColumnProcessor) serverSidePaths() ([][]string, bool) {
+	if p.dataType != "jsonb" {
+		return nil, false
+	}
+
+	pgPaths := make([][]string, len(p.jsonPaths))
+	for i, jp := range p.jsonPaths {
+		path, ok := jsonpath.PostgresPath(jp.Path)
+		if !ok {
+			return nil, false
+		}
+		pgPaths[i] = path
+	}
+
+	return pgPaths, true
+}
+
+// processServerSide anonymizes values at concrete paths without fetching
+// whole documents: only the values at each path are read, and new values
+// are written back with jsonb_set in the batch UPDATE.
+func (p *JSONColumnProcessor) processServerSide(ctx context.Context,
+	pgPaths [][]string, progress func(processed int64)) (*ProcessResult, error) {
+
+	batch := database.NewJSONBPathBatchProcessor(p.tx, p.column, pgPaths,
+		p.batchSize)
+	p.tuning.apply(batch)
+
+	if err := batch.OpenCursor(ctx); err != nil {
+		return nil, err
+	}
+	defer func() { _ = batch.CloseCursor(ctx) }()
+
+	result := &ProcessResult{}
+
+	for {
+		// Check for cancellation
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		// Fetch next batch
+		rows, err := batch.FetchBatch(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) == 0 {
+			break // No more rows
+		}
+
+		if err := p.batchHook.call(ctx, HookBeforeBatch, len(rows)); err != nil {
+			return nil, err
+		}
+
+		// Process batch
+		updates := make(map[string][]*string)
+
+		for _, row := range rows {
+			p.trace.setRow(row.CTID)
+
+			var newValues []*string
+
+			for i, value := range row.Values {
+				if !value.Valid {
+					continue
+				}
+				p.matched[p.jsonPaths[i].Path] = true
+
+				gen, ok := p.generators[p.jsonPaths[i].Path]
+				if !ok {
+					continue // No generator for this path (shouldn't happen)
+				}
+
+				// Check dictionary for existing mapping
+				dict := p.dictionaries[p.jsonPaths[i].Path]
+				anonymized, exists := dict.Get(value.String)
+				if !exists {
+					anonymized = gen.Generate(value.String)
+					dict.Set(value.String, anonymized)
+				}
+
+				if newValues == nil {
+					newValues = make([]*string, len(pgPaths))
+				}
+				newValues[i] = &anonymized
+				result.ValuesAnonymized++
+			}
+
+			if newValues != nil {
+				updates[row.CTID] = newValues
+			}
+		}
+
+		// Apply batch updates
+		if len(updates) > 0 {
+			if err := batch.UpdateBatch(ctx, updates); err != nil {
+				return nil, err
+			}
+		}
+
+		result.RowsProcessed += int64(len(rows))
+
+		if err := p.batchHook.call(ctx, HookAfterBatch, len(rows)); err != nil {
+			return nil, err
+		}
+		if err := batch.EndBatch(ctx); err != nil {
+			return nil, err
+		}
+
+		// Report progress
+		if progress != nil {
+			progress(result.RowsProcessed)
+		}
+	}
+
+	// Values are only fetched for strings, so check whether unmatched
+	// paths resolve to other types before applying on_missing
+	for i, jp := range p.jsonPaths {
+		if p.matched[jp.Path] || jp.OnMissing == "" ||
+			jp.OnMissing == config.OnMissingIgnore {
+			continue
+		}
+		exists, err := batch.PathExists(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			p.matched[jp.Path] = true
+		}
+	}
+
+	if err := p.checkMissing(result); err != nil {
+		return nil, err
+	}
+
+	result.MaxStatementBytes = batch.MaxStatementBytes()
+	result.Phases = batch.PhaseTimes()
+	return result, nil
+}
+
+// checkMissing applies the on_missing setting of each path that did not
+// resolve in any document. Nothing is reported for columns with no rows.
+func (p *JSONColumnProcessor) checkMissing(result *ProcessResult) error {
+	if result.RowsProcessed == 0 {
+		return nil
+	}
+
+	var missing []string
+	for _, jp := range p.jsonPaths {
+		if p.matched[jp.Path] {
+			continue
+		}
+
+		switch jp.OnMissing {
+		case config.OnMissingWarn:
+			if !p.quiet {
+				log.Printf("Warning: JSON path %s did not match any document in %s",
+					jp.Path, p.column)
+			}
+		case config.OnMissingError:
+			missing = append(missing, jp.Path)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("JSON paths did not match any document in %s: %s",
+			p.column, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
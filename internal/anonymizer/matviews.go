@@ -0,0 +1,72 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// dependentMatviews returns the materialized views reading the tables of
+// the anonymized columns, which keep the original values until they are
+// refreshed, warning about them unless refresh_matviews is set.
+func (a *Anonymizer) dependentMatviews(ctx context.Context,
+	validator *database.SchemaValidator,
+	columns []errors.ColumnRef) ([]database.MaterializedView, error) {
+
+	views, err := validator.GetDependentMatviews(ctx, unitTables(columns))
+	if err != nil {
+		return nil, err
+	}
+
+	if !a.config.RefreshMatviews && !a.quiet {
+		for _, m := range views {
+			log.Printf("Warning: materialized view %s reads anonymized "+
+				"tables and keeps their original values until refreshed; "+
+				"set refresh_matviews to refresh it", m)
+		}
+	}
+	return views, nil
+}
+
+// refreshMatviews refreshes materialized views in a transaction of its
+// own, once the tables they read are anonymized and committed.
+func (a *Anonymizer) refreshMatviews(ctx context.Context,
+	views []database.MaterializedView) error {
+
+	if !a.config.RefreshMatviews || len(views) == 0 {
+		return nil
+	}
+
+	tx, err := a.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range views {
+		if err := database.RefreshMatview(ctx, tx, m); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if !a.quiet {
+			fmt.Printf("Refreshed materialized view %s\n", m)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.NewDatabaseError("commit",
+			fmt.Sprintf("failed to commit materialized view refresh: %v",
+				err), err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// oversizedValues handles the values of a column larger than
+// max_value_bytes, which are not read: it fails the run at the first, or
+// counts those skipped, to report them once the column is done.
+type oversizedValues struct {
+	column errors.ColumnRef
+	limit  int64
+	skip   bool
+
+	skipped int64
+	largest int64
+}
+
+// newOversizedValues returns the handling of a column's oversized values,
+// or nil if the size of its values is not limited.
+func newOversizedValues(cfg *config.Config,
+	col errors.ColumnRef) *oversizedValues {
+
+	if cfg.MaxValueBytes <= 0 {
+		return nil
+	}
+	return &oversizedValues{
+		column: col,
+		limit:  cfg.MaxValueBytes,
+		skip:   cfg.OversizedValues == config.OversizedSkip,
+	}
+}
+
+// check handles a value larger than the limit, returning an error unless
+// oversized values are skipped.
+func (o *oversizedValues) check(ctid string, bytes int64) error {
+	if !o.skip {
+		return fmt.Errorf("value of %d bytes (ctid=%s) is larger than "+
+			"max_value_bytes (%d); set oversized_values to 'skip' to "+
+			"leave such values unchanged", bytes, ctid, o.limit)
+	}
+	o.skipped++
+	o.largest = max(o.largest, bytes)
+	return nil
+}
+
+// report warns of the values skipped, if any.
+func (o *oversizedValues) report(quiet bool) {
+	if o == nil || o.skipped == 0 || quiet {
+		return
+	}
+	log.Printf("Warning: %d values of %s larger than max_value_bytes (%d) "+
+		"were left unchanged; the largest is %d bytes", o.skipped, o.column,
+		o.limit, o.largest)
+}
@@ -0,0 +1,84 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// ResolvePartitions sets the run's partition_by on each column anonymized
+// with a pattern, in a table that has the partition column, that does not
+// set its own. It fails if the partition column is itself anonymized, as
+// its values would change part way through the run.
+func ResolvePartitions(ctx context.Context,
+	validator *database.SchemaValidator, cfg *config.Config,
+	columns []config.ColumnConfig) ([]config.ColumnConfig, error) {
+
+	if cfg.PartitionBy == "" {
+		return columns, nil
+	}
+
+	var anonymized []errors.ColumnRef
+	var candidates []errors.ColumnRef
+	seen := make(map[string]bool)
+	for _, col := range columns {
+		ref, err := errors.ParseColumnRef(col.Column)
+		if err != nil {
+			return nil, err
+		}
+		if ref.Column == cfg.PartitionBy {
+			anonymized = append(anonymized, ref)
+			continue
+		}
+		ref.Column = cfg.PartitionBy
+		if col.PartitionBy == "" && col.Partitionable() && !seen[ref.String()] {
+			seen[ref.String()] = true
+			candidates = append(candidates, ref)
+		}
+	}
+	if len(anonymized) > 0 {
+		return nil, errors.NewValidationError(fmt.Sprintf(
+			"partition_by column %q cannot be anonymized", cfg.PartitionBy),
+			anonymized)
+	}
+
+	// Only the tables that have the column are partitioned
+	missing, err := validator.ValidateColumns(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	lacking := make(map[string]bool)
+	for _, ref := range missing {
+		lacking[ref.String()] = true
+	}
+
+	resolved := make([]config.ColumnConfig, len(columns))
+	for i, col := range columns {
+		resolved[i] = col
+		if col.PartitionBy != "" || !col.Partitionable() {
+			continue
+		}
+		ref, err := errors.ParseColumnRef(col.Column)
+		if err != nil {
+			return nil, err
+		}
+		ref.Column = cfg.PartitionBy
+		if !lacking[ref.String()] {
+			resolved[i].PartitionBy = cfg.PartitionBy
+		}
+	}
+	return resolved, nil
+}
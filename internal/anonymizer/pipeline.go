@@ -0,0 +1,178 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+)
+
+// pipelineDepth is the most batches each stage of a pipelined column may
+// run ahead of the next stage, bounding the rows held in memory to about
+// this many batches per stage.
+const pipelineDepth = 2
+
+// pendingBatch is a batch of rows whose values have been generated, on
+// its way to being written.
+type pendingBatch struct {
+	rows    int
+	updates map[string]string
+}
+
+// pipelined returns true if the column's batches are fetched, generated
+// and written by separate stages. Batch hooks expect each batch to be
+// written before the next is fetched, so columns with them are processed
+// a batch at a time.
+func (p *ColumnProcessor) pipelined() bool {
+	return p.batchHook == nil
+}
+
+// processPipelined anonymizes the rows of the column in three stages,
+// connected by bounded channels: one fetching batches, one generating
+// their values, and one writing them back. Values are generated while the
+// database reads the next batch or writes the last, rather than leaving it
+// idle; the stages reading and writing share the transaction's
+// connection, so they take turns using it. A stage that falls behind
+// holds up the others once the channels are full. The first stage to fail
+// stops the others, and its error is returned.
+func (p *ColumnProcessor) processPipelined(ctx context.Context,
+	batch *database.BatchProcessor, result *ProcessResult,
+	progress func(processed int64)) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var conn sync.Mutex
+	fetched := make(chan []database.RowData, pipelineDepth)
+	generated := make(chan pendingBatch, pipelineDepth)
+
+	var wg sync.WaitGroup
+	var fetchErr, generateErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer close(fetched)
+		if fetchErr = p.fetchStage(ctx, batch, &conn, fetched); fetchErr != nil {
+			cancel()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		defer close(generated)
+		if generateErr = p.generateStage(ctx, fetched, generated,
+			result); generateErr != nil {
+			cancel()
+		}
+	}()
+
+	updateErr := p.updateStage(ctx, batch, &conn, generated, result,
+		progress)
+	if updateErr != nil {
+		cancel()
+	}
+	wg.Wait()
+
+	// Report the failure that stopped the pipeline, rather than the
+	// cancellation of the other stages, which the database errors wrap
+	for _, err := range []error{fetchErr, generateErr, updateErr} {
+		if err != nil && !stderrors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	for _, err := range []error{fetchErr, generateErr, updateErr} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchStage fetches batches of rows until there are no more.
+func (p *ColumnProcessor) fetchStage(ctx context.Context,
+	batch *database.BatchProcessor, conn *sync.Mutex,
+	out chan<- []database.RowData) error {
+
+	for {
+		conn.Lock()
+		rows, err := batch.FetchBatch(ctx)
+		conn.Unlock()
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil // No more rows
+		}
+
+		select {
+		case out <- rows:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// generateStage generates the values of each batch fetched.
+func (p *ColumnProcessor) generateStage(ctx context.Context,
+	in <-chan []database.RowData, out chan<- pendingBatch,
+	result *ProcessResult) error {
+
+	for rows := range in {
+		updates, err := p.anonymizeBatch(rows, result)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case out <- pendingBatch{rows: len(rows), updates: updates}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return ctx.Err()
+}
+
+// updateStage writes each batch generated, and ends it, which commits it
+// in per-batch transaction mode.
+func (p *ColumnProcessor) updateStage(ctx context.Context,
+	batch *database.BatchProcessor, conn *sync.Mutex,
+	in <-chan pendingBatch, result *ProcessResult,
+	progress func(processed int64)) error {
+
+	for b := range in {
+		if err := p.write(ctx, batch, conn, b); err != nil {
+			return err
+		}
+		result.RowsProcessed += int64(b.rows)
+
+		if progress != nil {
+			progress(result.RowsProcessed)
+		}
+	}
+	return ctx.Err()
+}
+
+// write writes a batch and ends it, holding the connection throughout.
+func (p *ColumnProcessor) write(ctx context.Context,
+	batch *database.BatchProcessor, conn *sync.Mutex, b pendingBatch) error {
+
+	conn.Lock()
+	defer conn.Unlock()
+
+	if len(b.updates) > 0 {
+		if err := batch.UpdateBatch(ctx, b.updates); err != nil {
+			return err
+		}
+	}
+	return batch.EndBatch(ctx)
+}
@@ -0,0 +1,259 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql/driver"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// anyConverter passes the arrays of batch updates as they are, as the
+// pgx driver accepts them.
+type anyConverter struct{}
+
+func (anyConverter) ConvertValue(v any) (driver.Value, error) {
+	return v, nil
+}
+
+// prefixGenerator anonymizes a value by prefixing it, failing once it has
+// generated more than failAfter values, if that is set.
+type prefixGenerator struct {
+	calls     atomic.Int32
+	failAfter int32
+}
+
+func (g *prefixGenerator) Name() string { return "PREFIX" }
+
+func (g *prefixGenerator) Generate(input string) string {
+	g.calls.Add(1)
+	return "anon-" + input
+}
+
+func (g *prefixGenerator) Err() error {
+	if g.failAfter > 0 && g.calls.Load() > g.failAfter {
+		return stderrors.New("generator failed")
+	}
+	return nil
+}
+
+// updatedCTIDs records the CTIDs of each batch update, sorted, in the
+// order the batches are written. sqlmock matches the arguments of a
+// statement more than once, so a batch matched again is recorded once.
+type updatedCTIDs struct {
+	batches [][]string
+}
+
+func (u *updatedCTIDs) Match(v driver.Value) bool {
+	ctids, ok := v.([]string)
+	if !ok {
+		return false
+	}
+	batch := slices.Sorted(slices.Values(ctids))
+	if n := len(u.batches); n == 0 || !slices.Equal(u.batches[n-1], batch) {
+		u.batches = append(u.batches, batch)
+	}
+	return true
+}
+
+// pipelineTest sets up a processor of public.users.email in batches of 2
+// rows, over a mock whose statements may run in any order, as those of
+// the pipeline's stages interleave.
+func pipelineTest(t *testing.T, gen generator.Generator) (*ColumnProcessor,
+	sqlmock.Sqlmock) {
+
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyConverter{}))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dict, err := NewDictionary(0)
+	if err != nil {
+		t.Fatalf("failed to create dictionary: %v", err)
+	}
+	t.Cleanup(func() { dict.Close() })
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+	mock.ExpectExec(`DECLARE anon_public_users_email CURSOR`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	return NewColumnProcessor(tx, col, "text", gen, dict, 2, false), mock
+}
+
+// expectFetch expects a FETCH returning rows of the given CTIDs.
+func expectFetch(mock sqlmock.Sqlmock, ctids ...string) {
+	rows := sqlmock.NewRows([]string{"ctid", "email"})
+	for _, ctid := range ctids {
+		rows.AddRow(ctid, "user"+ctid+"@example.com")
+	}
+	mock.ExpectQuery(`FETCH 2 FROM anon_public_users_email`).WillReturnRows(rows)
+}
+
+// TestPipelineOrder tests that every batch is written, in the order it
+// was fetched
+func TestPipelineOrder(t *testing.T) {
+	p, mock := pipelineTest(t, &prefixGenerator{})
+
+	updated := &updatedCTIDs{}
+	expectFetch(mock, "(0,1)", "(0,2)")
+	expectFetch(mock, "(0,3)", "(0,4)")
+	expectFetch(mock, "(0,5)")
+	expectFetch(mock)
+	for range 3 {
+		mock.ExpectExec(`UPDATE "public"."users" t`).
+			WithArgs(updated, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+	}
+	mock.ExpectExec(`CLOSE anon_public_users_email`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var progress []int64
+	result, err := p.Process(context.Background(), func(processed int64) {
+		progress = append(progress, processed)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][]string{{"(0,1)", "(0,2)"}, {"(0,3)", "(0,4)"}, {"(0,5)"}}
+	if !slices.EqualFunc(updated.batches, want, slices.Equal[[]string]) {
+		t.Errorf("expected batches %v, got %v", want, updated.batches)
+	}
+	if !slices.Equal(progress, []int64{2, 4, 5}) {
+		t.Errorf("unexpected progress %v", progress)
+	}
+	if result.RowsProcessed != 5 || result.ValuesAnonymized != 5 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestPipelineErrors tests that the failure of any stage stops the
+// pipeline, and is the error returned, rather than the cancellation of
+// the other stages
+func TestPipelineErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		failAfter int32
+		fetchErr  error
+		updateErr error
+		want      string
+	}{
+		{name: "fetch", fetchErr: stderrors.New("connection lost"),
+			want: "connection lost"},
+		{name: "generate", failAfter: 2, want: "generator failed"},
+		{name: "update", updateErr: stderrors.New("disk full"),
+			want: "disk full"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mock := pipelineTest(t, &prefixGenerator{
+				failAfter: tt.failAfter})
+
+			updated := &updatedCTIDs{}
+			expectFetch(mock, "(0,1)", "(0,2)")
+			if tt.fetchErr != nil {
+				mock.ExpectQuery(`FETCH 2 FROM anon_public_users_email`).
+					WillReturnError(tt.fetchErr)
+			} else {
+				expectFetch(mock, "(0,3)", "(0,4)")
+				expectFetch(mock, "(0,5)")
+				expectFetch(mock)
+			}
+			update := mock.ExpectExec(`UPDATE "public"."users" t`).
+				WithArgs(updated, sqlmock.AnyArg())
+			if tt.updateErr != nil {
+				update.WillReturnError(tt.updateErr)
+			} else {
+				update.WillReturnResult(sqlmock.NewResult(0, 2))
+			}
+
+			_, err := runPipeline(t, p, context.Background(), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error containing %q, got %v", tt.want,
+					err)
+			}
+
+			// Nothing is written after the batch that failed
+			if len(updated.batches) > 1 {
+				t.Errorf("expected at most the first batch written, got %v",
+					updated.batches)
+			}
+		})
+	}
+}
+
+// TestPipelineCancel tests that cancelling the context stops every stage
+func TestPipelineCancel(t *testing.T) {
+	p, mock := pipelineTest(t, &prefixGenerator{})
+
+	// More batches than the stages can hold, so that the fetch stage is
+	// still running when the first batch is written
+	for i := range 20 {
+		expectFetch(mock, fmt.Sprintf("(%d,1)", i), fmt.Sprintf("(%d,2)", i))
+		mock.ExpectExec(`UPDATE "public"."users" t`).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := runPipeline(t, p, ctx, func(int64) { cancel() })
+	if !stderrors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancellation, got %v", err)
+	}
+}
+
+// runPipeline processes a column, failing the test if the pipeline does
+// not stop.
+func runPipeline(t *testing.T, p *ColumnProcessor, ctx context.Context,
+	progress func(int64)) (*ProcessResult, error) {
+
+	t.Helper()
+	type outcome struct {
+		result *ProcessResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := p.Process(ctx, progress)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-time.After(10 * time.Second):
+		t.Fatal("the pipeline did not stop")
+		return nil, nil
+	}
+}
@@ -0,0 +1,306 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/plan"
+)
+
+// Plan returns the plan of the run the options configure: its columns in
+// processing order, with those it would skip, how each would be
+// anonymized, and estimates of the rows read and the time taken. The
+// database is read in read-only transactions, and nothing is changed. In
+// copy mode, the database copied from is planned, as the one copied to
+// only holds its rows once the run copies them.
+func Plan(ctx context.Context, opts Options) (*plan.Plan, error) {
+	genManager, err := newGenerators(opts)
+	if err != nil {
+		return nil, err
+	}
+	batchSize, throttle := batchSettings(opts)
+
+	a := &Anonymizer{
+		config:     opts.Config,
+		patterns:   opts.Patterns,
+		generators: genManager,
+		connector:  database.NewConnector(&opts.Config.Database),
+		batchSize:  batchSize,
+		throttle:   throttle,
+		quiet:      true,
+	}
+	defer a.Close()
+	a.connector.SetQuiet(opts.Quiet)
+	a.connector.SetReadOnly(true)
+
+	return a.plan(ctx)
+}
+
+// plan finds the columns a run would anonymize, as run does, and plans
+// each of them.
+func (a *Anonymizer) plan(ctx context.Context) (*plan.Plan, error) {
+	ckpt, err := a.openCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.connector.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	result := &plan.Plan{
+		Database:        a.config.Database.DatabaseName(),
+		TransactionMode: a.transactionMode(),
+	}
+
+	// Find the columns as the run does
+	validator := database.NewSchemaValidator(a.connector.DB())
+	colConfigs, err := validator.ExpandWildcards(ctx, a.config.Columns)
+	if err != nil {
+		return nil, err
+	}
+	colConfigs, resolved, err := validator.ResolveViewColumns(ctx, colConfigs)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resolved {
+		result.Views = append(result.Views, fmt.Sprintf("%s -> %s",
+			r.View.String(), r.Table.String()))
+	}
+	colConfigs, err = ResolvePartitions(ctx, validator, a.config, colConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	fkAnalyzer := database.NewFKAnalyzer(a.connector.DB())
+	fkAnalyzer.SetReferences(a.config.DeclaredReferences())
+	colConfigs, propagated, err := PropagateReferences(ctx, a.config,
+		fkAnalyzer, colConfigs)
+	if err != nil {
+		return nil, err
+	}
+	for _, rc := range propagated {
+		result.Propagated = append(result.Propagated, fmt.Sprintf(
+			"%s -> %s (%s)", rc.Parent.String(), rc.Child.String(), rc.Via()))
+	}
+
+	colConfigs, a.onlyTables, err = validator.ExpandInheritance(ctx,
+		colConfigs)
+	if err != nil {
+		return nil, err
+	}
+	cfg := a.config.WithColumns(colConfigs)
+
+	columns, err := cfg.GetColumnRefs()
+	if err != nil {
+		return nil, err
+	}
+	missing, err := validator.ValidateColumns(ctx,
+		append(cfg.ReferencedColumns(), columns...))
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, errors.NewValidationError(
+			"columns not found in database", missing)
+	}
+	if err := CheckColumnTypes(ctx, validator, a.generators,
+		cfg.Columns); err != nil {
+		return nil, err
+	}
+	if err := CheckProfileKeys(ctx, validator, cfg.Columns); err != nil {
+		return nil, err
+	}
+	if err := CheckSubsetTables(columns, cfg.Tables); err != nil {
+		return nil, err
+	}
+
+	// Order the columns, and find those the run skips, as the run does
+	ordered, err := fkAnalyzer.GetProcessingOrder(ctx, columns)
+	if err != nil {
+		return nil, err
+	}
+	cascadeTargets, err := fkAnalyzer.GetCascadeTargets(ctx, columns)
+	if err != nil {
+		return nil, err
+	}
+	skipSet := make(map[string]string)
+	for _, col := range cascadeTargets {
+		skipSet[col.String()] = "CASCADE target"
+	}
+	generated, err := CheckGeneratedColumns(ctx, validator, columns)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range generated {
+		skipSet[s.Column.String()] = s.Reason
+	}
+
+	columnConfigMap := make(map[string]config.ColumnConfig)
+	for _, cc := range cfg.Columns {
+		columnConfigMap[cc.Column] = cc
+	}
+	ordered = orderDerived(ordered, columnConfigMap)
+
+	// The tables an earlier run committed are listed as skipped, rather
+	// than left out
+	remaining := make(map[string]bool)
+	for _, col := range resumeColumns(ordered, ckpt) {
+		remaining[col.String()] = true
+	}
+	for _, col := range ordered {
+		if !remaining[col.String()] && skipSet[col.String()] == "" {
+			skipSet[col.String()] = "committed by an earlier run"
+		}
+	}
+
+	if a.config.AnalyzeColumns {
+		a.analysis, err = a.analyzeColumns(ctx, validator, ordered, skipSet,
+			columnConfigMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	units := [][]errors.ColumnRef{ordered}
+	if result.TransactionMode != config.TransactionSingle {
+		fks, err := fkAnalyzer.Analyze(ctx, columns)
+		if err != nil {
+			return nil, err
+		}
+		units = transactionUnits(ordered, fks)
+	}
+
+	expected := a.expectedThroughput(ctx)
+	for i, unit := range units {
+		for _, col := range unit {
+			pc := plan.Column{
+				Column:      col.String(),
+				Transaction: i + 1,
+				Skip:        skipSet[col.String()],
+			}
+			if pc.Skip == "" {
+				if err := a.planColumn(ctx, validator, col,
+					columnConfigMap[col.String()], expected, &pc); err != nil {
+					return nil, err
+				}
+			}
+			result.Columns = append(result.Columns, pc)
+		}
+	}
+	result.Transactions = len(units)
+	result.Estimate(a.config.MaxRowsPerSecond, a.config.BatchPause())
+
+	return result, nil
+}
+
+// planColumn plans how a column would be anonymized.
+func (a *Anonymizer) planColumn(ctx context.Context,
+	validator *database.SchemaValidator, col errors.ColumnRef,
+	colConfig config.ColumnConfig, expected map[string]float64,
+	pc *plan.Column) error {
+
+	var err error
+	if pc.DataType, err = validator.GetColumnDataType(ctx, col); err != nil {
+		return fmt.Errorf("failed to get data type for %s: %w", col.String(),
+			err)
+	}
+	if pc.Unique, err = validator.HasUniqueConstraint(ctx, col); err != nil {
+		return fmt.Errorf("failed to check unique constraint for %s: %w",
+			col.String(), err)
+	}
+	if pc.Rows, err = validator.GetTableRowEstimate(ctx, col.Schema,
+		col.Table); err != nil {
+		return err
+	}
+	pc.SamplePercent = colConfig.SamplePercent
+	if c, ok := a.analysis[col.String()]; ok {
+		pc.Distinct, pc.DistinctSampled = c.Distinct, c.Sampled
+		pc.Analyzed = true
+	}
+	pc.RowsPerSecond, pc.Measured = expected[col.String()]
+
+	tuning, err := a.batchTuning(ctx, validator, col, colConfig)
+	if err != nil {
+		return err
+	}
+	pc.BatchSize = tuning.batchSize
+	pc.Strategy = tuning.strategy
+	if pc.Strategy == "" {
+		pc.Strategy = config.StrategyCursor
+	}
+	var simple bool
+	pc.Method, simple = planMethod(colConfig, pc.DataType)
+
+	switch {
+	case colConfig.IsFillColumn():
+		pc.Strategy = "single update"
+	case simple && tuning.samplePercent == 0 && colConfig.PartitionBy == "":
+		// A low-cardinality column's values are mapped up front
+		limit, err := a.distinctLimit(ctx, validator, col)
+		if err != nil {
+			return err
+		}
+		if limit > 0 {
+			pc.Strategy = "distinct values"
+		}
+	}
+	return nil
+}
+
+// planMethod describes how the values of a column are replaced, choosing
+// as anonymizeUnit does, and returns true if the column has a single
+// pattern, whose values are replaced by the column processor.
+func planMethod(colConfig config.ColumnConfig,
+	dataType string) (string, bool) {
+
+	switch {
+	case colConfig.IsJSONColumn():
+		return fmt.Sprintf("json_paths (%d)", len(colConfig.JSONPaths)), false
+	case colConfig.IsXMLColumn():
+		return fmt.Sprintf("xml_paths (%d)", len(colConfig.XMLPaths)), false
+	case colConfig.IsCompositeColumn():
+		return fmt.Sprintf("fields (%d)", len(colConfig.Fields)), false
+	case colConfig.IsDerivedColumn():
+		return "derive " + colConfig.Derive, false
+	case colConfig.IsProfileColumn():
+		return fmt.Sprintf("profile %s (%s)", colConfig.Profile.Profile,
+			colConfig.Profile.Field), false
+	case colConfig.IsNullifyColumn():
+		return "nullify", false
+	case colConfig.IsConstantColumn():
+		return "constant", false
+	case colConfig.IsShuffleColumn():
+		if colConfig.ShuffleBy != "" {
+			return "shuffle by " + colConfig.ShuffleBy, false
+		}
+		return "shuffle", false
+	case colConfig.IsDistributionColumn():
+		return "preserve distribution", false
+	case colConfig.IsDateShiftColumn():
+		return fmt.Sprintf("pattern %s by %s", colConfig.Pattern,
+			colConfig.Entity), false
+	case colConfig.IsLargeObjectColumn():
+		return "large objects, pattern " + colConfig.Pattern, false
+	case colConfig.IsDocumentColumn():
+		return "documents, pattern " + colConfig.Pattern, false
+	case colConfig.PartitionBy != "":
+		return fmt.Sprintf("pattern %s, partitioned by %s", colConfig.Pattern,
+			colConfig.PartitionBy), true
+	default:
+		return "pattern " + colConfig.Pattern, true
+	}
+}
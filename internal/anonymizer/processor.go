@@ -14,15 +14,21 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"maps"
 	"strings"
 
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
 	"github.com/pgedge/pgedge-anonymizer/internal/database"
 	"github.com/pgedge/pgedge-anonymizer/internal/errors"
 	"github.com/pgedge/pgedge-anonymizer/internal/generator"
 )
 
-// maxCollisionRetries is the maximum number of times to retry generating
-// a unique value when collisions occur.
+// defaultLowCardinalityThreshold is the most distinct values a column may
+// have, by default, for them to be mapped up front.
+const defaultLowCardinalityThreshold = 1000
+
+// maxCollisionRetries is the maximum number of suffixes or sequence
+// numbers tried to make a value unique when collisions occur.
 const maxCollisionRetries = 100
 
 // addUniqueSuffix adds a numeric suffix to make a value unique.
@@ -46,6 +52,24 @@ type ColumnProcessor struct {
 	dictionary          *Dictionary
 	batchSize           int
 	hasUniqueConstraint bool
+	batchHook           batchHookFunc
+	trace               *columnTrace
+
+	// collision makes values unique in columns with a unique constraint;
+	// nil for the default policy
+	collision *collisionPolicy
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
+
+	// distinctLimit is the most distinct values the column may have for
+	// them to be mapped up front and updated in a single statement; zero
+	// processes the column row by row.
+	distinctLimit int
+
+	// partitionBy is the column whose value scopes the mappings of each
+	// row, or empty if the column is mapped as a whole
+	partitionBy string
 }
 
 // NewColumnProcessor creates a new column processor.
@@ -74,6 +98,94 @@ type ProcessResult struct {
 	RowsProcessed    int64
 	ValuesAnonymized int64
 	UniqueValues     int64
+
+	// Collisions is the number of generated values already given to
+	// another value, in columns with a unique constraint, and
+	// CollisionFallbacks the number of those still colliding after being
+	// generated again, made unique by the column's on_collision strategy.
+	Collisions         int64
+	CollisionFallbacks int64
+
+	// MaxStatementBytes is the size of the largest batch update statement.
+	MaxStatementBytes int64
+
+	// Phases is the time spent reading and writing rows, and throttled.
+	Phases database.PhaseTimes
+}
+
+// batchTuning holds the settings tuning how a processor reads batches and
+// writes them back; zero values use the defaults.
+type batchTuning struct {
+	batchSize         int
+	fetchSize         int
+	updateChunkSize   int
+	maxStatementBytes int64
+
+	// strategy is one of the config.Strategy values, with the primary key
+	// for config.StrategyKeyset, and the copier for config.StrategyCopy
+	strategy   string
+	keyColumns []database.KeyColumn
+	copier     database.Copier
+
+	// commit commits the transaction after each batch, in per-batch
+	// transaction mode
+	commit database.CommitFunc
+
+	// throttle limits the rate of the run, shared by all its columns
+	throttle *database.Throttle
+
+	// samplePercent reads only a random sample of about this percentage
+	// of the rows, if set
+	samplePercent float64
+
+	// only reads and updates the rows of the table itself, not those of
+	// the tables inheriting from it, which are processed on their own
+	only bool
+
+	// oversized handles the values larger than max_value_bytes, if set
+	oversized *oversizedValues
+}
+
+// tunableBatch is a batch processor the tuning settings apply to.
+type tunableBatch interface {
+	SetFetchSize(n int)
+	SetUpdateChunkSize(n int)
+	SetMaxStatementBytes(n int64)
+	SetCommit(commit database.CommitFunc)
+	SetThrottle(throttle *database.Throttle)
+	SetSample(percent float64)
+	SetOnly(only bool)
+}
+
+// usesCursor returns true if rows are read with a cursor and written a
+// batch at a time, as with the default strategy.
+func (t batchTuning) usesCursor() bool {
+	return t.strategy == "" || t.strategy == config.StrategyCursor
+}
+
+// apply applies the settings to a batch processor. Only whole-value batch
+// processors support the keyset and copy strategies, and limit the size
+// of the values read; the others read only values within documents.
+func (t batchTuning) apply(batch tunableBatch) {
+	batch.SetFetchSize(t.fetchSize)
+	batch.SetUpdateChunkSize(t.updateChunkSize)
+	batch.SetMaxStatementBytes(t.maxStatementBytes)
+	batch.SetCommit(t.commit)
+	batch.SetThrottle(t.throttle)
+	batch.SetSample(t.samplePercent)
+	batch.SetOnly(t.only)
+
+	if b, ok := batch.(*database.BatchProcessor); ok {
+		switch t.strategy {
+		case config.StrategyKeyset:
+			b.SetKeyset(t.keyColumns)
+		case config.StrategyCopy:
+			b.SetCopy(t.copier)
+		}
+		if t.oversized != nil {
+			b.SetMaxValueBytes(t.oversized.limit, t.oversized.check)
+		}
+	}
 }
 
 // Process anonymizes all values in the column.
@@ -81,6 +193,21 @@ func (p *ColumnProcessor) Process(ctx context.Context,
 	progress func(processed int64)) (*ProcessResult, error) {
 
 	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
+	p.tuning.apply(batch)
+	if p.partitionBy != "" {
+		batch.SetIdentity(p.partitionBy)
+	}
+
+	// Map the values of a low-cardinality column up front
+	if p.distinctLimit > 0 {
+		values, ok, err := batch.FetchDistinct(ctx, p.distinctLimit)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return p.processDistinct(ctx, batch, values, progress)
+		}
+	}
 
 	// Open cursor
 	if err := batch.OpenCursor(ctx); err != nil {
@@ -89,7 +216,12 @@ func (p *ColumnProcessor) Process(ctx context.Context,
 	defer func() { _ = batch.CloseCursor(ctx) }()
 
 	result := &ProcessResult{}
-	uniqueSeen := make(map[string]bool)
+	if p.pipelined() {
+		if err := p.processPipelined(ctx, batch, result, progress); err != nil {
+			return nil, err
+		}
+		return p.finish(ctx, batch, result)
+	}
 
 	for {
 		// Check for cancellation
@@ -109,58 +241,14 @@ func (p *ColumnProcessor) Process(ctx context.Context,
 			break // No more rows
 		}
 
-		// Process batch
-		updates := make(map[string]string)
-
-		for _, row := range rows {
-			// Skip empty values
-			if row.Value == "" {
-				continue
-			}
-
-			// Check dictionary for existing mapping
-			anonymized, exists := p.dictionary.Get(row.Value)
-			if !exists {
-				// Generate new anonymized value
-				anonymized = p.generator.Generate(row.Value)
-
-				// For columns with unique constraints, use uniqueness checking
-				// to avoid constraint violations. For other columns, just store
-				// directly since duplicates are allowed.
-				if p.hasUniqueConstraint {
-					// Try to set with uniqueness check, retry with suffix if needed
-					if !p.dictionary.SetUnique(row.Value, anonymized) {
-						// Collision detected - retry with numeric suffix
-						base := anonymized
-						found := false
-						for i := 1; i <= maxCollisionRetries; i++ {
-							anonymized = addUniqueSuffix(base, i)
-							if p.dictionary.SetUnique(row.Value, anonymized) {
-								found = true
-								break
-							}
-						}
-						if !found {
-							return nil, fmt.Errorf(
-								"failed to generate unique value after %d attempts",
-								maxCollisionRetries)
-						}
-					}
-				} else {
-					// No unique constraint: just store without uniqueness check
-					p.dictionary.Set(row.Value, anonymized)
-				}
-				result.UniqueValues++
-			}
-
-			// Track unique values seen
-			if !uniqueSeen[row.Value] {
-				uniqueSeen[row.Value] = true
-			}
+		if err := p.batchHook.call(ctx, HookBeforeBatch, len(rows)); err != nil {
+			return nil, err
+		}
 
-			// Queue update
-			updates[row.CTID] = anonymized
-			result.ValuesAnonymized++
+		// Process batch
+		updates, err := p.anonymizeBatch(rows, result)
+		if err != nil {
+			return nil, err
 		}
 
 		// Apply batch updates
@@ -172,11 +260,233 @@ func (p *ColumnProcessor) Process(ctx context.Context,
 
 		result.RowsProcessed += int64(len(rows))
 
+		if err := p.batchHook.call(ctx, HookAfterBatch, len(rows)); err != nil {
+			return nil, err
+		}
+		if err := batch.EndBatch(ctx); err != nil {
+			return nil, err
+		}
+
 		// Report progress
 		if progress != nil {
 			progress(result.RowsProcessed)
 		}
 	}
 
+	return p.finish(ctx, batch, result)
+}
+
+// finish writes any updates staged by the copy strategy, and completes
+// the result with the batch processor's statistics.
+func (p *ColumnProcessor) finish(ctx context.Context,
+	batch *database.BatchProcessor, result *ProcessResult) (*ProcessResult,
+	error) {
+
+	if err := batch.Finish(ctx); err != nil {
+		return nil, err
+	}
+
+	result.MaxStatementBytes = batch.MaxStatementBytes()
+	result.Phases = batch.PhaseTimes()
+	return result, nil
+}
+
+// anonymizeBatch returns the anonymized values of a batch of rows, by
+// their CTIDs, leaving out empty values. It fails if the generator has,
+// before any of its values are written.
+func (p *ColumnProcessor) anonymizeBatch(rows []database.RowData,
+	result *ProcessResult) (map[string]string, error) {
+
+	if p.partitionBy == "" {
+		return p.anonymizeRows(rows, result)
+	}
+
+	// Map the values of each partition in a namespace of its own, read as
+	// the rows' identity
+	var order []string
+	partitions := make(map[string][]database.RowData)
+	for _, row := range rows {
+		if _, ok := partitions[row.Identity]; !ok {
+			order = append(order, row.Identity)
+		}
+		partitions[row.Identity] = append(partitions[row.Identity], row)
+	}
+
+	updates := make(map[string]string, len(rows))
+	for _, partition := range order {
+		pp := *p
+		pp.dictionary = p.dictionary.Partition(partition)
+		partUpdates, err := pp.anonymizeRows(partitions[partition], result)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(updates, partUpdates)
+	}
+	return updates, nil
+}
+
+// anonymizeRows returns the anonymized values of rows mapped in the
+// processor's dictionary, as anonymizeBatch does.
+func (p *ColumnProcessor) anonymizeRows(rows []database.RowData,
+	result *ProcessResult) (map[string]string, error) {
+
+	updates := make(map[string]string)
+	generated := p.generateBatch(rows)
+
+	for _, row := range rows {
+		p.trace.setRow(row.CTID)
+
+		// Skip empty values
+		if row.Value == "" {
+			continue
+		}
+
+		anonymized, err := p.anonymizeValue(row.Value, generated, result)
+		if err != nil {
+			return nil, err
+		}
+
+		// Queue update
+		updates[row.CTID] = anonymized
+		result.ValuesAnonymized++
+	}
+
+	// Stop before writing values from a generator that has failed
+	if f, ok := p.generator.(generator.FallibleGenerator); ok {
+		if err := f.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return updates, nil
+}
+
+// processDistinct anonymizes a column by mapping each of its distinct
+// values, and writing the mapping with a single statement, which avoids
+// fetching and updating the rows in batches when the column holds few
+// distinct values, such as a status or category.
+func (p *ColumnProcessor) processDistinct(ctx context.Context,
+	batch *database.BatchProcessor, values []database.DistinctValue,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	result := &ProcessResult{}
+
+	var rows int64
+	distinct := make([]database.RowData, 0, len(values))
+	for _, v := range values {
+		rows += v.Rows
+		distinct = append(distinct, database.RowData{Value: v.Value})
+	}
+
+	if err := p.batchHook.call(ctx, HookBeforeBatch, int(rows)); err != nil {
+		return nil, err
+	}
+
+	// Empty values are left as they are, as when processing row by row
+	mapping := make(map[string]string, len(values))
+	generated := p.generateBatch(distinct)
+	for _, v := range values {
+		if v.Value == "" {
+			continue
+		}
+		anonymized, err := p.anonymizeValue(v.Value, generated, result)
+		if err != nil {
+			return nil, err
+		}
+		mapping[v.Value] = anonymized
+	}
+
+	// Stop before writing values from a generator that has failed
+	if f, ok := p.generator.(generator.FallibleGenerator); ok {
+		if err := f.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := batch.UpdateByValue(ctx, mapping)
+	if err != nil {
+		return nil, err
+	}
+	result.ValuesAnonymized = updated
+	result.RowsProcessed = rows
+
+	if err := p.batchHook.call(ctx, HookAfterBatch, int(rows)); err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		progress(result.RowsProcessed)
+	}
+
+	result.MaxStatementBytes = batch.MaxStatementBytes()
+	result.Phases = batch.PhaseTimes()
 	return result, nil
 }
+
+// anonymizeValue returns the anonymized value for an original value: the
+// value it is mapped to in the dictionary, or else a newly generated value,
+// taken from generated if present, which is then added to the dictionary.
+func (p *ColumnProcessor) anonymizeValue(value string,
+	generated map[string]string, result *ProcessResult) (string, error) {
+
+	// Check dictionary for existing mapping
+	anonymized, exists := p.dictionary.Get(value)
+	if exists {
+		return anonymized, nil
+	}
+
+	// Generate new anonymized value
+	var ok bool
+	if anonymized, ok = generated[value]; !ok {
+		anonymized = p.generator.Generate(value)
+	}
+
+	// For columns with unique constraints, use uniqueness checking
+	// to avoid constraint violations. For other columns, just store
+	// directly since duplicates are allowed.
+	if p.hasUniqueConstraint {
+		// Make the value unique by the column's collision policy
+		var err error
+		if anonymized, err = p.setUnique(value, anonymized,
+			result); err != nil {
+			return "", err
+		}
+	} else {
+		// No unique constraint: just store without uniqueness check
+		p.dictionary.Set(value, anonymized)
+	}
+	result.UniqueValues++
+
+	return anonymized, nil
+}
+
+// generateBatch generates values for all distinct, not yet mapped values in
+// a batch with a single call when the generator supports batching, which
+// avoids a round trip per value for generators backed by external commands.
+// It returns nil for generators that do not support batching.
+func (p *ColumnProcessor) generateBatch(rows []database.RowData) map[string]string {
+	batchGen, ok := p.generator.(generator.BatchGenerator)
+	if !ok {
+		return nil
+	}
+
+	var inputs []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if row.Value == "" || seen[row.Value] {
+			continue
+		}
+		seen[row.Value] = true
+		if _, exists := p.dictionary.Get(row.Value); !exists {
+			inputs = append(inputs, row.Value)
+		}
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	outputs := batchGen.GenerateBatch(inputs)
+	generated := make(map[string]string, len(inputs))
+	for i, input := range inputs {
+		generated[input] = outputs[i]
+	}
+	return generated
+}
@@ -0,0 +1,215 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// ProfileColumnProcessor processes a column filled from a profile's
+// personas. Each row's value is replaced with a field of the persona of
+// the person the row's identity column names, so that every column of
+// the profile, in any table, describes the same generated person. A table
+// without an identity column is identified by its primary key, making
+// each row a person whose columns all describe the same persona.
+type ProfileColumnProcessor struct {
+	tx         *sql.Tx
+	column     errors.ColumnRef
+	dataType   string
+	profile    config.ProfileColumn
+	keyColumns []string // Columns identifying each row's person
+	generators *generator.Manager
+	dictionary *Dictionary
+	batchSize  int
+	batchHook  batchHookFunc
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
+}
+
+// NewProfileColumnProcessor creates a new profile column processor.
+func NewProfileColumnProcessor(
+	tx *sql.Tx,
+	column errors.ColumnRef,
+	dataType string,
+	profile config.ProfileColumn,
+	keyColumns []string,
+	generators *generator.Manager,
+	dict *Dictionary,
+	batchSize int,
+) *ProfileColumnProcessor {
+	return &ProfileColumnProcessor{
+		tx:         tx,
+		column:     column,
+		dataType:   dataType,
+		profile:    profile,
+		keyColumns: keyColumns,
+		generators: generators,
+		dictionary: dict,
+		batchSize:  batchSize,
+	}
+}
+
+// Process replaces every value in the column with its persona's field.
+func (p *ProfileColumnProcessor) Process(ctx context.Context,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
+	p.tuning.apply(batch)
+	batch.SetIdentity(p.keyColumns...)
+
+	identities := make(map[string]bool)
+	loop := &batchLoop{batch: batch, batchHook: p.batchHook}
+	result, err := loop.run(ctx, func(row database.RowData) (string, int, error) {
+		persona, err := p.persona(p.identity(row.Identity))
+		if err != nil {
+			return "", 0, err
+		}
+		p.dictionary.RecordOriginal(row.Value)
+
+		identities[row.Identity] = true
+		return persona.Field(p.profile.Field, row.Value), 1, nil
+	}, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	result.UniqueValues = int64(len(identities))
+	return result, nil
+}
+
+// identity returns the key a row's persona is stored under. Rows
+// identified by their primary key are keyed by their table too, as the
+// same key in another table is another person.
+func (p *ProfileColumnProcessor) identity(value string) string {
+	if p.profile.Identity != "" || value == "" {
+		return value
+	}
+	return p.column.Schema + "." + p.column.Table + "\x00" + value
+}
+
+// persona returns the persona of an identity, generating and storing one
+// if the identity has none yet. A row whose identity is NULL belongs to
+// no known person, and is given a persona of its own.
+func (p *ProfileColumnProcessor) persona(identity string) (*generator.Persona,
+	error) {
+
+	if identity != "" {
+		if encoded, ok := p.dictionary.GetIdentity(p.profile.Profile,
+			identity); ok {
+			var persona generator.Persona
+			if err := json.Unmarshal([]byte(encoded), &persona); err != nil {
+				return nil, fmt.Errorf("failed to decode persona: %w", err)
+			}
+			return &persona, nil
+		}
+	}
+
+	persona, err := p.generators.NewPersona(p.profile.Country)
+	if err != nil {
+		return nil, err
+	}
+	if identity != "" {
+		encoded, err := json.Marshal(persona)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode persona: %w", err)
+		}
+		p.dictionary.SetIdentity(p.profile.Profile, identity, string(encoded))
+	}
+	return persona, nil
+}
+
+// ProfileKeyColumns returns the columns identifying the person each row of
+// a profile column's table belongs to: its identity column, or else the
+// table's primary key.
+func ProfileKeyColumns(ctx context.Context,
+	validator *database.SchemaValidator, col errors.ColumnRef,
+	profile config.ProfileColumn) ([]string, error) {
+
+	if profile.Identity != "" {
+		return []string{profile.Identity}, nil
+	}
+
+	key, err := validator.GetPrimaryKey(ctx, col.Schema, col.Table)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(key))
+	for i, kc := range key {
+		names[i] = kc.Name
+	}
+	return names, nil
+}
+
+// CheckProfileKeys checks that each profile table without an identity
+// column has a primary key to identify its rows by, and that the key is
+// not anonymized, as its values would change part way through the run.
+// It returns a validation error listing the tables that cannot be
+// identified.
+func CheckProfileKeys(ctx context.Context,
+	validator *database.SchemaValidator,
+	colConfigs []config.ColumnConfig) error {
+
+	anonymized := make(map[string]bool)
+	for _, colConfig := range colConfigs {
+		anonymized[colConfig.Column] = true
+	}
+
+	var noKey, anonymizedKey []errors.ColumnRef
+	checked := make(map[string]bool)
+	for _, colConfig := range colConfigs {
+		if !colConfig.IsProfileColumn() || colConfig.Profile.Identity != "" {
+			continue
+		}
+
+		col, err := errors.ParseColumnRef(colConfig.Column)
+		if err != nil {
+			return err
+		}
+		table := col.Schema + "." + col.Table
+		if checked[table] {
+			continue
+		}
+		checked[table] = true
+
+		key, err := ProfileKeyColumns(ctx, validator, col, *colConfig.Profile)
+		if err != nil {
+			return err
+		}
+		if len(key) == 0 {
+			noKey = append(noKey, col)
+		}
+		for _, name := range key {
+			if anonymized[table+"."+name] {
+				anonymizedKey = append(anonymizedKey, errors.ColumnRef{
+					Schema: col.Schema, Table: col.Table, Column: name})
+			}
+		}
+	}
+
+	if len(noKey) > 0 {
+		return errors.NewValidationError("profile tables without an identity "+
+			"column must have a primary key", noKey)
+	}
+	if len(anonymizedKey) > 0 {
+		return errors.NewValidationError("the primary key identifying the "+
+			"rows of a profile table cannot be anonymized", anonymizedKey)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// progressInterval is the number of rows between progress reports.
+const progressInterval = 10000
+
+// tableProgress reports the progress of anonymizing the columns of a table
+// that are processed one after another, as a single table: its row
+// estimate is read once, and the values anonymized are totalled across
+// its columns. A table whose columns are split by those of other tables,
+// to satisfy dependencies, is reported once for each run of its columns.
+type tableProgress struct {
+	quiet   bool
+	table   string
+	columns []string // Names of the table's columns in the run
+
+	column     string // Column being processed
+	done       int    // Columns completed
+	rows       int64  // Rows of the table processed, the most of any column
+	values     int64  // Values anonymized in all the completed columns
+	lastReport int64
+}
+
+// tableRuns splits the columns of a unit into runs of consecutive columns
+// of the same table, leaving out those skipped.
+func tableRuns(unit []errors.ColumnRef,
+	skipSet map[string]string) [][]errors.ColumnRef {
+
+	var runs [][]errors.ColumnRef
+	for _, col := range unit {
+		if skipSet[col.String()] != "" {
+			continue
+		}
+		if n := len(runs); n > 0 && runs[n-1][0].Schema == col.Schema &&
+			runs[n-1][0].Table == col.Table {
+			runs[n-1] = append(runs[n-1], col)
+			continue
+		}
+		runs = append(runs, []errors.ColumnRef{col})
+	}
+	return runs
+}
+
+// startTable reports the start of a run of a table's columns, with the
+// table's estimated row count, and returns its progress.
+func (a *Anonymizer) startTable(ctx context.Context,
+	validator *database.SchemaValidator,
+	run []errors.ColumnRef) *tableProgress {
+
+	p := &tableProgress{
+		quiet: a.quiet,
+		table: run[0].Schema + "." + run[0].Table,
+	}
+	for _, col := range run {
+		p.columns = append(p.columns, col.Column)
+	}
+	if p.quiet {
+		return p
+	}
+
+	estimate, _ := validator.GetTableRowEstimate(ctx, run[0].Schema,
+		run[0].Table)
+	if len(run) == 1 {
+		fmt.Printf("Processing %s (est. %d rows)...\n", run[0].String(),
+			estimate)
+	} else {
+		fmt.Printf("Processing %s (est. %d rows): %d columns, %s...\n",
+			p.table, estimate, len(run), strings.Join(p.columns, ", "))
+	}
+	return p
+}
+
+// startColumn begins the processing of one of the table's columns.
+func (p *tableProgress) startColumn(col errors.ColumnRef) {
+	p.column = col.Column
+	p.lastReport = 0
+}
+
+// update reports the number of rows of the current column processed so
+// far, every progressInterval rows.
+func (p *tableProgress) update(processed int64) {
+	if p.quiet || processed-p.lastReport < progressInterval {
+		return
+	}
+	p.lastReport = processed
+	if len(p.columns) == 1 {
+		fmt.Printf("  %d rows processed\n", processed)
+		return
+	}
+	fmt.Printf("  %d rows processed (%s, column %d of %d)\n", processed,
+		p.column, p.done+1, len(p.columns))
+}
+
+// finishColumn adds a completed column's counts to the table's, and
+// reports the table's totals once its last column is complete.
+func (p *tableProgress) finishColumn(result *ProcessResult) {
+	p.done++
+	p.rows = max(p.rows, result.RowsProcessed)
+	p.values += result.ValuesAnonymized
+
+	if p.quiet || p.done < len(p.columns) {
+		return
+	}
+	if len(p.columns) == 1 {
+		fmt.Printf("  Completed: %d rows, %d values anonymized\n", p.rows,
+			p.values)
+		return
+	}
+	fmt.Printf("  Completed: %d rows, %d values anonymized in %d columns\n",
+		p.rows, p.values, len(p.columns))
+}
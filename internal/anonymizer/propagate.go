@@ -0,0 +1,121 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// PropagateReferences adds, for each column declared in the references
+// section to reference an anonymized column, and, if
+// propagate_foreign_keys is set, each column whose foreign key references
+// one without cascading updates, directly or through other such columns,
+// an entry anonymizing it with the referenced column's settings, in the
+// same dictionary namespace, so that each value is given the replacement
+// of the value it references. A referencing column listed explicitly must
+// already be anonymized that way. It returns the entries, and the
+// referencing columns; those with a foreign key constraint have it
+// dropped while their tables are updated.
+func PropagateReferences(ctx context.Context, cfg *config.Config,
+	fkAnalyzer *database.FKAnalyzer,
+	columns []config.ColumnConfig) ([]config.ColumnConfig,
+	[]database.ReferencingColumn, error) {
+
+	expanded := append([]config.ColumnConfig(nil), columns...)
+	index := make(map[string]int)
+	var queue []errors.ColumnRef
+	for i, col := range expanded {
+		ref, err := errors.ParseColumnRef(col.Column)
+		if err != nil {
+			return nil, nil, err
+		}
+		index[col.Column] = i
+		queue = append(queue, ref)
+	}
+
+	declared := make(map[string][]database.ReferencingColumn)
+	for _, r := range cfg.DeclaredReferences() {
+		declared[r.Parent.String()] = append(declared[r.Parent.String()],
+			database.ReferencingColumn{Child: r.Child, Parent: r.Parent})
+	}
+
+	var propagated []database.ReferencingColumn
+	for len(queue) > 0 {
+		var referencing []database.ReferencingColumn
+		for _, ref := range queue {
+			referencing = append(referencing, declared[ref.String()]...)
+		}
+		if cfg.PropagateForeignKeys {
+			fks, err := fkAnalyzer.GetReferencingColumns(ctx, queue)
+			if err != nil {
+				return nil, nil, err
+			}
+			referencing = append(referencing, fks...)
+		}
+		queue = nil
+
+		for _, rc := range referencing {
+			pi := index[rc.Parent.String()]
+			parent := expanded[pi]
+			if parent.Pattern == "" || parent.IsJSONColumn() ||
+				parent.IsXMLColumn() || parent.IsCompositeColumn() ||
+				parent.IsLargeObjectColumn() || parent.IsDocumentColumn() ||
+				parent.SamplePercent > 0 {
+				return nil, nil, errors.NewValidationError(fmt.Sprintf(
+					"%s references %s, which must be anonymized with a "+
+						"single 'pattern' and no sample_percent for its "+
+						"values to be propagated", rc.Via(),
+					rc.Parent.String()), []errors.ColumnRef{rc.Child})
+			}
+
+			// A column listed explicitly must be given the same
+			// replacements
+			if ci, ok := index[rc.Child.String()]; ok {
+				child := expanded[ci]
+				if child.Pattern != parent.Pattern || child.SamplePercent > 0 ||
+					child.PartitionBy != parent.PartitionBy ||
+					ColumnNamespace(cfg, child, child.Pattern) !=
+						ColumnNamespace(cfg, parent, parent.Pattern) {
+					return nil, nil, errors.NewValidationError(fmt.Sprintf(
+						"%s references %s, which is anonymized differently; "+
+							"remove the column's entry, or give both the same "+
+							"pattern, consistency_group, and partition_by",
+						rc.Via(), rc.Parent.String()),
+						[]errors.ColumnRef{rc.Child})
+				}
+				propagated = append(propagated, rc)
+				continue
+			}
+
+			// Share the mappings of a column anonymized in a namespace
+			// of its own
+			if cfg.DictionaryScope == config.DictionaryScopeColumn &&
+				parent.ConsistencyGroup == "" {
+				parent.ConsistencyGroup = parent.Column
+				expanded[pi] = parent
+			}
+
+			child := parent
+			child.Column = rc.Child.String()
+			index[child.Column] = len(expanded)
+			expanded = append(expanded, child)
+			queue = append(queue, rc.Child)
+			propagated = append(propagated, rc)
+		}
+	}
+
+	return expanded, propagated, nil
+}
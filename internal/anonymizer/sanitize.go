@@ -0,0 +1,56 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// setSanitizer passes generated values through the configured sanitizers,
+// set up for the encoding of the database connected to. Without any
+// configured, the values written to a database whose encoding is not
+// UTF-8 are converted to characters it can hold.
+func (a *Anonymizer) setSanitizer(ctx context.Context,
+	validator *database.SchemaValidator) error {
+
+	encoding, err := validator.GetServerEncoding(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := a.config.Sanitize
+	if len(names) == 0 {
+		names = generator.DefaultSanitizers(encoding)
+		if len(names) > 0 && !a.quiet {
+			fmt.Printf("Database encoding is %s; converting generated "+
+				"values to characters it can hold\n", encoding)
+		}
+	}
+	if slices.Contains(names, generator.SanitizeStripInvalid) &&
+		!generator.IsKnownEncoding(encoding) {
+		log.Printf("Warning: the characters of the %s encoding are not "+
+			"known, so strip_invalid removes only invalid UTF-8 and NUL "+
+			"characters", encoding)
+	}
+
+	sanitizer, err := generator.NewSanitizer(names, encoding)
+	if err != nil {
+		return err
+	}
+	a.generators.SetSanitizer(sanitizer)
+	return nil
+}
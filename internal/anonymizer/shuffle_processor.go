@@ -0,0 +1,172 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// ShuffleColumnProcessor processes a column whose values are randomly
+// permuted among its rows, optionally only among the rows sharing the
+// value of a group column. The column keeps its exact set of values, and
+// so its distribution, while the link between each value and the rest of
+// its row is broken.
+type ShuffleColumnProcessor struct {
+	tx        *sql.Tx
+	column    errors.ColumnRef
+	dataType  string
+	shuffleBy string // Column grouping the rows, or empty for the whole column
+	batchSize int
+	batchHook batchHookFunc
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
+}
+
+// NewShuffleColumnProcessor creates a new shuffle column processor.
+func NewShuffleColumnProcessor(
+	tx *sql.Tx,
+	column errors.ColumnRef,
+	dataType string,
+	shuffleBy string,
+	batchSize int,
+) *ShuffleColumnProcessor {
+	return &ShuffleColumnProcessor{
+		tx:        tx,
+		column:    column,
+		dataType:  dataType,
+		shuffleBy: shuffleBy,
+		batchSize: batchSize,
+	}
+}
+
+// shuffleRow is a row read for shuffling.
+type shuffleRow struct {
+	ctid  string
+	value string
+}
+
+// Process reads every value of the column, permutes them within their
+// groups, and writes back those that moved. All the values are held in
+// memory until they are written.
+func (p *ShuffleColumnProcessor) Process(ctx context.Context,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
+	p.tuning.apply(batch)
+	if p.shuffleBy != "" {
+		batch.SetIdentity(p.shuffleBy)
+	}
+
+	groups, order, err := p.read(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProcessResult{}
+	distinct := make(map[string]bool)
+	updates := make(map[string]string)
+
+	for _, group := range order {
+		rows := groups[group]
+		values := make([]string, len(rows))
+		for i, row := range rows {
+			values[i] = row.value
+			distinct[row.value] = true
+		}
+		generator.Shuffle(len(values), func(i, j int) {
+			values[i], values[j] = values[j], values[i]
+		})
+
+		for i, row := range rows {
+			result.RowsProcessed++
+			if values[i] != row.value {
+				updates[row.ctid] = values[i]
+				result.ValuesAnonymized++
+			}
+
+			if len(updates) >= p.batchSize {
+				if err := p.write(ctx, batch, updates); err != nil {
+					return nil, err
+				}
+				updates = make(map[string]string)
+			}
+			if progress != nil {
+				progress(result.RowsProcessed)
+			}
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := p.write(ctx, batch, updates); err != nil {
+			return nil, err
+		}
+	}
+
+	// Write any updates staged by the copy strategy
+	if err := batch.Finish(ctx); err != nil {
+		return nil, err
+	}
+
+	result.UniqueValues = int64(len(distinct))
+	result.MaxStatementBytes = batch.MaxStatementBytes()
+	result.Phases = batch.PhaseTimes()
+	return result, nil
+}
+
+// read reads every non-NULL value of the column, grouped by the value of
+// the group column, and returns the groups in the order first read. Rows
+// whose group column is NULL are shuffled among themselves.
+func (p *ShuffleColumnProcessor) read(ctx context.Context,
+	batch *database.BatchProcessor) (map[string][]shuffleRow, []string,
+	error) {
+
+	groups := make(map[string][]shuffleRow)
+	var order []string
+
+	// The rows are only written once all have been read, so the cursor
+	// is closed before the first update
+	err := fetchBatches(ctx, batch, func(rows []database.RowData) error {
+		for _, row := range rows {
+			if _, ok := groups[row.Identity]; !ok {
+				order = append(order, row.Identity)
+			}
+			groups[row.Identity] = append(groups[row.Identity],
+				shuffleRow{ctid: row.CTID, value: row.Value})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return groups, order, nil
+}
+
+// write writes a batch of shuffled values.
+func (p *ShuffleColumnProcessor) write(ctx context.Context,
+	batch *database.BatchProcessor, updates map[string]string) error {
+
+	if err := p.batchHook.call(ctx, HookBeforeBatch, len(updates)); err != nil {
+		return err
+	}
+	if err := batch.UpdateBatch(ctx, updates); err != nil {
+		return err
+	}
+	if err := p.batchHook.call(ctx, HookAfterBatch, len(updates)); err != nil {
+		return err
+	}
+	return batch.EndBatch(ctx)
+}
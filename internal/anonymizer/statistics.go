@@ -0,0 +1,128 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// columnStatistics holds the values in the planner statistics of a
+// column from before the run.
+type columnStatistics struct {
+	column errors.ColumnRef
+	values []string
+}
+
+// captureStatistics returns the values in the planner statistics of the
+// columns anonymized with a single pattern, a profile, or a derive rule,
+// so that they can be checked for after the tables are analyzed. Columns
+// whose statistics cannot be read are reported and left out.
+func (a *Anonymizer) captureStatistics(ctx context.Context,
+	validator *database.SchemaValidator, columns []errors.ColumnRef,
+	configs map[string]config.ColumnConfig) []columnStatistics {
+
+	if a.config.SkipAnalyze {
+		log.Printf("Warning: skip_analyze is set; pg_stats will expose " +
+			"values from before anonymization until the anonymized tables " +
+			"are analyzed")
+		return nil
+	}
+
+	var captured []columnStatistics
+	for _, col := range columns {
+		if cc := configs[col.String()]; cc.Pattern == "" &&
+			!cc.IsProfileColumn() && !cc.IsDerivedColumn() &&
+			!cc.IsFillColumn() {
+			continue
+		}
+		values, err := validator.GetStatisticsValues(ctx, col)
+		if err != nil {
+			log.Printf("Warning: %s: statistics cannot be checked: %v",
+				col.String(), err)
+			continue
+		}
+		if len(values) > 0 {
+			captured = append(captured, columnStatistics{col, values})
+		}
+	}
+	return captured
+}
+
+// analyzeTables updates the planner statistics of the tables with
+// anonymized columns, so that pg_stats no longer exposes the original
+// values once the run is committed.
+func (a *Anonymizer) analyzeTables(ctx context.Context, tx *sql.Tx,
+	anonymized []errors.ColumnRef) error {
+
+	if a.config.SkipAnalyze {
+		return nil
+	}
+
+	analyzed := make(map[string]bool)
+	for _, col := range anonymized {
+		table := col.Schema + "." + col.Table
+		if analyzed[table] {
+			continue
+		}
+		analyzed[table] = true
+
+		if !a.quiet {
+			fmt.Printf("Analyzing %s\n", table)
+		}
+		if err := database.AnalyzeTable(ctx, tx, col.Schema,
+			col.Table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyStatistics reports columns whose planner statistics still hold
+// values from before the run, for example because the user was not
+// permitted to analyze the table. The values themselves are not shown.
+func (a *Anonymizer) verifyStatistics(ctx context.Context,
+	validator *database.SchemaValidator, before []columnStatistics) {
+
+	for _, b := range before {
+		col := b.column
+		values, err := validator.GetStatisticsValues(ctx, col)
+		if err != nil {
+			log.Printf("Warning: %s: statistics cannot be checked: %v",
+				col.String(), err)
+			continue
+		}
+
+		previous := make(map[string]bool, len(b.values))
+		for _, v := range b.values {
+			previous[v] = true
+		}
+		remaining := 0
+		for _, v := range values {
+			if previous[v] {
+				remaining++
+			}
+		}
+
+		if remaining > 0 {
+			log.Printf("Warning: %s: pg_stats still holds %d values from "+
+				"before anonymization; analyze the table as its owner, or "+
+				"check whether the anonymized values include the originals",
+				col.String(), remaining)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// CheckSubsetTables checks that each table subset has columns to
+// anonymize, as the rows of a table are deleted in the transaction
+// anonymizing its columns, so that the subset is committed, or resumed
+// after, along with them.
+func CheckSubsetTables(columns []errors.ColumnRef,
+	tables []config.TableConfig) error {
+
+	anonymized := make(map[string]bool)
+	for _, col := range columns {
+		anonymized[col.Schema+"."+col.Table] = true
+	}
+
+	var unused []string
+	for _, t := range tables {
+		if !anonymized[t.Table] {
+			unused = append(unused, t.Table)
+		}
+	}
+	if len(unused) > 0 {
+		return errors.NewConfigError("", fmt.Sprintf("tables to subset "+
+			"have no columns to anonymize: %s", strings.Join(unused, ", ")),
+			nil)
+	}
+	return nil
+}
+
+// subsetTables deletes the rows outside the subsets of the tables of a
+// unit, before their columns are anonymized, so that the rows deleted are
+// not anonymized needlessly.
+func (a *Anonymizer) subsetTables(ctx context.Context, tx *sql.Tx,
+	unit []errors.ColumnRef) error {
+
+	subsets := a.config.SubsetTables()
+	for _, name := range unitTables(unit) {
+		t, ok := subsets[name]
+		if !ok {
+			continue
+		}
+		percent, err := t.SamplePercent()
+		if err != nil {
+			return err
+		}
+
+		schema, table, _ := strings.Cut(name, ".")
+		deleted, err := database.SubsetTable(ctx, tx, schema, table, percent,
+			t.Limit)
+		if err != nil {
+			return fmt.Errorf("failed to subset %s: %w", name, err)
+		}
+		if !a.quiet {
+			fmt.Printf("Deleted %d rows of %s outside its subset\n", deleted,
+				name)
+		}
+	}
+	return nil
+}
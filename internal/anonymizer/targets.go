@@ -0,0 +1,88 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/stats"
+)
+
+// TargetResult holds the statistics for one target database.
+type TargetResult = stats.TargetStats
+
+// RunTargets anonymizes each database listed in the configuration's
+// targets in turn, each in its own transaction, with the same columns and
+// generators. With a shared target dictionary, equal values are replaced
+// by the same anonymized value in every target; otherwise each target
+// starts with an empty dictionary. Processing stops at the first target
+// that fails; the results of the targets already committed are returned
+// along with the error.
+func (a *Anonymizer) RunTargets(ctx context.Context) ([]TargetResult, error) {
+	results, err := a.runTargets(ctx)
+	if closeErr := a.closeFiles(); closeErr != nil && err == nil {
+		return results, fmt.Errorf("anonymization was committed, but %w",
+			closeErr)
+	}
+	return results, err
+}
+
+// runTargets anonymizes each target database in turn.
+func (a *Anonymizer) runTargets(ctx context.Context) ([]TargetResult, error) {
+	defer func() { a.dictionary.Close() }()
+	defer a.generators.Close()
+
+	base := a.config
+	defer func() { a.config = base }()
+
+	shared := base.TargetDictionary == config.TargetDictionaryShared
+	var results []TargetResult
+
+	targets := base.ResolveTargets()
+	for i, target := range targets {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		if i > 0 && !shared {
+			a.dictionary.Close()
+			dict, err := NewDictionaryWithOptions(a.dictOpts)
+			if err != nil {
+				return results, fmt.Errorf("failed to create dictionary: %w",
+					err)
+			}
+			a.dictionary = dict
+			if a.fingerprints != nil {
+				dict.RecordFingerprints(a.fingerprints)
+			}
+		}
+
+		a.config = base.ForTarget(target)
+		a.connector = database.NewConnector(&a.config.Database)
+		a.connector.SetQuiet(a.quiet)
+
+		if !a.quiet {
+			fmt.Printf("Target %s (%d of %d)\n", target.Name, i+1,
+				len(targets))
+		}
+
+		result, err := a.runAudited(ctx)
+		if err != nil {
+			return results, fmt.Errorf("target %s: %w", target.Name, err)
+		}
+		results = append(results, TargetResult{Name: target.Name, Stats: result})
+	}
+
+	return results, nil
+}
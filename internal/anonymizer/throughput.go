@@ -0,0 +1,73 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/audit"
+	"github.com/pgedge/pgedge-anonymizer/internal/stats"
+)
+
+// expectedThroughput returns the rate, in rows per second, each column is
+// expected to be anonymized at: the rate configured for it, or else its
+// average rate over the latest runs in the audit record, if learning is
+// enabled. Prior runs that cannot be read are warned of, as they only
+// serve to warn of slow runs.
+func (a *Anonymizer) expectedThroughput(ctx context.Context) map[string]float64 {
+	expected := make(map[string]float64)
+
+	if a.config.Throughput.Learn {
+		records, err := audit.Read(ctx, a.config.Audit, &a.config.Database,
+			a.config.Throughput.RunsOrDefault())
+		if err != nil {
+			log.Printf("Warning: prior runs could not be read to learn the "+
+				"expected throughput: %v", err)
+		} else {
+			expected = audit.Throughput(records)
+		}
+	}
+
+	for _, col := range a.config.Columns {
+		if col.ExpectedRowsPerSecond > 0 {
+			expected[col.Column] = col.ExpectedRowsPerSecond
+		}
+	}
+	return expected
+}
+
+// checkThroughput warns of each column anonymized more slowly than
+// expected by more than the configured factor, such as after a change to
+// the database server or its storage.
+func (a *Anonymizer) checkThroughput(result *stats.Stats,
+	expected map[string]float64) {
+
+	if result == nil || len(expected) == 0 {
+		return
+	}
+
+	slowdown := a.config.Throughput.SlowdownOrDefault()
+	for _, col := range result.Columns {
+		rate, ok := expected[col.Column.String()]
+		if !ok || col.Duration < audit.MinThroughputMS*time.Millisecond {
+			continue
+		}
+
+		actual := float64(col.RowsProcessed) / col.Duration.Seconds()
+		if actual*slowdown < rate {
+			log.Printf("Warning: %s anonymized at %.0f rows/s, %.1fx slower "+
+				"than the expected %.0f rows/s", col.Column.String(), actual,
+				rate/actual, rate)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/audit"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+	"github.com/pgedge/pgedge-anonymizer/internal/trace"
+)
+
+// openTrace creates the trace file to record the run to, or reads the
+// trace file to replay, as requested in the options.
+func (a *Anonymizer) openTrace(opts Options) error {
+	if opts.RecordPath != "" && opts.ReplayPath != "" {
+		return fmt.Errorf("a run cannot be recorded and replayed at once")
+	}
+
+	hash, err := audit.ConfigHash(opts.Config)
+	if err != nil {
+		return err
+	}
+
+	if opts.RecordPath != "" {
+		if a.recorder, err = trace.Create(opts.RecordPath, hash); err != nil {
+			return err
+		}
+	}
+
+	if opts.ReplayPath != "" {
+		if a.replay, err = trace.Open(opts.ReplayPath); err != nil {
+			return err
+		}
+		if a.replay.ConfigHash() != hash {
+			log.Printf("Warning: the configuration differs from the one " +
+				"used to record the trace; values may not be replayed")
+		}
+	}
+
+	return nil
+}
+
+// closeTrace closes the trace file being recorded, and reports values
+// that could not be replayed.
+func (a *Anonymizer) closeTrace() error {
+	if a.replay != nil {
+		if misses := a.replay.Misses(); misses > 0 {
+			log.Printf("Warning: %d values were not found in the trace and "+
+				"were generated anew", misses)
+		}
+		a.replay = nil
+	}
+
+	if a.recorder != nil {
+		err := a.recorder.Close()
+		a.recorder = nil
+		return err
+	}
+	return nil
+}
+
+// traceErr returns the first error recording the trace.
+func (a *Anonymizer) traceErr() error {
+	if a.recorder == nil {
+		return nil
+	}
+	return a.recorder.Err()
+}
+
+// columnTrace records or replays the values generated for a column. The
+// processor sets the row being anonymized so that it is recorded with
+// each value. A nil columnTrace does nothing.
+type columnTrace struct {
+	recorder *trace.Recorder
+	replay   *trace.Replay
+	database string
+	column   string
+	ctid     string
+}
+
+// newColumnTrace returns the trace for a column, or nil if the run is
+// neither recorded nor replayed.
+func (a *Anonymizer) newColumnTrace(col errors.ColumnRef) *columnTrace {
+	if a.recorder == nil && a.replay == nil {
+		return nil
+	}
+	return &columnTrace{
+		recorder: a.recorder,
+		replay:   a.replay,
+		database: a.config.Database.DatabaseName(),
+		column:   col.String(),
+	}
+}
+
+// setRow sets the row whose values are being generated.
+func (t *columnTrace) setRow(ctid string) {
+	if t != nil {
+		t.ctid = ctid
+	}
+}
+
+// wrap returns a generator that records or replays the values generated
+// for the pattern. The wrapper does not generate in batches, so that each
+// value is generated, and recorded, for its row.
+func (t *columnTrace) wrap(gen generator.Generator,
+	pattern string) generator.Generator {
+	if t == nil {
+		return gen
+	}
+	return &tracedGenerator{Generator: gen, trace: t, pattern: pattern}
+}
+
+// tracedGenerator records the values a generator returns, or returns
+// the values recorded in an earlier run.
+type tracedGenerator struct {
+	generator.Generator
+	trace   *columnTrace
+	pattern string
+}
+
+// Generate returns the recorded value when replaying a trace that holds
+// one, and generates a value otherwise.
+func (g *tracedGenerator) Generate(input string) string {
+	t := g.trace
+	if t.replay != nil {
+		if value, ok := t.replay.Lookup(t.database, t.column, g.pattern,
+			input); ok {
+			return value
+		}
+	}
+
+	value := g.Generator.Generate(input)
+	if t.recorder != nil {
+		t.recorder.Record(t.database, t.column, g.pattern, t.ctid, input,
+			value)
+	}
+	return value
+}
+
+// Err returns an error from the wrapped generator, or the first error
+// recording the trace.
+func (g *tracedGenerator) Err() error {
+	if f, ok := g.Generator.(generator.FallibleGenerator); ok {
+		if err := f.Err(); err != nil {
+			return err
+		}
+	}
+	if g.trace.recorder != nil {
+		return g.trace.recorder.Err()
+	}
+	return nil
+}
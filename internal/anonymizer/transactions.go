@@ -0,0 +1,393 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/stats"
+)
+
+// PartialCommitError is returned when a run fails after some of its
+// changes were committed, as they may be with the per_table and per_batch
+// transaction modes. The committed changes are not rolled back.
+type PartialCommitError struct {
+	// Committed lists the tables whose anonymization was committed.
+	Committed []string
+
+	// Partial lists the tables some of whose batches were committed.
+	Partial []string
+
+	Err error
+}
+
+// Error returns the error the run failed with, followed by the tables
+// committed before the failure.
+func (e *PartialCommitError) Error() string {
+	var parts []string
+	if len(e.Committed) > 0 {
+		parts = append(parts, "committed before the failure: "+
+			strings.Join(e.Committed, ", "))
+	}
+	if len(e.Partial) > 0 {
+		parts = append(parts, "partially committed: "+
+			strings.Join(e.Partial, ", "))
+	}
+	return fmt.Sprintf("%v (%s)", e.Err, strings.Join(parts, "; "))
+}
+
+// Unwrap returns the error the run failed with.
+func (e *PartialCommitError) Unwrap() error {
+	return e.Err
+}
+
+// transactionMode returns the configured transaction mode, or the default.
+func (a *Anonymizer) transactionMode() string {
+	if a.config.TransactionMode == "" {
+		return config.TransactionSingle
+	}
+	return a.config.TransactionMode
+}
+
+// transactionUnits groups the columns, in processing order, into the
+// units committed together. In single mode every column is in one unit;
+// otherwise each table is a unit, except that tables sharing a foreign key
+// on an anonymized column are committed together, so that the key is never
+// committed on one side only. Units are ordered by their first column.
+func transactionUnits(ordered []errors.ColumnRef,
+	fks []database.ForeignKey) [][]errors.ColumnRef {
+
+	configured := make(map[string]bool)
+	parent := make(map[string]string)
+	for _, col := range ordered {
+		configured[col.String()] = true
+		parent[col.Schema+"."+col.Table] = col.Schema + "." + col.Table
+	}
+
+	var find func(table string) string
+	find = func(table string) string {
+		if parent[table] != table {
+			parent[table] = find(parent[table])
+		}
+		return parent[table]
+	}
+
+	for _, fk := range fks {
+		parentTable := fk.ParentSchema + "." + fk.ParentTable
+		childTable := fk.ChildSchema + "." + fk.ChildTable
+		if _, ok := parent[parentTable]; !ok {
+			continue
+		}
+		if _, ok := parent[childTable]; !ok {
+			continue
+		}
+		if !configured[parentTable+"."+fk.ParentColumn] &&
+			!configured[childTable+"."+fk.ChildColumn] {
+			continue
+		}
+		parent[find(childTable)] = find(parentTable)
+	}
+
+	var units [][]errors.ColumnRef
+	index := make(map[string]int)
+	for _, col := range ordered {
+		root := find(col.Schema + "." + col.Table)
+		i, ok := index[root]
+		if !ok {
+			i = len(units)
+			index[root] = i
+			units = append(units, nil)
+		}
+		units[i] = append(units[i], col)
+	}
+	return units
+}
+
+// unitTables returns the names of the tables of a unit's columns, in
+// processing order.
+func unitTables(unit []errors.ColumnRef) []string {
+	var tables []string
+	seen := make(map[string]bool)
+	for _, col := range unit {
+		table := col.Schema + "." + col.Table
+		if !seen[table] {
+			seen[table] = true
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// beginTx starts a transaction, with the configured session settings.
+func (a *Anonymizer) beginTx(ctx context.Context) (*sql.Tx, error) {
+	tx, err := a.connector.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tune the session for the run
+	for _, name := range a.config.SessionSettingNames() {
+		if err := database.SetLocal(ctx, tx, name,
+			a.config.SessionSettings[name]); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Keep triggers from firing on the run's updates
+	if a.config.TriggerMode() == config.DisableTriggersReplica {
+		if err := database.SetLocal(ctx, tx, "session_replication_role",
+			"replica"); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Fail rather than wait indefinitely behind other sessions' locks,
+	// or on a statement that does not finish
+	for _, timeout := range []struct{ name, value string }{
+		{"lock_timeout", a.config.LockTimeoutSetting()},
+		{"statement_timeout", a.config.StatementTimeoutSetting()},
+	} {
+		if timeout.value == "" {
+			continue
+		}
+		if err := database.SetLocal(ctx, tx, timeout.name,
+			timeout.value); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// unitTransaction is the transaction a unit of tables is anonymized in.
+type unitTransaction struct {
+	a  *Anonymizer
+	tx *sql.Tx
+
+	// table is the table being anonymized, as schema.table, and locked
+	// the tables locked so far
+	table  string
+	locked map[string]bool
+
+	// batchCommits counts the commits made between batches
+	batchCommits int
+
+	// triggerTables are the tables whose user triggers are disabled in
+	// each of the unit's transactions, and triggers those disabled in the
+	// current one
+	triggerTables []string
+	triggers      []database.Trigger
+
+	// indexes are the indexes dropped in the transaction, to be created
+	// again before it commits
+	indexes []database.Index
+
+	// foreignKeys are the foreign keys dropped in the transaction, to be
+	// added again before it commits
+	foreignKeys []database.ForeignKeyConstraint
+}
+
+// disableTriggers disables the user triggers of the unit's tables in the
+// transaction, if triggers are configured to be disabled that way, so
+// that they do not fire on its updates.
+func (t *unitTransaction) disableTriggers(ctx context.Context,
+	tables []string) error {
+
+	if t.a.config.TriggerMode() != config.DisableTriggersTable {
+		return nil
+	}
+	t.triggerTables = tables
+	t.triggers = nil
+	for _, name := range tables {
+		schema, table, _ := strings.Cut(name, ".")
+		triggers, err := database.DisableUserTriggers(ctx, t.tx, schema, table)
+		if err != nil {
+			return err
+		}
+		t.triggers = append(t.triggers, triggers...)
+	}
+	return nil
+}
+
+// dropIndexes drops the indexes on the columns of a unit that
+// DropColumnIndexes allows, if indexes are configured to be dropped, so
+// that they are created once after the columns are updated rather than
+// updated with each row. Skipped columns, such as the targets of a
+// cascading foreign key, are left indexed.
+func (t *unitTransaction) dropIndexes(ctx context.Context,
+	unit []errors.ColumnRef, skipSet map[string]string) error {
+
+	if !t.a.config.DropIndexes {
+		return nil
+	}
+	columns := make(map[string][]string)
+	for _, col := range unit {
+		if skipSet[col.String()] == "" {
+			table := col.Schema + "." + col.Table
+			columns[table] = append(columns[table], col.Column)
+		}
+	}
+	for _, name := range unitTables(unit) {
+		schema, table, _ := strings.Cut(name, ".")
+		indexes, err := database.DropColumnIndexes(ctx, t.tx, schema, table,
+			columns[name])
+		if err != nil {
+			return err
+		}
+		if len(indexes) > 0 && !t.a.quiet {
+			fmt.Printf("Dropped %d indexes of %s, to recreate once it is "+
+				"anonymized\n", len(indexes), name)
+		}
+		t.indexes = append(t.indexes, indexes...)
+	}
+	return nil
+}
+
+// dropForeignKeys drops the foreign keys of the unit's tables whose
+// values are propagated, so that the referenced and referencing columns
+// can be updated one after the other.
+func (t *unitTransaction) dropForeignKeys(ctx context.Context,
+	tables []string) error {
+
+	keys, err := database.DropForeignKeys(ctx, t.tx, tables, t.a.foreignKeys)
+	if err != nil {
+		return err
+	}
+	if len(keys) > 0 && !t.a.quiet {
+		fmt.Printf("Dropped %d foreign keys of %s, to restore once they "+
+			"are anonymized\n", len(keys), strings.Join(tables, ", "))
+	}
+	t.foreignKeys = keys
+	return nil
+}
+
+// recreateIndexes creates the indexes dropped in the transaction again,
+// recording their definitions and the time taken for each table.
+func (t *unitTransaction) recreateIndexes(ctx context.Context,
+	collector *stats.Collector) error {
+
+	for len(t.indexes) > 0 {
+		// The indexes of each table are consecutive
+		n := 1
+		for n < len(t.indexes) && t.indexes[n].Schema == t.indexes[0].Schema &&
+			t.indexes[n].Table == t.indexes[0].Table {
+			n++
+		}
+		indexes := t.indexes[:n]
+
+		start := time.Now()
+		if err := database.RecreateIndexes(ctx, t.tx, indexes); err != nil {
+			return err
+		}
+		table := stats.TableStats{
+			Table: indexes[0].Schema + "." + indexes[0].Table,
+		}
+		for _, idx := range indexes {
+			table.RebuiltIndexes = append(table.RebuiltIndexes, idx.Definition)
+		}
+		table.IndexRebuild = time.Since(start)
+		collector.RecordTable(table)
+		t.indexes = t.indexes[n:]
+	}
+	return nil
+}
+
+// commit enables the triggers disabled in the transaction again, and
+// commits it.
+func (t *unitTransaction) commit(ctx context.Context) error {
+	if err := database.EnableTriggers(ctx, t.tx, t.triggers); err != nil {
+		return err
+	}
+	if err := t.tx.Commit(); err != nil {
+		return errors.NewDatabaseError("commit",
+			fmt.Sprintf("failed to commit transaction: %v", err), err)
+	}
+	return nil
+}
+
+// commitBatch commits the transaction after a batch, and starts the one
+// the next batch is written in, with the same triggers disabled.
+func (t *unitTransaction) commitBatch(ctx context.Context) (*sql.Tx, error) {
+	if err := t.commit(ctx); err != nil {
+		return nil, err
+	}
+	t.batchCommits++
+
+	tx, err := t.a.beginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.tx = tx
+	if err := t.disableTriggers(ctx, t.triggerTables); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// lockTable locks the table of a column in the transaction, as its update
+// would, unless it is already locked, and records the time taken, so that
+// time spent waiting behind other sessions is reported for the table.
+func (t *unitTransaction) lockTable(ctx context.Context, col errors.ColumnRef,
+	collector *stats.Collector) error {
+
+	t.table = col.Schema + "." + col.Table
+	if t.locked[t.table] {
+		return nil
+	}
+	if t.locked == nil {
+		t.locked = make(map[string]bool)
+	}
+	t.locked[t.table] = true
+
+	start := time.Now()
+	if err := database.LockTable(ctx, t.tx, col.Schema, col.Table); err != nil {
+		return err
+	}
+	collector.RecordTable(stats.TableStats{
+		Table:    t.table,
+		LockWait: time.Since(start),
+	})
+	return nil
+}
+
+// lockTimeoutError adds the table and the sessions holding locks on it
+// to an error caused by lock_timeout expiring; other errors are returned
+// as they are. The transaction that timed out must have been rolled back,
+// so that only other sessions' locks remain.
+func (a *Anonymizer) lockTimeoutError(ctx context.Context,
+	validator *database.SchemaValidator, table string, err error) error {
+
+	if table == "" || !database.IsLockTimeout(err) {
+		return err
+	}
+
+	schema, name, _ := strings.Cut(table, ".")
+	holders, herr := validator.GetLockHolders(ctx, schema, name)
+	if herr != nil || len(holders) == 0 {
+		return fmt.Errorf("timed out waiting for a lock on %s: %w", table, err)
+	}
+
+	described := make([]string, len(holders))
+	for i, h := range holders {
+		described[i] = h.String()
+	}
+	return fmt.Errorf("timed out waiting for a lock on %s, held by %s: %w",
+		table, strings.Join(described, ", "), err)
+}
@@ -0,0 +1,117 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+	"github.com/pgedge/pgedge-anonymizer/internal/xmlpath"
+)
+
+// XMLColumnProcessor processes an XML (or XML-bearing text) column for
+// anonymization. It selects values with XPath expressions, anonymizes
+// them, and updates the document with the anonymized values.
+type XMLColumnProcessor struct {
+	tx         *sql.Tx
+	column     errors.ColumnRef
+	dataType   string
+	xmlPaths   []config.XMLPathConfig
+	generators map[string]generator.Generator // path -> generator
+	batchSize  int
+	processor  *xmlpath.Processor
+	quiet      bool
+	batchHook  batchHookFunc
+	trace      *columnTrace
+
+	// dictionaries maps each path to the dictionary namespace its values
+	// are kept in
+	dictionaries map[string]*Dictionary
+
+	// Settings tuning how batches are read and written
+	tuning batchTuning
+}
+
+// NewXMLColumnProcessor creates a new XML column processor.
+func NewXMLColumnProcessor(
+	tx *sql.Tx,
+	column errors.ColumnRef,
+	dataType string,
+	xmlPaths []config.XMLPathConfig,
+	generators map[string]generator.Generator,
+	dictionaries map[string]*Dictionary,
+	batchSize int,
+	quiet bool,
+) *XMLColumnProcessor {
+	return &XMLColumnProcessor{
+		tx:           tx,
+		column:       column,
+		dataType:     dataType,
+		xmlPaths:     xmlPaths,
+		generators:   generators,
+		dictionaries: dictionaries,
+		batchSize:    batchSize,
+		processor:    xmlpath.NewProcessor(quiet),
+		quiet:        quiet,
+	}
+}
+
+// Process anonymizes all XML values in the column at the specified paths.
+func (p *XMLColumnProcessor) Process(ctx context.Context,
+	progress func(processed int64)) (*ProcessResult, error) {
+
+	// For XML columns we fetch the full document
+	batch := database.NewBatchProcessor(p.tx, p.column, p.dataType, p.batchSize)
+	p.tuning.apply(batch)
+
+	pathExprs := make([]string, len(p.xmlPaths))
+	for i, xp := range p.xmlPaths {
+		pathExprs[i] = xp.Path
+	}
+
+	loop := &batchLoop{batch: batch, batchHook: p.batchHook, trace: p.trace}
+	return loop.run(ctx, func(row database.RowData) (string, int, error) {
+		modifiedXML, valuesAnonymized, err := p.processor.Replace(
+			row.Value, pathExprs, p.anonymize)
+		if err != nil {
+			// Log error but continue processing other rows
+			if !p.quiet {
+				log.Printf("Warning: failed to process XML at %s (ctid=%s): %v",
+					p.column, row.CTID, err)
+			}
+			return "", 0, nil
+		}
+		return modifiedXML, valuesAnonymized, nil
+	}, progress)
+}
+
+// anonymize returns the anonymized value for a value selected by an XPath
+// expression, reusing any existing dictionary mapping.
+func (p *XMLColumnProcessor) anonymize(pathExpr, value string) string {
+	gen, ok := p.generators[pathExpr]
+	if !ok {
+		return value // No generator for this path (shouldn't happen)
+	}
+
+	// Check dictionary for existing mapping
+	dict := p.dictionaries[pathExpr]
+	anonymized, exists := dict.Get(value)
+	if !exists {
+		anonymized = gen.Generate(value)
+		dict.Set(value, anonymized)
+	}
+	return anonymized
+}
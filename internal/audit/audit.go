@@ -0,0 +1,283 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package audit records anonymization runs, as evidence for compliance
+// reviews, in an append-only file or a database table.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/stats"
+)
+
+// Run statuses.
+const (
+	StatusCommitted = "committed"
+	StatusFailed    = "failed"
+)
+
+// Column records the changes made to one column.
+type Column struct {
+	Column           string `json:"column"`
+	RowsProcessed    int64  `json:"rows_processed"`
+	ValuesAnonymized int64  `json:"values_anonymized"`
+	DurationMS       int64  `json:"duration_ms,omitempty"`
+}
+
+// Record describes one anonymization run.
+type Record struct {
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	Status           string    `json:"status"`
+	Error            string    `json:"error,omitempty"`
+	Operator         string    `json:"operator"`
+	Hostname         string    `json:"hostname,omitempty"`
+	Database         string    `json:"database"`
+	DatabaseUser     string    `json:"database_user,omitempty"`
+	ConfigHash       string    `json:"config_hash"`
+	Patterns         []string  `json:"patterns"`
+	Columns          []Column  `json:"columns"`
+	RowsProcessed    int64     `json:"rows_processed"`
+	ValuesAnonymized int64     `json:"values_anonymized"`
+}
+
+// NewRecord starts a record of a run with the given configuration.
+func NewRecord(cfg *config.Config, started time.Time) (*Record, error) {
+	hash, err := ConfigHash(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &Record{
+		StartedAt:    started,
+		Operator:     Operator(cfg.Audit.Operator),
+		Hostname:     hostname,
+		Database:     cfg.Database.DatabaseName(),
+		DatabaseUser: cfg.Database.User,
+		ConfigHash:   hash,
+		Patterns:     patternNames(cfg),
+		Columns:      []Column{},
+	}, nil
+}
+
+// Finish completes the record with the outcome of the run: its statistics
+// if it was committed, or the error that stopped it.
+func (r *Record) Finish(result *stats.Stats, runErr error, finished time.Time) {
+	r.FinishedAt = finished
+
+	if runErr != nil {
+		r.Status = StatusFailed
+		r.Error = runErr.Error()
+		return
+	}
+
+	r.Status = StatusCommitted
+	if result == nil {
+		return
+	}
+	for _, col := range result.Columns {
+		r.Columns = append(r.Columns, Column{
+			Column:           col.Column.String(),
+			RowsProcessed:    col.RowsProcessed,
+			ValuesAnonymized: col.ValuesAnonymized,
+			DurationMS:       col.Duration.Milliseconds(),
+		})
+	}
+	r.RowsProcessed = result.TotalRows
+	r.ValuesAnonymized = result.TotalAnonymized
+}
+
+// ConfigHash returns the SHA-256 hash of the configuration, with
+// passwords removed, so that a run can be matched to the configuration
+// it used.
+func ConfigHash(cfg *config.Config) (string, error) {
+	cp := *cfg
+	cp.Database.Password = ""
+	cp.Database.URL = config.RedactURL(cp.Database.URL)
+	cp.Targets = make([]config.TargetConfig, len(cfg.Targets))
+	for i, t := range cfg.Targets {
+		t.Password = ""
+		t.URL = config.RedactURL(t.URL)
+		cp.Targets[i] = t
+	}
+
+	data, err := yaml.Marshal(&cp)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash configuration: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Operator returns the configured operator, or the name of the operating
+// system user running the anonymizer.
+func Operator(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// patternNames returns the sorted names of the patterns referenced by the
+// configured columns.
+func patternNames(cfg *config.Config) []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, col := range cfg.Columns {
+		for _, name := range col.PatternNames() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Write writes the record to the file and table in the audit
+// configuration. The table is written through a new connection to the
+// database.
+func Write(ctx context.Context, cfg config.AuditConfig,
+	dbConfig *config.DatabaseConfig, rec *Record) error {
+
+	if cfg.File != "" {
+		if err := AppendFile(cfg.File, rec); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Table != "" {
+		connector := database.NewConnector(dbConfig)
+		if err := connector.Connect(ctx); err != nil {
+			return err
+		}
+		defer connector.Close()
+
+		if err := InsertTable(ctx, connector.DB(), cfg.Table, rec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AppendFile appends the record to a file as a line of JSON, creating the
+// file, readable only by its owner, if it does not exist.
+func AppendFile(path string, rec *Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit file: %w", err)
+	}
+	return nil
+}
+
+// InsertTable inserts the record into a table, creating the table if it
+// does not exist.
+func InsertTable(ctx context.Context, db *sql.DB, table string,
+	rec *Record) error {
+
+	name := quoteQualified(table)
+
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+name+` (
+    id bigserial PRIMARY KEY,
+    started_at timestamptz NOT NULL,
+    finished_at timestamptz NOT NULL,
+    status text NOT NULL,
+    error text,
+    operator text NOT NULL,
+    hostname text,
+    database_name text NOT NULL,
+    database_user text,
+    config_hash text NOT NULL,
+    patterns text[] NOT NULL,
+    columns jsonb NOT NULL,
+    rows_processed bigint NOT NULL,
+    values_anonymized bigint NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create audit table %s: %w", table, err)
+	}
+
+	columns, err := json.Marshal(rec.Columns)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO `+name+` (
+    started_at, finished_at, status, error, operator, hostname,
+    database_name, database_user, config_hash, patterns, columns,
+    rows_processed, values_anonymized
+) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		rec.StartedAt, rec.FinishedAt, rec.Status, rec.Error, rec.Operator,
+		rec.Hostname, rec.Database, rec.DatabaseUser, rec.ConfigHash,
+		textArray(rec.Patterns), string(columns), rec.RowsProcessed,
+		rec.ValuesAnonymized)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit record into %s: %w", table,
+			err)
+	}
+
+	return nil
+}
+
+// textArray formats strings as a PostgreSQL text array literal.
+func textArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		v = strings.ReplaceAll(v, `\`, `\\`)
+		v = strings.ReplaceAll(v, `"`, `\"`)
+		quoted[i] = `"` + v + `"`
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
+
+// quoteQualified quotes each part of a possibly schema-qualified name.
+func quoteQualified(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
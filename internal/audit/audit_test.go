@@ -0,0 +1,178 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	anonerrors "github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/stats"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Database: config.DatabaseConfig{Database: "app", User: "anon",
+			Password: "secret"},
+		Columns: []config.ColumnConfig{
+			{Column: "public.users.email", Pattern: "EMAIL"},
+			{Column: "public.users.profile", Fields: map[string]string{
+				"phone": "US_PHONE", "email": "EMAIL"}},
+		},
+		Audit: config.AuditConfig{Operator: "jane"},
+	}
+}
+
+func testRecord(t *testing.T) *Record {
+	started := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	rec, err := NewRecord(testConfig(), started)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := stats.NewCollector()
+	c.RecordColumn(stats.ColumnStats{
+		Column: anonerrors.ColumnRef{Schema: "public", Table: "users",
+			Column: "email"},
+		RowsProcessed:    10,
+		ValuesAnonymized: 8,
+	})
+	rec.Finish(c.Finalize(time.Second), nil, started.Add(time.Minute))
+	return rec
+}
+
+// TestRecord tests building a record of a run
+func TestRecord(t *testing.T) {
+	rec := testRecord(t)
+
+	if rec.Operator != "jane" || rec.Database != "app" ||
+		rec.DatabaseUser != "anon" {
+		t.Errorf("unexpected identity: %+v", rec)
+	}
+	if len(rec.Patterns) != 2 || rec.Patterns[0] != "EMAIL" ||
+		rec.Patterns[1] != "US_PHONE" {
+		t.Errorf("unexpected patterns: %v", rec.Patterns)
+	}
+	if rec.Status != StatusCommitted || len(rec.Columns) != 1 ||
+		rec.ValuesAnonymized != 8 {
+		t.Errorf("unexpected outcome: %+v", rec)
+	}
+
+	failed, _ := NewRecord(testConfig(), time.Now())
+	failed.Finish(nil, errors.New("boom"), time.Now())
+	if failed.Status != StatusFailed || failed.Error != "boom" ||
+		len(failed.Columns) != 0 {
+		t.Errorf("unexpected failed record: %+v", failed)
+	}
+}
+
+// TestConfigHash tests that the hash ignores passwords only
+func TestConfigHash(t *testing.T) {
+	cfg := testConfig()
+	hash, err := ConfigHash(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Database.Password = "other"
+	if h, _ := ConfigHash(cfg); h != hash {
+		t.Error("hash should not depend on the password")
+	}
+
+	cfg.Columns[0].Pattern = "PERSON_NAME"
+	if h, _ := ConfigHash(cfg); h == hash {
+		t.Error("hash should depend on the columns")
+	}
+
+	if cfg.Database.Password != "other" {
+		t.Error("ConfigHash modified the configuration")
+	}
+}
+
+// TestAppendFile tests appending records to an audit file
+func TestAppendFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for i := 0; i < 2; i++ {
+		if err := AppendFile(path, testRecord(t)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("unexpected permissions: %v", info.Mode().Perm())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid JSON line: %v", err)
+		}
+		if rec.Status != StatusCommitted || rec.Columns[0].Column !=
+			"public.users.email" {
+			t.Errorf("unexpected record: %+v", rec)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("expected 2 lines, got %d", lines)
+	}
+}
+
+// TestInsertTable tests inserting records into an audit table
+func TestInsertTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	rec := testRecord(t)
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS "audit"."runs"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO "audit"."runs"`).
+		WithArgs(rec.StartedAt, rec.FinishedAt, StatusCommitted, "", "jane",
+			rec.Hostname, "app", "anon", rec.ConfigHash,
+			`{"EMAIL","US_PHONE"}`,
+			`[{"column":"public.users.email","rows_processed":10,"values_anonymized":8}]`,
+			int64(10), int64(8)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := InsertTable(context.Background(), db, "audit.runs",
+		rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
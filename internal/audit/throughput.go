@@ -0,0 +1,166 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+)
+
+// MinThroughputMS is the shortest time, in milliseconds, a column must
+// take for its rate to be measured; shorter times are dominated by fixed
+// costs such as declaring the cursor.
+const MinThroughputMS = 1000
+
+// Read returns the committed runs of a database recorded in the audit
+// table, or else the audit file, latest first, up to limit of them.
+func Read(ctx context.Context, cfg config.AuditConfig,
+	dbConfig *config.DatabaseConfig, limit int) ([]Record, error) {
+
+	if cfg.Table != "" {
+		connector := database.NewConnector(dbConfig)
+		if err := connector.Connect(ctx); err != nil {
+			return nil, err
+		}
+		defer connector.Close()
+		return QueryTable(ctx, connector.DB(), cfg.Table,
+			dbConfig.Database, limit)
+	}
+
+	records, err := ReadFile(cfg.File)
+	if err != nil {
+		return nil, err
+	}
+	return latestCommitted(records, dbConfig.Database, limit), nil
+}
+
+// ReadFile reads the records in an audit file, in the order they were
+// written. A missing file holds no records.
+func ReadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("failed to decode audit record: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit file: %w", err)
+	}
+	return records, nil
+}
+
+// latestCommitted returns the committed runs of a database, latest first,
+// up to limit of them.
+func latestCommitted(records []Record, db string, limit int) []Record {
+	var runs []Record
+	for _, rec := range records {
+		if rec.Status == StatusCommitted && rec.Database == db {
+			runs = append(runs, rec)
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].FinishedAt.After(runs[j].FinishedAt)
+	})
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs
+}
+
+// QueryTable returns the committed runs of a database recorded in an
+// audit table, latest first, up to limit of them, with their columns. A
+// table that does not exist yet holds no records.
+func QueryTable(ctx context.Context, db *sql.DB, table, dbName string,
+	limit int) ([]Record, error) {
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`,
+		quoteQualified(table)).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to read audit table %s: %w", table, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT started_at, finished_at,
+    columns::text FROM `+quoteQualified(table)+`
+WHERE status = $1 AND database_name = $2
+ORDER BY finished_at DESC
+LIMIT $3`, StatusCommitted, dbName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		rec := Record{Status: StatusCommitted, Database: dbName}
+		var columns string
+		if err := rows.Scan(&rec.StartedAt, &rec.FinishedAt,
+			&columns); err != nil {
+			return nil, fmt.Errorf("failed to read audit table %s: %w",
+				table, err)
+		}
+		if err := json.Unmarshal([]byte(columns), &rec.Columns); err != nil {
+			return nil, fmt.Errorf("failed to decode audit record: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit table %s: %w", table, err)
+	}
+	return records, nil
+}
+
+// Throughput returns the average rate, in rows per second, at which each
+// column was anonymized in the given runs, leaving out the runs that
+// processed it too quickly to measure.
+func Throughput(records []Record) map[string]float64 {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, rec := range records {
+		for _, col := range rec.Columns {
+			if col.DurationMS < MinThroughputMS {
+				continue
+			}
+			sums[col.Column] += float64(col.RowsProcessed) /
+				(float64(col.DurationMS) / 1000)
+			counts[col.Column]++
+		}
+	}
+
+	rates := make(map[string]float64, len(sums))
+	for col, sum := range sums {
+		rates[col] = sum / float64(counts[col])
+	}
+	return rates
+}
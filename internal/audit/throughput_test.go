@@ -0,0 +1,135 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package audit
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+)
+
+func throughputRecord(db, status string, finished time.Time,
+	rows, ms int64) Record {
+	return Record{
+		Database:   db,
+		Status:     status,
+		FinishedAt: finished,
+		Columns: []Column{{Column: "public.users.email",
+			RowsProcessed: rows, DurationMS: ms}},
+	}
+}
+
+// TestThroughput tests averaging the rate of each column over prior runs
+func TestThroughput(t *testing.T) {
+	rates := Throughput([]Record{
+		throughputRecord("app", StatusCommitted, time.Time{}, 10000, 2000),
+		throughputRecord("app", StatusCommitted, time.Time{}, 30000, 2000),
+		// Too quick to measure
+		throughputRecord("app", StatusCommitted, time.Time{}, 10, 5),
+	})
+
+	if len(rates) != 1 || math.Abs(rates["public.users.email"]-10000) > 1e-9 {
+		t.Errorf("unexpected rates: %v", rates)
+	}
+	if len(Throughput(nil)) != 0 {
+		t.Error("expected no rates without runs")
+	}
+}
+
+// TestReadFile tests reading the latest committed runs from an audit file
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg := config.AuditConfig{File: path}
+	dbConfig := &config.DatabaseConfig{Database: "app"}
+	ctx := context.Background()
+
+	// A missing file holds no runs
+	records, err := Read(ctx, cfg, dbConfig, 5)
+	if err != nil || len(records) != 0 {
+		t.Fatalf("expected no records, got %v, %v", records, err)
+	}
+
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i, rec := range []Record{
+		throughputRecord("app", StatusCommitted, start, 1000, 1000),
+		throughputRecord("app", StatusFailed, start.Add(time.Hour), 1, 1000),
+		throughputRecord("other", StatusCommitted, start.Add(time.Hour), 1,
+			1000),
+		throughputRecord("app", StatusCommitted, start.Add(2*time.Hour), 3000,
+			1000),
+		throughputRecord("app", StatusCommitted, start.Add(3*time.Hour), 2000,
+			1000),
+	} {
+		if err := AppendFile(path, &rec); err != nil {
+			t.Fatalf("record %d: unexpected error: %v", i, err)
+		}
+	}
+
+	records, err = Read(ctx, cfg, dbConfig, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 2 || records[0].Columns[0].RowsProcessed != 2000 ||
+		records[1].Columns[0].RowsProcessed != 3000 {
+		t.Errorf("unexpected records: %+v", records)
+	}
+}
+
+// TestQueryTable tests reading the latest committed runs from an audit
+// table
+func TestQueryTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	finished := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT to_regclass`).
+		WithArgs(`"audit"."runs"`).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`FROM "audit"."runs"`).
+		WithArgs(StatusCommitted, "app", 5).
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"started_at", "finished_at", "columns"}).
+			AddRow(finished.Add(-time.Minute), finished,
+				`[{"column":"public.users.email","rows_processed":10,`+
+					`"values_anonymized":8,"duration_ms":2000}]`))
+
+	records, err := QueryTable(context.Background(), db, "audit.runs", "app",
+		5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 1 || records[0].Columns[0].DurationMS != 2000 ||
+		!records[0].FinishedAt.Equal(finished) {
+		t.Errorf("unexpected records: %+v", records)
+	}
+
+	// A table that does not exist yet holds no runs
+	mock.ExpectQuery(`SELECT to_regclass`).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	records, err = QueryTable(context.Background(), db, "audit.runs", "app",
+		5)
+	if err != nil || len(records) != 0 {
+		t.Errorf("expected no records, got %v, %v", records, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package bloom provides a Bloom filter: a compact set of strings that
+// may report a string it does not hold as present, at a chosen rate, but
+// never reports a string it holds as absent.
+package bloom
+
+import (
+	"hash/maphash"
+	"math"
+)
+
+// Filter is a Bloom filter over strings. It is not safe for concurrent
+// use while strings are being added.
+type Filter struct {
+	bits   []uint64
+	m      uint64 // Number of bits
+	k      uint64 // Number of hashes per string
+	seed   maphash.Seed
+	length int
+}
+
+// New creates a filter sized to hold n strings with a false positive rate
+// of p. More strings may be added, at the cost of a higher rate.
+func New(n int, p float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	m = max((m+63)/64*64, 64)
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	k = max(k, 1)
+
+	return &Filter{
+		bits: make([]uint64, m/64),
+		m:    m,
+		k:    k,
+		seed: maphash.MakeSeed(),
+	}
+}
+
+// hashes returns the two hashes a string's bits are derived from.
+func (f *Filter) hashes(s string) (uint64, uint64) {
+	h := maphash.String(f.seed, s)
+	return h, h>>32 | 1 // An odd step visits distinct bits
+}
+
+// Add adds a string to the filter.
+func (f *Filter) Add(s string) {
+	h1, h2 := f.hashes(s)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.length++
+}
+
+// MayContain returns false if the string has definitely not been added,
+// and true if it probably has.
+func (f *Filter) MayContain(s string) bool {
+	h1, h2 := f.hashes(s)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns the number of strings added, counting repeats.
+func (f *Filter) Len() int {
+	return f.length
+}
+
+// SizeBytes returns the memory used by the filter's bits.
+func (f *Filter) SizeBytes() int {
+	return len(f.bits) * 8
+}
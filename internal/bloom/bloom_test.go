@@ -0,0 +1,61 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package bloom
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestFilter tests that added strings are always found, and that strings
+// not added are rarely reported as present
+func TestFilter(t *testing.T) {
+	const n = 10000
+	f := New(n, 0.01)
+
+	for i := 0; i < n; i++ {
+		f.Add(fmt.Sprintf("user%d@example.com", i))
+	}
+	if f.Len() != n {
+		t.Errorf("expected %d strings, got %d", n, f.Len())
+	}
+
+	for i := 0; i < n; i++ {
+		if !f.MayContain(fmt.Sprintf("user%d@example.com", i)) {
+			t.Fatalf("added string %d not found", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		if f.MayContain(fmt.Sprintf("other%d@example.net", i)) {
+			falsePositives++
+		}
+	}
+	// Allow for chance well beyond the expected 1%
+	if falsePositives > n*3/100 {
+		t.Errorf("expected about 1%% false positives, got %d of %d",
+			falsePositives, n)
+	}
+}
+
+// TestFilterSize tests the size of filters for small and large sets
+func TestFilterSize(t *testing.T) {
+	if f := New(0, 0); f.SizeBytes() != 8 || f.MayContain("x") {
+		t.Errorf("unexpected empty filter: %d bytes", f.SizeBytes())
+	}
+
+	// About 9.6 bits per string at 1%
+	f := New(1000000, 0.01)
+	if size := f.SizeBytes(); size < 1100000 || size > 1300000 {
+		t.Errorf("unexpected size of %d bytes", size)
+	}
+}
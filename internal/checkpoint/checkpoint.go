@@ -0,0 +1,131 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package checkpoint records the tables whose anonymization a run has
+// committed, so that a run that stops before it finishes can be resumed
+// from where it left off.
+//
+// A checkpoint file holds a single JSON object, replaced as each table is
+// committed.
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Version is the checkpoint file format version.
+const Version = 1
+
+// Checkpoint is the resume point of a run: the tables it committed.
+type Checkpoint struct {
+	Version   int       `json:"version"`
+	Database  string    `json:"database"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// ConfigHash is the hash of the configuration the tables were
+	// anonymized with; a run with another configuration cannot resume
+	// from the checkpoint.
+	ConfigHash string `json:"config_hash"`
+
+	// Committed lists the committed tables, as schema.table, in the order
+	// they were committed.
+	Committed []string `json:"committed"`
+}
+
+// New creates an empty checkpoint for a run of a database with a
+// configuration.
+func New(database, configHash string) *Checkpoint {
+	return &Checkpoint{
+		Version:    Version,
+		Database:   database,
+		ConfigHash: configHash,
+	}
+}
+
+// Load reads a checkpoint file. A missing file returns nil, as there is
+// nothing to resume.
+func Load(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+
+	var c Checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint file %s: %w",
+			path, err)
+	}
+	if c.Version != Version {
+		return nil, fmt.Errorf("checkpoint file %s has unsupported version %d",
+			path, c.Version)
+	}
+	return &c, nil
+}
+
+// IsCommitted returns true if the table, as schema.table, was committed.
+func (c *Checkpoint) IsCommitted(table string) bool {
+	for _, t := range c.Committed {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// Commit adds tables to those committed, and writes the checkpoint to a
+// file, readable only by its owner. The file is replaced in a single
+// rename, so that a run stopped while writing it leaves the previous
+// checkpoint.
+func (c *Checkpoint) Commit(path string, tables ...string) error {
+	for _, table := range tables {
+		if !c.IsCommitted(table) {
+			c.Committed = append(c.Committed, table)
+		}
+	}
+	c.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	return nil
+}
+
+// Remove removes a checkpoint file, once the run it records has finished.
+func Remove(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoint file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCheckpoint tests writing, reloading and removing a checkpoint
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+
+	// A missing file has nothing to resume
+	c, err := Load(path)
+	if err != nil || c != nil {
+		t.Fatalf("expected no checkpoint, got %v, %v", c, err)
+	}
+
+	c = New("app", "abc123")
+	if err := c.Commit(path, "public.users", "public.orders"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Commit(path, "public.users", "public.payments"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("unexpected permissions: %v", info.Mode().Perm())
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Database != "app" || loaded.ConfigHash != "abc123" ||
+		len(loaded.Committed) != 3 || loaded.Committed[2] != "public.payments" {
+		t.Errorf("unexpected checkpoint: %+v", loaded)
+	}
+	if !loaded.IsCommitted("public.orders") ||
+		loaded.IsCommitted("public.invoices") {
+		t.Errorf("unexpected committed tables: %v", loaded.Committed)
+	}
+
+	// Only the checkpoint is left in the directory
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("expected only the checkpoint file, got %v, %v", entries, err)
+	}
+
+	if err := Remove(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Remove(path); err != nil {
+		t.Errorf("removing a missing file failed: %v", err)
+	}
+}
+
+// TestLoadInvalid tests that unreadable checkpoints are rejected
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"garbled": "{",
+		"version": `{"version": 99}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package composite parses and formats PostgreSQL composite type (row)
+// literals, such as ("123 Main St",Springfield,,62701), for anonymizing
+// individual fields of composite-typed columns.
+package composite
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse splits a row literal into its field values, in the text format
+// produced by PostgreSQL's record output function. A nil entry is a NULL
+// field.
+func Parse(literal string) ([]*string, error) {
+	if len(literal) < 2 || literal[0] != '(' ||
+		literal[len(literal)-1] != ')' {
+		return nil, fmt.Errorf("invalid row literal %q: must be enclosed "+
+			"in parentheses", literal)
+	}
+
+	body := literal[1 : len(literal)-1]
+	var fields []*string
+
+	i := 0
+	for {
+		var field strings.Builder
+		quotedOrEscaped := false
+
+		// Read one field up to the next unquoted comma or the end
+		inQuotes := false
+	scan:
+		for i < len(body) {
+			c := body[i]
+			switch {
+			case c == '\\':
+				if i+1 >= len(body) {
+					return nil, fmt.Errorf("invalid row literal %q: "+
+						"unexpected end after backslash", literal)
+				}
+				field.WriteByte(body[i+1])
+				quotedOrEscaped = true
+				i += 2
+				continue
+			case c == '"' && inQuotes && i+1 < len(body) && body[i+1] == '"':
+				// Doubled quote inside quotes
+				field.WriteByte('"')
+				i += 2
+				continue
+			case c == '"':
+				inQuotes = !inQuotes
+				quotedOrEscaped = true
+			case c == ',' && !inQuotes:
+				break scan
+			default:
+				field.WriteByte(c)
+			}
+			i++
+		}
+		if inQuotes {
+			return nil, fmt.Errorf("invalid row literal %q: unterminated "+
+				"quoted field", literal)
+		}
+
+		// An empty, unquoted field is NULL
+		if field.Len() == 0 && !quotedOrEscaped {
+			fields = append(fields, nil)
+		} else {
+			v := field.String()
+			fields = append(fields, &v)
+		}
+
+		if i >= len(body) {
+			break
+		}
+		i++ // Skip the comma
+	}
+
+	return fields, nil
+}
+
+// Format builds a row literal from field values, quoting values the same
+// way PostgreSQL's record output function does. A nil entry is written as
+// a NULL field.
+func Format(fields []*string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if f == nil {
+			continue
+		}
+		if !needsQuotes(*f) {
+			b.WriteString(*f)
+			continue
+		}
+
+		b.WriteByte('"')
+		for j := 0; j < len(*f); j++ {
+			c := (*f)[j]
+			if c == '"' || c == '\\' {
+				b.WriteByte(c)
+			}
+			b.WriteByte(c)
+		}
+		b.WriteByte('"')
+	}
+
+	b.WriteByte(')')
+	return b.String()
+}
+
+// needsQuotes reports whether a field value must be quoted in a row
+// literal.
+func needsQuotes(v string) bool {
+	if v == "" {
+		return true
+	}
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '"', '\\', '(', ')', ',', ' ', '\t', '\n', '\r', '\v', '\f':
+			return true
+		}
+	}
+	return false
+}
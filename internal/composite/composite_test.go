@@ -0,0 +1,148 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package composite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		literal string
+		want    []*string
+		wantErr bool
+	}{
+		{
+			name:    "simple",
+			literal: "(Springfield,62701)",
+			want:    []*string{strPtr("Springfield"), strPtr("62701")},
+		},
+		{
+			name:    "quoted with spaces and commas",
+			literal: `("123 Main St","Apt 4, Floor 2")`,
+			want:    []*string{strPtr("123 Main St"), strPtr("Apt 4, Floor 2")},
+		},
+		{
+			name:    "null and empty string",
+			literal: `(,"",x)`,
+			want:    []*string{nil, strPtr(""), strPtr("x")},
+		},
+		{
+			name:    "all null",
+			literal: "(,)",
+			want:    []*string{nil, nil},
+		},
+		{
+			name:    "single field",
+			literal: "(a)",
+			want:    []*string{strPtr("a")},
+		},
+		{
+			name:    "single null field",
+			literal: "()",
+			want:    []*string{nil},
+		},
+		{
+			name:    "escaped quotes and backslashes",
+			literal: `("say ""hi""","C:\\dir",a\,b)`,
+			want:    []*string{strPtr(`say "hi"`), strPtr(`C:\dir`), strPtr("a,b")},
+		},
+		{
+			name:    "nested composite",
+			literal: `(1,"(2,""x y"")")`,
+			want:    []*string{strPtr("1"), strPtr(`(2,"x y")`)},
+		},
+		{
+			name:    "missing parentheses",
+			literal: "a,b",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated quote",
+			literal: `("abc)`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.literal)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%s) = %v, want %v", tt.literal, deref(got),
+					deref(tt.want))
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		fields []*string
+		want   string
+	}{
+		{[]*string{strPtr("Springfield"), strPtr("62701")}, "(Springfield,62701)"},
+		{[]*string{strPtr("123 Main St"), nil}, `("123 Main St",)`},
+		{[]*string{strPtr("")}, `("")`},
+		{[]*string{strPtr(`say "hi"`), strPtr(`C:\dir`)}, `("say ""hi""","C:\\dir")`},
+		{[]*string{strPtr("(2,x)")}, `("(2,x)")`},
+	}
+
+	for _, tt := range tests {
+		if got := Format(tt.fields); got != tt.want {
+			t.Errorf("Format(%v) = %s, want %s", deref(tt.fields), got, tt.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	literals := []string{
+		"(Springfield,62701)",
+		`("123 Main St",,"")`,
+		`("say ""hi""","C:\\dir")`,
+		`(1,"(2,""x y"")")`,
+	}
+
+	for _, literal := range literals {
+		fields, err := Parse(literal)
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", literal, err)
+		}
+		if got := Format(fields); got != literal {
+			t.Errorf("round trip of %s produced %s", literal, got)
+		}
+	}
+}
+
+// deref converts field pointers to printable values.
+func deref(fields []*string) []any {
+	out := make([]any, len(fields))
+	for i, f := range fields {
+		if f != nil {
+			out[i] = *f
+		}
+	}
+	return out
+}
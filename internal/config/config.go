@@ -16,12 +16,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 
 	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+	"github.com/pgedge/pgedge-anonymizer/internal/pattern"
+	"github.com/pgedge/pgedge-anonymizer/internal/xmlpath"
 )
 
 // Config represents the complete application configuration.
@@ -29,10 +36,338 @@ type Config struct {
 	Database DatabaseConfig `yaml:"database" mapstructure:"database"`
 	Patterns PatternsConfig `yaml:"patterns" mapstructure:"patterns"`
 	Columns  []ColumnConfig `yaml:"columns" mapstructure:"columns"`
+
+	// Profiles group columns holding a person's details, across tables,
+	// so that each person is given a single generated persona. Their
+	// columns are added to Columns when the configuration is loaded.
+	Profiles []ProfileConfig `yaml:"profiles,omitempty" mapstructure:"profiles"`
+
+	// Tables lists tables of which only a subset of rows is kept, the
+	// others being deleted, such as for a small development dataset.
+	Tables []TableConfig `yaml:"tables,omitempty" mapstructure:"tables"`
+
+	// References declares columns holding copies of the values of other
+	// columns without a foreign key, which are anonymized with the same
+	// replacements as the columns they copy.
+	References []ReferenceConfig `yaml:"references,omitempty" mapstructure:"references"`
+
+	// Locale weights the countries that worldwide patterns, and the
+	// generic name, city and address patterns, draw values from, so that
+	// generated data matches the mix of the original; empty draws from
+	// all countries alike.
+	Locale LocaleConfig `yaml:"locale,omitempty" mapstructure:"locale"`
+
+	// Targets lists databases to anonymize with the same columns and
+	// patterns, one after another. When set, Database holds the defaults
+	// for any connection parameters a target leaves unset, rather than
+	// being a target itself.
+	Targets []TargetConfig `yaml:"targets,omitempty" mapstructure:"targets"`
+
+	// CopyTo selects copy mode, in which the database section is only
+	// read and the anonymized rows are written to this database instead.
+	CopyTo *CopyConfig `yaml:"copy_to,omitempty" mapstructure:"copy_to"`
+
+	// Relay configures the relay command, which applies the changes made
+	// to the database section's database to the one in copy_to.
+	Relay *RelayConfig `yaml:"relay,omitempty" mapstructure:"relay"`
+
+	// TargetDictionary is TargetDictionaryPerTarget (the default) to
+	// start each target with an empty dictionary, or
+	// TargetDictionaryShared to map equal values to the same anonymized
+	// value across all targets.
+	TargetDictionary string `yaml:"target_dictionary,omitempty" mapstructure:"target_dictionary"`
+
+	// DictionaryScope is which values are mapped consistently with each
+	// other: DictionaryScopeGlobal (the default) maps an equal value to
+	// the same anonymized value in every column, DictionaryScopePattern
+	// only in the columns anonymized with the same pattern, and
+	// DictionaryScopeColumn only within each column. Columns in a
+	// consistency group share its mappings in any scope.
+	DictionaryScope string `yaml:"dictionary_scope,omitempty" mapstructure:"dictionary_scope"`
+
+	// PartitionBy names a column, such as tenant_id, whose value scopes
+	// the mappings of each row: an equal value is given a different
+	// replacement in each partition. It applies to the columns anonymized
+	// with a pattern in the tables that have the column, unless a column
+	// sets its own.
+	PartitionBy string `yaml:"partition_by,omitempty" mapstructure:"partition_by"`
+
+	// Dictionary tunes the memory and disk the dictionary uses.
+	Dictionary DictionaryConfig `yaml:"dictionary,omitempty" mapstructure:"dictionary"`
+
+	// Sanitize lists the sanitizers generated values pass through, in
+	// order, before they are written, such as generator.SanitizeASCII.
+	Sanitize []string `yaml:"sanitize,omitempty" mapstructure:"sanitize"`
+
+	// Hooks lists external commands to run at points during a run.
+	Hooks HooksConfig `yaml:"hooks,omitempty" mapstructure:"hooks"`
+
+	// Audit configures where a record of each run is kept.
+	Audit AuditConfig `yaml:"audit,omitempty" mapstructure:"audit"`
+
+	// Verify configures the verify command.
+	Verify VerifyConfig `yaml:"verify,omitempty" mapstructure:"verify"`
+
+	// Throughput configures the warnings of columns anonymized much more
+	// slowly than expected.
+	Throughput ThroughputConfig `yaml:"throughput,omitempty" mapstructure:"throughput"`
+
+	// SessionSettings are configuration parameters set for the run's
+	// transaction only, as with SET LOCAL, such as synchronous_commit or
+	// work_mem.
+	SessionSettings map[string]string `yaml:"session_settings,omitempty" mapstructure:"session_settings"`
+
+	// SkipAnalyze disables analyzing the anonymized tables at the end of
+	// a run, leaving their planner statistics, which hold values from
+	// before anonymization, in place.
+	SkipAnalyze bool `yaml:"skip_analyze,omitempty" mapstructure:"skip_analyze"`
+
+	// MaxStatementBytes limits the size of each batch update statement;
+	// batches whose values would exceed it are written with several
+	// statements. Zero uses the default of 64 MiB.
+	MaxStatementBytes int64 `yaml:"max_statement_bytes,omitempty" mapstructure:"max_statement_bytes"`
+
+	// MaxValueBytes limits the size, in bytes of its text, of each value
+	// read to be anonymized, so that a single huge value, such as a JSON
+	// document of hundreds of megabytes, cannot exhaust the run's
+	// memory; larger values are not read, and OversizedValues says what
+	// is done with them: one of the Oversized values, OversizedError by
+	// default. Zero reads values of any size.
+	MaxValueBytes   int64  `yaml:"max_value_bytes,omitempty" mapstructure:"max_value_bytes"`
+	OversizedValues string `yaml:"oversized_values,omitempty" mapstructure:"oversized_values"`
+
+	// LowCardinalityThreshold is the most distinct values a column
+	// anonymized with a pattern may have for its values to be mapped up
+	// front and written with a single statement, rather than row by row.
+	// Zero uses the default of 1000; a negative value disables it.
+	LowCardinalityThreshold int `yaml:"low_cardinality_threshold,omitempty" mapstructure:"low_cardinality_threshold"`
+
+	// AnalyzeColumns estimates the distinct values and width of each
+	// column anonymized with a pattern before any is changed, from its
+	// planner statistics or a sample of its rows, to choose whether its
+	// values are mapped up front or row by row, and the size of its
+	// batches, unless a batch size is configured.
+	AnalyzeColumns bool `yaml:"analyze_columns,omitempty" mapstructure:"analyze_columns"`
+
+	// BatchSize is the number of rows anonymized together, FetchSize the
+	// number read from a column's cursor by each FETCH, and
+	// UpdateChunkSize the most rows written by each update statement.
+	// Zero uses the defaults: batches of 10000 rows, each read with a
+	// single FETCH and written with a single statement, subject to
+	// MaxStatementBytes.
+	BatchSize       int `yaml:"batch_size,omitempty" mapstructure:"batch_size"`
+	FetchSize       int `yaml:"fetch_size,omitempty" mapstructure:"fetch_size"`
+	UpdateChunkSize int `yaml:"update_chunk_size,omitempty" mapstructure:"update_chunk_size"`
+
+	// Strategy is how the rows of each column are read and written:
+	// StrategyCursor (the default), StrategyKeyset, or StrategyCopy.
+	Strategy string `yaml:"strategy,omitempty" mapstructure:"strategy"`
+
+	// TransactionMode is how the run's changes are committed:
+	// TransactionSingle (the default) commits them all at the end,
+	// TransactionPerTable commits each table, together with the tables
+	// it shares a foreign key with, and TransactionPerBatch also commits
+	// the tables anonymized on their own after each batch.
+	TransactionMode string `yaml:"transaction_mode,omitempty" mapstructure:"transaction_mode"`
+
+	// LockTimeout is the longest the run waits for a lock, as a duration
+	// such as "30s", before failing; empty waits as long as the server's
+	// lock_timeout allows.
+	LockTimeout string `yaml:"lock_timeout,omitempty" mapstructure:"lock_timeout"`
+
+	// StatementTimeout is the longest each of the run's statements may
+	// take, as a duration such as "10m", before failing; empty allows as
+	// long as the server's statement_timeout does.
+	StatementTimeout string `yaml:"statement_timeout,omitempty" mapstructure:"statement_timeout"`
+
+	// DisableTriggers keeps user triggers, such as audit triggers, from
+	// firing on the run's updates: "true" disables the enabled triggers
+	// of each anonymized table for the transaction and enables them again
+	// before it commits, and DisableTriggersReplica sets
+	// session_replication_role to replica for the transaction instead.
+	// Empty or "false" leaves triggers to fire.
+	DisableTriggers string `yaml:"disable_triggers,omitempty" mapstructure:"disable_triggers"`
+
+	// DropIndexes drops the indexes on anonymized columns that neither
+	// enforce uniqueness nor back a constraint before the columns of each
+	// table are updated, and creates them again from their recorded
+	// definitions before the transaction commits, so that they are built
+	// once rather than updated with each row.
+	DropIndexes bool `yaml:"drop_indexes,omitempty" mapstructure:"drop_indexes"`
+
+	// PropagateForeignKeys anonymizes the columns whose foreign keys
+	// reference an anonymized column without cascading updates with the
+	// referenced column's settings and mappings, so that equal values are
+	// given equal replacements, dropping the foreign keys while their
+	// tables are updated and adding them again before the transaction
+	// commits.
+	PropagateForeignKeys bool `yaml:"propagate_foreign_keys,omitempty" mapstructure:"propagate_foreign_keys"`
+
+	// RefreshMatviews refreshes the materialized views reading anonymized
+	// tables, directly or through views, once the run has committed, so
+	// that they no longer hold the original values.
+	RefreshMatviews bool `yaml:"refresh_matviews,omitempty" mapstructure:"refresh_matviews"`
+
+	// MaxRowsPerSecond limits the rate at which rows are anonymized, and
+	// SleepBetweenBatches is a duration, such as "100ms", to pause after
+	// each batch, to spare the I/O of a busy server. Zero and empty do not
+	// limit the run.
+	MaxRowsPerSecond    int    `yaml:"max_rows_per_second,omitempty" mapstructure:"max_rows_per_second"`
+	SleepBetweenBatches string `yaml:"sleep_between_batches,omitempty" mapstructure:"sleep_between_batches"`
+
+	// MaxDuration is the longest a run may take, as a duration such as
+	// "2h"; when it is reached, the table being anonymized is rolled back
+	// and the run stops, leaving the tables committed before it recorded
+	// in CheckpointFile. It requires the per_table or per_batch
+	// transaction mode.
+	MaxDuration string `yaml:"max_duration,omitempty" mapstructure:"max_duration"`
+
+	// CheckpointFile is a file recording the tables a run has committed,
+	// which a run with the same configuration skips, to resume a run
+	// that stopped before it finished. It is removed when a run finishes.
+	CheckpointFile string `yaml:"checkpoint_file,omitempty" mapstructure:"checkpoint_file"`
+}
+
+// VerifyConfig configures the check, made by the verify command, that no
+// original values remain in the anonymized columns.
+type VerifyConfig struct {
+	// Fingerprints is a file to which a run records the fingerprints of
+	// the original values it replaces, and from which verify reads them.
+	Fingerprints string `yaml:"fingerprints,omitempty" mapstructure:"fingerprints"`
+
+	// Sample limits the number of rows checked in each column; 0 checks
+	// every row.
+	Sample int64 `yaml:"sample,omitempty" mapstructure:"sample"`
+
+	// MaxMatchRate is the fraction of the values checked in a column that
+	// may match an original value, for patterns whose generated values
+	// can coincide with real ones, such as dates.
+	MaxMatchRate float64 `yaml:"max_match_rate,omitempty" mapstructure:"max_match_rate"`
+
+	// PIIPatterns are regular expressions no value may match.
+	PIIPatterns []PIIPattern `yaml:"pii_patterns,omitempty" mapstructure:"pii_patterns"`
+}
+
+// PIIPattern is a named regular expression matching personal data.
+type PIIPattern struct {
+	Name  string `yaml:"name" mapstructure:"name"`
+	Regex string `yaml:"regex" mapstructure:"regex"`
+}
+
+// AuditConfig configures the audit record written after each run, to an
+// append-only file, a table in the anonymized database, or both.
+type AuditConfig struct {
+	// File is a file to which a JSON line is appended for each run.
+	File string `yaml:"file,omitempty" mapstructure:"file"`
+
+	// Table is a table, optionally schema-qualified, into which a row is
+	// inserted for each run; it is created if it does not exist.
+	Table string `yaml:"table,omitempty" mapstructure:"table"`
+
+	// Operator identifies the person or system running the anonymizer;
+	// it defaults to the operating system user.
+	Operator string `yaml:"operator,omitempty" mapstructure:"operator"`
+}
+
+// Enabled reports whether an audit record is to be written.
+func (a AuditConfig) Enabled() bool {
+	return a.File != "" || a.Table != ""
+}
+
+// settingNameRE matches configuration parameter names, including the
+// qualified names of extension parameters (auto_explain.log_analyze).
+var settingNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SessionSettingNames returns the names of the session settings, sorted
+// so that they are applied in a predictable order.
+func (c *Config) SessionSettingNames() []string {
+	names := make([]string, 0, len(c.SessionSettings))
+	for name := range c.SessionSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HooksConfig lists the commands to run before and after each column and
+// each batch of rows.
+type HooksConfig struct {
+	BeforeColumn []HookCommand `yaml:"before_column,omitempty" mapstructure:"before_column"`
+	AfterColumn  []HookCommand `yaml:"after_column,omitempty" mapstructure:"after_column"`
+	BeforeBatch  []HookCommand `yaml:"before_batch,omitempty" mapstructure:"before_batch"`
+	AfterBatch   []HookCommand `yaml:"after_batch,omitempty" mapstructure:"after_batch"`
+}
+
+// HookCommand is an external command run as a hook.
+type HookCommand struct {
+	Exec string   `yaml:"exec" mapstructure:"exec"`
+	Args []string `yaml:"args,omitempty" mapstructure:"args"`
+}
+
+// Values for Config.TargetDictionary.
+const (
+	TargetDictionaryPerTarget = "per_target"
+	TargetDictionaryShared    = "shared"
+)
+
+// Values for Config.DictionaryScope.
+const (
+	DictionaryScopeGlobal  = "global"
+	DictionaryScopePattern = "pattern"
+	DictionaryScopeColumn  = "column"
+)
+
+// Values for Config.Strategy and ColumnConfig.Strategy.
+const (
+	StrategyCursor = "cursor" // Read with a cursor, update each batch
+	StrategyKeyset = "keyset" // Page through the table by primary key
+	StrategyCopy   = "copy"   // Copy new values to a staging table, then update once
+
+	// StrategyShuffle permutes a column's own values among its rows, and
+	// StrategyNullify sets them to NULL, in place of a pattern; they are
+	// ColumnConfig.Strategy values only.
+	StrategyShuffle = "shuffle"
+	StrategyNullify = "nullify"
+)
+
+// Values for Config.DisableTriggers, as returned by TriggerMode.
+const (
+	DisableTriggersTable   = "true"
+	DisableTriggersReplica = "session_replication_role"
+)
+
+// Values for Config.TransactionMode.
+const (
+	TransactionSingle   = "single"
+	TransactionPerTable = "per_table"
+	TransactionPerBatch = "per_batch"
+)
+
+// TargetConfig is a database listed under targets. Connection parameters
+// that are not set are taken from the database section.
+type TargetConfig struct {
+	// Name identifies the target in progress output and statistics; it
+	// defaults to the database name.
+	Name string `yaml:"name,omitempty" mapstructure:"name"`
+
+	DatabaseConfig `yaml:",inline" mapstructure:",squash"`
+}
+
+// Target is a database to anonymize, with its connection parameters
+// resolved.
+type Target struct {
+	Name     string
+	Database DatabaseConfig
 }
 
 // DatabaseConfig holds PostgreSQL connection parameters.
 type DatabaseConfig struct {
+	// URL is a connection URI, such as postgres://user@host/db, and
+	// Service a service in the libpq service file; parameters set below
+	// take precedence over theirs.
+	URL     string `yaml:"url,omitempty" mapstructure:"url"`
+	Service string `yaml:"service,omitempty" mapstructure:"service"`
+
 	Host        string `yaml:"host" mapstructure:"host"`
 	Port        int    `yaml:"port" mapstructure:"port"`
 	Database    string `yaml:"database" mapstructure:"database"`
@@ -42,6 +377,35 @@ type DatabaseConfig struct {
 	SSLCert     string `yaml:"sslcert,omitempty" mapstructure:"sslcert"`
 	SSLKey      string `yaml:"sslkey,omitempty" mapstructure:"sslkey"`
 	SSLRootCert string `yaml:"sslrootcert,omitempty" mapstructure:"sslrootcert"`
+
+	// PasswordFile is a file holding the password, and PasswordCommand a
+	// command printing it, so that it need not be in the configuration.
+	PasswordFile    string           `yaml:"password_file,omitempty" mapstructure:"password_file"`
+	PasswordCommand *PasswordCommand `yaml:"password_command,omitempty" mapstructure:"password_command"`
+
+	// PassFile is the password file in libpq's .pgpass format looked up
+	// when no password is given; it defaults to PGPASSFILE, or
+	// ~/.pgpass.
+	PassFile string `yaml:"passfile,omitempty" mapstructure:"passfile"`
+
+	// ConnectTimeout is the longest each attempt to connect may take, as a
+	// duration; it defaults to 10s.
+	ConnectTimeout string `yaml:"connect_timeout,omitempty" mapstructure:"connect_timeout"`
+
+	// ConnectRetries is how many more times connecting, or starting a
+	// transaction on a new connection, is attempted after a transient
+	// error, such as a dropped connection or a server restarting; each
+	// attempt waits RetryBackoff, doubled after each one up to 30s. A
+	// unit of work whose connection is lost before it commits anything is
+	// also started again on a new connection. ConnectRetries defaults to
+	// DefaultConnectRetries; zero fails at once.
+	ConnectRetries *int   `yaml:"connect_retries,omitempty" mapstructure:"connect_retries"`
+	RetryBackoff   string `yaml:"retry_backoff,omitempty" mapstructure:"retry_backoff"`
+
+	// MaxConnections and MaxConnectionLifetime size the pool of
+	// connections; they default to 10 and 5m.
+	MaxConnections        int    `yaml:"max_connections,omitempty" mapstructure:"max_connections"`
+	MaxConnectionLifetime string `yaml:"max_connection_lifetime,omitempty" mapstructure:"max_connection_lifetime"`
 }
 
 // PatternsConfig defines pattern file locations.
@@ -49,18 +413,142 @@ type PatternsConfig struct {
 	DefaultPath     string `yaml:"default_path,omitempty" mapstructure:"default_path"`
 	UserPath        string `yaml:"user_path,omitempty" mapstructure:"user_path"`
 	DisableDefaults bool   `yaml:"disable_defaults" mapstructure:"disable_defaults"`
+
+	// Plugins lists Go plugin files providing additional generators.
+	Plugins []string `yaml:"plugins,omitempty" mapstructure:"plugins"`
 }
 
 // ColumnConfig maps a database column to an anonymization pattern.
-// For simple columns, use Pattern. For JSON/JSONB columns, use JSONPaths.
+// For simple columns, use Pattern. For JSON/JSONB columns, use JSONPaths
+// and/or JSONSchema, for XML columns (or text columns holding XML), use
+// XMLPaths, and for composite-typed columns, use Fields.
+// Any part of Column may contain '*' wildcards (e.g. public.*.email), in
+// which case the entry is expanded against the database schema.
 type ColumnConfig struct {
-	Column    string           `yaml:"column" mapstructure:"column"`
-	Pattern   string           `yaml:"pattern,omitempty" mapstructure:"pattern"`
-	JSONPaths []JSONPathConfig `yaml:"json_paths,omitempty" mapstructure:"json_paths"`
+	Column     string            `yaml:"column" mapstructure:"column"`
+	Pattern    string            `yaml:"pattern,omitempty" mapstructure:"pattern"`
+	JSONPaths  []JSONPathConfig  `yaml:"json_paths,omitempty" mapstructure:"json_paths"`
+	JSONSchema string            `yaml:"json_schema,omitempty" mapstructure:"json_schema"`
+	XMLPaths   []XMLPathConfig   `yaml:"xml_paths,omitempty" mapstructure:"xml_paths"`
+	Fields     map[string]string `yaml:"fields,omitempty" mapstructure:"fields"`
+
+	// TreatAsJSON allows json_paths on a text column holding JSON
+	// documents; otherwise the column must be of type json or jsonb.
+	TreatAsJSON bool `yaml:"treat_as_json,omitempty" mapstructure:"treat_as_json"`
+
+	// BatchSize and Strategy override the run's batch size and strategy
+	// for this column; StrategyShuffle shuffles its values, and
+	// StrategyNullify sets them to NULL, in place of a pattern.
+	BatchSize int    `yaml:"batch_size,omitempty" mapstructure:"batch_size"`
+	Strategy  string `yaml:"strategy,omitempty" mapstructure:"strategy"`
+
+	// SamplePercent anonymizes only a random sample of about this
+	// percentage of the column's rows, leaving the others as they are,
+	// such as for a demonstration dataset; zero anonymizes every row.
+	SamplePercent float64 `yaml:"sample_percent,omitempty" mapstructure:"sample_percent"`
+
+	// Constant replaces every non-NULL value of the column with the same
+	// value, in place of a pattern; it may be empty.
+	Constant *string `yaml:"constant,omitempty" mapstructure:"constant"`
+
+	// ConsistencyGroup names a set of columns, in any tables, whose equal
+	// original values are given equal replacements, kept apart from the
+	// mappings of other columns, for values duplicated across tables
+	// without foreign keys.
+	ConsistencyGroup string `yaml:"consistency_group,omitempty" mapstructure:"consistency_group"`
+
+	// PartitionBy names a column of the same table, such as tenant_id,
+	// whose value scopes the column's mappings, in place of the run's
+	// partition_by.
+	PartitionBy string `yaml:"partition_by,omitempty" mapstructure:"partition_by"`
+
+	// ExpectedRowsPerSecond is the rate at which the column is expected
+	// to be anonymized; a run much slower than it is warned of.
+	ExpectedRowsPerSecond float64 `yaml:"expected_rows_per_second,omitempty" mapstructure:"expected_rows_per_second"`
+
+	// Derive computes the column's value from the anonymized values of
+	// other columns of the same row, such as "email from first_name,
+	// last_name", in place of a pattern.
+	Derive string `yaml:"derive,omitempty" mapstructure:"derive"`
+
+	// ShuffleBy names a column of the same table whose values group the
+	// rows of a column shuffled with StrategyShuffle, so that values are
+	// only exchanged between rows of the same group.
+	ShuffleBy string `yaml:"shuffle_by,omitempty" mapstructure:"shuffle_by"`
+
+	// Entity names the column of the same table identifying the entity,
+	// such as a patient, each row belongs to. A column anonymized with
+	// DATE_SHIFT_CONSISTENT shifts all the dates of an entity, in any
+	// table, by the same offset.
+	Entity string `yaml:"entity,omitempty" mapstructure:"entity"`
+
+	// LargeObject is true if the column holds the OIDs of large objects,
+	// whose contents are anonymized with the pattern in place of the
+	// column's values.
+	LargeObject bool `yaml:"large_object,omitempty" mapstructure:"large_object"`
+
+	// Document is true if the values of a bytea column are text
+	// documents, whose text is anonymized with the pattern in the
+	// encoding detected for each value. Other bytea columns are
+	// anonymized value by value, as other columns are.
+	Document bool `yaml:"document,omitempty" mapstructure:"document"`
+
+	// PreserveDistribution replaces the values of a numeric column with
+	// values drawn from a histogram of its original values, in place of a
+	// pattern, so that the column keeps realistic ranges.
+	PreserveDistribution bool `yaml:"preserve_distribution,omitempty" mapstructure:"preserve_distribution"`
+
+	// OnCollision is how a value generated for a column with a unique
+	// constraint is made unique when it has already been given to another
+	// value, and CollisionRetries fresh values generated for it have too:
+	// one of the Collision values, CollisionSuffix by default.
+	OnCollision string `yaml:"on_collision,omitempty" mapstructure:"on_collision"`
+
+	// CollisionRetries is the number of times a value is generated again
+	// when it collides, before OnCollision applies; nil for
+	// DefaultCollisionRetries.
+	CollisionRetries *int `yaml:"collision_retries,omitempty" mapstructure:"collision_retries"`
+
+	// Profile is set on the columns added for profiles.
+	Profile *ProfileColumn `yaml:"-" mapstructure:"-"`
 }
 
+// Values for ColumnConfig.OnCollision.
+const (
+	CollisionSuffix   = "suffix"   // Append a counter, before any @
+	CollisionSequence = "sequence" // Take the next number of a sequence
+	CollisionError    = "error"    // Fail the run
+)
+
+// Values for Config.OversizedValues.
+const (
+	OversizedError = "error" // Fail the run
+	OversizedSkip  = "skip"  // Leave the value unchanged, with a warning
+)
+
+// DefaultCollisionRetries is the number of times a colliding value is
+// generated again by default.
+const DefaultCollisionRetries = 10
+
 // JSONPathConfig specifies a JSON path within a column and its pattern.
+// OnMissing controls what happens if the path does not resolve in any
+// document in the column; it defaults to OnMissingIgnore.
 type JSONPathConfig struct {
+	Path      string `yaml:"path" mapstructure:"path"`
+	Pattern   string `yaml:"pattern" mapstructure:"pattern"`
+	OnMissing string `yaml:"on_missing,omitempty" mapstructure:"on_missing"`
+}
+
+// Values for JSONPathConfig.OnMissing.
+const (
+	OnMissingIgnore = "ignore" // Skip silently
+	OnMissingWarn   = "warn"   // Log a warning
+	OnMissingError  = "error"  // Fail the run
+)
+
+// XMLPathConfig specifies an XPath expression within a column and its
+// pattern. The expression may select elements, text nodes, or attributes.
+type XMLPathConfig struct {
 	Path    string `yaml:"path" mapstructure:"path"`
 	Pattern string `yaml:"pattern" mapstructure:"pattern"`
 }
@@ -70,8 +558,56 @@ func (c ColumnConfig) IsJSONColumn() bool {
 	return len(c.JSONPaths) > 0
 }
 
+// IsXMLColumn returns true if this column uses XPath specifications.
+func (c ColumnConfig) IsXMLColumn() bool {
+	return len(c.XMLPaths) > 0
+}
+
+// IsCompositeColumn returns true if this column uses per-field patterns for
+// a composite type.
+func (c ColumnConfig) IsCompositeColumn() bool {
+	return len(c.Fields) > 0
+}
+
+// FieldNames returns the names of the configured composite fields in
+// sorted order.
+func (c ColumnConfig) FieldNames() []string {
+	names := make([]string, 0, len(c.Fields))
+	for name := range c.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PatternNames returns every pattern referenced by this column, whether
+// directly or through its JSON paths, XML paths, or composite fields.
+func (c ColumnConfig) PatternNames() []string {
+	var names []string
+	if c.Pattern != "" {
+		names = append(names, c.Pattern)
+	}
+	for _, jp := range c.JSONPaths {
+		names = append(names, jp.Pattern)
+	}
+	for _, xp := range c.XMLPaths {
+		names = append(names, xp.Pattern)
+	}
+	for _, field := range c.FieldNames() {
+		names = append(names, c.Fields[field])
+	}
+	return names
+}
+
+// HasWildcard returns true if the column reference contains wildcards and
+// must be expanded against the database schema before use.
+func (c ColumnConfig) HasWildcard() bool {
+	return strings.Contains(c.Column, "*")
+}
+
 // CLIOverrides represents command-line overrides for config.
 type CLIOverrides struct {
+	URL             *string
 	Host            *string
 	Port            *int
 	Database        *string
@@ -80,11 +616,33 @@ type CLIOverrides struct {
 	DefaultPatterns *string
 	UserPatterns    *string
 	DisableDefaults *bool
+	BatchSize       *int
+	FetchSize       *int
+	UpdateChunkSize *int
+	LockTimeout     *string
+
+	StatementTimeout *string
+
+	MaxRowsPerSecond    *int
+	SleepBetweenBatches *string
+
+	MaxDuration *string
+
+	AnalyzeColumns *bool
 }
 
 // ConnectionString returns a PostgreSQL connection string, falling back to
-// libpq environment variables for missing values.
+// libpq environment variables for missing values. With a connection URI,
+// or a service, it holds only the parameters set, and the driver looks up
+// the others as libpq would.
 func (d *DatabaseConfig) ConnectionString() string {
+	if d.URL != "" {
+		return d.urlConnectionString()
+	}
+	if d.usesService() {
+		return d.serviceConnectionString()
+	}
+
 	host := d.Host
 	if host == "" {
 		host = os.Getenv("PGHOST")
@@ -134,7 +692,10 @@ func (d *DatabaseConfig) ConnectionString() string {
 		host, port, database, user, sslmode)
 
 	if password != "" {
-		connStr += fmt.Sprintf(" password=%s", password)
+		connStr += fmt.Sprintf(" password=%s", quoteConnValue(password))
+	}
+	if d.PassFile != "" {
+		connStr += fmt.Sprintf(" passfile=%s", quoteConnValue(d.PassFile))
 	}
 
 	if d.SSLCert != "" {
@@ -162,6 +723,14 @@ func Load(path string) (*Config, error) {
 		return nil, errors.NewConfigError(path, "failed to parse config file", err)
 	}
 
+	if err := cfg.ExpandEnv(); err != nil {
+		return nil, errors.NewConfigError(path, "failed to expand config file", err)
+	}
+	if err := cfg.ResolveJSONSchemas(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	cfg.ResolveProfiles()
+
 	return &cfg, nil
 }
 
@@ -171,11 +740,27 @@ func LoadFromViper() (*Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, errors.NewConfigError("", "failed to unmarshal config", err)
 	}
+
+	if err := cfg.ExpandEnv(); err != nil {
+		return nil, errors.NewConfigError(viper.ConfigFileUsed(),
+			"failed to expand config", err)
+	}
+
+	// Schema references are relative to the config file
+	if err := cfg.ResolveJSONSchemas(
+		filepath.Dir(viper.ConfigFileUsed())); err != nil {
+		return nil, err
+	}
+	cfg.ResolveProfiles()
+
 	return &cfg, nil
 }
 
 // ApplyOverrides applies CLI overrides to the configuration.
 func (c *Config) ApplyOverrides(overrides CLIOverrides) {
+	if overrides.URL != nil {
+		c.Database.URL = *overrides.URL
+	}
 	if overrides.Host != nil {
 		c.Database.Host = *overrides.Host
 	}
@@ -200,18 +785,304 @@ func (c *Config) ApplyOverrides(overrides CLIOverrides) {
 	if overrides.DisableDefaults != nil {
 		c.Patterns.DisableDefaults = *overrides.DisableDefaults
 	}
+	if overrides.BatchSize != nil {
+		c.BatchSize = *overrides.BatchSize
+	}
+	if overrides.FetchSize != nil {
+		c.FetchSize = *overrides.FetchSize
+	}
+	if overrides.UpdateChunkSize != nil {
+		c.UpdateChunkSize = *overrides.UpdateChunkSize
+	}
+	if overrides.LockTimeout != nil {
+		c.LockTimeout = *overrides.LockTimeout
+	}
+	if overrides.StatementTimeout != nil {
+		c.StatementTimeout = *overrides.StatementTimeout
+	}
+	if overrides.MaxRowsPerSecond != nil {
+		c.MaxRowsPerSecond = *overrides.MaxRowsPerSecond
+	}
+	if overrides.SleepBetweenBatches != nil {
+		c.SleepBetweenBatches = *overrides.SleepBetweenBatches
+	}
+	if overrides.MaxDuration != nil {
+		c.MaxDuration = *overrides.MaxDuration
+	}
+	if overrides.AnalyzeColumns != nil {
+		c.AnalyzeColumns = *overrides.AnalyzeColumns
+	}
+}
+
+// mergeDefaults returns the connection parameters with any unset fields
+// taken from defaults.
+func (d DatabaseConfig) mergeDefaults(defaults DatabaseConfig) DatabaseConfig {
+	fill := func(value *string, def string) {
+		if *value == "" {
+			*value = def
+		}
+	}
+	// A target naming its own URI or service does not inherit the
+	// parameters of another
+	if d.URL == "" && d.Service == "" {
+		d.URL = defaults.URL
+		d.Service = defaults.Service
+	}
+	fill(&d.Host, defaults.Host)
+	if d.Port == 0 {
+		d.Port = defaults.Port
+	}
+	fill(&d.Database, defaults.Database)
+	fill(&d.User, defaults.User)
+	fill(&d.Password, defaults.Password)
+	fill(&d.PasswordFile, defaults.PasswordFile)
+	if d.PasswordCommand == nil {
+		d.PasswordCommand = defaults.PasswordCommand
+	}
+	fill(&d.PassFile, defaults.PassFile)
+	fill(&d.ConnectTimeout, defaults.ConnectTimeout)
+	if d.ConnectRetries == nil {
+		d.ConnectRetries = defaults.ConnectRetries
+	}
+	fill(&d.RetryBackoff, defaults.RetryBackoff)
+	if d.MaxConnections == 0 {
+		d.MaxConnections = defaults.MaxConnections
+	}
+	fill(&d.MaxConnectionLifetime, defaults.MaxConnectionLifetime)
+	fill(&d.SSLMode, defaults.SSLMode)
+	fill(&d.SSLCert, defaults.SSLCert)
+	fill(&d.SSLKey, defaults.SSLKey)
+	fill(&d.SSLRootCert, defaults.SSLRootCert)
+	return d
+}
+
+// HasTargets reports whether the configuration lists target databases.
+func (c *Config) HasTargets() bool {
+	return len(c.Targets) > 0
+}
+
+// ResolveTargets returns the databases to anonymize: the configured
+// targets with unset connection parameters taken from the database
+// section, or the database section alone if there are no targets.
+func (c *Config) ResolveTargets() []Target {
+	if !c.HasTargets() {
+		return []Target{{Name: c.Database.DatabaseName(), Database: c.Database}}
+	}
+
+	targets := make([]Target, len(c.Targets))
+	for i, t := range c.Targets {
+		db := t.DatabaseConfig.mergeDefaults(c.Database)
+		name := t.Name
+		if name == "" {
+			name = db.DatabaseName()
+		}
+		targets[i] = Target{Name: name, Database: db}
+	}
+	return targets
+}
+
+// ForTarget returns a shallow copy of the configuration that anonymizes
+// the given target only.
+func (c *Config) ForTarget(t Target) *Config {
+	cp := *c
+	cp.Database = t.Database
+	cp.Targets = nil
+	return &cp
 }
 
 // Validate checks the configuration for completeness and correctness.
 func (c *Config) Validate() error {
 	var errs []string
 
-	// Database validation - either config or env vars must provide these
-	if c.Database.Database == "" && os.Getenv("PGDATABASE") == "" {
-		errs = append(errs, "database name is required")
+	// Database validation - either config or env vars must provide these.
+	// Each target must name its database, as falling back to PGDATABASE
+	// would anonymize the same database repeatedly.
+	if !c.HasTargets() {
+		if c.Database.DatabaseName() == "" && os.Getenv("PGDATABASE") == "" &&
+			!c.Database.usesService() {
+			errs = append(errs, "database name is required")
+		}
+	} else {
+		names := make(map[string]bool)
+		for i, t := range c.ResolveTargets() {
+			if t.Database.DatabaseName() == "" {
+				errs = append(errs, fmt.Sprintf(
+					"targets[%d]: database name is required", i))
+				continue
+			}
+			if names[t.Name] {
+				errs = append(errs, fmt.Sprintf(
+					"targets[%d]: duplicate target name %q; set name to tell "+
+						"targets apart", i, t.Name))
+			}
+			names[t.Name] = true
+		}
+	}
+	for point, commands := range map[string][]HookCommand{
+		"before_column": c.Hooks.BeforeColumn,
+		"after_column":  c.Hooks.AfterColumn,
+		"before_batch":  c.Hooks.BeforeBatch,
+		"after_batch":   c.Hooks.AfterBatch,
+	} {
+		for i, cmd := range commands {
+			if cmd.Exec == "" {
+				errs = append(errs, fmt.Sprintf(
+					"hooks.%s[%d]: exec is required", point, i))
+			}
+		}
+	}
+	if c.Audit.Table != "" && len(strings.Split(c.Audit.Table, ".")) > 2 {
+		errs = append(errs, fmt.Sprintf(
+			"audit.table: %q must be in table or schema.table format",
+			c.Audit.Table))
+	}
+	for _, name := range c.SessionSettingNames() {
+		if !settingNameRE.MatchString(name) {
+			errs = append(errs, fmt.Sprintf(
+				"session_settings: invalid parameter name %q", name))
+		}
+	}
+	if c.MaxStatementBytes < 0 {
+		errs = append(errs, "max_statement_bytes must not be negative")
+	}
+	if c.MaxValueBytes < 0 {
+		errs = append(errs, "max_value_bytes must not be negative")
+	}
+	switch c.OversizedValues {
+	case "", OversizedError, OversizedSkip:
+	default:
+		errs = append(errs, fmt.Sprintf(
+			"oversized_values must be 'error' or 'skip', got %q",
+			c.OversizedValues))
+	}
+	for _, size := range []struct {
+		name  string
+		value int
+	}{
+		{"batch_size", c.BatchSize},
+		{"fetch_size", c.FetchSize},
+		{"update_chunk_size", c.UpdateChunkSize},
+	} {
+		if size.value < 0 {
+			errs = append(errs, fmt.Sprintf("%s must not be negative",
+				size.name))
+		}
 	}
-	// User can come from config, PGUSER, or fall back to $USER (like libpq)
-	if c.Database.User == "" && os.Getenv("PGUSER") == "" && os.Getenv("USER") == "" {
+	if c.Verify.Sample < 0 {
+		errs = append(errs, "verify.sample must not be negative")
+	}
+	if c.Verify.MaxMatchRate < 0 || c.Verify.MaxMatchRate > 1 {
+		errs = append(errs, fmt.Sprintf(
+			"verify.max_match_rate must be between 0 and 1, got %g",
+			c.Verify.MaxMatchRate))
+	}
+	for i, p := range c.Verify.PIIPatterns {
+		if p.Name == "" {
+			errs = append(errs, fmt.Sprintf(
+				"verify.pii_patterns[%d]: name is required", i))
+		}
+		if _, err := regexp.Compile(p.Regex); err != nil || p.Regex == "" {
+			errs = append(errs, fmt.Sprintf(
+				"verify.pii_patterns[%d]: invalid regex %q", i, p.Regex))
+		}
+	}
+	if !validStrategy(c.Strategy) {
+		errs = append(errs, fmt.Sprintf(
+			"strategy must be 'cursor', 'keyset', or 'copy', got %q",
+			c.Strategy))
+	}
+	if c.LockTimeout != "" {
+		if d, err := time.ParseDuration(c.LockTimeout); err != nil || d <= 0 {
+			errs = append(errs, fmt.Sprintf(
+				"lock_timeout must be a positive duration such as '30s', got %q",
+				c.LockTimeout))
+		}
+	}
+	if c.StatementTimeout != "" {
+		if d, err := time.ParseDuration(c.StatementTimeout); err != nil || d <= 0 {
+			errs = append(errs, fmt.Sprintf(
+				"statement_timeout must be a positive duration such as '10m', "+
+					"got %q", c.StatementTimeout))
+		}
+	}
+	if c.MaxRowsPerSecond < 0 {
+		errs = append(errs, "max_rows_per_second must not be negative")
+	}
+	if c.SleepBetweenBatches != "" {
+		if d, err := time.ParseDuration(c.SleepBetweenBatches); err != nil || d < 0 {
+			errs = append(errs, fmt.Sprintf(
+				"sleep_between_batches must be a duration such as '100ms', got %q",
+				c.SleepBetweenBatches))
+		}
+	}
+	switch strings.ToLower(c.DisableTriggers) {
+	case "", "false", "0", "true", "1", DisableTriggersReplica:
+	default:
+		errs = append(errs, fmt.Sprintf(
+			"disable_triggers must be true, false, or "+
+				"'session_replication_role', got %q", c.DisableTriggers))
+	}
+	switch c.TransactionMode {
+	case "", TransactionSingle, TransactionPerTable:
+	case TransactionPerBatch:
+		// The copy strategy's staging table is dropped at each commit
+		if c.usesStrategy(StrategyCopy) {
+			errs = append(errs,
+				"transaction_mode 'per_batch' cannot be used with strategy 'copy'")
+		}
+		// Dropped indexes would be committed before they are recreated
+		if c.DropIndexes {
+			errs = append(errs,
+				"transaction_mode 'per_batch' cannot be used with drop_indexes")
+		}
+		// Likewise foreign keys dropped to propagate values
+		if c.PropagateForeignKeys {
+			errs = append(errs, "transaction_mode 'per_batch' cannot be "+
+				"used with propagate_foreign_keys")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf(
+			"transaction_mode must be 'single', 'per_table', or 'per_batch', got %q",
+			c.TransactionMode))
+	}
+	errs = append(errs, c.validateMaxDuration()...)
+	errs = append(errs, c.validateThroughput()...)
+	if len(c.Locale) > 0 {
+		if _, err := generator.NewLocale(c.Locale.Weights()); err != nil {
+			errs = append(errs, fmt.Sprintf("locale: %v", err))
+		}
+	}
+	switch c.TargetDictionary {
+	case "", TargetDictionaryPerTarget, TargetDictionaryShared:
+	default:
+		errs = append(errs, fmt.Sprintf(
+			"target_dictionary must be 'per_target' or 'shared', got %q",
+			c.TargetDictionary))
+	}
+	switch c.DictionaryScope {
+	case "", DictionaryScopeGlobal, DictionaryScopePattern, DictionaryScopeColumn:
+	default:
+		errs = append(errs, fmt.Sprintf(
+			"dictionary_scope must be 'global', 'pattern', or 'column', got %q",
+			c.DictionaryScope))
+	}
+	sanitizers := make(map[string]bool)
+	for _, name := range c.Sanitize {
+		if !generator.IsSanitizer(name) {
+			errs = append(errs, fmt.Sprintf(
+				"sanitize: unknown sanitizer %q; must be one of %s", name,
+				strings.Join(generator.Sanitizers, ", ")))
+		} else if sanitizers[name] {
+			errs = append(errs, fmt.Sprintf(
+				"sanitize: sanitizer %q is listed more than once", name))
+		}
+		sanitizers[name] = true
+	}
+	// User can come from config, the URI or service, PGUSER, or fall back
+	// to $USER (like libpq)
+	if c.Database.User == "" && c.Database.URL == "" &&
+		!c.Database.usesService() && os.Getenv("PGUSER") == "" &&
+		os.Getenv("USER") == "" {
 		errs = append(errs, "database user is required")
 	}
 
@@ -219,8 +1090,29 @@ func (c *Config) Validate() error {
 	if len(c.Columns) == 0 {
 		errs = append(errs, "at least one column must be specified")
 	}
+	errs = append(errs, c.validateProfiles()...)
+	errs = append(errs, c.validateDerived()...)
+	errs = append(errs, c.validateDateShift()...)
+	errs = append(errs, c.validateShuffle()...)
+	errs = append(errs, c.validatePartitions()...)
+	errs = append(errs, c.validateLargeObjects()...)
+	errs = append(errs, c.validateDistributions()...)
+	errs = append(errs, c.validateDictionary()...)
+	errs = append(errs, c.validateCredentials()...)
+	errs = append(errs, c.validateConnections()...)
+	errs = append(errs, c.validateFill()...)
+	errs = append(errs, c.validateTables()...)
+	errs = append(errs, c.validateReferences()...)
+	errs = append(errs, c.validateCopy()...)
+	errs = append(errs, c.validateRelay()...)
+	errs = append(errs, c.validateConsistencyGroups()...)
 
 	for i, col := range c.Columns {
+		// Profile columns are validated with their profiles
+		if col.IsProfileColumn() {
+			continue
+		}
+
 		if col.Column == "" {
 			errs = append(errs, fmt.Sprintf("column[%d]: column name is required", i))
 		} else {
@@ -233,13 +1125,59 @@ func (c *Config) Validate() error {
 			}
 		}
 
-		// Validate pattern vs json_paths (mutually exclusive)
+		if col.JSONSchema != "" && !col.IsJSONColumn() {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: json_schema %q has not been resolved", i,
+				col.JSONSchema))
+		}
+
+		if col.TreatAsJSON && !col.IsJSONColumn() {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: treat_as_json requires 'json_paths'", i))
+		}
+
+		if col.BatchSize < 0 {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: batch_size must not be negative", i))
+		}
+		if col.SamplePercent < 0 || col.SamplePercent > 100 {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: sample_percent must be between 0 and 100, "+
+					"got %g", i, col.SamplePercent))
+		}
+		switch col.OnCollision {
+		case "", CollisionSuffix, CollisionSequence, CollisionError:
+		default:
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: on_collision must be 'suffix', 'sequence', or "+
+					"'error', got %q", i, col.OnCollision))
+		}
+		if col.CollisionRetries != nil && *col.CollisionRetries < 0 {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: collision_retries must not be negative", i))
+		}
+		if !validStrategy(col.Strategy) && !col.IsShuffleColumn() &&
+			!col.IsNullifyColumn() {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: strategy must be 'cursor', 'keyset', 'copy', "+
+					"'shuffle', or 'nullify', got %q", i, col.Strategy))
+		}
+
+		// Validate pattern vs json_paths vs xml_paths (mutually exclusive)
 		if col.IsJSONColumn() {
 			// JSON column validation
 			if col.Pattern != "" {
 				errs = append(errs, fmt.Sprintf(
 					"column[%d]: cannot specify both 'pattern' and 'json_paths'", i))
 			}
+			if col.IsXMLColumn() {
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: cannot specify both 'json_paths' and 'xml_paths'", i))
+			}
+			if col.IsCompositeColumn() {
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: cannot specify both 'json_paths' and 'fields'", i))
+			}
 			for j, jp := range col.JSONPaths {
 				if jp.Path == "" {
 					errs = append(errs, fmt.Sprintf(
@@ -253,10 +1191,56 @@ func (c *Config) Validate() error {
 					errs = append(errs, fmt.Sprintf(
 						"column[%d].json_paths[%d]: pattern is required", i, j))
 				}
+				switch jp.OnMissing {
+				case "", OnMissingIgnore, OnMissingWarn, OnMissingError:
+				default:
+					errs = append(errs, fmt.Sprintf(
+						"column[%d].json_paths[%d]: on_missing must be one of "+
+							"'warn', 'error', or 'ignore', got %q", i, j,
+						jp.OnMissing))
+				}
+			}
+		} else if col.IsXMLColumn() {
+			// XML column validation
+			if col.Pattern != "" {
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: cannot specify both 'pattern' and 'xml_paths'", i))
+			}
+			if col.IsCompositeColumn() {
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: cannot specify both 'xml_paths' and 'fields'", i))
+			}
+			for j, xp := range col.XMLPaths {
+				if xp.Path == "" {
+					errs = append(errs, fmt.Sprintf(
+						"column[%d].xml_paths[%d]: path is required", i, j))
+				} else if err := xmlpath.Validate(xp.Path); err != nil {
+					errs = append(errs, fmt.Sprintf(
+						"column[%d].xml_paths[%d]: %v", i, j, err))
+				}
+				if xp.Pattern == "" {
+					errs = append(errs, fmt.Sprintf(
+						"column[%d].xml_paths[%d]: pattern is required", i, j))
+				}
+			}
+		} else if col.IsCompositeColumn() {
+			// Composite column validation
+			if col.Pattern != "" {
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: cannot specify both 'pattern' and 'fields'", i))
+			}
+			for _, field := range col.FieldNames() {
+				if col.Fields[field] == "" {
+					errs = append(errs, fmt.Sprintf(
+						"column[%d].fields.%s: pattern is required", i, field))
+				}
 			}
 		} else {
-			// Simple column validation
-			if col.Pattern == "" {
+			// Simple column validation; derived, shuffled, nullified,
+			// constant, and distribution columns are validated on their own
+			if col.Pattern == "" && !col.IsDerivedColumn() &&
+				!col.IsShuffleColumn() && !col.IsFillColumn() &&
+				!col.IsDistributionColumn() {
 				errs = append(errs, fmt.Sprintf(
 					"column[%d]: pattern name is required", i))
 			}
@@ -270,12 +1254,122 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validStrategy returns true if s is a strategy, or empty for the default.
+func validStrategy(s string) bool {
+	switch s {
+	case "", StrategyCursor, StrategyKeyset, StrategyCopy:
+		return true
+	}
+	return false
+}
+
+// LockTimeoutSetting returns the lock timeout as a value for PostgreSQL's
+// lock_timeout parameter, in milliseconds, or an empty string if it is
+// not set. The configuration must have been validated.
+func (c *Config) LockTimeoutSetting() string {
+	d, err := time.ParseDuration(c.LockTimeout)
+	if err != nil || d <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dms", max(d.Milliseconds(), 1))
+}
+
+// StatementTimeoutSetting returns the statement timeout as a value for
+// PostgreSQL's statement_timeout parameter, in milliseconds, or an empty
+// string if it is not set. The configuration must have been validated.
+func (c *Config) StatementTimeoutSetting() string {
+	d, err := time.ParseDuration(c.StatementTimeout)
+	if err != nil || d <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dms", max(d.Milliseconds(), 1))
+}
+
+// TriggerMode returns how triggers are kept from firing during a run:
+// DisableTriggersTable, DisableTriggersReplica, or an empty string if
+// they are left to fire. A boolean may have been decoded as "1" or "0".
+func (c *Config) TriggerMode() string {
+	switch strings.ToLower(c.DisableTriggers) {
+	case "true", "1":
+		return DisableTriggersTable
+	case DisableTriggersReplica:
+		return DisableTriggersReplica
+	}
+	return ""
+}
+
+// BatchPause returns the time to pause after each batch, or zero if it is
+// not set. The configuration must have been validated.
+func (c *Config) BatchPause() time.Duration {
+	d, err := time.ParseDuration(c.SleepBetweenBatches)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// MaxRunDuration returns the longest a run may take, or zero if it is not
+// limited. The configuration must have been validated.
+func (c *Config) MaxRunDuration() time.Duration {
+	d, err := time.ParseDuration(c.MaxDuration)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// validateMaxDuration returns the problems with the run's duration limit
+// and checkpoint file.
+func (c *Config) validateMaxDuration() []string {
+	var errs []string
+	if c.MaxDuration != "" {
+		if d, err := time.ParseDuration(c.MaxDuration); err != nil || d <= 0 {
+			errs = append(errs, fmt.Sprintf(
+				"max_duration must be a positive duration such as '2h', got %q",
+				c.MaxDuration))
+		}
+		// A single transaction has nothing to keep when it is stopped
+		if c.TransactionMode == "" || c.TransactionMode == TransactionSingle {
+			errs = append(errs, "max_duration requires transaction_mode "+
+				"'per_table' or 'per_batch'")
+		}
+		if c.CheckpointFile == "" {
+			errs = append(errs, "max_duration requires a checkpoint_file "+
+				"to resume the run from")
+		}
+	}
+	if c.HasTargets() && (c.MaxDuration != "" || c.CheckpointFile != "") {
+		errs = append(errs,
+			"max_duration and checkpoint_file cannot be used with targets")
+	}
+	return errs
+}
+
+// usesStrategy returns true if strategy is the run's strategy or that of
+// any column.
+func (c *Config) usesStrategy(strategy string) bool {
+	if c.Strategy == strategy {
+		return true
+	}
+	for _, col := range c.Columns {
+		if col.Strategy == strategy {
+			return true
+		}
+	}
+	return false
+}
+
 // FindDefaultPatternsFile searches for the default patterns file in standard
-// locations.
+// locations, returning pattern.EmbeddedPath to use the patterns embedded
+// in the binary if none is found or configPath selects them.
 func FindDefaultPatternsFile(configPath string) string {
-	// Search order per DESIGN.md:
+	if configPath == pattern.EmbeddedPath {
+		return configPath
+	}
+
+	// Search order:
 	// 1. Path specified in config (configPath)
-	// 2. /etc/pgedge
+	// 2. User and system configuration directories (see ConfigDirs)
 	// 3. Directory containing the binary
 
 	searchPaths := []string{}
@@ -284,11 +1378,9 @@ func FindDefaultPatternsFile(configPath string) string {
 		searchPaths = append(searchPaths, configPath)
 	}
 
-	searchPaths = append(searchPaths, "/etc/pgedge/pgedge-anonymizer-patterns.yaml")
-
-	if exe, err := os.Executable(); err == nil {
+	for _, dir := range SearchDirs() {
 		searchPaths = append(searchPaths,
-			filepath.Join(filepath.Dir(exe), "pgedge-anonymizer-patterns.yaml"))
+			filepath.Join(dir, "pgedge-anonymizer-patterns.yaml"))
 	}
 
 	// Also check current directory
@@ -300,7 +1392,15 @@ func FindDefaultPatternsFile(configPath string) string {
 		}
 	}
 
-	return ""
+	return pattern.EmbeddedPath
+}
+
+// WithColumns returns a shallow copy of the configuration with the column
+// list replaced, e.g. after wildcard expansion.
+func (c *Config) WithColumns(columns []ColumnConfig) *Config {
+	cp := *c
+	cp.Columns = columns
+	return &cp
 }
 
 // GetColumnRefs converts ColumnConfig slice to ColumnRef slice.
@@ -315,3 +1415,37 @@ func (c *Config) GetColumnRefs() ([]errors.ColumnRef, error) {
 	}
 	return refs, nil
 }
+
+// ReferencedColumns returns the columns that are read, but not
+// anonymized, to anonymize others: profile identities, date shift
+// entities, shuffle groups, and partition columns. They are checked to
+// exist along with the anonymized columns.
+func (c *Config) ReferencedColumns() []errors.ColumnRef {
+	return slices.Concat(c.ProfileIdentities(), c.DateShiftEntities(),
+		c.ShuffleGroups(), c.PartitionColumns())
+}
+
+// sameTableColumns returns the distinct columns named by a setting of the
+// column configurations, which name other columns of their own tables.
+func (c *Config) sameTableColumns(
+	setting func(col ColumnConfig) string) []errors.ColumnRef {
+
+	var refs []errors.ColumnRef
+	seen := make(map[string]bool)
+	for _, col := range c.Columns {
+		name := setting(col)
+		if name == "" {
+			continue
+		}
+		ref, err := errors.ParseColumnRef(col.Column)
+		if err != nil {
+			continue
+		}
+		ref.Column = name
+		if !seen[ref.String()] {
+			seen[ref.String()] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
@@ -13,7 +13,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestDatabaseConfigConnectionString tests connection string generation
@@ -259,6 +261,315 @@ func TestConfigValidate(t *testing.T) {
 		}
 	})
 
+	t.Run("negative batch size", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL"},
+			},
+			FetchSize: -1,
+		}
+		err := cfg.Validate()
+		if err == nil {
+			t.Fatal("expected error for negative fetch size")
+		}
+		if !contains(err.Error(), "fetch_size must not be negative") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown oversized_values", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL"},
+			},
+			MaxValueBytes:   -1,
+			OversizedValues: "truncate",
+		}
+		err := cfg.Validate()
+		if err == nil {
+			t.Fatal("expected error for unknown oversized_values")
+		}
+		if !contains(err.Error(), "max_value_bytes must not be negative") ||
+			!contains(err.Error(), `oversized_values must be 'error' or 'skip', got "truncate"`) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL",
+					Strategy: StrategyKeyset, BatchSize: 500},
+				{Column: "public.users.notes", Pattern: "LOREMIPSUM",
+					Strategy: "bulk"},
+			},
+		}
+		err := cfg.Validate()
+		if err == nil {
+			t.Fatal("expected error for unknown strategy")
+		}
+		if !contains(err.Error(), "column[1]: strategy must be") ||
+			contains(err.Error(), "column[0]") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("sample percent", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL",
+					SamplePercent: 2.5},
+				{Column: "public.users.notes", Pattern: "LOREMIPSUM",
+					SamplePercent: 150},
+			},
+		}
+		err := cfg.Validate()
+		if err == nil {
+			t.Fatal("expected error for a sample over 100 percent")
+		}
+		if !contains(err.Error(), "column[1]: sample_percent must be") ||
+			contains(err.Error(), "column[0]") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("on collision", func(t *testing.T) {
+		retries, negative := 0, -1
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL",
+					OnCollision: CollisionSequence, CollisionRetries: &retries},
+				{Column: "public.users.login", Pattern: "USERNAME",
+					OnCollision: "skip"},
+				{Column: "public.users.code", Pattern: "MRN",
+					CollisionRetries: &negative},
+			},
+		}
+		err := cfg.Validate()
+		if err == nil {
+			t.Fatal("expected error for an unknown collision strategy")
+		}
+		if !contains(err.Error(), "column[1]: on_collision must be") ||
+			!contains(err.Error(), "column[2]: collision_retries must not") ||
+			contains(err.Error(), "column[0]") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("transaction mode", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL"},
+			},
+			TransactionMode: TransactionPerBatch,
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected valid config, got: %v", err)
+		}
+
+		// Per-batch commits would drop the copy strategy's staging table
+		cfg.Columns[0].Strategy = StrategyCopy
+		err := cfg.Validate()
+		if err == nil || !contains(err.Error(), "cannot be used with strategy 'copy'") {
+			t.Errorf("expected error for per_batch with copy, got: %v", err)
+		}
+
+		cfg.Columns[0].Strategy = ""
+		cfg.DropIndexes = true
+		err = cfg.Validate()
+		if err == nil || !contains(err.Error(), "cannot be used with drop_indexes") {
+			t.Errorf("expected error for per_batch with drop_indexes, got: %v", err)
+		}
+
+		cfg.DropIndexes = false
+		cfg.PropagateForeignKeys = true
+		err = cfg.Validate()
+		if err == nil || !contains(err.Error(), "cannot be used with propagate_foreign_keys") {
+			t.Errorf("expected error for per_batch with propagate_foreign_keys, got: %v", err)
+		}
+
+		cfg.PropagateForeignKeys = false
+		cfg.TransactionMode = "per_column"
+		err = cfg.Validate()
+		if err == nil || !contains(err.Error(), "transaction_mode must be") {
+			t.Errorf("expected error for unknown transaction mode, got: %v", err)
+		}
+	})
+
+	t.Run("invalid lock timeout", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL"},
+			},
+			LockTimeout: "30",
+		}
+		err := cfg.Validate()
+		if err == nil || !contains(err.Error(), "lock_timeout must be a positive duration") {
+			t.Errorf("expected error for lock timeout without a unit, got: %v", err)
+		}
+	})
+
+	t.Run("disable triggers", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL"},
+			},
+		}
+		for value, want := range map[string]string{
+			"":                         "",
+			"false":                    "",
+			"0":                        "",
+			"true":                     DisableTriggersTable,
+			"1":                        DisableTriggersTable,
+			"session_replication_role": DisableTriggersReplica,
+		} {
+			cfg.DisableTriggers = value
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("disable_triggers %q: unexpected error: %v", value, err)
+			}
+			if got := cfg.TriggerMode(); got != want {
+				t.Errorf("TriggerMode() for %q = %q, want %q", value, got, want)
+			}
+		}
+
+		cfg.DisableTriggers = "replica"
+		err := cfg.Validate()
+		if err == nil || !contains(err.Error(), "disable_triggers must be") {
+			t.Errorf("expected error for unknown trigger mode, got: %v", err)
+		}
+	})
+
+	t.Run("invalid throttle", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL"},
+			},
+			MaxRowsPerSecond:    -1,
+			SleepBetweenBatches: "fast",
+		}
+		err := cfg.Validate()
+		if err == nil || !contains(err.Error(), "max_rows_per_second must not be negative") ||
+			!contains(err.Error(), "sleep_between_batches must be a duration") {
+			t.Errorf("expected errors for the throttle settings, got: %v", err)
+		}
+	})
+
+	t.Run("max duration", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL"},
+			},
+			MaxDuration:     "2h",
+			CheckpointFile:  "run.checkpoint",
+			TransactionMode: TransactionPerTable,
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected valid config, got: %v", err)
+		}
+		if cfg.MaxRunDuration() != 2*time.Hour {
+			t.Errorf("unexpected max duration: %v", cfg.MaxRunDuration())
+		}
+
+		// Nothing would be kept of a single transaction
+		cfg.TransactionMode = ""
+		cfg.CheckpointFile = ""
+		cfg.MaxDuration = "2"
+		err := cfg.Validate()
+		if err == nil || !contains(err.Error(), "max_duration must be a positive duration") ||
+			!contains(err.Error(), "requires transaction_mode") ||
+			!contains(err.Error(), "requires a checkpoint_file") {
+			t.Errorf("expected errors for the max duration, got: %v", err)
+		}
+	})
+
+	t.Run("dictionary scope", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL"},
+			},
+		}
+		for _, scope := range []string{DictionaryScopeGlobal,
+			DictionaryScopePattern, DictionaryScopeColumn} {
+			cfg.DictionaryScope = scope
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("%s: expected valid config, got: %v", scope, err)
+			}
+		}
+
+		cfg.DictionaryScope = "table"
+		err := cfg.Validate()
+		if err == nil || !contains(err.Error(), "dictionary_scope must be") {
+			t.Errorf("expected error for unknown dictionary scope, got: %v", err)
+		}
+	})
+
+	t.Run("sanitize", func(t *testing.T) {
+		cfg := Config{
+			Database: DatabaseConfig{
+				Database: "mydb",
+				User:     "myuser",
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL"},
+			},
+			Sanitize: []string{"nfc", "ascii", "strip_invalid"},
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected valid config, got: %v", err)
+		}
+
+		cfg.Sanitize = []string{"nfc", "latin1", "nfc"}
+		err := cfg.Validate()
+		if err == nil || !contains(err.Error(), `unknown sanitizer "latin1"`) ||
+			!contains(err.Error(), `"nfc" is listed more than once`) {
+			t.Errorf("expected errors for the sanitizers, got: %v", err)
+		}
+	})
+
 	t.Run("env vars provide database and user", func(t *testing.T) {
 		os.Setenv("PGDATABASE", "envdb")
 		os.Setenv("PGUSER", "envuser")
@@ -299,6 +610,14 @@ func TestConfigApplyOverrides(t *testing.T) {
 	defaultPatterns := "/new/default"
 	userPatterns := "/new/user"
 	disableDefaults := true
+	batchSize := 5000
+	fetchSize := 1000
+	updateChunkSize := 500
+	lockTimeout := "30s"
+	maxRowsPerSecond := 20000
+	sleepBetweenBatches := "250ms"
+	maxDuration := "90m"
+	analyzeColumns := true
 
 	overrides := CLIOverrides{
 		Host:            &host,
@@ -309,6 +628,17 @@ func TestConfigApplyOverrides(t *testing.T) {
 		DefaultPatterns: &defaultPatterns,
 		UserPatterns:    &userPatterns,
 		DisableDefaults: &disableDefaults,
+		BatchSize:       &batchSize,
+		FetchSize:       &fetchSize,
+		UpdateChunkSize: &updateChunkSize,
+		LockTimeout:     &lockTimeout,
+
+		MaxRowsPerSecond:    &maxRowsPerSecond,
+		SleepBetweenBatches: &sleepBetweenBatches,
+
+		MaxDuration: &maxDuration,
+
+		AnalyzeColumns: &analyzeColumns,
 	}
 
 	cfg.ApplyOverrides(overrides)
@@ -338,6 +668,25 @@ func TestConfigApplyOverrides(t *testing.T) {
 	if !cfg.Patterns.DisableDefaults {
 		t.Error("disable defaults not overridden")
 	}
+	if cfg.BatchSize != 5000 || cfg.FetchSize != 1000 ||
+		cfg.UpdateChunkSize != 500 {
+		t.Errorf("batch sizes not overridden: %d, %d, %d",
+			cfg.BatchSize, cfg.FetchSize, cfg.UpdateChunkSize)
+	}
+	if cfg.LockTimeout != "30s" || cfg.LockTimeoutSetting() != "30000ms" {
+		t.Errorf("lock timeout not overridden: %s", cfg.LockTimeout)
+	}
+	if cfg.MaxRowsPerSecond != 20000 ||
+		cfg.BatchPause() != 250*time.Millisecond {
+		t.Errorf("throttle not overridden: %d, %s", cfg.MaxRowsPerSecond,
+			cfg.SleepBetweenBatches)
+	}
+	if cfg.MaxRunDuration() != 90*time.Minute {
+		t.Errorf("max duration not overridden: %s", cfg.MaxDuration)
+	}
+	if !cfg.AnalyzeColumns {
+		t.Error("analyze columns not overridden")
+	}
 }
 
 // TestConfigLoad tests loading configuration from a file
@@ -567,6 +916,43 @@ func TestJSONColumnValidation(t *testing.T) {
 		}
 	})
 
+	t.Run("JSON path on_missing", func(t *testing.T) {
+		for _, value := range []string{"", "warn", "error", "ignore"} {
+			cfg := Config{
+				Columns: []ColumnConfig{
+					{
+						Column: "public.users.profile",
+						JSONPaths: []JSONPathConfig{
+							{Path: "$.email", Pattern: "EMAIL", OnMissing: value},
+						},
+					},
+				},
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("on_missing %q: expected valid config, got: %v",
+					value, err)
+			}
+		}
+
+		cfg := Config{
+			Columns: []ColumnConfig{
+				{
+					Column: "public.users.profile",
+					JSONPaths: []JSONPathConfig{
+						{Path: "$.email", Pattern: "EMAIL", OnMissing: "fail"},
+					},
+				},
+			},
+		}
+		err := cfg.Validate()
+		if err == nil {
+			t.Fatal("expected error for invalid on_missing")
+		}
+		if !contains(err.Error(), "on_missing must be one of") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
 	t.Run("JSON path with array wildcard", func(t *testing.T) {
 		cfg := Config{
 			Columns: []ColumnConfig{
@@ -582,6 +968,40 @@ func TestJSONColumnValidation(t *testing.T) {
 			t.Errorf("expected valid config with array wildcard, got: %v", err)
 		}
 	})
+
+	t.Run("treat_as_json", func(t *testing.T) {
+		cfg := Config{
+			Columns: []ColumnConfig{
+				{
+					Column:      "public.users.profile",
+					TreatAsJSON: true,
+					JSONPaths: []JSONPathConfig{
+						{Path: "$.email", Pattern: "EMAIL"},
+					},
+				},
+			},
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected valid config, got: %v", err)
+		}
+
+		cfg = Config{
+			Columns: []ColumnConfig{
+				{
+					Column:      "public.users.email",
+					Pattern:     "EMAIL",
+					TreatAsJSON: true,
+				},
+			},
+		}
+		err := cfg.Validate()
+		if err == nil {
+			t.Fatal("expected error for treat_as_json without json_paths")
+		}
+		if !contains(err.Error(), "treat_as_json requires") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
 }
 
 // TestIsJSONColumn tests the IsJSONColumn helper method
@@ -620,6 +1040,217 @@ func TestIsJSONColumn(t *testing.T) {
 	})
 }
 
+// TestHasWildcard tests the HasWildcard helper method
+func TestHasWildcard(t *testing.T) {
+	tests := []struct {
+		column string
+		want   bool
+	}{
+		{"public.users.email", false},
+		{"public.users.*", true},
+		{"public.*.email", true},
+		{"*.users.email", true},
+		{"public.users.email_*", true},
+	}
+
+	for _, tt := range tests {
+		col := ColumnConfig{Column: tt.column, Pattern: "EMAIL"}
+		if got := col.HasWildcard(); got != tt.want {
+			t.Errorf("HasWildcard(%q) = %v, want %v", tt.column, got, tt.want)
+		}
+	}
+}
+
+// TestXMLColumnValidation tests validation of xml_paths entries
+func TestXMLColumnValidation(t *testing.T) {
+	// Clear env vars that might affect validation
+	origDB := os.Getenv("PGDATABASE")
+	origPGUser := os.Getenv("PGUSER")
+	defer func() {
+		os.Setenv("PGDATABASE", origDB)
+		os.Setenv("PGUSER", origPGUser)
+	}()
+	os.Setenv("PGDATABASE", "testdb")
+	os.Setenv("PGUSER", "testuser")
+
+	tests := []struct {
+		name    string
+		column  ColumnConfig
+		wantErr string
+	}{
+		{
+			name: "valid XML column config",
+			column: ColumnConfig{
+				Column: "public.orders.document",
+				XMLPaths: []XMLPathConfig{
+					{Path: "//customer/email", Pattern: "EMAIL"},
+					{Path: "//customer/@phone", Pattern: "US_PHONE"},
+				},
+			},
+		},
+		{
+			name: "pattern and xml_paths",
+			column: ColumnConfig{
+				Column:   "public.orders.document",
+				Pattern:  "EMAIL",
+				XMLPaths: []XMLPathConfig{{Path: "//email", Pattern: "EMAIL"}},
+			},
+			wantErr: "cannot specify both 'pattern' and 'xml_paths'",
+		},
+		{
+			name: "json_paths and xml_paths",
+			column: ColumnConfig{
+				Column:    "public.orders.document",
+				JSONPaths: []JSONPathConfig{{Path: "$.email", Pattern: "EMAIL"}},
+				XMLPaths:  []XMLPathConfig{{Path: "//email", Pattern: "EMAIL"}},
+			},
+			wantErr: "cannot specify both 'json_paths' and 'xml_paths'",
+		},
+		{
+			name: "missing path",
+			column: ColumnConfig{
+				Column:   "public.orders.document",
+				XMLPaths: []XMLPathConfig{{Path: "", Pattern: "EMAIL"}},
+			},
+			wantErr: "path is required",
+		},
+		{
+			name: "invalid XPath",
+			column: ColumnConfig{
+				Column:   "public.orders.document",
+				XMLPaths: []XMLPathConfig{{Path: "//email[", Pattern: "EMAIL"}},
+			},
+			wantErr: "invalid XPath",
+		},
+		{
+			name: "missing pattern",
+			column: ColumnConfig{
+				Column:   "public.orders.document",
+				XMLPaths: []XMLPathConfig{{Path: "//email", Pattern: ""}},
+			},
+			wantErr: "pattern is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Columns: []ColumnConfig{tt.column}}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.wantErr)
+			}
+			if !contains(err.Error(), tt.wantErr) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestCompositeColumnValidation tests validation of composite fields
+func TestCompositeColumnValidation(t *testing.T) {
+	// Clear env vars that might affect validation
+	origDB := os.Getenv("PGDATABASE")
+	origPGUser := os.Getenv("PGUSER")
+	defer func() {
+		os.Setenv("PGDATABASE", origDB)
+		os.Setenv("PGUSER", origPGUser)
+	}()
+	os.Setenv("PGDATABASE", "testdb")
+	os.Setenv("PGUSER", "testuser")
+
+	tests := []struct {
+		name    string
+		column  ColumnConfig
+		wantErr string
+	}{
+		{
+			name: "valid composite column config",
+			column: ColumnConfig{
+				Column: "public.customers.address",
+				Fields: map[string]string{"street": "ADDRESS", "city": "CITY"},
+			},
+		},
+		{
+			name: "pattern and fields",
+			column: ColumnConfig{
+				Column:  "public.customers.address",
+				Pattern: "ADDRESS",
+				Fields:  map[string]string{"street": "ADDRESS"},
+			},
+			wantErr: "cannot specify both 'pattern' and 'fields'",
+		},
+		{
+			name: "json_paths and fields",
+			column: ColumnConfig{
+				Column:    "public.customers.address",
+				JSONPaths: []JSONPathConfig{{Path: "$.email", Pattern: "EMAIL"}},
+				Fields:    map[string]string{"street": "ADDRESS"},
+			},
+			wantErr: "cannot specify both 'json_paths' and 'fields'",
+		},
+		{
+			name: "missing field pattern",
+			column: ColumnConfig{
+				Column: "public.customers.address",
+				Fields: map[string]string{"street": ""},
+			},
+			wantErr: "fields.street: pattern is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Columns: []ColumnConfig{tt.column}}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.wantErr)
+			}
+			if !contains(err.Error(), tt.wantErr) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestPatternNames tests the PatternNames helper method
+func TestPatternNames(t *testing.T) {
+	col := ColumnConfig{
+		Column:    "public.users.profile",
+		JSONPaths: []JSONPathConfig{{Path: "$.email", Pattern: "EMAIL"}},
+		XMLPaths:  []XMLPathConfig{{Path: "//phone", Pattern: "US_PHONE"}},
+	}
+	got := col.PatternNames()
+	if len(got) != 2 || got[0] != "EMAIL" || got[1] != "US_PHONE" {
+		t.Errorf("PatternNames() = %v, want [EMAIL US_PHONE]", got)
+	}
+
+	composite := ColumnConfig{
+		Column: "public.customers.address",
+		Fields: map[string]string{"street": "ADDRESS", "city": "CITY"},
+	}
+	got = composite.PatternNames()
+	if len(got) != 2 || got[0] != "CITY" || got[1] != "ADDRESS" {
+		t.Errorf("PatternNames() = %v, want [CITY ADDRESS]", got)
+	}
+
+	simple := ColumnConfig{Column: "public.users.email", Pattern: "EMAIL"}
+	if got := simple.PatternNames(); len(got) != 1 || got[0] != "EMAIL" {
+		t.Errorf("PatternNames() = %v, want [EMAIL]", got)
+	}
+}
+
 // helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr ||
@@ -634,3 +1265,267 @@ func containsLoop(s, substr string) bool {
 	}
 	return false
 }
+
+// TestTargets tests resolving and validating target databases
+func TestTargets(t *testing.T) {
+	content := `
+database:
+  host: cluster1
+  port: 5433
+  user: admin
+  sslmode: require
+
+targets:
+  - database: tenant_a
+  - name: tenant_b_eu
+    database: tenant_b
+    host: cluster2
+
+target_dictionary: shared
+
+columns:
+  - column: public.users.email
+    pattern: EMAIL
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	if cfg.TargetDictionary != TargetDictionaryShared {
+		t.Errorf("unexpected target_dictionary: %q", cfg.TargetDictionary)
+	}
+
+	targets := cfg.ResolveTargets()
+	if len(targets) != 2 {
+		t.Fatalf("expected 2 targets, got %d", len(targets))
+	}
+	want := []Target{
+		{Name: "tenant_a", Database: DatabaseConfig{Host: "cluster1",
+			Port: 5433, Database: "tenant_a", User: "admin",
+			SSLMode: "require"}},
+		{Name: "tenant_b_eu", Database: DatabaseConfig{Host: "cluster2",
+			Port: 5433, Database: "tenant_b", User: "admin",
+			SSLMode: "require"}},
+	}
+	for i := range want {
+		if targets[i] != want[i] {
+			t.Errorf("target %d = %+v, want %+v", i, targets[i], want[i])
+		}
+	}
+
+	single := cfg.ForTarget(targets[1])
+	if single.HasTargets() || single.Database.Host != "cluster2" {
+		t.Errorf("unexpected target config: %+v", single.Database)
+	}
+
+	t.Run("no targets", func(t *testing.T) {
+		cfg := &Config{Database: DatabaseConfig{Database: "db"}}
+		targets := cfg.ResolveTargets()
+		if len(targets) != 1 || targets[0].Name != "db" {
+			t.Errorf("unexpected targets: %+v", targets)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		columns := []ColumnConfig{{Column: "public.t.c", Pattern: "EMAIL"}}
+		tests := []struct {
+			name    string
+			cfg     Config
+			wantErr bool
+		}{
+			{"database section needs no name", Config{
+				Database: DatabaseConfig{User: "u"},
+				Targets: []TargetConfig{
+					{DatabaseConfig: DatabaseConfig{Database: "a"}},
+				},
+			}, false},
+			{"missing database name", Config{
+				Database: DatabaseConfig{User: "u"},
+				Targets:  []TargetConfig{{Name: "a"}},
+			}, true},
+			{"duplicate names", Config{
+				Database: DatabaseConfig{User: "u"},
+				Targets: []TargetConfig{
+					{DatabaseConfig: DatabaseConfig{Database: "a"}},
+					{DatabaseConfig: DatabaseConfig{Database: "a",
+						Host: "other"}},
+				},
+			}, true},
+			{"invalid dictionary mode", Config{
+				Database:         DatabaseConfig{Database: "a", User: "u"},
+				TargetDictionary: "global",
+			}, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.cfg.Columns = columns
+				err := tt.cfg.Validate()
+				if tt.wantErr && err == nil {
+					t.Error("expected validation error")
+				}
+				if !tt.wantErr && err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			})
+		}
+	})
+}
+
+// TestHooksConfig tests loading and validating hook commands
+func TestHooksConfig(t *testing.T) {
+	content := `
+database:
+  database: testdb
+  user: testuser
+
+hooks:
+  after_column:
+    - exec: ./check-counts.sh
+      args: ["--strict"]
+  before_batch:
+    - exec: ""
+
+columns:
+  - column: public.users.email
+    pattern: EMAIL
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if len(cfg.Hooks.AfterColumn) != 1 ||
+		cfg.Hooks.AfterColumn[0].Exec != "./check-counts.sh" ||
+		len(cfg.Hooks.AfterColumn[0].Args) != 1 {
+		t.Errorf("unexpected after_column hooks: %+v", cfg.Hooks.AfterColumn)
+	}
+
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(),
+		"hooks.before_batch[0]: exec is required") {
+		t.Errorf("expected missing exec error, got %v", err)
+	}
+}
+
+// TestAuditConfig tests audit configuration validation
+func TestAuditConfig(t *testing.T) {
+	cfg := Config{
+		Database: DatabaseConfig{Database: "db", User: "u"},
+		Columns:  []ColumnConfig{{Column: "public.t.c", Pattern: "EMAIL"}},
+	}
+	if cfg.Audit.Enabled() {
+		t.Error("audit should be disabled by default")
+	}
+
+	cfg.Audit.Table = "audit.runs"
+	if !cfg.Audit.Enabled() {
+		t.Error("audit should be enabled with a table")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Audit.Table = "db.audit.runs"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for three-part table name")
+	}
+}
+
+func TestVerifyConfig(t *testing.T) {
+	base := Config{
+		Database: DatabaseConfig{Database: "db", User: "u"},
+		Columns:  []ColumnConfig{{Column: "public.t.c", Pattern: "EMAIL"}},
+	}
+
+	tests := []struct {
+		name    string
+		verify  VerifyConfig
+		wantErr bool
+	}{
+		{"empty", VerifyConfig{}, false},
+		{"valid", VerifyConfig{Fingerprints: "run.fp", Sample: 1000,
+			MaxMatchRate: 0.01, PIIPatterns: []PIIPattern{
+				{Name: "staff email", Regex: `@acme\.com$`}}}, false},
+		{"negative sample", VerifyConfig{Sample: -1}, true},
+		{"match rate too high", VerifyConfig{MaxMatchRate: 1.5}, true},
+		{"missing name", VerifyConfig{PIIPatterns: []PIIPattern{
+			{Regex: "x"}}}, true},
+		{"invalid regex", VerifyConfig{PIIPatterns: []PIIPattern{
+			{Name: "bad", Regex: "("}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			cfg.Verify = tt.verify
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSessionSettings(t *testing.T) {
+	content := `
+database:
+  database: app
+  user: admin
+
+session_settings:
+  work_mem: 256MB
+  synchronous_commit: off
+  maintenance_work_mem: 1048576
+  auto_explain.log_analyze: on
+
+columns:
+  - column: public.users.email
+    pattern: EMAIL
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	names := cfg.SessionSettingNames()
+	want := []string{"auto_explain.log_analyze", "maintenance_work_mem",
+		"synchronous_commit", "work_mem"}
+	if len(names) != len(want) {
+		t.Fatalf("SessionSettingNames() = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("SessionSettingNames() = %v, want %v", names, want)
+		}
+	}
+	if cfg.SessionSettings["synchronous_commit"] != "off" ||
+		cfg.SessionSettings["maintenance_work_mem"] != "1048576" {
+		t.Errorf("unexpected settings: %v", cfg.SessionSettings)
+	}
+
+	cfg.SessionSettings["work_mem; DROP TABLE users"] = "1"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for invalid parameter name")
+	}
+}
@@ -0,0 +1,233 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of the connection pool and of retrying connections.
+const (
+	DefaultConnectTimeout        = 10 * time.Second
+	DefaultConnectRetries        = 3
+	DefaultRetryBackoff          = time.Second
+	DefaultMaxConnections        = 10
+	DefaultMaxConnectionLifetime = 5 * time.Minute
+)
+
+// durationOr returns a duration setting, or def if it is not set. The
+// configuration must have been validated.
+func durationOr(value string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// ConnectTimeoutDuration returns the longest each attempt to connect may
+// take.
+func (d *DatabaseConfig) ConnectTimeoutDuration() time.Duration {
+	return durationOr(d.ConnectTimeout, DefaultConnectTimeout)
+}
+
+// Retries returns how many more times to attempt after a transient
+// error.
+func (d *DatabaseConfig) Retries() int {
+	if d.ConnectRetries == nil {
+		return DefaultConnectRetries
+	}
+	return *d.ConnectRetries
+}
+
+// RetryBackoffDuration returns how long to wait before the first retry of
+// a connection.
+func (d *DatabaseConfig) RetryBackoffDuration() time.Duration {
+	return durationOr(d.RetryBackoff, DefaultRetryBackoff)
+}
+
+// PoolSize returns the most connections the pool may open, and the
+// longest each may be used for.
+func (d *DatabaseConfig) PoolSize() (int, time.Duration) {
+	conns := d.MaxConnections
+	if conns <= 0 {
+		conns = DefaultMaxConnections
+	}
+	return conns, durationOr(d.MaxConnectionLifetime,
+		DefaultMaxConnectionLifetime)
+}
+
+// explicitSettings returns the connection parameters set in the
+// configuration, as libpq keywords and values, leaving those not set to
+// the connection URI, the service file, or the environment.
+func (d *DatabaseConfig) explicitSettings() [][2]string {
+	var settings [][2]string
+	add := func(keyword, value string) {
+		if value != "" {
+			settings = append(settings, [2]string{keyword, value})
+		}
+	}
+	add("host", d.Host)
+	if d.Port != 0 {
+		add("port", strconv.Itoa(d.Port))
+	}
+	add("dbname", d.Database)
+	add("user", d.User)
+	add("password", d.Password)
+	add("sslmode", d.SSLMode)
+	add("sslcert", d.SSLCert)
+	add("sslkey", d.SSLKey)
+	add("sslrootcert", d.SSLRootCert)
+	add("passfile", d.PassFile)
+	return settings
+}
+
+// usesService reports whether the connection parameters are looked up in
+// a libpq service file, named by the configuration or PGSERVICE.
+func (d *DatabaseConfig) usesService() bool {
+	return d.Service != "" || os.Getenv("PGSERVICE") != ""
+}
+
+// urlConnectionString returns the connection URI with the parameters set
+// in the configuration added to its query, where they take precedence
+// over those of the URI itself.
+func (d *DatabaseConfig) urlConnectionString() string {
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		// Validate reports the error; the driver reports it otherwise
+		return d.URL
+	}
+
+	query := u.Query()
+	if d.Service != "" {
+		query.Set("service", d.Service)
+	}
+	for _, s := range d.explicitSettings() {
+		query.Set(s[0], s[1])
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// serviceConnectionString returns a connection string naming the service,
+// with only the parameters set in the configuration, so that the others
+// are taken from the service file, and then the environment.
+func (d *DatabaseConfig) serviceConnectionString() string {
+	var parts []string
+	if d.Service != "" {
+		parts = append(parts, "service="+quoteConnValue(d.Service))
+	}
+	for _, s := range d.explicitSettings() {
+		parts = append(parts, s[0]+"="+quoteConnValue(s[1]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// DatabaseName returns the name of the database the parameters connect
+// to, as far as the configuration tells: the database setting, the
+// database of the connection URI, or the name of the service.
+func (d *DatabaseConfig) DatabaseName() string {
+	if d.Database != "" {
+		return d.Database
+	}
+	if d.URL != "" {
+		if u, err := url.Parse(d.URL); err == nil {
+			if name := u.Query().Get("dbname"); name != "" {
+				return name
+			}
+			if name := strings.TrimPrefix(u.Path, "/"); name != "" {
+				return name
+			}
+		}
+	}
+	return d.Service
+}
+
+// RedactURL returns a connection URI with any password removed, from its
+// user information or its query, so that it can be written or logged.
+func RedactURL(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	if u.User != nil {
+		u.User = url.User(u.User.Username())
+	}
+	query := u.Query()
+	if query.Has("password") {
+		query.Del("password")
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// validateConnection returns the problems with the connection URI, pool,
+// and retry settings of a database section.
+func (d *DatabaseConfig) validateConnection(section string) []string {
+	var errs []string
+
+	if d.URL != "" {
+		u, err := url.Parse(d.URL)
+		switch {
+		case err != nil:
+			// The error quotes the URI, which may hold a password
+			errs = append(errs, fmt.Sprintf("%s.url is not a valid URI",
+				section))
+		case u.Scheme != "postgres" && u.Scheme != "postgresql":
+			errs = append(errs, fmt.Sprintf(
+				"%s.url must begin with postgres:// or postgresql://", section))
+		}
+	}
+
+	for _, setting := range []struct{ name, value string }{
+		{"connect_timeout", d.ConnectTimeout},
+		{"retry_backoff", d.RetryBackoff},
+		{"max_connection_lifetime", d.MaxConnectionLifetime},
+	} {
+		if setting.value == "" {
+			continue
+		}
+		if v, err := time.ParseDuration(setting.value); err != nil || v <= 0 {
+			errs = append(errs, fmt.Sprintf(
+				"%s.%s must be a positive duration such as '10s', got %q",
+				section, setting.name, setting.value))
+		}
+	}
+	if d.ConnectRetries != nil && *d.ConnectRetries < 0 {
+		errs = append(errs, fmt.Sprintf(
+			"%s.connect_retries must not be negative", section))
+	}
+	if d.MaxConnections < 0 {
+		errs = append(errs, fmt.Sprintf(
+			"%s.max_connections must not be negative", section))
+	}
+
+	return errs
+}
+
+// validateConnections returns the problems with the connection settings.
+func (c *Config) validateConnections() []string {
+	errs := c.Database.validateConnection("database")
+	for i, t := range c.Targets {
+		errs = append(errs, t.DatabaseConfig.validateConnection(
+			fmt.Sprintf("targets[%d]", i))...)
+	}
+	if c.CopyTo != nil {
+		errs = append(errs, c.CopyTo.DatabaseConfig.validateConnection(
+			"copy_to")...)
+	}
+	return errs
+}
@@ -0,0 +1,49 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+)
+
+// validateConsistencyGroups returns the problems with the consistency
+// groups. A group's columns must be anonymized with a single pattern, and
+// all with the same one, as the first column to map a value decides its
+// replacement in the others.
+func (c *Config) validateConsistencyGroups() []string {
+	var errs []string
+
+	patterns := make(map[string]string)
+	for i, col := range c.Columns {
+		if col.ConsistencyGroup == "" {
+			continue
+		}
+
+		if col.Pattern == "" || col.IsJSONColumn() || col.IsXMLColumn() ||
+			col.IsCompositeColumn() {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: consistency_group requires a column anonymized "+
+					"with a single 'pattern'", i))
+			continue
+		}
+
+		pattern, ok := patterns[col.ConsistencyGroup]
+		if !ok {
+			patterns[col.ConsistencyGroup] = col.Pattern
+		} else if pattern != col.Pattern {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: consistency group %q uses pattern %s, not %s", i,
+				col.ConsistencyGroup, pattern, col.Pattern))
+		}
+	}
+
+	return errs
+}
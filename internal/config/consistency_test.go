@@ -0,0 +1,68 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestConsistencyGroupValidation tests the validation of consistency
+// groups
+func TestConsistencyGroupValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		column ColumnConfig
+		errMsg string
+	}{
+		{
+			name: "same pattern",
+			column: ColumnConfig{Column: "public.contacts.email",
+				Pattern: "EMAIL", ConsistencyGroup: "email"},
+		},
+		{
+			name: "different pattern",
+			column: ColumnConfig{Column: "public.contacts.email",
+				Pattern: "PERSON_NAME", ConsistencyGroup: "email"},
+			errMsg: `consistency group "email" uses pattern EMAIL, not PERSON_NAME`,
+		},
+		{
+			name: "json column",
+			column: ColumnConfig{Column: "public.contacts.data",
+				JSONPaths: []JSONPathConfig{{Path: "$.email",
+					Pattern: "EMAIL"}},
+				ConsistencyGroup: "email"},
+			errMsg: "consistency_group requires a column anonymized with " +
+				"a single 'pattern'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{
+					{Column: "public.users.email", Pattern: "EMAIL",
+						ConsistencyGroup: "email"},
+					tt.column,
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+// CopyConfig is the database that copy mode writes anonymized rows to.
+// In copy mode, the database section is only read: the rows of each table
+// with columns to anonymize are copied to the table of the same name in
+// this database, and anonymized there, in the same transaction. Connection
+// parameters that are not set are taken from the database section.
+type CopyConfig struct {
+	DatabaseConfig `yaml:",inline" mapstructure:",squash"`
+
+	// Truncate empties the tables copied to before copying; otherwise
+	// they must be empty.
+	Truncate bool `yaml:"truncate,omitempty" mapstructure:"truncate"`
+}
+
+// IsCopyMode reports whether anonymized rows are copied to another
+// database, leaving the database section untouched.
+func (c *Config) IsCopyMode() bool {
+	return c.CopyTo != nil
+}
+
+// CopyTarget returns the connection parameters of the database copied
+// to, with unset ones taken from the database section.
+func (c *Config) CopyTarget() DatabaseConfig {
+	return c.CopyTo.DatabaseConfig.mergeDefaults(c.Database)
+}
+
+// ForCopy returns a shallow copy of the configuration that anonymizes the
+// database copied to, for the checks, audit records, and other output of
+// a run in copy mode, which describe the database written to.
+func (c *Config) ForCopy() *Config {
+	cp := *c
+	cp.Database = c.CopyTarget()
+	return &cp
+}
+
+// validateCopy returns the problems with copy mode.
+func (c *Config) validateCopy() []string {
+	if !c.IsCopyMode() {
+		return nil
+	}
+
+	var errs []string
+	if c.HasTargets() {
+		errs = append(errs, "copy_to cannot be used with targets")
+	}
+	if c.TransactionMode == TransactionPerBatch {
+		errs = append(errs, "copy_to cannot be used with transaction_mode "+
+			"'per_batch', which would commit rows copied before they are "+
+			"anonymized")
+	}
+	target := c.CopyTarget()
+	if target.URL == c.Database.URL && target.Service == c.Database.Service &&
+		target.Host == c.Database.Host && target.Port == c.Database.Port &&
+		target.Database == c.Database.Database {
+		errs = append(errs, "copy_to must name a database other than "+
+			"the one in the database section")
+	}
+	return errs
+}
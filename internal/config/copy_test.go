@@ -0,0 +1,95 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestForCopy tests resolving the database copied to
+func TestForCopy(t *testing.T) {
+	cfg := &Config{
+		Database: DatabaseConfig{Host: "prod", Port: 5432, Database: "app",
+			User: "reader", SSLMode: "require"},
+		CopyTo: &CopyConfig{DatabaseConfig: DatabaseConfig{Host: "staging",
+			User: "writer"}},
+	}
+
+	cp := cfg.ForCopy()
+	want := DatabaseConfig{Host: "staging", Port: 5432, Database: "app",
+		User: "writer", SSLMode: "require"}
+	if cp.Database != want {
+		t.Errorf("got %+v, want %+v", cp.Database, want)
+	}
+	if cfg.Database.Host != "prod" {
+		t.Error("expected the original configuration to be unchanged")
+	}
+}
+
+// TestCopyValidation tests the validation of copy mode
+func TestCopyValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		copyTo  *CopyConfig
+		targets []TargetConfig
+		mode    string
+		errMsg  string
+	}{
+		{
+			name:   "other database",
+			copyTo: &CopyConfig{DatabaseConfig: DatabaseConfig{Database: "copy"}},
+		},
+		{
+			name:   "other host",
+			copyTo: &CopyConfig{DatabaseConfig: DatabaseConfig{Host: "staging"}},
+		},
+		{
+			name:   "same database",
+			copyTo: &CopyConfig{Truncate: true},
+			errMsg: "copy_to must name a database other than",
+		},
+		{
+			name:   "per batch",
+			copyTo: &CopyConfig{DatabaseConfig: DatabaseConfig{Database: "copy"}},
+			mode:   TransactionPerBatch,
+			errMsg: "would commit rows copied before they are anonymized",
+		},
+		{
+			name:    "targets",
+			copyTo:  &CopyConfig{DatabaseConfig: DatabaseConfig{Database: "copy"}},
+			targets: []TargetConfig{{DatabaseConfig: DatabaseConfig{Database: "a"}}},
+			errMsg:  "copy_to cannot be used with targets",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{
+					{Column: "public.users.email", Pattern: "EMAIL"},
+				},
+				CopyTo:          tt.copyTo,
+				Targets:         tt.targets,
+				TransactionMode: tt.mode,
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// PasswordCommand is an external command printing the database password,
+// such as a secrets manager's client.
+type PasswordCommand struct {
+	Exec string   `yaml:"exec" mapstructure:"exec"`
+	Args []string `yaml:"args,omitempty" mapstructure:"args"`
+}
+
+// passwordCommandTimeout is the longest a password command may run.
+const passwordCommandTimeout = 30 * time.Second
+
+// envTemplateRE matches a ${NAME} reference to an environment variable.
+var envTemplateRE = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces each ${NAME} in a value with the environment
+// variable's value. A variable that is not set is an error, rather than
+// an empty value, so that a missing secret is not taken for an empty one.
+func expandEnv(value string) (string, error) {
+	var missing []string
+	expanded := envTemplateRE.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envTemplateRE.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set",
+			strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// expandEnv replaces the ${NAME} references to environment variables in
+// the connection parameters.
+func (d *DatabaseConfig) expandEnv() error {
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"url", &d.URL},
+		{"service", &d.Service},
+		{"host", &d.Host},
+		{"database", &d.Database},
+		{"user", &d.User},
+		{"password", &d.Password},
+		{"password_file", &d.PasswordFile},
+		{"passfile", &d.PassFile},
+		{"sslmode", &d.SSLMode},
+		{"sslcert", &d.SSLCert},
+		{"sslkey", &d.SSLKey},
+		{"sslrootcert", &d.SSLRootCert},
+	} {
+		expanded, err := expandEnv(*field.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.name, err)
+		}
+		*field.value = expanded
+	}
+	return nil
+}
+
+// ExpandEnv replaces the ${NAME} references to environment variables in
+// the connection parameters of the database section, the targets, and
+// the database copied to.
+func (c *Config) ExpandEnv() error {
+	if err := c.Database.expandEnv(); err != nil {
+		return fmt.Errorf("database.%w", err)
+	}
+	for i := range c.Targets {
+		if err := c.Targets[i].DatabaseConfig.expandEnv(); err != nil {
+			return fmt.Errorf("targets[%d].%w", i, err)
+		}
+	}
+	if c.CopyTo != nil {
+		if err := c.CopyTo.DatabaseConfig.expandEnv(); err != nil {
+			return fmt.Errorf("copy_to.%w", err)
+		}
+	}
+	return nil
+}
+
+// ResolvePassword sets the password, if it is not set, from the password
+// file or the output of the password command. Otherwise it is left to
+// PGPASSWORD, or the passfile, as libpq would.
+func (d *DatabaseConfig) ResolvePassword(ctx context.Context) error {
+	switch {
+	case d.Password != "":
+	case d.PasswordFile != "":
+		data, err := os.ReadFile(d.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read password file: %w", err)
+		}
+		d.Password = strings.TrimRight(string(data), "\r\n")
+	case d.PasswordCommand != nil:
+		ctx, cancel := context.WithTimeout(ctx, passwordCommandTimeout)
+		defer cancel()
+
+		var stdout bytes.Buffer
+		cmd := exec.CommandContext(ctx, d.PasswordCommand.Exec,
+			d.PasswordCommand.Args...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("password command %s failed: %w",
+				d.PasswordCommand.Exec, err)
+		}
+		d.Password = strings.TrimRight(stdout.String(), "\r\n")
+		if d.Password == "" {
+			return fmt.Errorf("password command %s printed no password",
+				d.PasswordCommand.Exec)
+		}
+	}
+	return nil
+}
+
+// validateCredentials returns the problems with the ways the password of
+// a database section is given.
+func (d *DatabaseConfig) validateCredentials(section string) []string {
+	var errs []string
+
+	var sources []string
+	if d.Password != "" {
+		sources = append(sources, "password")
+	}
+	if d.PasswordFile != "" {
+		sources = append(sources, "password_file")
+	}
+	if d.PasswordCommand != nil {
+		sources = append(sources, "password_command")
+		if d.PasswordCommand.Exec == "" {
+			errs = append(errs, fmt.Sprintf(
+				"%s.password_command: exec is required", section))
+		}
+	}
+	if len(sources) > 1 {
+		errs = append(errs, fmt.Sprintf("%s: only one of %s may be set",
+			section, strings.Join(sources, ", ")))
+	}
+
+	return errs
+}
+
+// validateCredentials returns the problems with the ways the database
+// passwords are given.
+func (c *Config) validateCredentials() []string {
+	errs := c.Database.validateCredentials("database")
+	for i, t := range c.Targets {
+		errs = append(errs, t.DatabaseConfig.validateCredentials(
+			fmt.Sprintf("targets[%d]", i))...)
+	}
+	if c.CopyTo != nil {
+		errs = append(errs, c.CopyTo.DatabaseConfig.validateCredentials(
+			"copy_to")...)
+	}
+	return errs
+}
+
+// quoteConnValue quotes a value of a connection string if it is empty or
+// holds spaces, quotes, or backslashes.
+func quoteConnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n'\\") {
+		return value
+	}
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + r.Replace(value) + "'"
+}
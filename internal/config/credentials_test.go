@@ -0,0 +1,156 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestExpandEnv tests the expansion of environment variables in the
+// connection parameters
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("TEST_DB_HOST", "db.internal")
+	t.Setenv("TEST_DB_PASS", "s3cret")
+
+	cfg := Config{
+		Database: DatabaseConfig{Host: "${TEST_DB_HOST}", Database: "app",
+			Password: "${TEST_DB_PASS}"},
+		Targets: []TargetConfig{{DatabaseConfig: DatabaseConfig{
+			Database: "app_${TEST_DB_HOST}"}}},
+	}
+	if err := cfg.ExpandEnv(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Database.Host != "db.internal" || cfg.Database.Password != "s3cret" {
+		t.Errorf("unexpected database section: %+v", cfg.Database)
+	}
+	if cfg.Targets[0].Database != "app_db.internal" {
+		t.Errorf("unexpected target database %q", cfg.Targets[0].Database)
+	}
+
+	// A dollar sign without braces is left as it is
+	cfg = Config{Database: DatabaseConfig{Password: "pa$$word"}}
+	if err := cfg.ExpandEnv(); err != nil || cfg.Database.Password != "pa$$word" {
+		t.Errorf("unexpected password %q, error %v", cfg.Database.Password, err)
+	}
+
+	cfg = Config{Database: DatabaseConfig{Password: "${TEST_DB_UNSET}"}}
+	err := cfg.ExpandEnv()
+	if err == nil || !contains(err.Error(),
+		"database.password: environment variable TEST_DB_UNSET is not set") {
+		t.Errorf("expected unset variable error, got: %v", err)
+	}
+}
+
+// TestResolvePassword tests reading the password from a file and a
+// command
+func TestResolvePassword(t *testing.T) {
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("from file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db := DatabaseConfig{PasswordFile: path}
+	if err := db.ResolvePassword(ctx); err != nil || db.Password != "from file" {
+		t.Errorf("file: unexpected password %q, error %v", db.Password, err)
+	}
+
+	db = DatabaseConfig{PasswordCommand: &PasswordCommand{Exec: "echo",
+		Args: []string{"from command"}}}
+	if err := db.ResolvePassword(ctx); err != nil || db.Password != "from command" {
+		t.Errorf("command: unexpected password %q, error %v", db.Password, err)
+	}
+
+	// A password given directly is used as it is
+	db = DatabaseConfig{Password: "given", PasswordFile: "/nonexistent"}
+	if err := db.ResolvePassword(ctx); err != nil || db.Password != "given" {
+		t.Errorf("given: unexpected password %q, error %v", db.Password, err)
+	}
+
+	db = DatabaseConfig{PasswordFile: "/nonexistent"}
+	if err := db.ResolvePassword(ctx); err == nil {
+		t.Error("expected error for a missing password file")
+	}
+	db = DatabaseConfig{PasswordCommand: &PasswordCommand{Exec: "false"}}
+	if err := db.ResolvePassword(ctx); err == nil {
+		t.Error("expected error for a failing password command")
+	}
+}
+
+// TestCredentialsValidation tests the validation of the password settings
+func TestCredentialsValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		database DatabaseConfig
+		errMsg   string
+	}{
+		{
+			name:     "password file",
+			database: DatabaseConfig{PasswordFile: "/run/secrets/db"},
+		},
+		{
+			name: "password command",
+			database: DatabaseConfig{PasswordCommand: &PasswordCommand{
+				Exec: "vault", Args: []string{"read", "-field=password"}}},
+		},
+		{
+			name:     "password and file",
+			database: DatabaseConfig{Password: "x", PasswordFile: "/run/secrets/db"},
+			errMsg:   "database: only one of password, password_file may be set",
+		},
+		{
+			name:     "command without exec",
+			database: DatabaseConfig{PasswordCommand: &PasswordCommand{}},
+			errMsg:   "database.password_command: exec is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.database.Database = "mydb"
+			tt.database.User = "myuser"
+			cfg := Config{
+				Database: tt.database,
+				Columns: []ColumnConfig{
+					{Column: "public.users.email", Pattern: "EMAIL"},
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+// TestConnectionStringQuoting tests the quoting of passwords and the
+// passfile in the connection string
+func TestConnectionStringQuoting(t *testing.T) {
+	t.Setenv("PGPASSWORD", "")
+
+	db := DatabaseConfig{Host: "localhost", Port: 5432, Database: "mydb",
+		User: "myuser", SSLMode: "prefer", Password: `it's a \ secret`,
+		PassFile: "/etc/pg pass"}
+	expected := "host=localhost port=5432 dbname=mydb user=myuser " +
+		`sslmode=prefer password='it\'s a \\ secret' passfile='/etc/pg pass'`
+	if got := db.ConnectionString(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,86 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// IsDateShiftColumn returns true if this column's dates are shifted by an
+// offset of each row's entity.
+func (c ColumnConfig) IsDateShiftColumn() bool {
+	return c.Pattern == generator.DateShiftPattern
+}
+
+// DateShiftEntities returns the entity columns of the columns whose dates
+// are shifted.
+func (c *Config) DateShiftEntities() []errors.ColumnRef {
+	return c.sameTableColumns(func(col ColumnConfig) string {
+		if col.IsDateShiftColumn() {
+			return col.Entity
+		}
+		return ""
+	})
+}
+
+// validateDateShift returns the problems with the columns whose dates are
+// shifted. Each must name an entity column of its own table that is not
+// anonymized, as its values would change part way through the run, and
+// the pattern cannot be used for documents and fields, which have no
+// entity.
+func (c *Config) validateDateShift() []string {
+	var errs []string
+
+	anonymized := make(map[string]bool)
+	for _, col := range c.Columns {
+		anonymized[col.Column] = true
+	}
+
+	for i, col := range c.Columns {
+		if !col.IsDateShiftColumn() {
+			if col.Entity != "" {
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: 'entity' requires the %s pattern", i,
+					generator.DateShiftPattern))
+			}
+			if slices.Contains(col.PatternNames(),
+				generator.DateShiftPattern) {
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: %s cannot be used in 'json_paths', "+
+						"'xml_paths', or 'fields'", i,
+					generator.DateShiftPattern))
+			}
+			continue
+		}
+
+		table := col.Column[:max(strings.LastIndex(col.Column, "."), 0)]
+		switch {
+		case col.Entity == "":
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: %s requires an 'entity' column", i,
+				generator.DateShiftPattern))
+		case table+"."+col.Entity == col.Column:
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: a column cannot be its own entity", i))
+		case anonymized[table+"."+col.Entity]:
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: entity column %q cannot be anonymized", i,
+				col.Entity))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,93 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestDateShiftValidation tests the validation of date shifted columns
+func TestDateShiftValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		column ColumnConfig
+		errMsg string
+	}{
+		{
+			name: "valid",
+			column: ColumnConfig{Column: "public.visits.visited_on",
+				Pattern: "DATE_SHIFT_CONSISTENT", Entity: "patient_id"},
+		},
+		{
+			name: "no entity",
+			column: ColumnConfig{Column: "public.visits.visited_on",
+				Pattern: "DATE_SHIFT_CONSISTENT"},
+			errMsg: "requires an 'entity' column",
+		},
+		{
+			name: "entity without the pattern",
+			column: ColumnConfig{Column: "public.visits.visited_on",
+				Pattern: "DOB", Entity: "patient_id"},
+			errMsg: "'entity' requires the DATE_SHIFT_CONSISTENT pattern",
+		},
+		{
+			name: "own entity",
+			column: ColumnConfig{Column: "public.visits.visited_on",
+				Pattern: "DATE_SHIFT_CONSISTENT", Entity: "visited_on"},
+			errMsg: "cannot be its own entity",
+		},
+		{
+			name: "anonymized entity",
+			column: ColumnConfig{Column: "public.visits.visited_on",
+				Pattern: "DATE_SHIFT_CONSISTENT", Entity: "notes"},
+			errMsg: `entity column "notes" cannot be anonymized`,
+		},
+		{
+			name: "json path",
+			column: ColumnConfig{Column: "public.visits.details",
+				JSONPaths: []JSONPathConfig{{Path: "$.date",
+					Pattern: "DATE_SHIFT_CONSISTENT"}}},
+			errMsg: "cannot be used in 'json_paths'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{
+					{Column: "public.visits.notes", Pattern: "LOREMIPSUM"},
+					tt.column,
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	cfg := Config{Columns: []ColumnConfig{
+		{Column: "public.visits.visited_on",
+			Pattern: "DATE_SHIFT_CONSISTENT", Entity: "patient_id"},
+		{Column: "public.visits.discharged_on",
+			Pattern: "DATE_SHIFT_CONSISTENT", Entity: "patient_id"},
+	}}
+	refs := cfg.DateShiftEntities()
+	if len(refs) != 1 || refs[0].String() != "public.visits.patient_id" {
+		t.Errorf("unexpected entity columns: %v", refs)
+	}
+}
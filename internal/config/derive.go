@@ -0,0 +1,114 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// Derivation is a parsed derive rule: the kind of value to derive, and the
+// columns of the same table it is derived from.
+type Derivation struct {
+	Kind string
+	From []string
+}
+
+// IsDerivedColumn returns true if this column is derived from other
+// columns.
+func (c ColumnConfig) IsDerivedColumn() bool {
+	return c.Derive != ""
+}
+
+// ParseDerivation parses a derive rule of the form "kind from col, col".
+func ParseDerivation(rule string) (Derivation, error) {
+	kind, from, ok := strings.Cut(rule, " from ")
+	if !ok {
+		return Derivation{}, fmt.Errorf(
+			"%q must be in the form 'kind from column, ...'", rule)
+	}
+
+	d := Derivation{Kind: strings.ToLower(strings.TrimSpace(kind))}
+	for _, col := range strings.Split(from, ",") {
+		col = strings.TrimSpace(col)
+		if col == "" {
+			return Derivation{}, fmt.Errorf("%q has an empty column name",
+				rule)
+		}
+		d.From = append(d.From, col)
+	}
+	return d, nil
+}
+
+// Derivation returns the column's parsed derive rule.
+func (c ColumnConfig) Derivation() (Derivation, error) {
+	return ParseDerivation(c.Derive)
+}
+
+// validateDerived returns the problems with the derived columns. A column
+// must be derived from anonymized columns of its own table, which are not
+// derived themselves, so that it holds no original values and can be
+// derived once they have been anonymized.
+func (c *Config) validateDerived() []string {
+	var errs []string
+
+	columns := make(map[string]ColumnConfig)
+	for _, col := range c.Columns {
+		columns[col.Column] = col
+	}
+
+	for i, col := range c.Columns {
+		if !col.IsDerivedColumn() {
+			continue
+		}
+
+		if col.Pattern != "" || col.IsJSONColumn() || col.IsXMLColumn() ||
+			col.IsCompositeColumn() {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: 'derive' cannot be combined with 'pattern', "+
+					"'json_paths', 'xml_paths', or 'fields'", i))
+		}
+
+		d, err := col.Derivation()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("column[%d]: derive: %v", i, err))
+			continue
+		}
+		if !generator.IsDeriveKind(d.Kind) {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: derive: unknown kind %q; must be one of %s", i,
+				d.Kind, strings.Join(generator.DeriveKinds, ", ")))
+		}
+
+		table := col.Column[:max(strings.LastIndex(col.Column, "."), 0)]
+		for _, name := range d.From {
+			source, ok := columns[table+"."+name]
+			switch {
+			case table+"."+name == col.Column:
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: derive: a column cannot be derived from itself",
+					i))
+			case !ok:
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: derive: source column %q is not anonymized",
+					i, name))
+			case source.IsDerivedColumn():
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: derive: source column %q is itself derived",
+					i, name))
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,102 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestParseDerivation tests parsing derive rules
+func TestParseDerivation(t *testing.T) {
+	d, err := ParseDerivation("Email from first_name, last_name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Kind != "email" || len(d.From) != 2 || d.From[0] != "first_name" ||
+		d.From[1] != "last_name" {
+		t.Errorf("unexpected derivation: %+v", d)
+	}
+
+	for _, rule := range []string{"email", "email from ", "email from a,,b"} {
+		if _, err := ParseDerivation(rule); err == nil {
+			t.Errorf("expected an error for %q", rule)
+		}
+	}
+}
+
+// TestDeriveValidation tests the validation of derived columns
+func TestDeriveValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		column ColumnConfig
+		errMsg string
+	}{
+		{
+			name: "valid",
+			column: ColumnConfig{Column: "public.users.email",
+				Derive: "email from first_name, last_name"},
+		},
+		{
+			name: "with pattern",
+			column: ColumnConfig{Column: "public.users.email",
+				Pattern: "EMAIL", Derive: "email from first_name"},
+			errMsg: "cannot be combined",
+		},
+		{
+			name: "unknown kind",
+			column: ColumnConfig{Column: "public.users.email",
+				Derive: "phone from first_name"},
+			errMsg: `unknown kind "phone"`,
+		},
+		{
+			name: "source not anonymized",
+			column: ColumnConfig{Column: "public.users.email",
+				Derive: "email from nickname"},
+			errMsg: `source column "nickname" is not anonymized`,
+		},
+		{
+			name: "source in another table",
+			column: ColumnConfig{Column: "public.orders.email",
+				Derive: "email from first_name"},
+			errMsg: `source column "first_name" is not anonymized`,
+		},
+		{
+			name: "derived from itself",
+			column: ColumnConfig{Column: "public.users.email",
+				Derive: "email from email"},
+			errMsg: "derived from itself",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{
+					{Column: "public.users.first_name",
+						Pattern: "PERSON_FIRST_NAME"},
+					{Column: "public.users.last_name",
+						Pattern: "PERSON_LAST_NAME"},
+					tt.column,
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
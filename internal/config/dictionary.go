@@ -0,0 +1,93 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DictionaryConfig tunes the dictionary of value mappings: how many are
+// kept in memory, how those spilling over are stored, and whether they
+// are kept after the run.
+type DictionaryConfig struct {
+	// Path is a SQLite file to keep the mappings in after the run, and
+	// to look them up in by later runs, rather than a temporary file
+	// removed at the end of the run. It is created if it does not exist.
+	Path string `yaml:"path,omitempty" mapstructure:"path"`
+
+	// CacheSize is the number of mappings kept in memory before the
+	// least recently used are only looked up on disk; zero uses the
+	// default of 1000000.
+	CacheSize int `yaml:"cache_size,omitempty" mapstructure:"cache_size"`
+
+	// InMemory keeps the mappings spilling over in memory rather than in
+	// a temporary file, for runs small enough for all of them to fit.
+	InMemory bool `yaml:"in_memory,omitempty" mapstructure:"in_memory"`
+
+	// JournalMode and Synchronous set the SQLite journal_mode and
+	// synchronous pragmas of the temporary file; empty leaves SQLite's
+	// defaults. As the file is removed at the end of the run, wal and off
+	// trade nothing of value for speed.
+	JournalMode string `yaml:"journal_mode,omitempty" mapstructure:"journal_mode"`
+	Synchronous string `yaml:"synchronous,omitempty" mapstructure:"synchronous"`
+}
+
+// Values accepted for the SQLite pragmas of the dictionary.
+var (
+	journalModes = []string{"delete", "truncate", "persist", "memory",
+		"wal", "off"}
+	synchronousModes = []string{"off", "normal", "full", "extra"}
+)
+
+// validateDictionary returns the problems with the dictionary settings.
+func (c *Config) validateDictionary() []string {
+	var errs []string
+	d := c.Dictionary
+
+	if d.Path != "" {
+		if d.InMemory {
+			errs = append(errs, "dictionary.path cannot be set with "+
+				"in_memory, which keeps no file")
+		}
+		if c.HasTargets() && c.TargetDictionary != TargetDictionaryShared {
+			errs = append(errs, "dictionary.path requires "+
+				"target_dictionary: shared, as each target otherwise "+
+				"starts with an empty dictionary")
+		}
+	}
+	if d.CacheSize < 0 {
+		errs = append(errs, "dictionary.cache_size must not be negative")
+	}
+	for _, pragma := range []struct {
+		name, value string
+		values      []string
+	}{
+		{"journal_mode", d.JournalMode, journalModes},
+		{"synchronous", d.Synchronous, synchronousModes},
+	} {
+		if pragma.value == "" {
+			continue
+		}
+		if !slices.Contains(pragma.values, strings.ToLower(pragma.value)) {
+			errs = append(errs, fmt.Sprintf(
+				"dictionary.%s must be one of %s, got %q", pragma.name,
+				strings.Join(pragma.values, ", "), pragma.value))
+		} else if d.InMemory {
+			errs = append(errs, fmt.Sprintf(
+				"dictionary.%s cannot be set with in_memory, which keeps "+
+					"no file", pragma.name))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,93 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestDictionaryValidation tests the validation of the dictionary settings
+func TestDictionaryValidation(t *testing.T) {
+	tests := []struct {
+		name             string
+		dictionary       DictionaryConfig
+		targetDictionary string
+		errMsg           string
+	}{
+		{
+			name: "valid",
+			dictionary: DictionaryConfig{CacheSize: 50000,
+				JournalMode: "WAL", Synchronous: "off"},
+		},
+		{
+			name:       "in memory",
+			dictionary: DictionaryConfig{InMemory: true},
+		},
+		{
+			name:       "negative cache size",
+			dictionary: DictionaryConfig{CacheSize: -1},
+			errMsg:     "dictionary.cache_size must not be negative",
+		},
+		{
+			name:       "unknown journal mode",
+			dictionary: DictionaryConfig{JournalMode: "fast"},
+			errMsg:     `dictionary.journal_mode must be one of delete, truncate, persist, memory, wal, off, got "fast"`,
+		},
+		{
+			name:       "pragma in memory",
+			dictionary: DictionaryConfig{InMemory: true, Synchronous: "off"},
+			errMsg:     "dictionary.synchronous cannot be set with in_memory",
+		},
+		{
+			name:             "path",
+			dictionary:       DictionaryConfig{Path: "mappings.db"},
+			targetDictionary: TargetDictionaryShared,
+		},
+		{
+			name:       "path in memory",
+			dictionary: DictionaryConfig{Path: "mappings.db", InMemory: true},
+			errMsg:     "dictionary.path cannot be set with in_memory",
+		},
+		{
+			name:       "path per target",
+			dictionary: DictionaryConfig{Path: "mappings.db"},
+			errMsg:     "dictionary.path requires target_dictionary: shared",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{
+					{Column: "public.users.email", Pattern: "EMAIL"},
+				},
+				Dictionary: tt.dictionary,
+			}
+			if tt.targetDictionary != "" || tt.dictionary.Path != "" {
+				cfg.Targets = []TargetConfig{
+					{Name: "eu", DatabaseConfig: DatabaseConfig{Database: "eu"}},
+					{Name: "us", DatabaseConfig: DatabaseConfig{Database: "us"}},
+				}
+				cfg.TargetDictionary = tt.targetDictionary
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import "fmt"
+
+// IsDistributionColumn returns true if this column's values are replaced
+// with values drawn from the distribution of its original values.
+func (c ColumnConfig) IsDistributionColumn() bool {
+	return c.PreserveDistribution
+}
+
+// validateDistributions returns the problems with the columns whose
+// distribution is preserved. Their values are drawn from a histogram in
+// place of a pattern, so they cannot be anonymized in any other way.
+func (c *Config) validateDistributions() []string {
+	var errs []string
+
+	for i, col := range c.Columns {
+		if !col.IsDistributionColumn() {
+			continue
+		}
+
+		if col.Pattern != "" || col.IsJSONColumn() || col.IsXMLColumn() ||
+			col.IsCompositeColumn() || col.IsDerivedColumn() ||
+			col.IsFillColumn() || col.IsShuffleColumn() ||
+			col.IsLargeObjectColumn() || col.IsDocumentColumn() {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: 'preserve_distribution' cannot be combined "+
+					"with 'pattern', 'json_paths', 'xml_paths', 'fields', "+
+					"'derive', 'constant', 'large_object', 'document', or "+
+					"strategies 'nullify' and 'shuffle'", i))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,74 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestDistributionValidation tests the validation of columns whose
+// distribution is preserved
+func TestDistributionValidation(t *testing.T) {
+	constant := "0"
+	tests := []struct {
+		name   string
+		column ColumnConfig
+		errMsg string
+	}{
+		{
+			name: "without a pattern",
+			column: ColumnConfig{Column: "public.employees.salary",
+				PreserveDistribution: true},
+		},
+		{
+			name: "with a pattern",
+			column: ColumnConfig{Column: "public.employees.salary",
+				Pattern: "LAPLACE_NOISE", PreserveDistribution: true},
+			errMsg: "'preserve_distribution' cannot be combined",
+		},
+		{
+			name: "with a constant",
+			column: ColumnConfig{Column: "public.employees.salary",
+				Constant: &constant, PreserveDistribution: true},
+			errMsg: "'preserve_distribution' cannot be combined",
+		},
+		{
+			name: "shuffled",
+			column: ColumnConfig{Column: "public.employees.salary",
+				Strategy: StrategyShuffle, PreserveDistribution: true},
+			errMsg: "'preserve_distribution' cannot be combined",
+		},
+		{
+			name: "partitioned",
+			column: ColumnConfig{Column: "public.employees.salary",
+				PreserveDistribution: true, PartitionBy: "tenant_id"},
+			errMsg: "'partition_by' requires a single 'pattern'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns:  []ColumnConfig{tt.column},
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import "fmt"
+
+// IsNullifyColumn returns true if this column's values are set to NULL
+// rather than replaced.
+func (c ColumnConfig) IsNullifyColumn() bool {
+	return c.Strategy == StrategyNullify
+}
+
+// IsConstantColumn returns true if this column's values are all replaced
+// with the same constant.
+func (c ColumnConfig) IsConstantColumn() bool {
+	return c.Constant != nil
+}
+
+// IsFillColumn returns true if every value of this column is set to NULL
+// or to a constant, with no pattern.
+func (c ColumnConfig) IsFillColumn() bool {
+	return c.IsNullifyColumn() || c.IsConstantColumn()
+}
+
+// validateFill returns the problems with the columns set to NULL or to a
+// constant, which have no pattern, and need no strategy to read their
+// rows, nor can sample them, as they are written with a single statement.
+func (c *Config) validateFill() []string {
+	var errs []string
+
+	for i, col := range c.Columns {
+		if !col.IsFillColumn() {
+			continue
+		}
+
+		option := "strategy 'nullify'"
+		if col.IsConstantColumn() {
+			option = "'constant'"
+			if col.Strategy != "" {
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: 'constant' cannot be combined with "+
+						"'strategy'", i))
+			}
+		}
+
+		if col.Pattern != "" || col.IsDerivedColumn() || col.IsJSONColumn() ||
+			col.IsXMLColumn() || col.IsCompositeColumn() {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: %s cannot be combined with 'pattern', "+
+					"'derive', 'json_paths', 'xml_paths', or 'fields'", i,
+				option))
+		}
+
+		// Every row is written with a single statement
+		if col.SamplePercent != 0 {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: %s cannot be combined with 'sample_percent'",
+				i, option))
+		}
+	}
+
+	return errs
+}
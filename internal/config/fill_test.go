@@ -0,0 +1,98 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestFillValidation tests the validation of nullified and constant
+// columns
+func TestFillValidation(t *testing.T) {
+	empty := ""
+	unknown := "Unknown"
+
+	tests := []struct {
+		name   string
+		column ColumnConfig
+		errMsg string
+	}{
+		{
+			name: "nullify",
+			column: ColumnConfig{Column: "public.users.notes",
+				Strategy: "nullify"},
+		},
+		{
+			name: "constant",
+			column: ColumnConfig{Column: "public.users.notes",
+				Constant: &unknown},
+		},
+		{
+			name: "empty constant",
+			column: ColumnConfig{Column: "public.users.notes",
+				Constant: &empty},
+		},
+		{
+			name: "nullify with pattern",
+			column: ColumnConfig{Column: "public.users.notes",
+				Strategy: "nullify", Pattern: "LOREMIPSUM"},
+			errMsg: "strategy 'nullify' cannot be combined with 'pattern'",
+		},
+		{
+			name: "constant with fields",
+			column: ColumnConfig{Column: "public.users.notes",
+				Constant: &unknown, Fields: map[string]string{"a": "EMAIL"}},
+			errMsg: "'constant' cannot be combined with 'pattern'",
+		},
+		{
+			name: "nullify sample",
+			column: ColumnConfig{Column: "public.users.notes",
+				Strategy: "nullify", SamplePercent: 10},
+			errMsg: "strategy 'nullify' cannot be combined with 'sample_percent'",
+		},
+		{
+			name: "constant with strategy",
+			column: ColumnConfig{Column: "public.users.notes",
+				Constant: &unknown, Strategy: "nullify"},
+			errMsg: "'constant' cannot be combined with 'strategy'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns:  []ColumnConfig{tt.column},
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	// An empty constant is set, unlike a missing one
+	var col ColumnConfig
+	if err := yaml.Unmarshal([]byte("column: public.users.notes\n"+
+		"constant: \"\"\n"), &col); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !col.IsConstantColumn() || *col.Constant != "" {
+		t.Errorf("expected an empty constant, got %v", col.Constant)
+	}
+}
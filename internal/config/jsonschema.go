@@ -0,0 +1,89 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/jsonpath"
+)
+
+// ResolveJSONSchemas derives json_paths for every column with a json_schema
+// reference from the x-anonymize annotations in the referenced schema.
+// A reference is a JSON or YAML file, optionally followed by a JSON pointer
+// fragment selecting a schema within it, e.g.
+// "openapi.yaml#/components/schemas/Customer". Relative file names are
+// resolved against baseDir. Paths listed explicitly in json_paths take
+// precedence over derived paths.
+func (c *Config) ResolveJSONSchemas(baseDir string) error {
+	documents := make(map[string]any)
+
+	for i, col := range c.Columns {
+		if col.JSONSchema == "" {
+			continue
+		}
+
+		file, pointer, _ := strings.Cut(col.JSONSchema, "#")
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(baseDir, file)
+		}
+
+		doc, ok := documents[file]
+		if !ok {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return errors.NewConfigError(file,
+					"failed to read JSON schema", err)
+			}
+			// YAML is a superset of JSON, so this handles both formats
+			if err := yaml.Unmarshal(data, &doc); err != nil {
+				return errors.NewConfigError(file,
+					"failed to parse JSON schema", err)
+			}
+			documents[file] = doc
+		}
+
+		derived, err := jsonpath.PathsFromSchema(doc, pointer)
+		if err != nil {
+			return errors.NewConfigError(file, fmt.Sprintf(
+				"column %s: failed to derive JSON paths", col.Column), err)
+		}
+		if len(derived) == 0 {
+			return errors.NewConfigError(file, fmt.Sprintf(
+				"column %s: schema has no %s annotations", col.Column,
+				jsonpath.SchemaExtension), nil)
+		}
+
+		explicit := make(map[string]bool)
+		for _, jp := range col.JSONPaths {
+			explicit[jp.Path] = true
+		}
+
+		paths := append([]JSONPathConfig(nil), col.JSONPaths...)
+		for _, sp := range derived {
+			if !explicit[sp.Path] {
+				paths = append(paths, JSONPathConfig{
+					Path:    sp.Path,
+					Pattern: sp.Pattern,
+				})
+			}
+		}
+		c.Columns[i].JSONPaths = paths
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveJSONSchemas(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	openapi := `
+openapi: 3.0.0
+components:
+  schemas:
+    Customer:
+      type: object
+      properties:
+        email:
+          type: string
+          x-anonymize: EMAIL
+        phone:
+          type: string
+          x-anonymize: US_PHONE
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "openapi.yaml"),
+		[]byte(openapi), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	plain := `{"properties": {"name": {"type": "string"}}}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "plain.json"),
+		[]byte(plain), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	t.Run("derives paths", func(t *testing.T) {
+		cfg := Config{
+			Columns: []ColumnConfig{
+				{
+					Column:     "public.customers.profile",
+					JSONSchema: "openapi.yaml#/components/schemas/Customer",
+					JSONPaths: []JSONPathConfig{
+						// Explicit entries take precedence
+						{Path: "$.phone", Pattern: "UK_PHONE"},
+					},
+				},
+			},
+		}
+
+		if err := cfg.ResolveJSONSchemas(tmpDir); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []JSONPathConfig{
+			{Path: "$.phone", Pattern: "UK_PHONE"},
+			{Path: "$.email", Pattern: "EMAIL"},
+		}
+		got := cfg.Columns[0].JSONPaths
+		if len(got) != len(want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("json_paths[%d] = %v, want %v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("no annotations", func(t *testing.T) {
+		cfg := Config{
+			Columns: []ColumnConfig{
+				{Column: "public.customers.profile", JSONSchema: "plain.json"},
+			},
+		}
+		err := cfg.ResolveJSONSchemas(tmpDir)
+		if err == nil {
+			t.Fatal("expected error for schema without annotations")
+		}
+		if !contains(err.Error(), "no x-anonymize annotations") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		cfg := Config{
+			Columns: []ColumnConfig{
+				{Column: "public.customers.profile", JSONSchema: "missing.json"},
+			},
+		}
+		if err := cfg.ResolveJSONSchemas(tmpDir); err == nil {
+			t.Error("expected error for missing schema file")
+		}
+	})
+
+	t.Run("load resolves relative to config file", func(t *testing.T) {
+		content := `
+columns:
+  - column: public.customers.profile
+    json_schema: openapi.yaml#/components/schemas/Customer
+`
+		path := filepath.Join(tmpDir, "config.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("failed to load config: %v", err)
+		}
+		if len(cfg.Columns[0].JSONPaths) != 2 {
+			t.Errorf("expected 2 derived paths, got %v",
+				cfg.Columns[0].JSONPaths)
+		}
+	})
+}
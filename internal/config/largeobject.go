@@ -0,0 +1,68 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import "fmt"
+
+// IsLargeObjectColumn returns true if this column holds the OIDs of large
+// objects whose contents are anonymized.
+func (c ColumnConfig) IsLargeObjectColumn() bool {
+	return c.LargeObject
+}
+
+// IsDocumentColumn returns true if this column is a bytea column holding
+// text documents whose text is anonymized.
+func (c ColumnConfig) IsDocumentColumn() bool {
+	return c.Document
+}
+
+// validateLargeObjects returns the problems with the columns of documents
+// and of large objects. Each document, or the contents of each large
+// object, is anonymized as a whole with a pattern, so the column cannot
+// select values within it, or replace the references themselves.
+func (c *Config) validateLargeObjects() []string {
+	var errs []string
+
+	for i, col := range c.Columns {
+		option := "document"
+		switch {
+		case col.IsLargeObjectColumn() && col.IsDocumentColumn():
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: 'document' and 'large_object' cannot both be "+
+					"set", i))
+			continue
+		case col.IsLargeObjectColumn():
+			option = "large_object"
+		case !col.IsDocumentColumn():
+			continue
+		}
+
+		switch {
+		case col.IsJSONColumn() || col.IsXMLColumn() ||
+			col.IsCompositeColumn() || col.IsDerivedColumn() ||
+			col.IsFillColumn() || col.IsShuffleColumn():
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: '%s' cannot be combined with "+
+					"'json_paths', 'xml_paths', 'fields', 'derive', "+
+					"'constant', or strategies 'nullify' and 'shuffle'", i,
+				option))
+		case col.Pattern == "":
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: '%s' requires a 'pattern'", i, option))
+		case col.IsDateShiftColumn():
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: %s cannot be used with '%s'", i,
+				col.Pattern, option))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,85 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestLargeObjectValidation tests the validation of large object columns
+func TestLargeObjectValidation(t *testing.T) {
+	constant := "redacted"
+	tests := []struct {
+		name   string
+		column ColumnConfig
+		errMsg string
+	}{
+		{
+			name: "valid",
+			column: ColumnConfig{Column: "public.letters.body_oid",
+				Pattern: "TEXT_SCRUB", LargeObject: true},
+		},
+		{
+			name: "without pattern",
+			column: ColumnConfig{Column: "public.letters.body_oid",
+				LargeObject: true},
+			errMsg: "'large_object' requires a 'pattern'",
+		},
+		{
+			name: "with constant",
+			column: ColumnConfig{Column: "public.letters.body_oid",
+				Constant: &constant, LargeObject: true},
+			errMsg: "'large_object' cannot be combined with",
+		},
+		{
+			name: "with date shift",
+			column: ColumnConfig{Column: "public.letters.body_oid",
+				Pattern: "DATE_SHIFT_CONSISTENT", Entity: "patient_id",
+				LargeObject: true},
+			errMsg: "DATE_SHIFT_CONSISTENT cannot be used with 'large_object'",
+		},
+		{
+			name: "document",
+			column: ColumnConfig{Column: "public.letters.body",
+				Pattern: "TEXT_SCRUB", Document: true},
+		},
+		{
+			name: "document without pattern",
+			column: ColumnConfig{Column: "public.letters.body",
+				Document: true},
+			errMsg: "'document' requires a 'pattern'",
+		},
+		{
+			name: "document and large object",
+			column: ColumnConfig{Column: "public.letters.body",
+				Pattern: "TEXT_SCRUB", Document: true, LargeObject: true},
+			errMsg: "'document' and 'large_object' cannot both be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns:  []ColumnConfig{tt.column},
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocaleConfig weights the countries that worldwide and generic patterns
+// draw values from. It is written as a list of country codes and weights,
+// such as [US: 0.7, MX: 0.2, CA: 0.1], or as a mapping.
+type LocaleConfig []map[string]float64
+
+// UnmarshalYAML accepts the locale as a list, or as a single mapping.
+func (l *LocaleConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var weights map[string]float64
+		if err := node.Decode(&weights); err != nil {
+			return err
+		}
+		*l = LocaleConfig{weights}
+		return nil
+	}
+
+	var list []map[string]float64
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// Weights returns the weights keyed by country code. Codes are upper
+// cased, as configuration keys may be lowercased when loaded; a country
+// listed more than once takes the sum of its weights.
+func (l LocaleConfig) Weights() map[string]float64 {
+	if len(l) == 0 {
+		return nil
+	}
+	weights := make(map[string]float64)
+	for _, entry := range l {
+		for code, w := range entry {
+			weights[strings.ToUpper(code)] += w
+		}
+	}
+	return weights
+}
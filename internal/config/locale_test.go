@@ -0,0 +1,86 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// TestLocaleConfig tests reading the locale as a list or a mapping
+func TestLocaleConfig(t *testing.T) {
+	forms := map[string]string{
+		"list":    "locale: [US: 0.7, MX: 0.2, CA: 0.1]\n",
+		"mapping": "locale:\n  US: 0.7\n  MX: 0.2\n  CA: 0.1\n",
+	}
+
+	for name, locale := range forms {
+		content := `
+database:
+  database: testdb
+  user: testuser
+
+columns:
+  - column: public.users.name
+    pattern: PERSON_NAME
+` + locale
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+
+		t.Run(name, func(t *testing.T) {
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			checkLocale(t, cfg)
+		})
+
+		// Viper lowercases keys, and lifts a mapping into a list
+		t.Run(name+" with viper", func(t *testing.T) {
+			defer viper.Reset()
+			viper.SetConfigFile(path)
+			if err := viper.ReadInConfig(); err != nil {
+				t.Fatalf("failed to read config: %v", err)
+			}
+			cfg, err := LoadFromViper()
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			checkLocale(t, cfg)
+		})
+	}
+}
+
+// checkLocale checks the weights of the locale loaded by TestLocaleConfig
+func checkLocale(t *testing.T, cfg *Config) {
+	t.Helper()
+
+	weights := cfg.Locale.Weights()
+	if len(weights) != 3 || weights["US"] != 0.7 || weights["MX"] != 0.2 ||
+		weights["CA"] != 0.1 {
+		t.Errorf("unexpected weights: %v", weights)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected valid config, got: %v", err)
+	}
+
+	cfg.Locale = LocaleConfig{{"XX": 1}}
+	err := cfg.Validate()
+	if err == nil || !contains(err.Error(), `locale: unknown country "XX"`) {
+		t.Errorf("expected error for unknown country, got: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/pattern"
+)
+
+// ExternalDependencies returns a description of each file or command,
+// other than the configuration file and the database, that a run with
+// this configuration would depend on. Commands and plugins may in turn
+// use the network. defaultPatternsPath
+// is the default patterns file found by FindDefaultPatternsFile, and
+// registry holds the patterns loaded from it and the user patterns file.
+// An empty result means the run needs nothing beyond the binary.
+func (c *Config) ExternalDependencies(defaultPatternsPath string,
+	registry *pattern.Registry) []string {
+
+	var deps []string
+
+	if !c.Patterns.DisableDefaults && defaultPatternsPath != pattern.EmbeddedPath {
+		deps = append(deps, fmt.Sprintf("file: default patterns %s "+
+			"(set patterns.default_path to %q to use the embedded patterns)",
+			defaultPatternsPath, pattern.EmbeddedPath))
+	}
+	if c.Patterns.UserPath != "" {
+		deps = append(deps, "file: user patterns "+c.Patterns.UserPath)
+	}
+	for _, path := range c.Patterns.Plugins {
+		deps = append(deps, "file: plugin "+path)
+	}
+
+	for _, col := range c.Columns {
+		if col.JSONSchema != "" {
+			deps = append(deps, fmt.Sprintf("file: JSON schema %s for %s",
+				col.JSONSchema, col.Column))
+		}
+	}
+
+	if registry != nil {
+		for _, name := range registry.List() {
+			p, _ := registry.Get(name)
+			if p.IsExecPattern() {
+				deps = append(deps, fmt.Sprintf("command: pattern %s runs %s",
+					p.Name, p.Exec))
+			}
+			if p.IsFPEPattern() && p.FPE.KeyFile != "" {
+				deps = append(deps, fmt.Sprintf("file: key %s of pattern %s",
+					p.FPE.KeyFile, p.Name))
+			}
+			if p.IsHashPattern() && p.Hash.KeyFile != "" {
+				deps = append(deps, fmt.Sprintf("file: key %s of pattern %s",
+					p.Hash.KeyFile, p.Name))
+			}
+		}
+	}
+
+	for _, h := range []struct {
+		point    string
+		commands []HookCommand
+	}{
+		{"before_column", c.Hooks.BeforeColumn},
+		{"before_batch", c.Hooks.BeforeBatch},
+		{"after_batch", c.Hooks.AfterBatch},
+		{"after_column", c.Hooks.AfterColumn},
+	} {
+		for _, cmd := range h.commands {
+			deps = append(deps, fmt.Sprintf("command: %s hook runs %s",
+				h.point, cmd.Exec))
+		}
+	}
+
+	return deps
+}
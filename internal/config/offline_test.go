@@ -0,0 +1,102 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/pattern"
+)
+
+// TestExternalDependencies tests listing the files and commands a run
+// depends on
+func TestExternalDependencies(t *testing.T) {
+	t.Run("self-contained", func(t *testing.T) {
+		cfg := &Config{
+			Columns: []ColumnConfig{{Column: "public.t.c", Pattern: "EMAIL"}},
+		}
+		registry, err := pattern.LoadPatterns(pattern.EmbeddedPath, "", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if registry.Count() == 0 {
+			t.Error("expected embedded default patterns")
+		}
+
+		if deps := cfg.ExternalDependencies(pattern.EmbeddedPath,
+			registry); len(deps) != 0 {
+			t.Errorf("expected no dependencies, got %v", deps)
+		}
+	})
+
+	t.Run("files and commands", func(t *testing.T) {
+		cfg := &Config{
+			Patterns: PatternsConfig{
+				UserPath: "my-patterns.yaml",
+				Plugins:  []string{"gens.so"},
+			},
+			Columns: []ColumnConfig{
+				{Column: "public.t.doc", JSONSchema: "schema.json"},
+			},
+			Hooks: HooksConfig{
+				AfterColumn: []HookCommand{{Exec: "./notify.sh"}},
+			},
+		}
+		registry := pattern.NewRegistry()
+		_ = registry.Add(pattern.Pattern{Name: "UPPER", Exec: "tr"})
+
+		deps := cfg.ExternalDependencies("/etc/pgedge/patterns.yaml",
+			registry)
+		want := []string{
+			"default patterns /etc/pgedge/patterns.yaml",
+			"user patterns my-patterns.yaml",
+			"plugin gens.so",
+			"JSON schema schema.json",
+			"pattern UPPER runs tr",
+			"after_column hook runs ./notify.sh",
+		}
+		if len(deps) != len(want) {
+			t.Fatalf("expected %d dependencies, got %v", len(want), deps)
+		}
+		for i, w := range want {
+			if !strings.Contains(deps[i], w) {
+				t.Errorf("dependency %d = %q, want %q", i, deps[i], w)
+			}
+		}
+	})
+
+	t.Run("fpe and hash key files", func(t *testing.T) {
+		cfg := &Config{Patterns: PatternsConfig{DisableDefaults: true}}
+		registry := pattern.NewRegistry()
+		_ = registry.Add(pattern.Pattern{Name: "ACCOUNT_FPE",
+			FPE: &pattern.FPEConfig{KeyFile: "/etc/pgedge/account.key"}})
+		_ = registry.Add(pattern.Pattern{Name: "CARD_FPE",
+			FPE: &pattern.FPEConfig{KeyEnv: "CARD_KEY"}})
+		_ = registry.Add(pattern.Pattern{Name: "CUSTOMER_HASH",
+			Hash: &pattern.HashConfig{KeyFile: "/etc/pgedge/customer.key"}})
+
+		deps := strings.Join(cfg.ExternalDependencies("", registry), "\n")
+		if strings.Count(deps, "\n") != 1 ||
+			!strings.Contains(deps, "key /etc/pgedge/account.key") ||
+			!strings.Contains(deps, "key /etc/pgedge/customer.key") {
+			t.Errorf("expected the key files, got %v", deps)
+		}
+	})
+
+	t.Run("defaults disabled", func(t *testing.T) {
+		cfg := &Config{Patterns: PatternsConfig{DisableDefaults: true}}
+		if deps := cfg.ExternalDependencies("/etc/pgedge/patterns.yaml",
+			pattern.NewRegistry()); len(deps) != 0 {
+			t.Errorf("expected no dependencies, got %v", deps)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// Partitionable returns true if the column's values can be mapped apart
+// in each partition: it is anonymized with a single pattern, value by
+// value, rather than from other columns or as a whole document.
+func (c ColumnConfig) Partitionable() bool {
+	return c.Pattern != "" && !c.IsJSONColumn() && !c.IsXMLColumn() &&
+		!c.IsCompositeColumn() && !c.IsDerivedColumn() &&
+		!c.IsProfileColumn() && !c.IsShuffleColumn() &&
+		!c.IsDateShiftColumn() && !c.IsLargeObjectColumn() &&
+		!c.IsDocumentColumn()
+}
+
+// PartitionColumns returns the columns whose values scope the mappings
+// of the columns that set their own partition_by.
+func (c *Config) PartitionColumns() []errors.ColumnRef {
+	return c.sameTableColumns(func(col ColumnConfig) string {
+		return col.PartitionBy
+	})
+}
+
+// validatePartitions returns the problems with the partition columns. A
+// partitioned column must be anonymized with a pattern, and its partition
+// column must be another column that is not anonymized, as its values
+// would change part way through the run.
+func (c *Config) validatePartitions() []string {
+	var errs []string
+
+	anonymized := make(map[string]bool)
+	for _, col := range c.Columns {
+		anonymized[col.Column] = true
+	}
+
+	for i, col := range c.Columns {
+		if col.PartitionBy == "" {
+			continue
+		}
+		if !col.Partitionable() {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: 'partition_by' requires a single 'pattern', "+
+					"without 'json_paths', 'xml_paths', 'fields', 'derive', "+
+					"a shuffle, a date shift, or a large object", i))
+		}
+
+		table := col.Column[:max(strings.LastIndex(col.Column, "."), 0)]
+		switch {
+		case table+"."+col.PartitionBy == col.Column:
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: a column cannot be partitioned by itself", i))
+		case anonymized[table+"."+col.PartitionBy]:
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: partition_by column %q cannot be anonymized", i,
+				col.PartitionBy))
+		}
+	}
+
+	// The run's partition column is checked once wildcards are expanded
+	if c.PartitionBy != "" {
+		for i, col := range c.Columns {
+			if strings.HasSuffix(col.Column, "."+c.PartitionBy) {
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: partition_by column %q cannot be anonymized",
+					i, c.PartitionBy))
+			}
+		}
+	}
+
+	return errs
+}
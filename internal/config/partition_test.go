@@ -0,0 +1,111 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestPartitionValidation tests the validation of partition columns
+func TestPartitionValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		partitionBy string
+		columns     []ColumnConfig
+		errMsg      string
+	}{
+		{
+			name: "column partition",
+			columns: []ColumnConfig{{Column: "public.users.email",
+				Pattern: "EMAIL", PartitionBy: "tenant_id"}},
+		},
+		{
+			name:        "run partition",
+			partitionBy: "tenant_id",
+			columns: []ColumnConfig{{Column: "public.users.email",
+				Pattern: "EMAIL"}},
+		},
+		{
+			name: "partitioned by itself",
+			columns: []ColumnConfig{{Column: "public.users.email",
+				Pattern: "EMAIL", PartitionBy: "email"}},
+			errMsg: "a column cannot be partitioned by itself",
+		},
+		{
+			name: "partition column anonymized",
+			columns: []ColumnConfig{
+				{Column: "public.users.email", Pattern: "EMAIL",
+					PartitionBy: "tenant_id"},
+				{Column: "public.users.tenant_id", Pattern: "INTEGER"},
+			},
+			errMsg: `partition_by column "tenant_id" cannot be anonymized`,
+		},
+		{
+			name:        "run partition column anonymized",
+			partitionBy: "tenant_id",
+			columns: []ColumnConfig{{Column: "public.orders.tenant_id",
+				Pattern: "INTEGER"}},
+			errMsg: `partition_by column "tenant_id" cannot be anonymized`,
+		},
+		{
+			name: "json column",
+			columns: []ColumnConfig{{Column: "public.users.data",
+				JSONPaths: []JSONPathConfig{{Path: "$.email",
+					Pattern: "EMAIL"}},
+				PartitionBy: "tenant_id"}},
+			errMsg: "'partition_by' requires a single 'pattern'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database:    DatabaseConfig{Database: "mydb", User: "myuser"},
+				PartitionBy: tt.partitionBy,
+				Columns:     tt.columns,
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+// TestPartitionColumns tests that the partition columns are referenced,
+// so that they are checked to exist
+func TestPartitionColumns(t *testing.T) {
+	cfg := Config{Columns: []ColumnConfig{
+		{Column: "public.users.email", Pattern: "EMAIL",
+			PartitionBy: "tenant_id"},
+		{Column: "public.users.name", Pattern: "PERSON_NAME"},
+	}}
+
+	refs := cfg.ReferencedColumns()
+	found := false
+	for _, ref := range refs {
+		if ref.String() == "public.users.tenant_id" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected public.users.tenant_id to be referenced, got %v",
+			refs)
+	}
+	if got := cfg.PartitionColumns(); len(got) != 1 {
+		t.Errorf("expected 1 partition column, got %v", got)
+	}
+}
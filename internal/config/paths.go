@@ -0,0 +1,63 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ConfigDirs returns the directories searched for the configuration and
+// default patterns files, other than the current directory and the
+// directory containing the binary: the user's configuration directory
+// first, then the system-wide directories for this platform.
+func ConfigDirs() []string {
+	var dirs []string
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "pgedge"))
+	}
+	return append(dirs, systemConfigDirs(runtime.GOOS, os.Getenv)...)
+}
+
+// systemConfigDirs returns the system-wide configuration directories for
+// an operating system.
+func systemConfigDirs(goos string, getenv func(string) string) []string {
+	switch goos {
+	case "windows":
+		// Typically C:\ProgramData, which services can read
+		if dir := getenv("ProgramData"); dir != "" {
+			return []string{filepath.Join(dir, "pgEdge")}
+		}
+		return nil
+	case "darwin":
+		// Homebrew installs under /usr/local on Intel and /opt/homebrew
+		// on Apple silicon
+		return []string{
+			"/etc/pgedge",
+			"/usr/local/etc/pgedge",
+			"/opt/homebrew/etc/pgedge",
+		}
+	default:
+		return []string{"/etc/pgedge"}
+	}
+}
+
+// SearchDirs returns the standard directories searched for the
+// configuration and default patterns files: those returned by ConfigDirs,
+// followed by the directory containing the binary.
+func SearchDirs() []string {
+	dirs := ConfigDirs()
+	if exe, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(exe))
+	}
+	return dirs
+}
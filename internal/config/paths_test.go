@@ -0,0 +1,70 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// TestSystemConfigDirs tests the platform configuration directories
+func TestSystemConfigDirs(t *testing.T) {
+	getenv := func(name string) string {
+		if name == "ProgramData" {
+			return `C:\ProgramData`
+		}
+		return ""
+	}
+
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{"linux", []string{"/etc/pgedge"}},
+		{"freebsd", []string{"/etc/pgedge"}},
+		{"darwin", []string{"/etc/pgedge", "/usr/local/etc/pgedge",
+			"/opt/homebrew/etc/pgedge"}},
+		{"windows", []string{filepath.Join(`C:\ProgramData`, "pgEdge")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			got := systemConfigDirs(tt.goos, getenv)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("systemConfigDirs(%q) = %v, want %v", tt.goos, got,
+					tt.want)
+			}
+		})
+	}
+
+	t.Run("windows without ProgramData", func(t *testing.T) {
+		got := systemConfigDirs("windows", func(string) string { return "" })
+		if len(got) != 0 {
+			t.Errorf("expected no directories, got %v", got)
+		}
+	})
+}
+
+// TestSearchDirs tests that the user configuration directory is searched
+// before the system directories
+func TestSearchDirs(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_CONFIG_HOME is only used on Linux")
+	}
+	t.Setenv("XDG_CONFIG_HOME", "/home/test/.config")
+
+	dirs := SearchDirs()
+	if len(dirs) < 2 || dirs[0] != filepath.Join("/home/test/.config", "pgedge") {
+		t.Errorf("unexpected search directories: %v", dirs)
+	}
+}
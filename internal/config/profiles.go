@@ -0,0 +1,209 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// ProfileConfig groups the columns holding a person's details, across the
+// tables they are kept in, so that each person is given a single generated
+// persona, drawn from one country's data, whose name, email address,
+// address and so on are written to all of them.
+type ProfileConfig struct {
+	Name string `yaml:"name" mapstructure:"name"`
+
+	// Country is the code of the country personas are drawn from, such
+	// as "DE"; empty draws each persona from a randomly chosen country.
+	Country string `yaml:"country,omitempty" mapstructure:"country"`
+
+	Tables []ProfileTableConfig `yaml:"tables" mapstructure:"tables"`
+}
+
+// ProfileTableConfig lists the columns of a table that are filled from a
+// profile's personas.
+type ProfileTableConfig struct {
+	// Table is the table, in schema.table format.
+	Table string `yaml:"table" mapstructure:"table"`
+
+	// Identity is the column identifying the person each row belongs to,
+	// such as users.id or addresses.user_id; rows with the same identity
+	// in any of the profile's tables are given the same persona. If it is
+	// empty, each row of the table is a person of its own, identified by
+	// the table's primary key.
+	Identity string `yaml:"identity,omitempty" mapstructure:"identity"`
+
+	// Columns maps each column to the persona field written to it.
+	Columns map[string]string `yaml:"columns" mapstructure:"columns"`
+}
+
+// ColumnNames returns the names of the table's profile columns in sorted
+// order.
+func (t ProfileTableConfig) ColumnNames() []string {
+	names := make([]string, 0, len(t.Columns))
+	for name := range t.Columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileColumn is set on a column filled from a profile's personas.
+type ProfileColumn struct {
+	Profile  string // Profile name
+	Country  string // Country personas are drawn from, or empty
+	Field    string // Persona field written to the column
+	Identity string // Column identifying the person, or empty for each row
+}
+
+// IsProfileColumn returns true if this column is filled from a profile.
+func (c ColumnConfig) IsProfileColumn() bool {
+	return c.Profile != nil
+}
+
+// ResolveProfiles adds a column entry for each column of each profile,
+// replacing any added before, so that profile columns are ordered,
+// locked, and reported along with the other columns.
+func (c *Config) ResolveProfiles() {
+	columns := make([]ColumnConfig, 0, len(c.Columns))
+	for _, col := range c.Columns {
+		if !col.IsProfileColumn() {
+			columns = append(columns, col)
+		}
+	}
+
+	for _, p := range c.Profiles {
+		for _, t := range p.Tables {
+			for _, name := range t.ColumnNames() {
+				columns = append(columns, ColumnConfig{
+					Column: t.Table + "." + name,
+					Profile: &ProfileColumn{
+						Profile:  p.Name,
+						Country:  p.Country,
+						Field:    t.Columns[name],
+						Identity: t.Identity,
+					},
+				})
+			}
+		}
+	}
+	c.Columns = columns
+}
+
+// ProfileIdentities returns the identity columns of the profiles' tables,
+// which must exist for the profile columns to be filled.
+func (c *Config) ProfileIdentities() []errors.ColumnRef {
+	var refs []errors.ColumnRef
+	seen := make(map[string]bool)
+	for _, p := range c.Profiles {
+		for _, t := range p.Tables {
+			if t.Identity == "" {
+				continue
+			}
+			schema, table, _ := strings.Cut(t.Table, ".")
+			ref := errors.ColumnRef{Schema: schema, Table: table,
+				Column: t.Identity}
+			if !seen[ref.String()] {
+				seen[ref.String()] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// validateProfiles returns the problems with the profiles.
+func (c *Config) validateProfiles() []string {
+	var errs []string
+
+	configured := make(map[string]bool)
+	anonymized := make(map[string]bool)
+	for _, col := range c.Columns {
+		if !col.IsProfileColumn() {
+			configured[col.Column] = true
+		}
+		anonymized[col.Column] = true
+	}
+
+	names := make(map[string]bool)
+	profiled := make(map[string]string)
+	for i, p := range c.Profiles {
+		if p.Name == "" {
+			errs = append(errs, fmt.Sprintf("profiles[%d]: name is required", i))
+		} else if names[p.Name] {
+			errs = append(errs, fmt.Sprintf(
+				"profiles[%d]: duplicate profile name %q", i, p.Name))
+		}
+		names[p.Name] = true
+
+		if p.Country != "" && !generator.IsCountry(p.Country) {
+			errs = append(errs, fmt.Sprintf(
+				"profiles[%d]: unknown country %q", i, p.Country))
+		}
+		if len(p.Tables) == 0 {
+			errs = append(errs, fmt.Sprintf(
+				"profiles[%d]: at least one table must be specified", i))
+		}
+
+		for j, t := range p.Tables {
+			if parts := strings.Split(t.Table, "."); len(parts) != 2 ||
+				parts[0] == "" || parts[1] == "" ||
+				strings.Contains(t.Table, "*") {
+				errs = append(errs, fmt.Sprintf(
+					"profiles[%d].tables[%d]: %q must be in schema.table format",
+					i, j, t.Table))
+			}
+			if t.Identity != "" && anonymized[t.Table+"."+t.Identity] {
+				// Its values would change part way through the run
+				errs = append(errs, fmt.Sprintf(
+					"profiles[%d].tables[%d]: identity column %q cannot be "+
+						"anonymized", i, j, t.Identity))
+			}
+			if len(t.Columns) == 0 {
+				errs = append(errs, fmt.Sprintf(
+					"profiles[%d].tables[%d]: at least one column must be specified",
+					i, j))
+			}
+
+			for _, name := range t.ColumnNames() {
+				field := t.Columns[name]
+				column := t.Table + "." + name
+				switch {
+				case !generator.IsPersonaField(field):
+					errs = append(errs, fmt.Sprintf(
+						"profiles[%d].tables[%d].columns.%s: unknown field %q; "+
+							"must be one of %s", i, j, name, field,
+						strings.Join(generator.PersonaFields, ", ")))
+				case name == t.Identity:
+					errs = append(errs, fmt.Sprintf(
+						"profiles[%d].tables[%d].columns.%s: the identity "+
+							"column cannot be anonymized", i, j, name))
+				case configured[column]:
+					errs = append(errs, fmt.Sprintf(
+						"profiles[%d].tables[%d].columns.%s: column is also "+
+							"listed under columns", i, j, name))
+				case profiled[column] != "":
+					errs = append(errs, fmt.Sprintf(
+						"profiles[%d].tables[%d].columns.%s: column is also "+
+							"in profile %q", i, j, name, profiled[column]))
+				}
+				profiled[column] = p.Name
+			}
+		}
+	}
+
+	return errs
+}
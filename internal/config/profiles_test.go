@@ -0,0 +1,181 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestResolveProfiles tests adding the columns of profiles to the column
+// list when the configuration is loaded
+func TestResolveProfiles(t *testing.T) {
+	content := `
+database:
+  database: testdb
+  user: testuser
+
+columns:
+  - column: public.orders.notes
+    pattern: LOREM
+
+profiles:
+  - name: customer
+    country: DE
+    tables:
+      - table: public.users
+        identity: id
+        columns:
+          last_name: last_name
+          first_name: first_name
+      - table: public.addresses
+        identity: user_id
+        columns:
+          street: address
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid config, got: %v", err)
+	}
+
+	var columns []string
+	for _, col := range cfg.Columns {
+		columns = append(columns, col.Column)
+	}
+	want := []string{"public.orders.notes", "public.users.first_name",
+		"public.users.last_name", "public.addresses.street"}
+	if len(columns) != len(want) {
+		t.Fatalf("expected columns %v, got %v", want, columns)
+	}
+	for i := range want {
+		if columns[i] != want[i] {
+			t.Fatalf("expected columns %v, got %v", want, columns)
+		}
+	}
+
+	street := cfg.Columns[3]
+	if !street.IsProfileColumn() || *street.Profile != (ProfileColumn{
+		Profile: "customer", Country: "DE", Field: "address",
+		Identity: "user_id"}) {
+		t.Errorf("unexpected profile column: %+v", street.Profile)
+	}
+
+	// Resolving again does not add the columns twice
+	cfg.ResolveProfiles()
+	if len(cfg.Columns) != len(want) {
+		t.Errorf("expected %d columns, got %d", len(want), len(cfg.Columns))
+	}
+
+	refs := cfg.ProfileIdentities()
+	if len(refs) != 2 || refs[1].String() != "public.addresses.user_id" {
+		t.Errorf("unexpected identities: %v", refs)
+	}
+}
+
+// TestProfileValidation tests the validation of profiles
+func TestProfileValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile ProfileConfig
+		errMsg  string
+	}{
+		{
+			name: "unknown country",
+			profile: ProfileConfig{Name: "customer", Country: "ZZ",
+				Tables: []ProfileTableConfig{{Table: "public.users",
+					Identity: "id",
+					Columns:  map[string]string{"email": "email"}}}},
+			errMsg: `unknown country "ZZ"`,
+		},
+		{
+			name: "unknown field",
+			profile: ProfileConfig{Name: "customer",
+				Tables: []ProfileTableConfig{{Table: "public.users",
+					Identity: "id",
+					Columns:  map[string]string{"email": "mail"}}}},
+			errMsg: `unknown field "mail"`,
+		},
+		{
+			name: "anonymized identity",
+			profile: ProfileConfig{Name: "customer",
+				Tables: []ProfileTableConfig{{Table: "public.users",
+					Identity: "email",
+					Columns:  map[string]string{"email": "email"}}}},
+			errMsg: "cannot be anonymized",
+		},
+		{
+			name: "table without schema",
+			profile: ProfileConfig{Name: "customer",
+				Tables: []ProfileTableConfig{{Table: "users",
+					Identity: "id",
+					Columns:  map[string]string{"email": "email"}}}},
+			errMsg: "must be in schema.table format",
+		},
+		{
+			name: "column also listed under columns",
+			profile: ProfileConfig{Name: "customer",
+				Tables: []ProfileTableConfig{{Table: "public.orders",
+					Identity: "user_id",
+					Columns:  map[string]string{"notes": "name"}}}},
+			errMsg: "also listed under columns",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{
+					{Column: "public.orders.notes", Pattern: "LOREM"},
+				},
+				Profiles: []ProfileConfig{tt.profile},
+			}
+			cfg.ResolveProfiles()
+
+			err := cfg.Validate()
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+// TestProfileWithoutIdentity tests a profile table whose rows are each a
+// person of their own
+func TestProfileWithoutIdentity(t *testing.T) {
+	cfg := Config{
+		Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+		Profiles: []ProfileConfig{{Name: "contact",
+			Tables: []ProfileTableConfig{{Table: "public.contacts",
+				Columns: map[string]string{"first_name": "first_name",
+					"email": "email"}}}}},
+	}
+	cfg.ResolveProfiles()
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid config, got: %v", err)
+	}
+	if len(cfg.Columns) != 2 || cfg.Columns[0].Profile.Identity != "" {
+		t.Errorf("unexpected columns: %+v", cfg.Columns)
+	}
+	if refs := cfg.ProfileIdentities(); len(refs) != 0 {
+		t.Errorf("expected no identities, got %v", refs)
+	}
+}
@@ -0,0 +1,114 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// ReferenceConfig declares that a column holds copies of the values of
+// another, as a foreign key would, without the database enforcing it,
+// such as a denormalized copy of a customer's email address.
+type ReferenceConfig struct {
+	// Column is the column holding the copies, and References the
+	// column it copies, each in schema.table.column format.
+	Column     string `yaml:"column" mapstructure:"column"`
+	References string `yaml:"references" mapstructure:"references"`
+}
+
+// DeclaredReference is a declared reference, with its columns parsed.
+type DeclaredReference struct {
+	Child  errors.ColumnRef
+	Parent errors.ColumnRef
+}
+
+// DeclaredReferences returns the declared references whose columns are
+// valid, in the order declared.
+func (c *Config) DeclaredReferences() []DeclaredReference {
+	var refs []DeclaredReference
+	for _, r := range c.References {
+		child, err := errors.ParseColumnRef(r.Column)
+		if err != nil {
+			continue
+		}
+		parent, err := errors.ParseColumnRef(r.References)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, DeclaredReference{Child: child, Parent: parent})
+	}
+	return refs
+}
+
+// validateReferences returns the problems with the declared references.
+// A column copies at most one other, and may not copy itself, directly
+// or through others.
+func (c *Config) validateReferences() []string {
+	var errs []string
+
+	parents := make(map[string]string)
+	for i, r := range c.References {
+		valid := true
+		for _, name := range []struct{ key, value string }{
+			{"column", r.Column},
+			{"references", r.References},
+		} {
+			ref, err := errors.ParseColumnRef(name.value)
+			switch {
+			case err != nil || ref.Schema == "" || ref.Table == "" ||
+				ref.Column == "":
+				errs = append(errs, fmt.Sprintf(
+					"references[%d]: %s %q must be in schema.table.column "+
+						"format", i, name.key, name.value))
+				valid = false
+			case strings.Contains(name.value, "*"):
+				errs = append(errs, fmt.Sprintf(
+					"references[%d]: %s %q cannot contain wildcards", i,
+					name.key, name.value))
+				valid = false
+			}
+		}
+		if !valid {
+			continue
+		}
+
+		if _, ok := parents[r.Column]; ok {
+			errs = append(errs, fmt.Sprintf(
+				"references[%d]: column %s is declared to reference more "+
+					"than one column", i, r.Column))
+			continue
+		}
+		parents[r.Column] = r.References
+	}
+
+	// Follow each column's chain of references back to a column that
+	// references none
+	for i, r := range c.References {
+		seen := map[string]bool{r.Column: true}
+		for name, ok := parents[r.Column]; ok; name, ok = parents[name] {
+			if name == r.Column {
+				errs = append(errs, fmt.Sprintf(
+					"references[%d]: column %s references itself", i,
+					r.Column))
+				break
+			}
+			if seen[name] {
+				break // A loop not through this column
+			}
+			seen[name] = true
+		}
+	}
+
+	return errs
+}
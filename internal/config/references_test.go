@@ -0,0 +1,99 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestReferencesValidation tests the validation of declared references
+func TestReferencesValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		references []ReferenceConfig
+		errMsg     string
+	}{
+		{
+			name: "valid",
+			references: []ReferenceConfig{
+				{Column: "sales.orders.email", References: "public.users.email"},
+				{Column: "sales.invoices.email", References: "sales.orders.email"},
+			},
+		},
+		{
+			name: "table name",
+			references: []ReferenceConfig{
+				{Column: "sales.orders", References: "public.users.email"},
+			},
+			errMsg: "must be in schema.table.column format",
+		},
+		{
+			name: "wildcard",
+			references: []ReferenceConfig{
+				{Column: "sales.*.email", References: "public.users.email"},
+			},
+			errMsg: "cannot contain wildcards",
+		},
+		{
+			name: "two parents",
+			references: []ReferenceConfig{
+				{Column: "sales.orders.email", References: "public.users.email"},
+				{Column: "sales.orders.email", References: "public.staff.email"},
+			},
+			errMsg: "more than one column",
+		},
+		{
+			name: "loop",
+			references: []ReferenceConfig{
+				{Column: "sales.orders.email", References: "public.users.email"},
+				{Column: "public.users.email", References: "sales.orders.email"},
+			},
+			errMsg: "references itself",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{
+					{Column: "public.users.email", Pattern: "EMAIL"},
+				},
+				References: tt.references,
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+// TestDeclaredReferences tests parsing the declared references
+func TestDeclaredReferences(t *testing.T) {
+	cfg := &Config{References: []ReferenceConfig{
+		{Column: "sales.orders.email", References: "public.users.email"},
+		{Column: "invalid", References: "public.users.email"},
+	}}
+
+	refs := cfg.DeclaredReferences()
+	if len(refs) != 1 || refs[0].Child.Table != "orders" ||
+		refs[0].Parent.String() != "public.users.email" {
+		t.Errorf("unexpected references: %+v", refs)
+	}
+}
@@ -0,0 +1,123 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Logical decoding plugins the relay reads changes with.
+const (
+	RelayPluginPgoutput = "pgoutput"
+	RelayPluginWal2JSON = "wal2json"
+)
+
+// Relay defaults.
+const (
+	DefaultRelayPollInterval = time.Second
+	DefaultRelayMaxChanges   = 10000
+)
+
+// RelayConfig configures the relay command, which keeps the database
+// written to in copy mode in sync after the copy: it reads the changes
+// made to the database section's database from a logical replication
+// slot, anonymizes the configured columns, and applies them to the
+// database in copy_to.
+type RelayConfig struct {
+	// Slot is the logical replication slot changes are read from.
+	Slot string `yaml:"slot" mapstructure:"slot"`
+
+	// Plugin is the slot's output plugin, RelayPluginPgoutput (the
+	// default) or RelayPluginWal2JSON.
+	Plugin string `yaml:"plugin,omitempty" mapstructure:"plugin"`
+
+	// Publication is the publication of the tables to relay, for the
+	// pgoutput plugin.
+	Publication string `yaml:"publication,omitempty" mapstructure:"publication"`
+
+	// PollInterval is how long to wait before reading the slot again
+	// when no changes were found, such as "5s".
+	PollInterval string `yaml:"poll_interval,omitempty" mapstructure:"poll_interval"`
+
+	// MaxChanges limits the changes read from the slot at once; whole
+	// transactions are always read, so a larger one is read entirely.
+	MaxChanges int `yaml:"max_changes,omitempty" mapstructure:"max_changes"`
+}
+
+// slotName matches the names PostgreSQL allows for replication slots.
+var slotName = regexp.MustCompile(`^[a-z0-9_]{1,63}$`)
+
+// PluginName returns the relay's output plugin.
+func (r *RelayConfig) PluginName() string {
+	if r.Plugin == "" {
+		return RelayPluginPgoutput
+	}
+	return r.Plugin
+}
+
+// Interval returns the time to wait when no changes were found.
+func (r *RelayConfig) Interval() time.Duration {
+	d, err := time.ParseDuration(r.PollInterval)
+	if err != nil || d <= 0 {
+		return DefaultRelayPollInterval
+	}
+	return d
+}
+
+// ChangeLimit returns the most changes read from the slot at once.
+func (r *RelayConfig) ChangeLimit() int {
+	if r.MaxChanges <= 0 {
+		return DefaultRelayMaxChanges
+	}
+	return r.MaxChanges
+}
+
+// validateRelay returns the problems with the relay section.
+func (c *Config) validateRelay() []string {
+	r := c.Relay
+	if r == nil {
+		return nil
+	}
+
+	var errs []string
+	if !c.IsCopyMode() {
+		errs = append(errs, "relay requires copy_to, naming the database "+
+			"to apply changes to")
+	}
+	if !slotName.MatchString(r.Slot) {
+		errs = append(errs, fmt.Sprintf("relay.slot must be a replication "+
+			"slot name of lower case letters, digits and underscores, "+
+			"got %q", r.Slot))
+	}
+	switch r.PluginName() {
+	case RelayPluginPgoutput:
+		if r.Publication == "" {
+			errs = append(errs, "relay.publication is required with the "+
+				"pgoutput plugin")
+		}
+	case RelayPluginWal2JSON:
+	default:
+		errs = append(errs, fmt.Sprintf("relay.plugin must be 'pgoutput' "+
+			"or 'wal2json', got %q", r.Plugin))
+	}
+	if r.PollInterval != "" {
+		if d, err := time.ParseDuration(r.PollInterval); err != nil || d <= 0 {
+			errs = append(errs, fmt.Sprintf("relay.poll_interval must be a "+
+				"positive duration such as '5s', got %q", r.PollInterval))
+		}
+	}
+	if r.MaxChanges < 0 {
+		errs = append(errs, "relay.max_changes must not be negative")
+	}
+	return errs
+}
@@ -0,0 +1,121 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRelayDefaults tests the defaults of the relay settings
+func TestRelayDefaults(t *testing.T) {
+	r := &RelayConfig{Slot: "anonymizer"}
+	if r.PluginName() != RelayPluginPgoutput {
+		t.Errorf("expected pgoutput, got %s", r.PluginName())
+	}
+	if r.Interval() != DefaultRelayPollInterval {
+		t.Errorf("expected the default interval, got %v", r.Interval())
+	}
+	if r.ChangeLimit() != DefaultRelayMaxChanges {
+		t.Errorf("expected the default limit, got %d", r.ChangeLimit())
+	}
+
+	r = &RelayConfig{Slot: "anonymizer", PollInterval: "250ms",
+		MaxChanges: 50}
+	if r.Interval() != 250*time.Millisecond {
+		t.Errorf("expected 250ms, got %v", r.Interval())
+	}
+	if r.ChangeLimit() != 50 {
+		t.Errorf("expected 50, got %d", r.ChangeLimit())
+	}
+}
+
+// TestRelayValidation tests the validation of the relay section
+func TestRelayValidation(t *testing.T) {
+	copyTo := &CopyConfig{DatabaseConfig: DatabaseConfig{Database: "copy"}}
+
+	tests := []struct {
+		name   string
+		relay  *RelayConfig
+		copyTo *CopyConfig
+		errMsg string
+	}{
+		{
+			name:   "pgoutput",
+			relay:  &RelayConfig{Slot: "anonymizer", Publication: "app"},
+			copyTo: copyTo,
+		},
+		{
+			name:   "wal2json",
+			relay:  &RelayConfig{Slot: "anonymizer", Plugin: "wal2json"},
+			copyTo: copyTo,
+		},
+		{
+			name:   "without copy_to",
+			relay:  &RelayConfig{Slot: "anonymizer", Publication: "app"},
+			errMsg: "relay requires copy_to",
+		},
+		{
+			name:   "invalid slot",
+			relay:  &RelayConfig{Slot: "Anonymizer-1", Publication: "app"},
+			copyTo: copyTo,
+			errMsg: "relay.slot must be a replication slot name",
+		},
+		{
+			name:   "no publication",
+			relay:  &RelayConfig{Slot: "anonymizer"},
+			copyTo: copyTo,
+			errMsg: "relay.publication is required",
+		},
+		{
+			name:   "unknown plugin",
+			relay:  &RelayConfig{Slot: "anonymizer", Plugin: "test_decoding"},
+			copyTo: copyTo,
+			errMsg: "relay.plugin must be",
+		},
+		{
+			name: "invalid interval",
+			relay: &RelayConfig{Slot: "anonymizer", Publication: "app",
+				PollInterval: "often"},
+			copyTo: copyTo,
+			errMsg: "relay.poll_interval must be a positive duration",
+		},
+		{
+			name: "negative limit",
+			relay: &RelayConfig{Slot: "anonymizer", Publication: "app",
+				MaxChanges: -1},
+			copyTo: copyTo,
+			errMsg: "relay.max_changes must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{
+					{Column: "public.users.email", Pattern: "EMAIL"},
+				},
+				CopyTo: tt.copyTo,
+				Relay:  tt.relay,
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// IsShuffleColumn returns true if this column's values are permuted among
+// its rows rather than replaced.
+func (c ColumnConfig) IsShuffleColumn() bool {
+	return c.Strategy == StrategyShuffle
+}
+
+// ShuffleGroups returns the columns grouping the rows of shuffled
+// columns.
+func (c *Config) ShuffleGroups() []errors.ColumnRef {
+	return c.sameTableColumns(func(col ColumnConfig) string {
+		if col.IsShuffleColumn() {
+			return col.ShuffleBy
+		}
+		return ""
+	})
+}
+
+// validateShuffle returns the problems with the shuffled columns. A
+// shuffled column has no pattern, and its group column, if it has one,
+// must be another column of its table that is not anonymized, as its
+// values would change part way through the run.
+func (c *Config) validateShuffle() []string {
+	var errs []string
+
+	anonymized := make(map[string]bool)
+	for _, col := range c.Columns {
+		anonymized[col.Column] = true
+	}
+
+	for i, col := range c.Columns {
+		if !col.IsShuffleColumn() {
+			if col.ShuffleBy != "" {
+				errs = append(errs, fmt.Sprintf(
+					"column[%d]: 'shuffle_by' requires strategy 'shuffle'", i))
+			}
+			continue
+		}
+
+		if col.Pattern != "" || col.IsDerivedColumn() || col.IsJSONColumn() ||
+			col.IsXMLColumn() || col.IsCompositeColumn() {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: strategy 'shuffle' cannot be combined with "+
+					"'pattern', 'derive', 'json_paths', 'xml_paths', or "+
+					"'fields'", i))
+		}
+
+		if col.ShuffleBy == "" {
+			continue
+		}
+		table := col.Column[:max(strings.LastIndex(col.Column, "."), 0)]
+		switch {
+		case table+"."+col.ShuffleBy == col.Column:
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: a column cannot be shuffled by itself", i))
+		case anonymized[table+"."+col.ShuffleBy]:
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: shuffle_by column %q cannot be anonymized", i,
+				col.ShuffleBy))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,107 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestShuffleValidation tests the validation of shuffled columns
+func TestShuffleValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		column ColumnConfig
+		errMsg string
+	}{
+		{
+			name: "valid",
+			column: ColumnConfig{Column: "public.employees.salary",
+				Strategy: "shuffle"},
+		},
+		{
+			name: "valid with group",
+			column: ColumnConfig{Column: "public.employees.salary",
+				Strategy: "shuffle", ShuffleBy: "department"},
+		},
+		{
+			name: "with pattern",
+			column: ColumnConfig{Column: "public.employees.salary",
+				Strategy: "shuffle", Pattern: "LOREMIPSUM"},
+			errMsg: "cannot be combined with 'pattern'",
+		},
+		{
+			name: "group without shuffle",
+			column: ColumnConfig{Column: "public.employees.salary",
+				Pattern: "LOREMIPSUM", ShuffleBy: "department"},
+			errMsg: "'shuffle_by' requires strategy 'shuffle'",
+		},
+		{
+			name: "shuffled by itself",
+			column: ColumnConfig{Column: "public.employees.salary",
+				Strategy: "shuffle", ShuffleBy: "salary"},
+			errMsg: "cannot be shuffled by itself",
+		},
+		{
+			name: "anonymized group",
+			column: ColumnConfig{Column: "public.employees.salary",
+				Strategy: "shuffle", ShuffleBy: "name"},
+			errMsg: `shuffle_by column "name" cannot be anonymized`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{
+					{Column: "public.employees.name", Pattern: "PERSON_NAME"},
+					tt.column,
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	// The run's strategy cannot be shuffle
+	cfg := Config{
+		Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+		Strategy: "shuffle",
+		Columns: []ColumnConfig{
+			{Column: "public.employees.name", Pattern: "PERSON_NAME"},
+		},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for a run strategy of shuffle")
+	}
+
+	cfg.Strategy = ""
+	cfg.Columns = append(cfg.Columns,
+		ColumnConfig{Column: "public.employees.salary", Strategy: "shuffle",
+			ShuffleBy: "department"},
+		ColumnConfig{Column: "public.visits.visited_on",
+			Pattern: "DATE_SHIFT_CONSISTENT", Entity: "patient_id"})
+	var names []string
+	for _, ref := range cfg.ReferencedColumns() {
+		names = append(names, ref.String())
+	}
+	if len(names) != 2 || names[0] != "public.visits.patient_id" ||
+		names[1] != "public.employees.department" {
+		t.Errorf("unexpected referenced columns: %v", names)
+	}
+}
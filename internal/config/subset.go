@@ -0,0 +1,92 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TableConfig selects the subset of a table's rows that is kept.
+type TableConfig struct {
+	Table string `yaml:"table" mapstructure:"table"`
+
+	// Sample keeps a random sample of about this percentage of the rows,
+	// such as "10%".
+	Sample string `yaml:"sample,omitempty" mapstructure:"sample"`
+
+	// Limit keeps at most this many rows, chosen at random from those
+	// sampled.
+	Limit int64 `yaml:"limit,omitempty" mapstructure:"limit"`
+}
+
+// SamplePercent returns the percentage of the rows sampled, or zero if
+// the table is not sampled.
+func (t TableConfig) SamplePercent() (float64, error) {
+	if t.Sample == "" {
+		return 0, nil
+	}
+	s := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(t.Sample),
+		"%"))
+	percent, err := strconv.ParseFloat(s, 64)
+	if err != nil || percent <= 0 || percent > 100 {
+		return 0, fmt.Errorf("sample must be a percentage greater than 0 "+
+			"and at most 100, such as \"10%%\", got %q", t.Sample)
+	}
+	return percent, nil
+}
+
+// SubsetTables returns the tables subset, by their schema-qualified
+// names.
+func (c *Config) SubsetTables() map[string]TableConfig {
+	tables := make(map[string]TableConfig, len(c.Tables))
+	for _, t := range c.Tables {
+		tables[t.Table] = t
+	}
+	return tables
+}
+
+// validateTables returns the problems with the tables subset.
+func (c *Config) validateTables() []string {
+	var errs []string
+
+	seen := make(map[string]bool)
+	for i, t := range c.Tables {
+		parts := strings.Split(t.Table, ".")
+		switch {
+		case len(parts) != 2 || parts[0] == "" || parts[1] == "":
+			errs = append(errs, fmt.Sprintf(
+				"tables[%d]: %q must be in schema.table format", i, t.Table))
+		case strings.Contains(t.Table, "*"):
+			errs = append(errs, fmt.Sprintf(
+				"tables[%d]: %q cannot contain wildcards", i, t.Table))
+		case seen[t.Table]:
+			errs = append(errs, fmt.Sprintf(
+				"tables[%d]: table %s is listed more than once", i, t.Table))
+		}
+		seen[t.Table] = true
+
+		if _, err := t.SamplePercent(); err != nil {
+			errs = append(errs, fmt.Sprintf("tables[%d]: %v", i, err))
+		}
+		if t.Limit < 0 {
+			errs = append(errs, fmt.Sprintf(
+				"tables[%d]: limit must not be negative", i))
+		}
+		if t.Sample == "" && t.Limit == 0 {
+			errs = append(errs, fmt.Sprintf(
+				"tables[%d]: 'sample' or 'limit' is required", i))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,108 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestSamplePercent tests parsing the percentage of a table sampled
+func TestSamplePercent(t *testing.T) {
+	for sample, want := range map[string]float64{
+		"":     0,
+		"10%":  10,
+		"2.5%": 2.5,
+		"50":   50,
+		"100%": 100,
+	} {
+		got, err := TableConfig{Sample: sample}.SamplePercent()
+		if err != nil || got != want {
+			t.Errorf("%q: got %g, %v, want %g", sample, got, err, want)
+		}
+	}
+
+	for _, sample := range []string{"0%", "101%", "-5%", "ten%"} {
+		if _, err := (TableConfig{Sample: sample}).SamplePercent(); err == nil {
+			t.Errorf("%q: expected an error", sample)
+		}
+	}
+}
+
+// TestTablesValidation tests the validation of the tables subset
+func TestTablesValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables []TableConfig
+		errMsg string
+	}{
+		{
+			name: "valid",
+			tables: []TableConfig{
+				{Table: "public.events", Sample: "10%", Limit: 1000},
+				{Table: "public.users", Limit: 100},
+			},
+		},
+		{
+			name:   "column name",
+			tables: []TableConfig{{Table: "public.users.email", Limit: 1}},
+			errMsg: "must be in schema.table format",
+		},
+		{
+			name:   "wildcard",
+			tables: []TableConfig{{Table: "public.*", Limit: 1}},
+			errMsg: "cannot contain wildcards",
+		},
+		{
+			name: "duplicate",
+			tables: []TableConfig{
+				{Table: "public.users", Limit: 1},
+				{Table: "public.users", Sample: "5%"},
+			},
+			errMsg: "listed more than once",
+		},
+		{
+			name:   "bad sample",
+			tables: []TableConfig{{Table: "public.users", Sample: "150%"}},
+			errMsg: "sample must be a percentage",
+		},
+		{
+			name:   "negative limit",
+			tables: []TableConfig{{Table: "public.users", Limit: -1}},
+			errMsg: "limit must not be negative",
+		},
+		{
+			name:   "no subset",
+			tables: []TableConfig{{Table: "public.users"}},
+			errMsg: "'sample' or 'limit' is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{
+					{Column: "public.users.email", Pattern: "EMAIL"},
+				},
+				Tables: tt.tables,
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+)
+
+// Defaults for ThroughputConfig.
+const (
+	DefaultThroughputRuns     = 5
+	DefaultThroughputSlowdown = 2.0
+)
+
+// ThroughputConfig configures the warnings of columns anonymized much
+// more slowly than expected, surfacing regressions in the database or its
+// infrastructure during scheduled runs. A column's expected rate is its
+// expected_rows_per_second, or else, if Learn is set, its average rate in
+// the latest runs recorded in the audit file or table.
+type ThroughputConfig struct {
+	// Learn takes the expected rates from the audit records of earlier
+	// runs of the same database.
+	Learn bool `yaml:"learn,omitempty" mapstructure:"learn"`
+
+	// Runs is the number of earlier runs averaged; zero uses
+	// DefaultThroughputRuns.
+	Runs int `yaml:"runs,omitempty" mapstructure:"runs"`
+
+	// Slowdown is how many times slower than expected a column must be
+	// for a warning; zero uses DefaultThroughputSlowdown.
+	Slowdown float64 `yaml:"slowdown,omitempty" mapstructure:"slowdown"`
+}
+
+// RunsOrDefault returns the number of earlier runs averaged.
+func (t ThroughputConfig) RunsOrDefault() int {
+	if t.Runs > 0 {
+		return t.Runs
+	}
+	return DefaultThroughputRuns
+}
+
+// SlowdownOrDefault returns how many times slower than expected a column
+// must be for a warning.
+func (t ThroughputConfig) SlowdownOrDefault() float64 {
+	if t.Slowdown > 0 {
+		return t.Slowdown
+	}
+	return DefaultThroughputSlowdown
+}
+
+// validateThroughput returns the problems with the throughput settings.
+func (c *Config) validateThroughput() []string {
+	var errs []string
+
+	if c.Throughput.Learn && !c.Audit.Enabled() {
+		errs = append(errs, "throughput.learn requires audit.file or "+
+			"audit.table, from which earlier runs are read")
+	}
+	if c.Throughput.Runs < 0 {
+		errs = append(errs, "throughput.runs must not be negative")
+	}
+	if c.Throughput.Slowdown != 0 && c.Throughput.Slowdown <= 1 {
+		errs = append(errs, fmt.Sprintf(
+			"throughput.slowdown must be greater than 1, got %g",
+			c.Throughput.Slowdown))
+	}
+	for i, col := range c.Columns {
+		if col.ExpectedRowsPerSecond < 0 {
+			errs = append(errs, fmt.Sprintf(
+				"column[%d]: expected_rows_per_second must not be negative", i))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,75 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+// TestThroughputValidation tests the validation of throughput settings
+func TestThroughputValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		throughput ThroughputConfig
+		audit      AuditConfig
+		expected   float64
+		errMsg     string
+	}{
+		{
+			name:       "learn from audit",
+			throughput: ThroughputConfig{Learn: true, Runs: 3, Slowdown: 1.5},
+			audit:      AuditConfig{File: "audit.jsonl"},
+			expected:   50000,
+		},
+		{
+			name:       "learn without audit",
+			throughput: ThroughputConfig{Learn: true},
+			errMsg:     "throughput.learn requires audit.file or audit.table",
+		},
+		{
+			name:       "slowdown too small",
+			throughput: ThroughputConfig{Slowdown: 0.5},
+			errMsg:     "throughput.slowdown must be greater than 1",
+		},
+		{
+			name:     "negative expected rate",
+			expected: -1,
+			errMsg:   "expected_rows_per_second must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Database: DatabaseConfig{Database: "mydb", User: "myuser"},
+				Columns: []ColumnConfig{{Column: "public.users.email",
+					Pattern: "EMAIL", ExpectedRowsPerSecond: tt.expected}},
+				Throughput: tt.throughput,
+				Audit:      tt.audit,
+			}
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	var defaults ThroughputConfig
+	if defaults.RunsOrDefault() != DefaultThroughputRuns ||
+		defaults.SlowdownOrDefault() != DefaultThroughputSlowdown {
+		t.Error("unexpected defaults")
+	}
+}
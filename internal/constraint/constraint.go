@@ -0,0 +1,557 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package constraint evaluates simple CHECK constraints against generated
+// values, so that values can be regenerated or adjusted to satisfy them
+// before they are written to the database.
+package constraint
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnsupported is returned by Parse for expressions it cannot evaluate.
+var ErrUnsupported = errors.New("unsupported constraint expression")
+
+// Constraint is a parsed CHECK constraint on a single column: a
+// conjunction of predicates on the column's value or its length. The
+// labels of an enum type are also a Constraint, made by NewEnum.
+type Constraint struct {
+	Name       string
+	Expression string
+	predicates []predicate
+	labels     []string // The labels of an enum type
+}
+
+// predicateKind identifies what a predicate tests.
+type predicateKind int
+
+const (
+	kindCompare predicateKind = iota // value compared with a literal
+	kindLength                       // length compared with a number
+	kindMatch                        // value matched by LIKE or a regex
+	kindLabel                        // value one of an enum's labels
+)
+
+// predicate is a single test from a constraint expression.
+type predicate struct {
+	kind   predicateKind
+	op     string  // Comparison operator, for compare and length
+	number float64 // Numeric literal, for compare and length
+	text   string  // String literal, for compare with = or <>
+	isText bool    // Whether a compare uses text rather than number
+	re     *regexp.Regexp
+	negate bool   // NOT LIKE / !~
+	like   string // LIKE pattern, if the match came from LIKE
+	labels map[string]bool
+}
+
+// comparison operators, longest first so that <= is found before <
+var compareOps = []string{"<=", ">=", "<>", "!=", "<", ">", "="}
+
+// match operators, longest first
+var matchOps = []string{"!~~*", "!~~", "~~*", "!~*", "~~", "~*", "!~", "~"}
+
+// Parse parses a CHECK constraint expression, as shown by pg_get_expr, on
+// the named column. Supported predicates, which may be combined with AND,
+// are comparisons of the column with numbers (age >= 0), equality with a
+// string, length comparisons (char_length(code) <= 8), LIKE and ILIKE
+// patterns, and regular expression matches. ErrUnsupported is returned
+// for anything else.
+func Parse(name, expression, column string) (*Constraint, error) {
+	c := &Constraint{Name: name, Expression: expression}
+
+	for _, part := range splitTopLevel(stripParens(expression), " AND ") {
+		p, err := parsePredicate(stripParens(part), column)
+		if err != nil {
+			return nil, err
+		}
+		c.predicates = append(c.predicates, p)
+	}
+
+	return c, nil
+}
+
+// NewEnum returns a constraint satisfied by the labels of an enum type.
+func NewEnum(name string, labels []string) *Constraint {
+	p := predicate{kind: kindLabel, labels: make(map[string]bool, len(labels))}
+	for _, label := range labels {
+		p.labels[label] = true
+	}
+	return &Constraint{Name: name, predicates: []predicate{p},
+		labels: labels}
+}
+
+// String describes the constraint for messages, as a CHECK constraint and
+// its expression, or an enum type and its labels.
+func (c *Constraint) String() string {
+	if c.labels != nil {
+		quoted := make([]string, len(c.labels))
+		for i, label := range c.labels {
+			quoted[i] = "'" + strings.ReplaceAll(label, "'", "''") + "'"
+		}
+		return "enum type " + c.Name + " (" + strings.Join(quoted, ", ") + ")"
+	}
+	return "CHECK constraint " + c.Name + ": " + c.Expression
+}
+
+// Check returns true if the value satisfies the constraint.
+func (c *Constraint) Check(value string) bool {
+	for _, p := range c.predicates {
+		if !p.check(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Repair adjusts a value that violates the constraint where a predicate
+// allows it: values are truncated to a maximum length, given the fixed
+// prefix or suffix of a LIKE pattern (keeping the local part of an email
+// address for a pattern such as '%@example.com'), or clamped to a numeric
+// range, and values differing from an enum label only in case are given
+// the label's. The result may still violate the constraint.
+func (c *Constraint) Repair(value string) string {
+	for _, p := range c.predicates {
+		if !p.check(value) {
+			value = p.repair(value)
+		}
+	}
+	return value
+}
+
+// parsePredicate parses a single predicate.
+func parsePredicate(expr, column string) (predicate, error) {
+	if op, left, right, ok := findOperator(expr, matchOps); ok {
+		if !isColumn(left, column) {
+			return predicate{}, ErrUnsupported
+		}
+		literal, ok := parseString(right)
+		if !ok {
+			return predicate{}, ErrUnsupported
+		}
+		return matchPredicate(op, literal)
+	}
+
+	op, left, right, ok := findOperator(expr, compareOps)
+	if !ok {
+		return predicate{}, ErrUnsupported
+	}
+	if op == "!=" {
+		op = "<>"
+	}
+
+	// Put the column on the left, flipping the operator if needed
+	if !refersTo(left, column) && refersTo(right, column) {
+		left, right = right, left
+		op = flip(op)
+	}
+
+	if inner, ok := lengthArg(left); ok && isColumn(inner, column) {
+		n, ok := parseNumber(right)
+		if !ok {
+			return predicate{}, ErrUnsupported
+		}
+		return predicate{kind: kindLength, op: op, number: n}, nil
+	}
+
+	if !isColumn(left, column) {
+		return predicate{}, ErrUnsupported
+	}
+	if n, ok := parseNumber(right); ok {
+		return predicate{kind: kindCompare, op: op, number: n}, nil
+	}
+	if s, ok := parseString(right); ok && (op == "=" || op == "<>") {
+		// Ordering of strings depends on the collation, so only equality
+		// is evaluated
+		return predicate{kind: kindCompare, op: op, text: s, isText: true}, nil
+	}
+
+	return predicate{}, ErrUnsupported
+}
+
+// matchPredicate builds a LIKE or regular expression predicate.
+func matchPredicate(op, literal string) (predicate, error) {
+	p := predicate{kind: kindMatch, negate: strings.HasPrefix(op, "!")}
+	op = strings.TrimPrefix(op, "!")
+	insensitive := strings.HasSuffix(op, "*")
+
+	var pattern string
+	if strings.HasPrefix(op, "~~") {
+		p.like = literal
+		pattern = likeToRegexp(literal)
+	} else {
+		pattern = literal
+	}
+	if insensitive {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		// PostgreSQL regular expressions that Go cannot compile
+		return predicate{}, ErrUnsupported
+	}
+	p.re = re
+	return p, nil
+}
+
+// check evaluates the predicate against a value.
+func (p predicate) check(value string) bool {
+	switch p.kind {
+	case kindLength:
+		return compareNumbers(float64(utf8.RuneCountInString(value)), p.op,
+			p.number)
+	case kindMatch:
+		return p.re.MatchString(value) != p.negate
+	case kindLabel:
+		return p.labels[value]
+	default:
+		if p.isText {
+			return (value == p.text) == (p.op == "=")
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return false
+		}
+		return compareNumbers(n, p.op, p.number)
+	}
+}
+
+// repair adjusts a value to satisfy the predicate, where possible.
+func (p predicate) repair(value string) string {
+	switch p.kind {
+	case kindLength:
+		limit := int(p.number)
+		switch p.op {
+		case "<":
+			limit--
+		case "<=", "=":
+		default:
+			return value
+		}
+		if limit >= 0 && utf8.RuneCountInString(value) > limit {
+			return string([]rune(value)[:limit])
+		}
+		return value
+
+	case kindMatch:
+		if p.like == "" || p.negate {
+			return value
+		}
+		return repairLike(value, p.like)
+
+	case kindLabel:
+		for label := range p.labels {
+			if strings.EqualFold(label, strings.TrimSpace(value)) {
+				return label
+			}
+		}
+		return value
+
+	default:
+		if p.isText {
+			if p.op == "=" {
+				return p.text
+			}
+			return value
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return value
+		}
+		integral := n == math.Trunc(n) && p.number == math.Trunc(p.number)
+		bound := p.number
+		switch p.op {
+		case "<":
+			if !integral {
+				return value
+			}
+			bound--
+		case ">":
+			if !integral {
+				return value
+			}
+			bound++
+		case "<=", ">=", "=":
+		default:
+			return value
+		}
+		return strconv.FormatFloat(bound, 'f', -1, 64)
+	}
+}
+
+// repairLike fits a value to a LIKE pattern with at most one % wildcard
+// and no _ wildcards, such as 'ACME-%' or '%@example.com'.
+func repairLike(value, pattern string) string {
+	parts := splitLike(pattern)
+	if parts == nil {
+		return value
+	}
+	if len(parts) == 1 {
+		return parts[0] // No wildcards, so the pattern is the only value
+	}
+
+	prefix, suffix := parts[0], parts[1]
+	core := strings.TrimSuffix(strings.TrimPrefix(value, prefix), suffix)
+
+	// A fixed domain replaces the domain of an email address
+	if strings.HasPrefix(suffix, "@") {
+		if at := strings.LastIndex(core, "@"); at >= 0 {
+			core = core[:at]
+		}
+	}
+
+	return prefix + core + suffix
+}
+
+// splitLike splits a LIKE pattern at its single % wildcard, returning the
+// literal text before and after it, or the whole literal if it has no
+// wildcards. It returns nil for patterns with _ or several % wildcards.
+func splitLike(pattern string) []string {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteByte(pattern[i])
+			}
+		case '_':
+			return nil
+		case '%':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	parts = append(parts, b.String())
+	if len(parts) > 2 {
+		return nil
+	}
+	return parts
+}
+
+// likeToRegexp converts a LIKE pattern to an anchored regular expression.
+func likeToRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("(?s)^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// compareNumbers applies a comparison operator.
+func compareNumbers(a float64, op string, b float64) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "=":
+		return a == b
+	case "<>":
+		return a != b
+	}
+	return false
+}
+
+// flip returns the operator with its operands swapped.
+func flip(op string) string {
+	switch op {
+	case "<":
+		return ">"
+	case "<=":
+		return ">="
+	case ">":
+		return "<"
+	case ">=":
+		return "<="
+	}
+	return op
+}
+
+// findOperator finds the first of the given operators outside quotes and
+// parentheses, and returns the operands either side of it.
+func findOperator(expr string, ops []string) (op, left, right string,
+	found bool) {
+
+	depth := 0
+	inQuotes := false
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == '\'':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0:
+			for _, op := range ops {
+				if strings.HasPrefix(expr[i:], " "+op+" ") {
+					return op, strings.TrimSpace(expr[:i]),
+						strings.TrimSpace(expr[i+len(op)+2:]), true
+				}
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// splitTopLevel splits an expression on a separator that appears outside
+// quotes and parentheses.
+func splitTopLevel(expr, sep string) []string {
+	var parts []string
+	depth := 0
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == '\'':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(expr[i:], sep):
+			parts = append(parts, expr[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, expr[start:])
+}
+
+// stripParens removes parentheses enclosing a whole expression.
+func stripParens(expr string) string {
+	expr = strings.TrimSpace(expr)
+	for len(expr) >= 2 && expr[0] == '(' && matchingParen(expr) == len(expr)-1 {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
+
+// matchingParen returns the index of the parenthesis closing the one at
+// the start of expr, or -1.
+func matchingParen(expr string) int {
+	depth := 0
+	inQuotes := false
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == '\'':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// castRe matches a trailing type cast, such as ::text or
+// ::character varying(20).
+var castRe = regexp.MustCompile(`::[a-z][a-z0-9_ ]*(\([0-9, ]+\))?(\[\])?$`)
+
+// stripCast removes trailing type casts and enclosing parentheses.
+func stripCast(expr string) string {
+	for {
+		expr = stripParens(expr)
+		stripped := castRe.ReplaceAllString(expr, "")
+		if stripped == expr {
+			return expr
+		}
+		expr = stripped
+	}
+}
+
+// isColumn returns true if the expression is a reference to the column,
+// possibly cast to another type.
+func isColumn(expr, column string) bool {
+	expr = stripCast(expr)
+	return expr == column || expr == `"`+strings.ReplaceAll(column, `"`, `""`)+`"`
+}
+
+// refersTo returns true if the expression is the column or its length.
+func refersTo(expr, column string) bool {
+	if inner, ok := lengthArg(expr); ok {
+		return isColumn(inner, column)
+	}
+	return isColumn(expr, column)
+}
+
+// lengthArg returns the argument of a length function call.
+func lengthArg(expr string) (string, bool) {
+	expr = stripParens(expr)
+	for _, fn := range []string{"char_length(", "character_length(", "length("} {
+		if strings.HasPrefix(expr, fn) && strings.HasSuffix(expr, ")") &&
+			matchingParen(expr[len(fn)-1:]) == len(expr)-len(fn) {
+			return expr[len(fn) : len(expr)-1], true
+		}
+	}
+	return "", false
+}
+
+// parseString parses a quoted string literal, possibly cast to a type.
+func parseString(expr string) (string, bool) {
+	expr = stripCast(expr)
+	if len(expr) < 2 || expr[0] != '\'' || expr[len(expr)-1] != '\'' {
+		return "", false
+	}
+	body := expr[1 : len(expr)-1]
+	if strings.Contains(strings.ReplaceAll(body, "''", ""), "'") {
+		return "", false
+	}
+	return strings.ReplaceAll(body, "''", "'"), true
+}
+
+// parseNumber parses a numeric literal, possibly quoted or cast to a type.
+func parseNumber(expr string) (float64, bool) {
+	expr = stripCast(expr)
+	if s, ok := parseString(expr); ok {
+		expr = s
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(expr, " ", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
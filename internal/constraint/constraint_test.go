@@ -0,0 +1,158 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package constraint
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		column    string
+		supported bool
+	}{
+		{"like", "(email ~~ '%@company.com'::text)", "email", true},
+		{"like on varchar", "((email)::text ~~ '%@company.com'::text)", "email", true},
+		{"ilike", "((email)::text ~~* '%@COMPANY.COM'::text)", "email", true},
+		{"not like", "((email)::text !~~ '%@gmail.com'::text)", "email", true},
+		{"regex", "(code ~ '^[A-Z]{3}-[0-9]+$'::text)", "code", true},
+		{"length", "(char_length(name) <= 50)", "name", true},
+		{"length of varchar", "(length((code)::text) = 8)", "code", true},
+		{"range", "((age >= 0) AND (age <= 150))", "age", true},
+		{"numeric literal", "(price > (0)::numeric)", "price", true},
+		{"negative literal", "(delta >= '-10'::integer)", "delta", true},
+		{"reversed", "(0 < qty)", "qty", true},
+		{"string equality", "(status <> 'deleted'::text)", "status", true},
+		{"quoted column", `("Email" ~~ '%@x.com'::text)`, "Email", true},
+		{"combined", "((email ~~ '%@x.com'::text) AND (length(email) < 40))", "email", true},
+		{"other column", "(start_date < end_date)", "start_date", false},
+		{"or", "((age < 0) OR (age > 10))", "age", false},
+		{"is not null", "(email IS NOT NULL)", "email", false},
+		{"string ordering", "(name > 'a'::text)", "name", false},
+		{"in list", "(status = ANY (ARRAY['a'::text, 'b'::text]))", "status", false},
+		{"function", "(lower(email) = email)", "email", false},
+		{"unsupported regex", "(code ~ '(?<=a)b'::text)", "code", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("c", tt.expr, tt.column)
+			if tt.supported && err != nil {
+				t.Errorf("Parse(%q) error = %v", tt.expr, err)
+			}
+			if !tt.supported && !errors.Is(err, ErrUnsupported) {
+				t.Errorf("Parse(%q) error = %v, want ErrUnsupported", tt.expr, err)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column string
+		value  string
+		want   bool
+	}{
+		{"(email ~~ '%@company.com'::text)", "email", "a@company.com", true},
+		{"(email ~~ '%@company.com'::text)", "email", "a@example.com", false},
+		{"(email ~~* '%@COMPANY.COM'::text)", "email", "a@company.com", true},
+		{"(email !~~ '%@gmail.com'::text)", "email", "a@gmail.com", false},
+		{"(code ~~ 'A_C'::text)", "code", "ABC", true},
+		{"(code ~~ 'A_C'::text)", "code", "ABBC", false},
+		{"(code ~~ '100\\%'::text)", "code", "100%", true},
+		{"(code ~ '^[A-Z]{3}$'::text)", "code", "ABC", true},
+		{"(code ~ '^[A-Z]{3}$'::text)", "code", "AB1", false},
+		{"(code ~* '^[a-z]+$'::text)", "code", "ABC", true},
+		{"(VALUE ~ '^[0-9]{5}$'::text)", "VALUE", "02134", true},
+		{"(VALUE ~ '^[0-9]{5}$'::text)", "VALUE", "2134", false},
+		{"(char_length(name) <= 5)", "name", "Zoë A", true},
+		{"(char_length(name) <= 5)", "name", "Zoë Ab", false},
+		{"((age >= 0) AND (age <= 150))", "age", "42", true},
+		{"((age >= 0) AND (age <= 150))", "age", "151", false},
+		{"((age >= 0) AND (age <= 150))", "age", "abc", false},
+		{"(0 < qty)", "qty", "0", false},
+		{"(0 < qty)", "qty", "1", true},
+		{"(status <> 'deleted'::text)", "status", "deleted", false},
+		{"(status <> 'it''s'::text)", "status", "it's", false},
+	}
+
+	for _, tt := range tests {
+		c, err := Parse("c", tt.expr, tt.column)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got := c.Check(tt.value); got != tt.want {
+			t.Errorf("Check(%q) with %s = %v, want %v", tt.value, tt.expr, got,
+				tt.want)
+		}
+	}
+}
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name   string
+		expr   string
+		column string
+		value  string
+		want   string
+	}{
+		{"email domain", "(email ~~ '%@company.com'::text)", "email",
+			"jane.doe@example.org", "jane.doe@company.com"},
+		{"prefix", "(code ~~ 'EMP-%'::text)", "code", "12345", "EMP-12345"},
+		{"prefix already present", "(code ~~ 'EMP-%'::text)", "code",
+			"EMP-1", "EMP-1"},
+		{"truncate", "(char_length(name) <= 5)", "name", "Alexander", "Alexa"},
+		{"truncate strict", "(length(name) < 5)", "name", "Alexander", "Alex"},
+		{"clamp max", "((age >= 0) AND (age <= 150))", "age", "200", "150"},
+		{"clamp strict min", "(qty > 0)", "qty", "-3", "1"},
+		{"regex cannot be repaired", "(code ~ '^[A-Z]+$'::text)", "code",
+			"abc", "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse("c", tt.expr, tt.column)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			if got := c.Repair(tt.value); got != tt.want {
+				t.Errorf("Repair(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnum(t *testing.T) {
+	c := NewEnum("mood", []string{"happy", "sad", "it's ok"})
+
+	if !c.Check("sad") {
+		t.Error("expected a label to satisfy the enum")
+	}
+	if c.Check("angry") {
+		t.Error("expected a value that is not a label to violate the enum")
+	}
+	if got := c.Repair("HAPPY"); got != "happy" {
+		t.Errorf("Repair(%q) = %q, want %q", "HAPPY", got, "happy")
+	}
+	if got := c.Repair("angry"); got != "angry" {
+		t.Errorf("Repair(%q) = %q, want it unchanged", "angry", got)
+	}
+
+	want := "enum type mood ('happy', 'sad', 'it''s ok')"
+	if got := c.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
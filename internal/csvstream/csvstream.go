@@ -0,0 +1,223 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package csvstream anonymizes fields of CSV streams with the anonymizer's
+// generators, so that pipelines handling data outside PostgreSQL can
+// reuse its patterns.
+package csvstream
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// Column is a field of each record to anonymize, and the pattern to
+// anonymize it with.
+type Column struct {
+	// Index is the position of the field, from zero, or -1 until a field
+	// named by Name is found in the header.
+	Index int
+
+	// Name is the header of the field, if it was selected by name.
+	Name string
+
+	Pattern string
+}
+
+// ParseColumns parses column selections of the form FIELD=PATTERN, where
+// FIELD is the position of a field, from 1, or the name of a field in the
+// header.
+func ParseColumns(specs []string) ([]Column, error) {
+	columns := make([]Column, 0, len(specs))
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		field, pattern, ok := strings.Cut(spec, "=")
+		field = strings.TrimSpace(field)
+		pattern = strings.TrimSpace(pattern)
+		if !ok || field == "" || pattern == "" {
+			return nil, fmt.Errorf("column %q must be in FIELD=PATTERN "+
+				"format, such as 3=EMAIL", spec)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("column %s is listed more than once", field)
+		}
+		seen[field] = true
+
+		col := Column{Index: -1, Pattern: pattern}
+		if n, err := strconv.Atoi(field); err == nil {
+			if n < 1 {
+				return nil, fmt.Errorf("column %q: field positions start "+
+					"at 1", spec)
+			}
+			col.Index = n - 1
+		} else {
+			col.Name = field
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// Dictionary maps original values to the values they were anonymized to,
+// so that equal values are replaced alike throughout the stream.
+type Dictionary interface {
+	Get(original string) (string, bool)
+	Set(original, anonymized string)
+}
+
+// Options configures how a stream is read and written.
+type Options struct {
+	Header    bool // The first record is a header, which is copied as is
+	Delimiter rune // The field delimiter, or zero for a comma
+}
+
+// Anonymizer anonymizes the selected fields of CSV records.
+type Anonymizer struct {
+	columns      []Column
+	generators   []generator.Generator
+	dictionaries []Dictionary
+	opts         Options
+}
+
+// New returns an anonymizer for the given columns, looking up the
+// generator of each column's pattern with get, and the dictionary it
+// keeps its values in with dict.
+func New(columns []Column, get func(pattern string) (generator.Generator,
+	bool), dict func(col Column) Dictionary,
+	opts Options) (*Anonymizer, error) {
+
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	if opts.Delimiter == '"' || opts.Delimiter == '\r' ||
+		opts.Delimiter == '\n' || !utf8.ValidRune(opts.Delimiter) {
+		return nil, fmt.Errorf("invalid delimiter %q", opts.Delimiter)
+	}
+
+	a := &Anonymizer{
+		columns:      columns,
+		generators:   make([]generator.Generator, len(columns)),
+		dictionaries: make([]Dictionary, len(columns)),
+		opts:         opts,
+	}
+	for i, col := range columns {
+		if col.Name != "" && !opts.Header {
+			return nil, fmt.Errorf("column %s is selected by name, which "+
+				"requires a header", col.Name)
+		}
+		gen, ok := get(col.Pattern)
+		if !ok {
+			return nil, fmt.Errorf("unknown pattern %q (see "+
+				"pgedge-anonymizer patterns list)", col.Pattern)
+		}
+		a.generators[i] = gen
+		a.dictionaries[i] = dict(col)
+	}
+	return a, nil
+}
+
+// Stream reads CSV records from r and writes them to w with the selected
+// fields anonymized, one record at a time, and returns the number of
+// records anonymized, not counting the header. Empty fields are left
+// empty, as NULLs are in the database.
+func (a *Anonymizer) Stream(r io.Reader, w io.Writer) (int64, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = a.opts.Delimiter
+	reader.ReuseRecord = true
+
+	writer := csv.NewWriter(w)
+	writer.Comma = a.opts.Delimiter
+
+	columns := a.columns
+	var rows int64
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rows, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		if first && a.opts.Header {
+			if columns, err = resolveNames(columns, record); err != nil {
+				return rows, err
+			}
+			if err := writer.Write(record); err != nil {
+				return rows, fmt.Errorf("failed to write CSV: %w", err)
+			}
+			continue
+		}
+
+		for i, col := range columns {
+			if col.Index >= len(record) {
+				line, _ := reader.FieldPos(0)
+				return rows, fmt.Errorf("line %d: field %d not found in a "+
+					"record of %d fields", line, col.Index+1, len(record))
+			}
+			record[col.Index] = a.anonymize(i, record[col.Index])
+		}
+		if err := writer.Write(record); err != nil {
+			return rows, fmt.Errorf("failed to write CSV: %w", err)
+		}
+		rows++
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return rows, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return rows, nil
+}
+
+// anonymize returns the anonymized value of a field of a column: the
+// value it is mapped to in the column's dictionary, or else a newly
+// generated value, which is then added to the dictionary.
+func (a *Anonymizer) anonymize(column int, value string) string {
+	if value == "" {
+		return value
+	}
+	dict := a.dictionaries[column]
+	if anonymized, ok := dict.Get(value); ok {
+		return anonymized
+	}
+	anonymized := a.generators[column].Generate(value)
+	dict.Set(value, anonymized)
+	return anonymized
+}
+
+// resolveNames returns the columns with those selected by name given the
+// position of the field of that name in the header.
+func resolveNames(columns []Column, header []string) ([]Column, error) {
+	resolved := make([]Column, len(columns))
+	for i, col := range columns {
+		resolved[i] = col
+		if col.Name == "" {
+			continue
+		}
+		for j, name := range header {
+			if name == col.Name {
+				resolved[i].Index = j
+				break
+			}
+		}
+		if resolved[i].Index < 0 {
+			return nil, fmt.Errorf("column %s not found in the header",
+				col.Name)
+		}
+	}
+	return resolved, nil
+}
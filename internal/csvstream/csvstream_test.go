@@ -0,0 +1,155 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package csvstream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// mapDictionary is an in-memory dictionary
+type mapDictionary map[string]string
+
+func (d mapDictionary) Get(original string) (string, bool) {
+	v, ok := d[original]
+	return v, ok
+}
+
+func (d mapDictionary) Set(original, anonymized string) {
+	d[original] = anonymized
+}
+
+// counter generates numbered values, so that tests can tell them apart
+type counter struct {
+	prefix string
+	n      int
+}
+
+func (g *counter) Name() string { return g.prefix }
+
+func (g *counter) Generate(input string) string {
+	g.n++
+	return g.prefix + strings.Repeat("*", g.n)
+}
+
+func newTestAnonymizer(t *testing.T, specs []string,
+	opts Options) *Anonymizer {
+	t.Helper()
+	columns, err := ParseColumns(specs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gens := map[string]generator.Generator{
+		"EMAIL": &counter{prefix: "e"},
+		"PHONE": &counter{prefix: "p"},
+	}
+	dict := mapDictionary{}
+	a, err := New(columns, func(name string) (generator.Generator, bool) {
+		g, ok := gens[name]
+		return g, ok
+	}, func(Column) Dictionary { return dict }, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return a
+}
+
+// TestParseColumns tests parsing column selections
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns([]string{"3=EMAIL", "phone = US_PHONE"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if columns[0] != (Column{Index: 2, Pattern: "EMAIL"}) ||
+		columns[1] != (Column{Index: -1, Name: "phone", Pattern: "US_PHONE"}) {
+		t.Errorf("unexpected columns: %+v", columns)
+	}
+
+	for _, specs := range [][]string{
+		{"3"},
+		{"=EMAIL"},
+		{"0=EMAIL"},
+		{"2=EMAIL", "2=US_PHONE"},
+	} {
+		if _, err := ParseColumns(specs); err == nil {
+			t.Errorf("%v: expected an error", specs)
+		}
+	}
+}
+
+// TestStream tests anonymizing the fields of a CSV stream
+func TestStream(t *testing.T) {
+	t.Run("positions", func(t *testing.T) {
+		a := newTestAnonymizer(t, []string{"2=EMAIL", "3=PHONE"}, Options{})
+		in := "1,a@x.com,555\n2,b@x.com,\n3,a@x.com,\"5,6\"\n"
+
+		var out strings.Builder
+		rows, err := a.Stream(strings.NewReader(in), &out)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "1,e*,p*\n2,e**,\n3,e*,p**\n"
+		if rows != 3 || out.String() != want {
+			t.Errorf("got %d rows:\n%s\nwant:\n%s", rows, out.String(), want)
+		}
+	})
+
+	t.Run("header names", func(t *testing.T) {
+		a := newTestAnonymizer(t, []string{"email=EMAIL"},
+			Options{Header: true, Delimiter: ';'})
+		in := "id;email\n1;a@x.com\n"
+
+		var out strings.Builder
+		rows, err := a.Stream(strings.NewReader(in), &out)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := "id;email\n1;e*\n"; rows != 1 || out.String() != want {
+			t.Errorf("got %d rows:\n%s\nwant:\n%s", rows, out.String(), want)
+		}
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		a := newTestAnonymizer(t, []string{"4=EMAIL"}, Options{})
+		_, err := a.Stream(strings.NewReader("1,2,3\n"), &strings.Builder{})
+		if err == nil || !strings.Contains(err.Error(), "field 4 not found") {
+			t.Errorf("expected a missing field error, got %v", err)
+		}
+	})
+
+	t.Run("missing header", func(t *testing.T) {
+		a := newTestAnonymizer(t, []string{"mail=EMAIL"}, Options{Header: true})
+		_, err := a.Stream(strings.NewReader("id,email\n"), &strings.Builder{})
+		if err == nil || !strings.Contains(err.Error(), "not found in the header") {
+			t.Errorf("expected a missing header error, got %v", err)
+		}
+	})
+}
+
+// TestNew tests the checks made creating an anonymizer
+func TestNew(t *testing.T) {
+	get := func(string) (generator.Generator, bool) { return nil, false }
+	dict := func(Column) Dictionary { return mapDictionary{} }
+
+	if _, err := New([]Column{{Index: 0, Pattern: "NOPE"}}, get, dict,
+		Options{}); err == nil {
+		t.Error("expected an error for an unknown pattern")
+	}
+	if _, err := New([]Column{{Index: -1, Name: "email", Pattern: "EMAIL"}},
+		get, dict, Options{}); err == nil {
+		t.Error("expected an error for a name without a header")
+	}
+	if _, err := New(nil, get, dict, Options{Delimiter: '"'}); err == nil {
+		t.Error("expected an error for a quote delimiter")
+	}
+}
@@ -14,7 +14,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math/rand/v2"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pgedge/pgedge-anonymizer/internal/errors"
 )
@@ -22,10 +25,33 @@ import (
 // DefaultBatchSize is the default number of rows to process in a batch.
 const DefaultBatchSize = 10000
 
+// DefaultMaxStatementBytes is the default limit on the size of a batch
+// update statement. A batch whose values would exceed it is written with
+// several statements, so that batches of large values, such as JSONB
+// documents, do not exceed the server's limits on the size of a message.
+const DefaultMaxStatementBytes = 64 << 20
+
+// CommitFunc commits the transaction a batch processor runs in, and
+// returns the transaction to continue in.
+type CommitFunc func(ctx context.Context) (*sql.Tx, error)
+
 // RowData represents a row fetched for processing.
 type RowData struct {
 	CTID  string // PostgreSQL physical row ID
 	Value string // The column value to anonymize
+
+	// Identity is the value of the identity column, if the processor has
+	// one, or empty if it is NULL; a row identified by several columns has
+	// them as a row value, such as "(1,2)"
+	Identity string
+
+	// size is the size of the value's text, if the processor has a
+	// limit on it
+	size int64
+
+	// Sources are the values of the source columns, if the processor has
+	// any, each empty if it is NULL
+	Sources []string
 }
 
 // BatchProcessor handles batch reading and writing for a table column.
@@ -35,6 +61,49 @@ type BatchProcessor struct {
 	dataType  string
 	batchSize int
 
+	// Rows read by each FETCH, and the most rows written by each update
+	// statement; zero reads or writes a whole batch at once
+	fetchSize       int
+	updateChunkSize int
+
+	// Statement size limit, and the size of the largest statement written
+	maxStatementBytes int64
+	statementSizes    statementSizes
+	phases            PhaseTimes
+
+	// Primary key to page through the table by, and the key of the last
+	// row read, for the keyset strategy
+	keyColumns []KeyColumn
+	lastKey    []string
+
+	// Copier and staging table for the copy strategy
+	copier  Copier
+	staging string
+
+	// Commits the transaction between batches, if set
+	commit CommitFunc
+
+	// Limits the rate at which rows are read, if set
+	throttle *Throttle
+
+	// Columns read with each row to identify whom it belongs to, if set
+	identity []string
+
+	// Other columns read with each row, if set
+	sources []string
+
+	// Largest value read, in bytes of its text, and what is done with
+	// larger ones, if set
+	maxValueBytes int64
+	oversized     OversizedFunc
+
+	// Reads only a random sample of the rows, if set
+	sample tableSample
+
+	// Reads and updates the table's own rows only, and not those of the
+	// tables inheriting from it, if set
+	only bool
+
 	// Cursor state
 	cursorName string
 	cursorOpen bool
@@ -56,18 +125,196 @@ func NewBatchProcessor(tx *sql.Tx, col errors.ColumnRef, dataType string,
 	}
 }
 
-// OpenCursor declares a server-side cursor for reading rows.
+// SetFetchSize sets the number of rows read from the cursor by each FETCH;
+// zero or less reads a whole batch with a single FETCH.
+func (p *BatchProcessor) SetFetchSize(n int) {
+	p.fetchSize = n
+}
+
+// SetUpdateChunkSize sets the most rows written by each batch update
+// statement; zero or less writes a whole batch with a single statement,
+// subject to the limit on its size.
+func (p *BatchProcessor) SetUpdateChunkSize(n int) {
+	p.updateChunkSize = n
+}
+
+// SetKeyset makes the processor read rows in batches ordered by the
+// table's primary key, each starting after the last key read, rather than
+// with a cursor. The key must not include the column being anonymized.
+func (p *BatchProcessor) SetKeyset(key []KeyColumn) {
+	p.keyColumns = key
+}
+
+// SetCopy makes the processor copy new values to a temporary staging
+// table, rather than updating each batch, and update the column from the
+// staging table with a single statement when Finish is called.
+func (p *BatchProcessor) SetCopy(copier Copier) {
+	p.copier = copier
+}
+
+// SetCommit makes EndBatch commit the transaction after each batch, and
+// continue in the transaction commit returns. The cursor is then declared
+// WITH HOLD, so that it remains open across the commits.
+func (p *BatchProcessor) SetCommit(commit CommitFunc) {
+	p.commit = commit
+}
+
+// SetThrottle limits the rate at which rows are read, and pauses after
+// each batch, as the throttle sets.
+func (p *BatchProcessor) SetThrottle(throttle *Throttle) {
+	p.throttle = throttle
+}
+
+// SetIdentity makes the processor read the value of other columns of the
+// table with each row, as RowData.Identity, such as the owner of the row
+// or its primary key.
+func (p *BatchProcessor) SetIdentity(columns ...string) {
+	p.identity = columns
+}
+
+// SetSources makes the processor read the values of other columns of the
+// table with each row, as RowData.Sources, such as the columns a value is
+// derived from.
+func (p *BatchProcessor) SetSources(columns ...string) {
+	p.sources = columns
+}
+
+// SetSample makes the processor read only a random sample of about
+// percent of the table's rows; zero or less, or 100 or more, reads them
+// all.
+func (p *BatchProcessor) SetSample(percent float64) {
+	p.sample = newTableSample(percent)
+}
+
+// SetOnly makes the processor read and update only the rows of the table
+// itself, and not those of the tables inheriting from it, which are
+// anonymized as tables of their own.
+func (p *BatchProcessor) SetOnly(only bool) {
+	p.only = only
+}
+
+// OversizedFunc is called with the row and size of each value larger than
+// the limit set with SetMaxValueBytes. The row is left out of its batch,
+// so that its value is left unchanged, unless it returns an error.
+type OversizedFunc func(ctid string, bytes int64) error
+
+// SetMaxValueBytes limits the size of the values read, in bytes of their
+// text. A larger value is not read from the server, so that it cannot
+// exhaust the memory of the run, and its row is passed to oversized;
+// zero or less reads values of any size.
+func (p *BatchProcessor) SetMaxValueBytes(n int64, oversized OversizedFunc) {
+	p.maxValueBytes = n
+	p.oversized = oversized
+}
+
+// table returns the table's quoted name, with ONLY if it is set.
+func (p *BatchProcessor) table() string {
+	return tableName(p.column, p.only)
+}
+
+// selectList returns the columns read for each row: its ctid, the value of
+// the column, and its size if values are limited, the identity column, if
+// set, and the source columns. A value over the limit is read as empty.
+func (p *BatchProcessor) selectList() string {
+	value := p.textExpr(quoteIdent(p.column.Column))
+	list := "ctid::text, " + value
+	if p.maxValueBytes > 0 {
+		list = fmt.Sprintf(
+			"ctid::text, CASE WHEN octet_length(%[1]s) > %[2]d THEN '' "+
+				"ELSE %[1]s END, octet_length(%[1]s)", value, p.maxValueBytes)
+	}
+	switch len(p.identity) {
+	case 0:
+	case 1:
+		list += fmt.Sprintf(", COALESCE(%s::text, '')", quoteIdent(p.identity[0]))
+	default:
+		quoted := make([]string, len(p.identity))
+		for i, col := range p.identity {
+			quoted[i] = quoteIdent(col)
+		}
+		list += fmt.Sprintf(", ROW(%s)::text", strings.Join(quoted, ", "))
+	}
+	for _, col := range p.sources {
+		list += fmt.Sprintf(", COALESCE(%s::text, '')", quoteIdent(col))
+	}
+	return list
+}
+
+// scanDest returns the destinations of the columns of selectList.
+func (p *BatchProcessor) scanDest(rd *RowData) []any {
+	dest := []any{&rd.CTID, &rd.Value}
+	if p.maxValueBytes > 0 {
+		dest = append(dest, &rd.size)
+	}
+	if len(p.identity) > 0 {
+		dest = append(dest, &rd.Identity)
+	}
+	if len(p.sources) > 0 {
+		rd.Sources = make([]string, len(p.sources))
+		for i := range rd.Sources {
+			dest = append(dest, &rd.Sources[i])
+		}
+	}
+	return dest
+}
+
+// EndBatch commits the transaction after a batch has been written, if the
+// processor commits between batches, and then pauses if it is throttled.
+func (p *BatchProcessor) EndBatch(ctx context.Context) error {
+	if p.commit != nil {
+		start := time.Now()
+		tx, err := p.commit(ctx)
+		since(&p.phases.Update, start)
+		if err != nil {
+			return err
+		}
+		p.tx = tx
+	}
+	defer since(&p.phases.Wait, time.Now())
+	return p.throttle.Pause(ctx)
+}
+
+// SetMaxStatementBytes sets the limit on the size of a batch update
+// statement; zero or less uses DefaultMaxStatementBytes.
+func (p *BatchProcessor) SetMaxStatementBytes(n int64) {
+	p.maxStatementBytes = n
+}
+
+// MaxStatementBytes returns the size of the largest batch update
+// statement written, counting the query and its parameters.
+func (p *BatchProcessor) MaxStatementBytes() int64 {
+	return p.statementSizes.max
+}
+
+// PhaseTimes returns the time spent reading rows, writing them back, and
+// throttled.
+func (p *BatchProcessor) PhaseTimes() PhaseTimes {
+	return p.phases
+}
+
+// OpenCursor declares a server-side cursor for reading rows. With the
+// keyset strategy, no cursor is needed, and rows are read from the start
+// of the key.
 func (p *BatchProcessor) OpenCursor(ctx context.Context) error {
+	defer since(&p.phases.Fetch, time.Now())
+
+	if p.keyColumns != nil {
+		p.lastKey = nil
+		p.cursorOpen = true
+		return nil
+	}
+
 	// Use ctid for efficient updates
 	query := fmt.Sprintf(
-		`DECLARE %s CURSOR FOR
-         SELECT ctid::text, %s::text
-         FROM %s.%s
+		`DECLARE %s CURSOR %sFOR
+         SELECT %s
+         FROM %s%s
          WHERE %s IS NOT NULL`,
 		p.cursorName,
-		quoteIdent(p.column.Column),
-		quoteIdent(p.column.Schema),
-		quoteIdent(p.column.Table),
+		cursorHold(p.commit != nil),
+		p.selectList(),
+		p.table(),
+		p.sample.clause(),
 		quoteIdent(p.column.Column),
 	)
 
@@ -81,14 +328,67 @@ func (p *BatchProcessor) OpenCursor(ctx context.Context) error {
 	return nil
 }
 
-// FetchBatch fetches the next batch of rows from the cursor.
+// FetchBatch fetches the next batch of rows from the cursor, with as many
+// FETCH statements as the fetch size requires.
 func (p *BatchProcessor) FetchBatch(ctx context.Context) ([]RowData, error) {
 	if !p.cursorOpen {
 		return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
 			"cursor not open", nil)
 	}
 
-	query := fmt.Sprintf("FETCH %d FROM %s", p.batchSize, p.cursorName)
+	for {
+		rows, err := fetchBatch(p.batchSize, p.fetchSize,
+			func(n int) ([]RowData, error) {
+				rows, err := p.fetch(ctx, n)
+				if err != nil {
+					return nil, err
+				}
+				defer since(&p.phases.Wait, time.Now())
+				return rows, p.throttle.Wait(ctx, len(rows))
+			})
+		if err != nil || len(rows) == 0 {
+			return rows, err
+		}
+
+		// A batch of nothing but oversized values is not the last
+		rows, err = p.dropOversized(rows)
+		if err != nil || len(rows) > 0 {
+			return rows, err
+		}
+	}
+}
+
+// dropOversized returns the rows of a batch whose values are within the
+// limit on their size, passing the others to the oversized function.
+func (p *BatchProcessor) dropOversized(rows []RowData) ([]RowData, error) {
+	if p.maxValueBytes <= 0 {
+		return rows, nil
+	}
+
+	kept := rows[:0]
+	for _, rd := range rows {
+		if rd.size <= p.maxValueBytes {
+			kept = append(kept, rd)
+			continue
+		}
+		if p.oversized != nil {
+			if err := p.oversized(rd.CTID, rd.size); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return kept, nil
+}
+
+// fetch reads up to n rows from the cursor.
+func (p *BatchProcessor) fetch(ctx context.Context, n int) ([]RowData, error) {
+	defer since(&p.phases.Fetch, time.Now())
+
+	if p.keyColumns != nil {
+		return p.fetchByKey(ctx, n)
+	}
+
+	query := fmt.Sprintf("FETCH %d FROM %s", n, p.cursorName)
 	rows, err := p.tx.QueryContext(ctx, query)
 	if err != nil {
 		return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
@@ -99,7 +399,77 @@ func (p *BatchProcessor) FetchBatch(ctx context.Context) ([]RowData, error) {
 	var batch []RowData
 	for rows.Next() {
 		var rd RowData
-		if err := rows.Scan(&rd.CTID, &rd.Value); err != nil {
+		if err := rows.Scan(p.scanDest(&rd)...); err != nil {
+			return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+				fmt.Sprintf("failed to scan row: %v", err), err)
+		}
+		batch = append(batch, rd)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+			fmt.Sprintf("error iterating rows: %v", err), err)
+	}
+
+	return batch, nil
+}
+
+// fetchByKey reads up to n rows following the last key read, in key
+// order.
+func (p *BatchProcessor) fetchByKey(ctx context.Context,
+	n int) ([]RowData, error) {
+
+	keys := make([]string, len(p.keyColumns))
+	keyText := make([]string, len(p.keyColumns))
+	after := make([]string, len(p.keyColumns))
+	for i, kc := range p.keyColumns {
+		keys[i] = quoteIdent(kc.Name)
+		keyText[i] = keys[i] + "::text"
+		after[i] = fmt.Sprintf("$%d::text::%s", i+1, kc.DataType)
+	}
+
+	where := quoteIdent(p.column.Column) + " IS NOT NULL"
+	var args []any
+	if p.lastKey != nil {
+		where += fmt.Sprintf(" AND (%s) > (%s)",
+			strings.Join(keys, ", "), strings.Join(after, ", "))
+		for _, k := range p.lastKey {
+			args = append(args, k)
+		}
+	}
+
+	query := fmt.Sprintf(
+		`SELECT %s, %s
+         FROM %s%s
+         WHERE %s
+         ORDER BY %s
+         LIMIT %d`,
+		p.selectList(),
+		strings.Join(keyText, ", "),
+		p.table(),
+		p.sample.clause(),
+		where,
+		strings.Join(keys, ", "),
+		n,
+	)
+
+	rows, err := p.tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+			fmt.Sprintf("failed to fetch rows by key: %v", err), err)
+	}
+	defer rows.Close()
+
+	var batch []RowData
+	key := make([]string, len(p.keyColumns))
+	dest := make([]any, 0, len(key)+3)
+	for rows.Next() {
+		var rd RowData
+		dest = append(dest[:0], p.scanDest(&rd)...)
+		for i := range key {
+			dest = append(dest, &key[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
 				fmt.Sprintf("failed to scan row: %v", err), err)
 		}
@@ -111,6 +481,9 @@ func (p *BatchProcessor) FetchBatch(ctx context.Context) ([]RowData, error) {
 			fmt.Sprintf("error iterating rows: %v", err), err)
 	}
 
+	if len(batch) > 0 {
+		p.lastKey = append([]string(nil), key...)
+	}
 	return batch, nil
 }
 
@@ -119,6 +492,10 @@ func (p *BatchProcessor) CloseCursor(ctx context.Context) error {
 	if !p.cursorOpen {
 		return nil
 	}
+	if p.keyColumns != nil {
+		p.cursorOpen = false
+		return nil
+	}
 
 	_, err := p.tx.ExecContext(ctx, fmt.Sprintf("CLOSE %s", p.cursorName))
 	if err != nil {
@@ -133,9 +510,8 @@ func (p *BatchProcessor) CloseCursor(ctx context.Context) error {
 // UpdateRow updates a single row by CTID.
 func (p *BatchProcessor) UpdateRow(ctx context.Context, ctid, newValue string) error {
 	query := fmt.Sprintf(
-		`UPDATE %s.%s SET %s = $1 WHERE ctid = $2::tid`,
-		quoteIdent(p.column.Schema),
-		quoteIdent(p.column.Table),
+		`UPDATE %s SET %s = $1 WHERE ctid = $2::tid`,
+		p.table(),
 		quoteIdent(p.column.Column),
 	)
 
@@ -148,13 +524,19 @@ func (p *BatchProcessor) UpdateRow(ctx context.Context, ctid, newValue string) e
 	return nil
 }
 
-// UpdateBatch updates multiple rows by CTID in a single statement.
+// UpdateBatch updates multiple rows by CTID in a single statement, or in
+// several if the values would make the statement larger than the limit.
 func (p *BatchProcessor) UpdateBatch(ctx context.Context,
 	updates map[string]string) error {
 
+	defer since(&p.phases.Update, time.Now())
+
 	if len(updates) == 0 {
 		return nil
 	}
+	if p.copier != nil {
+		return p.stage(ctx, updates)
+	}
 
 	// Build arrays for unnest
 	ctids := make([]string, 0, len(updates))
@@ -165,36 +547,468 @@ func (p *BatchProcessor) UpdateBatch(ctx context.Context,
 	}
 
 	// Build the value expression with appropriate type cast
-	valueExpr := "u.new_value"
-	if p.dataType != "" && p.dataType != "text" &&
-		p.dataType != "character varying" && p.dataType != "character" {
-		// Cast to the column's actual type for non-text columns
-		valueExpr = fmt.Sprintf("u.new_value::%s", p.dataType)
-	}
+	valueExpr := p.valueExpr("u.new_value")
 
 	// Use UPDATE FROM with unnest for efficient batch updates
 	query := fmt.Sprintf(`
-        UPDATE %s.%s t
+        UPDATE %s t
         SET %s = %s
         FROM (
             SELECT unnest($1::tid[]) AS ctid, unnest($2::text[]) AS new_value
         ) u
         WHERE t.ctid = u.ctid`,
-		quoteIdent(p.column.Schema),
-		quoteIdent(p.column.Table),
+		p.table(),
 		quoteIdent(p.column.Column),
 		valueExpr,
 	)
 
-	_, err := p.tx.ExecContext(ctx, query, ctids, values)
-	if err != nil {
+	// Write the rows in chunks that keep each statement under the limits
+	chunks := splitBySize(len(ctids), int64(len(query)), p.maxStatementBytes,
+		p.updateChunkSize, func(i int) int64 {
+			return int64(len(ctids[i]) + len(values[i]))
+		})
+	for _, c := range chunks {
+		_, err := p.tx.ExecContext(ctx, query, ctids[c.start:c.end],
+			values[c.start:c.end])
+		if err != nil {
+			return errors.NewDatabaseErrorWithColumn("batch_update", p.column,
+				fmt.Sprintf("failed to batch update: %v", err), err)
+		}
+		p.statementSizes.record(c.bytes)
+	}
+
+	return nil
+}
+
+// stage copies updates to the staging table, creating it for the first
+// batch.
+func (p *BatchProcessor) stage(ctx context.Context,
+	updates map[string]string) error {
+
+	if p.staging == "" {
+		name := fmt.Sprintf("anon_stage_%s_%s_%s",
+			p.column.Schema, p.column.Table, p.column.Column)
+		_, err := p.tx.ExecContext(ctx, fmt.Sprintf(
+			`CREATE TEMPORARY TABLE %s (ctid text, new_value text)
+             ON COMMIT DROP`, quoteIdent(name)))
+		if err != nil {
+			return errors.NewDatabaseErrorWithColumn("batch_update", p.column,
+				fmt.Sprintf("failed to create staging table: %v", err), err)
+		}
+		p.staging = name
+	}
+
+	rows := make([][]any, 0, len(updates))
+	for ctid, value := range updates {
+		rows = append(rows, []any{ctid, value})
+	}
+	if _, err := p.copier.CopyFrom(ctx, p.staging,
+		[]string{"ctid", "new_value"}, rows); err != nil {
+		return errors.NewDatabaseErrorWithColumn("batch_update", p.column,
+			fmt.Sprintf("failed to stage updates: %v", err), err)
+	}
+
+	return nil
+}
+
+// Finish writes the updates staged by the copy strategy to the column with
+// a single statement, and drops the staging table. It does nothing for the
+// other strategies, which write each batch as it is updated.
+func (p *BatchProcessor) Finish(ctx context.Context) error {
+	defer since(&p.phases.Update, time.Now())
+
+	if p.staging == "" {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+        UPDATE %s t
+        SET %s = %s
+        FROM %s s
+        WHERE t.ctid = s.ctid::tid`,
+		p.table(),
+		quoteIdent(p.column.Column),
+		p.valueExpr("s.new_value"),
+		quoteIdent(p.staging),
+	)
+	if _, err := p.tx.ExecContext(ctx, query); err != nil {
 		return errors.NewDatabaseErrorWithColumn("batch_update", p.column,
-			fmt.Sprintf("failed to batch update: %v", err), err)
+			fmt.Sprintf("failed to update from staging table: %v", err), err)
 	}
 
+	if _, err := p.tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s",
+		quoteIdent(p.staging))); err != nil {
+		return errors.NewDatabaseErrorWithColumn("batch_update", p.column,
+			fmt.Sprintf("failed to drop staging table: %v", err), err)
+	}
+	p.staging = ""
+
 	return nil
 }
 
+// DistinctValue is a distinct value of a column and the number of rows
+// holding it.
+type DistinctValue struct {
+	Value string
+	Rows  int64
+}
+
+// FetchDistinct returns the distinct non-null values of the column with
+// the number of rows holding each, if there are no more than limit of
+// them; otherwise it returns false.
+func (p *BatchProcessor) FetchDistinct(ctx context.Context,
+	limit int) ([]DistinctValue, bool, error) {
+
+	defer since(&p.phases.Fetch, time.Now())
+
+	query := fmt.Sprintf(
+		`SELECT %s, count(*)
+         FROM %s
+         WHERE %s IS NOT NULL
+         GROUP BY 1
+         LIMIT %d`,
+		p.textExpr(quoteIdent(p.column.Column)),
+		p.table(),
+		quoteIdent(p.column.Column),
+		limit+1,
+	)
+
+	rows, err := p.tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, false, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+			fmt.Sprintf("failed to fetch distinct values: %v", err), err)
+	}
+	defer rows.Close()
+
+	var values []DistinctValue
+	for rows.Next() {
+		var dv DistinctValue
+		if err := rows.Scan(&dv.Value, &dv.Rows); err != nil {
+			return nil, false, errors.NewDatabaseErrorWithColumn("fetch",
+				p.column, fmt.Sprintf("failed to scan row: %v", err), err)
+		}
+		values = append(values, dv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+			fmt.Sprintf("error iterating rows: %v", err), err)
+	}
+
+	if len(values) > limit {
+		return nil, false, nil
+	}
+	return values, true, nil
+}
+
+// FetchQuantiles returns the values dividing the column's non-null values,
+// read as numbers, into the given number of buckets of equal numbers of
+// rows, from the smallest value to the largest: buckets+1 values, or none
+// if the column has no values. They are taken from a sample of about
+// samplePercent of the rows, or from every row if it is zero.
+func (p *BatchProcessor) FetchQuantiles(ctx context.Context, buckets int,
+	samplePercent float64) ([]float64, error) {
+
+	defer since(&p.phases.Fetch, time.Now())
+
+	fractions := make([]string, buckets+1)
+	for i := range fractions {
+		fractions[i] = strconv.FormatFloat(float64(i)/float64(buckets), 'f',
+			-1, 64)
+	}
+	query := fmt.Sprintf(
+		`SELECT unnest(percentile_cont($1::float8[])
+                WITHIN GROUP (ORDER BY %s::float8))
+         FROM %s%s
+         WHERE %s IS NOT NULL`,
+		quoteIdent(p.column.Column),
+		p.table(),
+		newTableSample(samplePercent).clause(),
+		quoteIdent(p.column.Column),
+	)
+
+	rows, err := p.tx.QueryContext(ctx, query,
+		"{"+strings.Join(fractions, ",")+"}")
+	if err != nil {
+		return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+			fmt.Sprintf("failed to fetch quantiles: %v", err), err)
+	}
+	defer rows.Close()
+
+	var quantiles []float64
+	for rows.Next() {
+		var q sql.NullFloat64
+		if err := rows.Scan(&q); err != nil {
+			return nil, errors.NewDatabaseErrorWithColumn("fetch",
+				p.column, fmt.Sprintf("failed to scan row: %v", err), err)
+		}
+		if q.Valid {
+			quantiles = append(quantiles, q.Float64)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+			fmt.Sprintf("error iterating rows: %v", err), err)
+	}
+	return quantiles, nil
+}
+
+// UpdateByValue replaces each original value in the mapping with its new
+// value, in every row of the column, with a single statement joining the
+// table to the mapping, and returns the number of rows updated.
+func (p *BatchProcessor) UpdateByValue(ctx context.Context,
+	mapping map[string]string) (int64, error) {
+
+	defer since(&p.phases.Update, time.Now())
+
+	if len(mapping) == 0 {
+		return 0, nil
+	}
+
+	originals := make([]string, 0, len(mapping))
+	values := make([]string, 0, len(mapping))
+	var size int64
+	for original, value := range mapping {
+		originals = append(originals, original)
+		values = append(values, value)
+		size += int64(len(original) + len(value))
+	}
+
+	// Values are compared as text, as they were read
+	query := fmt.Sprintf(`
+        UPDATE %s t
+        SET %s = %s
+        FROM unnest($1::text[], $2::text[]) AS m(old_value, new_value)
+        WHERE %s = m.old_value`,
+		p.table(),
+		quoteIdent(p.column.Column),
+		p.valueExpr("m.new_value"),
+		p.textExpr("t."+quoteIdent(p.column.Column)),
+	)
+
+	res, err := p.tx.ExecContext(ctx, query, originals, values)
+	if err != nil {
+		return 0, errors.NewDatabaseErrorWithColumn("batch_update", p.column,
+			fmt.Sprintf("failed to update values: %v", err), err)
+	}
+	p.statementSizes.record(int64(len(query)) + size)
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.NewDatabaseErrorWithColumn("batch_update", p.column,
+			fmt.Sprintf("failed to count updated rows: %v", err), err)
+	}
+	return updated, nil
+}
+
+// CountValues returns the number of rows whose value in the column is not
+// NULL.
+func (p *BatchProcessor) CountValues(ctx context.Context) (int64, error) {
+	defer since(&p.phases.Fetch, time.Now())
+
+	query := fmt.Sprintf(
+		`SELECT count(*) FROM %s WHERE %s IS NOT NULL`,
+		p.table(),
+		quoteIdent(p.column.Column),
+	)
+
+	var count int64
+	if err := p.tx.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+			fmt.Sprintf("failed to count values: %v", err), err)
+	}
+	return count, nil
+}
+
+// UpdateAll sets every non-null value of the column to value, or to NULL
+// if value is nil, with a single statement, and returns the number of rows
+// updated.
+func (p *BatchProcessor) UpdateAll(ctx context.Context,
+	value *string) (int64, error) {
+
+	defer since(&p.phases.Update, time.Now())
+
+	expr := "NULL"
+	var args []any
+	var size int64
+	if value != nil {
+		expr = p.valueExpr("$1")
+		args = append(args, *value)
+		size = int64(len(*value))
+	}
+
+	query := fmt.Sprintf(
+		`UPDATE %s SET %s = %s WHERE %s IS NOT NULL`,
+		p.table(),
+		quoteIdent(p.column.Column),
+		expr,
+		quoteIdent(p.column.Column),
+	)
+
+	res, err := p.tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, errors.NewDatabaseErrorWithColumn("batch_update", p.column,
+			fmt.Sprintf("failed to update values: %v", err), err)
+	}
+	p.statementSizes.record(int64(len(query)) + size)
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.NewDatabaseErrorWithColumn("batch_update", p.column,
+			fmt.Sprintf("failed to count updated rows: %v", err), err)
+	}
+	return updated, nil
+}
+
+// textExpr returns an expression reading a column as text. PostGIS
+// geometries and geographies are read as EWKT, such as
+// "SRID=4326;POINT(-0.1278 51.5074)", rather than as the hexadecimal
+// EWKB of their text output, so that generators can read their
+// coordinates; valueExpr casts the EWKT written back to the column's type.
+func (p *BatchProcessor) textExpr(col string) string {
+	if isSpatialType(p.dataType) {
+		return fmt.Sprintf("ST_AsEWKT(%s)", col)
+	}
+	return col + "::text"
+}
+
+// isSpatialType returns true if a data type, as GetColumnDataType returns
+// it, is the PostGIS geometry or geography type.
+func isSpatialType(dataType string) bool {
+	name := dataType[strings.LastIndexByte(dataType, '.')+1:]
+	return name == "geometry" || name == "geography"
+}
+
+// valueExpr returns an expression casting a text value to the column's
+// type, or the value itself for text columns.
+func (p *BatchProcessor) valueExpr(value string) string {
+	if p.dataType != "" && p.dataType != "text" &&
+		p.dataType != "character varying" && p.dataType != "character" {
+		// Cast to the column's actual type for non-text columns
+		return fmt.Sprintf("%s::%s", value, p.dataType)
+	}
+	return value
+}
+
+// statementChunk is a range of the rows of a batch written with a single
+// statement, and the size of that statement.
+type statementChunk struct {
+	start, end int
+	bytes      int64
+}
+
+// splitBySize splits n rows into chunks of at most maxRows rows whose
+// statements, of base bytes plus the size of each row, do not exceed limit
+// bytes; a row too large to fit is written alone. A limit of zero or less
+// uses DefaultMaxStatementBytes, and a maxRows of zero or less leaves the
+// number of rows unlimited.
+func splitBySize(n int, base, limit int64, maxRows int,
+	size func(i int) int64) []statementChunk {
+
+	if limit <= 0 {
+		limit = DefaultMaxStatementBytes
+	}
+
+	var chunks []statementChunk
+	c := statementChunk{bytes: base}
+	for i := 0; i < n; i++ {
+		rowBytes := size(i)
+		full := maxRows > 0 && c.end-c.start >= maxRows
+		if c.end > c.start && (full || c.bytes+rowBytes > limit) {
+			chunks = append(chunks, c)
+			c = statementChunk{start: i, end: i, bytes: base}
+		}
+		c.end++
+		c.bytes += rowBytes
+	}
+	if c.end > c.start {
+		chunks = append(chunks, c)
+	}
+	return chunks
+}
+
+// fetchBatch reads a batch of up to batchSize rows with fetch, fetchSize
+// rows at a time, stopping early when fetch returns fewer rows than asked
+// for. A fetchSize of zero or less, or larger than batchSize, reads the
+// batch with a single fetch.
+func fetchBatch[T any](batchSize, fetchSize int,
+	fetch func(n int) ([]T, error)) ([]T, error) {
+
+	if fetchSize <= 0 || fetchSize > batchSize {
+		fetchSize = batchSize
+	}
+
+	var batch []T
+	for len(batch) < batchSize {
+		n := min(fetchSize, batchSize-len(batch))
+		rows, err := fetch(n)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, rows...)
+		if len(rows) < n {
+			break
+		}
+	}
+	return batch, nil
+}
+
+// statementSizes tracks the size of the largest statement written.
+type statementSizes struct {
+	max int64
+}
+
+// record records the size of a statement.
+func (s *statementSizes) record(bytes int64) {
+	if bytes > s.max {
+		s.max = bytes
+	}
+}
+
+// cursorHold returns the clause declaring a cursor that remains open after
+// its transaction commits, if hold is true.
+func cursorHold(hold bool) string {
+	if hold {
+		return "WITH HOLD "
+	}
+	return ""
+}
+
+// tableSample selects a random sample of a table's rows with TABLESAMPLE.
+// Its seed makes every query sampling the table choose the same rows, so
+// that the keyset strategy, which reads each batch with a query of its
+// own, reads a single sample.
+type tableSample struct {
+	percent float64
+	seed    int32
+}
+
+// newTableSample returns a sample of about percent of a table's rows.
+func newTableSample(percent float64) tableSample {
+	return tableSample{percent: percent, seed: rand.Int32()}
+}
+
+// clause returns the TABLESAMPLE clause following the table's name, or an
+// empty string if every row is read.
+func (s tableSample) clause() string {
+	if s.percent <= 0 || s.percent >= 100 {
+		return ""
+	}
+	return fmt.Sprintf(" TABLESAMPLE BERNOULLI (%g) REPEATABLE (%d)",
+		s.percent, s.seed)
+}
+
+// tableName returns the quoted name of a column's table, preceded by ONLY
+// to exclude the rows of the tables inheriting from it if only is set.
+func tableName(col errors.ColumnRef, only bool) string {
+	name := quoteIdent(col.Schema) + "." + quoteIdent(col.Table)
+	if only {
+		return "ONLY " + name
+	}
+	return name
+}
+
 // quoteIdent quotes a PostgreSQL identifier to prevent SQL injection.
 func quoteIdent(s string) string {
 	// Replace any double quotes with two double quotes
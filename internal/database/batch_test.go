@@ -0,0 +1,784 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// TestSplitBySize tests splitting batches into statements by size
+func TestSplitBySize(t *testing.T) {
+	sizes := []int64{40, 40, 40, 200, 10}
+	size := func(i int) int64 { return sizes[i] }
+
+	chunks := splitBySize(len(sizes), 20, 100, 0, size)
+	want := []statementChunk{
+		{start: 0, end: 2, bytes: 100},
+		{start: 2, end: 3, bytes: 60},
+		{start: 3, end: 4, bytes: 220}, // too large to fit, so written alone
+		{start: 4, end: 5, bytes: 30},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("expected %d chunks, got %+v", len(want), chunks)
+	}
+	for i := range want {
+		if chunks[i] != want[i] {
+			t.Errorf("chunk %d: expected %+v, got %+v", i, want[i], chunks[i])
+		}
+	}
+
+	if chunks := splitBySize(len(sizes), 20, 0, 0, size); len(chunks) != 1 {
+		t.Errorf("expected a single chunk with the default limit, got %+v",
+			chunks)
+	}
+	if chunks := splitBySize(0, 20, 100, 0, size); len(chunks) != 0 {
+		t.Errorf("expected no chunks for no rows, got %+v", chunks)
+	}
+
+	// A row limit splits the batch as well
+	chunks = splitBySize(len(sizes), 20, 0, 2, size)
+	want = []statementChunk{
+		{start: 0, end: 2, bytes: 100},
+		{start: 2, end: 4, bytes: 260},
+		{start: 4, end: 5, bytes: 30},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("expected %d chunks, got %+v", len(want), chunks)
+	}
+	for i := range want {
+		if chunks[i] != want[i] {
+			t.Errorf("chunk %d: expected %+v, got %+v", i, want[i], chunks[i])
+		}
+	}
+}
+
+// TestFetchBatch tests reading a batch with several fetches
+func TestFetchBatch(t *testing.T) {
+	remaining := 25
+	var fetches []int
+	fetch := func(n int) ([]int, error) {
+		fetches = append(fetches, n)
+		rows := make([]int, min(n, remaining))
+		remaining -= len(rows)
+		return rows, nil
+	}
+
+	batch, err := fetchBatch(10, 4, fetch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batch) != 10 || fmt.Sprint(fetches) != "[4 4 2]" {
+		t.Errorf("unexpected batch of %d rows with fetches %v",
+			len(batch), fetches)
+	}
+
+	// The last batch stops at the first short fetch
+	fetches = nil
+	batch, _ = fetchBatch(20, 0, fetch)
+	if len(batch) != 15 || fmt.Sprint(fetches) != "[20]" {
+		t.Errorf("unexpected batch of %d rows with fetches %v",
+			len(batch), fetches)
+	}
+}
+
+// TestMaxValueBytes tests that values larger than the limit are not read,
+// and are left out of their batches
+func TestMaxValueBytes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT ctid::text, CASE WHEN ` +
+		`octet_length("doc"::text) > 100 THEN '' ELSE "doc"::text END, ` +
+		`octet_length("doc"::text)`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// A batch of nothing but oversized values is followed by the next
+	mock.ExpectQuery(`FETCH 2 FROM`).
+		WillReturnRows(sqlmock.NewRows([]string{"ctid", "doc", "size"}).
+			AddRow("(0,1)", "", 5000).
+			AddRow("(0,2)", "", 7000))
+	mock.ExpectQuery(`FETCH 2 FROM`).
+		WillReturnRows(sqlmock.NewRows([]string{"ctid", "doc", "size"}).
+			AddRow("(0,3)", "small", 5))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "docs", Column: "doc"}
+	p := NewBatchProcessor(tx, col, "text", 2)
+	var oversized []string
+	p.SetMaxValueBytes(100, func(ctid string, bytes int64) error {
+		oversized = append(oversized, fmt.Sprintf("%s:%d", ctid, bytes))
+		return nil
+	})
+	ctx := context.Background()
+
+	if err := p.OpenCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, err := p.FetchBatch(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].CTID != "(0,3)" || rows[0].Value != "small" {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+	if fmt.Sprint(oversized) != "[(0,1):5000 (0,2):7000]" {
+		t.Errorf("unexpected oversized values: %v", oversized)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestUpdateBatchSplitsLargeValues tests that a batch of large values is
+// written with several statements
+func TestUpdateBatchSplitsLargeValues(t *testing.T) {
+	// The arrays are passed as is, as the pgx driver accepts them
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyConverter{}))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	for i := 0; i < 3; i++ {
+		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "docs", Column: "body"}
+	p := NewBatchProcessor(tx, col, "text", 0)
+	p.SetMaxStatementBytes(1024)
+
+	value := strings.Repeat("x", 600)
+	err = p.UpdateBatch(context.Background(), map[string]string{
+		"(0,1)": value, "(0,2)": value, "(0,3)": value,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected one statement per value: %v", err)
+	}
+	if n := p.MaxStatementBytes(); n <= 600 || n > 1024 {
+		t.Errorf("unexpected largest statement size %d", n)
+	}
+}
+
+// TestDistinctValues tests fetching and updating the distinct values of a
+// column
+func TestDistinctValues(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyConverter{}))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`GROUP BY 1\s+LIMIT 3`).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).
+			AddRow("active", 10).AddRow("closed", 5))
+	mock.ExpectQuery(`GROUP BY 1\s+LIMIT 2`).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).
+			AddRow("active", 10).AddRow("closed", 5))
+	mock.ExpectExec(`FROM unnest\(\$1::text\[\], \$2::text\[\]\) AS m`).
+		WillReturnResult(sqlmock.NewResult(0, 15))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "status"}
+	p := NewBatchProcessor(tx, col, "text", 0)
+	ctx := context.Background()
+
+	values, ok, err := p.FetchDistinct(ctx, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok || len(values) != 2 || values[0] != (DistinctValue{"active", 10}) {
+		t.Errorf("unexpected distinct values: %v %+v", ok, values)
+	}
+
+	// More values than the limit
+	if _, ok, err := p.FetchDistinct(ctx, 1); err != nil || ok {
+		t.Errorf("expected too many values, got %v, %v", ok, err)
+	}
+
+	updated, err := p.UpdateByValue(ctx, map[string]string{
+		"active": "pending", "closed": "open"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated != 15 {
+		t.Errorf("expected 15 rows updated, got %d", updated)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestFetchQuantiles tests reading the bounds of a column's histogram
+func TestFetchQuantiles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT unnest\(percentile_cont\(\$1::float8\[\]\)\s+` +
+		`WITHIN GROUP \(ORDER BY "salary"::float8\)\)\s+` +
+		`FROM "public"."employees"\s+WHERE "salary" IS NOT NULL`).
+		WithArgs("{0,0.25,0.5,0.75,1}").
+		WillReturnRows(sqlmock.NewRows([]string{"unnest"}).
+			AddRow(1000.0).AddRow(2000.0).AddRow(2500.0).AddRow(4000.0).
+			AddRow(9000.0))
+	mock.ExpectQuery(`FROM "public"."employees" TABLESAMPLE BERNOULLI \(10\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"unnest"}))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "employees",
+		Column: "salary"}
+	p := NewBatchProcessor(tx, col, "numeric", 0)
+	ctx := context.Background()
+
+	quantiles, err := p.FetchQuantiles(ctx, 4, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(quantiles) != "[1000 2000 2500 4000 9000]" {
+		t.Errorf("unexpected quantiles: %v", quantiles)
+	}
+
+	// A column with no values has none
+	if quantiles, err := p.FetchQuantiles(ctx, 4, 10); err != nil ||
+		len(quantiles) != 0 {
+		t.Errorf("expected no quantiles, got %v, %v", quantiles, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestTableSample tests reading a sample of the rows with TABLESAMPLE
+func TestTableSample(t *testing.T) {
+	for _, percent := range []float64{0, 100} {
+		if clause := newTableSample(percent).clause(); clause != "" {
+			t.Errorf("%g%%: expected no clause, got %q", percent, clause)
+		}
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	sample := `FROM "public"."users" TABLESAMPLE BERNOULLI \(2\.5\) REPEATABLE \(-?\d+\)\s+WHERE "email" IS NOT NULL`
+	mock.ExpectBegin()
+	mock.ExpectExec(`DECLARE .* CURSOR FOR\s+SELECT .*\s+` + sample).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT .*\s+` + sample + `\s+ORDER BY "id"`).
+		WillReturnRows(sqlmock.NewRows([]string{"ctid", "email", "id"}))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+	ctx := context.Background()
+
+	p := NewBatchProcessor(tx, col, "text", 10)
+	p.SetSample(2.5)
+	if err := p.OpenCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p = NewBatchProcessor(tx, col, "text", 10)
+	p.SetSample(2.5)
+	p.SetKeyset([]KeyColumn{{Name: "id", DataType: "integer"}})
+	if err := p.OpenCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := p.FetchBatch(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestOnly tests reading and updating only the rows of a table that
+// other tables inherit from
+func TestOnly(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyConverter{}))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DECLARE .* CURSOR FOR\s+SELECT .*\s+FROM ONLY "public"."users"\s+WHERE`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE ONLY "public"."users" t`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+	ctx := context.Background()
+
+	p := NewBatchProcessor(tx, col, "text", 10)
+	p.SetOnly(true)
+	if err := p.OpenCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.UpdateBatch(ctx, map[string]string{"(0,1)": "a@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestUpdateAll tests setting every value of a column to NULL or to a
+// constant
+func TestUpdateAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "public"."users" WHERE "age" IS NOT NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	mock.ExpectExec(`UPDATE "public"."users" SET "age" = NULL WHERE "age" IS NOT NULL`).
+		WithoutArgs().
+		WillReturnResult(sqlmock.NewResult(0, 12))
+	mock.ExpectExec(`SET "age" = \$1::integer WHERE`).
+		WithArgs("0").
+		WillReturnResult(sqlmock.NewResult(0, 12))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "age"}
+	p := NewBatchProcessor(tx, col, "integer", 0)
+	ctx := context.Background()
+
+	if count, err := p.CountValues(ctx); err != nil || count != 12 {
+		t.Errorf("expected 12 values, got %d, %v", count, err)
+	}
+	if updated, err := p.UpdateAll(ctx, nil); err != nil || updated != 12 {
+		t.Errorf("expected 12 rows set to NULL, got %d, %v", updated, err)
+	}
+	zero := "0"
+	if updated, err := p.UpdateAll(ctx, &zero); err != nil || updated != 12 {
+		t.Errorf("expected 12 rows set to 0, got %d, %v", updated, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestSpatialColumn tests reading PostGIS geometries as EWKT and casting
+// them back
+func TestSpatialColumn(t *testing.T) {
+	if !isSpatialType("geometry") || !isSpatialType("postgis.geography") ||
+		isSpatialType("point") {
+		t.Error("unexpected spatial types")
+	}
+
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyConverter{}))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DECLARE .* CURSOR FOR\s+SELECT ctid::text, ST_AsEWKT\("location"\)\s+FROM`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT ST_AsEWKT\("location"\), count\(\*\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"location", "count"}))
+	mock.ExpectExec(`SET "location" = m.new_value::geometry\s+FROM unnest.*\s+WHERE ST_AsEWKT\(t."location"\) = m.old_value`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "stores", Column: "location"}
+	p := NewBatchProcessor(tx, col, "geometry", 0)
+	ctx := context.Background()
+
+	if err := p.OpenCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := p.FetchDistinct(ctx, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := p.UpdateByValue(ctx, map[string]string{
+		"SRID=4326;POINT(-0.1278 51.5074)": "SRID=4326;POINT(2.3522 48.8566)",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// anyConverter passes query arguments to sqlmock unconverted.
+type anyConverter struct{}
+
+func (anyConverter) ConvertValue(v any) (driver.Value, error) {
+	return v, nil
+}
+
+// TestKeysetStrategy tests reading rows by primary key
+func TestKeysetStrategy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`ORDER BY "id"\s+LIMIT 2`).WithArgs().
+		WillReturnRows(sqlmock.NewRows([]string{"ctid", "email", "id"}).
+			AddRow("(0,1)", "a@example.com", "1").
+			AddRow("(0,2)", "b@example.com", "2"))
+	mock.ExpectQuery(`AND \("id"\) > \(\$1::text::integer\)`).
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"ctid", "email", "id"}).
+			AddRow("(0,3)", "c@example.com", "3"))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+	p := NewBatchProcessor(tx, col, "text", 2)
+	p.SetKeyset([]KeyColumn{{Name: "id", DataType: "integer"}})
+	ctx := context.Background()
+
+	// No cursor is declared or closed
+	if err := p.OpenCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.CloseCursor(ctx)
+
+	rows, err := p.FetchBatch(ctx)
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %+v, %v", rows, err)
+	}
+	rows, err = p.FetchBatch(ctx)
+	if err != nil || len(rows) != 1 || rows[0].CTID != "(0,3)" {
+		t.Fatalf("expected the last row, got %+v, %v", rows, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestCopyStrategy tests staging updates and writing them with a single
+// statement
+func TestCopyStrategy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TEMPORARY TABLE "anon_stage_public_users_email"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`FROM "anon_stage_public_users_email" s\s+WHERE t.ctid = s.ctid::tid`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`DROP TABLE "anon_stage_public_users_email"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+	p := NewBatchProcessor(tx, col, "text", 0)
+	copier := &fakeCopier{}
+	p.SetCopy(copier)
+	ctx := context.Background()
+
+	if err := p.UpdateBatch(ctx, map[string]string{
+		"(0,1)": "x@example.com", "(0,2)": "y@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.UpdateBatch(ctx, map[string]string{
+		"(0,3)": "z@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.Finish(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if copier.table != "anon_stage_public_users_email" || copier.rows != 3 {
+		t.Errorf("expected 3 rows copied to the staging table, got %d to %q",
+			copier.rows, copier.table)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestCommitBetweenBatches tests that a processor committing after each
+// batch holds its cursor and continues in the new transaction
+func TestCommitBetweenBatches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DECLARE anon_public_users_email CURSOR WITH HOLD FOR`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FETCH 2 FROM anon_public_users_email`).
+		WillReturnRows(sqlmock.NewRows([]string{"ctid", "email"}).
+			AddRow("(0,1)", "a@example.com"))
+	mock.ExpectExec(`CLOSE anon_public_users_email`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+	p := NewBatchProcessor(tx, col, "text", 2)
+	commits := 0
+	p.SetCommit(func(ctx context.Context) (*sql.Tx, error) {
+		commits++
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return db.Begin()
+	})
+	ctx := context.Background()
+
+	if err := p.OpenCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.EndBatch(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows, err := p.FetchBatch(ctx); err != nil || len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %+v, %v", rows, err)
+	}
+	if err := p.CloseCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if commits != 1 {
+		t.Errorf("expected 1 commit, got %d", commits)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// fakeCopier records the rows copied to a table.
+type fakeCopier struct {
+	table string
+	rows  int
+}
+
+func (c *fakeCopier) CopyFrom(ctx context.Context, table string,
+	columns []string, rows [][]any) (int64, error) {
+	c.table = table
+	c.rows += len(rows)
+	return int64(len(rows)), nil
+}
+
+// TestIdentityColumn tests reading the identity column with each row
+func TestIdentityColumn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT ctid::text, "street"::text, COALESCE\("user_id"::text, ''\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FETCH 10 FROM`).
+		WillReturnRows(sqlmock.NewRows([]string{"ctid", "street", "user_id"}).
+			AddRow("(0,1)", "1 Main St", "42").
+			AddRow("(0,2)", "2 Oak Ave", ""))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "addresses", Column: "street"}
+	p := NewBatchProcessor(tx, col, "text", 10)
+	p.SetIdentity("user_id")
+	ctx := context.Background()
+
+	if err := p.OpenCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, err := p.FetchBatch(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Identity != "42" || rows[1].Identity != "" {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestIdentityColumns tests reading a row value of several identity
+// columns, such as a composite primary key, with each row
+func TestIdentityColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT ctid::text, "email"::text, ROW\("tenant_id", "id"\)::text`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FETCH 10 FROM`).
+		WillReturnRows(sqlmock.NewRows([]string{"ctid", "email", "row"}).
+			AddRow("(0,1)", "jane@example.com", "(1,42)"))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+	p := NewBatchProcessor(tx, col, "text", 10)
+	p.SetIdentity("tenant_id", "id")
+	ctx := context.Background()
+
+	if err := p.OpenCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, err := p.FetchBatch(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Identity != "(1,42)" {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestSourceColumns tests reading the values of source columns with each
+// row
+func TestSourceColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT ctid::text, "email"::text, ` +
+		`COALESCE\("first_name"::text, ''\), COALESCE\("last_name"::text, ''\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FETCH 10 FROM`).
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"ctid", "email", "first_name", "last_name"}).
+			AddRow("(0,1)", "john@example.com", "Jane", "Doe").
+			AddRow("(0,2)", "kate@example.com", "Anna", ""))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+	p := NewBatchProcessor(tx, col, "text", 10)
+	p.SetSources("first_name", "last_name")
+	ctx := context.Background()
+
+	if err := p.OpenCursor(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, err := p.FetchBatch(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Sources[0] != "Jane" ||
+		rows[0].Sources[1] != "Doe" || rows[1].Sources[0] != "Anna" ||
+		rows[1].Sources[1] != "" {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -14,10 +14,13 @@ package database
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"io"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
 
 	"github.com/pgedge/pgedge-anonymizer/internal/config"
 	"github.com/pgedge/pgedge-anonymizer/internal/errors"
@@ -26,36 +29,75 @@ import (
 // Connector manages database connections.
 type Connector struct {
 	db     *sql.DB
+	conn   *sql.Conn // Connection of the transaction started by BeginTx
 	config *config.DatabaseConfig
+
+	// quiet suppresses the warnings of retried connections
+	quiet bool
+
+	// readOnly makes every transaction of the connections read-only
+	readOnly bool
+
+	// sleep waits between retries; it is replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Copier copies rows into a table with the COPY protocol, within the
+// transaction the rows are anonymized in.
+type Copier interface {
+	CopyFrom(ctx context.Context, table string, columns []string,
+		rows [][]any) (int64, error)
 }
 
 // NewConnector creates a new database connector.
 func NewConnector(cfg *config.DatabaseConfig) *Connector {
 	return &Connector{
 		config: cfg,
+		sleep:  sleepContext,
 	}
 }
 
+// SetQuiet suppresses the warnings logged when connecting is retried.
+func (c *Connector) SetQuiet(quiet bool) {
+	c.quiet = quiet
+}
+
+// SetReadOnly makes every transaction of the connections read-only, so
+// that the server refuses any change to the database.
+func (c *Connector) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
+
 // Connect establishes a connection to the database.
 func (c *Connector) Connect(ctx context.Context) error {
-	connStr := c.config.ConnectionString()
+	// The password is read from its file or command once, when the pool
+	// is opened, and kept out of the configuration
+	cfg := *c.config
+	if err := cfg.ResolvePassword(ctx); err != nil {
+		return errors.NewDatabaseError("connect", err.Error(), err)
+	}
+	connStr := cfg.ConnectionString()
 
-	db, err := sql.Open("pgx", connStr)
+	db, err := c.open(connStr)
 	if err != nil {
 		return errors.NewDatabaseError("connect",
 			fmt.Sprintf("failed to open database: %v", err), err)
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	conns, lifetime := c.config.PoolSize()
+	db.SetMaxOpenConns(conns)
+	db.SetMaxIdleConns(max(conns/2, 1))
+	db.SetConnMaxLifetime(lifetime)
 
-	// Test the connection
-	pingCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-
-	if err := db.PingContext(pingCtx); err != nil {
+	// Test the connection, retrying transient failures
+	err = c.retry(ctx, "connect", func() error {
+		pingCtx, cancel := context.WithTimeout(ctx,
+			c.config.ConnectTimeoutDuration())
+		defer cancel()
+		return db.PingContext(pingCtx)
+	})
+	if err != nil {
 		db.Close()
 		return errors.NewDatabaseError("connect",
 			fmt.Sprintf("failed to ping database: %v", err), err)
@@ -65,8 +107,27 @@ func (c *Connector) Connect(ctx context.Context) error {
 	return nil
 }
 
+// open returns the pool of connections to the database, whose sessions
+// are read-only if the connector is.
+func (c *Connector) open(connStr string) (*sql.DB, error) {
+	if !c.readOnly {
+		return sql.Open("pgx", connStr)
+	}
+
+	pgxConfig, err := pgx.ParseConfig(connStr)
+	if err != nil {
+		return nil, err
+	}
+	pgxConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	return stdlib.OpenDB(*pgxConfig), nil
+}
+
 // Close closes the database connection.
 func (c *Connector) Close() error {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
 	if c.db != nil {
 		return c.db.Close()
 	}
@@ -78,20 +139,164 @@ func (c *Connector) DB() *sql.DB {
 	return c.db
 }
 
-// BeginTx starts a new transaction.
+// BeginTx starts a new transaction, on a connection kept for CopyFrom.
+// Later transactions run on the same connection, so that cursors declared
+// WITH HOLD in an earlier one can still be read.
 func (c *Connector) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return c.begin(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+}
+
+// BeginReadOnly starts a read-only transaction, on the connection kept for
+// CopyOut, so that all the rows copied out of the database are read from
+// the same snapshot, and none can be changed.
+func (c *Connector) BeginReadOnly(ctx context.Context) (*sql.Tx, error) {
+	return c.begin(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+}
+
+// begin starts a transaction on the connection kept for COPY.
+func (c *Connector) begin(ctx context.Context,
+	opts *sql.TxOptions) (*sql.Tx, error) {
 	if c.db == nil {
 		return nil, errors.NewDatabaseError("begin",
 			"database connection not established", nil)
 	}
 
-	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelSerializable,
+	if c.conn != nil {
+		tx, err := c.conn.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, errors.NewDatabaseError("begin",
+				fmt.Sprintf("failed to start transaction: %v", err), err)
+		}
+		return tx, nil
+	}
+
+	// A connection taken from the pool holds nothing yet, so one that
+	// fails is replaced by another
+	var tx *sql.Tx
+	err := c.retry(ctx, "start a transaction", func() error {
+		conn, err := c.db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		tx, err = conn.BeginTx(ctx, opts)
+		if err != nil {
+			discard(conn)
+			return err
+		}
+		c.conn = conn
+		return nil
 	})
 	if err != nil {
 		return nil, errors.NewDatabaseError("begin",
 			fmt.Sprintf("failed to start transaction: %v", err), err)
 	}
-
 	return tx, nil
 }
+
+// Release returns the connection kept for the transactions started by
+// BeginTx and BeginReadOnly to the pool, so that the next transaction is
+// started on a connection known to work, retrying transient failures as
+// Connect does. It must not be called while a transaction is open, or a
+// cursor declared WITH HOLD is still to be read.
+func (c *Connector) Release() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+}
+
+// discard closes a connection that failed, rather than returning it to
+// the pool.
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
+
+// CopyFrom copies rows into a table with the COPY protocol, on the
+// connection of the transaction started by BeginTx, and returns the number
+// of rows copied.
+func (c *Connector) CopyFrom(ctx context.Context, table string,
+	columns []string, rows [][]any) (int64, error) {
+
+	var copied int64
+	err := c.raw(func(conn *pgx.Conn) error {
+		var err error
+		copied, err = conn.CopyFrom(ctx, pgx.Identifier{table}, columns,
+			pgx.CopyFromRows(rows))
+		return err
+	})
+	if err != nil {
+		return 0, errors.NewDatabaseError("copy",
+			fmt.Sprintf("failed to copy rows to %s: %v", table, err), err)
+	}
+
+	return copied, nil
+}
+
+// CopyOut runs a COPY ... TO STDOUT statement on the connection of the
+// transaction started by BeginReadOnly or BeginTx, writing its output to
+// w, and returns the number of rows copied.
+func (c *Connector) CopyOut(ctx context.Context, w io.Writer,
+	query string) (int64, error) {
+
+	var copied int64
+	err := c.raw(func(conn *pgx.Conn) error {
+		tag, err := conn.PgConn().CopyTo(ctx, w, query)
+		copied = tag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, errors.NewDatabaseError("copy",
+			fmt.Sprintf("failed to copy rows out: %v", err), err)
+	}
+	return copied, nil
+}
+
+// CopyIn runs a COPY ... FROM STDIN statement on the connection of the
+// transaction started by BeginTx, reading its input from r, and returns
+// the number of rows copied.
+func (c *Connector) CopyIn(ctx context.Context, r io.Reader,
+	query string) (int64, error) {
+
+	var copied int64
+	err := c.raw(func(conn *pgx.Conn) error {
+		tag, err := conn.PgConn().CopyFrom(ctx, r, query)
+		copied = tag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, errors.NewDatabaseError("copy",
+			fmt.Sprintf("failed to copy rows in: %v", err), err)
+	}
+	return copied, nil
+}
+
+// raw runs fn with the pgx connection of the transaction started by
+// BeginTx or BeginReadOnly, for COPY, which database/sql does not support.
+func (c *Connector) raw(fn func(conn *pgx.Conn) error) error {
+	if c.conn == nil {
+		return fmt.Errorf("no transaction has been started")
+	}
+	return c.conn.Raw(func(driverConn any) error {
+		pc, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("COPY is not supported by the %T driver",
+				driverConn)
+		}
+		return fn(pc.Conn())
+	})
+}
+
+// SetLocal sets a configuration parameter for the rest of a transaction,
+// as SET LOCAL does.
+func SetLocal(ctx context.Context, tx *sql.Tx, name, value string) error {
+	if _, err := tx.ExecContext(ctx, "SELECT set_config($1, $2, true)",
+		name, value); err != nil {
+		return errors.NewDatabaseError("set",
+			fmt.Sprintf("failed to set %s to %q: %v", name, value, err), err)
+	}
+	return nil
+}
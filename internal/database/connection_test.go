@@ -0,0 +1,51 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSetLocal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	query := regexp.QuoteMeta("SELECT set_config($1, $2, true)")
+	mock.ExpectBegin()
+	mock.ExpectExec(query).WithArgs("synchronous_commit", "off").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(query).WithArgs("no_such_setting", "1").
+		WillReturnError(fmt.Errorf("unrecognized configuration parameter"))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	if err := SetLocal(ctx, tx, "synchronous_commit", "off"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := SetLocal(ctx, tx, "no_such_setting", "1"); err == nil {
+		t.Error("expected error for unknown setting")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
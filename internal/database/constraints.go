@@ -0,0 +1,376 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// CheckConstraint is a CHECK constraint that references a column.
+type CheckConstraint struct {
+	Name        string
+	Expression  string // The constraint expression, as pg_get_expr shows it
+	ColumnCount int    // Number of columns the expression references
+	ColumnType  string // Formatted type of the referenced column
+	Domain      string // Domain the constraint is of, if not the table's
+}
+
+// QualifiedName returns the constraint's name, with the domain it is of,
+// if any.
+func (c CheckConstraint) QualifiedName() string {
+	if c.Domain != "" {
+		return c.Name + " of domain " + c.Domain
+	}
+	return c.Name
+}
+
+// EnumType is an enum type and its labels, in their sort order.
+type EnumType struct {
+	Name   string
+	Labels []string
+}
+
+// ExpressionIndex is an index defined on an expression.
+type ExpressionIndex struct {
+	Name       string
+	Definition string // As pg_get_indexdef shows it
+}
+
+// GetCheckConstraints returns the CHECK constraints referencing a column.
+func (v *SchemaValidator) GetCheckConstraints(ctx context.Context,
+	col errors.ColumnRef) ([]CheckConstraint, error) {
+
+	query := `
+        SELECT con.conname, pg_get_expr(con.conbin, con.conrelid),
+               cardinality(con.conkey), format_type(a.atttypid, a.atttypmod)
+        FROM pg_constraint con
+        JOIN pg_class c ON c.oid = con.conrelid
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        JOIN pg_attribute a ON a.attrelid = c.oid
+        WHERE n.nspname = $1
+          AND c.relname = $2
+          AND a.attname = $3
+          AND con.contype = 'c'
+          AND a.attnum = ANY(con.conkey)
+        ORDER BY con.conname
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, col.Schema, col.Table,
+		col.Column)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_constraints",
+			fmt.Sprintf("failed to get check constraints: %v", err), err)
+	}
+	defer rows.Close()
+
+	var constraints []CheckConstraint
+	for rows.Next() {
+		var con CheckConstraint
+		if err := rows.Scan(&con.Name, &con.Expression, &con.ColumnCount,
+			&con.ColumnType); err != nil {
+			return nil, errors.NewDatabaseError("get_constraints",
+				fmt.Sprintf("failed to scan check constraint: %v", err), err)
+		}
+		constraints = append(constraints, con)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_constraints",
+			fmt.Sprintf("error iterating check constraints: %v", err), err)
+	}
+
+	return constraints, nil
+}
+
+// GetDomainConstraints returns the CHECK constraints of the domain a
+// column is of, and of the domains that domain is based on. Their
+// expressions refer to the value as VALUE, and their ColumnType is the
+// type the domains are ultimately based on.
+func (v *SchemaValidator) GetDomainConstraints(ctx context.Context,
+	col errors.ColumnRef) ([]CheckConstraint, error) {
+
+	query := `
+        WITH RECURSIVE domains AS (
+            SELECT t.oid, t.typbasetype, t.typtypmod, 1 AS depth
+            FROM pg_attribute a
+            JOIN pg_class c ON c.oid = a.attrelid
+            JOIN pg_namespace n ON n.oid = c.relnamespace
+            JOIN pg_type t ON t.oid = a.atttypid
+            WHERE n.nspname = $1
+              AND c.relname = $2
+              AND a.attname = $3
+              AND t.typtype = 'd'
+            UNION ALL
+            SELECT t.oid, t.typbasetype, t.typtypmod, d.depth + 1
+            FROM domains d
+            JOIN pg_type t ON t.oid = d.typbasetype
+            WHERE t.typtype = 'd'
+        )
+        SELECT con.conname, pg_get_expr(con.conbin, 0),
+               (SELECT format_type(b.typbasetype, b.typtypmod)
+                FROM domains b ORDER BY b.depth DESC LIMIT 1),
+               format_type(d.oid, NULL)
+        FROM domains d
+        JOIN pg_constraint con ON con.contypid = d.oid
+        WHERE con.contype = 'c'
+        ORDER BY d.depth, con.conname
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, col.Schema, col.Table,
+		col.Column)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_constraints",
+			fmt.Sprintf("failed to get domain constraints: %v", err), err)
+	}
+	defer rows.Close()
+
+	var constraints []CheckConstraint
+	for rows.Next() {
+		con := CheckConstraint{ColumnCount: 1}
+		if err := rows.Scan(&con.Name, &con.Expression, &con.ColumnType,
+			&con.Domain); err != nil {
+			return nil, errors.NewDatabaseError("get_constraints",
+				fmt.Sprintf("failed to scan domain constraint: %v", err), err)
+		}
+		constraints = append(constraints, con)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_constraints",
+			fmt.Sprintf("error iterating domain constraints: %v", err), err)
+	}
+
+	return constraints, nil
+}
+
+// GetEnumType returns the enum type of a column, or of the domain it is
+// of, or nil if it is not of one.
+func (v *SchemaValidator) GetEnumType(ctx context.Context,
+	col errors.ColumnRef) (*EnumType, error) {
+
+	query := `
+        WITH RECURSIVE types AS (
+            SELECT t.oid, t.typtype, t.typbasetype
+            FROM pg_attribute a
+            JOIN pg_class c ON c.oid = a.attrelid
+            JOIN pg_namespace n ON n.oid = c.relnamespace
+            JOIN pg_type t ON t.oid = a.atttypid
+            WHERE n.nspname = $1
+              AND c.relname = $2
+              AND a.attname = $3
+            UNION ALL
+            SELECT t.oid, t.typtype, t.typbasetype
+            FROM types s
+            JOIN pg_type t ON t.oid = s.typbasetype
+            WHERE s.typtype = 'd'
+        )
+        SELECT format_type(e.enumtypid, NULL), e.enumlabel
+        FROM types s
+        JOIN pg_enum e ON e.enumtypid = s.oid
+        WHERE s.typtype = 'e'
+        ORDER BY e.enumsortorder
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, col.Schema, col.Table,
+		col.Column)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_enum",
+			fmt.Sprintf("failed to get enum labels: %v", err), err)
+	}
+	defer rows.Close()
+
+	var enum *EnumType
+	for rows.Next() {
+		var name, label string
+		if err := rows.Scan(&name, &label); err != nil {
+			return nil, errors.NewDatabaseError("get_enum",
+				fmt.Sprintf("failed to scan enum label: %v", err), err)
+		}
+		if enum == nil {
+			enum = &EnumType{Name: name}
+		}
+		enum.Labels = append(enum.Labels, label)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_enum",
+			fmt.Sprintf("error iterating enum labels: %v", err), err)
+	}
+
+	return enum, nil
+}
+
+// GetUniqueExpressionIndexes returns the unique indexes whose expressions
+// (rather than key columns) reference a column, such as an index on
+// lower(email). Unlike unique key columns, these are not accounted for
+// when generating unique values.
+func (v *SchemaValidator) GetUniqueExpressionIndexes(ctx context.Context,
+	col errors.ColumnRef) ([]ExpressionIndex, error) {
+
+	query := `
+        SELECT ix.relname, pg_get_indexdef(i.indexrelid)
+        FROM pg_index i
+        JOIN pg_class ix ON ix.oid = i.indexrelid
+        JOIN pg_class t ON t.oid = i.indrelid
+        JOIN pg_namespace n ON n.oid = t.relnamespace
+        JOIN pg_attribute a ON a.attrelid = t.oid
+        WHERE n.nspname = $1
+          AND t.relname = $2
+          AND a.attname = $3
+          AND i.indisunique = true
+          AND i.indexprs IS NOT NULL
+          AND NOT a.attnum = ANY(i.indkey)
+          AND EXISTS (
+              SELECT 1 FROM pg_depend d
+              WHERE d.classid = 'pg_class'::regclass
+                AND d.objid = i.indexrelid
+                AND d.refobjid = t.oid
+                AND d.refobjsubid = a.attnum
+          )
+        ORDER BY ix.relname
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, col.Schema, col.Table,
+		col.Column)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_indexes",
+			fmt.Sprintf("failed to get expression indexes: %v", err), err)
+	}
+	defer rows.Close()
+
+	var indexes []ExpressionIndex
+	for rows.Next() {
+		var idx ExpressionIndex
+		if err := rows.Scan(&idx.Name, &idx.Definition); err != nil {
+			return nil, errors.NewDatabaseError("get_indexes",
+				fmt.Sprintf("failed to scan expression index: %v", err), err)
+		}
+		indexes = append(indexes, idx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_indexes",
+			fmt.Sprintf("error iterating expression indexes: %v", err), err)
+	}
+
+	return indexes, nil
+}
+
+// GetSampleValues returns up to limit non-null values from a column.
+func (v *SchemaValidator) GetSampleValues(ctx context.Context,
+	col errors.ColumnRef, limit int) ([]string, error) {
+
+	query := fmt.Sprintf(`
+        SELECT %s::text
+        FROM %s.%s
+        WHERE %s IS NOT NULL
+        LIMIT %d
+    `,
+		quoteIdent(col.Column),
+		quoteIdent(col.Schema),
+		quoteIdent(col.Table),
+		quoteIdent(col.Column),
+		limit,
+	)
+
+	rows, err := v.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_sample",
+			fmt.Sprintf("failed to get sample values: %v", err), err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var val string
+		if err := rows.Scan(&val); err != nil {
+			return nil, errors.NewDatabaseError("get_sample",
+				fmt.Sprintf("failed to scan value: %v", err), err)
+		}
+		values = append(values, val)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_sample",
+			fmt.Sprintf("error iterating values: %v", err), err)
+	}
+
+	return values, nil
+}
+
+// FindCheckViolations evaluates a single-column CHECK constraint against
+// candidate values for the column, returning those the constraint would
+// reject. An error is returned if the values cannot be evaluated, for
+// example because they are not valid for the column's type. The
+// constraints of domains are evaluated with VALUE bound to each value.
+func (v *SchemaValidator) FindCheckViolations(ctx context.Context,
+	col errors.ColumnRef, con CheckConstraint, values []string) ([]string, error) {
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(values))
+	args := make([]any, len(values))
+	for i, val := range values {
+		placeholders[i] = fmt.Sprintf("($%d)", i+1)
+		args[i] = val
+	}
+
+	name := quoteIdent(col.Column)
+	if con.Domain != "" {
+		name = "value"
+	}
+
+	// Evaluate the expression with the column bound to each value; like
+	// the constraint itself, a NULL result is not a violation
+	query := fmt.Sprintf(`
+        SELECT %s::text
+        FROM (SELECT v::%s AS %s FROM (VALUES %s) AS s(v)) AS t
+        WHERE NOT (%s)
+    `,
+		name,
+		con.ColumnType,
+		name,
+		strings.Join(placeholders, ", "),
+		con.Expression,
+	)
+
+	rows, err := v.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.NewDatabaseErrorWithColumn("check_constraint", col,
+			fmt.Sprintf("failed to evaluate constraint %s: %v",
+				con.QualifiedName(), err),
+			err)
+	}
+	defer rows.Close()
+
+	var violations []string
+	for rows.Next() {
+		var val string
+		if err := rows.Scan(&val); err != nil {
+			return nil, errors.NewDatabaseErrorWithColumn("check_constraint",
+				col, fmt.Sprintf("failed to scan value: %v", err), err)
+		}
+		violations = append(violations, val)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseErrorWithColumn("check_constraint", col,
+			fmt.Sprintf("error iterating values: %v", err), err)
+	}
+
+	return violations, nil
+}
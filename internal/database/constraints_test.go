@@ -0,0 +1,236 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+func TestGetCheckConstraints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+
+	mock.ExpectQuery(`con.contype = 'c'`).
+		WithArgs("public", "users", "email").
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"conname", "expr", "cardinality", "format_type"}).
+			AddRow("users_email_check", "(email ~~ '%@company.com'::text)", 1,
+				"character varying(255)").
+			AddRow("users_contact_check",
+				"((email IS NOT NULL) OR (phone IS NOT NULL))", 2,
+				"character varying(255)"))
+
+	constraints, err := v.GetCheckConstraints(context.Background(), col)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(constraints) != 2 {
+		t.Fatalf("expected 2 constraints, got %d", len(constraints))
+	}
+	if constraints[0].Name != "users_email_check" ||
+		constraints[0].ColumnCount != 1 ||
+		constraints[0].ColumnType != "character varying(255)" {
+		t.Errorf("unexpected constraint: %+v", constraints[0])
+	}
+	if constraints[1].ColumnCount != 2 {
+		t.Errorf("expected 2 columns, got %d", constraints[1].ColumnCount)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestGetDomainConstraints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "zip"}
+
+	mock.ExpectQuery(`con.contypid = d.oid`).
+		WithArgs("public", "users", "zip").
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"conname", "expr", "base", "domain"}).
+			AddRow("us_zip_check", "(VALUE ~ '^[0-9]{5}$'::text)", "text",
+				"us_zip"))
+
+	constraints, err := v.GetDomainConstraints(context.Background(), col)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(constraints) != 1 {
+		t.Fatalf("expected 1 constraint, got %d", len(constraints))
+	}
+	con := constraints[0]
+	if con.ColumnCount != 1 || con.ColumnType != "text" ||
+		con.Domain != "us_zip" {
+		t.Errorf("unexpected constraint: %+v", con)
+	}
+	if got := con.QualifiedName(); got != "us_zip_check of domain us_zip" {
+		t.Errorf("QualifiedName() = %q", got)
+	}
+
+	// Domain constraints are evaluated on VALUE
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`FROM (SELECT v::text AS value FROM (VALUES ($1)) AS s(v)) AS t`)).
+		WithArgs("1234").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow("1234"))
+
+	violations, err := v.FindCheckViolations(context.Background(), col, con,
+		[]string{"1234"})
+	if err != nil || len(violations) != 1 {
+		t.Errorf("unexpected violations: %v, %v", violations, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestGetEnumType(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "mood"}
+
+	mock.ExpectQuery(`pg_enum`).
+		WithArgs("public", "users", "mood").
+		WillReturnRows(sqlmock.NewRows([]string{"format_type", "enumlabel"}).
+			AddRow("mood", "happy").
+			AddRow("mood", "sad"))
+	mock.ExpectQuery(`pg_enum`).
+		WithArgs("public", "users", "mood").
+		WillReturnRows(sqlmock.NewRows([]string{"format_type", "enumlabel"}))
+
+	enum, err := v.GetEnumType(context.Background(), col)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enum == nil || enum.Name != "mood" || len(enum.Labels) != 2 ||
+		enum.Labels[1] != "sad" {
+		t.Errorf("unexpected enum: %+v", enum)
+	}
+
+	// Columns not of an enum type have none
+	enum, err = v.GetEnumType(context.Background(), col)
+	if err != nil || enum != nil {
+		t.Errorf("expected no enum, got %+v, %v", enum, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestGetUniqueExpressionIndexes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+
+	mock.ExpectQuery(`i.indexprs IS NOT NULL`).
+		WithArgs("public", "users", "email").
+		WillReturnRows(sqlmock.NewRows([]string{"relname", "indexdef"}).
+			AddRow("users_lower_email_key",
+				"CREATE UNIQUE INDEX users_lower_email_key ON public.users "+
+					"USING btree (lower(email))"))
+
+	indexes, err := v.GetUniqueExpressionIndexes(context.Background(), col)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(indexes) != 1 || indexes[0].Name != "users_lower_email_key" {
+		t.Errorf("unexpected indexes: %+v", indexes)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestFindCheckViolations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+	con := CheckConstraint{
+		Name:        "users_email_check",
+		Expression:  "(email ~~ '%@company.com'::text)",
+		ColumnCount: 1,
+		ColumnType:  "text",
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`FROM (SELECT v::text AS "email" FROM (VALUES ($1), ($2)) AS s(v)) AS t
+        WHERE NOT ((email ~~ '%@company.com'::text))`)).
+		WithArgs("a@example.com", "b@company.com").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).
+			AddRow("a@example.com"))
+
+	violations, err := v.FindCheckViolations(context.Background(), col, con,
+		[]string{"a@example.com", "b@company.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(violations) != 1 || violations[0] != "a@example.com" {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+
+	// Evaluation errors, such as invalid input for the type, are reported
+	mock.ExpectQuery(`WHERE NOT`).
+		WithArgs("not-a-number").
+		WillReturnError(fmt.Errorf("invalid input syntax for type integer"))
+
+	con.ColumnType = "integer"
+	if _, err := v.FindCheckViolations(context.Background(), col, con,
+		[]string{"not-a-number"}); err == nil {
+		t.Error("expected error for evaluation failure")
+	}
+
+	// No values means nothing to check
+	violations, err = v.FindCheckViolations(context.Background(), col, con, nil)
+	if err != nil || violations != nil {
+		t.Errorf("expected no violations, got %v, %v", violations, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
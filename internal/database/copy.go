@@ -0,0 +1,126 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// TableCopy holds the COPY statements copying the rows of a table from
+// one database to the table of the same name in another.
+type TableCopy struct {
+	Out string // COPY ... TO STDOUT, run in the database copied from
+	In  string // COPY ... FROM STDIN, run in the database copied to
+}
+
+// PrepareCopy readies a table of the database copied to for the rows of
+// the table of the same name in the database copied from: it empties the
+// table if truncate is set, and otherwise checks that it is empty. It
+// returns the statements copying the columns the table can be written to,
+// which excludes generated columns, as those are computed again.
+func PrepareCopy(ctx context.Context, tx *sql.Tx, schema, table string,
+	truncate bool) (*TableCopy, error) {
+
+	name := quoteIdent(schema) + "." + quoteIdent(table)
+
+	if truncate {
+		if _, err := tx.ExecContext(ctx, "TRUNCATE "+name); err != nil {
+			return nil, errors.NewDatabaseError("copy",
+				fmt.Sprintf("failed to truncate %s.%s: %v", schema, table,
+					err), err)
+		}
+	} else {
+		var hasRows bool
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf(
+			"SELECT EXISTS (SELECT 1 FROM %s)", name)).Scan(
+			&hasRows); err != nil {
+			return nil, errors.NewDatabaseError("copy",
+				fmt.Sprintf("failed to check %s.%s is empty: %v", schema,
+					table, err), err)
+		}
+		if hasRows {
+			return nil, errors.NewDatabaseError("copy",
+				fmt.Sprintf("table %s.%s is not empty; empty it, or set "+
+					"copy_to.truncate", schema, table), nil)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT a.attname
+        FROM pg_attribute a
+        WHERE a.attrelid = $1::regclass
+          AND a.attnum > 0
+          AND NOT a.attisdropped
+          AND a.attgenerated = ''
+        ORDER BY a.attnum
+    `, name)
+	if err != nil {
+		return nil, errors.NewDatabaseError("copy",
+			fmt.Sprintf("failed to query columns of %s.%s: %v", schema,
+				table, err), err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, errors.NewDatabaseError("copy",
+				fmt.Sprintf("failed to scan column: %v", err), err)
+		}
+		columns = append(columns, quoteIdent(column))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("copy",
+			fmt.Sprintf("error iterating columns: %v", err), err)
+	}
+
+	list := strings.Join(columns, ", ")
+	return &TableCopy{
+		Out: fmt.Sprintf("COPY (SELECT %s FROM %s) TO STDOUT", list, name),
+		In:  fmt.Sprintf("COPY %s (%s) FROM STDIN", name, list),
+	}, nil
+}
+
+// CopyTable streams the rows of a table from the source database to the
+// target, in the transactions started on each, and returns the number of
+// rows copied.
+func CopyTable(ctx context.Context, source, target *Connector,
+	c *TableCopy) (int64, error) {
+
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := source.CopyOut(ctx, w, c.Out)
+		w.CloseWithError(err)
+		done <- err
+	}()
+
+	copied, err := target.CopyIn(ctx, r, c.In)
+
+	// Stop the rows being read if they could not all be written
+	_ = r.Close()
+	outErr := <-done
+
+	if outErr != nil {
+		return 0, outErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	return copied, nil
+}
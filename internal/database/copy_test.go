@@ -0,0 +1,107 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestPrepareCopy tests readying a table to be copied to
+func TestPrepareCopy(t *testing.T) {
+	columns := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"attname"}).
+			AddRow("id").AddRow("email")
+	}
+
+	t.Run("empty table", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock: %v", err)
+		}
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM "public"."users"\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`SELECT a.attname\s+FROM pg_attribute a`).
+			WithArgs(`"public"."users"`).
+			WillReturnRows(columns())
+
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c, err := PrepareCopy(context.Background(), tx, "public", "users",
+			false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Out != `COPY (SELECT "id", "email" FROM "public"."users") TO STDOUT` {
+			t.Errorf("unexpected statement: %s", c.Out)
+		}
+		if c.In != `COPY "public"."users" ("id", "email") FROM STDIN` {
+			t.Errorf("unexpected statement: %s", c.In)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock: %v", err)
+		}
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`TRUNCATE "public"."users"`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT a.attname`).WillReturnRows(columns())
+
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := PrepareCopy(context.Background(), tx, "public", "users",
+			true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("table not empty", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock: %v", err)
+		}
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT EXISTS`).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = PrepareCopy(context.Background(), tx, "public", "users", false)
+		if err == nil || !strings.Contains(err.Error(), "is not empty") {
+			t.Errorf("expected an error for a table with rows, got %v", err)
+		}
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
 	"github.com/pgedge/pgedge-anonymizer/internal/errors"
 )
 
@@ -29,11 +30,19 @@ type ForeignKey struct {
 	ChildColumn    string
 	OnUpdate       string // CASCADE, SET NULL, NO ACTION, etc.
 	OnDelete       string
+
+	// Declared is set for a reference declared in the configuration,
+	// which the database does not enforce; it has no constraint name or
+	// actions.
+	Declared bool
 }
 
 // FKAnalyzer analyzes foreign key relationships.
 type FKAnalyzer struct {
 	db *sql.DB
+
+	// declared are the references declared in the configuration
+	declared []ForeignKey
 }
 
 // NewFKAnalyzer creates a new foreign key analyzer.
@@ -41,7 +50,25 @@ func NewFKAnalyzer(db *sql.DB) *FKAnalyzer {
 	return &FKAnalyzer{db: db}
 }
 
-// Analyze retrieves all foreign key relationships involving the given columns.
+// SetReferences sets the references declared in the configuration, which
+// are analyzed along with the database's foreign keys.
+func (a *FKAnalyzer) SetReferences(refs []config.DeclaredReference) {
+	a.declared = nil
+	for _, r := range refs {
+		a.declared = append(a.declared, ForeignKey{
+			ParentSchema: r.Parent.Schema,
+			ParentTable:  r.Parent.Table,
+			ParentColumn: r.Parent.Column,
+			ChildSchema:  r.Child.Schema,
+			ChildTable:   r.Child.Table,
+			ChildColumn:  r.Child.Column,
+			Declared:     true,
+		})
+	}
+}
+
+// Analyze retrieves all foreign key relationships involving the given
+// columns, including the references declared with SetReferences.
 func (a *FKAnalyzer) Analyze(ctx context.Context,
 	columns []errors.ColumnRef) ([]ForeignKey, error) {
 
@@ -128,6 +155,14 @@ func (a *FKAnalyzer) Analyze(ctx context.Context,
 			fmt.Sprintf("error iterating foreign keys: %v", err), err)
 	}
 
+	for _, fk := range a.declared {
+		parentKey := fmt.Sprintf("%s.%s", fk.ParentSchema, fk.ParentTable)
+		childKey := fmt.Sprintf("%s.%s", fk.ChildSchema, fk.ChildTable)
+		if tables[parentKey] || tables[childKey] {
+			fks = append(fks, fk)
+		}
+	}
+
 	return fks, nil
 }
 
@@ -174,7 +209,8 @@ func (a *FKAnalyzer) GetCascadeTargets(ctx context.Context,
 }
 
 // GetProcessingOrder returns the columns in an order that respects
-// foreign key dependencies (parent before child for CASCADE).
+// foreign key dependencies (parent before child for CASCADE and declared
+// references).
 func (a *FKAnalyzer) GetProcessingOrder(ctx context.Context,
 	columns []errors.ColumnRef) ([]errors.ColumnRef, error) {
 
@@ -184,7 +220,8 @@ func (a *FKAnalyzer) GetProcessingOrder(ctx context.Context,
 	}
 
 	// Build dependency graph
-	// For CASCADE: parent must be processed before child
+	// For CASCADE and declared references: parent must be processed
+	// before child
 	deps := make(map[string][]string) // child -> []parent
 	colSet := make(map[string]errors.ColumnRef)
 
@@ -193,7 +230,7 @@ func (a *FKAnalyzer) GetProcessingOrder(ctx context.Context,
 	}
 
 	for _, fk := range fks {
-		if fk.OnUpdate != "CASCADE" {
+		if fk.OnUpdate != "CASCADE" && !fk.Declared {
 			continue
 		}
 
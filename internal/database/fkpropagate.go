@@ -0,0 +1,224 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// ReferencingColumn is a column of a foreign key that references another
+// column without cascading updates of it, or a column declared to
+// reference another.
+type ReferencingColumn struct {
+	// Constraint is the foreign key constraint, as schema.table.name of
+	// the referencing table, or empty for a declared reference.
+	Constraint string
+
+	Child  errors.ColumnRef
+	Parent errors.ColumnRef
+}
+
+// Via describes how the column references the other: by its foreign key
+// constraint, or a declared reference.
+func (rc ReferencingColumn) Via() string {
+	if rc.Constraint == "" {
+		return "declared reference " + rc.Child.String()
+	}
+	return "foreign key " + rc.Constraint
+}
+
+// ForeignKeyConstraint is a foreign key constraint of a table, and its
+// definition, as pg_get_constraintdef gives it, to add it again with.
+type ForeignKeyConstraint struct {
+	Schema     string
+	Table      string
+	Name       string
+	Definition string
+}
+
+// String returns the constraint as schema.table.name.
+func (k ForeignKeyConstraint) String() string {
+	return k.Schema + "." + k.Table + "." + k.Name
+}
+
+// GetReferencingColumns returns the columns of foreign keys referencing
+// any of the given columns whose updates they do not cascade, each paired
+// with the column it references, by constraint.
+func (a *FKAnalyzer) GetReferencingColumns(ctx context.Context,
+	columns []errors.ColumnRef) ([]ReferencingColumn, error) {
+
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	schemas := make([]string, len(columns))
+	tables := make([]string, len(columns))
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		schemas[i], tables[i], names[i] = col.Schema, col.Table, col.Column
+	}
+
+	// The columns of a multi-column key are paired by position; the
+	// constraints partitions inherit from partitioned tables are left out
+	query := `
+        SELECT c.conname,
+               cn.nspname, cc.relname, ca.attname,
+               pn.nspname, pc.relname, pa.attname
+        FROM pg_constraint c
+        CROSS JOIN LATERAL unnest(c.conkey, c.confkey) AS k(child, parent)
+        JOIN pg_class pc ON pc.oid = c.confrelid
+        JOIN pg_namespace pn ON pn.oid = pc.relnamespace
+        JOIN pg_attribute pa ON pa.attrelid = c.confrelid
+                            AND pa.attnum = k.parent
+        JOIN pg_class cc ON cc.oid = c.conrelid
+        JOIN pg_namespace cn ON cn.oid = cc.relnamespace
+        JOIN pg_attribute ca ON ca.attrelid = c.conrelid
+                            AND ca.attnum = k.child
+        WHERE c.contype = 'f'
+          AND c.confupdtype <> 'c'
+          AND c.conparentid = 0
+          AND (pn.nspname, pc.relname, pa.attname) IN (
+              SELECT * FROM unnest($1::text[], $2::text[], $3::text[])
+          )
+        ORDER BY cn.nspname, cc.relname, c.conname, ca.attnum
+    `
+
+	rows, err := a.db.QueryContext(ctx, query, schemas, tables, names)
+	if err != nil {
+		return nil, errors.NewDatabaseError("fk_referencing",
+			fmt.Sprintf("failed to query referencing columns: %v", err), err)
+	}
+	defer rows.Close()
+
+	var referencing []ReferencingColumn
+	for rows.Next() {
+		var rc ReferencingColumn
+		var name string
+		if err := rows.Scan(&name,
+			&rc.Child.Schema, &rc.Child.Table, &rc.Child.Column,
+			&rc.Parent.Schema, &rc.Parent.Table, &rc.Parent.Column,
+		); err != nil {
+			return nil, errors.NewDatabaseError("fk_referencing",
+				fmt.Sprintf("failed to scan referencing column: %v", err), err)
+		}
+		rc.Constraint = rc.Child.Schema + "." + rc.Child.Table + "." + name
+		referencing = append(referencing, rc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("fk_referencing",
+			fmt.Sprintf("error iterating referencing columns: %v", err), err)
+	}
+
+	return referencing, nil
+}
+
+// DropForeignKeys drops, in a transaction, those of the given foreign key
+// constraints, keyed by schema.table.name, that reference or belong to
+// the given tables, each as schema.table, or tables they inherit from. It
+// returns the constraints dropped, with their definitions, to add them
+// again with RestoreForeignKeys.
+func DropForeignKeys(ctx context.Context, tx *sql.Tx, tables []string,
+	constraints map[string]bool) ([]ForeignKeyConstraint, error) {
+
+	if len(tables) == 0 || len(constraints) == 0 {
+		return nil, nil
+	}
+
+	schemas := make([]string, len(tables))
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		schemas[i], names[i], _ = strings.Cut(t, ".")
+	}
+
+	query := `
+        WITH RECURSIVE rels AS (
+            SELECT c.oid
+            FROM unnest($1::text[], $2::text[]) AS t(nspname, relname)
+            JOIN pg_namespace n ON n.nspname = t.nspname
+            JOIN pg_class c ON c.relnamespace = n.oid
+                           AND c.relname = t.relname
+            UNION
+            SELECT h.inhparent
+            FROM rels
+            JOIN pg_inherits h ON h.inhrelid = rels.oid
+        )
+        SELECT n.nspname, c.relname, k.conname, pg_get_constraintdef(k.oid)
+        FROM pg_constraint k
+        JOIN pg_class c ON c.oid = k.conrelid
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE k.contype = 'f'
+          AND k.conparentid = 0
+          AND (k.conrelid IN (SELECT oid FROM rels)
+               OR k.confrelid IN (SELECT oid FROM rels))
+        ORDER BY n.nspname, c.relname, k.conname
+    `
+
+	rows, err := tx.QueryContext(ctx, query, schemas, names)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_foreign_keys",
+			fmt.Sprintf("failed to get foreign keys: %v", err), err)
+	}
+	defer rows.Close()
+
+	var keys []ForeignKeyConstraint
+	for rows.Next() {
+		var k ForeignKeyConstraint
+		if err := rows.Scan(&k.Schema, &k.Table, &k.Name,
+			&k.Definition); err != nil {
+			return nil, errors.NewDatabaseError("get_foreign_keys",
+				fmt.Sprintf("failed to scan foreign key: %v", err), err)
+		}
+		if constraints[k.String()] {
+			keys = append(keys, k)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_foreign_keys",
+			fmt.Sprintf("error iterating foreign keys: %v", err), err)
+	}
+
+	for _, k := range keys {
+		query := fmt.Sprintf("ALTER TABLE %s.%s DROP CONSTRAINT %s",
+			quoteIdent(k.Schema), quoteIdent(k.Table), quoteIdent(k.Name))
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return nil, errors.NewDatabaseError("drop_foreign_key",
+				fmt.Sprintf("failed to drop foreign key %s on %s.%s: %v",
+					k.Name, k.Schema, k.Table, err), err)
+		}
+	}
+
+	return keys, nil
+}
+
+// RestoreForeignKeys adds foreign key constraints dropped by
+// DropForeignKeys again, in a transaction, which checks that every row
+// still references an existing one.
+func RestoreForeignKeys(ctx context.Context, tx *sql.Tx,
+	keys []ForeignKeyConstraint) error {
+
+	for _, k := range keys {
+		query := fmt.Sprintf("ALTER TABLE %s.%s ADD CONSTRAINT %s %s",
+			quoteIdent(k.Schema), quoteIdent(k.Table), quoteIdent(k.Name),
+			k.Definition)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return errors.NewDatabaseError("restore_foreign_key",
+				fmt.Sprintf("failed to restore foreign key %s on %s.%s: %v",
+					k.Name, k.Schema, k.Table, err), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+func TestGetReferencingColumns(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyConverter{}))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`c.confupdtype <> 'c'`).
+		WithArgs([]string{"public"}, []string{"users"}, []string{"email"}).
+		WillReturnRows(sqlmock.NewRows([]string{"conname",
+			"child_schema", "child_table", "child_column",
+			"parent_schema", "parent_table", "parent_column"}).
+			AddRow("orders_email_fkey", "sales", "orders", "email",
+				"public", "users", "email"))
+
+	a := NewFKAnalyzer(db)
+	referencing, err := a.GetReferencingColumns(context.Background(),
+		[]errors.ColumnRef{{Schema: "public", Table: "users", Column: "email"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(referencing) != 1 {
+		t.Fatalf("expected 1 referencing column, got %d", len(referencing))
+	}
+	rc := referencing[0]
+	if rc.Constraint != "sales.orders.orders_email_fkey" ||
+		rc.Child.String() != "sales.orders.email" ||
+		rc.Parent.String() != "public.users.email" {
+		t.Errorf("unexpected referencing column: %+v", rc)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+func TestDropForeignKeys(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyConverter{}))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	definition := "FOREIGN KEY (email) REFERENCES public.users(email)"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`pg_get_constraintdef`).
+		WithArgs([]string{"public", "sales"}, []string{"users", "orders"}).
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname",
+			"conname", "definition"}).
+			AddRow("sales", "orders", "orders_email_fkey", definition).
+			AddRow("sales", "orders", "orders_product_fkey",
+				"FOREIGN KEY (product_id) REFERENCES products(id)"))
+
+	// Only the foreign keys whose values are propagated are dropped
+	mock.ExpectExec(regexp.QuoteMeta(
+		`ALTER TABLE "sales"."orders" DROP CONSTRAINT "orders_email_fkey"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`ALTER TABLE "sales"."orders" ADD CONSTRAINT "orders_email_fkey" ` +
+			definition)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keys, err := DropForeignKeys(ctx, tx,
+		[]string{"public.users", "sales.orders"},
+		map[string]bool{"sales.orders.orders_email_fkey": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Definition != definition {
+		t.Errorf("unexpected foreign keys: %+v", keys)
+	}
+
+	if err := RestoreForeignKeys(ctx, tx, keys); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+func TestDeclaredReferences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fkColumns := []string{"constraint_name", "parent_schema", "parent_table",
+		"parent_column", "child_schema", "child_table", "child_column",
+		"on_update", "on_delete"}
+	for range 2 {
+		mock.ExpectQuery(`c.contype = 'f'`).
+			WillReturnRows(sqlmock.NewRows(fkColumns))
+	}
+
+	a := NewFKAnalyzer(db)
+	a.SetReferences([]config.DeclaredReference{{
+		Child:  errors.ColumnRef{Schema: "sales", Table: "orders", Column: "email"},
+		Parent: errors.ColumnRef{Schema: "public", Table: "users", Column: "email"},
+	}})
+
+	columns := []errors.ColumnRef{
+		{Schema: "sales", Table: "orders", Column: "email"},
+		{Schema: "public", Table: "users", Column: "email"},
+	}
+	ctx := context.Background()
+
+	fks, err := a.Analyze(ctx, columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fks) != 1 || !fks[0].Declared || fks[0].ChildTable != "orders" {
+		t.Errorf("unexpected foreign keys: %+v", fks)
+	}
+
+	// The referenced column is anonymized first
+	ordered, err := a.GetProcessingOrder(ctx, columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ordered) != 2 || ordered[0].Table != "users" {
+		t.Errorf("expected public.users.email first, got %v", ordered)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// Kinds of GeneratedColumn.
+const (
+	// GeneratedStored is a GENERATED ALWAYS AS (expression) STORED column.
+	GeneratedStored = "generated"
+
+	// IdentityAlways and IdentityByDefault are GENERATED ALWAYS and
+	// GENERATED BY DEFAULT identity columns.
+	IdentityAlways    = "identity always"
+	IdentityByDefault = "identity by default"
+)
+
+// GeneratedColumn is a column whose values the database generates.
+type GeneratedColumn struct {
+	Column errors.ColumnRef
+	Kind   string
+
+	// Expression is the expression a stored generated column is computed
+	// by, and Sources the columns of its table the expression reads.
+	Expression string
+	Sources    []string
+}
+
+// GetGeneratedColumns returns those of the given columns that are stored
+// generated columns or identity columns, in the order given.
+func (v *SchemaValidator) GetGeneratedColumns(ctx context.Context,
+	columns []errors.ColumnRef) ([]GeneratedColumn, error) {
+
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	schemas := make([]string, len(columns))
+	tables := make([]string, len(columns))
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		schemas[i], tables[i], names[i] = col.Schema, col.Table, col.Column
+	}
+
+	// A generated column's default depends on the columns it reads, and
+	// on the generated column itself
+	query := `
+        SELECT t.ord, a.attgenerated::text, a.attidentity::text,
+               coalesce(pg_get_expr(ad.adbin, ad.adrelid), ''),
+               coalesce(s.attname, '')
+        FROM unnest($1::text[], $2::text[], $3::text[])
+             WITH ORDINALITY AS t(nspname, relname, attname, ord)
+        JOIN pg_namespace n ON n.nspname = t.nspname
+        JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = t.relname
+        JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = t.attname
+        LEFT JOIN pg_attrdef ad ON a.attgenerated = 's'
+                               AND ad.adrelid = a.attrelid
+                               AND ad.adnum = a.attnum
+        LEFT JOIN pg_depend d ON d.classid = 'pg_attrdef'::regclass
+                             AND d.objid = ad.oid
+                             AND d.refobjid = c.oid
+                             AND d.refobjsubid > 0
+                             AND d.refobjsubid <> a.attnum
+        LEFT JOIN pg_attribute s ON s.attrelid = c.oid
+                                AND s.attnum = d.refobjsubid
+        WHERE a.attgenerated = 's'
+           OR a.attidentity <> ''
+        ORDER BY t.ord, s.attnum
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, schemas, tables, names)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_generated",
+			fmt.Sprintf("failed to get generated columns: %v", err), err)
+	}
+	defer rows.Close()
+
+	var generated []GeneratedColumn
+	last := int64(0)
+	for rows.Next() {
+		var ord int64
+		var attgenerated, attidentity, expr, source string
+		if err := rows.Scan(&ord, &attgenerated, &attidentity, &expr,
+			&source); err != nil {
+			return nil, errors.NewDatabaseError("get_generated",
+				fmt.Sprintf("failed to scan generated column: %v", err), err)
+		}
+
+		// Each source of a generated column is a row of its own
+		if ord != last {
+			last = ord
+			gc := GeneratedColumn{Column: columns[ord-1], Expression: expr}
+			switch {
+			case attgenerated == "s":
+				gc.Kind = GeneratedStored
+			case attidentity == "a":
+				gc.Kind = IdentityAlways
+			default:
+				gc.Kind = IdentityByDefault
+			}
+			generated = append(generated, gc)
+		}
+		if source != "" {
+			gc := &generated[len(generated)-1]
+			gc.Sources = append(gc.Sources, source)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_generated",
+			fmt.Sprintf("error iterating generated columns: %v", err), err)
+	}
+
+	return generated, nil
+}
@@ -0,0 +1,77 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+func TestGetGeneratedColumns(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyConverter{}))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	columns := []errors.ColumnRef{
+		{Schema: "public", Table: "users", Column: "id"},
+		{Schema: "public", Table: "users", Column: "email"},
+		{Schema: "public", Table: "users", Column: "full_name"},
+		{Schema: "public", Table: "orders", Column: "number"},
+	}
+
+	mock.ExpectQuery(`a.attgenerated = 's'`).
+		WithArgs([]string{"public", "public", "public", "public"},
+			[]string{"users", "users", "users", "orders"},
+			[]string{"id", "email", "full_name", "number"}).
+		WillReturnRows(sqlmock.NewRows([]string{"ord", "attgenerated",
+			"attidentity", "expr", "attname"}).
+			AddRow(1, "", "a", "", "").
+			AddRow(3, "s", "", "((first_name || ' '::text) || last_name)",
+				"first_name").
+			AddRow(3, "s", "", "((first_name || ' '::text) || last_name)",
+				"last_name").
+			AddRow(4, "", "d", "", ""))
+
+	v := NewSchemaValidator(db)
+	generated, err := v.GetGeneratedColumns(context.Background(), columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(generated) != 3 {
+		t.Fatalf("expected 3 generated columns, got %d: %+v",
+			len(generated), generated)
+	}
+	if generated[0].Column.Column != "id" ||
+		generated[0].Kind != IdentityAlways {
+		t.Errorf("unexpected first column: %+v", generated[0])
+	}
+	if generated[1].Column.Column != "full_name" ||
+		generated[1].Kind != GeneratedStored ||
+		len(generated[1].Sources) != 2 ||
+		generated[1].Sources[1] != "last_name" {
+		t.Errorf("unexpected second column: %+v", generated[1])
+	}
+	if generated[2].Column.Table != "orders" ||
+		generated[2].Kind != IdentityByDefault {
+		t.Errorf("unexpected third column: %+v", generated[2])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// Index is an index dropped by DropColumnIndexes.
+type Index struct {
+	Schema     string
+	Table      string
+	Name       string
+	Definition string // As pg_get_indexdef shows it
+}
+
+// DropColumnIndexes drops the indexes of a table in a transaction that
+// reference any of the given columns, in their keys, expressions or
+// predicates, returning them so that they can be created again with
+// RecreateIndexes. Unique indexes, indexes backing a constraint, indexes
+// on the columns of a foreign key, which checking the key relies on, and
+// the partitions of a partitioned index are kept.
+func DropColumnIndexes(ctx context.Context, tx *sql.Tx, schema, table string,
+	columns []string) ([]Index, error) {
+
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	args := []any{schema, table}
+	placeholders := make([]string, len(columns))
+	for i, col := range columns {
+		args = append(args, col)
+		placeholders[i] = fmt.Sprintf("$%d", i+3)
+	}
+
+	query := fmt.Sprintf(`
+        SELECT ix.relname, pg_get_indexdef(i.indexrelid)
+        FROM pg_index i
+        JOIN pg_class ix ON ix.oid = i.indexrelid
+        JOIN pg_class t ON t.oid = i.indrelid
+        JOIN pg_namespace n ON n.oid = t.relnamespace
+        WHERE n.nspname = $1
+          AND t.relname = $2
+          AND ix.relkind = 'i'
+          AND NOT i.indisunique
+          AND NOT EXISTS (
+              SELECT 1 FROM pg_constraint con
+              WHERE con.conindid = i.indexrelid
+          )
+          AND NOT EXISTS (
+              SELECT 1 FROM pg_constraint fk
+              WHERE fk.conrelid = t.oid
+                AND fk.contype = 'f'
+                AND fk.conkey <@ i.indkey::int2[]
+          )
+          AND NOT EXISTS (
+              SELECT 1 FROM pg_inherits h
+              WHERE h.inhrelid = i.indexrelid
+          )
+          AND EXISTS (
+              SELECT 1
+              FROM pg_depend d
+              JOIN pg_attribute a ON a.attrelid = t.oid
+                                 AND a.attnum = d.refobjsubid
+              WHERE d.classid = 'pg_class'::regclass
+                AND d.objid = i.indexrelid
+                AND d.refobjid = t.oid
+                AND a.attname IN (%s)
+          )
+        ORDER BY ix.relname
+    `, strings.Join(placeholders, ", "))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_indexes",
+			fmt.Sprintf("failed to get indexes of %s.%s: %v", schema, table,
+				err), err)
+	}
+	defer rows.Close()
+
+	var indexes []Index
+	for rows.Next() {
+		idx := Index{Schema: schema, Table: table}
+		if err := rows.Scan(&idx.Name, &idx.Definition); err != nil {
+			return nil, errors.NewDatabaseError("get_indexes",
+				fmt.Sprintf("failed to scan index: %v", err), err)
+		}
+		indexes = append(indexes, idx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_indexes",
+			fmt.Sprintf("error iterating indexes: %v", err), err)
+	}
+
+	for _, idx := range indexes {
+		query := fmt.Sprintf("DROP INDEX %s.%s", quoteIdent(schema),
+			quoteIdent(idx.Name))
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return nil, errors.NewDatabaseError("drop_index",
+				fmt.Sprintf("failed to drop index %s on %s.%s: %v", idx.Name,
+					schema, table, err), err)
+		}
+	}
+
+	return indexes, nil
+}
+
+// RecreateIndexes creates indexes dropped by DropColumnIndexes again,
+// from their definitions.
+func RecreateIndexes(ctx context.Context, tx *sql.Tx, indexes []Index) error {
+	for _, idx := range indexes {
+		if _, err := tx.ExecContext(ctx, idx.Definition); err != nil {
+			return errors.NewDatabaseError("create_index",
+				fmt.Sprintf("failed to recreate index %s on %s.%s: %v",
+					idx.Name, idx.Schema, idx.Table, err), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestDropColumnIndexes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	definition := "CREATE INDEX users_email_idx ON public.users " +
+		"USING btree (lower((email)::text))"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`a.attname IN \(\$3, \$4\)`).
+		WithArgs("public", "users", "email", "phone").
+		WillReturnRows(sqlmock.NewRows([]string{"relname", "indexdef"}).
+			AddRow("users_email_idx", definition))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DROP INDEX "public"."users_email_idx"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(definition)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	indexes, err := DropColumnIndexes(ctx, tx, "public", "users",
+		[]string{"email", "phone"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(indexes) != 1 || indexes[0].Name != "users_email_idx" ||
+		indexes[0].Table != "users" || indexes[0].Definition != definition {
+		t.Errorf("unexpected indexes: %+v", indexes)
+	}
+
+	if err := RecreateIndexes(ctx, tx, indexes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Without columns, nothing is queried
+	indexes, err = DropColumnIndexes(ctx, tx, "public", "users", nil)
+	if err != nil || indexes != nil {
+		t.Errorf("expected no indexes, got %+v, %v", indexes, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
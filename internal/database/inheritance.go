@@ -0,0 +1,250 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// inheritedTable is a table of an inheritance hierarchy, such as a
+// partition of a partitioned table.
+type inheritedTable struct {
+	schema, table string
+	relkind       string // pg_class.relkind, r for an ordinary table
+	hasChildren   bool   // Whether other tables inherit from it
+}
+
+// MaterializedView is a materialized view.
+type MaterializedView struct {
+	Schema string
+	Name   string
+}
+
+// String returns the view's name as schema.name.
+func (m MaterializedView) String() string {
+	return m.Schema + "." + m.Name
+}
+
+// ExpandInheritance adds, for each column of a table that other tables
+// inherit from, an entry for the column in each of those tables, directly
+// or through others, copying the pattern and other settings, so that the
+// rows of the whole hierarchy are anonymized, each table on its own. A
+// partitioned table, which holds no rows itself, is replaced by its
+// partitions. Explicitly listed columns take precedence over inherited
+// ones. It also returns the tables that others inherit from, keyed by
+// schema.table, whose own rows must be read and updated with ONLY.
+func (v *SchemaValidator) ExpandInheritance(ctx context.Context,
+	columns []config.ColumnConfig) ([]config.ColumnConfig, map[string]bool,
+	error) {
+
+	seen := make(map[string]bool)
+	for _, col := range columns {
+		seen[col.Column] = true
+	}
+
+	hierarchies := make(map[string][]inheritedTable)
+	only := make(map[string]bool)
+	var expanded []config.ColumnConfig
+	for _, col := range columns {
+		ref, err := errors.ParseColumnRef(col.Column)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		table := ref.Schema + "." + ref.Table
+		tree, ok := hierarchies[table]
+		if !ok {
+			tree, err = v.getInheritanceTree(ctx, ref.Schema, ref.Table)
+			if err != nil {
+				return nil, nil, err
+			}
+			hierarchies[table] = tree
+		}
+		if len(tree) == 0 || !tree[0].hasChildren {
+			expanded = append(expanded, col)
+			continue
+		}
+
+		for i, t := range tree {
+			if t.relkind != "r" {
+				continue // Partitioned tables and foreign tables
+			}
+			if t.hasChildren {
+				only[t.schema+"."+t.table] = true
+			}
+			name := errors.ColumnRef{Schema: t.schema, Table: t.table,
+				Column: ref.Column}.String()
+			if i > 0 && seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			entry := col
+			entry.Column = name
+			expanded = append(expanded, entry)
+		}
+	}
+
+	return expanded, only, nil
+}
+
+// getInheritanceTree returns a table, followed by the tables inheriting
+// from it, directly or through others, nearest first. It returns nothing
+// if the table does not exist.
+func (v *SchemaValidator) getInheritanceTree(ctx context.Context, schema,
+	table string) ([]inheritedTable, error) {
+
+	query := `
+        WITH RECURSIVE tree AS (
+            SELECT c.oid, 0 AS depth
+            FROM pg_class c
+            JOIN pg_namespace n ON n.oid = c.relnamespace
+            WHERE n.nspname = $1
+              AND c.relname = $2
+            UNION ALL
+            SELECT h.inhrelid, t.depth + 1
+            FROM tree t
+            JOIN pg_inherits h ON h.inhparent = t.oid
+        )
+        SELECT n.nspname, c.relname, c.relkind::text,
+               EXISTS (SELECT 1 FROM pg_inherits h WHERE h.inhparent = c.oid)
+        FROM tree t
+        JOIN pg_class c ON c.oid = t.oid
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        ORDER BY t.depth, n.nspname, c.relname
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_inheritance",
+			fmt.Sprintf("failed to get tables inheriting from %s.%s: %v",
+				schema, table, err), err)
+	}
+	defer rows.Close()
+
+	var tree []inheritedTable
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var t inheritedTable
+		if err := rows.Scan(&t.schema, &t.table, &t.relkind,
+			&t.hasChildren); err != nil {
+			return nil, errors.NewDatabaseError("get_inheritance",
+				fmt.Sprintf("failed to scan table: %v", err), err)
+		}
+		// A table inheriting from several tables of the tree is listed
+		// once
+		if !seen[t.schema+"."+t.table] {
+			seen[t.schema+"."+t.table] = true
+			tree = append(tree, t)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_inheritance",
+			fmt.Sprintf("error iterating tables: %v", err), err)
+	}
+
+	return tree, nil
+}
+
+// GetDependentMatviews returns the populated materialized views that read
+// any of the given tables, each as schema.table, directly or through
+// views and other materialized views, ordered so that each comes after
+// the materialized views it reads.
+func (v *SchemaValidator) GetDependentMatviews(ctx context.Context,
+	tables []string) ([]MaterializedView, error) {
+
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	schemas := make([]string, len(tables))
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		schemas[i], names[i], _ = strings.Cut(t, ".")
+	}
+
+	query := `
+        WITH RECURSIVE deps AS (
+            SELECT r.ev_class AS oid, 1 AS depth
+            FROM pg_depend d
+            JOIN pg_rewrite r ON r.oid = d.objid
+            WHERE d.classid = 'pg_rewrite'::regclass
+              AND d.refclassid = 'pg_class'::regclass
+              AND d.refobjid IN (
+                  SELECT c.oid
+                  FROM unnest($1::text[], $2::text[]) AS t(nspname, relname)
+                  JOIN pg_namespace n ON n.nspname = t.nspname
+                  JOIN pg_class c ON c.relnamespace = n.oid
+                                 AND c.relname = t.relname
+              )
+              AND r.ev_class <> d.refobjid
+            UNION ALL
+            SELECT r.ev_class, deps.depth + 1
+            FROM deps
+            JOIN pg_depend d ON d.refobjid = deps.oid
+                            AND d.classid = 'pg_rewrite'::regclass
+                            AND d.refclassid = 'pg_class'::regclass
+            JOIN pg_rewrite r ON r.oid = d.objid
+            WHERE r.ev_class <> deps.oid
+        )
+        SELECT n.nspname, c.relname
+        FROM deps
+        JOIN pg_class c ON c.oid = deps.oid
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE c.relkind = 'm'
+          AND c.relispopulated
+        GROUP BY n.nspname, c.relname
+        ORDER BY max(deps.depth), n.nspname, c.relname
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, schemas, names)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_matviews",
+			fmt.Sprintf("failed to get materialized views: %v", err), err)
+	}
+	defer rows.Close()
+
+	var views []MaterializedView
+	for rows.Next() {
+		var m MaterializedView
+		if err := rows.Scan(&m.Schema, &m.Name); err != nil {
+			return nil, errors.NewDatabaseError("get_matviews",
+				fmt.Sprintf("failed to scan materialized view: %v", err), err)
+		}
+		views = append(views, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_matviews",
+			fmt.Sprintf("error iterating materialized views: %v", err), err)
+	}
+
+	return views, nil
+}
+
+// RefreshMatview refreshes a materialized view in a transaction.
+func RefreshMatview(ctx context.Context, tx *sql.Tx, m MaterializedView) error {
+	query := fmt.Sprintf("REFRESH MATERIALIZED VIEW %s.%s",
+		quoteIdent(m.Schema), quoteIdent(m.Name))
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return errors.NewDatabaseError("refresh_matview",
+			fmt.Sprintf("failed to refresh materialized view %s: %v", m, err),
+			err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+)
+
+func TestExpandInheritance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	treeColumns := []string{"nspname", "relname", "relkind", "exists"}
+
+	// A partitioned table, with a partition that is partitioned in turn
+	mock.ExpectQuery(`pg_inherits`).
+		WithArgs("public", "events").
+		WillReturnRows(sqlmock.NewRows(treeColumns).
+			AddRow("public", "events", "p", true).
+			AddRow("public", "events_2025", "r", false).
+			AddRow("public", "events_2026", "p", true).
+			AddRow("public", "events_2026_01", "r", false))
+
+	// A table that others inherit from, with one listed explicitly
+	mock.ExpectQuery(`pg_inherits`).
+		WithArgs("public", "people").
+		WillReturnRows(sqlmock.NewRows(treeColumns).
+			AddRow("public", "people", "r", true).
+			AddRow("public", "staff", "r", false).
+			AddRow("public", "remote_people", "f", false))
+	mock.ExpectQuery(`pg_inherits`).
+		WithArgs("public", "staff").
+		WillReturnRows(sqlmock.NewRows(treeColumns).
+			AddRow("public", "staff", "r", false))
+
+	// A table without inheritance
+	mock.ExpectQuery(`pg_inherits`).
+		WithArgs("public", "users").
+		WillReturnRows(sqlmock.NewRows(treeColumns).
+			AddRow("public", "users", "r", false))
+
+	columns := []config.ColumnConfig{
+		{Column: "public.events.ip", Pattern: "IPV4_ADDRESS"},
+		{Column: "public.people.email", Pattern: "EMAIL"},
+		{Column: "public.staff.email", Pattern: "WORK_EMAIL"},
+		{Column: "public.users.name", Pattern: "PERSON_NAME"},
+	}
+
+	v := NewSchemaValidator(db)
+	expanded, only, err := v.ExpandInheritance(context.Background(),
+		columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []config.ColumnConfig{
+		{Column: "public.events_2025.ip", Pattern: "IPV4_ADDRESS"},
+		{Column: "public.events_2026_01.ip", Pattern: "IPV4_ADDRESS"},
+		{Column: "public.people.email", Pattern: "EMAIL"},
+		{Column: "public.staff.email", Pattern: "WORK_EMAIL"},
+		{Column: "public.users.name", Pattern: "PERSON_NAME"},
+	}
+	if len(expanded) != len(want) {
+		t.Fatalf("expected %d columns, got %d: %+v", len(want),
+			len(expanded), expanded)
+	}
+	for i, w := range want {
+		if expanded[i].Column != w.Column || expanded[i].Pattern != w.Pattern {
+			t.Errorf("column %d: expected %s (%s), got %s (%s)", i,
+				w.Column, w.Pattern, expanded[i].Column, expanded[i].Pattern)
+		}
+	}
+
+	if len(only) != 1 || !only["public.people"] {
+		t.Errorf("expected only public.people to be read with ONLY, got %v",
+			only)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+func TestGetDependentMatviews(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyConverter{}))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`c.relkind = 'm'`).
+		WithArgs([]string{"public", "sales"}, []string{"users", "orders"}).
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname"}).
+			AddRow("public", "user_counts").
+			AddRow("reports", "monthly"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`REFRESH MATERIALIZED VIEW "reports"."monthly"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	v := NewSchemaValidator(db)
+	views, err := v.GetDependentMatviews(ctx,
+		[]string{"public.users", "sales.orders"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(views) != 2 || views[0].String() != "public.user_counts" ||
+		views[1].String() != "reports.monthly" {
+		t.Errorf("unexpected materialized views: %+v", views)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := RefreshMatview(ctx, tx, views[1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
@@ -0,0 +1,397 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// PathRowData represents a row fetched with the string values found at a
+// set of JSONB paths.
+type PathRowData struct {
+	CTID   string           // PostgreSQL physical row ID
+	Values []sql.NullString // One per path; invalid if not a string value
+}
+
+// JSONBPathBatchProcessor handles batch reading and writing of individual
+// values within a JSONB column. Values are extracted server-side with #>>
+// and written back with jsonb_set, so whole documents never leave the
+// database.
+type JSONBPathBatchProcessor struct {
+	tx        *sql.Tx
+	column    errors.ColumnRef
+	paths     [][]string
+	batchSize int
+
+	// Rows read by each FETCH, and the most rows written by each update
+	// statement; zero reads or writes a whole batch at once
+	fetchSize       int
+	updateChunkSize int
+
+	// Statement size limit, and the size of the largest statement written
+	maxStatementBytes int64
+	statementSizes    statementSizes
+	phases            PhaseTimes
+
+	// Commits the transaction between batches, if set
+	commit CommitFunc
+
+	// Limits the rate at which rows are read, if set
+	throttle *Throttle
+
+	// Reads only a random sample of the rows, if set
+	sample tableSample
+
+	// Reads and updates the table's own rows only, and not those of the
+	// tables inheriting from it, if set
+	only bool
+
+	// Cursor state
+	cursorName string
+	cursorOpen bool
+}
+
+// NewJSONBPathBatchProcessor creates a new JSONB path batch processor.
+// Each path is in PostgreSQL text[] form, e.g. ["users", "0", "email"].
+func NewJSONBPathBatchProcessor(tx *sql.Tx, col errors.ColumnRef,
+	paths [][]string, batchSize int) *JSONBPathBatchProcessor {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	return &JSONBPathBatchProcessor{
+		tx:        tx,
+		column:    col,
+		paths:     paths,
+		batchSize: batchSize,
+		cursorName: fmt.Sprintf("anon_%s_%s_%s_paths",
+			col.Schema, col.Table, col.Column),
+	}
+}
+
+// SetFetchSize sets the number of rows read from the cursor by each FETCH;
+// zero or less reads a whole batch with a single FETCH.
+func (p *JSONBPathBatchProcessor) SetFetchSize(n int) {
+	p.fetchSize = n
+}
+
+// SetUpdateChunkSize sets the most rows written by each batch update
+// statement; zero or less writes a whole batch with a single statement,
+// subject to the limit on its size.
+func (p *JSONBPathBatchProcessor) SetUpdateChunkSize(n int) {
+	p.updateChunkSize = n
+}
+
+// SetCommit makes EndBatch commit the transaction after each batch, and
+// continue in the transaction commit returns. The cursor is then declared
+// WITH HOLD, so that it remains open across the commits.
+func (p *JSONBPathBatchProcessor) SetCommit(commit CommitFunc) {
+	p.commit = commit
+}
+
+// SetThrottle limits the rate at which rows are read, and pauses after
+// each batch, as the throttle sets.
+func (p *JSONBPathBatchProcessor) SetThrottle(throttle *Throttle) {
+	p.throttle = throttle
+}
+
+// SetSample makes the processor read only a random sample of about
+// percent of the table's rows; zero or less, or 100 or more, reads them
+// all.
+func (p *JSONBPathBatchProcessor) SetSample(percent float64) {
+	p.sample = newTableSample(percent)
+}
+
+// SetOnly makes the processor read and update only the rows of the table
+// itself, and not those of the tables inheriting from it, which are
+// anonymized as tables of their own.
+func (p *JSONBPathBatchProcessor) SetOnly(only bool) {
+	p.only = only
+}
+
+// table returns the table's quoted name, with ONLY if it is set.
+func (p *JSONBPathBatchProcessor) table() string {
+	return tableName(p.column, p.only)
+}
+
+// EndBatch commits the transaction after a batch has been written, if the
+// processor commits between batches, and then pauses if it is throttled.
+func (p *JSONBPathBatchProcessor) EndBatch(ctx context.Context) error {
+	if p.commit != nil {
+		start := time.Now()
+		tx, err := p.commit(ctx)
+		since(&p.phases.Update, start)
+		if err != nil {
+			return err
+		}
+		p.tx = tx
+	}
+	defer since(&p.phases.Wait, time.Now())
+	return p.throttle.Pause(ctx)
+}
+
+// SetMaxStatementBytes sets the limit on the size of a batch update
+// statement; zero or less uses DefaultMaxStatementBytes.
+func (p *JSONBPathBatchProcessor) SetMaxStatementBytes(n int64) {
+	p.maxStatementBytes = n
+}
+
+// MaxStatementBytes returns the size of the largest batch update
+// statement written, counting the query and its parameters.
+func (p *JSONBPathBatchProcessor) MaxStatementBytes() int64 {
+	return p.statementSizes.max
+}
+
+// PhaseTimes returns the time spent reading rows, writing them back, and
+// throttled.
+func (p *JSONBPathBatchProcessor) PhaseTimes() PhaseTimes {
+	return p.phases
+}
+
+// OpenCursor declares a server-side cursor returning the string value at
+// each path for every row.
+func (p *JSONBPathBatchProcessor) OpenCursor(ctx context.Context) error {
+	defer since(&p.phases.Fetch, time.Now())
+
+	col := quoteIdent(p.column.Column)
+
+	selects := make([]string, len(p.paths))
+	for i, path := range p.paths {
+		lit := quoteTextArray(path)
+		selects[i] = fmt.Sprintf(
+			"CASE WHEN jsonb_typeof(%s #> %s) = 'string' THEN %s #>> %s END",
+			col, lit, col, lit)
+	}
+
+	query := fmt.Sprintf(
+		`DECLARE %s CURSOR %sFOR
+         SELECT ctid::text, %s
+         FROM %s%s
+         WHERE %s IS NOT NULL`,
+		p.cursorName,
+		cursorHold(p.commit != nil),
+		strings.Join(selects, ", "),
+		p.table(),
+		p.sample.clause(),
+		col,
+	)
+
+	_, err := p.tx.ExecContext(ctx, query)
+	if err != nil {
+		return errors.NewDatabaseErrorWithColumn("cursor_open", p.column,
+			fmt.Sprintf("failed to declare cursor: %v", err), err)
+	}
+
+	p.cursorOpen = true
+	return nil
+}
+
+// FetchBatch fetches the next batch of rows from the cursor, with as many
+// FETCH statements as the fetch size requires.
+func (p *JSONBPathBatchProcessor) FetchBatch(ctx context.Context) (
+	[]PathRowData, error) {
+
+	if !p.cursorOpen {
+		return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+			"cursor not open", nil)
+	}
+
+	return fetchBatch(p.batchSize, p.fetchSize,
+		func(n int) ([]PathRowData, error) {
+			rows, err := p.fetch(ctx, n)
+			if err != nil {
+				return nil, err
+			}
+			defer since(&p.phases.Wait, time.Now())
+			return rows, p.throttle.Wait(ctx, len(rows))
+		})
+}
+
+// fetch reads up to n rows from the cursor.
+func (p *JSONBPathBatchProcessor) fetch(ctx context.Context, n int) (
+	[]PathRowData, error) {
+
+	defer since(&p.phases.Fetch, time.Now())
+
+	query := fmt.Sprintf("FETCH %d FROM %s", n, p.cursorName)
+	rows, err := p.tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+			fmt.Sprintf("failed to fetch from cursor: %v", err), err)
+	}
+	defer rows.Close()
+
+	var batch []PathRowData
+	for rows.Next() {
+		rd := PathRowData{Values: make([]sql.NullString, len(p.paths))}
+		dest := make([]any, 0, len(p.paths)+1)
+		dest = append(dest, &rd.CTID)
+		for i := range rd.Values {
+			dest = append(dest, &rd.Values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+				fmt.Sprintf("failed to scan row: %v", err), err)
+		}
+		batch = append(batch, rd)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseErrorWithColumn("fetch", p.column,
+			fmt.Sprintf("error iterating rows: %v", err), err)
+	}
+
+	return batch, nil
+}
+
+// CloseCursor closes the server-side cursor.
+func (p *JSONBPathBatchProcessor) CloseCursor(ctx context.Context) error {
+	if !p.cursorOpen {
+		return nil
+	}
+
+	_, err := p.tx.ExecContext(ctx, fmt.Sprintf("CLOSE %s", p.cursorName))
+	if err != nil {
+		return errors.NewDatabaseErrorWithColumn("cursor_close", p.column,
+			fmt.Sprintf("failed to close cursor: %v", err), err)
+	}
+
+	p.cursorOpen = false
+	return nil
+}
+
+// UpdateBatch writes new values at each path for multiple rows in a single
+// statement, or in several if the values would make the statement larger
+// than the limit. The updates map is keyed by CTID; each value slice holds one
+// entry per path, with nil meaning the value at that path is left as is.
+func (p *JSONBPathBatchProcessor) UpdateBatch(ctx context.Context,
+	updates map[string][]*string) error {
+
+	defer since(&p.phases.Update, time.Now())
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	// Build arrays for unnest: one for the CTIDs, then one per path
+	ctids := make([]string, 0, len(updates))
+	values := make([][]*string, len(p.paths))
+	for ctid, vals := range updates {
+		ctids = append(ctids, ctid)
+		for i := range p.paths {
+			var v *string
+			if i < len(vals) {
+				v = vals[i]
+			}
+			values[i] = append(values[i], v)
+		}
+	}
+
+	unnestArgs := []string{"$1::tid[]"}
+	unnestCols := []string{"ctid"}
+	for i := range p.paths {
+		unnestArgs = append(unnestArgs, fmt.Sprintf("$%d::text[]", i+2))
+		unnestCols = append(unnestCols, fmt.Sprintf("v%d", i))
+	}
+
+	// Chain jsonb_set calls, one per path. When a row has no new value for
+	// a path, the original value at that path is written back so the
+	// document is unchanged; create_missing is false so absent paths are
+	// never added.
+	col := "t." + quoteIdent(p.column.Column)
+	expr := col
+	for i, path := range p.paths {
+		lit := quoteTextArray(path)
+		expr = fmt.Sprintf(
+			"jsonb_set(%s, %s, COALESCE(to_jsonb(u.v%d), %s #> %s, 'null'::jsonb), false)",
+			expr, lit, i, col, lit)
+	}
+
+	query := fmt.Sprintf(`
+        UPDATE %s t
+        SET %s = %s
+        FROM unnest(%s) AS u(%s)
+        WHERE t.ctid = u.ctid`,
+		p.table(),
+		quoteIdent(p.column.Column),
+		expr,
+		strings.Join(unnestArgs, ", "),
+		strings.Join(unnestCols, ", "),
+	)
+
+	// Write the rows in chunks that keep each statement under the limits
+	chunks := splitBySize(len(ctids), int64(len(query)), p.maxStatementBytes,
+		p.updateChunkSize, func(i int) int64 {
+			n := int64(len(ctids[i]))
+			for _, vals := range values {
+				if vals[i] != nil {
+					n += int64(len(*vals[i]))
+				}
+			}
+			return n
+		})
+	for _, c := range chunks {
+		args := make([]any, 0, len(p.paths)+1)
+		args = append(args, ctids[c.start:c.end])
+		for _, vals := range values {
+			args = append(args, vals[c.start:c.end])
+		}
+		_, err := p.tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return errors.NewDatabaseErrorWithColumn("batch_update", p.column,
+				fmt.Sprintf("failed to batch update: %v", err), err)
+		}
+		p.statementSizes.record(c.bytes)
+	}
+
+	return nil
+}
+
+// PathExists reports whether the path at the given index resolves to a
+// value of any type, including JSON null, in at least one row.
+func (p *JSONBPathBatchProcessor) PathExists(ctx context.Context,
+	index int) (bool, error) {
+
+	col := quoteIdent(p.column.Column)
+	query := fmt.Sprintf(
+		`SELECT EXISTS (SELECT 1 FROM %s WHERE %s #> %s IS NOT NULL)`,
+		p.table(),
+		col,
+		quoteTextArray(p.paths[index]),
+	)
+
+	var exists bool
+	if err := p.tx.QueryRowContext(ctx, query).Scan(&exists); err != nil {
+		return false, errors.NewDatabaseErrorWithColumn("query", p.column,
+			fmt.Sprintf("failed to check JSON path: %v", err), err)
+	}
+
+	return exists, nil
+}
+
+// quoteTextArray renders a path as a quoted PostgreSQL text[] literal,
+// e.g. '{"users","0","email"}'::text[].
+func quoteTextArray(elems []string) string {
+	quoted := make([]string, len(elems))
+	for i, e := range elems {
+		e = strings.ReplaceAll(e, `\`, `\\`)
+		e = strings.ReplaceAll(e, `"`, `\"`)
+		quoted[i] = `"` + e + `"`
+	}
+	arr := "{" + strings.Join(quoted, ",") + "}"
+	return "'" + strings.ReplaceAll(arr, "'", "''") + "'::text[]"
+}
@@ -0,0 +1,88 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+func TestQuoteTextArray(t *testing.T) {
+	tests := []struct {
+		elems []string
+		want  string
+	}{
+		{[]string{"email"}, `'{"email"}'::text[]`},
+		{[]string{"users", "0", "email"}, `'{"users","0","email"}'::text[]`},
+		{[]string{`it's`}, `'{"it''s"}'::text[]`},
+		{[]string{`a"b`}, `'{"a\"b"}'::text[]`},
+		{[]string{`a\b`}, `'{"a\\b"}'::text[]`},
+	}
+
+	for _, tt := range tests {
+		got := quoteTextArray(tt.elems)
+		if got != tt.want {
+			t.Errorf("quoteTextArray(%q) = %s, want %s", tt.elems, got, tt.want)
+		}
+	}
+}
+
+func TestPathExists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT EXISTS (SELECT 1 FROM "public"."users" WHERE "profile" #> '{"address","city"}'::text[] IS NOT NULL)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`WHERE "profile" #> '{"ssn"}'::text[] IS NOT NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "profile"}
+	batch := NewJSONBPathBatchProcessor(tx, col,
+		[][]string{{"address", "city"}, {"ssn"}}, 0)
+
+	ctx := context.Background()
+
+	exists, err := batch.PathExists(ctx, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !exists {
+		t.Error("expected path 0 to exist")
+	}
+
+	exists, err = batch.PathExists(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exists {
+		t.Error("expected path 1 not to exist")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
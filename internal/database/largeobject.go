@@ -0,0 +1,73 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// invWrite is the INV_WRITE mode large objects are opened for writing in.
+const invWrite = 0x20000
+
+// ReadLargeObject returns the contents of a large object, by its OID, in
+// a transaction. It returns false if there is no large object with the
+// OID, such as one whose reference was left behind when it was unlinked.
+func ReadLargeObject(ctx context.Context, tx *sql.Tx,
+	oid string) ([]byte, bool, error) {
+
+	var data []byte
+	err := tx.QueryRowContext(ctx, `
+        SELECT lo_get(oid)
+        FROM pg_largeobject_metadata
+        WHERE oid = $1::oid`, oid).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.NewDatabaseError("read_large_object",
+			fmt.Sprintf("failed to read large object %s: %v", oid, err), err)
+	}
+	return data, true, nil
+}
+
+// WriteLargeObject replaces the contents of a large object, in a
+// transaction. The large object keeps its OID, owner, and privileges, so
+// that the rows referencing it need not change.
+func WriteLargeObject(ctx context.Context, tx *sql.Tx, oid string,
+	data []byte) error {
+
+	var fd int
+	if err := tx.QueryRowContext(ctx, "SELECT lo_open($1::oid, $2)", oid,
+		invWrite).Scan(&fd); err != nil {
+		return errors.NewDatabaseError("write_large_object",
+			fmt.Sprintf("failed to open large object %s: %v", oid, err), err)
+	}
+
+	for _, step := range []struct {
+		query string
+		args  []any
+	}{
+		{"SELECT lo_truncate64($1, 0)", []any{fd}},
+		{"SELECT lowrite($1, $2)", []any{fd, data}},
+		{"SELECT lo_close($1)", []any{fd}},
+	} {
+		if _, err := tx.ExecContext(ctx, step.query, step.args...); err != nil {
+			return errors.NewDatabaseError("write_large_object",
+				fmt.Sprintf("failed to write large object %s: %v", oid, err),
+				err)
+		}
+	}
+	return nil
+}
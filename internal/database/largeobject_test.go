@@ -0,0 +1,90 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestReadLargeObject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`lo_get`).WithArgs("16401").
+		WillReturnRows(sqlmock.NewRows([]string{"lo_get"}).
+			AddRow([]byte("Dear Jane")))
+	mock.ExpectQuery(`lo_get`).WithArgs("16402").
+		WillReturnRows(sqlmock.NewRows([]string{"lo_get"}))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	data, ok, err := ReadLargeObject(ctx, tx, "16401")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok || string(data) != "Dear Jane" {
+		t.Errorf("unexpected contents %q (found %v)", data, ok)
+	}
+
+	// A dangling reference is not an error
+	if _, ok, err := ReadLargeObject(ctx, tx, "16402"); err != nil || ok {
+		t.Errorf("expected a missing large object, got %v, %v", ok, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+func TestWriteLargeObject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT lo_open($1::oid, $2)")).
+		WithArgs("16401", invWrite).
+		WillReturnRows(sqlmock.NewRows([]string{"lo_open"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT lo_truncate64($1, 0)")).
+		WithArgs(0).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT lowrite($1, $2)")).
+		WithArgs(0, []byte("Dear Joan")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT lo_close($1)")).
+		WithArgs(0).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := WriteLargeObject(context.Background(), tx, "16401",
+		[]byte("Dear Joan")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// lockNotAvailable is the SQLSTATE of an error raised when a lock cannot
+// be acquired within lock_timeout.
+const lockNotAvailable = "55P03"
+
+// LockHolder is a session holding a lock on a table.
+type LockHolder struct {
+	PID             int
+	Mode            string // The lock mode, such as AccessExclusiveLock
+	ApplicationName string
+	State           string // The session state, such as idle in transaction
+}
+
+// String describes the session for display.
+func (h LockHolder) String() string {
+	s := fmt.Sprintf("pid %d (%s", h.PID, h.Mode)
+	if h.ApplicationName != "" {
+		s += ", " + h.ApplicationName
+	}
+	if h.State != "" {
+		s += ", " + h.State
+	}
+	return s + ")"
+}
+
+// LockTable takes the lock an update of a table takes, ROW EXCLUSIVE, in
+// a transaction, waiting for any session holding a conflicting lock.
+func LockTable(ctx context.Context, tx *sql.Tx, schema, table string) error {
+	query := fmt.Sprintf("LOCK TABLE %s.%s IN ROW EXCLUSIVE MODE",
+		quoteIdent(schema), quoteIdent(table))
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return errors.NewDatabaseError("lock",
+			fmt.Sprintf("failed to lock %s.%s: %v", schema, table, err), err)
+	}
+	return nil
+}
+
+// IsLockTimeout returns true if err was caused by a lock not being
+// acquired within lock_timeout.
+func IsLockTimeout(err error) bool {
+	var pgErr *pgconn.PgError
+	return stderrors.As(err, &pgErr) && pgErr.Code == lockNotAvailable
+}
+
+// GetLockHolders returns the other sessions holding a lock on a table.
+func (v *SchemaValidator) GetLockHolders(ctx context.Context, schema,
+	table string) ([]LockHolder, error) {
+
+	query := `
+        SELECT l.pid, l.mode, COALESCE(a.application_name, ''),
+               COALESCE(a.state, '')
+        FROM pg_locks l
+        LEFT JOIN pg_stat_activity a ON a.pid = l.pid
+        WHERE l.locktype = 'relation'
+          AND l.relation = to_regclass($1)
+          AND l.granted
+          AND l.pid <> pg_backend_pid()
+        ORDER BY l.pid, l.mode
+    `
+
+	rows, err := v.db.QueryContext(ctx, query,
+		quoteIdent(schema)+"."+quoteIdent(table))
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_lock_holders",
+			fmt.Sprintf("failed to get lock holders: %v", err), err)
+	}
+	defer rows.Close()
+
+	var holders []LockHolder
+	for rows.Next() {
+		var h LockHolder
+		if err := rows.Scan(&h.PID, &h.Mode, &h.ApplicationName,
+			&h.State); err != nil {
+			return nil, errors.NewDatabaseError("get_lock_holders",
+				fmt.Sprintf("failed to scan lock holder: %v", err), err)
+		}
+		holders = append(holders, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_lock_holders",
+			fmt.Sprintf("error iterating lock holders: %v", err), err)
+	}
+
+	return holders, nil
+}
@@ -0,0 +1,65 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+func TestGetLockHolders(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+
+	mock.ExpectQuery(`FROM pg_locks l`).
+		WithArgs(`"public"."users"`).
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"pid", "mode", "application_name", "state"}).
+			AddRow(4242, "AccessExclusiveLock", "psql", "idle in transaction"))
+
+	holders, err := v.GetLockHolders(context.Background(), "public", "users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(holders) != 1 ||
+		holders[0].String() != "pid 4242 (AccessExclusiveLock, psql, idle in transaction)" {
+		t.Errorf("unexpected holders: %+v", holders)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+func TestIsLockTimeout(t *testing.T) {
+	timeout := &pgconn.PgError{Code: "55P03",
+		Message: "canceling statement due to lock timeout"}
+	wrapped := errors.NewDatabaseError("update", "failed to update batch",
+		fmt.Errorf("batch: %w", timeout))
+
+	if !IsLockTimeout(wrapped) {
+		t.Error("expected a wrapped lock timeout to be recognized")
+	}
+	if IsLockTimeout(&pgconn.PgError{Code: "57014"}) {
+		t.Error("expected a statement timeout not to be a lock timeout")
+	}
+}
@@ -0,0 +1,28 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import "time"
+
+// PhaseTimes holds the time a batch processor spent in the database,
+// reading and writing rows, and throttled, so that the time spent
+// generating values can be told apart from it.
+type PhaseTimes struct {
+	Fetch  time.Duration // Declaring the cursor and reading rows
+	Update time.Duration // Writing rows back, and committing them
+	Wait   time.Duration // Waiting for the rate limit, or between batches
+}
+
+// since adds the time since start to a phase; it is deferred at the start
+// of the work timed.
+func since(phase *time.Duration, start time.Time) {
+	*phase += time.Since(start)
+}
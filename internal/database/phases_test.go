@@ -0,0 +1,40 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// TestPhaseTimesEndBatch tests that the commit after a batch counts as
+// updating, and the pause after it as waiting
+func TestPhaseTimesEndBatch(t *testing.T) {
+	p := NewBatchProcessor(nil, errors.ColumnRef{}, "text", 10)
+	p.SetThrottle(NewThrottle(0, 5*time.Millisecond))
+	p.SetCommit(func(ctx context.Context) (*sql.Tx, error) {
+		time.Sleep(5 * time.Millisecond)
+		return nil, nil
+	})
+
+	if err := p.EndBatch(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	phases := p.PhaseTimes()
+	if phases.Update < 5*time.Millisecond || phases.Wait < 5*time.Millisecond ||
+		phases.Fetch != 0 {
+		t.Errorf("unexpected phase times: %+v", phases)
+	}
+}
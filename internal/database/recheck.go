@@ -0,0 +1,187 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// ConstraintViolation is a constraint the rows of a table do not satisfy.
+type ConstraintViolation struct {
+	Table      string // Schema-qualified and quoted
+	Constraint string
+	Err        error // The error adding the constraint again
+}
+
+// tableConstraint is a constraint to recheck.
+type tableConstraint struct {
+	name       string
+	foreignKey bool
+	table      string
+	definition string // As pg_get_constraintdef shows it
+}
+
+// RecheckConstraints checks that the rows of every table outside the
+// system schemas satisfy their primary key, unique, CHECK, exclusion, and
+// foreign key constraints, as if they were added now. Each constraint is
+// dropped and added again, which checks every row, in a transaction that
+// is rolled back, so the schema is left unchanged; the tables are locked
+// exclusively meanwhile, so this is meant for disposable databases. A
+// constraint that cannot be dropped, as something else depends on it, is
+// skipped with a warning.
+func (v *SchemaValidator) RecheckConstraints(
+	ctx context.Context) ([]ConstraintViolation, error) {
+
+	constraints, err := v.listConstraints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := v.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.NewDatabaseError("recheck_constraints",
+			fmt.Sprintf("failed to begin transaction: %v", err), err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Foreign keys depend on the keys they reference, so they are all
+	// dropped before the keys are rechecked, and added again last
+	for _, con := range constraints {
+		if !con.foreignKey {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"ALTER TABLE %s DROP CONSTRAINT %s", con.table,
+			quoteIdent(con.name))); err != nil {
+			return nil, errors.NewDatabaseError("recheck_constraints",
+				fmt.Sprintf("failed to drop constraint %s on %s: %v",
+					con.name, con.table, err), err)
+		}
+	}
+
+	var violations []ConstraintViolation
+	for _, foreignKeys := range []bool{false, true} {
+		for _, con := range constraints {
+			if con.foreignKey != foreignKeys {
+				continue
+			}
+			violation, err := recheck(ctx, tx, con)
+			if err != nil {
+				return nil, err
+			}
+			if violation != nil {
+				violations = append(violations, *violation)
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// recheck adds a constraint again within a savepoint, dropping it first
+// unless it is a foreign key, and returns the violation if it fails.
+func recheck(ctx context.Context, tx *sql.Tx,
+	con tableConstraint) (*ConstraintViolation, error) {
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT recheck"); err != nil {
+		return nil, errors.NewDatabaseError("recheck_constraints",
+			fmt.Sprintf("failed to create savepoint: %v", err), err)
+	}
+	rollback := func() error {
+		if _, err := tx.ExecContext(ctx,
+			"ROLLBACK TO SAVEPOINT recheck"); err != nil {
+			return errors.NewDatabaseError("recheck_constraints",
+				fmt.Sprintf("failed to roll back to savepoint: %v", err), err)
+		}
+		return nil
+	}
+
+	if !con.foreignKey {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"ALTER TABLE %s DROP CONSTRAINT %s", con.table,
+			quoteIdent(con.name))); err != nil {
+			log.Printf("Warning: cannot recheck constraint %s on %s: %v",
+				con.name, con.table, err)
+			return nil, rollback()
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"ALTER TABLE %s ADD CONSTRAINT %s %s", con.table,
+		quoteIdent(con.name), con.definition)); err != nil {
+		return &ConstraintViolation{Table: con.table, Constraint: con.name,
+			Err: err}, rollback()
+	}
+
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT recheck"); err != nil {
+		return nil, errors.NewDatabaseError("recheck_constraints",
+			fmt.Sprintf("failed to release savepoint: %v", err), err)
+	}
+	return nil, nil
+}
+
+// listConstraints returns the constraints declared on the tables outside
+// the system schemas, other than those inherited from a parent table or
+// belonging to an extension.
+func (v *SchemaValidator) listConstraints(
+	ctx context.Context) ([]tableConstraint, error) {
+
+	query := `
+        SELECT con.conname, con.contype = 'f',
+               format('%I.%I', n.nspname, c.relname),
+               pg_get_constraintdef(con.oid)
+        FROM pg_constraint con
+        JOIN pg_class c ON c.oid = con.conrelid
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE con.contype IN ('p', 'u', 'c', 'x', 'f')
+          AND con.conislocal
+          AND con.conparentid = 0
+          AND c.relkind IN ('r', 'p')
+          AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+          AND n.nspname NOT LIKE 'pg\_%'
+          AND NOT EXISTS (
+              SELECT 1 FROM pg_depend d
+              WHERE d.classid = 'pg_class'::regclass
+                AND d.objid = c.oid
+                AND d.deptype = 'e')
+        ORDER BY n.nspname, c.relname, con.conname
+    `
+
+	rows, err := v.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.NewDatabaseError("recheck_constraints",
+			fmt.Sprintf("failed to list constraints: %v", err), err)
+	}
+	defer rows.Close()
+
+	var constraints []tableConstraint
+	for rows.Next() {
+		var con tableConstraint
+		if err := rows.Scan(&con.name, &con.foreignKey, &con.table,
+			&con.definition); err != nil {
+			return nil, errors.NewDatabaseError("recheck_constraints",
+				fmt.Sprintf("failed to scan constraint: %v", err), err)
+		}
+		constraints = append(constraints, con)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("recheck_constraints",
+			fmt.Sprintf("error iterating constraints: %v", err), err)
+	}
+
+	return constraints, nil
+}
@@ -0,0 +1,101 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRecheckConstraints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+
+	mock.ExpectQuery(`pg_get_constraintdef`).
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"conname", "fk", "table", "def"}).
+			AddRow("orders_customer_fk", true, "app.orders",
+				"FOREIGN KEY (customer_id) REFERENCES app.customers(id)").
+			AddRow("customers_pkey", false, "app.customers",
+				"PRIMARY KEY (id)").
+			AddRow("customers_email_key", false, "app.customers",
+				"UNIQUE (email)").
+			AddRow("customers_view_key", false, "app.customers",
+				"UNIQUE (name)"))
+
+	exec := func(query string) *sqlmock.ExpectedExec {
+		return mock.ExpectExec(regexp.QuoteMeta(query))
+	}
+	ok := sqlmock.NewResult(0, 0)
+
+	mock.ExpectBegin()
+	exec(`ALTER TABLE app.orders DROP CONSTRAINT "orders_customer_fk"`).
+		WillReturnResult(ok)
+
+	// A key that holds
+	exec("SAVEPOINT recheck").WillReturnResult(ok)
+	exec(`ALTER TABLE app.customers DROP CONSTRAINT "customers_pkey"`).
+		WillReturnResult(ok)
+	exec(`ALTER TABLE app.customers ADD CONSTRAINT "customers_pkey" ` +
+		`PRIMARY KEY (id)`).WillReturnResult(ok)
+	exec("RELEASE SAVEPOINT recheck").WillReturnResult(ok)
+
+	// A key that no longer holds
+	exec("SAVEPOINT recheck").WillReturnResult(ok)
+	exec(`ALTER TABLE app.customers DROP CONSTRAINT "customers_email_key"`).
+		WillReturnResult(ok)
+	exec(`ALTER TABLE app.customers ADD CONSTRAINT "customers_email_key" ` +
+		`UNIQUE (email)`).WillReturnError(fmt.Errorf("duplicate key"))
+	exec("ROLLBACK TO SAVEPOINT recheck").WillReturnResult(ok)
+
+	// A key something else depends on, which is skipped
+	exec("SAVEPOINT recheck").WillReturnResult(ok)
+	exec(`ALTER TABLE app.customers DROP CONSTRAINT "customers_view_key"`).
+		WillReturnError(fmt.Errorf("other objects depend on it"))
+	exec("ROLLBACK TO SAVEPOINT recheck").WillReturnResult(ok)
+
+	// Foreign keys are added again last, without being dropped again
+	exec("SAVEPOINT recheck").WillReturnResult(ok)
+	exec(`ALTER TABLE app.orders ADD CONSTRAINT "orders_customer_fk" ` +
+		`FOREIGN KEY (customer_id) REFERENCES app.customers(id)`).
+		WillReturnError(fmt.Errorf("violates foreign key constraint"))
+	exec("ROLLBACK TO SAVEPOINT recheck").WillReturnResult(ok)
+	mock.ExpectRollback()
+
+	violations, err := v.RecheckConstraints(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %+v", violations)
+	}
+	if violations[0].Constraint != "customers_email_key" ||
+		violations[0].Table != "app.customers" {
+		t.Errorf("unexpected violation: %+v", violations[0])
+	}
+	if violations[1].Constraint != "orders_customer_fk" ||
+		violations[1].Err == nil {
+		t.Errorf("unexpected violation: %+v", violations[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
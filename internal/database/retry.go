@@ -0,0 +1,99 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	stderrors "errors"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// maxRetryBackoff is the longest wait between attempts to connect.
+const maxRetryBackoff = 30 * time.Second
+
+// Error codes of a server that is shutting down, starting up, or has no
+// connections to spare, which a later attempt may not meet.
+var transientCodes = map[string]bool{
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+	"53300": true, // too_many_connections
+}
+
+// IsTransient returns true if err is a failure to reach the server, or a
+// loss of the connection, that a new connection may not meet, rather
+// than an error, such as a wrong password, that would recur.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// The server's reply decides, even when the connection then closed
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || transientCodes[pgErr.Code]
+	}
+
+	var netErr net.Error
+	switch {
+	case stderrors.Is(err, driver.ErrBadConn),
+		stderrors.Is(err, io.EOF),
+		stderrors.Is(err, io.ErrUnexpectedEOF),
+		stderrors.Is(err, syscall.ECONNREFUSED),
+		stderrors.Is(err, syscall.ECONNRESET),
+		stderrors.Is(err, context.DeadlineExceeded),
+		stderrors.As(err, &netErr):
+		return true
+	}
+	return pgconn.SafeToRetry(err)
+}
+
+// Retry calls fn as a connection is retried: again after a transient
+// error, on a new connection if fn releases the one that failed.
+func (c *Connector) Retry(ctx context.Context, op string,
+	fn func() error) error {
+
+	return c.retry(ctx, op, fn)
+}
+
+// retry calls fn until it succeeds, fails with an error that is not
+// transient, or the connection's retries are used up, waiting the retry
+// backoff, doubled after each attempt, between attempts.
+func (c *Connector) retry(ctx context.Context, op string,
+	fn func() error) error {
+
+	backoff := c.config.RetryBackoffDuration()
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.config.Retries() ||
+			!IsTransient(err) || ctx.Err() != nil {
+			return err
+		}
+
+		if !c.quiet {
+			log.Printf("Warning: failed to %s (%v); retrying in %s "+
+				"(attempt %d of %d)", op, err, backoff, attempt+2,
+				c.config.Retries()+1)
+		}
+		if err := c.sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
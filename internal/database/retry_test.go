@@ -0,0 +1,110 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"nil", nil, false},
+		{"refused", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, true},
+		{"reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"timeout", context.DeadlineExceeded, true},
+		{"shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"starting up", &pgconn.PgError{Code: "57P03"}, true},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"wrong password", &pgconn.PgError{Code: "28P01"}, false},
+		{"syntax error", &pgconn.PgError{Code: "42601"}, false},
+		{"other", fmt.Errorf("column does not exist"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.transient {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.transient, got)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+
+	newConnector := func(retries int) (*Connector, *[]time.Duration) {
+		var waits []time.Duration
+		c := NewConnector(&config.DatabaseConfig{ConnectRetries: &retries,
+			RetryBackoff: "20s"})
+		c.SetQuiet(true)
+		c.sleep = func(ctx context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		}
+		return c, &waits
+	}
+
+	// Transient failures are retried, with the backoff doubled up to
+	// its limit
+	c, waits := newConnector(3)
+	calls := 0
+	err := c.retry(context.Background(), "connect", func() error {
+		calls++
+		if calls < 4 {
+			return refused
+		}
+		return nil
+	})
+	if err != nil || calls != 4 {
+		t.Errorf("expected success after 4 calls, got %d calls, error %v",
+			calls, err)
+	}
+	expected := []time.Duration{20 * time.Second, 30 * time.Second,
+		30 * time.Second}
+	if fmt.Sprint(*waits) != fmt.Sprint(expected) {
+		t.Errorf("expected waits %v, got %v", expected, *waits)
+	}
+
+	// Retries are used up
+	c, _ = newConnector(2)
+	calls = 0
+	err = c.retry(context.Background(), "connect", func() error {
+		calls++
+		return refused
+	})
+	if err != refused || calls != 3 {
+		t.Errorf("expected failure after 3 calls, got %d calls, error %v",
+			calls, err)
+	}
+
+	// Other errors are not retried
+	c, _ = newConnector(5)
+	calls = 0
+	wrongPassword := &pgconn.PgError{Code: "28P01"}
+	err = c.retry(context.Background(), "connect", func() error {
+		calls++
+		return wrongPassword
+	})
+	if err != wrongPassword || calls != 1 {
+		t.Errorf("expected failure after 1 call, got %d calls, error %v",
+			calls, err)
+	}
+}
@@ -14,7 +14,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
 	"github.com/pgedge/pgedge-anonymizer/internal/errors"
 )
 
@@ -93,12 +95,157 @@ func (v *SchemaValidator) ValidateColumns(ctx context.Context,
 	return missing, nil
 }
 
-// GetColumnDataType returns the data type of a column.
+// ExpandWildcards replaces column entries containing '*' wildcards (e.g.
+// public.users.* or public.*.email) with one entry per matching column in
+// an ordinary table, copying the pattern and other settings. Explicitly
+// listed columns take precedence over wildcard matches, and a wildcard that
+// matches no columns is reported as an error.
+func (v *SchemaValidator) ExpandWildcards(ctx context.Context,
+	columns []config.ColumnConfig) ([]config.ColumnConfig, error) {
+
+	// Collect explicit columns first so they win over wildcard matches
+	seen := make(map[string]bool)
+	for _, col := range columns {
+		if !col.HasWildcard() {
+			seen[col.Column] = true
+		}
+	}
+
+	query := `
+        SELECT c.table_schema, c.table_name, c.column_name
+        FROM information_schema.columns c
+        JOIN information_schema.tables t
+          ON t.table_schema = c.table_schema
+         AND t.table_name = c.table_name
+        WHERE t.table_type = 'BASE TABLE'
+          AND c.table_schema NOT IN ('pg_catalog', 'information_schema')
+          AND c.table_schema LIKE $1
+          AND c.table_name LIKE $2
+          AND c.column_name LIKE $3
+        ORDER BY c.table_schema, c.table_name, c.ordinal_position
+    `
+
+	var expanded []config.ColumnConfig
+	var unmatched []errors.ColumnRef
+	for _, col := range columns {
+		if !col.HasWildcard() {
+			expanded = append(expanded, col)
+			continue
+		}
+
+		ref, err := errors.ParseColumnRef(col.Column)
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := v.db.QueryContext(ctx, query,
+			globToLike(ref.Schema), globToLike(ref.Table), globToLike(ref.Column))
+		if err != nil {
+			return nil, errors.NewDatabaseError("expand_wildcards",
+				fmt.Sprintf("failed to query columns: %v", err), err)
+		}
+
+		matched := 0
+		for rows.Next() {
+			var m errors.ColumnRef
+			if err := rows.Scan(&m.Schema, &m.Table, &m.Column); err != nil {
+				rows.Close()
+				return nil, errors.NewDatabaseError("expand_wildcards",
+					fmt.Sprintf("failed to scan column: %v", err), err)
+			}
+			matched++
+
+			if seen[m.String()] {
+				continue
+			}
+			seen[m.String()] = true
+
+			entry := col
+			entry.Column = m.String()
+			expanded = append(expanded, entry)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, errors.NewDatabaseError("expand_wildcards",
+				fmt.Sprintf("error iterating columns: %v", err), err)
+		}
+
+		if matched == 0 {
+			unmatched = append(unmatched, ref)
+		}
+	}
+
+	if len(unmatched) > 0 {
+		return nil, errors.NewValidationError(
+			"wildcard columns matched nothing in database", unmatched)
+	}
+
+	return expanded, nil
+}
+
+// TableColumn is a column of a table and its data type.
+type TableColumn struct {
+	Column   errors.ColumnRef
+	DataType string
+}
+
+// ListColumns returns the columns of the ordinary tables in schemas
+// matching a '*' wildcard pattern, excluding system schemas and generated
+// columns, which cannot be updated, in table and column order.
+func (v *SchemaValidator) ListColumns(ctx context.Context,
+	schema string) ([]TableColumn, error) {
+
+	query := `
+        SELECT c.table_schema, c.table_name, c.column_name, c.data_type
+        FROM information_schema.columns c
+        JOIN information_schema.tables t
+          ON t.table_schema = c.table_schema
+         AND t.table_name = c.table_name
+        WHERE t.table_type = 'BASE TABLE'
+          AND c.table_schema NOT IN ('pg_catalog', 'information_schema')
+          AND c.table_schema LIKE $1
+          AND c.is_generated = 'NEVER'
+        ORDER BY c.table_schema, c.table_name, c.ordinal_position
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, globToLike(schema))
+	if err != nil {
+		return nil, errors.NewDatabaseError("list_columns",
+			fmt.Sprintf("failed to query columns: %v", err), err)
+	}
+	defer rows.Close()
+
+	var columns []TableColumn
+	for rows.Next() {
+		var tc TableColumn
+		if err := rows.Scan(&tc.Column.Schema, &tc.Column.Table,
+			&tc.Column.Column, &tc.DataType); err != nil {
+			return nil, errors.NewDatabaseError("list_columns",
+				fmt.Sprintf("failed to scan column: %v", err), err)
+		}
+		columns = append(columns, tc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("list_columns",
+			fmt.Sprintf("error iterating columns: %v", err), err)
+	}
+
+	return columns, nil
+}
+
+// GetColumnDataType returns the data type of a column, as the information
+// schema names it, or the name of a user-defined type.
 func (v *SchemaValidator) GetColumnDataType(ctx context.Context,
 	col errors.ColumnRef) (string, error) {
 
+	// User-defined types, such as enums and PostGIS geometries, are named
+	// so that values can be cast to them, qualified by their schema only
+	// if it is not on the search path
 	query := `
-        SELECT data_type
+        SELECT CASE WHEN data_type = 'USER-DEFINED'
+                    THEN format('%I.%I', udt_schema, udt_name)::regtype::text
+                    ELSE data_type END
         FROM information_schema.columns
         WHERE table_schema = $1
           AND table_name = $2
@@ -121,6 +268,92 @@ func (v *SchemaValidator) GetColumnDataType(ctx context.Context,
 	return dataType, nil
 }
 
+// GetColumnMaxLength returns the most characters a column holds, as its
+// type declares, such as 50 for varchar(50), or zero if its length is
+// unlimited.
+func (v *SchemaValidator) GetColumnMaxLength(ctx context.Context,
+	col errors.ColumnRef) (int, error) {
+
+	query := `
+        SELECT character_maximum_length
+        FROM information_schema.columns
+        WHERE table_schema = $1
+          AND table_name = $2
+          AND column_name = $3
+    `
+
+	var length sql.NullInt64
+	err := v.db.QueryRowContext(ctx, query,
+		col.Schema, col.Table, col.Column).Scan(&length)
+
+	if err == sql.ErrNoRows {
+		return 0, errors.NewDatabaseError("get_length",
+			fmt.Sprintf("column %s not found", col.String()), nil)
+	}
+	if err != nil {
+		return 0, errors.NewDatabaseError("get_length",
+			fmt.Sprintf("failed to get column length: %v", err), err)
+	}
+
+	return int(length.Int64), nil
+}
+
+// GetCompositeType returns the quoted, schema-qualified name of a
+// composite-typed column's type, and the names of its fields in order.
+// It returns an error if the column is not of a composite type.
+func (v *SchemaValidator) GetCompositeType(ctx context.Context,
+	col errors.ColumnRef) (string, []string, error) {
+
+	query := `
+        SELECT format('%I.%I', n.nspname, t.typname), a.attname
+        FROM pg_attribute ca
+        JOIN pg_class c ON c.oid = ca.attrelid
+        JOIN pg_namespace cn ON cn.oid = c.relnamespace
+        JOIN pg_type t ON t.oid = ca.atttypid
+        JOIN pg_namespace n ON n.oid = t.typnamespace
+        JOIN pg_attribute a ON a.attrelid = t.typrelid
+        WHERE cn.nspname = $1
+          AND c.relname = $2
+          AND ca.attname = $3
+          AND t.typtype = 'c'
+          AND a.attnum > 0
+          AND NOT a.attisdropped
+        ORDER BY a.attnum
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, col.Schema, col.Table,
+		col.Column)
+	if err != nil {
+		return "", nil, errors.NewDatabaseError("get_type",
+			fmt.Sprintf("failed to get composite type: %v", err), err)
+	}
+	defer rows.Close()
+
+	var typeName string
+	var fields []string
+	for rows.Next() {
+		var field string
+		if err := rows.Scan(&typeName, &field); err != nil {
+			return "", nil, errors.NewDatabaseError("get_type",
+				fmt.Sprintf("failed to scan composite field: %v", err), err)
+		}
+		fields = append(fields, field)
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", nil, errors.NewDatabaseError("get_type",
+			fmt.Sprintf("error iterating composite fields: %v", err), err)
+	}
+
+	if len(fields) == 0 {
+		return "", nil, errors.NewDatabaseError("get_type",
+			fmt.Sprintf("column %s is not of a composite type", col.String()),
+			nil)
+	}
+
+	return typeName, fields, nil
+}
+
 // GetTableRowEstimate returns an estimated row count for a table.
 // This uses pg_class.reltuples for fast estimation without scanning.
 func (v *SchemaValidator) GetTableRowEstimate(ctx context.Context,
@@ -146,6 +379,69 @@ func (v *SchemaValidator) GetTableRowEstimate(ctx context.Context,
 	return estimate, nil
 }
 
+// GetServerEncoding returns the database's character encoding, such as
+// "UTF8" or "LATIN1".
+func (v *SchemaValidator) GetServerEncoding(ctx context.Context) (string,
+	error) {
+
+	var encoding string
+	err := v.db.QueryRowContext(ctx,
+		"SELECT current_setting('server_encoding')").Scan(&encoding)
+	if err != nil {
+		return "", errors.NewDatabaseError("encoding",
+			fmt.Sprintf("failed to get server encoding: %v", err), err)
+	}
+	return encoding, nil
+}
+
+// KeyColumn is a column of a table's primary key and its data type.
+type KeyColumn struct {
+	Name     string
+	DataType string
+}
+
+// GetPrimaryKey returns the columns of a table's primary key in key
+// order, or none if the table has no primary key.
+func (v *SchemaValidator) GetPrimaryKey(ctx context.Context,
+	schema, table string) ([]KeyColumn, error) {
+
+	query := `
+        SELECT a.attname, format_type(a.atttypid, a.atttypmod)
+        FROM pg_constraint c
+        JOIN pg_class t ON t.oid = c.conrelid
+        JOIN pg_namespace n ON n.oid = t.relnamespace
+        JOIN pg_attribute a ON a.attrelid = t.oid
+        WHERE n.nspname = $1
+          AND t.relname = $2
+          AND c.contype = 'p'
+          AND a.attnum = ANY(c.conkey)
+        ORDER BY array_position(c.conkey, a.attnum)
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_primary_key",
+			fmt.Sprintf("failed to get primary key: %v", err), err)
+	}
+	defer rows.Close()
+
+	var key []KeyColumn
+	for rows.Next() {
+		var kc KeyColumn
+		if err := rows.Scan(&kc.Name, &kc.DataType); err != nil {
+			return nil, errors.NewDatabaseError("get_primary_key",
+				fmt.Sprintf("failed to scan key column: %v", err), err)
+		}
+		key = append(key, kc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_primary_key",
+			fmt.Sprintf("error iterating key columns: %v", err), err)
+	}
+
+	return key, nil
+}
+
 // HasUniqueConstraint checks if a column has a unique constraint or is part
 // of a unique index.
 func (v *SchemaValidator) HasUniqueConstraint(ctx context.Context,
@@ -202,6 +498,32 @@ func (v *SchemaValidator) HasUniqueConstraint(ctx context.Context,
 	return hasConstraint, nil
 }
 
+// IsNotNull checks if a column is declared NOT NULL, or is part of a
+// primary key.
+func (v *SchemaValidator) IsNotNull(ctx context.Context,
+	col errors.ColumnRef) (bool, error) {
+
+	query := `
+        SELECT a.attnotnull
+        FROM pg_attribute a
+        JOIN pg_class t ON t.oid = a.attrelid
+        JOIN pg_namespace n ON n.oid = t.relnamespace
+        WHERE n.nspname = $1
+          AND t.relname = $2
+          AND a.attname = $3
+    `
+
+	var notNull bool
+	err := v.db.QueryRowContext(ctx, query,
+		col.Schema, col.Table, col.Column).Scan(&notNull)
+	if err != nil {
+		return false, errors.NewDatabaseError("check_not_null",
+			fmt.Sprintf("failed to check NOT NULL constraint: %v", err), err)
+	}
+
+	return notNull, nil
+}
+
 // GetDistinctValues returns all distinct non-null values from a column.
 // Used to pre-load existing values for uniqueness checking.
 func (v *SchemaValidator) GetDistinctValues(ctx context.Context,
@@ -243,6 +565,60 @@ func (v *SchemaValidator) GetDistinctValues(ctx context.Context,
 	return values, nil
 }
 
+// ScanValues calls fn with each non-null value in a column, as text, up
+// to limit values (all values if limit is 0).
+func (v *SchemaValidator) ScanValues(ctx context.Context,
+	col errors.ColumnRef, limit int64, fn func(value string) error) error {
+
+	query := fmt.Sprintf(`
+        SELECT %s::text
+        FROM %s.%s
+        WHERE %s IS NOT NULL
+    `,
+		quoteIdent(col.Column),
+		quoteIdent(col.Schema),
+		quoteIdent(col.Table),
+		quoteIdent(col.Column),
+	)
+	if limit > 0 {
+		query += fmt.Sprintf("LIMIT %d", limit)
+	}
+
+	rows, err := v.db.QueryContext(ctx, query)
+	if err != nil {
+		return errors.NewDatabaseErrorWithColumn("scan", col,
+			fmt.Sprintf("failed to scan values: %v", err), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var val string
+		if err := rows.Scan(&val); err != nil {
+			return errors.NewDatabaseErrorWithColumn("scan", col,
+				fmt.Sprintf("failed to scan value: %v", err), err)
+		}
+		if err := fn(val); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.NewDatabaseErrorWithColumn("scan", col,
+			fmt.Sprintf("error iterating values: %v", err), err)
+	}
+
+	return nil
+}
+
+// globToLike converts a '*' wildcard pattern to a LIKE pattern, escaping
+// any characters that LIKE would otherwise treat specially.
+func globToLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	s = strings.ReplaceAll(s, "_", `\_`)
+	return strings.ReplaceAll(s, "*", "%")
+}
+
 // quoteIdentForSchema quotes an identifier for use in SQL.
 func quoteIdentForSchema(s string) string {
 	return `"` + s + `"`
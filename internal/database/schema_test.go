@@ -15,6 +15,9 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
 )
 
 func TestGetTableRowEstimate_handlesNegativeEstimate(t *testing.T) {
@@ -64,3 +67,282 @@ func TestGetTableRowEstimate_handlesNegativeEstimate(t *testing.T) {
 		t.Errorf("unmet sqlmock expectations: %v", err)
 	}
 }
+
+func TestGetServerEncoding(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT current_setting('server_encoding')")).
+		WillReturnRows(sqlmock.NewRows([]string{"current_setting"}).
+			AddRow("LATIN1"))
+
+	encoding, err := v.GetServerEncoding(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if encoding != "LATIN1" {
+		t.Errorf("expected LATIN1, got %q", encoding)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+func TestGlobToLike(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{"*", "%"},
+		{"users", "users"},
+		{"email*", "email%"},
+		{"user_data", `user\_data`},
+		{"100%", `100\%`},
+	}
+
+	for _, tt := range tests {
+		if got := globToLike(tt.glob); got != tt.want {
+			t.Errorf("globToLike(%q) = %q, want %q", tt.glob, got, tt.want)
+		}
+	}
+}
+
+func TestExpandWildcards(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+
+	mock.ExpectQuery(`FROM information_schema.columns c`).
+		WithArgs("public", "%", "email").
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"table_schema", "table_name", "column_name"}).
+			AddRow("public", "customers", "email").
+			AddRow("public", "users", "email"))
+
+	columns := []config.ColumnConfig{
+		{Column: "public.users.email", Pattern: "EMAIL"},
+		{Column: "public.*.email", Pattern: "PERSON_NAME"},
+	}
+
+	expanded, err := v.ExpandWildcards(context.Background(), columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(expanded) != 2 {
+		t.Fatalf("expected 2 columns, got %d: %+v", len(expanded), expanded)
+	}
+	// The explicit entry keeps its own pattern
+	if expanded[0].Column != "public.users.email" || expanded[0].Pattern != "EMAIL" {
+		t.Errorf("unexpected explicit entry: %+v", expanded[0])
+	}
+	if expanded[1].Column != "public.customers.email" ||
+		expanded[1].Pattern != "PERSON_NAME" {
+		t.Errorf("unexpected expanded entry: %+v", expanded[1])
+	}
+
+	// A wildcard that matches nothing is an error
+	mock.ExpectQuery(`FROM information_schema.columns c`).
+		WithArgs("public", "nothing", "%").
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"table_schema", "table_name", "column_name"}))
+
+	_, err = v.ExpandWildcards(context.Background(), []config.ColumnConfig{
+		{Column: "public.nothing.*", Pattern: "EMAIL"},
+	})
+	if err == nil {
+		t.Error("expected error for unmatched wildcard")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+func TestListColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+
+	mock.ExpectQuery(`c.is_generated = 'NEVER'`).
+		WithArgs("app%").
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"table_schema", "table_name", "column_name", "data_type"}).
+			AddRow("app", "users", "id", "integer").
+			AddRow("app", "users", "email", "text"))
+
+	columns, err := v.ListColumns(context.Background(), "app*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(columns) != 2 {
+		t.Fatalf("expected 2 columns, got %d: %+v", len(columns), columns)
+	}
+	if columns[1].Column.String() != "app.users.email" ||
+		columns[1].DataType != "text" {
+		t.Errorf("unexpected column: %+v", columns[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+func TestGetColumnMaxLength(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "bio"}
+
+	mock.ExpectQuery(`SELECT character_maximum_length`).
+		WithArgs("public", "users", "bio").
+		WillReturnRows(sqlmock.NewRows([]string{"character_maximum_length"}).
+			AddRow(200))
+	mock.ExpectQuery(`SELECT character_maximum_length`).
+		WithArgs("public", "users", "bio").
+		WillReturnRows(sqlmock.NewRows([]string{"character_maximum_length"}).
+			AddRow(nil))
+
+	for _, want := range []int{200, 0} {
+		got, err := v.GetColumnMaxLength(context.Background(), col)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("got %d, want %d", got, want)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+func TestGetPrimaryKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+
+	mock.ExpectQuery(`c.contype = 'p'`).
+		WithArgs("public", "order_lines").
+		WillReturnRows(sqlmock.NewRows([]string{"attname", "format_type"}).
+			AddRow("order_id", "bigint").
+			AddRow("line_no", "integer"))
+	mock.ExpectQuery(`c.contype = 'p'`).
+		WithArgs("public", "events").
+		WillReturnRows(sqlmock.NewRows([]string{"attname", "format_type"}))
+
+	ctx := context.Background()
+	key, err := v.GetPrimaryKey(ctx, "public", "order_lines")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(key) != 2 || key[1] != (KeyColumn{Name: "line_no", DataType: "integer"}) {
+		t.Errorf("unexpected key: %+v", key)
+	}
+
+	// A table without a primary key
+	if key, err := v.GetPrimaryKey(ctx, "public", "events"); err != nil ||
+		len(key) != 0 {
+		t.Errorf("expected no key, got %+v, %v", key, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+func TestGetCompositeType(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "customers", Column: "address"}
+
+	mock.ExpectQuery(`AND t.typtype = 'c'`).
+		WithArgs("public", "customers", "address").
+		WillReturnRows(sqlmock.NewRows([]string{"format", "attname"}).
+			AddRow("public.address_t", "street").
+			AddRow("public.address_t", "city").
+			AddRow("public.address_t", "zip"))
+
+	typeName, fields, err := v.GetCompositeType(context.Background(), col)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if typeName != "public.address_t" {
+		t.Errorf("typeName = %q, want public.address_t", typeName)
+	}
+	if len(fields) != 3 || fields[0] != "street" || fields[2] != "zip" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+
+	// A column that is not composite has no fields
+	mock.ExpectQuery(`AND t.typtype = 'c'`).
+		WithArgs("public", "customers", "address").
+		WillReturnRows(sqlmock.NewRows([]string{"format", "attname"}))
+
+	if _, _, err := v.GetCompositeType(context.Background(), col); err == nil {
+		t.Error("expected error for non-composite column")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestScanValues(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+
+	mock.ExpectQuery(`SELECT "email"::text\s+FROM "public"."users"\s+WHERE "email" IS NOT NULL\s+LIMIT 2`).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).
+			AddRow("a@example.com").
+			AddRow("b@example.com"))
+
+	var values []string
+	err = v.ScanValues(context.Background(), col, 2, func(value string) error {
+		values = append(values, value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 2 || values[1] != "b@example.com" {
+		t.Errorf("unexpected values: %v", values)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
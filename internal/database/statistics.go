@@ -0,0 +1,215 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// GetStatisticsValues returns the column values held in the planner
+// statistics for a column: its most common values and histogram bounds,
+// as shown in pg_stats. The statistics are not updated when values
+// change, so until the table is analyzed they expose values from before
+// anonymization.
+func (v *SchemaValidator) GetStatisticsValues(ctx context.Context,
+	col errors.ColumnRef) ([]string, error) {
+
+	query := `
+        SELECT DISTINCT val
+        FROM pg_stats s,
+             unnest(s.most_common_vals::text::text[] ||
+                    s.histogram_bounds::text::text[]) AS val
+        WHERE s.schemaname = $1
+          AND s.tablename = $2
+          AND s.attname = $3
+          AND val <> ''
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, col.Schema, col.Table,
+		col.Column)
+	if err != nil {
+		return nil, errors.NewDatabaseErrorWithColumn("get_statistics", col,
+			fmt.Sprintf("failed to get column statistics: %v", err), err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, errors.NewDatabaseErrorWithColumn("get_statistics", col,
+				fmt.Sprintf("failed to scan column statistics: %v", err), err)
+		}
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseErrorWithColumn("get_statistics", col,
+			fmt.Sprintf("error iterating column statistics: %v", err), err)
+	}
+
+	return values, nil
+}
+
+// GetDistinctEstimate returns the planner's estimate of the number of
+// distinct values in a column, from pg_stats, or -1 if the column has no
+// statistics.
+func (v *SchemaValidator) GetDistinctEstimate(ctx context.Context,
+	col errors.ColumnRef) (int64, error) {
+
+	// A negative n_distinct is a fraction of the rows in the table
+	query := `
+        SELECT CASE WHEN s.n_distinct >= 0 THEN s.n_distinct
+                    ELSE -s.n_distinct * GREATEST(c.reltuples, 0)
+               END::bigint
+        FROM pg_stats s
+        JOIN pg_namespace n ON n.nspname = s.schemaname
+        JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = s.tablename
+        WHERE s.schemaname = $1
+          AND s.tablename = $2
+          AND s.attname = $3
+    `
+
+	var estimate int64
+	err := v.db.QueryRowContext(ctx, query, col.Schema, col.Table,
+		col.Column).Scan(&estimate)
+	if err == sql.ErrNoRows {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, errors.NewDatabaseErrorWithColumn("get_statistics", col,
+			fmt.Sprintf("failed to get distinct estimate: %v", err), err)
+	}
+	return estimate, nil
+}
+
+// ColumnEstimate is an estimate of the distinct values of a column and of
+// their average width, in bytes.
+type ColumnEstimate struct {
+	Distinct int64
+	AvgWidth int
+
+	// Sampled is set if the estimate is from a sample of the rows, rather
+	// than the planner statistics.
+	Sampled bool
+}
+
+// EstimateColumn estimates the distinct values of a column and their
+// width from the planner statistics, or, if the column has none, from a
+// sample of about sampleRows of the table's rows, which number about
+// rows, or zero if unknown.
+func (v *SchemaValidator) EstimateColumn(ctx context.Context,
+	col errors.ColumnRef, rows int64, sampleRows int) (ColumnEstimate,
+	error) {
+
+	query := `
+        SELECT CASE WHEN s.n_distinct >= 0 THEN s.n_distinct
+                    ELSE -s.n_distinct * GREATEST(c.reltuples, 0)
+               END::bigint,
+               s.avg_width
+        FROM pg_stats s
+        JOIN pg_namespace n ON n.nspname = s.schemaname
+        JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = s.tablename
+        WHERE s.schemaname = $1
+          AND s.tablename = $2
+          AND s.attname = $3
+    `
+
+	var est ColumnEstimate
+	err := v.db.QueryRowContext(ctx, query, col.Schema, col.Table,
+		col.Column).Scan(&est.Distinct, &est.AvgWidth)
+	if err == nil {
+		return est, nil
+	}
+	if err != sql.ErrNoRows {
+		return est, errors.NewDatabaseErrorWithColumn("get_statistics", col,
+			fmt.Sprintf("failed to get distinct estimate: %v", err), err)
+	}
+	return v.sampleColumn(ctx, col, rows, sampleRows)
+}
+
+// sampleColumn estimates the distinct values of a column and their width
+// from a sample of the table's rows: a random sample of its pages if the
+// number of its rows is known, or else its first rows. Values clustered
+// on the pages sampled make the estimate low.
+func (v *SchemaValidator) sampleColumn(ctx context.Context,
+	col errors.ColumnRef, rows int64, sampleRows int) (ColumnEstimate,
+	error) {
+
+	column := quoteIdent(col.Column)
+	source := tableName(col, false)
+	switch {
+	case rows <= 0:
+		source = fmt.Sprintf("(SELECT %s FROM %s LIMIT %d) t", column,
+			source, sampleRows)
+	case rows > int64(sampleRows):
+		source += fmt.Sprintf(" TABLESAMPLE SYSTEM (%g)",
+			float64(sampleRows)*100/float64(rows))
+	}
+
+	// Count the values sampled, those sampled once, and the rows
+	// sampled, with the average width of their values
+	query := fmt.Sprintf(`
+        SELECT count(*), count(*) FILTER (WHERE n = 1),
+               COALESCE(sum(n), 0),
+               COALESCE(sum(n * w) / NULLIF(sum(n), 0), 0)::int
+        FROM (SELECT count(*) AS n, avg(octet_length(%[1]s::text)) AS w
+              FROM %[2]s
+              WHERE %[1]s IS NOT NULL
+              GROUP BY %[1]s::text) g
+    `, column, source)
+
+	est := ColumnEstimate{Sampled: true}
+	var distinct, once, sampled int64
+	if err := v.db.QueryRowContext(ctx, query).Scan(&distinct, &once,
+		&sampled, &est.AvgWidth); err != nil {
+		return est, errors.NewDatabaseErrorWithColumn("sample", col,
+			fmt.Sprintf("failed to sample distinct values: %v", err), err)
+	}
+	est.Distinct = EstimateDistinct(distinct, once, sampled, rows)
+	return est, nil
+}
+
+// EstimateDistinct estimates the distinct values of the rows of a table
+// from a sample of sampled of them, holding distinct values, once of
+// which were sampled only once, with the estimator of Haas and Stokes
+// that ANALYZE uses. A table of unknown rows, zero, is taken to hold no
+// more than the sample.
+func EstimateDistinct(distinct, once, sampled, rows int64) int64 {
+	if sampled == 0 || rows <= sampled || once == 0 {
+		return distinct
+	}
+	n, total := float64(sampled), float64(rows)
+	estimate := n * float64(distinct) /
+		(n - float64(once) + float64(once)*n/total)
+	return min(int64(math.Round(estimate)), rows)
+}
+
+// AnalyzeTable updates the planner statistics of a table within the
+// transaction, so that the new statistics become visible when the
+// anonymized values are committed. PostgreSQL skips, with a warning,
+// tables the user is not permitted to analyze.
+func AnalyzeTable(ctx context.Context, tx *sql.Tx, schema,
+	table string) error {
+
+	query := fmt.Sprintf("ANALYZE %s.%s", quoteIdent(schema),
+		quoteIdent(table))
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return errors.NewDatabaseError("analyze",
+			fmt.Sprintf("failed to analyze %s.%s: %v", schema, table, err), err)
+	}
+	return nil
+}
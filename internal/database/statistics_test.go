@@ -0,0 +1,200 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+func TestGetStatisticsValues(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "email"}
+
+	mock.ExpectQuery(`FROM pg_stats s`).
+		WithArgs("public", "users", "email").
+		WillReturnRows(sqlmock.NewRows([]string{"val"}).
+			AddRow("alice@example.com").
+			AddRow("bob@example.com"))
+
+	values, err := v.GetStatisticsValues(context.Background(), col)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 2 || values[0] != "alice@example.com" {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestAnalyzeTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`ANALYZE "public"."my""table"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := AnalyzeTable(context.Background(), tx, "public",
+		`my"table`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetDistinctEstimate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "users", Column: "status"}
+
+	mock.ExpectQuery(`FROM pg_stats s`).
+		WithArgs("public", "users", "status").
+		WillReturnRows(sqlmock.NewRows([]string{"n_distinct"}).AddRow(4))
+	mock.ExpectQuery(`FROM pg_stats s`).
+		WithArgs("public", "users", "status").
+		WillReturnRows(sqlmock.NewRows([]string{"n_distinct"}))
+
+	estimate, err := v.GetDistinctEstimate(context.Background(), col)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if estimate != 4 {
+		t.Errorf("expected 4, got %d", estimate)
+	}
+
+	// A column without statistics has no estimate
+	estimate, err = v.GetDistinctEstimate(context.Background(), col)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if estimate != -1 {
+		t.Errorf("expected -1 without statistics, got %d", estimate)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestEstimateColumn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+	col := errors.ColumnRef{Schema: "public", Table: "orders",
+		Column: "status"}
+
+	mock.ExpectQuery(`FROM pg_stats s`).
+		WithArgs("public", "orders", "status").
+		WillReturnRows(sqlmock.NewRows([]string{"n_distinct", "avg_width"}).
+			AddRow(5, 8))
+
+	est, err := v.EstimateColumn(context.Background(), col, 1000000, 30000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if est.Distinct != 5 || est.AvgWidth != 8 || est.Sampled {
+		t.Errorf("unexpected estimate: %+v", est)
+	}
+
+	// A column without statistics is sampled
+	mock.ExpectQuery(`FROM pg_stats s`).
+		WithArgs("public", "orders", "status").
+		WillReturnRows(sqlmock.NewRows([]string{"n_distinct", "avg_width"}))
+	mock.ExpectQuery(`FROM "public"."orders" TABLESAMPLE SYSTEM \(3\)\s+` +
+		`WHERE "status" IS NOT NULL\s+GROUP BY "status"::text`).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "once", "sum",
+			"width"}).AddRow(5, 0, 30000, 7))
+
+	est, err = v.EstimateColumn(context.Background(), col, 1000000, 30000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if est.Distinct != 5 || est.AvgWidth != 7 || !est.Sampled {
+		t.Errorf("unexpected estimate: %+v", est)
+	}
+
+	// The first rows of a table of unknown size are sampled
+	mock.ExpectQuery(`FROM pg_stats s`).
+		WithArgs("public", "orders", "status").
+		WillReturnRows(sqlmock.NewRows([]string{"n_distinct", "avg_width"}))
+	mock.ExpectQuery(`FROM \(SELECT "status" FROM "public"."orders" ` +
+		`LIMIT 30000\) t`).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "once", "sum",
+			"width"}).AddRow(0, 0, 0, 0))
+
+	est, err = v.EstimateColumn(context.Background(), col, 0, 30000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if est.Distinct != 0 {
+		t.Errorf("expected no values, got %+v", est)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestEstimateDistinct(t *testing.T) {
+	tests := []struct {
+		name                          string
+		distinct, once, sampled, rows int64
+		want                          int64
+	}{
+		{"every value repeated", 5, 0, 30000, 1000000, 5},
+		{"every value unique", 30000, 30000, 30000, 1000000, 1000000},
+		{"whole table", 700, 200, 30000, 30000, 700},
+		{"unknown rows", 700, 200, 30000, 0, 700},
+		{"some values unique", 20000, 10000, 30000, 1000000, 29557},
+		{"no rows", 0, 0, 0, 1000000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateDistinct(tt.distinct, tt.once, tt.sampled, tt.rows)
+			if got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
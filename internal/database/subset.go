@@ -0,0 +1,71 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// SubsetTable deletes the rows of a table other than a random subset of
+// them: a sample of about percent of the rows, if percent is between 0 and
+// 100, of which at most limit are kept, if limit is set. It returns the
+// number of rows deleted. Rows referenced by foreign keys of other tables
+// are deleted, or fail the deletion, as the foreign keys specify.
+func SubsetTable(ctx context.Context, tx *sql.Tx, schema, table string,
+	percent float64, limit int64) (int64, error) {
+
+	name := quoteIdent(schema) + "." + quoteIdent(table)
+	var deleted int64
+
+	if percent > 0 && percent < 100 {
+		n, err := deleteRows(ctx, tx, fmt.Sprintf(
+			`DELETE FROM %s WHERE random() >= $1`, name), percent/100)
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+
+	if limit > 0 {
+		n, err := deleteRows(ctx, tx, fmt.Sprintf(
+			`DELETE FROM %s
+             WHERE ctid IN (SELECT ctid FROM %s ORDER BY random() OFFSET $1)`,
+			name, name), limit)
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+
+	return deleted, nil
+}
+
+// deleteRows runs a DELETE statement and returns the number of rows it
+// deleted.
+func deleteRows(ctx context.Context, tx *sql.Tx, query string,
+	args ...any) (int64, error) {
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, errors.NewDatabaseError("subset",
+			fmt.Sprintf("failed to delete rows: %v", err), err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.NewDatabaseError("subset",
+			fmt.Sprintf("failed to count deleted rows: %v", err), err)
+	}
+	return n, nil
+}
@@ -0,0 +1,66 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestSubsetTable tests deleting the rows outside a table's subset
+func TestSubsetTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "public"."events" WHERE random\(\) >= \$1`).
+		WithArgs(0.1).
+		WillReturnResult(sqlmock.NewResult(0, 900))
+	mock.ExpectExec(`DELETE FROM "public"."events"\s+WHERE ctid IN ` +
+		`\(SELECT ctid FROM "public"."events" ORDER BY random\(\) OFFSET \$1\)`).
+		WithArgs(int64(50)).
+		WillReturnResult(sqlmock.NewResult(0, 50))
+	// With a limit alone, every row may be kept
+	mock.ExpectExec(`OFFSET \$1`).
+		WithArgs(int64(10)).
+		WillReturnResult(sqlmock.NewResult(0, 90))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	deleted, err := SubsetTable(ctx, tx, "public", "events", 10, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 950 {
+		t.Errorf("expected 950 rows deleted, got %d", deleted)
+	}
+
+	deleted, err = SubsetTable(ctx, tx, "public", "events", 0, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 90 {
+		t.Errorf("expected 90 rows deleted, got %d", deleted)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
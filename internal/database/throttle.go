@@ -0,0 +1,102 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Throttle limits the rate at which batch processors read rows, to spare
+// the I/O of a busy server. The rate is kept with a token bucket holding up
+// to a second's worth of rows: each batch takes a token per row, and waits
+// when the bucket is empty until enough have been added, so that a batch
+// larger than the rate is spread over more than a second. A Throttle may
+// be shared by several processors, and a nil Throttle does not limit them.
+type Throttle struct {
+	rate  float64       // Rows per second; zero is unlimited
+	pause time.Duration // Pause after each batch
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	// now and sleep are replaced in tests
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewThrottle creates a throttle limiting processors to rowsPerSecond
+// rows, and pausing for pause after each batch; zero values do not limit
+// them. It returns nil if neither limit is set.
+func NewThrottle(rowsPerSecond int, pause time.Duration) *Throttle {
+	if rowsPerSecond <= 0 && pause <= 0 {
+		return nil
+	}
+	return &Throttle{
+		rate:   float64(max(rowsPerSecond, 0)),
+		pause:  max(pause, 0),
+		tokens: float64(max(rowsPerSecond, 0)),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+// Wait takes a token for each of rows, waiting until the bucket holds
+// enough, or the context is cancelled.
+func (t *Throttle) Wait(ctx context.Context, rows int) error {
+	if t == nil || t.rate == 0 || rows <= 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	now := t.now()
+	if !t.last.IsZero() {
+		t.tokens = min(t.rate, t.tokens+now.Sub(t.last).Seconds()*t.rate)
+	}
+	t.last = now
+
+	// Take the tokens now, so that processors sharing the throttle queue
+	// behind each other, and wait for the bucket to refill the shortfall
+	t.tokens -= float64(rows)
+	var wait time.Duration
+	if t.tokens < 0 {
+		wait = time.Duration(-t.tokens / t.rate * float64(time.Second))
+	}
+	t.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	return t.sleep(ctx, wait)
+}
+
+// Pause waits for the pause between batches, or until the context is
+// cancelled.
+func (t *Throttle) Pause(ctx context.Context) error {
+	if t == nil || t.pause == 0 {
+		return nil
+	}
+	return t.sleep(ctx, t.pause)
+}
+
+// sleepContext waits for d, or until the context is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
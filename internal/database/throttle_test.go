@@ -0,0 +1,84 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestThrottle tests the token bucket and the pause between batches
+func TestThrottle(t *testing.T) {
+	if NewThrottle(0, 0) != nil {
+		t.Error("expected no throttle without limits")
+	}
+
+	// A nil throttle does not limit
+	var none *Throttle
+	if err := none.Wait(context.Background(), 1000); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	clock := time.Unix(0, 0)
+	var slept []time.Duration
+	th := NewThrottle(1000, 50*time.Millisecond)
+	th.now = func() time.Time { return clock }
+	th.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		clock = clock.Add(d)
+		return nil
+	}
+	ctx := context.Background()
+
+	// A second's worth of rows is available at once
+	if err := th.Wait(ctx, 1000); err != nil || len(slept) != 0 {
+		t.Fatalf("expected no wait, got %v, %v", slept, err)
+	}
+
+	// The next 500 rows wait half a second for the bucket to refill
+	if err := th.Wait(ctx, 500); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slept) != 1 || slept[0] != 500*time.Millisecond {
+		t.Errorf("expected a wait of 500ms, got %v", slept)
+	}
+
+	// The bucket refills at the rate, up to a second's worth
+	clock = clock.Add(10 * time.Second)
+	slept = nil
+	if err := th.Wait(ctx, 1500); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slept) != 1 || slept[0] != 500*time.Millisecond {
+		t.Errorf("expected a wait of 500ms, got %v", slept)
+	}
+
+	slept = nil
+	if err := th.Pause(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slept) != 1 || slept[0] != 50*time.Millisecond {
+		t.Errorf("expected a pause of 50ms, got %v", slept)
+	}
+}
+
+// TestThrottleCancelled tests that a wait ends when the context is
+// cancelled
+func TestThrottleCancelled(t *testing.T) {
+	th := NewThrottle(1, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := th.Wait(ctx, 3600); err != context.Canceled {
+		t.Errorf("expected the wait to be cancelled, got %v", err)
+	}
+}
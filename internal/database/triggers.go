@@ -0,0 +1,108 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// Trigger is a user trigger on a table.
+type Trigger struct {
+	Schema string
+	Table  string
+	Name   string
+
+	// Enabled is when the trigger fires, as pg_trigger.tgenabled holds
+	// it: O in the origin and local session replication roles, R in the
+	// replica role only, and A in all roles.
+	Enabled string
+}
+
+// DisableUserTriggers disables the enabled user triggers of a table in a
+// transaction, returning them so that they can be enabled again with
+// EnableTriggers. Triggers that were already disabled are left so, and
+// internal triggers, such as those enforcing foreign keys, still fire.
+func DisableUserTriggers(ctx context.Context, tx *sql.Tx, schema,
+	table string) ([]Trigger, error) {
+
+	query := `
+        SELECT t.tgname, t.tgenabled
+        FROM pg_trigger t
+        JOIN pg_class c ON c.oid = t.tgrelid
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE n.nspname = $1
+          AND c.relname = $2
+          AND NOT t.tgisinternal
+          AND t.tgenabled <> 'D'
+        ORDER BY t.tgname
+    `
+
+	rows, err := tx.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_triggers",
+			fmt.Sprintf("failed to get triggers of %s.%s: %v", schema, table,
+				err), err)
+	}
+	defer rows.Close()
+
+	var triggers []Trigger
+	for rows.Next() {
+		trig := Trigger{Schema: schema, Table: table}
+		if err := rows.Scan(&trig.Name, &trig.Enabled); err != nil {
+			return nil, errors.NewDatabaseError("get_triggers",
+				fmt.Sprintf("failed to scan trigger: %v", err), err)
+		}
+		triggers = append(triggers, trig)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_triggers",
+			fmt.Sprintf("error iterating triggers: %v", err), err)
+	}
+
+	for _, trig := range triggers {
+		query := fmt.Sprintf("ALTER TABLE %s.%s DISABLE TRIGGER %s",
+			quoteIdent(schema), quoteIdent(table), quoteIdent(trig.Name))
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return nil, errors.NewDatabaseError("disable_trigger",
+				fmt.Sprintf("failed to disable trigger %s on %s.%s: %v",
+					trig.Name, schema, table, err), err)
+		}
+	}
+
+	return triggers, nil
+}
+
+// EnableTriggers enables triggers disabled by DisableUserTriggers again,
+// to fire in the session replication roles they fired in before.
+func EnableTriggers(ctx context.Context, tx *sql.Tx, triggers []Trigger) error {
+	for _, trig := range triggers {
+		mode := ""
+		switch trig.Enabled {
+		case "R":
+			mode = "REPLICA "
+		case "A":
+			mode = "ALWAYS "
+		}
+		query := fmt.Sprintf("ALTER TABLE %s.%s ENABLE %sTRIGGER %s",
+			quoteIdent(trig.Schema), quoteIdent(trig.Table), mode,
+			quoteIdent(trig.Name))
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return errors.NewDatabaseError("enable_trigger",
+				fmt.Sprintf("failed to enable trigger %s on %s.%s: %v",
+					trig.Name, trig.Schema, trig.Table, err), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestDisableUserTriggers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`NOT t.tgisinternal`).
+		WithArgs("public", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"tgname", "tgenabled"}).
+			AddRow("users_audit", "O").
+			AddRow("users_sync", "A"))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`ALTER TABLE "public"."users" DISABLE TRIGGER "users_audit"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`ALTER TABLE "public"."users" DISABLE TRIGGER "users_sync"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Triggers are enabled again in the roles they fired in
+	mock.ExpectExec(regexp.QuoteMeta(
+		`ALTER TABLE "public"."users" ENABLE TRIGGER "users_audit"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`ALTER TABLE "public"."users" ENABLE ALWAYS TRIGGER "users_sync"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	triggers, err := DisableUserTriggers(ctx, tx, "public", "users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(triggers) != 2 || triggers[1].Name != "users_sync" ||
+		triggers[1].Enabled != "A" || triggers[1].Table != "users" {
+		t.Errorf("unexpected triggers: %+v", triggers)
+	}
+
+	if err := EnableTriggers(ctx, tx, triggers); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet sqlmock expectations: %v", err)
+	}
+}
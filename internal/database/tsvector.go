@@ -0,0 +1,240 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// TSVectorColumn describes a tsvector column and, where it can be
+// determined, the columns its value is derived from.
+type TSVectorColumn struct {
+	Column errors.ColumnRef
+
+	// Sources are the columns the value is derived from; empty if unknown.
+	Sources []string
+
+	// Generated is true for GENERATED ALWAYS AS ... STORED columns, which
+	// PostgreSQL recomputes whenever a source column is updated.
+	Generated bool
+
+	// Trigger names a tsvector_update_trigger or
+	// tsvector_update_trigger_column trigger maintaining the column.
+	Trigger        string
+	TriggerEnabled bool
+
+	// Config is the text search configuration used by the trigger, or
+	// when ConfigColumn is true, the column holding it.
+	Config       string
+	ConfigColumn bool
+}
+
+// GetTSVectorColumns returns the tsvector columns of a table, along with
+// how each is maintained.
+func (v *SchemaValidator) GetTSVectorColumns(ctx context.Context,
+	schema, table string) ([]TSVectorColumn, error) {
+
+	// Source columns of generated columns come from pg_depend; a row is
+	// returned per source column, or a single row with a NULL source
+	query := `
+        SELECT a.attname, a.attgenerated = 's', sa.attname
+        FROM pg_attribute a
+        JOIN pg_class c ON c.oid = a.attrelid
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        LEFT JOIN pg_attrdef ad
+          ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum
+        LEFT JOIN pg_depend dep
+          ON dep.classid = 'pg_attrdef'::regclass
+         AND dep.objid = ad.oid
+         AND dep.refobjid = a.attrelid
+         AND dep.refobjsubid > 0
+        LEFT JOIN pg_attribute sa
+          ON sa.attrelid = dep.refobjid AND sa.attnum = dep.refobjsubid
+        WHERE n.nspname = $1
+          AND c.relname = $2
+          AND a.atttypid = 'tsvector'::regtype
+          AND a.attnum > 0
+          AND NOT a.attisdropped
+        ORDER BY a.attnum, sa.attnum
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_tsvector",
+			fmt.Sprintf("failed to get tsvector columns: %v", err), err)
+	}
+	defer rows.Close()
+
+	var columns []TSVectorColumn
+	index := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var generated bool
+		var source sql.NullString
+		if err := rows.Scan(&name, &generated, &source); err != nil {
+			return nil, errors.NewDatabaseError("get_tsvector",
+				fmt.Sprintf("failed to scan tsvector column: %v", err), err)
+		}
+
+		i, ok := index[name]
+		if !ok {
+			i = len(columns)
+			index[name] = i
+			columns = append(columns, TSVectorColumn{
+				Column:    errors.ColumnRef{Schema: schema, Table: table, Column: name},
+				Generated: generated,
+			})
+		}
+		if source.Valid {
+			columns[i].Sources = append(columns[i].Sources, source.String)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_tsvector",
+			fmt.Sprintf("error iterating tsvector columns: %v", err), err)
+	}
+
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	if err := v.addTSVectorTriggers(ctx, schema, table, columns, index); err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// addTSVectorTriggers fills in the trigger details of tsvector columns
+// maintained by the built-in tsvector update triggers.
+func (v *SchemaValidator) addTSVectorTriggers(ctx context.Context,
+	schema, table string, columns []TSVectorColumn, index map[string]int) error {
+
+	query := `
+        SELECT t.tgname, t.tgenabled <> 'D', p.proname, t.tgargs
+        FROM pg_trigger t
+        JOIN pg_proc p ON p.oid = t.tgfoid
+        JOIN pg_class c ON c.oid = t.tgrelid
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE n.nspname = $1
+          AND c.relname = $2
+          AND NOT t.tgisinternal
+          AND p.pronamespace = 'pg_catalog'::regnamespace
+          AND p.proname IN ('tsvector_update_trigger',
+                            'tsvector_update_trigger_column')
+        ORDER BY t.tgname
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return errors.NewDatabaseError("get_tsvector",
+			fmt.Sprintf("failed to get tsvector triggers: %v", err), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, function string
+		var enabled bool
+		var rawArgs []byte
+		if err := rows.Scan(&name, &enabled, &function, &rawArgs); err != nil {
+			return errors.NewDatabaseError("get_tsvector",
+				fmt.Sprintf("failed to scan tsvector trigger: %v", err), err)
+		}
+
+		// Arguments are the tsvector column, the configuration (or the
+		// column holding it), then the source columns
+		args := splitTriggerArgs(rawArgs)
+		if len(args) < 3 {
+			continue
+		}
+		i, ok := index[args[0]]
+		if !ok {
+			continue
+		}
+
+		col := &columns[i]
+		col.Trigger = name
+		col.TriggerEnabled = enabled
+		col.Config = args[1]
+		col.ConfigColumn = function == "tsvector_update_trigger_column"
+		col.Sources = args[2:]
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.NewDatabaseError("get_tsvector",
+			fmt.Sprintf("error iterating tsvector triggers: %v", err), err)
+	}
+
+	return nil
+}
+
+// splitTriggerArgs splits pg_trigger.tgargs, in which each argument is
+// terminated by a NUL byte.
+func splitTriggerArgs(raw []byte) []string {
+	var args []string
+	for _, arg := range bytes.Split(bytes.TrimSuffix(raw, []byte{0}), []byte{0}) {
+		args = append(args, string(arg))
+	}
+	if len(args) == 1 && args[0] == "" {
+		return nil
+	}
+	return args
+}
+
+// RefreshTSVector recomputes a trigger-maintained tsvector column from
+// its source columns, as the trigger would. It is used when the trigger
+// is disabled and so did not fire while the sources were anonymized.
+func RefreshTSVector(ctx context.Context, tx *sql.Tx,
+	col TSVectorColumn) (int64, error) {
+
+	if len(col.Sources) == 0 || col.Config == "" {
+		return 0, errors.NewDatabaseErrorWithColumn("refresh_tsvector",
+			col.Column, "source columns and configuration are unknown", nil)
+	}
+
+	sources := make([]string, len(col.Sources))
+	for i, s := range col.Sources {
+		sources[i] = quoteIdent(s)
+	}
+
+	var config string
+	var args []any
+	if col.ConfigColumn {
+		config = quoteIdent(col.Config) + "::regconfig"
+	} else {
+		config = "$1::regconfig"
+		args = append(args, col.Config)
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET %s = to_tsvector(%s, concat_ws(' ', %s))",
+		quoteIdent(col.Column.Schema),
+		quoteIdent(col.Column.Table),
+		quoteIdent(col.Column.Column),
+		config,
+		strings.Join(sources, ", "),
+	)
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, errors.NewDatabaseErrorWithColumn("refresh_tsvector",
+			col.Column, fmt.Sprintf("failed to refresh tsvector: %v", err), err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,184 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+func TestGetTSVectorColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+
+	mock.ExpectQuery(`a.atttypid = 'tsvector'::regtype`).
+		WithArgs("public", "docs").
+		WillReturnRows(sqlmock.NewRows([]string{"attname", "generated", "source"}).
+			AddRow("search", true, "title").
+			AddRow("search", true, "body").
+			AddRow("tsv", false, nil).
+			AddRow("legacy", false, nil))
+	mock.ExpectQuery(`tsvector_update_trigger`).
+		WithArgs("public", "docs").
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"tgname", "enabled", "proname", "tgargs"}).
+			AddRow("docs_tsv", false, "tsvector_update_trigger",
+				[]byte("tsv\x00pg_catalog.english\x00title\x00author\x00")))
+
+	columns, err := v.GetTSVectorColumns(context.Background(), "public", "docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(columns) != 3 {
+		t.Fatalf("expected 3 columns, got %d", len(columns))
+	}
+
+	search := columns[0]
+	if !search.Generated || len(search.Sources) != 2 ||
+		search.Sources[1] != "body" {
+		t.Errorf("unexpected generated column: %+v", search)
+	}
+
+	tsv := columns[1]
+	if tsv.Trigger != "docs_tsv" || tsv.TriggerEnabled ||
+		tsv.Config != "pg_catalog.english" || tsv.ConfigColumn {
+		t.Errorf("unexpected trigger column: %+v", tsv)
+	}
+	if len(tsv.Sources) != 2 || tsv.Sources[0] != "title" ||
+		tsv.Sources[1] != "author" {
+		t.Errorf("unexpected trigger sources: %v", tsv.Sources)
+	}
+
+	legacy := columns[2]
+	if legacy.Generated || legacy.Trigger != "" || len(legacy.Sources) != 0 {
+		t.Errorf("unexpected untracked column: %+v", legacy)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestGetTSVectorColumnsNone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	v := &SchemaValidator{db: db}
+
+	// Without tsvector columns, triggers are not queried
+	mock.ExpectQuery(`a.atttypid = 'tsvector'::regtype`).
+		WithArgs("public", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"attname", "generated", "source"}))
+
+	columns, err := v.GetTSVectorColumns(context.Background(), "public", "users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(columns) != 0 {
+		t.Errorf("expected no columns, got %v", columns)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRefreshTSVector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	col := TSVectorColumn{
+		Column:  errors.ColumnRef{Schema: "public", Table: "docs", Column: "tsv"},
+		Sources: []string{"title", "author"},
+		Config:  "pg_catalog.english",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "public"."docs" SET "tsv" = ` +
+		`to_tsvector($1::regconfig, concat_ws(' ', "title", "author"))`)).
+		WithArgs("pg_catalog.english").
+		WillReturnResult(sqlmock.NewResult(0, 12))
+
+	// With tsvector_update_trigger_column the configuration is a column
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "public"."docs" SET "tsv" = ` +
+		`to_tsvector("lang"::regconfig, concat_ws(' ', "title", "author"))`)).
+		WillReturnResult(sqlmock.NewResult(0, 12))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+
+	count, err := RefreshTSVector(context.Background(), tx, col)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 12 {
+		t.Errorf("count = %d, want 12", count)
+	}
+
+	col.Config = "lang"
+	col.ConfigColumn = true
+	if _, err := RefreshTSVector(context.Background(), tx, col); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Columns without known sources cannot be refreshed
+	if _, err := RefreshTSVector(context.Background(), tx,
+		TSVectorColumn{Column: col.Column}); err == nil {
+		t.Error("expected error for unknown sources")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSplitTriggerArgs(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{"", nil},
+		{"tsv\x00english\x00title\x00", []string{"tsv", "english", "title"}},
+		{"tsv\x00english\x00title", []string{"tsv", "english", "title"}},
+	}
+
+	for _, tt := range tests {
+		got := splitTriggerArgs([]byte(tt.raw))
+		if len(got) != len(tt.want) {
+			t.Errorf("splitTriggerArgs(%q) = %v, want %v", tt.raw, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("splitTriggerArgs(%q) = %v, want %v", tt.raw, got,
+					tt.want)
+			}
+		}
+	}
+}
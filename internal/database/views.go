@@ -0,0 +1,236 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// ResolvedColumn is a column of a view, and the table column the view
+// reads it from.
+type ResolvedColumn struct {
+	View  errors.ColumnRef
+	Table errors.ColumnRef
+}
+
+// ResolveViewColumns replaces each column entry naming a column of a view
+// with an entry for the table column of the same name that the view
+// reads, directly or through other views, copying the pattern and other
+// settings, as a view's rows cannot be updated by their ctid. Explicitly
+// listed table columns take precedence over resolved ones. A view column
+// that does not resolve to exactly one table column, such as a renamed or
+// computed one, is reported as an error naming the table columns the view
+// reads, to anonymize instead.
+func (v *SchemaValidator) ResolveViewColumns(ctx context.Context,
+	columns []config.ColumnConfig) ([]config.ColumnConfig, []ResolvedColumn,
+	error) {
+
+	var refs []errors.ColumnRef
+	for _, col := range columns {
+		ref, err := errors.ParseColumnRef(col.Column)
+		if err != nil {
+			return nil, nil, err
+		}
+		refs = append(refs, ref)
+	}
+
+	views, err := v.getViews(ctx, refs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(views) == 0 {
+		return columns, nil, nil
+	}
+
+	seen := make(map[string]bool)
+	for i, ref := range refs {
+		if !views[ref.Schema+"."+ref.Table] {
+			seen[columns[i].Column] = true
+		}
+	}
+
+	reads := make(map[string][]errors.ColumnRef)
+	var expanded []config.ColumnConfig
+	var resolved []ResolvedColumn
+	var unresolved []errors.ColumnRef
+	var suggestions []string
+	for i, ref := range refs {
+		view := ref.Schema + "." + ref.Table
+		if !views[view] {
+			expanded = append(expanded, columns[i])
+			continue
+		}
+
+		read, ok := reads[view]
+		if !ok {
+			read, err = v.getViewSources(ctx, ref.Schema, ref.Table)
+			if err != nil {
+				return nil, nil, err
+			}
+			reads[view] = read
+		}
+
+		var matches []errors.ColumnRef
+		for _, col := range read {
+			if col.Column == ref.Column {
+				matches = append(matches, col)
+			}
+		}
+		if len(matches) != 1 {
+			unresolved = append(unresolved, ref)
+			names := make([]string, len(read))
+			for j, col := range read {
+				names[j] = col.String()
+			}
+			if len(names) == 0 {
+				names = []string{"no table columns"}
+			}
+			suggestions = append(suggestions, fmt.Sprintf("%s reads %s",
+				view, strings.Join(names, ", ")))
+			continue
+		}
+
+		resolved = append(resolved, ResolvedColumn{View: ref, Table: matches[0]})
+		if seen[matches[0].String()] {
+			continue
+		}
+		seen[matches[0].String()] = true
+
+		entry := columns[i]
+		entry.Column = matches[0].String()
+		expanded = append(expanded, entry)
+	}
+
+	if len(unresolved) > 0 {
+		return nil, nil, errors.NewValidationError(fmt.Sprintf(
+			"view columns do not resolve to a table column; anonymize the "+
+				"table columns the views read instead (%s)",
+			strings.Join(suggestions, "; ")), unresolved)
+	}
+
+	return expanded, resolved, nil
+}
+
+// getViews returns the tables of the given columns that are views, keyed
+// by schema.table.
+func (v *SchemaValidator) getViews(ctx context.Context,
+	columns []errors.ColumnRef) (map[string]bool, error) {
+
+	schemas := make([]string, len(columns))
+	tables := make([]string, len(columns))
+	for i, col := range columns {
+		schemas[i], tables[i] = col.Schema, col.Table
+	}
+
+	query := `
+        SELECT DISTINCT n.nspname, c.relname
+        FROM unnest($1::text[], $2::text[]) AS t(nspname, relname)
+        JOIN pg_namespace n ON n.nspname = t.nspname
+        JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = t.relname
+        WHERE c.relkind = 'v'
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, schemas, tables)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_views",
+			fmt.Sprintf("failed to get views: %v", err), err)
+	}
+	defer rows.Close()
+
+	views := make(map[string]bool)
+	for rows.Next() {
+		var schema, table string
+		if err := rows.Scan(&schema, &table); err != nil {
+			return nil, errors.NewDatabaseError("get_views",
+				fmt.Sprintf("failed to scan view: %v", err), err)
+		}
+		views[schema+"."+table] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_views",
+			fmt.Sprintf("error iterating views: %v", err), err)
+	}
+
+	return views, nil
+}
+
+// getViewSources returns the columns of tables that a view's rule reads,
+// directly or through other views, in table and column order.
+func (v *SchemaValidator) getViewSources(ctx context.Context, schema,
+	view string) ([]errors.ColumnRef, error) {
+
+	query := `
+        WITH RECURSIVE reads AS (
+            SELECT d.refobjid, d.refobjsubid
+            FROM pg_class v
+            JOIN pg_namespace n ON n.oid = v.relnamespace
+            JOIN pg_rewrite r ON r.ev_class = v.oid
+            JOIN pg_depend d ON d.classid = 'pg_rewrite'::regclass
+                            AND d.objid = r.oid
+                            AND d.refclassid = 'pg_class'::regclass
+                            AND d.refobjid <> v.oid
+                            AND d.refobjsubid > 0
+            WHERE n.nspname = $1
+              AND v.relname = $2
+            UNION
+            SELECT d.refobjid, d.refobjsubid
+            FROM reads
+            JOIN pg_class v ON v.oid = reads.refobjid AND v.relkind = 'v'
+            JOIN pg_rewrite r ON r.ev_class = v.oid
+            JOIN pg_depend d ON d.classid = 'pg_rewrite'::regclass
+                            AND d.objid = r.oid
+                            AND d.refclassid = 'pg_class'::regclass
+                            AND d.refobjid <> v.oid
+                            AND d.refobjsubid > 0
+        )
+        SELECT DISTINCT n.nspname, c.relname, a.attname, a.attnum
+        FROM reads
+        JOIN pg_class c ON c.oid = reads.refobjid
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        JOIN pg_attribute a ON a.attrelid = c.oid
+                           AND a.attnum = reads.refobjsubid
+        WHERE c.relkind IN ('r', 'p')
+        ORDER BY n.nspname, c.relname, a.attnum
+    `
+
+	rows, err := v.db.QueryContext(ctx, query, schema, view)
+	if err != nil {
+		return nil, errors.NewDatabaseError("get_view_sources",
+			fmt.Sprintf("failed to get columns read by view %s.%s: %v",
+				schema, view, err), err)
+	}
+	defer rows.Close()
+
+	var columns []errors.ColumnRef
+	for rows.Next() {
+		var col errors.ColumnRef
+		var attnum int
+		if err := rows.Scan(&col.Schema, &col.Table, &col.Column,
+			&attnum); err != nil {
+			return nil, errors.NewDatabaseError("get_view_sources",
+				fmt.Sprintf("failed to scan column: %v", err), err)
+		}
+		columns = append(columns, col)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("get_view_sources",
+			fmt.Sprintf("error iterating columns: %v", err), err)
+	}
+
+	return columns, nil
+}
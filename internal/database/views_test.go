@@ -0,0 +1,109 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+)
+
+func TestResolveViewColumns(t *testing.T) {
+	newMock := func(t *testing.T) (*SchemaValidator, sqlmock.Sqlmock) {
+		db, mock, err := sqlmock.New(
+			sqlmock.ValueConverterOption(anyConverter{}))
+		if err != nil {
+			t.Fatalf("failed to open sqlmock: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		mock.ExpectQuery(`c.relkind = 'v'`).
+			WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname"}).
+				AddRow("public", "active_users"))
+		mock.ExpectQuery(`pg_rewrite`).
+			WithArgs("public", "active_users").
+			WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname",
+				"attname", "attnum"}).
+				AddRow("public", "users", "id", 1).
+				AddRow("public", "users", "email", 2).
+				AddRow("public", "users", "active", 3))
+		return NewSchemaValidator(db), mock
+	}
+
+	t.Run("resolves view columns", func(t *testing.T) {
+		v, mock := newMock(t)
+
+		columns := []config.ColumnConfig{
+			{Column: "public.active_users.email", Pattern: "EMAIL"},
+			{Column: "public.orders.notes", Pattern: "LOREMIPSUM"},
+		}
+		expanded, resolved, err := v.ResolveViewColumns(
+			context.Background(), columns)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(expanded) != 2 ||
+			expanded[0].Column != "public.users.email" ||
+			expanded[0].Pattern != "EMAIL" ||
+			expanded[1].Column != "public.orders.notes" {
+			t.Errorf("unexpected columns: %+v", expanded)
+		}
+		if len(resolved) != 1 ||
+			resolved[0].View.String() != "public.active_users.email" ||
+			resolved[0].Table.String() != "public.users.email" {
+			t.Errorf("unexpected resolved columns: %+v", resolved)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet sqlmock expectations: %v", err)
+		}
+	})
+
+	t.Run("explicit table column wins", func(t *testing.T) {
+		v, _ := newMock(t)
+
+		columns := []config.ColumnConfig{
+			{Column: "public.active_users.email", Pattern: "EMAIL"},
+			{Column: "public.users.email", Pattern: "WORK_EMAIL"},
+		}
+		expanded, _, err := v.ResolveViewColumns(context.Background(),
+			columns)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(expanded) != 1 || expanded[0].Pattern != "WORK_EMAIL" {
+			t.Errorf("unexpected columns: %+v", expanded)
+		}
+	})
+
+	t.Run("renamed column suggests table columns", func(t *testing.T) {
+		v, _ := newMock(t)
+
+		columns := []config.ColumnConfig{
+			{Column: "public.active_users.mail", Pattern: "EMAIL"},
+		}
+		_, _, err := v.ResolveViewColumns(context.Background(), columns)
+		if err == nil {
+			t.Fatal("expected an error for an unresolved view column")
+		}
+		for _, want := range []string{"public.active_users.mail",
+			"public.users.email"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("expected error to mention %s, got: %v", want, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,203 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package document detects whether stored bytes, such as the contents of
+// a bytea column or a large object, are a text document, and decodes and
+// encodes the text in the document's own encoding.
+package document
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is a character encoding a document's text is stored in.
+type Encoding string
+
+// Encodings a document may be detected in.
+const (
+	UTF8    Encoding = "UTF-8"
+	UTF16LE Encoding = "UTF-16LE"
+	UTF16BE Encoding = "UTF-16BE"
+	Latin1  Encoding = "ISO-8859-1"
+)
+
+// maxControlRatio is the fraction of a document's characters that may be
+// control characters other than whitespace before it is taken to be
+// binary data rather than text.
+const maxControlRatio = 0.05
+
+// Byte order marks, in the order they are looked for.
+var boms = []struct {
+	mark     []byte
+	encoding Encoding
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, UTF8},
+	{[]byte{0xFF, 0xFE}, UTF16LE},
+	{[]byte{0xFE, 0xFF}, UTF16BE},
+}
+
+// Document is the text of a document, and how it was stored.
+type Document struct {
+	Text     string
+	Encoding Encoding
+
+	// BOM is true if the document began with a byte order mark, which
+	// is written again when it is encoded.
+	BOM bool
+}
+
+// Decode detects the encoding of a document from its byte order mark, or
+// failing that its bytes: UTF-16 by the zero bytes of ASCII characters,
+// valid UTF-8, or otherwise Latin-1. It returns false if the bytes
+// are not text, such as an image or a compressed file.
+func Decode(b []byte) (Document, bool) {
+	for _, bom := range boms {
+		if rest, ok := bytes.CutPrefix(b, bom.mark); ok {
+			return decodeAs(rest, bom.encoding, true)
+		}
+	}
+
+	// UTF-16 text is checked for first, as its zero bytes are valid UTF-8
+	if enc, ok := guessUTF16(b); ok {
+		return decodeAs(b, enc, false)
+	}
+	if utf8.Valid(b) {
+		return decodeAs(b, UTF8, false)
+	}
+	return decodeAs(b, Latin1, false)
+}
+
+// decodeAs decodes a document in the given encoding, returning false if
+// it is not valid in it, or has too many control characters to be text.
+func decodeAs(b []byte, enc Encoding, bom bool) (Document, bool) {
+	doc := Document{Encoding: enc, BOM: bom}
+
+	switch enc {
+	case UTF8:
+		if !utf8.Valid(b) {
+			return doc, false
+		}
+		doc.Text = string(b)
+	case UTF16LE, UTF16BE:
+		if len(b)%2 != 0 {
+			return doc, false
+		}
+		order := byteOrder(enc)
+		units := make([]uint16, len(b)/2)
+		for i := range units {
+			units[i] = order.Uint16(b[2*i:])
+		}
+		doc.Text = string(utf16.Decode(units))
+	case Latin1:
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		doc.Text = string(runes)
+	}
+
+	return doc, isText(doc.Text)
+}
+
+// guessUTF16 returns the byte order of a document in UTF-16 without a
+// byte order mark, if its text is mostly ASCII: either the high or the
+// low byte of at least half its characters is zero, and the other never
+// is.
+func guessUTF16(b []byte) (Encoding, bool) {
+	if len(b) < 2 || len(b)%2 != 0 {
+		return "", false
+	}
+
+	var even, odd int
+	for i, c := range b {
+		if c != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+
+	half := len(b) / 4
+	switch {
+	case odd > 0 && odd >= half && even == 0:
+		return UTF16LE, true
+	case even > 0 && even >= half && odd == 0:
+		return UTF16BE, true
+	default:
+		return "", false
+	}
+}
+
+// isText returns true if no more than maxControlRatio of the characters
+// of a text are control characters other than whitespace, and none is a
+// NUL or a character that could not be decoded.
+func isText(s string) bool {
+	var chars, controls int
+	for _, r := range s {
+		chars++
+		switch {
+		case r == 0 || r == utf8.RuneError:
+			return false
+		case r == '\t' || r == '\n' || r == '\r' || r == '\f' || r == '\v':
+		case r < 0x20 || (r >= 0x7F && r < 0xA0):
+			controls++
+		}
+	}
+	return float64(controls) <= maxControlRatio*float64(chars)
+}
+
+// Encode returns the bytes of the document with its text replaced by the
+// given text, in the document's encoding, with its byte order mark if it
+// had one. A character Latin-1 cannot represent is written as '?'.
+func (d Document) Encode(text string) []byte {
+	var out []byte
+	if d.BOM {
+		for _, bom := range boms {
+			if bom.encoding == d.Encoding {
+				out = append(out, bom.mark...)
+			}
+		}
+	}
+
+	switch d.Encoding {
+	case UTF16LE, UTF16BE:
+		order := byteOrder(d.Encoding)
+		for _, u := range utf16.Encode([]rune(text)) {
+			out = order.AppendUint16(out, u)
+		}
+	case Latin1:
+		for _, r := range text {
+			if r > 0xFF {
+				r = '?'
+			}
+			out = append(out, byte(r))
+		}
+	default:
+		out = append(out, text...)
+	}
+	return out
+}
+
+// byteOrder returns the byte order of a UTF-16 encoding.
+func byteOrder(enc Encoding) interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+} {
+	if enc == UTF16BE {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
@@ -0,0 +1,101 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package document
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		text     string
+		encoding Encoding
+		bom      bool
+	}{
+		{"utf-8", []byte("Call Jane on 555-1234"), "Call Jane on 555-1234",
+			UTF8, false},
+		{"utf-8 with bom", []byte("\xEF\xBB\xBFcafé"), "café", UTF8, true},
+		{"utf-16le with bom", []byte{0xFF, 0xFE, 'H', 0, 'i', 0}, "Hi",
+			UTF16LE, true},
+		{"utf-16be with bom", []byte{0xFE, 0xFF, 0, 'H', 0, 'i'}, "Hi",
+			UTF16BE, true},
+		{"utf-16le without bom", []byte{'J', 0, 'a', 0, 'n', 0, 'e', 0},
+			"Jane", UTF16LE, false},
+		{"utf-16be without bom", []byte{0, 'J', 0, 'a', 0, 'n', 0, 'e'},
+			"Jane", UTF16BE, false},
+		{"latin-1", []byte("caf\xE9 cr\xE8me"), "café crème", Latin1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, ok := Decode(tt.input)
+			if !ok {
+				t.Fatalf("expected %q to be text", tt.input)
+			}
+			if doc.Text != tt.text || doc.Encoding != tt.encoding ||
+				doc.BOM != tt.bom {
+				t.Errorf("got %+v, want text %q in %s (bom %v)", doc,
+					tt.text, tt.encoding, tt.bom)
+			}
+
+			// Encoding the same text gives back the same bytes
+			if got := doc.Encode(doc.Text); !bytes.Equal(got, tt.input) {
+				t.Errorf("Encode() = %v, want %v", got, tt.input)
+			}
+		})
+	}
+}
+
+func TestDecodeBinary(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"png header", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")},
+		{"gzip header", []byte{0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x03}},
+		{"control characters", []byte("a\x01b\x02c\x03d\x04")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if doc, ok := Decode(tt.input); ok {
+				t.Errorf("expected binary data, got %+v", doc)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  Document
+		text string
+		want []byte
+	}{
+		{"utf-8", Document{Encoding: UTF8}, "Jo", []byte("Jo")},
+		{"utf-16le with bom", Document{Encoding: UTF16LE, BOM: true}, "Jo",
+			[]byte{0xFF, 0xFE, 'J', 0, 'o', 0}},
+		{"latin-1 replaces unrepresentable characters",
+			Document{Encoding: Latin1}, "Zoë €5", []byte("Zo\xEB ?5")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.doc.Encode(tt.text); !bytes.Equal(got, tt.want) {
+				t.Errorf("Encode(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package e2e tests the anonymizer end to end against a disposable
+// database: it loads schema fixtures, anonymizes them, and checks that no
+// original values remain and that the anonymized rows still satisfy the
+// schema's keys, foreign keys, and CHECK constraints.
+package e2e
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/fingerprint"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+	"github.com/pgedge/pgedge-anonymizer/internal/pattern"
+	"github.com/pgedge/pgedge-anonymizer/internal/verify"
+)
+
+//go:embed fixtures/schema.sql
+var defaultFixtures string
+
+//go:embed fixtures/config.yaml
+var defaultConfig []byte
+
+// DefaultFixtures returns the built-in fixtures, a small schema holding
+// personal data in the anonymizer_e2e schema, and the configuration
+// anonymizing them, without a database section.
+func DefaultFixtures() (string, *config.Config, error) {
+	var cfg config.Config
+	if err := yaml.Unmarshal(defaultConfig, &cfg); err != nil {
+		return "", nil, fmt.Errorf("failed to parse fixture config: %w", err)
+	}
+	cfg.ResolveProfiles()
+	return defaultFixtures, &cfg, nil
+}
+
+// Options configures a test.
+type Options struct {
+	// Config configures the run, with its database section naming the
+	// disposable database to load the fixtures into.
+	Config *config.Config
+
+	// Fixtures is SQL creating and populating the tables the configuration
+	// anonymizes.
+	Fixtures string
+
+	Patterns   *pattern.Registry
+	Generators []generator.Generator
+	Quiet      bool
+
+	// Keep leaves the fixtures in the database after the test, rather than
+	// dropping the schemas they created.
+	Keep bool
+}
+
+// Check is the outcome of one step of a test.
+type Check struct {
+	Name    string
+	Passed  bool
+	Details []string
+}
+
+// Report is the outcome of a test.
+type Report struct {
+	Database string
+	Checks   []Check
+	Passed   bool
+}
+
+// add adds a check to the report, returning whether it passed.
+func (r *Report) add(c Check) bool {
+	r.Checks = append(r.Checks, c)
+	r.Passed = r.Passed && c.Passed
+	return c.Passed
+}
+
+// Write writes the report as text.
+func (r *Report) Write(w io.Writer) {
+	fmt.Fprintf(w, "End-to-end test of database %s\n", r.Database)
+	for _, c := range r.Checks {
+		status := "PASS"
+		if !c.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "  %s  %s\n", status, c.Name)
+		for _, d := range c.Details {
+			fmt.Fprintf(w, "        %s\n", d)
+		}
+	}
+	if r.Passed {
+		fmt.Fprintln(w, "Result: PASS")
+	} else {
+		fmt.Fprintln(w, "Result: FAIL")
+	}
+}
+
+// Run loads the fixtures into the configured database, anonymizes them,
+// and checks the result. The database must hold no tables, so that a test
+// can never be run against one holding real data; the schemas created by
+// the fixtures are dropped afterwards unless Keep is set. An error is
+// returned if the test could not be carried out, and a report of the
+// checks otherwise, which may include failures.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	cfg := opts.Config
+	if cfg.HasTargets() || cfg.IsCopyMode() {
+		return nil, fmt.Errorf("end-to-end tests anonymize a single " +
+			"database: remove targets and copy_to from the configuration")
+	}
+
+	connector := database.NewConnector(&cfg.Database)
+	if err := connector.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("database connection error: %w", err)
+	}
+	defer connector.Close()
+	db := connector.DB()
+
+	if err := checkEmpty(ctx, db, cfg.Database.Database); err != nil {
+		return nil, err
+	}
+	existing, err := listSchemas(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.ExecContext(ctx, opts.Fixtures); err != nil {
+		return nil, fmt.Errorf("failed to load fixtures: %w", err)
+	}
+	if !opts.Keep {
+		defer dropSchemas(db, existing)
+	}
+
+	dir, err := os.MkdirTemp("", "pgedge-anonymizer-e2e")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Record the fingerprints of the original values to check against
+	run := *cfg
+	run.Verify.Fingerprints = filepath.Join(dir, "fingerprints")
+
+	report := &Report{Database: cfg.Database.Database, Passed: true}
+	check, err := anonymize(ctx, &run, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !report.add(check) {
+		return report, nil
+	}
+
+	validator := database.NewSchemaValidator(db)
+	if check, err = verifyColumns(ctx, &run, validator); err != nil {
+		return nil, err
+	}
+	report.add(check)
+
+	if check, err = checkConstraints(ctx, validator); err != nil {
+		return nil, err
+	}
+	report.add(check)
+
+	return report, nil
+}
+
+// anonymize runs the anonymizer, failing the check if the run fails.
+func anonymize(ctx context.Context, cfg *config.Config,
+	opts Options) (Check, error) {
+
+	check := Check{Name: "Anonymize the fixtures"}
+
+	anon, err := anonymizer.New(anonymizer.Options{
+		Config:     cfg,
+		Patterns:   opts.Patterns,
+		Quiet:      opts.Quiet,
+		Generators: opts.Generators,
+	})
+	if err != nil {
+		return check, fmt.Errorf("failed to create anonymizer: %w", err)
+	}
+	defer anon.Close()
+
+	result, err := anon.Run(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return check, ctx.Err()
+		}
+		check.Details = []string{err.Error()}
+		return check, nil
+	}
+
+	check.Passed = true
+	check.Details = []string{fmt.Sprintf("%d columns, %d rows, %d values "+
+		"anonymized", len(result.Columns), result.TotalRows,
+		result.TotalAnonymized)}
+	return check, nil
+}
+
+// verifyColumns checks the anonymized columns for original values, as the
+// verify command does.
+func verifyColumns(ctx context.Context, cfg *config.Config,
+	validator *database.SchemaValidator) (Check, error) {
+
+	check := Check{Name: "No original values remain"}
+
+	fingerprints, err := fingerprint.Load(cfg.Verify.Fingerprints)
+	if err != nil {
+		return check, err
+	}
+	verifier, err := verify.New(verify.Options{
+		Fingerprints: fingerprints,
+		Sample:       cfg.Verify.Sample,
+		MaxMatchRate: cfg.Verify.MaxMatchRate,
+		PIIPatterns:  cfg.Verify.PIIPatterns,
+	})
+	if err != nil {
+		return check, err
+	}
+
+	columns, err := validator.ExpandWildcards(ctx, cfg.Columns)
+	if err != nil {
+		return check, fmt.Errorf("wildcard expansion error: %w", err)
+	}
+	report, err := verifier.Run(ctx, cfg.Database.Database, validator,
+		columns)
+	if err != nil {
+		return check, err
+	}
+
+	check.Passed = report.Passed
+	for _, col := range report.Columns {
+		if col.Passed {
+			continue
+		}
+		detail := fmt.Sprintf("%s: %d of %d values match original values",
+			col.Column, col.FingerprintMatches, col.ValuesChecked)
+		names := make([]string, 0, len(col.PIIMatches))
+		for name := range col.PIIMatches {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if n := col.PIIMatches[name]; n > 0 {
+				detail += fmt.Sprintf(", %d match %s", n, name)
+			}
+		}
+		check.Details = append(check.Details, detail)
+	}
+	return check, nil
+}
+
+// checkConstraints checks that the anonymized rows satisfy the schema's
+// constraints.
+func checkConstraints(ctx context.Context,
+	validator *database.SchemaValidator) (Check, error) {
+
+	check := Check{Name: "Constraints and foreign keys hold"}
+
+	violations, err := validator.RecheckConstraints(ctx)
+	if err != nil {
+		return check, err
+	}
+
+	check.Passed = len(violations) == 0
+	for _, v := range violations {
+		check.Details = append(check.Details, fmt.Sprintf("%s on %s: %v",
+			v.Constraint, v.Table, v.Err))
+	}
+	return check, nil
+}
+
+// systemSchemas matches the schemas of the system catalogs.
+const systemSchemas = `n.nspname NOT IN ('pg_catalog', 'information_schema')
+          AND n.nspname NOT LIKE 'pg\_%'`
+
+// checkEmpty returns an error if the database holds any tables outside
+// the system schemas.
+func checkEmpty(ctx context.Context, db *sql.DB, name string) error {
+	query := `
+        SELECT count(*)
+        FROM pg_class c
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE c.relkind IN ('r', 'p', 'v', 'm', 'f')
+          AND ` + systemSchemas
+
+	var tables int
+	if err := db.QueryRowContext(ctx, query).Scan(&tables); err != nil {
+		return fmt.Errorf("failed to count tables: %w", err)
+	}
+	if tables > 0 {
+		return fmt.Errorf("database %s holds %d tables: end-to-end tests "+
+			"load fixtures into a disposable database, and refuse to run "+
+			"against one that is not empty", name, tables)
+	}
+	return nil
+}
+
+// listSchemas returns the names of the schemas outside the system ones.
+func listSchemas(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, `
+        SELECT n.nspname
+        FROM pg_namespace n
+        WHERE `+systemSchemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+	defer rows.Close()
+
+	schemas := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan schema: %w", err)
+		}
+		schemas[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+	return schemas, nil
+}
+
+// dropSchemas drops the schemas created since the existing ones were
+// listed. Objects the fixtures created in existing schemas are left.
+func dropSchemas(db *sql.DB, existing map[string]bool) {
+	// The test may have been cancelled, but the fixtures are still dropped
+	ctx := context.Background()
+
+	schemas, err := listSchemas(ctx, db)
+	if err != nil {
+		log.Printf("Warning: failed to drop fixtures: %v", err)
+		return
+	}
+	for name := range schemas {
+		if existing[name] {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, fmt.Sprintf(
+			"DROP SCHEMA %s CASCADE", pgx.Identifier{name}.Sanitize())); err != nil {
+			log.Printf("Warning: failed to drop fixture schema %s: %v",
+				name, err)
+		}
+	}
+}
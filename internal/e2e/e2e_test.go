@@ -0,0 +1,114 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+)
+
+func TestDefaultFixtures(t *testing.T) {
+	fixtures, cfg, err := DefaultFixtures()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(fixtures, "CREATE SCHEMA anonymizer_e2e") {
+		t.Error("expected the fixtures to create their own schema")
+	}
+	if len(cfg.Columns) == 0 {
+		t.Fatal("expected configured columns")
+	}
+	for _, col := range cfg.Columns {
+		if !strings.HasPrefix(col.Column, "anonymizer_e2e.") {
+			t.Errorf("column %s is outside the fixture schema", col.Column)
+		}
+		table := strings.Split(col.Column, ".")[1]
+		if !strings.Contains(fixtures,
+			"CREATE TABLE anonymizer_e2e."+table+" (") {
+			t.Errorf("column %s is of a table the fixtures do not create",
+				col.Column)
+		}
+	}
+
+	cfg.Database = config.DatabaseConfig{Database: "scratch", User: "tester"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("fixture config is invalid: %v", err)
+	}
+}
+
+func TestCheckEmpty(t *testing.T) {
+	tests := []struct {
+		name    string
+		tables  int
+		wantErr bool
+	}{
+		{"empty", 0, false},
+		{"holding tables", 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to open sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(`SELECT count\(\*\)`).WillReturnRows(
+				sqlmock.NewRows([]string{"count"}).AddRow(tt.tables))
+
+			err = checkEmpty(context.Background(), db, "scratch")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkEmpty() error = %v, wantErr %v", err,
+					tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "3 tables") {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	report := &Report{Database: "scratch", Passed: true}
+	report.add(Check{Name: "Anonymize the fixtures", Passed: true,
+		Details: []string{"3 columns, 10 rows, 30 values anonymized"}})
+	if report.add(Check{Name: "Constraints and foreign keys hold",
+		Details: []string{"orders_customer_fk on app.orders: violated"}}) {
+		t.Error("expected a failed check")
+	}
+	if report.Passed {
+		t.Error("expected the report to fail with a failed check")
+	}
+
+	var buf bytes.Buffer
+	report.Write(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"End-to-end test of database scratch",
+		"  PASS  Anonymize the fixtures",
+		"  FAIL  Constraints and foreign keys hold",
+		"        orders_customer_fk on app.orders: violated",
+		"Result: FAIL",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in report:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+-- pgEdge Anonymizer end-to-end test fixtures
+--
+-- A small CRM schema holding personal data, with the keys, foreign keys,
+-- and CHECK constraints the anonymized rows must still satisfy. Every
+-- object is created in the anonymizer_e2e schema, which the test drops
+-- when it is done.
+
+CREATE SCHEMA anonymizer_e2e;
+
+CREATE TABLE anonymizer_e2e.customers (
+    id INTEGER PRIMARY KEY,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL UNIQUE CHECK (email LIKE '%@%'),
+    phone VARCHAR(50),
+    date_of_birth DATE CHECK (date_of_birth > '1900-01-01'),
+    ssn CHAR(11) CHECK (length(ssn) = 11),
+    profile JSONB
+);
+
+CREATE TABLE anonymizer_e2e.addresses (
+    id INTEGER PRIMARY KEY,
+    customer_id INTEGER NOT NULL
+        REFERENCES anonymizer_e2e.customers(id) ON DELETE CASCADE,
+    street_address TEXT NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    postal_code VARCHAR(10),
+    UNIQUE (customer_id, street_address)
+);
+
+CREATE TABLE anonymizer_e2e.orders (
+    id INTEGER PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES anonymizer_e2e.customers(id),
+    address_id INTEGER REFERENCES anonymizer_e2e.addresses(id),
+    contact_email VARCHAR(255),
+    total NUMERIC(10, 2) NOT NULL CHECK (total >= 0)
+);
+
+INSERT INTO anonymizer_e2e.customers
+SELECT i,
+       'Firstname' || i,
+       'Lastname' || i,
+       'customer' || i || '@e2e.example.com',
+       '+1 555 01' || lpad(i::text, 5, '0'),
+       DATE '1950-01-01' + (i * 37) % 18000,
+       lpad((i % 1000)::text, 3, '0') || '-' ||
+           lpad((i % 100)::text, 2, '0') || '-' ||
+           lpad(i::text, 4, '0'),
+       jsonb_build_object(
+           'alt_email', 'alt' || i || '@e2e.example.com',
+           'contacts', jsonb_build_array(
+               jsonb_build_object('name', 'Contact' || i,
+                                  'phone', '+1 555 02' || lpad(i::text, 5, '0'))))
+FROM generate_series(1, 500) AS i;
+
+INSERT INTO anonymizer_e2e.addresses
+SELECT i, (i - 1) % 500 + 1,
+       i || ' Fixture Street',
+       'Fixtureville' || i % 50,
+       lpad(i::text, 5, '0')
+FROM generate_series(1, 750) AS i;
+
+INSERT INTO anonymizer_e2e.orders
+SELECT i, (i - 1) % 500 + 1, (i - 1) % 750 + 1,
+       'customer' || (i - 1) % 500 + 1 || '@e2e.example.com',
+       (i % 200) * 1.25
+FROM generate_series(1, 2000) AS i;
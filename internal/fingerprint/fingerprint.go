@@ -0,0 +1,187 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package fingerprint records salted hashes of the original values
+// replaced during a run, so that a later check can prove that none of
+// them remain in the database without keeping the values themselves.
+//
+// A fingerprint file holds a line of JSON with a Header, followed by a
+// line per value with its hexadecimal fingerprint.
+package fingerprint
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Version is the fingerprint file format version.
+const Version = 1
+
+// size is the number of bytes of each hash kept as a fingerprint.
+const size = 16
+
+// Header is the first line of a fingerprint file.
+type Header struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Key       string    `json:"key"`
+}
+
+// fingerprint returns the fingerprint of a value: a truncated HMAC-SHA256
+// with the file's key.
+func fingerprint(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:size])
+}
+
+// Writer writes the fingerprints of values to a file.
+type Writer struct {
+	mu  sync.Mutex
+	f   *os.File
+	w   *bufio.Writer
+	key []byte
+	err error
+}
+
+// Create creates a fingerprint file, readable only by its owner, with a
+// new random key.
+func Create(path string) (*Writer, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate fingerprint key: %w", err)
+	}
+
+	header, err := json.Marshal(Header{
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		Key:       hex.EncodeToString(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode fingerprint header: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fingerprint file: %w", err)
+	}
+
+	w := &Writer{f: f, w: bufio.NewWriter(f), key: key}
+	if _, err := w.w.Write(append(header, '\n')); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write fingerprint file: %w", err)
+	}
+	return w, nil
+}
+
+// Add writes the fingerprint of a value, keeping the first error.
+func (w *Writer) Add(value string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return
+	}
+	if _, err := w.w.WriteString(fingerprint(w.key, value) + "\n"); err != nil {
+		w.err = fmt.Errorf("failed to write fingerprint file: %w", err)
+	}
+}
+
+// Err returns the first error writing the fingerprint file.
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close flushes and closes the fingerprint file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.w.Flush(); err != nil && w.err == nil {
+		w.err = fmt.Errorf("failed to write fingerprint file: %w", err)
+	}
+	if err := w.f.Close(); err != nil && w.err == nil {
+		w.err = fmt.Errorf("failed to write fingerprint file: %w", err)
+	}
+	return w.err
+}
+
+// Set holds the fingerprints read from a file.
+type Set struct {
+	key          []byte
+	fingerprints map[string]struct{}
+}
+
+// Load reads a fingerprint file.
+func Load(path string) (*Set, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fingerprint file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read fingerprint file: %w", err)
+		}
+		return nil, fmt.Errorf("fingerprint file %s is empty", path)
+	}
+
+	var header Header
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, fmt.Errorf("invalid fingerprint file header: %w", err)
+	}
+	if header.Version != Version {
+		return nil, fmt.Errorf("unsupported fingerprint file version %d",
+			header.Version)
+	}
+
+	s := &Set{fingerprints: make(map[string]struct{})}
+	if s.key, err = hex.DecodeString(header.Key); err != nil ||
+		len(s.key) == 0 {
+		return nil, fmt.Errorf("invalid fingerprint file key")
+	}
+
+	for line := 2; scanner.Scan(); line++ {
+		fp := scanner.Text()
+		if len(fp) != 2*size {
+			return nil, fmt.Errorf("invalid fingerprint on line %d", line)
+		}
+		s.fingerprints[fp] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read fingerprint file: %w", err)
+	}
+
+	return s, nil
+}
+
+// Contains reports whether the fingerprint of a value is in the set.
+func (s *Set) Contains(value string) bool {
+	_, ok := s.fingerprints[fingerprint(s.key, value)]
+	return ok
+}
+
+// Len returns the number of fingerprints in the set.
+func (s *Set) Len() int {
+	return len(s.fingerprints)
+}
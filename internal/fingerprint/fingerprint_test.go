@@ -0,0 +1,75 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package fingerprint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWriteLoad tests reading back the fingerprints written to a file
+func TestWriteLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.fingerprints")
+
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.Add("john@example.com")
+	w.Add("John Smith")
+	w.Add("john@example.com")
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "john") {
+		t.Error("fingerprint file contains an original value")
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Len() != 2 {
+		t.Errorf("expected 2 fingerprints, got %d", s.Len())
+	}
+	if !s.Contains("john@example.com") || !s.Contains("John Smith") {
+		t.Error("expected original values to be found")
+	}
+	if s.Contains("kate@example.net") {
+		t.Error("unexpected match for a value not added")
+	}
+}
+
+// TestLoadInvalid tests reading invalid fingerprint files
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"empty":       "",
+		"version":     `{"version":2,"key":"00"}` + "\n",
+		"key":         `{"version":1,"key":""}` + "\n",
+		"fingerprint": `{"version":1,"key":"00"}` + "\nabc\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
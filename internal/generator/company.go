@@ -0,0 +1,212 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// vatNumbers generate the national part of the VAT numbers of the
+// countries whose check digits EUVATGenerator computes, by country prefix.
+var vatNumbers = map[string]func() string{
+	"DE": deVATNumber,
+	"FR": frVATNumber,
+	"IT": itVATNumber,
+}
+
+// vatCountries are the prefixes of vatNumbers, for values without one.
+var vatCountries = []string{"DE", "FR", "IT"}
+
+// EUVATGenerator generates EU VAT identification numbers.
+type EUVATGenerator struct {
+	BaseGenerator
+}
+
+// NewEUVATGenerator creates a new EU VAT number generator.
+func NewEUVATGenerator() *EUVATGenerator {
+	return &EUVATGenerator{
+		BaseGenerator: BaseGenerator{name: "EU_VAT"},
+	}
+}
+
+// Generate produces a VAT number of the same country as the input. German,
+// French and Italian numbers have valid check digits; those of other
+// countries keep their prefix and have their digits replaced. Input
+// without a country prefix is replaced with a German, French or Italian
+// number.
+func (g *EUVATGenerator) Generate(input string) string {
+	trimmed := strings.TrimSpace(input)
+	if len(trimmed) < 2 || !isUpperLetters(strings.ToUpper(trimmed[:2])) {
+		country := randomString(vatCountries)
+		return country + vatNumbers[country]()
+	}
+
+	country := strings.ToUpper(trimmed[:2])
+	sep := ""
+	if strings.HasPrefix(trimmed[2:], " ") {
+		sep = " "
+	}
+	if number, ok := vatNumbers[country]; ok {
+		return country + sep + number()
+	}
+	return country + sep + replaceDigits(strings.TrimSpace(trimmed[2:]))
+}
+
+// deVATNumber returns a German USt-IdNr: 9 digits, the last a check digit
+// computed with ISO 7064 MOD 11,10.
+func deVATNumber() string {
+	digits := string(randomDigitNonZero()) + generateDigits(7)
+
+	product := 10
+	for i := 0; i < len(digits); i++ {
+		sum := (int(digits[i]-'0') + product) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = (2 * sum) % 11
+	}
+	check := (11 - product) % 10
+	return digits + string(byte('0'+check))
+}
+
+// frVATNumber returns a French numéro de TVA: a 2 digit key followed by
+// the 9 digit SIREN it is computed from, which ends in a Luhn check digit.
+func frVATNumber() string {
+	payload := string(randomDigitNonZero()) + generateDigits(7)
+	siren := payload + string(luhnCheckDigit(payload))
+
+	n := 0
+	for i := 0; i < len(siren); i++ {
+		n = n*10 + int(siren[i]-'0')
+	}
+	return fmt.Sprintf("%02d%s", (12+3*(n%97))%97, siren)
+}
+
+// itVATNumber returns an Italian partita IVA: 7 digits identifying the
+// company, 3 for the provincial office that issued it, and a Luhn check
+// digit.
+func itVATNumber() string {
+	payload := generateDigits(7) + fmt.Sprintf("%03d", 1+randomInt(100))
+	return payload + string(luhnCheckDigit(payload))
+}
+
+// einPrefixes are the prefixes the IRS assigns to EINs.
+var einPrefixes = []string{
+	"01", "02", "03", "04", "05", "06", "10", "11", "12", "13", "14", "15",
+	"16", "20", "21", "22", "23", "24", "25", "26", "27", "30", "31", "32",
+	"33", "34", "35", "36", "37", "38", "39", "40", "41", "42", "43", "44",
+	"45", "46", "47", "48", "50", "51", "52", "53", "54", "55", "56", "57",
+	"58", "59", "60", "61", "62", "63", "64", "65", "66", "67", "68", "71",
+	"72", "73", "74", "75", "76", "77", "80", "81", "82", "83", "84", "85",
+	"86", "87", "88", "90", "91", "92", "93", "94", "95", "98", "99",
+}
+
+// USEINGenerator generates US Employer Identification Numbers.
+type USEINGenerator struct {
+	BaseGenerator
+}
+
+// NewUSEINGenerator creates a new US EIN generator.
+func NewUSEINGenerator() *USEINGenerator {
+	return &USEINGenerator{
+		BaseGenerator: BaseGenerator{name: "US_EIN"},
+	}
+}
+
+// Generate produces a US EIN (XX-XXXXXXX) with a prefix the IRS assigns.
+// EINs have no check digit.
+func (g *USEINGenerator) Generate(input string) string {
+	prefix := randomString(einPrefixes)
+	if strings.TrimSpace(input) != "" && !strings.Contains(input, "-") {
+		return prefix + generateDigits(7)
+	}
+	return prefix + "-" + generateDigits(7)
+}
+
+// UKCRNGenerator generates UK Companies House registration numbers.
+type UKCRNGenerator struct {
+	BaseGenerator
+}
+
+// NewUKCRNGenerator creates a new UK company registration number generator.
+func NewUKCRNGenerator() *UKCRNGenerator {
+	return &UKCRNGenerator{
+		BaseGenerator: BaseGenerator{name: "UK_CRN"},
+	}
+}
+
+// Generate produces a UK company registration number (8 characters). The
+// two letter prefix of the input, such as SC for Scottish companies or OC
+// for LLPs, is kept; numbers without one are of companies in England and
+// Wales. Registration numbers have no check digit.
+func (g *UKCRNGenerator) Generate(input string) string {
+	trimmed := strings.ToUpper(strings.TrimSpace(input))
+	if len(trimmed) >= 2 && isUpperLetters(trimmed[:2]) {
+		return trimmed[:2] + fmt.Sprintf("%06d", 1+randomInt(999999))
+	}
+	return fmt.Sprintf("%08d", 1+randomInt(15999999))
+}
+
+// hrNumberPattern matches the register and number of a Handelsregister
+// entry, such as "HRB 12345".
+var hrNumberPattern = regexp.MustCompile(`(HR[AB])(\s*)(\d+)`)
+
+// DEHRBGenerator generates German commercial register numbers.
+type DEHRBGenerator struct {
+	BaseGenerator
+}
+
+// NewDEHRBGenerator creates a new German Handelsregister number generator.
+func NewDEHRBGenerator() *DEHRBGenerator {
+	return &DEHRBGenerator{
+		BaseGenerator: BaseGenerator{name: "DE_HRB"},
+	}
+}
+
+// Generate produces a German Handelsregister number (HRB 12345). The
+// number of the input is replaced with one of the same length, keeping
+// the register (HRA or HRB) and any court or suffix around it, as in
+// "Amtsgericht München HRB 123456" or "HRB 12345 B". Register numbers
+// have no check digit.
+func (g *DEHRBGenerator) Generate(input string) string {
+	m := hrNumberPattern.FindStringSubmatchIndex(input)
+	if m == nil {
+		return "HRB " + string(randomDigitNonZero()) + generateDigits(4)
+	}
+
+	n := m[7] - m[6]
+	number := string(randomDigitNonZero()) + generateDigits(n-1)
+	return input[:m[6]] + number + input[m[7]:]
+}
+
+// isUpperLetters returns true if s holds only the letters A to Z.
+func isUpperLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// replaceDigits replaces each digit of s with a random digit, keeping the
+// other characters.
+func replaceDigits(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= '0' && c <= '9' {
+			b[i] = randomDigit()
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,145 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// validDEVAT checks the MOD 11,10 check digit of a German VAT number.
+func validDEVAT(digits string) bool {
+	product := 10
+	for i := 0; i < 8; i++ {
+		sum := (int(digits[i]-'0') + product) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = (2 * sum) % 11
+	}
+	return (11-product)%10 == int(digits[8]-'0')
+}
+
+// validFRVAT checks the key and SIREN of a French VAT number.
+func validFRVAT(digits string) bool {
+	key, _ := strconv.Atoi(digits[:2])
+	siren, _ := strconv.Atoi(digits[2:])
+	return isValidLuhn(digits[2:]) && key == (12+3*(siren%97))%97
+}
+
+func TestEUVATGenerator(t *testing.T) {
+	g := NewEUVATGenerator()
+
+	tests := []struct {
+		input   string
+		pattern string
+		valid   func(digits string) bool
+	}{
+		{"DE123456789", `^DE[1-9]\d{8}$`, validDEVAT},
+		{"FR 40303265045", `^FR \d{11}$`, validFRVAT},
+		{"IT00743110157", `^IT\d{11}$`, isValidLuhn},
+		{"NL123456789B01", `^NL\d{9}B\d{2}$`, nil},
+		{"ATU12345678", `^ATU\d{8}$`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			re := regexp.MustCompile(tt.pattern)
+			for range 50 {
+				result := g.Generate(tt.input)
+				if !re.MatchString(result) {
+					t.Fatalf("expected %s to match %s", result, tt.pattern)
+				}
+				digits := strings.TrimSpace(result[2:])
+				if tt.valid != nil && !tt.valid(digits) {
+					t.Fatalf("invalid check digits in %s", result)
+				}
+			}
+		})
+	}
+
+	t.Run("without a prefix", func(t *testing.T) {
+		for range 50 {
+			result := g.Generate("123456789")
+			number, ok := vatNumbers[result[:2]]
+			if !ok {
+				t.Fatalf("unexpected country in %s", result)
+			}
+			if len(result[2:]) != len(number()) {
+				t.Fatalf("unexpected length of %s", result)
+			}
+		}
+	})
+}
+
+func TestUSEINGenerator(t *testing.T) {
+	g := NewUSEINGenerator()
+	prefixes := strings.Join(einPrefixes, " ")
+
+	for _, input := range []string{"12-3456789", "123456789"} {
+		result := g.Generate(input)
+		digits := strings.ReplaceAll(result, "-", "")
+		if len(digits) != 9 {
+			t.Errorf("expected 9 digits, got %s", result)
+		}
+		if strings.Contains(input, "-") != strings.Contains(result, "-") {
+			t.Errorf("expected %s formatted as %s", result, input)
+		}
+		if !strings.Contains(prefixes, digits[:2]) {
+			t.Errorf("unassigned prefix in %s", result)
+		}
+	}
+}
+
+func TestUKCRNGenerator(t *testing.T) {
+	g := NewUKCRNGenerator()
+
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"01234567", `^\d{8}$`},
+		{"SC123456", `^SC\d{6}$`},
+		{"oc301234", `^OC\d{6}$`},
+	}
+
+	for _, tt := range tests {
+		result := g.Generate(tt.input)
+		if !regexp.MustCompile(tt.pattern).MatchString(result) {
+			t.Errorf("%s: expected %s to match %s", tt.input, result,
+				tt.pattern)
+		}
+	}
+}
+
+func TestDEHRBGenerator(t *testing.T) {
+	g := NewDEHRBGenerator()
+
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"HRB 12345", `^HRB [1-9]\d{4}$`},
+		{"Amtsgericht München HRA 123456", `^Amtsgericht München HRA [1-9]\d{5}$`},
+		{"HRB 98765 B", `^HRB [1-9]\d{4} B$`},
+		{"unknown", `^HRB [1-9]\d{4}$`},
+	}
+
+	for _, tt := range tests {
+		result := g.Generate(tt.input)
+		if !regexp.MustCompile(tt.pattern).MatchString(result) {
+			t.Errorf("%s: expected %s to match %s", tt.input, result,
+				tt.pattern)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// ColumnInfo describes the column values are generated for.
+type ColumnInfo struct {
+	Column errors.ColumnRef
+
+	// DataType is the column's data type as information_schema names it,
+	// such as "character varying", or empty if it is not known.
+	DataType string
+
+	// MaxLength is the most characters the column holds, or zero if its
+	// length is unlimited or not known.
+	MaxLength int
+}
+
+// ContextGenerator is implemented by generators whose output depends on
+// the column being anonymized, such as fitting its length or choosing a
+// form of value that suits its type. BindContext returns a generator for
+// that column. It supersedes ColumnBinder, which is only given the
+// column's name.
+type ContextGenerator interface {
+	Generator
+	BindContext(info ColumnInfo) Generator
+}
+
+// bind returns the generator to use for a column: the one a context
+// generator or column binder returns for it, or g itself.
+func bind(g Generator, info ColumnInfo) Generator {
+	if cg, ok := g.(ContextGenerator); ok {
+		return cg.BindContext(info)
+	}
+	if binder, ok := g.(ColumnBinder); ok {
+		return binder.BindColumn(info.Column)
+	}
+	return g
+}
+
+// fitLength cuts text to at most max characters, at the last space within
+// them if there is one, for columns of limited length. A max of zero
+// leaves text as it is.
+func fitLength(text string, max int) string {
+	runes := []rune(text)
+	if max <= 0 || len(runes) <= max {
+		return text
+	}
+	cut := runes[:max]
+	for i := len(cut) - 1; i > 0; i-- {
+		if cut[i] == ' ' {
+			cut = cut[:i]
+			break
+		}
+	}
+	return string(cut)
+}
@@ -0,0 +1,93 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// contextGenerator records the column it is bound to
+type contextGenerator struct {
+	BaseGenerator
+	info ColumnInfo
+}
+
+func (g *contextGenerator) Generate(input string) string {
+	return g.info.DataType
+}
+
+func (g *contextGenerator) BindContext(info ColumnInfo) Generator {
+	return &contextGenerator{BaseGenerator: g.BaseGenerator, info: info}
+}
+
+// TestGetForContext tests binding column metadata to generators
+func TestGetForContext(t *testing.T) {
+	m := NewManager()
+	m.Register(&contextGenerator{BaseGenerator: BaseGenerator{name: "CTX"}})
+
+	info := ColumnInfo{
+		Column:    errors.ColumnRef{Schema: "public", Table: "users", Column: "bio"},
+		DataType:  "character varying",
+		MaxLength: 40,
+	}
+	g, ok := m.GetForContext("CTX", info)
+	if !ok {
+		t.Fatal("generator not found")
+	}
+	if got := g.Generate("x"); got != "character varying" {
+		t.Errorf("Generate() = %q, want the bound data type", got)
+	}
+
+	// Column binding passes the column alone
+	g, _ = m.GetForColumn("CTX", info.Column)
+	if got := g.Generate("x"); got != "" {
+		t.Errorf("Generate() = %q, want no data type", got)
+	}
+
+	t.Run("lorem fits length", func(t *testing.T) {
+		g, _ := m.GetForContext("LOREMIPSUM", info)
+		long := string(make([]byte, 200))
+		for range 20 {
+			if got := g.Generate(long); utf8.RuneCountInString(got) > 40 {
+				t.Fatalf("value %q is longer than 40 characters", got)
+			}
+		}
+
+		// Without a length, text about as long as the input is generated
+		g, _ = m.GetForColumn("LOREMIPSUM", info.Column)
+		if got := g.Generate(long); len(got) < 200 {
+			t.Errorf("expected at least 200 characters, got %d", len(got))
+		}
+	})
+}
+
+// TestFitLength tests cutting text to a column's length
+func TestFitLength(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		max  int
+		want string
+	}{
+		{"Lorem ipsum dolor.", 0, "Lorem ipsum dolor."},
+		{"Lorem ipsum dolor.", 18, "Lorem ipsum dolor."},
+		{"Lorem ipsum dolor.", 14, "Lorem ipsum"},
+		{"Loremipsumdolor", 5, "Lorem"},
+		{"Ünïcödé text", 7, "Ünïcödé"},
+	} {
+		if got := fitLength(tt.text, tt.max); got != tt.want {
+			t.Errorf("fitLength(%q, %d) = %q, want %q", tt.text, tt.max, got,
+				tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data/countries"
+)
+
+// countryEmailDomains holds example email domains under each country's
+// top-level domain, in the forms its providers use, such as .co.uk.
+var countryEmailDomains = map[string][]string{
+	countries.AU: {"example.com.au", "mail.example.com.au", "example.net.au"},
+	countries.CA: {"example.ca", "mail.example.ca", "home.example.ca"},
+	countries.DE: {"example.de", "mail.example.de", "post.example.de"},
+	countries.ES: {"example.es", "correo.example.es", "example.com.es"},
+	countries.FI: {"example.fi", "posti.example.fi", "mail.example.fi"},
+	countries.FR: {"example.fr", "courriel.example.fr", "mail.example.fr"},
+	countries.IE: {"example.ie", "mail.example.ie", "home.example.ie"},
+	countries.IN: {"example.in", "example.co.in", "mail.example.in"},
+	countries.IT: {"example.it", "posta.example.it", "mail.example.it"},
+	countries.JP: {"example.jp", "example.co.jp", "example.ne.jp"},
+	countries.KR: {"example.kr", "example.co.kr", "mail.example.kr"},
+	countries.MX: {"example.mx", "example.com.mx", "correo.example.mx"},
+	countries.NO: {"example.no", "post.example.no", "mail.example.no"},
+	countries.NZ: {"example.nz", "example.co.nz", "mail.example.nz"},
+	countries.PK: {"example.pk", "example.com.pk", "mail.example.pk"},
+	countries.SE: {"example.se", "post.example.se", "mail.example.se"},
+	countries.SG: {"example.sg", "example.com.sg", "mail.example.sg"},
+	countries.UK: {"example.co.uk", "mail.example.co.uk", "home.example.co.uk"},
+	countries.US: {"example.com", "example.net", "example.us"},
+}
+
+// CountryEmailGenerator generates email addresses for a specific country,
+// made from the country's names at domains under its top-level domain.
+type CountryEmailGenerator struct {
+	BaseGenerator
+	firstNames []string
+	lastNames  []string
+	domains    []string
+}
+
+// NewCountryEmailGenerator creates a new country-specific email generator.
+func NewCountryEmailGenerator(country string, data *countries.CountryData) *CountryEmailGenerator {
+	return &CountryEmailGenerator{
+		BaseGenerator: BaseGenerator{name: country + "_EMAIL"},
+		firstNames:    data.FirstNames,
+		lastNames:     data.LastNames,
+		domains:       countryEmailDomains[country],
+	}
+}
+
+// Generate produces an email address for the country, with a suffix
+// derived from the input, as EMAIL does.
+func (g *CountryEmailGenerator) Generate(input string) string {
+	return emailAddress(emailLocal(randomString(g.firstNames)),
+		emailLocal(randomString(g.lastNames)), randomString(g.domains),
+		input)
+}
+
+// randomLocalPart returns a random run of lowercase letters, for the local
+// part of an email address made from a name without an ASCII form.
+func randomLocalPart() string {
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+	b := make([]byte, 6)
+	for i := range b {
+		b[i] = letters[randomInt(len(letters))]
+	}
+	return string(b)
+}
+
+// emailAddress returns an email address in a randomly chosen form made
+// from a lowercase first and last name, with a unique suffix from a hash
+// of the input, so that the same input always produces the same suffix
+// and different inputs do not collide.
+func emailAddress(firstName, lastName, domain, input string) string {
+	// Names without an ASCII form give a random local part, rather than
+	// the same one for every such name
+	if firstName == "" {
+		firstName = randomLocalPart()
+	}
+	if lastName == "" {
+		lastName = randomLocalPart()
+	}
+
+	// Use first 6 hex characters as unique suffix
+	hash := sha256.Sum256([]byte(input))
+	uniqueSuffix := hex.EncodeToString(hash[:])[:6]
+
+	// Vary email format randomly
+	format := randomInt(5)
+	switch format {
+	case 0:
+		// first.last.abc123@domain
+		return firstName + "." + lastName + "." + uniqueSuffix + "@" + domain
+	case 1:
+		// flast.abc123@domain
+		return string(firstName[0]) + lastName + "." + uniqueSuffix + "@" + domain
+	case 2:
+		// firstl.abc123@domain
+		return firstName + string(lastName[0]) + "." + uniqueSuffix + "@" + domain
+	case 3:
+		// first_last_abc123@domain
+		return firstName + "_" + lastName + "_" + uniqueSuffix + "@" + domain
+	default:
+		// firstlast.abc123@domain
+		return firstName + lastName + "." + uniqueSuffix + "@" + domain
+	}
+}
@@ -0,0 +1,268 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Version bytes of Base58Check Bitcoin addresses, by their first character.
+var btcVersions = map[byte]byte{
+	'1': 0x00, // Pay to public key hash
+	'3': 0x05, // Pay to script hash
+	'm': 0x6f, // Testnet pay to public key hash
+	'n': 0x6f,
+	'2': 0xc4, // Testnet pay to script hash
+}
+
+// BTCAddressGenerator generates Bitcoin addresses.
+type BTCAddressGenerator struct {
+	BaseGenerator
+}
+
+// NewBTCAddressGenerator creates a new Bitcoin address generator.
+func NewBTCAddressGenerator() *BTCAddressGenerator {
+	return &BTCAddressGenerator{
+		BaseGenerator: BaseGenerator{name: "BTC_ADDRESS"},
+	}
+}
+
+// Generate produces a Bitcoin address of the same type and network as the
+// input, with a valid checksum: a Base58Check address for legacy and
+// script addresses, and a bech32 or bech32m address for segwit and
+// taproot ones. The hash or key in the address is random, so no key is
+// known that could spend from it. Input of no known type is replaced with
+// a segwit address.
+func (g *BTCAddressGenerator) Generate(input string) string {
+	trimmed := strings.TrimSpace(input)
+	lower := strings.ToLower(trimmed)
+
+	for _, hrp := range []string{"bc", "tb", "bcrt"} {
+		if strings.HasPrefix(lower, hrp+"1") && len(lower) > len(hrp)+1 {
+			addr := segwitAddress(hrp, lower[len(hrp)+1:])
+			if trimmed == strings.ToUpper(trimmed) {
+				return strings.ToUpper(addr)
+			}
+			return addr
+		}
+	}
+
+	if trimmed != "" {
+		if version, ok := btcVersions[trimmed[0]]; ok {
+			return base58Check(append([]byte{version}, randomBytes(20)...))
+		}
+	}
+	return segwitAddress("bc", "q")
+}
+
+// segwitAddress returns a segwit address of the witness version and
+// program length of the data part of another, with a random program.
+func segwitAddress(hrp, data string) string {
+	version := strings.IndexByte(bech32Charset, data[0])
+	if version < 0 || version > 16 {
+		version = 0
+	}
+
+	// The data part holds the version, the program in 5 bit groups, and a
+	// 6 character checksum
+	size := (len(data) - 7) * 5 / 8
+	if size != 20 && size != 32 {
+		size = 20
+		if version > 0 {
+			size = 32
+		}
+	}
+
+	values := append([]byte{byte(version)}, toBase32(randomBytes(size))...)
+	encoding := uint32(bech32Const)
+	if version > 0 {
+		encoding = bech32mConst
+	}
+	return bech32Encode(hrp, values, encoding)
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Checksum constants of bech32, used by segwit version 0, and of bech32m,
+// used by later versions.
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// bech32Polymod returns the BCH checksum of 5 bit values.
+func bech32Polymod(values []byte) uint32 {
+	gen := []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd,
+		0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := range 5 {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HRPExpand returns the human readable part of an address as it
+// enters its checksum.
+func bech32HRPExpand(hrp string) []byte {
+	values := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]&31)
+	}
+	return values
+}
+
+// bech32Encode returns the address of 5 bit values with a checksum of the
+// given encoding.
+func bech32Encode(hrp string, values []byte, encoding uint32) string {
+	checked := append(bech32HRPExpand(hrp), values...)
+	polymod := bech32Polymod(append(checked, 0, 0, 0, 0, 0, 0)) ^ encoding
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range values {
+		sb.WriteByte(bech32Charset[v])
+	}
+	for i := range 6 {
+		sb.WriteByte(bech32Charset[(polymod>>(5*(5-i)))&31])
+	}
+	return sb.String()
+}
+
+// toBase32 regroups bytes into 5 bit values, padding the last.
+func toBase32(data []byte) []byte {
+	var values []byte
+	acc, bits := 0, 0
+	for _, b := range data {
+		acc = acc<<8 | int(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			values = append(values, byte(acc>>bits&31))
+		}
+	}
+	if bits > 0 {
+		values = append(values, byte(acc<<(5-bits)&31))
+	}
+	return values
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Check returns the Base58Check encoding of a payload: the payload
+// followed by the first 4 bytes of its double SHA-256, in base 58, with a
+// 1 for each leading zero byte.
+func base58Check(payload []byte) string {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	data := append(payload, second[:4]...)
+
+	var digits []byte
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		digits = append(digits, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		digits = append(digits, '1')
+	}
+
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits)
+}
+
+// ETHAddressGenerator generates Ethereum addresses.
+type ETHAddressGenerator struct {
+	BaseGenerator
+}
+
+// NewETHAddressGenerator creates a new Ethereum address generator.
+func NewETHAddressGenerator() *ETHAddressGenerator {
+	return &ETHAddressGenerator{
+		BaseGenerator: BaseGenerator{name: "ETH_ADDRESS"},
+	}
+}
+
+// Generate produces an Ethereum address of 40 random hex digits, with the
+// input's 0x prefix. Input in mixed case is replaced with an address with
+// an EIP-55 checksum in its case, and input in lower or upper case with
+// an address in the same case. No key is known for the address.
+func (g *ETHAddressGenerator) Generate(input string) string {
+	trimmed := strings.TrimSpace(input)
+	digits := strings.TrimPrefix(strings.TrimPrefix(trimmed, "0x"), "0X")
+	prefix := trimmed[:len(trimmed)-len(digits)]
+	if trimmed == "" {
+		prefix = "0x"
+	}
+
+	address := hex.EncodeToString(randomBytes(20))
+	switch {
+	case digits != "" && digits == strings.ToLower(digits):
+	case digits != "" && digits == strings.ToUpper(digits):
+		address = strings.ToUpper(address)
+	default:
+		address = eip55Checksum(address)
+	}
+	return prefix + address
+}
+
+// eip55Checksum returns a lowercase hex address in EIP-55 mixed case: each
+// letter is upper case where the matching hex digit of the Keccak-256 hash
+// of the address is 8 or more.
+func eip55Checksum(address string) string {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(address))
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	b := []byte(address)
+	for i, c := range b {
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			b[i] = c - 'a' + 'A'
+		}
+	}
+	return string(b)
+}
+
+// randomBytes returns n cryptographically secure random bytes.
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// Fall back to the secure integers of randomInt (should never
+		// happen)
+		for i := range b {
+			b[i] = byte(randomInt(256))
+		}
+	}
+	return b
+}
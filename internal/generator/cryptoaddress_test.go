@@ -0,0 +1,180 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// validBase58Check checks the checksum of a Base58Check address and
+// returns its version byte.
+func validBase58Check(address string) (byte, bool) {
+	n := new(big.Int)
+	for i := 0; i < len(address); i++ {
+		d := strings.IndexByte(base58Alphabet, address[i])
+		if d < 0 {
+			return 0, false
+		}
+		n.Mul(n, big.NewInt(58)).Add(n, big.NewInt(int64(d)))
+	}
+	data := n.Bytes()
+	for i := 0; i < len(address) && address[i] == '1'; i++ {
+		data = append([]byte{0}, data...)
+	}
+	if len(data) != 25 {
+		return 0, false
+	}
+
+	first := sha256.Sum256(data[:21])
+	second := sha256.Sum256(first[:])
+	return data[0], bytes.Equal(second[:4], data[21:])
+}
+
+// bech32Checksum returns the checksum constant a bech32 address satisfies:
+// bech32Const, bech32mConst, or neither.
+func bech32Checksum(address string) uint32 {
+	address = strings.ToLower(address)
+	sep := strings.LastIndexByte(address, '1')
+	values := bech32HRPExpand(address[:sep])
+	for i := sep + 1; i < len(address); i++ {
+		values = append(values, byte(strings.IndexByte(bech32Charset,
+			address[i])))
+	}
+	return bech32Polymod(values)
+}
+
+func TestChecksumVectors(t *testing.T) {
+	// Valid addresses from BIP 173, BIP 350, and the Bitcoin wiki
+	if c := bech32Checksum(
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"); c != bech32Const {
+		t.Errorf("expected a bech32 checksum, got %x", c)
+	}
+	if c := bech32Checksum("bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e" +
+		"72q4k9hcz7vqzk5jj0"); c != bech32mConst {
+		t.Errorf("expected a bech32m checksum, got %x", c)
+	}
+	for _, addr := range []string{"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2",
+		"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy"} {
+		if _, ok := validBase58Check(addr); !ok {
+			t.Errorf("expected %s to be valid", addr)
+		}
+	}
+
+	// Examples from EIP-55
+	for _, addr := range []string{
+		"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"fB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"dbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"D1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		if got := eip55Checksum(strings.ToLower(addr)); got != addr {
+			t.Errorf("expected %s, got %s", addr, got)
+		}
+	}
+}
+
+func TestBTCAddressGenerator(t *testing.T) {
+	g := NewBTCAddressGenerator()
+
+	t.Run("base58", func(t *testing.T) {
+		tests := []struct {
+			input   string
+			version byte
+		}{
+			{"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", 0x00},
+			{"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", 0x05},
+			{"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", 0x6f},
+			{"2MzQwSSnBHWHqSAqtTVQ6v47XtaisrJa1Vc", 0xc4},
+		}
+		for _, tt := range tests {
+			for range 20 {
+				result := g.Generate(tt.input)
+				version, ok := validBase58Check(result)
+				if !ok || version != tt.version {
+					t.Fatalf("%s: invalid address %s (version %x)",
+						tt.input, result, version)
+				}
+				if result[0] != tt.input[0] && tt.version != 0x6f {
+					t.Fatalf("%s: expected %s to start alike", tt.input,
+						result)
+				}
+			}
+		}
+	})
+
+	t.Run("bech32", func(t *testing.T) {
+		tests := []struct {
+			input    string
+			pattern  string
+			checksum uint32
+		}{
+			{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				`^bc1q[02-9ac-hj-np-z]{38}$`, bech32Const},
+			{"bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3",
+				`^bc1q[02-9ac-hj-np-z]{58}$`, bech32Const},
+			{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+				`^bc1p[02-9ac-hj-np-z]{58}$`, bech32mConst},
+			{"tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+				`^tb1q[02-9ac-hj-np-z]{38}$`, bech32Const},
+			{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4",
+				`^BC1Q[02-9AC-HJ-NP-Z]{38}$`, bech32Const},
+			{"", `^bc1q[02-9ac-hj-np-z]{38}$`, bech32Const},
+		}
+		for _, tt := range tests {
+			re := regexp.MustCompile(tt.pattern)
+			for range 20 {
+				result := g.Generate(tt.input)
+				if !re.MatchString(result) {
+					t.Fatalf("%q: expected %s to match %s", tt.input,
+						result, tt.pattern)
+				}
+				if c := bech32Checksum(result); c != tt.checksum {
+					t.Fatalf("%q: invalid checksum in %s", tt.input, result)
+				}
+			}
+		}
+	})
+}
+
+func TestETHAddressGenerator(t *testing.T) {
+	g := NewETHAddressGenerator()
+
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", `^0x[0-9a-fA-F]{40}$`},
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", `^0x[0-9a-f]{40}$`},
+		{"0X5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", `^0X[0-9A-F]{40}$`},
+		{"5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", `^[0-9a-f]{40}$`},
+		{"", `^0x[0-9a-fA-F]{40}$`},
+	}
+
+	for _, tt := range tests {
+		result := g.Generate(tt.input)
+		if !regexp.MustCompile(tt.pattern).MatchString(result) {
+			t.Errorf("%q: expected %s to match %s", tt.input, result,
+				tt.pattern)
+		}
+	}
+
+	for range 20 {
+		result := g.Generate("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+		if eip55Checksum(strings.ToLower(result[2:])) != result[2:] {
+			t.Fatalf("invalid EIP-55 checksum in %s", result)
+		}
+	}
+}
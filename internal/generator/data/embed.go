@@ -13,6 +13,7 @@ package data
 
 import (
 	_ "embed"
+	"strconv"
 	"strings"
 )
 
@@ -34,6 +35,9 @@ var domainsRaw string
 //go:embed lorem_words.txt
 var loremWordsRaw string
 
+//go:embed user_agents.txt
+var userAgentsRaw string
+
 // DataSet provides access to parsed data lists.
 type DataSet struct {
 	FirstNames  []string
@@ -42,6 +46,13 @@ type DataSet struct {
 	Cities      []string
 	Domains     []string
 	LoremWords  []string
+	UserAgents  []Weighted
+}
+
+// Weighted is a value of a data list with its relative frequency.
+type Weighted struct {
+	Value  string
+	Weight int
 }
 
 // parseLines splits raw text into lines, filtering empty lines.
@@ -57,6 +68,23 @@ func parseLines(raw string) []string {
 	return result
 }
 
+// parseWeightedLines splits raw text into values, each preceded on its
+// line by its weight and a tab. Lines without a weight have a weight of 1.
+func parseWeightedLines(raw string) []Weighted {
+	lines := parseLines(raw)
+	result := make([]Weighted, 0, len(lines))
+	for _, line := range lines {
+		entry := Weighted{Value: line, Weight: 1}
+		if weight, value, ok := strings.Cut(line, "\t"); ok {
+			if w, err := strconv.Atoi(weight); err == nil && w > 0 {
+				entry = Weighted{Value: strings.TrimSpace(value), Weight: w}
+			}
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
 // Load parses all embedded data files and returns a DataSet.
 func Load() *DataSet {
 	return &DataSet{
@@ -66,5 +94,6 @@ func Load() *DataSet {
 		Cities:      parseLines(citiesRaw),
 		Domains:     parseLines(domainsRaw),
 		LoremWords:  parseLines(loremWordsRaw),
+		UserAgents:  parseWeightedLines(userAgentsRaw),
 	}
 }
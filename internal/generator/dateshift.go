@@ -0,0 +1,97 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateShiftPattern is the name of the pattern shifting the dates of each
+// entity by an offset of its own.
+const DateShiftPattern = "DATE_SHIFT_CONSISTENT"
+
+// MaxDateShiftDays is the largest number of days a date is shifted by,
+// earlier or later.
+const MaxDateShiftDays = 365
+
+// dateShiftLayouts are the layouts, other than ISO dates, that dates are
+// parsed and written back in. Slash-separated dates are read as month
+// first, unless that fails.
+var dateShiftLayouts = []string{
+	"01/02/2006", "02/01/2006", "01/02/06", "02/01/06",
+	"January 2, 2006", "Jan 2, 2006", "2 January 2006", "2 Jan 2006",
+}
+
+// DateShiftGenerator shifts dates by a number of days, keeping their
+// format and any time of day. On its own it shifts each value by an
+// offset of its own; columns using it are given an entity column, and
+// all the dates of an entity, in any table, are shifted by the same
+// offset, keeping the intervals between its events.
+type DateShiftGenerator struct {
+	BaseGenerator
+}
+
+// NewDateShiftGenerator creates a date shifting generator.
+func NewDateShiftGenerator() *DateShiftGenerator {
+	return &DateShiftGenerator{
+		BaseGenerator: BaseGenerator{name: DateShiftPattern},
+	}
+}
+
+// Offset returns a random offset in days, of at most MaxDateShiftDays
+// either way, and never zero.
+func (g *DateShiftGenerator) Offset() int {
+	days := 1 + randomInt(MaxDateShiftDays)
+	if randomInt(2) == 0 {
+		return -days
+	}
+	return days
+}
+
+// Generate shifts the input by a random offset. A value that is not a
+// date is returned as it is.
+func (g *DateShiftGenerator) Generate(input string) string {
+	shifted, err := g.Shift(input, g.Offset())
+	if err != nil {
+		return input
+	}
+	return shifted
+}
+
+// Shift returns value moved by a number of days. A value starting with
+// an ISO date, such as a timestamp, has its date shifted and the rest
+// kept; other values must be dates in one of dateShiftLayouts.
+func (g *DateShiftGenerator) Shift(value string, days int) (string, error) {
+	if len(value) >= 10 {
+		if t, err := time.Parse("2006-01-02", value[:10]); err == nil {
+			return t.AddDate(0, 0, days).Format("2006-01-02") + value[10:],
+				nil
+		}
+	}
+	for _, layout := range dateShiftLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.AddDate(0, 0, days).Format(layout), nil
+		}
+	}
+	return "", fmt.Errorf("%q is not a date", value)
+}
+
+// Description describes the date shifting generator.
+func (g *DateShiftGenerator) Description() string {
+	return fmt.Sprintf("Dates shifted by up to %d days, by the same offset "+
+		"for each entity", MaxDateShiftDays)
+}
+
+// Category returns the category of date shifting.
+func (g *DateShiftGenerator) Category() string {
+	return CategoryDate
+}
@@ -0,0 +1,52 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import "testing"
+
+// TestDateShiftGenerator tests shifting dates by a number of days
+func TestDateShiftGenerator(t *testing.T) {
+	g := NewDateShiftGenerator()
+
+	tests := []struct {
+		value string
+		days  int
+		want  string
+	}{
+		{"2024-02-27", 3, "2024-03-01"},
+		{"2024-01-10", -20, "2023-12-21"},
+		{"2024-01-10 09:30:00+00", 1, "2024-01-11 09:30:00+00"},
+		{"2024-01-10T09:30:00Z", 1, "2024-01-11T09:30:00Z"},
+		{"01/31/2024", 1, "02/01/2024"},
+		{"31/01/2024", 1, "01/02/2024"},
+		{"March 3, 2024", -3, "February 29, 2024"},
+	}
+	for _, tt := range tests {
+		got, err := g.Shift(tt.value, tt.days)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.value, err)
+		} else if got != tt.want {
+			t.Errorf("%q shifted by %d: got %q, want %q", tt.value, tt.days,
+				got, tt.want)
+		}
+	}
+
+	if _, err := g.Shift("next tuesday", 1); err == nil {
+		t.Error("expected an error for a value that is not a date")
+	}
+
+	for i := 0; i < 100; i++ {
+		if days := g.Offset(); days == 0 || days < -MaxDateShiftDays ||
+			days > MaxDateShiftDays {
+			t.Fatalf("offset %d out of range", days)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeriveKinds lists the kinds of value that may be derived from other
+// columns of a row.
+var DeriveKinds = []string{"email", "name", "username"}
+
+// IsDeriveKind returns true if kind is one of DeriveKinds.
+func IsDeriveKind(kind string) bool {
+	for _, k := range DeriveKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Derive returns a value of the given kind made from the values of other
+// columns of the same row, such as an email address made from a first and
+// last name, formatted like input:
+//   - email: the sources joined with dots, with a random suffix, at a
+//     random domain, such as "jane.doe.3fa2c1@example.com"
+//   - name: the sources joined with spaces, in the case of input
+//   - username: the initials of all but the last source followed by the
+//     last source, with a random number, such as "jdoe417"
+//
+// Empty sources are left out.
+func (m *Manager) Derive(kind string, sources []string,
+	input string) (string, error) {
+
+	var parts []string
+	for _, s := range sources {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+
+	switch kind {
+	case "email":
+		local := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if l := emailLocal(part); l != "" {
+				local = append(local, l)
+			}
+		}
+		if len(local) == 0 {
+			local = []string{"user"}
+		}
+		return strings.Join(local, ".") + "." + randomHex(3) + "@" +
+			randomString(m.data.Domains), nil
+	case "name":
+		return matchCase(strings.Join(parts, " "), input), nil
+	case "username":
+		var b strings.Builder
+		for i, part := range parts {
+			l := emailLocal(part)
+			if i < len(parts)-1 && l != "" {
+				l = l[:1]
+			}
+			b.WriteString(l)
+		}
+		if b.Len() == 0 {
+			b.WriteString("user")
+		}
+		return fmt.Sprintf("%s%d", b.String(), 1+randomInt(999)), nil
+	}
+	return "", fmt.Errorf("unknown derive kind %q", kind)
+}
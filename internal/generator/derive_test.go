@@ -0,0 +1,51 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"regexp"
+	"testing"
+)
+
+// TestDerive tests deriving values from other columns of a row
+func TestDerive(t *testing.T) {
+	m := NewManager()
+
+	tests := []struct {
+		kind    string
+		sources []string
+		input   string
+		want    string // Regular expression
+	}{
+		{"email", []string{"Jane", "O'Brien"}, "x@example.com",
+			`^jane\.obrien\.[0-9a-f]{6}@[a-z0-9.-]+$`},
+		{"email", []string{"", ""}, "x@example.com",
+			`^user\.[0-9a-f]{6}@`},
+		{"name", []string{"Jane", "Doe"}, "John Smith", `^Jane Doe$`},
+		{"name", []string{"Jane", "", "Doe"}, "JOHN SMITH", `^JANE DOE$`},
+		{"username", []string{"Jane", "Doe"}, "jsmith", `^jdoe\d{1,3}$`},
+	}
+
+	for _, tt := range tests {
+		got, err := m.Derive(tt.kind, tt.sources, tt.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.kind, err)
+		}
+		if !regexp.MustCompile(tt.want).MatchString(got) {
+			t.Errorf("%s from %q: got %q, want %s", tt.kind, tt.sources,
+				got, tt.want)
+		}
+	}
+
+	if _, err := m.Derive("phone", []string{"Jane"}, ""); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
@@ -0,0 +1,168 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Generator categories.
+const (
+	CategoryAddress    = "address"
+	CategoryDate       = "date"
+	CategoryEmail      = "email"
+	CategoryIdentifier = "identifier"
+	CategoryName       = "name"
+	CategoryNetwork    = "network"
+	CategoryPayment    = "payment"
+	CategoryPhone      = "phone"
+	CategoryPostcode   = "postcode"
+	CategoryText       = "text"
+	CategoryFormat     = "format"
+	CategoryExec       = "exec"
+	CategoryScript     = "script"
+	CategoryFPE        = "fpe"
+	CategoryHash       = "hash"
+	CategoryRedact     = "redact"
+	CategorySuppress   = "suppress"
+	CategoryOther      = "other"
+)
+
+// Describer is implemented by generators that describe the values they
+// generate, for listings such as the patterns list command. It is
+// optional so that existing custom generators and plugins need not
+// change; built-in generators are categorized by name.
+type Describer interface {
+	// Description returns a one-line description of the values generated.
+	Description() string
+
+	// Category returns the kind of data generated, such as "phone".
+	Category() string
+}
+
+// Info describes a generator.
+type Info struct {
+	Name        string
+	Category    string
+	Description string
+}
+
+// Describe returns the description of a generator, from the generator
+// itself if it is a Describer, and otherwise with a category derived
+// from its name and no description.
+func Describe(g Generator) Info {
+	if d, ok := g.(Describer); ok {
+		return Info{Name: g.Name(), Category: d.Category(),
+			Description: d.Description()}
+	}
+	return Info{Name: g.Name(), Category: categoryOf(g.Name())}
+}
+
+// categoryOf derives the category of a built-in generator from its name.
+func categoryOf(name string) string {
+	switch {
+	case name == "IPV4_ADDRESS" || name == "IPV6_ADDRESS" ||
+		name == "HOSTNAME" || name == "URL" || name == "USER_AGENT":
+		return CategoryNetwork
+	case name == "BTC_ADDRESS" || name == "ETH_ADDRESS":
+		return CategoryPayment
+	case strings.Contains(name, "PHONE"):
+		return CategoryPhone
+	case strings.Contains(name, "POSTCODE") || strings.HasSuffix(name, "ZIP"):
+		return CategoryPostcode
+	case strings.Contains(name, "ADDRESS") || strings.HasSuffix(name, "CITY"):
+		return CategoryAddress
+	case strings.HasSuffix(name, "NAME") || strings.HasSuffix(name, "HANDLE"):
+		return CategoryName
+	case strings.Contains(name, "EMAIL"):
+		return CategoryEmail
+	case strings.HasPrefix(name, "CREDIT_CARD") || name == "IBAN" ||
+		name == "BIC":
+		return CategoryPayment
+	case strings.HasPrefix(name, "DOB"):
+		return CategoryDate
+	case name == "LOREMIPSUM" || name == "TEXT_SCRUB":
+		return CategoryText
+	case isIdentifierName(name):
+		return CategoryIdentifier
+	default:
+		return CategoryOther
+	}
+}
+
+// identifierNames are the suffixes of the names of built-in national,
+// company, vehicle and medical identifier generators.
+var identifierNames = []string{"SSN", "NI", "NHS", "PASSPORT", "TFN", "SIN",
+	"STEUERID", "NIF", "HETU", "NIR", "PPS", "AADHAAR", "PAN", "CF",
+	"MYNUMBER", "RRN", "CURP", "FNR", "IRD", "CNIC", "PNR", "NRIC", "VAT",
+	"EIN", "CRN", "HRB", "VIN", "LICENSE_PLATE", "MRN",
+	"NPI", "DEA"}
+
+// isIdentifierName returns true for the names of identifier generators.
+func isIdentifierName(name string) bool {
+	for _, id := range identifierNames {
+		if name == id || strings.HasSuffix(name, "_"+id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Description describes the values a format generator produces.
+func (g *FormatGenerator) Description() string {
+	switch g.config.Type {
+	case FormatTypeDate:
+		return fmt.Sprintf("Dates formatted as %q, from %d to %d",
+			g.config.Format, g.config.MinYear, g.config.MaxYear)
+	case FormatTypeNumber:
+		return fmt.Sprintf("Numbers formatted as %q, from %d to %d",
+			g.config.Format, g.config.Min, g.config.Max)
+	default:
+		return fmt.Sprintf("Values matching the mask %q", g.config.Format)
+	}
+}
+
+// Category returns the category of format patterns.
+func (g *FormatGenerator) Category() string {
+	return CategoryFormat
+}
+
+// Description describes the command generating values.
+func (g *ExecGenerator) Description() string {
+	return "Values generated by the command " +
+		strings.Join(append([]string{g.command}, g.args...), " ")
+}
+
+// Category returns the category of exec patterns.
+func (g *ExecGenerator) Category() string {
+	return CategoryExec
+}
+
+// Description describes a script generator.
+func (g *ScriptGenerator) Description() string {
+	return "Values generated by a Lua script"
+}
+
+// Category returns the category of script patterns.
+func (g *ScriptGenerator) Category() string {
+	return CategoryScript
+}
+
+// Description describes a format preserving encryption generator.
+func (g *FPEGenerator) Description() string {
+	return "Digits and letters encrypted in place with FF3-1"
+}
+
+// Category returns the category of format preserving encryption patterns.
+func (g *FPEGenerator) Category() string {
+	return CategoryFPE
+}
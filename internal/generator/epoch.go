@@ -0,0 +1,171 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values for EpochPatternConfig.Mode.
+const (
+	EpochModeShift    = "shift"    // Shift each value by a random offset
+	EpochModeGenerate = "generate" // Replace each value within a window
+)
+
+// epochMillisThreshold is the magnitude from which epoch values are read
+// as milliseconds rather than seconds: 1e11 seconds is in the year 5138,
+// while 1e11 milliseconds is in 1973.
+const epochMillisThreshold = 100_000_000_000
+
+// secondsPerDay is the number of seconds in a day.
+const secondsPerDay = 24 * 60 * 60
+
+// EpochPatternConfig holds configuration for creating an epoch timestamp
+// generator.
+type EpochPatternConfig struct {
+	Name      string // Pattern name (becomes generator name)
+	Mode      string // EpochModeShift (the default) or EpochModeGenerate
+	ShiftDays int    // Most days shifted by, or zero for MaxDateShiftDays
+	From      string // Earliest date kept or generated, as YYYY-MM-DD
+	To        string // Latest date kept or generated, as YYYY-MM-DD
+}
+
+// EpochGenerator anonymizes Unix epoch timestamps stored as integers, in
+// seconds or milliseconds, as told apart by their magnitude. It shifts
+// each value by a random number of days, keeping its time of day, or
+// generates a new one between two dates, and keeps the result between
+// those dates when they are set. A value that fits a 32-bit integer is
+// kept within that range, so that it can be written back to an integer
+// column.
+type EpochGenerator struct {
+	BaseGenerator
+	cfg      EpochPatternConfig
+	from, to time.Time // Zero if unset
+}
+
+// NewEpochGenerator creates an epoch timestamp generator.
+func NewEpochGenerator(cfg EpochPatternConfig) (*EpochGenerator, error) {
+	if cfg.Mode == "" {
+		cfg.Mode = EpochModeShift
+	}
+	if cfg.Mode != EpochModeShift && cfg.Mode != EpochModeGenerate {
+		return nil, fmt.Errorf("pattern %s: epoch mode must be '%s' or "+
+			"'%s', got %q", cfg.Name, EpochModeShift, EpochModeGenerate,
+			cfg.Mode)
+	}
+	if cfg.ShiftDays < 0 {
+		return nil, fmt.Errorf("pattern %s: shift_days cannot be negative",
+			cfg.Name)
+	}
+	if cfg.ShiftDays == 0 {
+		cfg.ShiftDays = MaxDateShiftDays
+	}
+
+	g := &EpochGenerator{
+		BaseGenerator: BaseGenerator{name: cfg.Name},
+		cfg:           cfg,
+	}
+	for _, d := range []struct {
+		value string
+		t     *time.Time
+	}{{cfg.From, &g.from}, {cfg.To, &g.to}} {
+		if d.value == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", d.value)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %q is not a date in "+
+				"YYYY-MM-DD format", cfg.Name, d.value)
+		}
+		*d.t = t
+	}
+	if cfg.Mode == EpochModeGenerate && (g.from.IsZero() || g.to.IsZero()) {
+		return nil, fmt.Errorf("pattern %s: epoch mode '%s' requires "+
+			"'from' and 'to'", cfg.Name, EpochModeGenerate)
+	}
+	if !g.from.IsZero() && !g.to.IsZero() && g.to.Before(g.from) {
+		return nil, fmt.Errorf("pattern %s: 'to' is before 'from'", cfg.Name)
+	}
+	return g, nil
+}
+
+// Generate returns an anonymized epoch timestamp in the unit of the
+// input. An input that is not an integer, such as when the pattern's
+// values are sampled, is taken as the present time, in seconds.
+func (g *EpochGenerator) Generate(input string) string {
+	value, err := strconv.ParseInt(strings.TrimSpace(input), 10, 64)
+	if err != nil {
+		value = time.Now().Unix()
+	}
+	unit := int64(1)
+	if value >= epochMillisThreshold || value <= -epochMillisThreshold {
+		unit = 1000
+	}
+
+	var out int64
+	if g.cfg.Mode == EpochModeGenerate {
+		// The window includes the whole of its last day
+		span := g.to.Unix() - g.from.Unix() + secondsPerDay
+		out = (g.from.Unix()+int64(randomInt(int(span))))*unit +
+			int64(randomInt(int(unit)))
+	} else {
+		out = value + int64(g.offset())*secondsPerDay*unit
+	}
+
+	if !g.from.IsZero() {
+		out = max(out, g.from.Unix()*unit)
+	}
+	if !g.to.IsZero() {
+		out = min(out, (g.to.Unix()+secondsPerDay)*unit-1)
+	}
+	if value >= math.MinInt32 && value <= math.MaxInt32 {
+		out = min(max(out, math.MinInt32), math.MaxInt32)
+	}
+	return strconv.FormatInt(out, 10)
+}
+
+// offset returns a random offset in days, of at most ShiftDays either
+// way, and never zero.
+func (g *EpochGenerator) offset() int {
+	days := 1 + randomInt(g.cfg.ShiftDays)
+	if randomInt(2) == 0 {
+		return -days
+	}
+	return days
+}
+
+// Description describes how an epoch generator changes values.
+func (g *EpochGenerator) Description() string {
+	desc := "Epoch timestamps in seconds or milliseconds, "
+	if g.cfg.Mode == EpochModeGenerate {
+		return desc + fmt.Sprintf("generated between %s and %s", g.cfg.From,
+			g.cfg.To)
+	}
+	desc += fmt.Sprintf("shifted by up to %d days", g.cfg.ShiftDays)
+	switch {
+	case g.cfg.From != "" && g.cfg.To != "":
+		desc += fmt.Sprintf(", kept between %s and %s", g.cfg.From, g.cfg.To)
+	case g.cfg.From != "":
+		desc += ", kept from " + g.cfg.From
+	case g.cfg.To != "":
+		desc += ", kept until " + g.cfg.To
+	}
+	return desc
+}
+
+// Category returns the category of epoch patterns.
+func (g *EpochGenerator) Category() string {
+	return CategoryDate
+}
@@ -0,0 +1,118 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestEpochGenerator tests shifting and generating epoch timestamps
+func TestEpochGenerator(t *testing.T) {
+	parse := func(t *testing.T, s string) int64 {
+		t.Helper()
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			t.Fatalf("value %q is not an integer", s)
+		}
+		return n
+	}
+
+	t.Run("shift", func(t *testing.T) {
+		g, err := NewEpochGenerator(EpochPatternConfig{Name: "E",
+			ShiftDays: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		const seconds, millis = 1700000123, 1700000123456
+		for i := 0; i < 20; i++ {
+			// The time of day is kept, in the unit of the input
+			shifted := parse(t, g.Generate(strconv.Itoa(seconds)))
+			diff := shifted - seconds
+			if diff == 0 || diff%secondsPerDay != 0 ||
+				diff < -10*secondsPerDay || diff > 10*secondsPerDay {
+				t.Fatalf("seconds shifted by %d", diff)
+			}
+
+			shifted = parse(t, g.Generate(strconv.Itoa(millis)))
+			diff = shifted - millis
+			if diff == 0 || diff%(secondsPerDay*1000) != 0 ||
+				diff < -10*secondsPerDay*1000 || diff > 10*secondsPerDay*1000 {
+				t.Fatalf("milliseconds shifted by %d", diff)
+			}
+		}
+	})
+
+	t.Run("window", func(t *testing.T) {
+		g, err := NewEpochGenerator(EpochPatternConfig{Name: "E",
+			Mode: EpochModeGenerate, From: "2020-01-01", To: "2020-12-31"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+		to := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+		for i := 0; i < 20; i++ {
+			if v := parse(t, g.Generate("1700000000")); v < from || v >= to {
+				t.Fatalf("seconds %d outside the window", v)
+			}
+			if v := parse(t, g.Generate("1700000000000")); v < from*1000 ||
+				v >= to*1000 {
+				t.Fatalf("milliseconds %d outside the window", v)
+			}
+		}
+
+		// Shifted values are kept within the window too
+		g, err = NewEpochGenerator(EpochPatternConfig{Name: "E",
+			From: "2023-11-14"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		start := time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC).Unix()
+		for i := 0; i < 20; i++ {
+			if v := parse(t, g.Generate("1699999999")); v < start {
+				t.Fatalf("seconds %d before the window", v)
+			}
+		}
+	})
+
+	t.Run("integer range", func(t *testing.T) {
+		g, err := NewEpochGenerator(EpochPatternConfig{Name: "E",
+			ShiftDays: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i := 0; i < 20; i++ {
+			if v := parse(t, g.Generate(strconv.Itoa(math.MaxInt32))); v >
+				math.MaxInt32 {
+				t.Fatalf("value %d does not fit an integer column", v)
+			}
+		}
+
+		// Values that are not integers are replaced with one
+		parse(t, g.Generate(""))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, cfg := range map[string]EpochPatternConfig{
+			"mode":           {Name: "E", Mode: "random"},
+			"negative shift": {Name: "E", ShiftDays: -1},
+			"no window":      {Name: "E", Mode: EpochModeGenerate},
+			"bad date":       {Name: "E", From: "01/02/2020"},
+			"reversed":       {Name: "E", From: "2021-01-01", To: "2020-01-01"},
+		} {
+			if _, err := NewEpochGenerator(cfg); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,192 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// BatchGenerator is implemented by generators that can produce values for
+// many inputs more efficiently than one at a time.
+type BatchGenerator interface {
+	Generator
+
+	// GenerateBatch returns one anonymized value per input, in order.
+	GenerateBatch(inputs []string) []string
+}
+
+// FallibleGenerator is implemented by generators that can fail, such as
+// those backed by external commands. After a failure, Generate returns
+// empty strings and Err reports the cause; callers must check Err before
+// committing any generated values.
+type FallibleGenerator interface {
+	Generator
+
+	// Err returns the first error encountered, or nil.
+	Err() error
+}
+
+// ExecGenerator generates values by piping them through an external
+// command. The command is started on first use and kept running; each
+// input is written to its stdin as a JSON string on its own line, and it
+// must write one JSON string line per input to stdout, in order.
+type ExecGenerator struct {
+	BaseGenerator
+	command string
+	args    []string
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	writer *bufio.Writer
+	reader *bufio.Reader
+	err    error
+}
+
+// NewExecGenerator creates a generator backed by an external command.
+func NewExecGenerator(name, command string, args []string) *ExecGenerator {
+	return &ExecGenerator{
+		BaseGenerator: BaseGenerator{name: name},
+		command:       command,
+		args:          args,
+	}
+}
+
+// Generate returns the command's replacement for a single input.
+func (g *ExecGenerator) Generate(input string) string {
+	return g.GenerateBatch([]string{input})[0]
+}
+
+// GenerateBatch sends all inputs to the command in one go rather than
+// waiting for each result in turn, so a batch costs a single round trip.
+func (g *ExecGenerator) GenerateBatch(inputs []string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	results := make([]string, len(inputs))
+	if g.err != nil || len(inputs) == 0 {
+		return results
+	}
+
+	if err := g.exchange(inputs, results); err != nil {
+		g.err = fmt.Errorf("pattern %s: command %s: %w", g.name, g.command, err)
+		g.stop()
+		clear(results)
+	}
+
+	return results
+}
+
+// exchange sends inputs to the command and reads back the results,
+// starting the command if necessary.
+func (g *ExecGenerator) exchange(inputs, results []string) error {
+	if g.cmd == nil {
+		if err := g.start(); err != nil {
+			return err
+		}
+	}
+
+	// Write inputs concurrently with reading results, so that a command
+	// that answers each line as it arrives cannot fill the output pipe
+	// while inputs are still being written
+	writeErr := make(chan error, 1)
+	go func() {
+		writeErr <- g.writeInputs(inputs)
+	}()
+
+	for i := range results {
+		line, err := g.reader.ReadBytes('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read output: %w", err)
+		}
+		if err := json.Unmarshal(line, &results[i]); err != nil {
+			return fmt.Errorf("invalid output line %q: expected a JSON string",
+				line)
+		}
+	}
+
+	return <-writeErr
+}
+
+// writeInputs writes each input to the command as a JSON string line.
+func (g *ExecGenerator) writeInputs(inputs []string) error {
+	for _, input := range inputs {
+		line, err := json.Marshal(input)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+		if _, err := g.writer.Write(line); err != nil {
+			return fmt.Errorf("failed to write input: %w", err)
+		}
+	}
+	if err := g.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write input: %w", err)
+	}
+	return nil
+}
+
+// start launches the command with pipes attached.
+func (g *ExecGenerator) start() error {
+	cmd := exec.Command(g.command, g.args...)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start: %w", err)
+	}
+
+	g.cmd = cmd
+	g.stdin = stdin
+	g.writer = bufio.NewWriter(stdin)
+	g.reader = bufio.NewReader(stdout)
+	return nil
+}
+
+// stop closes the command's stdin and waits for it to exit.
+func (g *ExecGenerator) stop() {
+	if g.cmd == nil {
+		return
+	}
+	_ = g.stdin.Close()
+	if err := g.cmd.Wait(); err != nil && g.err == nil {
+		g.err = fmt.Errorf("pattern %s: command %s: %w", g.name, g.command, err)
+	}
+	g.cmd = nil
+}
+
+// Err returns the first error encountered running the command.
+func (g *ExecGenerator) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// Close stops the command, if running.
+func (g *ExecGenerator) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stop()
+	return g.err
+}
@@ -0,0 +1,155 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"os/exec"
+	"strconv"
+	"testing"
+)
+
+// requireShell skips tests that need a POSIX shell to run commands.
+func requireShell(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+}
+
+// TestExecGenerator tests generators backed by external commands
+func TestExecGenerator(t *testing.T) {
+	requireShell(t)
+
+	t.Run("echo command", func(t *testing.T) {
+		// cat echoes each JSON line back unchanged
+		g := NewExecGenerator("ECHO", "cat", nil)
+		defer g.Close()
+
+		if got := g.Generate("john@example.com"); got != "john@example.com" {
+			t.Errorf("Generate() = %q, want input echoed", got)
+		}
+		if got := g.Generate("line\nbreak \"quoted\""); got != "line\nbreak \"quoted\"" {
+			t.Errorf("Generate() = %q, want special characters preserved", got)
+		}
+		if err := g.Err(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		// Convert each line separately; tr alone would buffer its output
+		g := NewExecGenerator("UPPER", "sh", []string{"-c",
+			`while IFS= read -r line; do echo "$line" | tr a-z A-Z; done`})
+		defer g.Close()
+
+		got := g.GenerateBatch([]string{"alice", "bob", "carol"})
+		want := []string{"ALICE", "BOB", "CAROL"}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("GenerateBatch()[%d] = %q, want %q", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("large batch", func(t *testing.T) {
+		// More output than a pipe buffer holds must not deadlock
+		g := NewExecGenerator("ECHO", "cat", nil)
+		defer g.Close()
+
+		inputs := make([]string, 20000)
+		for i := range inputs {
+			inputs[i] = "value-" + strconv.Itoa(i)
+		}
+		got := g.GenerateBatch(inputs)
+		if err := g.Err(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got[len(got)-1] != inputs[len(inputs)-1] {
+			t.Errorf("last value = %q, want %q", got[len(got)-1],
+				inputs[len(inputs)-1])
+		}
+	})
+
+	t.Run("command not found", func(t *testing.T) {
+		g := NewExecGenerator("MISSING", "/nonexistent/command", nil)
+		defer g.Close()
+
+		if got := g.Generate("value"); got != "" {
+			t.Errorf("Generate() = %q, want empty string on failure", got)
+		}
+		if g.Err() == nil {
+			t.Error("expected error for missing command")
+		}
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		g := NewExecGenerator("BAD", "sh",
+			[]string{"-c", "while read line; do echo not-json; done"})
+		defer g.Close()
+
+		g.Generate("value")
+		if g.Err() == nil {
+			t.Error("expected error for non-JSON output")
+		}
+
+		// Once failed, the generator stays failed
+		if got := g.Generate("other"); got != "" {
+			t.Errorf("Generate() = %q after failure, want empty string", got)
+		}
+	})
+
+	t.Run("command exits early", func(t *testing.T) {
+		g := NewExecGenerator("EXIT", "sh", []string{"-c", "exit 3"})
+		defer g.Close()
+
+		g.Generate("value")
+		if g.Err() == nil {
+			t.Error("expected error when command exits")
+		}
+	})
+}
+
+// TestManagerExecPattern tests registering exec patterns with the manager
+func TestManagerExecPattern(t *testing.T) {
+	requireShell(t)
+
+	m := NewManager()
+	defer m.Close()
+
+	if err := m.RegisterExecPattern(ExecPatternConfig{Name: "BAD"}); err == nil {
+		t.Error("expected error for empty command")
+	}
+
+	err := m.RegisterExecPattern(ExecPatternConfig{
+		Name:    "FAILING",
+		Command: "sh",
+		Args:    []string{"-c", "exit 1"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	g, ok := m.Get("FAILING")
+	if !ok {
+		t.Fatal("exec pattern not registered")
+	}
+	if _, ok := g.(BatchGenerator); !ok {
+		t.Error("exec generator should support batching")
+	}
+
+	if err := m.Err(); err != nil {
+		t.Errorf("unexpected error before use: %v", err)
+	}
+	g.Generate("value")
+	if m.Err() == nil {
+		t.Error("expected manager to report generator failure")
+	}
+}
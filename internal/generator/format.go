@@ -81,6 +81,13 @@ func (g *FormatGenerator) Generate(input string) string {
 	}
 }
 
+// GeneratesDates returns true if the generator produces dates, whether
+// its type is date or is detected from the format.
+func (g *FormatGenerator) GeneratesDates() bool {
+	return g.config.Type == FormatTypeDate ||
+		(g.config.Type == "" && containsDateCodes(g.config.Format))
+}
+
 // generateDate generates a random date in the specified format.
 // Supports strftime-like format codes.
 func (g *FormatGenerator) generateDate() string {
@@ -143,10 +150,19 @@ func (g *FormatGenerator) generateDate() string {
 //
 // All other characters are literals.
 func (g *FormatGenerator) generateMask() string {
+	return fillMask(g.config.Format, randomInt)
+}
+
+// fillMask replaces the placeholders of a mask with characters chosen by
+// intn, which returns an integer in [0, n).
+func fillMask(format string, intn func(n int) int) string {
 	var result strings.Builder
-	format := g.config.Format
 	escaped := false
 
+	digit := func() byte { return byte('0' + intn(10)) }
+	upper := func() byte { return byte('A' + intn(26)) }
+	lower := func() byte { return byte('a' + intn(26)) }
+
 	for i := 0; i < len(format); i++ {
 		c := format[i]
 
@@ -160,31 +176,31 @@ func (g *FormatGenerator) generateMask() string {
 		case '\\':
 			escaped = true
 		case '#', '9':
-			result.WriteByte(randomDigit())
+			result.WriteByte(digit())
 		case 'A':
-			result.WriteByte(randomUpperLetter())
+			result.WriteByte(upper())
 		case 'a':
-			result.WriteByte(randomLowerLetter())
+			result.WriteByte(lower())
 		case 'X':
-			if randomInt(2) == 0 {
-				result.WriteByte(randomDigit())
+			if intn(2) == 0 {
+				result.WriteByte(digit())
 			} else {
-				result.WriteByte(randomUpperLetter())
+				result.WriteByte(upper())
 			}
 		case 'x':
-			if randomInt(2) == 0 {
-				result.WriteByte(randomDigit())
+			if intn(2) == 0 {
+				result.WriteByte(digit())
 			} else {
-				result.WriteByte(randomLowerLetter())
+				result.WriteByte(lower())
 			}
 		case '*':
-			switch randomInt(3) {
+			switch intn(3) {
 			case 0:
-				result.WriteByte(randomDigit())
+				result.WriteByte(digit())
 			case 1:
-				result.WriteByte(randomUpperLetter())
+				result.WriteByte(upper())
 			default:
-				result.WriteByte(randomLowerLetter())
+				result.WriteByte(lower())
 			}
 		default:
 			result.WriteByte(c)
@@ -207,16 +223,6 @@ func (g *FormatGenerator) generateNumber() string {
 	return fmt.Sprintf(g.config.Format, value)
 }
 
-// randomUpperLetter returns a random uppercase letter A-Z.
-func randomUpperLetter() byte {
-	return byte('A' + randomInt(26))
-}
-
-// randomLowerLetter returns a random lowercase letter a-z.
-func randomLowerLetter() byte {
-	return byte('a' + randomInt(26))
-}
-
 // containsDateCodes checks if a format string contains date/time codes.
 func containsDateCodes(format string) bool {
 	dateCodes := []string{"%Y", "%y", "%m", "%d", "%H", "%M", "%S", "%I",
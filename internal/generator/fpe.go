@@ -0,0 +1,416 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/sealed"
+)
+
+// FF3-1 limits, from NIST SP 800-38G Revision 1: the domain of the values
+// encrypted must hold at least a million values, and, in radix 10, a value
+// may have at most 56 digits.
+const (
+	fpeMinDomain = 1000000
+	fpeMaxDigits = 56
+	fpeTweakSize = 7 // 56 bits
+)
+
+// ReversibleGenerator is implemented by generators whose values can be
+// converted back to the original values, such as those of format
+// preserving encryption patterns.
+type ReversibleGenerator interface {
+	Generator
+
+	// Reverse returns the original value a generated value was made from.
+	Reverse(value string) (string, error)
+}
+
+// ff31 is the FF3-1 format-preserving encryption mode of AES, for strings
+// of decimal digits.
+type ff31 struct {
+	block  cipher.Block
+	tl, tr [4]byte // Left and right halves of the tweak
+}
+
+// newFF31 creates an FF3-1 cipher from an AES key and a 56-bit tweak.
+func newFF31(key, tweak []byte) (*ff31, error) {
+	if len(tweak) != fpeTweakSize {
+		return nil, fmt.Errorf("tweak must be %d bytes, got %d", fpeTweakSize,
+			len(tweak))
+	}
+	c := &ff31{}
+	c.tl = [4]byte{tweak[0], tweak[1], tweak[2], tweak[3] & 0xF0}
+	c.tr = [4]byte{tweak[4], tweak[5], tweak[6], tweak[3] << 4}
+
+	if err := c.setKey(key); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// setKey sets the AES key, which FF3-1 uses with its bytes reversed.
+func (c *ff31) setKey(key []byte) error {
+	reversed := make([]byte, len(key))
+	for i, b := range key {
+		reversed[len(key)-1-i] = b
+	}
+	block, err := aes.NewCipher(reversed)
+	if err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
+	c.block = block
+	return nil
+}
+
+// round returns the output of the round function for round i, applied to
+// the half of the numerals b.
+func (c *ff31) round(i int, w [4]byte, b []byte) *big.Int {
+	var p [16]byte
+	copy(p[:4], w[:])
+	p[3] ^= byte(i)
+	num(b).FillBytes(p[4:])
+
+	// The block is encrypted with its bytes reversed
+	reverseBytes(p[:])
+	c.block.Encrypt(p[:], p[:])
+	reverseBytes(p[:])
+	return new(big.Int).SetBytes(p[:])
+}
+
+// crypt encrypts, or decrypts, a string of decimal numerals in place.
+func (c *ff31) crypt(x []byte, decrypt bool) {
+	n := len(x)
+	u := (n + 1) / 2
+	a := append([]byte(nil), x[:u]...)
+	b := append([]byte(nil), x[u:]...)
+
+	modU := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(u)), nil)
+	modV := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n-u)), nil)
+
+	for r := 0; r < 8; r++ {
+		i := r
+		if decrypt {
+			i = 7 - r
+		}
+		m, mod, w := u, modU, c.tr
+		if i%2 == 1 {
+			m, mod, w = n-u, modV, c.tl
+		}
+
+		if !decrypt {
+			y := c.round(i, w, b)
+			y.Add(y, num(a)).Mod(y, mod)
+			a, b = b, str(y, m)
+		} else {
+			y := c.round(i, w, a)
+			y.Sub(num(b), y).Mod(y, mod)
+			a, b = str(y, m), a
+		}
+	}
+
+	copy(x, a)
+	copy(x[u:], b)
+}
+
+// num returns the number whose decimal numerals, least significant first,
+// are x; FF3-1 reads each half of a value in reverse.
+func num(x []byte) *big.Int {
+	n := new(big.Int)
+	ten := big.NewInt(10)
+	for i := len(x) - 1; i >= 0; i-- {
+		n.Mul(n, ten).Add(n, big.NewInt(int64(x[i])))
+	}
+	return n
+}
+
+// str returns the m decimal numerals of n, least significant first.
+func str(n *big.Int, m int) []byte {
+	x := make([]byte, m)
+	n = new(big.Int).Set(n)
+	ten := big.NewInt(10)
+	digit := new(big.Int)
+	for i := 0; i < m; i++ {
+		n.DivMod(n, ten, digit)
+		x[i] = byte(digit.Int64())
+	}
+	return x
+}
+
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// FPEPatternConfig holds configuration for creating a format preserving
+// encryption generator.
+type FPEPatternConfig struct {
+	Name    string // Pattern name (becomes generator name)
+	KeyEnv  string // Environment variable holding the hexadecimal key
+	KeyFile string // File holding the hexadecimal key
+	Tweak   string // Hexadecimal 56-bit tweak, or empty for zero
+}
+
+// FPEGenerator encrypts the ASCII digits and letters of each value in
+// place with FF3-1, so that each digit is replaced with a digit and each
+// letter with a letter of the same case, leaving other characters as they
+// are. Anyone holding the key can recover the original values, so it
+// pseudonymizes rather than anonymizes.
+//
+// The digits and letters of a value are read together as a number, with
+// a digit in radix 10 or a letter in radix 26 at each position, which is
+// encrypted with cycle walking: its decimal numerals are encrypted until
+// the result is a number of the same range. A value whose digits and
+// letters can take fewer than a million values cannot be encrypted
+// securely, and fails the generator.
+type FPEGenerator struct {
+	BaseGenerator
+	cfg   FPEPatternConfig
+	tweak []byte
+
+	// The cipher is created from the key on first use, so that a key
+	// that is not available only fails the runs using the pattern
+	once    sync.Once
+	cipher  *ff31
+	loadErr error
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewFPEGenerator creates a format preserving encryption generator from an
+// AES key of 16, 24 or 32 bytes and a 7-byte tweak.
+func NewFPEGenerator(name string, key, tweak []byte) (*FPEGenerator, error) {
+	c, err := newFF31(key, tweak)
+	if err != nil {
+		return nil, fmt.Errorf("pattern %s: %w", name, err)
+	}
+	g := &FPEGenerator{
+		BaseGenerator: BaseGenerator{name: name},
+		cipher:        c,
+	}
+	g.once.Do(func() {}) // The cipher is loaded
+	return g, nil
+}
+
+// newFPEPatternGenerator creates a format preserving encryption generator
+// that reads its key when it is first used.
+func newFPEPatternGenerator(cfg FPEPatternConfig,
+	tweak []byte) *FPEGenerator {
+	return &FPEGenerator{
+		BaseGenerator: BaseGenerator{name: cfg.Name},
+		cfg:           cfg,
+		tweak:         tweak,
+	}
+}
+
+// load creates the cipher from the configured key, once.
+func (g *FPEGenerator) load() error {
+	g.once.Do(func() {
+		key, err := readFPEKey(g.cfg)
+		if err == nil {
+			g.cipher, err = newFF31(key, g.tweak)
+		}
+		if err != nil {
+			g.loadErr = fmt.Errorf("pattern %s: %w", g.name, err)
+		}
+	})
+	return g.loadErr
+}
+
+// Generate returns the value with its digits and letters encrypted. After
+// a failure it returns an empty string, and Err reports the cause.
+func (g *FPEGenerator) Generate(input string) string {
+	out, err := g.transform(input, false)
+	if err != nil {
+		g.mu.Lock()
+		if g.err == nil {
+			g.err = err
+		}
+		g.mu.Unlock()
+		return ""
+	}
+	return out
+}
+
+// Reverse returns the value with its digits and letters decrypted.
+func (g *FPEGenerator) Reverse(value string) (string, error) {
+	return g.transform(value, true)
+}
+
+// transform encrypts or decrypts a value, loading the cipher if it has not
+// been loaded.
+func (g *FPEGenerator) transform(value string, decrypt bool) (string,
+	error) {
+
+	if err := g.load(); err != nil {
+		return "", err
+	}
+	out, err := g.crypt(value, decrypt)
+	if err != nil {
+		return "", fmt.Errorf("pattern %s: %w", g.name, err)
+	}
+	return out, nil
+}
+
+// Err returns the first error encrypting a value, such as a missing key or
+// a value too short to encrypt.
+func (g *FPEGenerator) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// fpeRadix returns the radix of a character in the number encrypted, or
+// zero if it is left as it is.
+func fpeRadix(r byte) int64 {
+	switch {
+	case r >= '0' && r <= '9':
+		return 10
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		return 26
+	}
+	return 0
+}
+
+// crypt encrypts or decrypts the digits and letters of a value. Values
+// too long to encrypt as one number are encrypted in parts of about equal
+// size, each with its own tweak.
+func (g *FPEGenerator) crypt(value string, decrypt bool) (string, error) {
+	out := []byte(value)
+
+	var positions []int
+	var digits float64 // Decimal digits needed for the domain
+	for i := 0; i < len(out); i++ {
+		if radix := fpeRadix(out[i]); radix > 0 {
+			positions = append(positions, i)
+			digits += math.Log10(float64(radix))
+		}
+	}
+	if digits < math.Log10(fpeMinDomain) {
+		return "", fmt.Errorf("value has too few digits and letters to " +
+			"encrypt; a million possible values are needed")
+	}
+
+	// Leave room for rounding up the numerals of each part
+	parts := int(math.Ceil(digits / (fpeMaxDigits - 2)))
+	var start int
+	var sum float64
+	for part := 0; part < parts; part++ {
+		end := start
+		target := digits * float64(part+1) / float64(parts)
+		for end < len(positions) && (part == parts-1 ||
+			sum+math.Log10(float64(fpeRadix(out[positions[end]]))) <= target) {
+			sum += math.Log10(float64(fpeRadix(out[positions[end]])))
+			end++
+		}
+		if err := g.cryptPart(out, positions[start:end], part,
+			decrypt); err != nil {
+			return "", err
+		}
+		start = end
+	}
+	return string(out), nil
+}
+
+// cryptPart encrypts or decrypts the characters of a value at the given
+// positions as a number of mixed radix.
+func (g *FPEGenerator) cryptPart(out []byte, positions []int, part int,
+	decrypt bool) error {
+
+	// Read the number, and the size of its domain
+	n := new(big.Int)
+	domain := big.NewInt(1)
+	for _, pos := range positions {
+		radix := big.NewInt(fpeRadix(out[pos]))
+		n.Mul(n, radix).Add(n, big.NewInt(fpeValue(out[pos])))
+		domain.Mul(domain, radix)
+	}
+	if domain.Cmp(big.NewInt(fpeMinDomain)) < 0 {
+		return fmt.Errorf("value has too few digits and letters to encrypt; " +
+			"a million possible values are needed")
+	}
+
+	// Numbers in the domain are encrypted as decimal numerals, repeating
+	// until the result falls in the domain too
+	width := len(new(big.Int).Sub(domain, big.NewInt(1)).String())
+	c := g.cipher
+	if part > 0 {
+		partCipher := *g.cipher
+		partCipher.tr[0] ^= byte(part)
+		c = &partCipher
+	}
+	for {
+		numerals := str(n, width)
+		reverseBytes(numerals)
+		c.crypt(numerals, decrypt)
+		reverseBytes(numerals)
+		n = num(numerals)
+		if n.Cmp(domain) < 0 {
+			break
+		}
+	}
+
+	// Write the number back, keeping the case of each letter
+	radixValue := new(big.Int)
+	for i := len(positions) - 1; i >= 0; i-- {
+		pos := positions[i]
+		radix := big.NewInt(fpeRadix(out[pos]))
+		n.DivMod(n, radix, radixValue)
+		out[pos] = fpeChar(out[pos], radixValue.Int64())
+	}
+	return nil
+}
+
+// fpeValue returns the value of a digit or letter in its radix.
+func fpeValue(r byte) int64 {
+	switch {
+	case r >= '0' && r <= '9':
+		return int64(r - '0')
+	case r >= 'a' && r <= 'z':
+		return int64(r - 'a')
+	}
+	return int64(r - 'A')
+}
+
+// fpeChar returns the digit or letter, of the same kind and case as r, with
+// the given value.
+func fpeChar(r byte, value int64) byte {
+	switch {
+	case r >= '0' && r <= '9':
+		return '0' + byte(value)
+	case r >= 'a' && r <= 'z':
+		return 'a' + byte(value)
+	}
+	return 'A' + byte(value)
+}
+
+// readFPEKey returns the hexadecimal key held by an environment variable
+// or a file.
+func readFPEKey(cfg FPEPatternConfig) ([]byte, error) {
+	encoded, err := sealed.ReadSecret(cfg.KeyEnv, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("key must be hexadecimal: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,186 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cryptDigits encrypts or decrypts a string of decimal digits with c.
+func cryptDigits(c *ff31, digits string, decrypt bool) string {
+	x := []byte(digits)
+	for i := range x {
+		x[i] -= '0'
+	}
+	c.crypt(x, decrypt)
+	for i := range x {
+		x[i] += '0'
+	}
+	return string(x)
+}
+
+// TestFF31 tests the cipher against published test vectors
+func TestFF31(t *testing.T) {
+	decode := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	// NIST's FF3 sample 1, with a 64-bit tweak split into its halves
+	ff3 := &ff31{}
+	tweak := decode("D8E7920AFA330A73")
+	copy(ff3.tl[:], tweak[:4])
+	copy(ff3.tr[:], tweak[4:])
+	if err := ff3.setKey(decode("EF4359D8D580AA4F7F036D6F04FC6A94")); err != nil {
+		t.Fatal(err)
+	}
+	if got := cryptDigits(ff3, "890121234567890000", false); got != "750918814058654607" {
+		t.Errorf("FF3 encrypt = %s", got)
+	}
+	if got := cryptDigits(ff3, "750918814058654607", true); got != "890121234567890000" {
+		t.Errorf("FF3 decrypt = %s", got)
+	}
+
+	// FF3-1, with a 56-bit tweak
+	c, err := newFF31(decode("2DE79D232DF5585D68CE47882AE256D6"),
+		decode("CBD09280979564"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cryptDigits(c, "3992520240", false); got != "8901801106" {
+		t.Errorf("FF3-1 encrypt = %s", got)
+	}
+	if got := cryptDigits(c, "8901801106", true); got != "3992520240" {
+		t.Errorf("FF3-1 decrypt = %s", got)
+	}
+
+	if _, err := newFF31(make([]byte, 16), make([]byte, 8)); err == nil {
+		t.Error("expected an error for a 64-bit tweak")
+	}
+	if _, err := newFF31(make([]byte, 15), make([]byte, 7)); err == nil {
+		t.Error("expected an error for a 15-byte key")
+	}
+}
+
+// TestFPEGenerator tests encrypting values in place and reversing them
+func TestFPEGenerator(t *testing.T) {
+	key, _ := hex.DecodeString("2DE79D232DF5585D68CE47882AE256D6")
+	g, err := NewFPEGenerator("ACCOUNT", key, make([]byte, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []string{
+		"4111-1111-1111-1111",
+		"AB123456",
+		"john.smith@example.com",
+		"Élodie 12345",
+		strings.Repeat("0123456789", 12) + "-" + strings.Repeat("abc", 30),
+	}
+	for _, input := range inputs {
+		out := g.Generate(input)
+		if out == input || len(out) != len(input) {
+			t.Errorf("%q: unexpected output %q", input, out)
+			continue
+		}
+
+		// Each character keeps its kind and case
+		for i := 0; i < len(input); i++ {
+			in, o := input[i], out[i]
+			if fpeRadix(in) != fpeRadix(o) ||
+				(in >= 'a' && in <= 'z') != (o >= 'a' && o <= 'z') ||
+				(fpeRadix(in) == 0 && in != o) {
+				t.Errorf("%q: character %d changed kind in %q", input, i, out)
+				break
+			}
+		}
+
+		if again := g.Generate(input); again != out {
+			t.Errorf("%q: encrypted to %q and %q", input, out, again)
+		}
+		if back, err := g.Reverse(out); err != nil || back != input {
+			t.Errorf("%q: reversed %q to %q, %v", input, out, back, err)
+		}
+	}
+	if err := g.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Another key encrypts differently
+	other, _ := NewFPEGenerator("ACCOUNT", make([]byte, 16), make([]byte, 7))
+	if other.Generate(inputs[0]) == g.Generate(inputs[0]) {
+		t.Error("expected different keys to encrypt differently")
+	}
+
+	// Five digits have too few values to encrypt securely
+	if out := g.Generate("12345"); out != "" || g.Err() == nil {
+		t.Errorf("expected a failure for a short value, got %q", out)
+	}
+}
+
+// TestRegisterFPEPattern tests reading FPE keys from the environment and
+// files
+func TestRegisterFPEPattern(t *testing.T) {
+	m := NewManager()
+
+	t.Setenv("TEST_FPE_KEY", "2DE79D232DF5585D68CE47882AE256D6")
+	if err := m.RegisterFPEPattern(FPEPatternConfig{Name: "FPE_ENV",
+		KeyEnv: "TEST_FPE_KEY", Tweak: "CBD09280979564"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g, ok := m.Get("FPE_ENV")
+	if !ok {
+		t.Fatal("pattern not registered")
+	}
+	if got := g.Generate("3992520240"); got != "8901801106" {
+		t.Errorf("unexpected encryption: %s", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "fpe.key")
+	if err := os.WriteFile(path,
+		[]byte("2DE79D232DF5585D68CE47882AE256D6\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterFPEPattern(FPEPatternConfig{Name: "FPE_FILE",
+		KeyFile: path}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for name, cfg := range map[string]FPEPatternConfig{
+		"no key":      {Name: "BAD"},
+		"bad tweak":   {Name: "BAD", KeyEnv: "TEST_FPE_KEY", Tweak: "xyz"},
+		"short tweak": {Name: "BAD", KeyEnv: "TEST_FPE_KEY", Tweak: "CBD0"},
+	} {
+		if err := m.RegisterFPEPattern(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// A missing key fails the pattern only when it is used
+	if err := m.RegisterFPEPattern(FPEPatternConfig{Name: "FPE_UNSET",
+		KeyEnv: "TEST_FPE_UNSET"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.Err(); err != nil {
+		t.Fatalf("unexpected error before use: %v", err)
+	}
+	g, _ = m.Get("FPE_UNSET")
+	if out := g.Generate("3992520240"); out != "" || m.Err() == nil {
+		t.Errorf("expected a failure without the key, got %q", out)
+	}
+}
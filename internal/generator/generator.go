@@ -99,6 +99,15 @@ func randomString(choices []string) string {
 	return choices[randomInt(len(choices))]
 }
 
+// Shuffle randomly permutes n elements with swap, drawing from the same
+// secure source as generated values, so that the permutation cannot be
+// predicted and undone.
+func Shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, randomInt(i+1))
+	}
+}
+
 // generateDigits generates a string of n random digits.
 func generateDigits(n int) string {
 	result := make([]byte, n)
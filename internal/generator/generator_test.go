@@ -12,6 +12,7 @@ package generator
 
 import (
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -53,20 +54,33 @@ func TestManager(t *testing.T) {
 			"IE_ADDRESS", "IN_ADDRESS", "IT_ADDRESS", "JP_ADDRESS",
 			"KR_ADDRESS", "MX_ADDRESS", "NO_ADDRESS", "NZ_ADDRESS",
 			"PK_ADDRESS", "SE_ADDRESS", "SG_ADDRESS",
+			// Country-specific email generators
+			"AU_EMAIL", "DE_EMAIL", "JP_EMAIL", "UK_EMAIL", "US_EMAIL",
 			// Financial generators
 			"CREDIT_CARD", "CREDIT_CARD_EXPIRY", "CREDIT_CARD_CVV",
+			"IBAN", "BIC", "BTC_ADDRESS", "ETH_ADDRESS",
 			// ID number generators
 			"US_SSN", "UK_NI", "UK_NHS", "PASSPORT",
 			"AU_TFN", "CA_SIN", "DE_STEUERID", "ES_NIF", "FI_HETU",
 			"FR_NIR", "IE_PPS", "IN_AADHAAR", "IN_PAN", "IT_CF",
 			"JP_MYNUMBER", "KR_RRN", "MX_CURP", "NO_FNR", "NZ_IRD",
 			"PK_CNIC", "SE_PNR", "SG_NRIC", "US_SSN",
+			// Company identifier generators
+			"EU_VAT", "US_EIN", "UK_CRN", "DE_HRB",
+			// Vehicle generators
+			"VIN", "US_LICENSE_PLATE", "UK_LICENSE_PLATE", "DE_LICENSE_PLATE",
+			// Medical generators
+			"MRN", "US_NPI", "US_DEA", "ICD10",
 			// Date generators
 			"DOB", "DOB_OVER_13", "DOB_OVER_16", "DOB_OVER_18", "DOB_OVER_21",
+			"DATE_SHIFT_CONSISTENT",
 			// Text generators
-			"LOREMIPSUM",
+			"LOREMIPSUM", "TEXT_SCRUB",
 			// Network generators
-			"IPV4_ADDRESS", "IPV6_ADDRESS", "HOSTNAME",
+			"IPV4_ADDRESS", "IPV6_ADDRESS", "HOSTNAME", "URL",
+			"USER_AGENT",
+			// Account generators
+			"USERNAME", "SOCIAL_HANDLE", "TWITTER_HANDLE", "GITHUB_HANDLE",
 		}
 
 		for _, name := range coreGenerators {
@@ -90,6 +104,30 @@ func TestManager(t *testing.T) {
 	})
 }
 
+// TestShuffle tests that shuffling permutes the elements
+func TestShuffle(t *testing.T) {
+	values := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	moved := false
+	for range 10 {
+		Shuffle(len(values), func(i, j int) {
+			values[i], values[j] = values[j], values[i]
+		})
+		moved = moved || !slices.IsSorted(values)
+
+		sorted := slices.Sorted(slices.Values(values))
+		if !slices.Equal(sorted, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) {
+			t.Fatalf("shuffle lost or repeated elements: %v", values)
+		}
+	}
+	if !moved {
+		t.Error("expected the elements to be permuted")
+	}
+
+	// Nothing to swap in an empty or single element
+	Shuffle(0, func(i, j int) { t.Fatal("unexpected swap") })
+	Shuffle(1, func(i, j int) { t.Fatal("unexpected swap") })
+}
+
 // TestUSPhoneGenerator tests US phone number generation
 func TestUSPhoneGenerator(t *testing.T) {
 	g := NewUSPhoneGenerator()
@@ -334,6 +372,43 @@ func TestEmailGenerator(t *testing.T) {
 	}
 }
 
+// TestCountryEmailGenerator tests country-specific email generation
+func TestCountryEmailGenerator(t *testing.T) {
+	m := NewManager()
+	for country, suffix := range map[string]string{
+		"DE": ".de", "UK": ".co.uk", "JP": ".jp", "MX": ".mx",
+	} {
+		g, ok := m.Get(country + "_EMAIL")
+		if !ok {
+			t.Fatalf("expected a %s_EMAIL generator", country)
+		}
+		result := g.Generate("test@example.com")
+		if !regexp.MustCompile(`^[a-z0-9._]+@[a-z.]+$`).MatchString(result) {
+			t.Errorf("%s: unexpected email %q", country, result)
+		}
+		if !strings.HasSuffix(result, suffix) &&
+			!strings.HasSuffix(result, ".com"+suffix) {
+			t.Errorf("%s: expected a %s domain, got %q", country, suffix,
+				result)
+		}
+	}
+
+	// Accented letters are kept in their unaccented forms
+	if got := emailLocal("Jörg-Müller"); got != "jorgmuller" {
+		t.Errorf("got %q, want %q", got, "jorgmuller")
+	}
+
+	// Names without an ASCII form give random local parts
+	seen := make(map[string]bool)
+	for range 20 {
+		email := emailAddress("", "", "example.jp", "input")
+		seen[email[:strings.IndexByte(email, '@')]] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected random local parts, got %v", seen)
+	}
+}
+
 // TestCreditCardGenerator tests credit card generation
 func TestCreditCardGenerator(t *testing.T) {
 	g := NewCreditCardGenerator()
@@ -1047,6 +1122,18 @@ func TestManagerRegisterFormatPattern(t *testing.T) {
 	if !matched2 {
 		t.Errorf("expected ID-NNNN-XXX format, got %s", result2)
 	}
+
+	// Built-in and format patterns are pure, unless replaced
+	if !m.IsPure("EMAIL") || !m.IsPure("CUSTOM_ID") {
+		t.Error("expected built-in and format patterns to be pure")
+	}
+	if err := m.RegisterRedactPattern(RedactPatternConfig{
+		Name: "CUSTOM_ID", KeepLast: 4}); err != nil {
+		t.Fatalf("failed to register redact pattern: %v", err)
+	}
+	if m.IsPure("CUSTOM_ID") || m.IsPure("UNKNOWN") {
+		t.Error("expected replaced and unknown patterns not to be pure")
+	}
 }
 
 // TestIPv4Generator tests IPv4 address generation
@@ -1310,3 +1397,46 @@ func TestWorldwidePostcodeGenerator(t *testing.T) {
 		}
 	})
 }
+
+// TestDescribe tests describing generators
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		gen      Generator
+		category string
+	}{
+		{NewUSPhoneGenerator(), CategoryPhone},
+		{NewIPv4Generator(), CategoryNetwork},
+		{NewEmailGenerator(data.Load()), CategoryEmail},
+		{NewSSNGenerator(), CategoryIdentifier},
+		{NewBTCAddressGenerator(), CategoryPayment},
+		{NewUKLicensePlateGenerator(), CategoryIdentifier},
+		{NewURLGenerator(), CategoryNetwork},
+		{NewGitHubHandleGenerator(data.Load()), CategoryName},
+		{NewFormatGenerator("ORDER_REF",
+			FormatConfig{Format: "ORD-####", Type: FormatTypeMask}), CategoryFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.gen.Name(), func(t *testing.T) {
+			info := Describe(tt.gen)
+			if info.Name != tt.gen.Name() {
+				t.Errorf("expected name %s, got %s", tt.gen.Name(), info.Name)
+			}
+			if info.Category != tt.category {
+				t.Errorf("expected category %s, got %s", tt.category,
+					info.Category)
+			}
+		})
+	}
+
+	info := Describe(NewFormatGenerator("ORDER_REF",
+		FormatConfig{Format: "ORD-####", Type: FormatTypeMask}))
+	if !strings.Contains(info.Description, "ORD-####") {
+		t.Errorf("expected description to include the mask, got %q",
+			info.Description)
+	}
+
+	if c := categoryOf("CUSTOM_THING"); c != CategoryOther {
+		t.Errorf("expected category %s, got %s", CategoryOther, c)
+	}
+}
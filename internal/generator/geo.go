@@ -0,0 +1,326 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Values for GeoPatternConfig.Type.
+const (
+	GeoTypeLatitude  = "latitude"  // A latitude in degrees
+	GeoTypeLongitude = "longitude" // A longitude in degrees
+	GeoTypePoint     = "point"     // A pair of coordinates
+	GeoTypeGeohash   = "geohash"   // A geohash
+)
+
+// metersPerDegree is the length of a degree of latitude, and of longitude
+// at the equator.
+const metersPerDegree = 111_320
+
+// geoDecimals is the number of decimal places of generated coordinates
+// when the input has none to follow; 6 places is about 10 cm.
+const geoDecimals = 6
+
+// GeoPatternConfig holds configuration for creating a geolocation
+// generator.
+type GeoPatternConfig struct {
+	Name string // Pattern name (becomes generator name)
+	Type string // GeoTypePoint (the default), or another GeoType
+
+	// BoundingBox is the area values are generated in and kept within, as
+	// the minimum latitude, minimum longitude, maximum latitude and
+	// maximum longitude; empty for the whole Earth.
+	BoundingBox []float64
+
+	// JitterMeters moves each value by up to this distance from the
+	// original, rather than generating a new one; zero generates values.
+	JitterMeters float64
+}
+
+// geoNumberPattern matches the coordinates of a value.
+var geoNumberPattern = regexp.MustCompile(`[-+]?\d+(\.\d+)?([eE][-+]?\d+)?`)
+
+// geoSRIDPattern matches the SRID prefix of a PostGIS EWKT value.
+var geoSRIDPattern = regexp.MustCompile(`^(?i)SRID=\d+;`)
+
+// GeoGenerator anonymizes geographic coordinates, in degrees of WGS 84
+// (SRID 4326): latitudes, longitudes, points, and geohashes. It either
+// generates new coordinates within a bounding box, or anywhere on Earth,
+// or moves the original ones by a random distance up to a radius,
+// keeping them within the bounding box if one is set.
+type GeoGenerator struct {
+	BaseGenerator
+	cfg                            GeoPatternConfig
+	minLat, minLon, maxLat, maxLon float64
+}
+
+// NewGeoGenerator creates a geolocation generator.
+func NewGeoGenerator(cfg GeoPatternConfig) (*GeoGenerator, error) {
+	if cfg.Type == "" {
+		cfg.Type = GeoTypePoint
+	}
+	switch cfg.Type {
+	case GeoTypeLatitude, GeoTypeLongitude, GeoTypePoint, GeoTypeGeohash:
+	default:
+		return nil, fmt.Errorf("pattern %s: geo type must be '%s', '%s', "+
+			"'%s' or '%s', got %q", cfg.Name, GeoTypeLatitude,
+			GeoTypeLongitude, GeoTypePoint, GeoTypeGeohash, cfg.Type)
+	}
+	if cfg.JitterMeters < 0 {
+		return nil, fmt.Errorf("pattern %s: jitter_meters cannot be negative",
+			cfg.Name)
+	}
+
+	g := &GeoGenerator{
+		BaseGenerator: BaseGenerator{name: cfg.Name},
+		cfg:           cfg,
+		minLat:        -90, minLon: -180, maxLat: 90, maxLon: 180,
+	}
+	if len(cfg.BoundingBox) > 0 {
+		box := cfg.BoundingBox
+		if len(box) != 4 || box[0] < -90 || box[2] > 90 || box[1] < -180 ||
+			box[3] > 180 || box[0] > box[2] || box[1] > box[3] {
+			return nil, fmt.Errorf("pattern %s: bounding_box must be the "+
+				"minimum latitude, minimum longitude, maximum latitude and "+
+				"maximum longitude", cfg.Name)
+		}
+		g.minLat, g.minLon, g.maxLat, g.maxLon = box[0], box[1], box[2], box[3]
+	}
+	return g, nil
+}
+
+// Generate returns anonymized coordinates in the format of the input. A
+// latitude or longitude keeps the input's decimal places. A point keeps
+// the input's text around its coordinates, such as "51.5074, -0.1278",
+// the PostgreSQL point "(-0.1278,51.5074)", or the PostGIS value
+// "SRID=4326;POINT(-0.1278 51.5074)"; points written with POINT or in
+// parentheses give the longitude first, and others the latitude. A
+// geohash keeps the input's precision. Input that cannot be read is
+// replaced with a value in the default format.
+func (g *GeoGenerator) Generate(input string) string {
+	trimmed := strings.TrimSpace(input)
+
+	switch g.cfg.Type {
+	case GeoTypeLatitude, GeoTypeLongitude:
+		value, err := strconv.ParseFloat(trimmed, 64)
+		lat, lon := g.move(value, value, err == nil)
+		if g.cfg.Type == GeoTypeLatitude {
+			return formatCoordinate(lat, decimalsOf(trimmed))
+		}
+		return formatCoordinate(lon, decimalsOf(trimmed))
+	case GeoTypeGeohash:
+		precision := len(trimmed)
+		lat, lon, ok := decodeGeohash(trimmed)
+		if !ok {
+			precision = 9
+		}
+		lat, lon = g.move(lat, lon, ok)
+		return encodeGeohash(lat, lon, precision)
+	}
+
+	prefix := geoSRIDPattern.FindString(trimmed)
+	rest := trimmed[len(prefix):]
+	loc := geoNumberPattern.FindAllStringIndex(rest, 2)
+	if len(loc) < 2 {
+		lat, lon := g.move(0, 0, false)
+		return formatCoordinate(lat, geoDecimals) + ", " +
+			formatCoordinate(lon, geoDecimals)
+	}
+
+	first, second := rest[loc[0][0]:loc[0][1]], rest[loc[1][0]:loc[1][1]]
+	lonFirst := strings.HasPrefix(rest, "(") ||
+		strings.Contains(strings.ToUpper(rest), "POINT")
+	latText, lonText := first, second
+	if lonFirst {
+		latText, lonText = second, first
+	}
+	lat, errLat := strconv.ParseFloat(latText, 64)
+	lon, errLon := strconv.ParseFloat(lonText, 64)
+	lat, lon = g.move(lat, lon, errLat == nil && errLon == nil &&
+		math.Abs(lat) <= 90 && math.Abs(lon) <= 180)
+
+	latOut := formatCoordinate(lat, decimalsOf(latText))
+	lonOut := formatCoordinate(lon, decimalsOf(lonText))
+	if lonFirst {
+		latOut, lonOut = lonOut, latOut
+	}
+	return prefix + rest[:loc[0][0]] + latOut + rest[loc[0][1]:loc[1][0]] +
+		lonOut + rest[loc[1][1]:]
+}
+
+// move returns coordinates moved by up to the jitter radius from the
+// original ones, if they are valid and a radius is set, and otherwise new
+// ones, within the bounding box in either case. Only the latitude or
+// longitude is used by generators of one of them, so a latitude is moved
+// north or south, and a longitude east or west by up to the radius at the
+// equator.
+func (g *GeoGenerator) move(lat, lon float64, valid bool) (float64, float64) {
+	if !valid || g.cfg.JitterMeters == 0 {
+		return g.random()
+	}
+
+	switch g.cfg.Type {
+	case GeoTypeLatitude:
+		lat += (2*randomFloat() - 1) * g.cfg.JitterMeters / metersPerDegree
+	case GeoTypeLongitude:
+		lon += (2*randomFloat() - 1) * g.cfg.JitterMeters / metersPerDegree
+	default:
+		// Distances spread evenly over the area of the circle
+		distance := g.cfg.JitterMeters * math.Sqrt(randomFloat())
+		bearing := 2 * math.Pi * randomFloat()
+		lat += distance * math.Cos(bearing) / metersPerDegree
+		scale := math.Max(math.Cos(lat*math.Pi/180), 0.01)
+		lon += distance * math.Sin(bearing) / (metersPerDegree * scale)
+	}
+
+	if lon > 180 {
+		lon -= 360
+	} else if lon < -180 {
+		lon += 360
+	}
+	return min(max(lat, g.minLat), g.maxLat), min(max(lon, g.minLon), g.maxLon)
+}
+
+// random returns coordinates within the bounding box, evenly spread over
+// its area rather than over its degrees, so that points are no denser
+// towards the poles.
+func (g *GeoGenerator) random() (float64, float64) {
+	lo := math.Sin(g.minLat * math.Pi / 180)
+	hi := math.Sin(g.maxLat * math.Pi / 180)
+	lat := math.Asin(lo+(hi-lo)*randomFloat()) * 180 / math.Pi
+	lon := g.minLon + (g.maxLon-g.minLon)*randomFloat()
+	return lat, lon
+}
+
+// Description describes the values a geolocation generator produces.
+func (g *GeoGenerator) Description() string {
+	var desc string
+	switch g.cfg.Type {
+	case GeoTypeLatitude:
+		desc = "Latitudes"
+	case GeoTypeLongitude:
+		desc = "Longitudes"
+	case GeoTypeGeohash:
+		desc = "Geohashes"
+	default:
+		desc = "Points"
+	}
+	if g.cfg.JitterMeters > 0 {
+		desc += fmt.Sprintf(" moved by up to %g meters", g.cfg.JitterMeters)
+	}
+	if len(g.cfg.BoundingBox) > 0 {
+		desc += fmt.Sprintf(", within %g,%g to %g,%g", g.minLat, g.minLon,
+			g.maxLat, g.maxLon)
+	}
+	return desc
+}
+
+// Category returns the category of geolocation patterns.
+func (g *GeoGenerator) Category() string {
+	return CategoryAddress
+}
+
+// randomFloat returns a random number in [0, 1).
+func randomFloat() float64 {
+	return float64(randomInt(1<<53)) / (1 << 53)
+}
+
+// decimalsOf returns the number of decimal places of a number, or
+// geoDecimals if it has none.
+func decimalsOf(number string) int {
+	i := strings.IndexByte(number, '.')
+	if i < 0 {
+		return geoDecimals
+	}
+	n := 0
+	for _, c := range number[i+1:] {
+		if c < '0' || c > '9' {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// formatCoordinate formats a coordinate with a number of decimal places.
+func formatCoordinate(value float64, decimals int) string {
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
+
+// geohashAlphabet is the base 32 alphabet of geohashes.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// encodeGeohash returns the geohash of coordinates, of a number of
+// characters: bits that alternately halve the range of longitudes and of
+// latitudes the point is in, five to a character.
+func encodeGeohash(lat, lon float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+
+	var sb strings.Builder
+	even := true
+	bit, ch := 0, 0
+	for sb.Len() < precision {
+		r, v := &latRange, lat
+		if even {
+			r, v = &lonRange, lon
+		}
+		mid := (r[0] + r[1]) / 2
+		ch <<= 1
+		if v >= mid {
+			ch |= 1
+			r[0] = mid
+		} else {
+			r[1] = mid
+		}
+		even = !even
+		if bit++; bit == 5 {
+			sb.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+// decodeGeohash returns the coordinates of the center of the area of a
+// geohash, and false if it is not a geohash.
+func decodeGeohash(hash string) (float64, float64, bool) {
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+
+	even := true
+	for i := 0; i < len(hash); i++ {
+		d := strings.IndexByte(geohashAlphabet, hash[i]|0x20)
+		if d < 0 {
+			return 0, 0, false
+		}
+		for mask := 16; mask > 0; mask >>= 1 {
+			r := &latRange
+			if even {
+				r = &lonRange
+			}
+			mid := (r[0] + r[1]) / 2
+			if d&mask != 0 {
+				r[0] = mid
+			} else {
+				r[1] = mid
+			}
+			even = !even
+		}
+	}
+	return (latRange[0] + latRange[1]) / 2, (lonRange[0] + lonRange[1]) / 2,
+		hash != ""
+}
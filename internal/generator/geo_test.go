@@ -0,0 +1,141 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGeohash(t *testing.T) {
+	// Example from the geohash specification
+	if got := encodeGeohash(57.64911, 10.40744, 11); got != "u4pruydqqvj" {
+		t.Errorf("expected u4pruydqqvj, got %s", got)
+	}
+	lat, lon, ok := decodeGeohash("u4pruydqqvj")
+	if !ok || math.Abs(lat-57.64911) > 1e-4 || math.Abs(lon-10.40744) > 1e-4 {
+		t.Errorf("unexpected coordinates %f, %f", lat, lon)
+	}
+	if _, _, ok := decodeGeohash("u4a"); ok {
+		t.Error("expected a geohash with an 'a' to be invalid")
+	}
+}
+
+func TestNewGeoGenerator(t *testing.T) {
+	for name, cfg := range map[string]GeoPatternConfig{
+		"unknown type":     {Type: "altitude"},
+		"negative jitter":  {JitterMeters: -1},
+		"short box":        {BoundingBox: []float64{1, 2}},
+		"inverted box":     {BoundingBox: []float64{10, 0, 5, 1}},
+		"latitude outside": {BoundingBox: []float64{-91, 0, 5, 1}},
+	} {
+		cfg.Name = "LOCATION"
+		if _, err := NewGeoGenerator(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// parsePoint returns the coordinates of a "lat, lon" point.
+func parsePoint(t *testing.T, point string) (float64, float64) {
+	t.Helper()
+	parts := strings.Split(point, ",")
+	if len(parts) != 2 {
+		t.Fatalf("unexpected point %q", point)
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil {
+		t.Fatalf("unexpected point %q", point)
+	}
+	return lat, lon
+}
+
+func TestGeoGenerator(t *testing.T) {
+	t.Run("formats", func(t *testing.T) {
+		g, _ := NewGeoGenerator(GeoPatternConfig{Name: "GEO_POINT"})
+		tests := []struct {
+			input   string
+			pattern string
+		}{
+			{"51.5074, -0.1278", `^-?\d+\.\d{4}, -?\d+\.\d{4}$`},
+			{"(-0.1278,51.5074)", `^\(-?\d+\.\d{4},-?\d+\.\d{4}\)$`},
+			{"SRID=4326;POINT(-0.1278 51.5074)",
+				`^SRID=4326;POINT\(-?\d+\.\d{4} -?\d+\.\d{4}\)$`},
+			{"POINT(-0 51)", `^POINT\(-?\d+\.\d{6} -?\d+\.\d{6}\)$`},
+			{"", `^-?\d+\.\d{6}, -?\d+\.\d{6}$`},
+		}
+		for _, tt := range tests {
+			result := g.Generate(tt.input)
+			if !regexp.MustCompile(tt.pattern).MatchString(result) {
+				t.Errorf("%q: expected %s to match %s", tt.input, result,
+					tt.pattern)
+			}
+		}
+	})
+
+	t.Run("bounding box", func(t *testing.T) {
+		g, _ := NewGeoGenerator(GeoPatternConfig{Name: "UK_POINT",
+			BoundingBox: []float64{49.9, -8.6, 60.9, 1.8}})
+		for range 100 {
+			lat, lon := parsePoint(t, g.Generate("40.7128, -74.0060"))
+			if lat < 49.9 || lat > 60.9 || lon < -8.6 || lon > 1.8 {
+				t.Fatalf("%f, %f is outside the bounding box", lat, lon)
+			}
+		}
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		g, _ := NewGeoGenerator(GeoPatternConfig{Name: "NEARBY",
+			JitterMeters: 1000})
+		for range 100 {
+			lat, lon := parsePoint(t, g.Generate("51.507400, -0.127800"))
+			dLat := (lat - 51.5074) * metersPerDegree
+			dLon := (lon + 0.1278) * metersPerDegree *
+				math.Cos(51.5074*math.Pi/180)
+			// Allow for the rounding of the output to 6 places
+			if d := math.Hypot(dLat, dLon); d > 1001 {
+				t.Fatalf("%f, %f is %.0f meters away", lat, lon, d)
+			}
+		}
+	})
+
+	t.Run("latitude and longitude", func(t *testing.T) {
+		lat, _ := NewGeoGenerator(GeoPatternConfig{Name: "LATITUDE",
+			Type: GeoTypeLatitude, JitterMeters: 100})
+		lon, _ := NewGeoGenerator(GeoPatternConfig{Name: "LONGITUDE",
+			Type: GeoTypeLongitude, BoundingBox: []float64{0, 10, 1, 20}})
+		for range 100 {
+			v, err := strconv.ParseFloat(lat.Generate("-33.8688"), 64)
+			if err != nil || math.Abs(v+33.8688) > 0.001 {
+				t.Fatalf("unexpected latitude %f", v)
+			}
+			v, err = strconv.ParseFloat(lon.Generate("151.2093"), 64)
+			if err != nil || v < 10 || v > 20 {
+				t.Fatalf("unexpected longitude %f", v)
+			}
+		}
+	})
+
+	t.Run("geohash", func(t *testing.T) {
+		g, _ := NewGeoGenerator(GeoPatternConfig{Name: "GEOHASH",
+			Type: GeoTypeGeohash, JitterMeters: 5000})
+		for range 20 {
+			result := g.Generate("gcpvj0duq")
+			if len(result) != 9 || !strings.HasPrefix(result, "gc") {
+				t.Fatalf("unexpected geohash %s", result)
+			}
+		}
+	})
+}
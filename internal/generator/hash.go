@@ -0,0 +1,171 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/sealed"
+)
+
+// Hash pattern output lengths, in hexadecimal digits.
+const (
+	DefaultHashLength = 16
+	MaxHashLength     = 2 * sha256.Size
+)
+
+// HashPatternConfig holds configuration for creating a keyed hash
+// generator.
+type HashPatternConfig struct {
+	Name            string // Pattern name (becomes generator name)
+	KeyEnv          string // Environment variable holding the key
+	KeyFile         string // File holding the key
+	Length          int    // Hexadecimal digits kept, or zero for the default
+	Format          string // Mask filled from the hash, instead of hex digits
+	CaseInsensitive bool   // Values differing only in case hash the same
+}
+
+// HashGenerator replaces each value with a one-way pseudonym derived from
+// its HMAC-SHA256 under a secret key: by default the first digits of the
+// hash in hexadecimal, or a mask, such as "CUST-########", whose
+// placeholders are filled from the hash. The same value always gives the
+// same pseudonym under the same key, so pseudonyms stay consistent across
+// tables, runs, and other systems sharing the key, without a dictionary;
+// without the key, they cannot be computed from guessed values.
+type HashGenerator struct {
+	BaseGenerator
+	cfg HashPatternConfig
+
+	// The key is read on first use, so that a key that is not available
+	// only fails the runs using the pattern
+	once    sync.Once
+	key     []byte
+	loadErr error
+
+	mu  sync.Mutex
+	err error
+}
+
+// newHashGenerator creates a keyed hash generator.
+func newHashGenerator(cfg HashPatternConfig) *HashGenerator {
+	if cfg.Length == 0 {
+		cfg.Length = DefaultHashLength
+	}
+	return &HashGenerator{
+		BaseGenerator: BaseGenerator{name: cfg.Name},
+		cfg:           cfg,
+	}
+}
+
+// load reads the key, once.
+func (g *HashGenerator) load() error {
+	g.once.Do(func() {
+		key, err := sealed.ReadSecret(g.cfg.KeyEnv, g.cfg.KeyFile)
+		if err != nil {
+			g.loadErr = fmt.Errorf("pattern %s: %w", g.name, err)
+			return
+		}
+		g.key = []byte(key)
+	})
+	return g.loadErr
+}
+
+// Generate returns the pseudonym of the input. If the key cannot be read
+// it returns an empty string, and Err reports the cause.
+func (g *HashGenerator) Generate(input string) string {
+	if err := g.load(); err != nil {
+		g.mu.Lock()
+		if g.err == nil {
+			g.err = err
+		}
+		g.mu.Unlock()
+		return ""
+	}
+	if g.cfg.CaseInsensitive {
+		input = strings.ToLower(input)
+	}
+
+	s := newHashStream(g.key, input)
+	if g.cfg.Format != "" {
+		return fillMask(g.cfg.Format, s.intn)
+	}
+	return hex.EncodeToString(s.buf)[:g.cfg.Length]
+}
+
+// Err returns the error reading the key, once the generator has been
+// used.
+func (g *HashGenerator) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// Description describes a keyed hash generator.
+func (g *HashGenerator) Description() string {
+	if g.cfg.Format != "" {
+		return fmt.Sprintf("Keyed hashes of values, formatted as %q",
+			g.cfg.Format)
+	}
+	return fmt.Sprintf("Keyed hashes of values, as %d hexadecimal digits",
+		g.cfg.Length)
+}
+
+// Category returns the category of hash patterns.
+func (g *HashGenerator) Category() string {
+	return CategoryHash
+}
+
+// hashStream is a deterministic source of integers drawn from the
+// HMAC-SHA256 of a value, followed by the HMAC of each previous block when
+// more are needed.
+type hashStream struct {
+	mac   hash.Hash
+	block []byte // Current block
+	buf   []byte // Unused bytes of the current block
+}
+
+// newHashStream returns the stream of a value under a key, starting with
+// the value's HMAC.
+func newHashStream(key []byte, value string) *hashStream {
+	s := &hashStream{mac: hmac.New(sha256.New, key)}
+	s.setBlock([]byte(value))
+	return s
+}
+
+// setBlock makes the HMAC of data the current block.
+func (s *hashStream) setBlock(data []byte) {
+	s.mac.Reset()
+	s.mac.Write(data)
+	s.block = s.mac.Sum(nil)
+	s.buf = s.block
+}
+
+// intn returns an integer in [0, n), for n of at most 256, rejecting the
+// bytes that would bias the result.
+func (s *hashStream) intn(n int) int {
+	limit := 256 - 256%n
+	for {
+		if len(s.buf) == 0 {
+			s.setBlock(s.block)
+		}
+		b := int(s.buf[0])
+		s.buf = s.buf[1:]
+		if b < limit {
+			return b % n
+		}
+	}
+}
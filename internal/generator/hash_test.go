@@ -0,0 +1,105 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// TestHashGenerator tests keyed hash pseudonyms
+func TestHashGenerator(t *testing.T) {
+	t.Setenv("TEST_HASH_KEY", "Jefe")
+
+	// The HMAC-SHA256 test vector of RFC 4231, test case 2
+	const input = "what do ya want for nothing?"
+	const mac = "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+
+	t.Run("hex", func(t *testing.T) {
+		g := newHashGenerator(HashPatternConfig{Name: "H",
+			KeyEnv: "TEST_HASH_KEY"})
+		if got := g.Generate(input); got != mac[:DefaultHashLength] {
+			t.Errorf("got %q, want %q", got, mac[:DefaultHashLength])
+		}
+
+		g = newHashGenerator(HashPatternConfig{Name: "H",
+			KeyEnv: "TEST_HASH_KEY", Length: MaxHashLength})
+		if got := g.Generate(input); got != mac {
+			t.Errorf("got %q, want %q", got, mac)
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		// The mask takes more bytes than a single block holds
+		format := "CUST-####-AAAA-xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"
+		g := newHashGenerator(HashPatternConfig{Name: "H",
+			KeyEnv: "TEST_HASH_KEY", Format: format})
+		first := g.Generate("alice@example.com")
+		if !regexp.MustCompile(`^CUST-\d{4}-[A-Z]{4}-[a-z0-9]{40}$`).
+			MatchString(first) {
+			t.Errorf("value %q does not match the format", first)
+		}
+		if g.Generate("alice@example.com") != first {
+			t.Error("expected the same value for the same input")
+		}
+		if g.Generate("bob@example.com") == first {
+			t.Error("expected a different value for a different input")
+		}
+	})
+
+	t.Run("case insensitive", func(t *testing.T) {
+		g := newHashGenerator(HashPatternConfig{Name: "H",
+			KeyEnv: "TEST_HASH_KEY", CaseInsensitive: true})
+		if g.Generate("Alice@Example.com") != g.Generate("alice@example.com") {
+			t.Error("expected values differing in case to hash the same")
+		}
+	})
+
+	t.Run("key file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hash.key")
+		if err := os.WriteFile(path, []byte("Jefe\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		g := newHashGenerator(HashPatternConfig{Name: "H", KeyFile: path})
+		if got := g.Generate(input); got != mac[:DefaultHashLength] {
+			t.Errorf("got %q, want %q", got, mac[:DefaultHashLength])
+		}
+	})
+}
+
+// TestRegisterHashPattern tests registering hash patterns
+func TestRegisterHashPattern(t *testing.T) {
+	m := NewManager()
+
+	for name, cfg := range map[string]HashPatternConfig{
+		"no key":      {Name: "BAD"},
+		"long length": {Name: "BAD", KeyEnv: "TEST_HASH_KEY", Length: 65},
+	} {
+		if err := m.RegisterHashPattern(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// A missing key fails the pattern only when it is used
+	if err := m.RegisterHashPattern(HashPatternConfig{Name: "HASH_UNSET",
+		KeyEnv: "TEST_HASH_UNSET"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.Err(); err != nil {
+		t.Fatalf("unexpected error before use: %v", err)
+	}
+	g, _ := m.Get("HASH_UNSET")
+	if out := g.Generate("alice"); out != "" || m.Err() == nil {
+		t.Errorf("expected a failure without the key, got %q", out)
+	}
+}
@@ -0,0 +1,106 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// HistogramGenerator generates numbers drawn from the distribution of a
+// column's original values, described by its quantiles: the values
+// dividing the original values into buckets of equal numbers of rows.
+// Each value is drawn from a bucket chosen at random, uniformly between
+// its bounds, so that the generated values follow the original
+// distribution, within its range, without repeating the original values
+// row by row.
+type HistogramGenerator struct {
+	BaseGenerator
+	quantiles []float64
+
+	// integer is set for integer columns, whose values are rounded
+	integer bool
+}
+
+// NewHistogramGenerator creates a generator of numbers following the
+// distribution described by ascending quantiles, from the smallest value
+// to the largest.
+func NewHistogramGenerator(name string,
+	quantiles []float64) (*HistogramGenerator, error) {
+
+	if len(quantiles) == 0 {
+		return nil, fmt.Errorf("%s: no values to draw from", name)
+	}
+	if !slices.IsSorted(quantiles) {
+		return nil, fmt.Errorf("%s: quantiles must be in ascending order",
+			name)
+	}
+	for _, q := range quantiles {
+		if math.IsInf(q, 0) || math.IsNaN(q) {
+			return nil, fmt.Errorf("%s: quantiles must be finite", name)
+		}
+	}
+	return &HistogramGenerator{
+		BaseGenerator: BaseGenerator{name: name},
+		quantiles:     quantiles,
+	}, nil
+}
+
+// BindContext returns a generator that rounds the values of an integer
+// column.
+func (g *HistogramGenerator) BindContext(info ColumnInfo) Generator {
+	if _, ok := integerRanges[info.DataType]; !ok {
+		return g
+	}
+	bound := *g
+	bound.integer = true
+	return &bound
+}
+
+// Generate returns a value drawn from the distribution, with the input's
+// decimal places, so that integers stay integers.
+func (g *HistogramGenerator) Generate(input string) string {
+	value := g.draw()
+
+	trimmed := strings.TrimSpace(input)
+	switch {
+	case g.integer:
+		return strconv.FormatFloat(math.Round(value), 'f', 0, 64)
+	case strings.ContainsAny(trimmed, "eE"):
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(value, 'f', decimalPlaces(trimmed), 64)
+}
+
+// draw returns a value from a bucket chosen at random, uniformly between
+// its bounds.
+func (g *HistogramGenerator) draw() float64 {
+	if len(g.quantiles) == 1 {
+		return g.quantiles[0]
+	}
+	i := randomInt(len(g.quantiles) - 1)
+	lo, hi := g.quantiles[i], g.quantiles[i+1]
+	return lo + (hi-lo)*randomFloat()
+}
+
+// Description describes the values a histogram generator produces.
+func (g *HistogramGenerator) Description() string {
+	return fmt.Sprintf("Numbers from %g to %g, following the original "+
+		"distribution", g.quantiles[0], g.quantiles[len(g.quantiles)-1])
+}
+
+// Category returns the category of histogram generators.
+func (g *HistogramGenerator) Category() string {
+	return CategoryOther
+}
@@ -0,0 +1,79 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestHistogramGenerator(t *testing.T) {
+	// Half the values are from 0 to 10, and half from 10 to 1000
+	g, err := NewHistogramGenerator("DISTRIBUTION", []float64{0, 10, 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const n = 10000
+	low := 0
+	for range n {
+		value, err := strconv.ParseFloat(g.Generate("12.50"), 64)
+		if err != nil {
+			t.Fatalf("unexpected value: %v", err)
+		}
+		if value < 0 || value > 1000 {
+			t.Fatalf("expected a value within the range, got %g", value)
+		}
+		if value < 10 {
+			low++
+		}
+	}
+	if low < 4700 || low > 5300 {
+		t.Errorf("expected about half the values below 10, got %d of %d",
+			low, n)
+	}
+
+	re := regexp.MustCompile(`^\d+\.\d{2}$`)
+	if result := g.Generate("12.50"); !re.MatchString(result) {
+		t.Errorf("expected 2 decimal places, got %s", result)
+	}
+	if result := g.Generate("12"); !regexp.MustCompile(`^\d+$`).
+		MatchString(result) {
+		t.Errorf("expected an integer, got %s", result)
+	}
+
+	t.Run("integer columns", func(t *testing.T) {
+		bound := g.BindContext(ColumnInfo{DataType: "integer"})
+		for range 100 {
+			if _, err := strconv.ParseInt(bound.Generate("12.5"), 10,
+				32); err != nil {
+				t.Fatalf("expected an integer: %v", err)
+			}
+		}
+	})
+
+	t.Run("single value", func(t *testing.T) {
+		g, _ := NewHistogramGenerator("DISTRIBUTION", []float64{42})
+		if result := g.Generate("7"); result != "42" {
+			t.Errorf("expected the only value, got %s", result)
+		}
+	})
+
+	for _, quantiles := range [][]float64{nil, {3, 1},
+		{0, math.Inf(1)}} {
+		if _, err := NewHistogramGenerator("DISTRIBUTION",
+			quantiles); err == nil {
+			t.Errorf("expected an error for %v", quantiles)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ibanFormats are the formats of the national part (BBAN) of the IBANs of
+// each country, as in the SWIFT IBAN registry: runs of n digits, a
+// uppercase letters, or c alphanumeric characters.
+var ibanFormats = map[string]string{
+	"AD": "4n4n12c", "AT": "5n11n", "BE": "3n7n2n", "BG": "4a4n2n8c",
+	"CH": "5n12c", "CY": "3n5n16c", "CZ": "4n6n10n", "DE": "8n10n",
+	"DK": "4n9n1n", "EE": "2n2n11n1n", "ES": "4n4n1n1n10n", "FI": "3n11n",
+	"FR": "5n5n11c2n", "GB": "4a6n8n", "GR": "3n4n16c", "HR": "7n10n",
+	"HU": "3n4n1n15n1n", "IE": "4a6n8n", "IS": "4n2n6n10n",
+	"IT": "1a5n5n12c", "LI": "5n12c", "LT": "5n11n", "LU": "3n13c",
+	"LV": "4a13c", "MC": "5n5n11c2n", "MT": "4a5n18c", "NL": "4a10n",
+	"NO": "4n6n1n", "PL": "8n16n", "PT": "4n4n11n2n", "RO": "4a16c",
+	"SE": "3n16n1n", "SI": "5n8n2n", "SK": "4n6n10n", "SM": "1a5n5n12c",
+}
+
+// ibanCountries are the countries of ibanFormats, sorted.
+var ibanCountries = func() []string {
+	countries := make([]string, 0, len(ibanFormats))
+	for country := range ibanFormats {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	return countries
+}()
+
+const (
+	upperLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	upperAlnum   = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// IBANGenerator generates International Bank Account Numbers.
+type IBANGenerator struct {
+	BaseGenerator
+}
+
+// NewIBANGenerator creates a new IBAN generator.
+func NewIBANGenerator() *IBANGenerator {
+	return &IBANGenerator{
+		BaseGenerator: BaseGenerator{name: "IBAN"},
+	}
+}
+
+// Generate produces an IBAN of the same country and length as the input,
+// with valid mod-97 check digits. IBANs of countries without a known
+// format keep the kinds of character of their national part. Input
+// printed in groups of four characters is printed the same way.
+func (g *IBANGenerator) Generate(input string) string {
+	compact := strings.ToUpper(strings.Join(strings.Fields(input), ""))
+
+	var country, bban string
+	if len(compact) >= 2 && isUpperLetters(compact[:2]) {
+		country = compact[:2]
+	}
+	if format, ok := ibanFormats[country]; ok {
+		bban = generateBBAN(format)
+	} else if country != "" && len(compact) > 4 {
+		bban = replaceAlnum(compact[4:])
+	} else {
+		country = randomString(ibanCountries)
+		bban = generateBBAN(ibanFormats[country])
+	}
+
+	iban := country + ibanCheckDigits(country, bban) + bban
+	if strings.Contains(strings.TrimSpace(input), " ") {
+		return groupFours(iban)
+	}
+	return iban
+}
+
+// generateBBAN returns a random national account number of a format.
+// Alphanumeric positions are filled with digits, as most accounts are.
+func generateBBAN(format string) string {
+	var sb strings.Builder
+	n := 0
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c >= '0' && c <= '9' {
+			n = n*10 + int(c-'0')
+			continue
+		}
+		for range n {
+			if c == 'a' {
+				sb.WriteByte(upperLetters[randomInt(len(upperLetters))])
+			} else {
+				sb.WriteByte(randomDigit())
+			}
+		}
+		n = 0
+	}
+	return sb.String()
+}
+
+// ibanCheckDigits returns the check digits of an IBAN: 98 less the
+// remainder, modulo 97, of the number formed by the national part, the
+// country, and 00, with letters taken as 10 to 35.
+func ibanCheckDigits(country, bban string) string {
+	return fmt.Sprintf("%02d", 98-ibanMod97(bban+country+"00"))
+}
+
+// ibanMod97 returns the remainder, modulo 97, of the number formed by an
+// alphanumeric string, with letters taken as 10 to 35.
+func ibanMod97(s string) int {
+	rem := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			rem = (rem*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			rem = (rem*100 + int(c-'A') + 10) % 97
+		}
+	}
+	return rem
+}
+
+// groupFours returns s in groups of four characters separated by spaces.
+func groupFours(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i += 4 {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(s[i:min(i+4, len(s))])
+	}
+	return sb.String()
+}
+
+// replaceAlnum replaces each digit of s with a random digit, and each
+// letter with a random letter.
+func replaceAlnum(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			b[i] = randomDigit()
+		case c >= 'A' && c <= 'Z':
+			b[i] = upperLetters[randomInt(len(upperLetters))]
+		}
+	}
+	return string(b)
+}
+
+// BICGenerator generates BIC (SWIFT) codes.
+type BICGenerator struct {
+	BaseGenerator
+}
+
+// NewBICGenerator creates a new BIC generator.
+func NewBICGenerator() *BICGenerator {
+	return &BICGenerator{
+		BaseGenerator: BaseGenerator{name: "BIC"},
+	}
+}
+
+// Generate produces a BIC of the same country and length as the input: a
+// 4 letter institution code, the country, a 2 character location code,
+// and for 11 character codes a branch code. The primary office branch
+// code XXX is kept.
+func (g *BICGenerator) Generate(input string) string {
+	bic := strings.ToUpper(strings.TrimSpace(input))
+
+	country := ""
+	if len(bic) >= 6 && isUpperLetters(bic[4:6]) {
+		country = bic[4:6]
+	} else {
+		country = randomString(ibanCountries)
+	}
+
+	var sb strings.Builder
+	for range 4 {
+		sb.WriteByte(upperLetters[randomInt(len(upperLetters))])
+	}
+	sb.WriteString(country)
+	sb.WriteByte(upperAlnum[randomInt(len(upperAlnum))])
+	// A location code ending in 0 denotes a test BIC
+	sb.WriteByte(upperAlnum[1+randomInt(len(upperAlnum)-1)])
+
+	if len(bic) == 11 {
+		if strings.HasSuffix(bic, "XXX") {
+			sb.WriteString("XXX")
+		} else {
+			for range 3 {
+				sb.WriteByte(upperAlnum[randomInt(len(upperAlnum))])
+			}
+		}
+	}
+	return sb.String()
+}
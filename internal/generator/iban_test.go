@@ -0,0 +1,108 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// validIBAN checks the mod-97 check digits of a compact IBAN.
+func validIBAN(iban string) bool {
+	return len(iban) > 4 && ibanMod97(iban[4:]+iban[:4]) == 1
+}
+
+func TestIBANMod97(t *testing.T) {
+	// Examples from the SWIFT IBAN registry
+	for _, iban := range []string{"DE89370400440532013000",
+		"GB29NWBK60161331926819", "FR1420041010050500013M02606",
+		"NL91ABNA0417164300"} {
+		if !validIBAN(iban) {
+			t.Errorf("expected %s to be valid", iban)
+		}
+	}
+	if validIBAN("DE88370400440532013000") {
+		t.Error("expected wrong check digits to be invalid")
+	}
+}
+
+func TestIBANGenerator(t *testing.T) {
+	g := NewIBANGenerator()
+
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"DE89370400440532013000", `^DE\d{20}$`},
+		{"GB29 NWBK 6016 1331 9268 19", `^GB\d{2} [A-Z]{4}( \d{4}){3} \d{2}$`},
+		{"FR1420041010050500013M02606", `^FR\d{25}$`},
+		{"nl91abna0417164300", `^NL\d{2}[A-Z]{4}\d{10}$`},
+		// Without a known format, the kinds of character are kept
+		{"XK051212012345678906", `^XK\d{18}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			re := regexp.MustCompile(tt.pattern)
+			for range 50 {
+				result := g.Generate(tt.input)
+				if !re.MatchString(result) {
+					t.Fatalf("expected %s to match %s", result, tt.pattern)
+				}
+				if !validIBAN(strings.ReplaceAll(result, " ", "")) {
+					t.Fatalf("invalid check digits in %s", result)
+				}
+			}
+		})
+	}
+
+	t.Run("without a country", func(t *testing.T) {
+		result := g.Generate("12345")
+		if _, ok := ibanFormats[result[:2]]; !ok || !validIBAN(result) {
+			t.Errorf("unexpected IBAN %s", result)
+		}
+	})
+}
+
+func TestIBANFormatLengths(t *testing.T) {
+	// IBAN lengths from the SWIFT IBAN registry
+	lengths := map[string]int{"AT": 20, "BE": 16, "CH": 21, "DE": 22,
+		"ES": 24, "FR": 27, "GB": 22, "IT": 27, "MT": 31, "NL": 18,
+		"NO": 15, "PL": 28}
+	for country, want := range lengths {
+		if got := 4 + len(generateBBAN(ibanFormats[country])); got != want {
+			t.Errorf("%s: expected length %d, got %d", country, want, got)
+		}
+	}
+}
+
+func TestBICGenerator(t *testing.T) {
+	g := NewBICGenerator()
+
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"DEUTDEFF", `^[A-Z]{4}DE[A-Z0-9][A-Z1-9]$`},
+		{"NWBKGB2LXXX", `^[A-Z]{4}GB[A-Z0-9][A-Z1-9]XXX$`},
+		{"BNPAFRPP123", `^[A-Z]{4}FR[A-Z0-9][A-Z1-9][A-Z0-9]{3}$`},
+		{"", `^[A-Z]{6}[A-Z0-9][A-Z1-9]$`},
+	}
+
+	for _, tt := range tests {
+		result := g.Generate(tt.input)
+		if !regexp.MustCompile(tt.pattern).MatchString(result) {
+			t.Errorf("%q: expected %s to match %s", tt.input, result,
+				tt.pattern)
+		}
+	}
+}
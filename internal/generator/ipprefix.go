@@ -0,0 +1,188 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Default numbers of leading bits kept by IP prefix patterns.
+const (
+	DefaultIPv4Prefix = 16 // A /16, such as 10.1.0.0/16
+	DefaultIPv6Prefix = 48 // A /48, the usual size of a site's allocation
+)
+
+// IPPatternConfig holds configuration for creating a generator of IP
+// addresses that keeps their network prefix.
+type IPPatternConfig struct {
+	Name       string // Pattern name (becomes generator name)
+	IPv4Prefix int    // Leading bits of IPv4 addresses kept; 0 for the default
+	IPv6Prefix int    // Leading bits of IPv6 addresses kept; 0 for the default
+}
+
+// IPPrefixGenerator anonymizes IPv4 and IPv6 addresses by keeping their
+// network prefix and replacing the rest of their bits, so that addresses
+// of the same network remain so, and the network topology of logs can
+// still be analyzed. It handles the values of PostgreSQL inet and cidr
+// columns, with or without a netmask.
+type IPPrefixGenerator struct {
+	BaseGenerator
+	prefix4, prefix6 int
+}
+
+// NewIPPrefixGenerator creates a generator of IP addresses keeping their
+// network prefix.
+func NewIPPrefixGenerator(cfg IPPatternConfig) (*IPPrefixGenerator, error) {
+	g := &IPPrefixGenerator{
+		BaseGenerator: BaseGenerator{name: cfg.Name},
+		prefix4:       cfg.IPv4Prefix,
+		prefix6:       cfg.IPv6Prefix,
+	}
+	if g.prefix4 == 0 {
+		g.prefix4 = DefaultIPv4Prefix
+	}
+	if g.prefix6 == 0 {
+		g.prefix6 = DefaultIPv6Prefix
+	}
+	if g.prefix4 < 0 || g.prefix4 > 32 {
+		return nil, fmt.Errorf("pattern %s: ipv4_prefix must be between 0 "+
+			"and 32, got %d", cfg.Name, g.prefix4)
+	}
+	if g.prefix6 < 0 || g.prefix6 > 128 {
+		return nil, fmt.Errorf("pattern %s: ipv6_prefix must be between 0 "+
+			"and 128, got %d", cfg.Name, g.prefix6)
+	}
+	return g, nil
+}
+
+// Generate returns an address with the input's leading prefix bits, and
+// the rest random. A netmask, as in "192.168.1.20/24", is kept. A network,
+// whose bits beyond its netmask are zero, as in the cidr value
+// "10.1.0.0/16", remains a network of that size: only its bits between the
+// prefix and the netmask are replaced, and a network no longer than the
+// prefix is kept as it is. Addresses other than networks are not given a
+// host part of all zeros or all ones. IPv6 addresses keep the case and
+// whether they are written in full or compressed, and IPv4 addresses
+// mapped to IPv6, as in "::ffff:10.1.2.3", keep their IPv4 prefix. Input
+// that is not an address is replaced with a random IPv4 address.
+func (g *IPPrefixGenerator) Generate(input string) string {
+	trimmed := strings.TrimSpace(input)
+	text, maskText, hasMask := strings.Cut(trimmed, "/")
+
+	addr, err := netip.ParseAddr(text)
+	if err != nil {
+		return netip.AddrFrom4([4]byte{byte(1 + randomInt(223)),
+			byte(randomInt(256)), byte(randomInt(256)),
+			byte(1 + randomInt(254))}).String()
+	}
+	// IPv4 addresses mapped to IPv6 keep their IPv4 prefix
+	mapped := addr.Is4In6() && !hasMask
+	if mapped {
+		addr = addr.Unmap()
+	}
+	total := addr.BitLen()
+	keep := g.prefix4
+	if addr.Is6() {
+		keep = g.prefix6
+	}
+
+	mask := total
+	if hasMask {
+		m, err := strconv.Atoi(maskText)
+		if err == nil && m >= 0 && m <= total {
+			mask = m
+		}
+	}
+	bits := addr.AsSlice()
+	network := hasMask && mask < total && zeroBits(bits, mask, total)
+
+	end, hostStart := total, keep
+	if network {
+		end = mask
+	} else if hasMask {
+		hostStart = max(keep, mask)
+	}
+	for range 10 {
+		for i := keep; i < end; i++ {
+			setBit(bits, i, randomInt(2) == 1)
+		}
+		// Avoid network and broadcast addresses
+		if network || total-hostStart < 2 ||
+			(!zeroBits(bits, hostStart, total) &&
+				!oneBits(bits, hostStart, total)) {
+			break
+		}
+	}
+
+	result, _ := netip.AddrFromSlice(bits)
+	out := result.String()
+	switch {
+	case mapped:
+		out = text[:strings.LastIndexByte(text, ':')+1] + out
+	case addr.Is6():
+		if len(text) == 39 {
+			out = result.StringExpanded()
+		}
+		if strings.ToUpper(text) == text {
+			out = strings.ToUpper(out)
+		}
+	}
+	if hasMask {
+		out += "/" + maskText
+	}
+	return out
+}
+
+// Description describes the values an IP prefix generator produces.
+func (g *IPPrefixGenerator) Description() string {
+	return fmt.Sprintf("IP addresses keeping the first %d bits of IPv4 "+
+		"and %d bits of IPv6 addresses", g.prefix4, g.prefix6)
+}
+
+// Category returns the category of IP prefix patterns.
+func (g *IPPrefixGenerator) Category() string {
+	return CategoryNetwork
+}
+
+// setBit sets or clears a bit of an address, counting from the most
+// significant.
+func setBit(b []byte, i int, on bool) {
+	if on {
+		b[i/8] |= 0x80 >> (i % 8)
+	} else {
+		b[i/8] &^= 0x80 >> (i % 8)
+	}
+}
+
+// zeroBits reports whether the bits of an address from start to end are
+// all zeros.
+func zeroBits(b []byte, start, end int) bool {
+	for i := start; i < end; i++ {
+		if b[i/8]&(0x80>>(i%8)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// oneBits reports whether the bits of an address from start to end are
+// all ones.
+func oneBits(b []byte, start, end int) bool {
+	for i := start; i < end; i++ {
+		if b[i/8]&(0x80>>(i%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
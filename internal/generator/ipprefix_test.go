@@ -0,0 +1,80 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"net/netip"
+	"regexp"
+	"testing"
+)
+
+func TestIPPrefixGenerator(t *testing.T) {
+	g, err := NewIPPrefixGenerator(IPPatternConfig{Name: "IP_KEEP_PREFIX"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"10.1.2.3", `^10\.1\.\d+\.\d+$`},
+		{"192.168.1.20/24", `^192\.168\.\d+\.\d+/24$`},
+		{"10.1.0.0/16", `^10\.1\.0\.0/16$`},
+		{"10.0.0.0/8", `^10\.0\.0\.0/8$`},
+		{"10.1.2.0/24", `^10\.1\.\d+\.0/24$`},
+		{"2001:db8:85a3::8a2e:370:7334", `^2001:db8:85a3:[0-9a-f:]+$`},
+		{"2001:0DB8:85A3:0000:0000:8A2E:0370:7334",
+			`^2001:0DB8:85A3(:[0-9A-F]{4}){5}$`},
+		{"2001:db8:85a3::/48", `^2001:db8:85a3::/48$`},
+		{"::ffff:10.1.2.3", `^::ffff:10\.1\.\d+\.\d+$`},
+		{"not an address", `^\d+\.\d+\.\d+\.\d+$`},
+	}
+
+	for _, tt := range tests {
+		re := regexp.MustCompile(tt.pattern)
+		for range 20 {
+			result := g.Generate(tt.input)
+			if !re.MatchString(result) {
+				t.Fatalf("%q: expected %s to match %s", tt.input, result,
+					tt.pattern)
+			}
+		}
+	}
+
+	t.Run("networks stay valid", func(t *testing.T) {
+		for range 50 {
+			result := g.Generate("10.1.128.0/20")
+			prefix, err := netip.ParsePrefix(result)
+			if err != nil || prefix.Masked() != prefix || prefix.Bits() != 20 {
+				t.Fatalf("expected a /20 network, got %s", result)
+			}
+		}
+	})
+
+	t.Run("no network or broadcast addresses", func(t *testing.T) {
+		g, _ := NewIPPrefixGenerator(IPPatternConfig{Name: "IP",
+			IPv4Prefix: 29})
+		for range 200 {
+			result := g.Generate("10.1.2.3")
+			if result == "10.1.2.0" || result == "10.1.2.7" {
+				t.Fatalf("unexpected address %s", result)
+			}
+		}
+	})
+
+	for _, cfg := range []IPPatternConfig{{IPv4Prefix: 33}, {IPv6Prefix: -1},
+		{IPv6Prefix: 129}} {
+		if _, err := NewIPPrefixGenerator(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
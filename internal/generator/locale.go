@@ -0,0 +1,146 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data/countries"
+)
+
+// localeScale is the resolution with which weighted countries are chosen.
+const localeScale = 1 << 30
+
+// Locale chooses countries in proportion to their weights, so that values
+// drawn from several countries match the mix of the data they replace.
+type Locale struct {
+	countries  []string
+	cumulative []float64 // Running total of the weights, ending at 1
+}
+
+// NewLocale creates a locale from weights keyed by country code. Weights
+// need not add up to 1, as they are normalized.
+func NewLocale(weights map[string]float64) (*Locale, error) {
+	codes := make([]string, 0, len(weights))
+	var total float64
+	for code, w := range weights {
+		if !IsCountry(code) {
+			return nil, fmt.Errorf("unknown country %q", code)
+		}
+		if w <= 0 {
+			return nil, fmt.Errorf("weight of %s must be positive, got %g",
+				code, w)
+		}
+		codes = append(codes, code)
+		total += w
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("locale has no countries")
+	}
+	sort.Strings(codes)
+
+	l := &Locale{countries: codes}
+	var sum float64
+	for _, code := range codes {
+		sum += weights[code] / total
+		l.cumulative = append(l.cumulative, sum)
+	}
+	l.cumulative[len(l.cumulative)-1] = 1
+	return l, nil
+}
+
+// Country chooses a country in proportion to its weight.
+func (l *Locale) Country() string {
+	r := float64(randomInt(localeScale)) / localeScale
+	i := sort.SearchFloat64s(l.cumulative, r)
+	if i < len(l.cumulative) && l.cumulative[i] == r {
+		i++
+	}
+	return l.countries[min(i, len(l.countries)-1)]
+}
+
+// localeGenerator generates each value with the generator of a country
+// chosen by the locale, replacing a worldwide or generic generator.
+type localeGenerator struct {
+	BaseGenerator
+	locale    *Locale
+	byCountry map[string]Generator
+}
+
+// Generate produces a value from a country chosen by the locale.
+func (g *localeGenerator) Generate(input string) string {
+	return g.byCountry[g.locale.Country()].Generate(input)
+}
+
+// localeGenerators maps the worldwide and generic generators the locale
+// applies to, to the suffix of the country-specific generators replacing
+// them.
+var localeGenerators = map[string]string{
+	"WORLDWIDE_FIRST_NAME": "_FIRST_NAME",
+	"WORLDWIDE_LAST_NAME":  "_LAST_NAME",
+	"WORLDWIDE_NAME":       "_NAME",
+	"WORLDWIDE_CITY":       "_CITY",
+	"WORLDWIDE_ADDRESS":    "_ADDRESS",
+	"WORLDWIDE_PHONE":      "_PHONE",
+	"WORLDWIDE_POSTCODE":   "_POSTCODE",
+	"PERSON_FIRST_NAME":    "_FIRST_NAME",
+	"PERSON_LAST_NAME":     "_LAST_NAME",
+	"PERSON_NAME":          "_NAME",
+	"CITY":                 "_CITY",
+	"ADDRESS":              "_ADDRESS",
+	"EMAIL":                "_EMAIL",
+}
+
+// countryGeneratorName returns the name of a country's generator with the
+// given suffix; US postcodes are generated by US_ZIP.
+func countryGeneratorName(country, suffix string) string {
+	if country == countries.US && suffix == "_POSTCODE" {
+		return "US_ZIP"
+	}
+	return country + suffix
+}
+
+// SetLocale makes the worldwide generators, and the generic name, city,
+// address and email generators, draw each value from a country chosen in
+// proportion to weights, keyed by country code, rather than from all
+// countries alike. Personas generated without a country are drawn from
+// the locale too. It must be called before custom generators are
+// registered, as it replaces the generators it applies to.
+func (m *Manager) SetLocale(weights map[string]float64) error {
+	if len(weights) == 0 {
+		return nil
+	}
+	locale, err := NewLocale(weights)
+	if err != nil {
+		return err
+	}
+
+	for name, suffix := range localeGenerators {
+		g := &localeGenerator{
+			BaseGenerator: BaseGenerator{name: name},
+			locale:        locale,
+			byCountry:     make(map[string]Generator),
+		}
+		for _, country := range locale.countries {
+			gen, ok := m.registry.Get(countryGeneratorName(country, suffix))
+			if !ok {
+				return fmt.Errorf("no %s generator for country %s",
+					suffix[1:], country)
+			}
+			g.byCountry[country] = gen
+		}
+		m.registry.Register(g)
+	}
+
+	m.locale = locale
+	return nil
+}
@@ -0,0 +1,90 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data/countries"
+)
+
+// TestLocale tests choosing countries in proportion to their weights
+func TestLocale(t *testing.T) {
+	l, err := NewLocale(map[string]float64{"US": 3, "MX": 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		counts[l.Country()]++
+	}
+	if len(counts) != 2 || counts["US"] < 2700 || counts["US"] > 3300 {
+		t.Errorf("unexpected country counts: %v", counts)
+	}
+
+	for _, weights := range []map[string]float64{
+		{}, {"XX": 1}, {"US": 0}, {"US": -1},
+	} {
+		if _, err := NewLocale(weights); err == nil {
+			t.Errorf("expected an error for %v", weights)
+		}
+	}
+}
+
+// TestManagerSetLocale tests that worldwide and generic generators draw
+// from the locale's countries
+func TestManagerSetLocale(t *testing.T) {
+	m := NewManager()
+	if err := m.SetLocale(map[string]float64{"DE": 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	de := m.countryData.Countries[countries.DE]
+
+	for _, name := range []string{"WORLDWIDE_CITY", "CITY"} {
+		g, _ := m.Get(name)
+		for i := 0; i < 20; i++ {
+			if city := g.Generate("Paris"); !contains(de.Cities, city) {
+				t.Fatalf("%s generated %q, not a German city", name, city)
+			}
+		}
+	}
+
+	g, _ := m.Get("EMAIL")
+	if email := g.Generate("jean@example.fr"); !strings.HasSuffix(email,
+		".de") {
+		t.Errorf("expected a German email domain, got %q", email)
+	}
+
+	g, _ = m.Get("PERSON_FIRST_NAME")
+	if name := g.Generate("Jean"); !contains(de.FirstNames, name) {
+		t.Errorf("expected a German first name, got %q", name)
+	}
+	if g.Name() != "PERSON_FIRST_NAME" {
+		t.Errorf("unexpected generator name %q", g.Name())
+	}
+
+	p, err := m.NewPersona("")
+	if err != nil || p.Country != countries.DE {
+		t.Errorf("expected a German persona, got %+v, %v", p, err)
+	}
+
+	// Every generator the locale applies to has a generator for each
+	// country
+	all := make(map[string]float64)
+	for _, code := range countries.AllCountries {
+		all[code] = 1
+	}
+	if err := NewManager().SetLocale(all); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -20,7 +20,8 @@ import (
 // LoremGenerator generates lorem ipsum text.
 type LoremGenerator struct {
 	BaseGenerator
-	data *data.DataSet
+	data      *data.DataSet
+	maxLength int // Most characters the column holds, or zero
 }
 
 // NewLoremGenerator creates a new lorem ipsum generator.
@@ -31,6 +32,17 @@ func NewLoremGenerator(d *data.DataSet) *LoremGenerator {
 	}
 }
 
+// BindContext returns a generator whose text fits the column's length,
+// as text slightly longer than the input is otherwise generated.
+func (g *LoremGenerator) BindContext(info ColumnInfo) Generator {
+	if info.MaxLength == 0 {
+		return g
+	}
+	bound := *g
+	bound.maxLength = info.MaxLength
+	return &bound
+}
+
 // capitalizeFirst capitalizes the first letter of a string.
 func capitalizeFirst(s string) string {
 	if len(s) == 0 {
@@ -89,5 +101,5 @@ func (g *LoremGenerator) Generate(input string) string {
 		text += "."
 	}
 
-	return text
+	return fitLength(text, g.maxLength)
 }
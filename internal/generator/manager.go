@@ -12,6 +12,12 @@
 package generator
 
 import (
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+
 	"github.com/pgedge/pgedge-anonymizer/internal/generator/data"
 	"github.com/pgedge/pgedge-anonymizer/internal/generator/data/countries"
 )
@@ -21,6 +27,10 @@ type Manager struct {
 	registry    *Registry
 	data        *data.DataSet
 	countryData *countries.CountryDataSet
+	tracked     []Generator     // generators that can fail or hold resources
+	pure        map[string]bool // generators without side effects, by name
+	locale      *Locale         // weights countries, if set
+	sanitizer   *Sanitizer      // cleans values for the database, if set
 }
 
 // FormatPatternConfig holds configuration for creating a format-based generator.
@@ -34,6 +44,21 @@ type FormatPatternConfig struct {
 	MaxYear int    // Max year for date type
 }
 
+// ExecPatternConfig holds configuration for creating a command-based
+// generator.
+type ExecPatternConfig struct {
+	Name    string   // Pattern name (becomes generator name)
+	Command string   // Command to run
+	Args    []string // Command arguments
+}
+
+// ScriptPatternConfig holds configuration for creating a script-based
+// generator.
+type ScriptPatternConfig struct {
+	Name   string // Pattern name (becomes generator name)
+	Script string // Lua source
+}
+
 // NewManager creates a new generator manager with all built-in generators.
 func NewManager() *Manager {
 	dataset := data.Load()
@@ -48,6 +73,10 @@ func NewManager() *Manager {
 
 	// Register all built-in generators
 	m.registerBuiltins()
+	m.pure = make(map[string]bool)
+	for _, name := range registry.List() {
+		m.pure[name] = true
+	}
 
 	return m
 }
@@ -94,6 +123,7 @@ func (m *Manager) registerBuiltins() {
 			m.registry.Register(NewCountryLastNameGenerator(code, data))
 			m.registry.Register(NewCountryFullNameGenerator(code, data))
 			m.registry.Register(NewCountryCityGenerator(code, data))
+			m.registry.Register(NewCountryEmailGenerator(code, data))
 		}
 	}
 
@@ -193,6 +223,10 @@ func (m *Manager) registerBuiltins() {
 	m.registry.Register(NewCreditCardGenerator())
 	m.registry.Register(NewCreditCardExpiryGenerator())
 	m.registry.Register(NewCreditCardCVVGenerator())
+	m.registry.Register(NewIBANGenerator())
+	m.registry.Register(NewBICGenerator())
+	m.registry.Register(NewBTCAddressGenerator())
+	m.registry.Register(NewETHAddressGenerator())
 
 	// ID number generators (legacy/generic)
 	m.registry.Register(NewSSNGenerator())
@@ -221,20 +255,48 @@ func (m *Manager) registerBuiltins() {
 	m.registry.Register(NewSGNRICGenerator())
 	m.registry.Register(NewUSSSNGenerator())
 
+	// Company identifier generators
+	m.registry.Register(NewEUVATGenerator())
+	m.registry.Register(NewUSEINGenerator())
+	m.registry.Register(NewUKCRNGenerator())
+	m.registry.Register(NewDEHRBGenerator())
+
+	// Vehicle generators
+	m.registry.Register(NewVINGenerator())
+	m.registry.Register(NewUSLicensePlateGenerator())
+	m.registry.Register(NewUKLicensePlateGenerator())
+	m.registry.Register(NewDELicensePlateGenerator())
+
+	// Medical generators
+	m.registry.Register(NewMRNGenerator())
+	m.registry.Register(NewUSNPIGenerator())
+	m.registry.Register(NewUSDEAGenerator())
+	m.registry.Register(NewICD10Generator())
+
 	// Date generators
 	m.registry.Register(NewDOBGenerator())
 	m.registry.Register(NewDOBOver13Generator())
 	m.registry.Register(NewDOBOver16Generator())
 	m.registry.Register(NewDOBOver18Generator())
 	m.registry.Register(NewDOBOver21Generator())
+	m.registry.Register(NewDateShiftGenerator())
 
 	// Text generators
 	m.registry.Register(NewLoremGenerator(m.data))
+	m.registry.Register(NewTextScrubGenerator(m.data))
 
 	// Network generators
 	m.registry.Register(NewIPv4Generator())
 	m.registry.Register(NewIPv6Generator())
 	m.registry.Register(NewHostnameGenerator(m.data))
+	m.registry.Register(NewURLGenerator())
+	m.registry.Register(NewUserAgentGenerator(m.data))
+
+	// Account generators
+	m.registry.Register(NewUsernameGenerator(m.data))
+	m.registry.Register(NewSocialHandleGenerator(m.data))
+	m.registry.Register(NewTwitterHandleGenerator(m.data))
+	m.registry.Register(NewGitHubHandleGenerator(m.data))
 }
 
 // Get retrieves a generator by name.
@@ -242,6 +304,54 @@ func (m *Manager) Get(name string) (Generator, bool) {
 	return m.registry.Get(name)
 }
 
+// GetForColumn retrieves a generator by name for use on a specific column,
+// binding the column to generators whose output depends on it, and
+// passing its values through the sanitizer, if one is set.
+func (m *Manager) GetForColumn(name string, col errors.ColumnRef) (Generator,
+	bool) {
+	return m.GetForContext(name, ColumnInfo{Column: col})
+}
+
+// GetForContext retrieves a generator by name for use on a column whose
+// data type and length are known, binding them to generators whose output
+// depends on them, and passing its values through the sanitizer, if one
+// is set.
+func (m *Manager) GetForContext(name string, info ColumnInfo) (Generator,
+	bool) {
+	g, ok := m.registry.Get(name)
+	if !ok {
+		return nil, false
+	}
+	return m.sanitize(bind(g, info)), true
+}
+
+// Register adds a generator, replacing any existing generator with the
+// same name. This is used to add custom generators supplied by callers.
+func (m *Manager) Register(g Generator) {
+	m.registry.Register(g)
+	delete(m.pure, g.Name())
+	m.track(g)
+}
+
+// IsPure returns true if the generator registered under name is a
+// built-in or format generator, whose values depend only on chance, so
+// that generating some, such as to check the data types they suit, has
+// no effect on a run. Generators that run commands or scripts, encrypt
+// or hash values, transform their input, or are supplied by callers are
+// not pure.
+func (m *Manager) IsPure(name string) bool {
+	return m.pure[name]
+}
+
+// track remembers generators that report errors or need closing.
+func (m *Manager) track(g Generator) {
+	_, fallible := g.(FallibleGenerator)
+	_, closer := g.(io.Closer)
+	if fallible || closer {
+		m.tracked = append(m.tracked, g)
+	}
+}
+
 // List returns all registered generator names.
 func (m *Manager) List() []string {
 	return m.registry.List()
@@ -283,6 +393,175 @@ func (m *Manager) RegisterFormatPattern(cfg FormatPatternConfig) error {
 	// Create and register the generator
 	gen := NewFormatGenerator(cfg.Name, formatConfig)
 	m.registry.Register(gen)
+	m.pure[cfg.Name] = true
+
+	return nil
+}
+
+// RegisterExecPattern creates and registers a generator that pipes values
+// through an external command. The command is not started until the
+// generator is first used.
+func (m *Manager) RegisterExecPattern(cfg ExecPatternConfig) error {
+	if cfg.Command == "" {
+		return fmt.Errorf("pattern %s: exec command is empty", cfg.Name)
+	}
+
+	m.Register(NewExecGenerator(cfg.Name, cfg.Command, cfg.Args))
+
+	return nil
+}
+
+// RegisterFPEPattern creates and registers a generator that encrypts the
+// digits and letters of values with FF3-1. Its key is not read from the
+// configured environment variable or file until the generator is first
+// used.
+func (m *Manager) RegisterFPEPattern(cfg FPEPatternConfig) error {
+	if cfg.KeyEnv == "" && cfg.KeyFile == "" {
+		return fmt.Errorf("pattern %s: a key_env or key_file is required",
+			cfg.Name)
+	}
+
+	tweak := make([]byte, fpeTweakSize)
+	if cfg.Tweak != "" {
+		var err error
+		tweak, err = hex.DecodeString(cfg.Tweak)
+		if err != nil {
+			return fmt.Errorf("pattern %s: tweak must be hexadecimal: %w",
+				cfg.Name, err)
+		}
+		if len(tweak) != fpeTweakSize {
+			return fmt.Errorf("pattern %s: tweak must be %d bytes, got %d",
+				cfg.Name, fpeTweakSize, len(tweak))
+		}
+	}
+
+	m.Register(newFPEPatternGenerator(cfg, tweak))
+	return nil
+}
+
+// RegisterHashPattern creates and registers a generator that replaces
+// values with keyed hashes. Its key is not read from the configured
+// environment variable or file until the generator is first used.
+func (m *Manager) RegisterHashPattern(cfg HashPatternConfig) error {
+	if cfg.KeyEnv == "" && cfg.KeyFile == "" {
+		return fmt.Errorf("pattern %s: a key_env or key_file is required",
+			cfg.Name)
+	}
+	if cfg.Length < 0 || cfg.Length > MaxHashLength {
+		return fmt.Errorf("pattern %s: length must be between 1 and %d",
+			cfg.Name, MaxHashLength)
+	}
+
+	m.Register(newHashGenerator(cfg))
+	return nil
+}
+
+// RegisterRedactPattern creates and registers a generator that masks
+// values, keeping part of them.
+func (m *Manager) RegisterRedactPattern(cfg RedactPatternConfig) error {
+	gen, err := NewRedactGenerator(cfg)
+	if err != nil {
+		return err
+	}
+
+	m.Register(gen)
+	return nil
+}
+
+// RegisterSuppressPattern creates and registers a generator that replaces
+// values with neutral ones.
+func (m *Manager) RegisterSuppressPattern(cfg SuppressPatternConfig) error {
+	gen, err := NewSuppressGenerator(cfg)
+	if err != nil {
+		return err
+	}
+
+	m.Register(gen)
+	return nil
+}
+
+// RegisterEpochPattern creates and registers a generator that anonymizes
+// epoch timestamps.
+func (m *Manager) RegisterEpochPattern(cfg EpochPatternConfig) error {
+	gen, err := NewEpochGenerator(cfg)
+	if err != nil {
+		return err
+	}
+
+	m.Register(gen)
+	return nil
+}
+
+// RegisterGeoPattern creates and registers a generator that anonymizes
+// geographic coordinates.
+func (m *Manager) RegisterGeoPattern(cfg GeoPatternConfig) error {
+	gen, err := NewGeoGenerator(cfg)
+	if err != nil {
+		return err
+	}
+
+	m.Register(gen)
+	return nil
+}
+
+// RegisterIPPattern creates and registers a generator that anonymizes IP
+// addresses keeping their network prefix.
+func (m *Manager) RegisterIPPattern(cfg IPPatternConfig) error {
+	gen, err := NewIPPrefixGenerator(cfg)
+	if err != nil {
+		return err
+	}
+
+	m.Register(gen)
+	return nil
+}
+
+// RegisterNoisePattern creates and registers a generator that adds
+// Laplace noise to numbers.
+func (m *Manager) RegisterNoisePattern(cfg NoisePatternConfig) error {
+	gen, err := NewNoiseGenerator(cfg)
+	if err != nil {
+		return err
+	}
+
+	m.Register(gen)
+	return nil
+}
+
+// Err returns the first error reported by a generator that can fail, such
+// as one backed by an external command.
+func (m *Manager) Err() error {
+	for _, gen := range m.tracked {
+		if f, ok := gen.(FallibleGenerator); ok {
+			if err := f.Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close releases resources held by generators, such as external commands.
+func (m *Manager) Close() error {
+	var firstErr error
+	for _, gen := range m.tracked {
+		if c, ok := gen.(io.Closer); ok {
+			if err := c.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// RegisterScriptPattern compiles and registers a generator that runs a Lua
+// script. Scripts can call any generator registered with the manager.
+func (m *Manager) RegisterScriptPattern(cfg ScriptPatternConfig) error {
+	gen, err := NewScriptGenerator(cfg.Name, cfg.Script, m.Get)
+	if err != nil {
+		return err
+	}
 
+	m.Register(gen)
 	return nil
 }
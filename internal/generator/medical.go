@@ -0,0 +1,162 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"strings"
+)
+
+// MRNGenerator generates medical record numbers.
+type MRNGenerator struct {
+	BaseGenerator
+}
+
+// NewMRNGenerator creates a new medical record number generator.
+func NewMRNGenerator() *MRNGenerator {
+	return &MRNGenerator{
+		BaseGenerator: BaseGenerator{name: "MRN"},
+	}
+}
+
+// Generate produces a medical record number in the format of the input.
+// Record numbers are assigned by each facility in its own format, so the
+// letters and separators of the input, which usually identify the
+// facility, are kept, and each run of digits is replaced with a number of
+// the same length, keeping any zero padding. Empty input is replaced with
+// an 8 digit number.
+func (g *MRNGenerator) Generate(input string) string {
+	if strings.TrimSpace(input) == "" {
+		return string(randomDigitNonZero()) + generateDigits(7)
+	}
+
+	b := []byte(input)
+	leading := true
+	for i, c := range b {
+		switch {
+		case c < '0' || c > '9':
+			leading = true
+		case leading && c == '0' && i+1 < len(b) && b[i+1] >= '0' &&
+			b[i+1] <= '9':
+			// Zero padding
+		case leading:
+			b[i] = randomDigitNonZero()
+			leading = false
+		default:
+			b[i] = randomDigit()
+		}
+	}
+	return string(b)
+}
+
+// USNPIGenerator generates US National Provider Identifiers.
+type USNPIGenerator struct {
+	BaseGenerator
+}
+
+// NewUSNPIGenerator creates a new US NPI generator.
+func NewUSNPIGenerator() *USNPIGenerator {
+	return &USNPIGenerator{
+		BaseGenerator: BaseGenerator{name: "US_NPI"},
+	}
+}
+
+// Generate produces a US National Provider Identifier: 10 digits starting
+// with 1 or 2, the last a Luhn check digit computed with the prefix 80840
+// of NPIs used as health identifiers on cards.
+func (g *USNPIGenerator) Generate(input string) string {
+	payload := string(byte('1'+randomInt(2))) + generateDigits(8)
+	return payload + string(luhnCheckDigit("80840"+payload))
+}
+
+// deaRegistrants are the first letters of DEA numbers, which give the
+// type of registrant, such as A, B or F for practitioners and M for
+// mid-level practitioners.
+const deaRegistrants = "ABFGM"
+
+// USDEAGenerator generates US DEA registration numbers.
+type USDEAGenerator struct {
+	BaseGenerator
+}
+
+// NewUSDEAGenerator creates a new US DEA number generator.
+func NewUSDEAGenerator() *USDEAGenerator {
+	return &USDEAGenerator{
+		BaseGenerator: BaseGenerator{name: "US_DEA"},
+	}
+}
+
+// Generate produces a DEA registration number: a letter for the type of
+// registrant, a letter that is usually the initial of the registrant's
+// last name, and 7 digits, the last a check digit. The input's registrant
+// type is kept; the initial is random.
+func (g *USDEAGenerator) Generate(input string) string {
+	trimmed := strings.ToUpper(strings.TrimSpace(input))
+
+	registrant := deaRegistrants[randomInt(len(deaRegistrants))]
+	if trimmed != "" && isUpperLetters(trimmed[:1]) {
+		registrant = trimmed[0]
+	}
+
+	digits := generateDigits(6)
+	return string(registrant) +
+		string(upperLetters[randomInt(len(upperLetters))]) + digits +
+		string(deaCheckDigit(digits))
+}
+
+// deaCheckDigit returns the check digit of the first 6 digits of a DEA
+// number: the last digit of the sum of the 1st, 3rd and 5th digits and
+// twice the sum of the 2nd, 4th and 6th.
+func deaCheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < 6; i++ {
+		d := int(digits[i] - '0')
+		if i%2 == 1 {
+			d *= 2
+		}
+		sum += d
+	}
+	return byte('0' + sum%10)
+}
+
+// icd10Codes are common ICD-10-CM diagnosis codes, without their dots.
+var icd10Codes = []string{
+	"A090", "B349", "E039", "E119", "E1165", "E559", "E669", "E785",
+	"F17210", "F329", "F411", "G4700", "G43909", "H1045", "H6590", "I10",
+	"I2510", "I480", "I509", "J029", "J069", "J189", "J209", "J301",
+	"J449", "J45909", "K219", "K5900", "L309", "M545", "M5416", "M79604",
+	"M1990", "N179", "N390", "R05", "R0600", "R109", "R51", "R5383",
+	"S93401A", "Z0000", "Z0001", "Z23", "Z794", "Z87891",
+}
+
+// ICD10Generator generates ICD-10 diagnosis codes.
+type ICD10Generator struct {
+	BaseGenerator
+}
+
+// NewICD10Generator creates a new ICD-10 code generator.
+func NewICD10Generator() *ICD10Generator {
+	return &ICD10Generator{
+		BaseGenerator: BaseGenerator{name: "ICD10"},
+	}
+}
+
+// Generate produces a common ICD-10-CM diagnosis code, written with a dot
+// after the category (E11.9) unless the input is written without one.
+// Diagnoses are special category data, so the code is unrelated to the
+// input's, even in its chapter.
+func (g *ICD10Generator) Generate(input string) string {
+	code := randomString(icd10Codes)
+	trimmed := strings.TrimSpace(input)
+	if len(code) == 3 || (trimmed != "" && !strings.Contains(trimmed, ".")) {
+		return code
+	}
+	return code[:3] + "." + code[3:]
+}
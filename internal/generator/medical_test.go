@@ -0,0 +1,108 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMRNGenerator(t *testing.T) {
+	g := NewMRNGenerator()
+
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"12345678", `^[1-9]\d{7}$`},
+		{"00123456", `^00[1-9]\d{5}$`},
+		{"MRN-2024-000789", `^MRN-[1-9]\d{3}-000[1-9]\d{2}$`},
+		{"A1234567", `^A[1-9]\d{6}$`},
+		{"0", `^[1-9]$`},
+		{"", `^[1-9]\d{7}$`},
+	}
+
+	for _, tt := range tests {
+		re := regexp.MustCompile(tt.pattern)
+		for range 20 {
+			if result := g.Generate(tt.input); !re.MatchString(result) {
+				t.Fatalf("%q: expected %s to match %s", tt.input, result,
+					tt.pattern)
+			}
+		}
+	}
+}
+
+func TestUSNPIGenerator(t *testing.T) {
+	// Example from the CMS NPI check digit specification
+	if !isValidLuhn("80840" + "1234567893") {
+		t.Fatal("expected 1234567893 to be a valid NPI")
+	}
+
+	g := NewUSNPIGenerator()
+	re := regexp.MustCompile(`^[12]\d{9}$`)
+	for range 50 {
+		result := g.Generate("1234567893")
+		if !re.MatchString(result) || !isValidLuhn("80840"+result) {
+			t.Fatalf("invalid NPI %s", result)
+		}
+	}
+}
+
+func TestUSDEAGenerator(t *testing.T) {
+	if got := deaCheckDigit("123456"); got != '3' {
+		t.Errorf("expected check digit 3 for AB1234563, got %c", got)
+	}
+
+	g := NewUSDEAGenerator()
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"AB1234563", `^A[A-Z]\d{7}$`},
+		{"mj4567891", `^M[A-Z]\d{7}$`},
+		{"", `^[ABFGM][A-Z]\d{7}$`},
+	}
+
+	for _, tt := range tests {
+		re := regexp.MustCompile(tt.pattern)
+		for range 20 {
+			result := g.Generate(tt.input)
+			if !re.MatchString(result) {
+				t.Fatalf("%q: expected %s to match %s", tt.input, result,
+					tt.pattern)
+			}
+			if deaCheckDigit(result[2:8]) != result[8] {
+				t.Fatalf("invalid check digit in %s", result)
+			}
+		}
+	}
+}
+
+func TestICD10Generator(t *testing.T) {
+	g := NewICD10Generator()
+
+	for range 50 {
+		result := g.Generate("E11.9")
+		if len(result) > 3 && result[3] != '.' {
+			t.Fatalf("expected a dot in %s", result)
+		}
+		if !slices.Contains(icd10Codes, strings.Replace(result, ".", "", 1)) {
+			t.Fatalf("unexpected code %s", result)
+		}
+
+		if result := g.Generate("E119"); strings.Contains(result, ".") {
+			t.Fatalf("expected no dot in %s", result)
+		}
+	}
+}
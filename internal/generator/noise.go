@@ -0,0 +1,161 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NoisePatternConfig holds configuration for creating a generator that
+// adds Laplace noise to numbers.
+type NoisePatternConfig struct {
+	Name string // Pattern name (becomes generator name)
+
+	// Epsilon is the privacy budget of each value: the smaller it is, the
+	// more noise is added. Sensitivity is the most a value can change
+	// that must not be told apart, such as a salary rise. The noise has
+	// a scale of Sensitivity / Epsilon.
+	Epsilon     float64
+	Sensitivity float64
+
+	// NonNegative keeps noisy values at zero or above, for amounts and
+	// counts that cannot be negative.
+	NonNegative bool
+}
+
+// integerRanges are the values the integer types hold.
+var integerRanges = map[string][2]int64{
+	"smallint": {math.MinInt16, math.MaxInt16},
+	"integer":  {math.MinInt32, math.MaxInt32},
+	"bigint":   {math.MinInt64, math.MaxInt64},
+}
+
+// NoiseGenerator anonymizes numbers by adding noise drawn from the Laplace
+// distribution, the mechanism of differential privacy: each noisy value is
+// epsilon-differentially private with respect to a change in the original
+// of up to the sensitivity, so that aggregates such as sums and averages
+// over many rows remain close to the original ones while no single value
+// can be told within that margin.
+type NoiseGenerator struct {
+	BaseGenerator
+	cfg   NoisePatternConfig
+	scale float64
+
+	// integer is set for integer columns, whose values are rounded and
+	// kept within the range of their type, from min to max
+	integer  bool
+	min, max int64
+}
+
+// NewNoiseGenerator creates a Laplace noise generator.
+func NewNoiseGenerator(cfg NoisePatternConfig) (*NoiseGenerator, error) {
+	if cfg.Epsilon <= 0 || math.IsInf(cfg.Epsilon, 0) {
+		return nil, fmt.Errorf("pattern %s: epsilon must be greater than 0",
+			cfg.Name)
+	}
+	if cfg.Sensitivity <= 0 || math.IsInf(cfg.Sensitivity, 0) {
+		return nil, fmt.Errorf("pattern %s: sensitivity must be greater "+
+			"than 0", cfg.Name)
+	}
+	return &NoiseGenerator{
+		BaseGenerator: BaseGenerator{name: cfg.Name},
+		cfg:           cfg,
+		scale:         cfg.Sensitivity / cfg.Epsilon,
+	}, nil
+}
+
+// BindContext returns a generator that keeps the values of an integer
+// column within the range of its type.
+func (g *NoiseGenerator) BindContext(info ColumnInfo) Generator {
+	r, ok := integerRanges[info.DataType]
+	if !ok {
+		return g
+	}
+	bound := *g
+	bound.integer, bound.min, bound.max = true, r[0], r[1]
+	return &bound
+}
+
+// Generate returns the input with Laplace noise added, with the input's
+// decimal places, so that integers stay integers. Infinities and NaN are
+// returned as they are, and input that is not a number is replaced with
+// noise around zero.
+func (g *NoiseGenerator) Generate(input string) string {
+	trimmed := strings.TrimSpace(input)
+	value, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil {
+		value, trimmed = 0, "0"
+	} else if math.IsInf(value, 0) || math.IsNaN(value) {
+		return input
+	}
+
+	out := value + g.laplace()
+	if g.cfg.NonNegative {
+		out = max(out, 0)
+	}
+	if g.integer {
+		return strconv.FormatInt(g.clamp(math.Round(out)), 10)
+	}
+	if strings.ContainsAny(trimmed, "eE") {
+		return strconv.FormatFloat(out, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(out, 'f', decimalPlaces(trimmed), 64)
+}
+
+// clamp returns an integer value within the range of the column's type.
+// The range is compared in integer space, as a float64 cannot hold the
+// largest bigint: values at or beyond 2^63 are clamped before they are
+// converted.
+func (g *NoiseGenerator) clamp(value float64) int64 {
+	limit := math.Ldexp(1, 63)
+	switch {
+	case value >= limit:
+		return g.max
+	case value < -limit:
+		return g.min
+	}
+	return min(max(int64(value), g.min), g.max)
+}
+
+// laplace returns noise drawn from the Laplace distribution centered on
+// zero with the generator's scale: an exponentially distributed distance,
+// either way.
+func (g *NoiseGenerator) laplace() float64 {
+	distance := -g.scale * math.Log(1-randomFloat())
+	if randomInt(2) == 0 {
+		return -distance
+	}
+	return distance
+}
+
+// Description describes the values a noise generator produces.
+func (g *NoiseGenerator) Description() string {
+	return fmt.Sprintf("Numbers with Laplace noise of scale %g (epsilon %g, "+
+		"sensitivity %g)", g.scale, g.cfg.Epsilon, g.cfg.Sensitivity)
+}
+
+// Category returns the category of noise patterns.
+func (g *NoiseGenerator) Category() string {
+	return CategoryOther
+}
+
+// decimalPlaces returns the number of decimal places of a number, or zero
+// if it has none.
+func decimalPlaces(number string) int {
+	i := strings.IndexByte(number, '.')
+	if i < 0 {
+		return 0
+	}
+	return len(number) - i - 1
+}
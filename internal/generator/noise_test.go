@@ -0,0 +1,130 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestNoiseGenerator(t *testing.T) {
+	g, err := NewNoiseGenerator(NoisePatternConfig{Name: "SALARY_NOISE",
+		Epsilon: 0.5, Sensitivity: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"52000", `^-?\d+$`},
+		{"52000.50", `^-?\d+\.\d{2}$`},
+		{" 17.125 ", `^-?\d+\.\d{3}$`},
+		{"5.2e4", `^-?[0-9.]+(e[-+]\d+)?$`},
+		{"not a number", `^-?\d+$`},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile(tt.pattern)
+		for range 20 {
+			if result := g.Generate(tt.input); !re.MatchString(result) {
+				t.Fatalf("%q: expected %s to match %s", tt.input, result,
+					tt.pattern)
+			}
+		}
+	}
+
+	for _, input := range []string{"NaN", "Infinity", "-Infinity"} {
+		if result := g.Generate(input); result != input {
+			t.Errorf("expected %s to be kept, got %s", input, result)
+		}
+	}
+
+	t.Run("noise has the configured scale", func(t *testing.T) {
+		// The mean absolute deviation of the Laplace distribution is its
+		// scale, here 1000 / 0.5
+		const n = 20000
+		sum, total := 0.0, 0.0
+		for range n {
+			value, err := strconv.ParseFloat(g.Generate("0"), 64)
+			if err != nil {
+				t.Fatalf("unexpected value: %v", err)
+			}
+			sum += value
+			total += math.Abs(value)
+		}
+		if mean := sum / n; math.Abs(mean) > 100 {
+			t.Errorf("expected noise centered on 0, got a mean of %g", mean)
+		}
+		if scale := total / n; scale < 1900 || scale > 2100 {
+			t.Errorf("expected a scale of about 2000, got %g", scale)
+		}
+	})
+
+	t.Run("non-negative", func(t *testing.T) {
+		g, _ := NewNoiseGenerator(NoisePatternConfig{Name: "COUNT_NOISE",
+			Epsilon: 0.1, Sensitivity: 10, NonNegative: true})
+		for range 200 {
+			if value, _ := strconv.ParseFloat(g.Generate("1"),
+				64); value < 0 {
+				t.Fatalf("expected a value of at least 0, got %g", value)
+			}
+		}
+	})
+
+	t.Run("integer columns", func(t *testing.T) {
+		bound := g.BindContext(ColumnInfo{DataType: "smallint"})
+		for range 200 {
+			value, err := strconv.ParseInt(bound.Generate("32000.5"), 10, 16)
+			if err != nil {
+				t.Fatalf("expected a smallint: %v", err)
+			}
+			if value < math.MinInt16 || value > math.MaxInt16 {
+				t.Fatalf("unexpected value %d", value)
+			}
+		}
+		if bound := g.BindContext(ColumnInfo{DataType: "numeric"}); bound != g {
+			t.Error("expected the generator itself for a numeric column")
+		}
+	})
+
+	t.Run("bigint limits", func(t *testing.T) {
+		wide, _ := NewNoiseGenerator(NoisePatternConfig{Name: "WIDE_NOISE",
+			Epsilon: 1, Sensitivity: 1e6})
+		bound := wide.BindContext(ColumnInfo{DataType: "bigint"})
+		for _, input := range []string{"9223372036854775807",
+			"-9223372036854775808", "1e300", "-1e300"} {
+			for range 100 {
+				// A value past the range would not parse
+				out := bound.Generate(input)
+				if _, err := strconv.ParseInt(out, 10, 64); err != nil {
+					t.Fatalf("expected a bigint for %s: %v", input, err)
+				}
+			}
+		}
+		if got := bound.Generate("1e300"); got != "9223372036854775807" {
+			t.Errorf("expected the largest bigint, got %s", got)
+		}
+		if got := bound.Generate("-1e300"); got != "-9223372036854775808" {
+			t.Errorf("expected the smallest bigint, got %s", got)
+		}
+	})
+
+	for _, cfg := range []NoisePatternConfig{{Sensitivity: 1},
+		{Epsilon: 1}, {Epsilon: -1, Sensitivity: 1},
+		{Epsilon: 1, Sensitivity: math.Inf(1)}} {
+		if _, err := NewNoiseGenerator(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
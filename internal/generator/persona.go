@@ -0,0 +1,211 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data/countries"
+)
+
+// Persona is a generated person whose details are drawn from a single
+// country's data, so that they are consistent with each other: the email
+// address is made from the name, and the address holds the city and
+// postcode.
+type Persona struct {
+	Country   string `json:"country"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+	Address   string `json:"address"`
+	City      string `json:"city"`
+	Postcode  string `json:"postcode"`
+	DOB       string `json:"dob"` // YYYY-MM-DD
+}
+
+// PersonaFields lists the fields of a persona that may be written to a
+// column.
+var PersonaFields = []string{
+	"first_name", "last_name", "name", "email", "phone", "address", "city",
+	"postcode", "dob", "country",
+}
+
+// IsPersonaField returns true if field is one of PersonaFields.
+func IsPersonaField(field string) bool {
+	for _, f := range PersonaFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCountry returns true if code is a supported country code.
+func IsCountry(code string) bool {
+	for _, c := range countries.AllCountries {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPersona generates a persona from the data of country, or of a
+// country chosen by the locale, or at random, if it is empty. With a
+// sanitizer, a persona losing characters is generated again, up to a
+// limit.
+func (m *Manager) NewPersona(country string) (*Persona, error) {
+	p, err := m.newPersona(country)
+	if err != nil || m.sanitizer == nil {
+		return p, err
+	}
+	for i := 0; m.sanitizePersona(p) && i < maxSanitizeRetries; i++ {
+		if p, err = m.newPersona(country); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// newPersona generates a persona, as NewPersona does, without sanitizing
+// it.
+func (m *Manager) newPersona(country string) (*Persona, error) {
+	if country == "" && m.locale != nil {
+		country = m.locale.Country()
+	} else if country == "" {
+		country = randomString(countries.AllCountries)
+	}
+	data := m.countryData.Countries[country]
+	if data == nil {
+		return nil, fmt.Errorf("unknown country %q", country)
+	}
+
+	p := &Persona{
+		Country:   country,
+		FirstName: randomString(data.FirstNames),
+		LastName:  randomString(data.LastNames),
+		City:      randomString(data.Cities),
+	}
+	p.Email = personaEmail(p.FirstName, p.LastName,
+		randomString(countryEmailDomains[country]))
+
+	if g, ok := m.registry.Get(country + "_PHONE"); ok {
+		p.Phone = g.Generate("")
+	}
+
+	// The address is formatted with the persona's city and postcode
+	if g, ok := m.registry.Get(country + "_ADDRESS"); ok {
+		if addr, ok := g.(*CountryAddressGenerator); ok {
+			p.Postcode = addr.postcodeGen.Generate("")
+			streetType := ""
+			if len(addr.streetTypes) > 0 {
+				streetType = randomString(addr.streetTypes)
+			}
+			p.Address = addr.format(1+randomInt(999),
+				randomString(genericStreetNames), streetType, p.City,
+				p.Postcode)
+		}
+	}
+
+	dob, _ := m.registry.Get("DOB_OVER_18")
+	p.DOB = dob.Generate("")
+
+	return p, nil
+}
+
+// personaEmail returns an email address made from a persona's name, with
+// a random suffix so that personas sharing a name have different
+// addresses.
+func personaEmail(firstName, lastName, domain string) string {
+	name := emailLocal(firstName) + "." + emailLocal(lastName)
+	if name == "." {
+		name = randomLocalPart()
+	}
+	return name + "." + randomHex(3) + "@" + domain
+}
+
+// randomHex returns n random bytes in hexadecimal.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// emailLocal returns the lowercase ASCII letters and digits of s, with
+// accented letters in their unaccented forms, for use in the local part
+// of an email address.
+func emailLocal(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		ascii, _ := asciiForm(r)
+		for _, c := range ascii {
+			if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+				b.WriteRune(c)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Field returns the value of a persona's field, formatted like input:
+// names and addresses take its case, and dates of birth its date format.
+func (p *Persona) Field(field, input string) string {
+	var value string
+	switch field {
+	case "first_name":
+		value = p.FirstName
+	case "last_name":
+		value = p.LastName
+	case "name":
+		// Keep the "Last, First" order of the original
+		if strings.Contains(input, ",") {
+			value = p.LastName + ", " + p.FirstName
+		} else {
+			value = p.FirstName + " " + p.LastName
+		}
+	case "email":
+		return p.Email
+	case "phone":
+		return p.Phone
+	case "address":
+		value = p.Address
+	case "city":
+		value = p.City
+	case "postcode":
+		value = p.Postcode
+	case "dob":
+		dob, err := time.Parse("2006-01-02", p.DOB)
+		if err != nil {
+			return p.DOB
+		}
+		return formatDate(dob, detectDateFormat(input))
+	case "country":
+		return p.Country
+	}
+	return matchCase(value, input)
+}
+
+// matchCase returns value in upper or lower case if input is all upper or
+// lower case.
+func matchCase(value, input string) string {
+	if strings.ToUpper(input) == input && len(input) > 1 {
+		return strings.ToUpper(value)
+	}
+	if strings.ToLower(input) == input && len(input) > 1 {
+		return strings.ToLower(value)
+	}
+	return value
+}
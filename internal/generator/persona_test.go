@@ -0,0 +1,105 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data/countries"
+)
+
+// TestNewPersona tests that a persona's details are drawn from one country
+func TestNewPersona(t *testing.T) {
+	m := NewManager()
+	de := m.countryData.Countries[countries.DE]
+
+	p, err := m.NewPersona(countries.DE)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Country != countries.DE {
+		t.Errorf("unexpected country %q", p.Country)
+	}
+	if !contains(de.FirstNames, p.FirstName) ||
+		!contains(de.LastNames, p.LastName) ||
+		!contains(de.Cities, p.City) {
+		t.Errorf("expected German names and city, got %+v", p)
+	}
+
+	// The address holds the persona's city and postcode
+	if !strings.Contains(p.Address, p.City) ||
+		!strings.Contains(p.Address, p.Postcode) {
+		t.Errorf("address %q does not hold %q and %q", p.Address, p.City,
+			p.Postcode)
+	}
+	if p.Phone == "" {
+		t.Error("expected a phone number")
+	}
+	if !regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`).MatchString(p.DOB) {
+		t.Errorf("unexpected date of birth %q", p.DOB)
+	}
+	if !regexp.MustCompile(`^[a-z0-9]+\.[a-z0-9]+\.[0-9a-f]{6}@.*\.de$`).
+		MatchString(p.Email) {
+		t.Errorf("unexpected email %q", p.Email)
+	}
+
+	// Without a country, one is chosen
+	p, err = m.NewPersona("")
+	if err != nil || !IsCountry(p.Country) {
+		t.Errorf("expected a persona from a supported country, got %+v, %v",
+			p, err)
+	}
+
+	if _, err := m.NewPersona("XX"); err == nil {
+		t.Error("expected an error for an unknown country")
+	}
+}
+
+// TestPersonaField tests formatting a persona's fields like the values
+// they replace
+func TestPersonaField(t *testing.T) {
+	p := &Persona{
+		FirstName: "Anna",
+		LastName:  "Schmidt",
+		City:      "Berlin",
+		DOB:       "1980-03-14",
+	}
+
+	tests := []struct {
+		field, input, want string
+	}{
+		{"first_name", "John", "Anna"},
+		{"first_name", "JOHN", "ANNA"},
+		{"name", "John Smith", "Anna Schmidt"},
+		{"name", "Smith, John", "Schmidt, Anna"},
+		{"city", "london", "berlin"},
+		{"dob", "1975-01-02", "1980-03-14"},
+		{"dob", "01/02/1975", "03/14/1980"},
+	}
+	for _, tt := range tests {
+		if got := p.Field(tt.field, tt.input); got != tt.want {
+			t.Errorf("Field(%q, %q) = %q, want %q", tt.field, tt.input, got,
+				tt.want)
+		}
+	}
+}
+
+// contains returns true if values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
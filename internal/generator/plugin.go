@@ -0,0 +1,68 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// PluginSymbol is the name of the function a generator plugin must export.
+// Its signature must be func() []generator.Generator; plugins built outside
+// this module can use the equivalent pkg/anonymizer.Generator type.
+const PluginSymbol = "Generators"
+
+// LoadPlugin opens a Go plugin (built with -buildmode=plugin) and returns
+// the generators it provides. Plugins must be built with the same Go
+// version and dependency versions as the anonymizer, and are only
+// supported on platforms where the plugin package is available.
+func LoadPlugin(path string) ([]Generator, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s does not export %s: %w", path,
+			PluginSymbol, err)
+	}
+
+	fn, ok := sym.(func() []Generator)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s: %s has type %T, expected "+
+			"func() []generator.Generator", path, PluginSymbol, sym)
+	}
+
+	generators := fn()
+	for _, g := range generators {
+		if g == nil || g.Name() == "" {
+			return nil, fmt.Errorf("plugin %s returned a generator with no name",
+				path)
+		}
+	}
+
+	return generators, nil
+}
+
+// LoadPlugins loads each plugin in turn and returns all of their
+// generators.
+func LoadPlugins(paths []string) ([]Generator, error) {
+	var generators []Generator
+	for _, path := range paths {
+		gens, err := LoadPlugin(path)
+		if err != nil {
+			return nil, err
+		}
+		generators = append(generators, gens...)
+	}
+	return generators, nil
+}
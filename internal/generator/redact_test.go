@@ -0,0 +1,63 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import "testing"
+
+// TestRedactGenerator tests masking values while keeping part of them
+func TestRedactGenerator(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   RedactPatternConfig
+		input string
+		want  string
+	}{
+		{"card last four", RedactPatternConfig{KeepLast: 4},
+			"4532 1234 5678 9012", "**** **** **** 9012"},
+		{"card dashes", RedactPatternConfig{KeepFirst: 4, KeepLast: 4},
+			"4532-1234-5678-9012", "4532-****-****-9012"},
+		{"keep more than the value", RedactPatternConfig{KeepLast: 10},
+			"1234", "1234"},
+		{"mask everything", RedactPatternConfig{}, "AB-12 cd", "**-** **"},
+		{"other character", RedactPatternConfig{KeepLast: 2, Char: "#"},
+			"ABC123", "####23"},
+		{"email domain", RedactPatternConfig{KeepDomain: true},
+			"alice.smith@example.com", "*****.*****@example.com"},
+		{"email domain and first", RedactPatternConfig{KeepDomain: true,
+			KeepFirst: 1}, "alice@example.com", "a****@example.com"},
+		{"not an email", RedactPatternConfig{KeepDomain: true},
+			"alice", "*****"},
+		{"unicode", RedactPatternConfig{KeepFirst: 1}, "Zoë Ørsted",
+			"Z** ******"},
+		{"empty", RedactPatternConfig{KeepLast: 4}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewRedactGenerator(tt.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := g.Generate(tt.input); got != tt.want {
+				t.Errorf("Generate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	for name, cfg := range map[string]RedactPatternConfig{
+		"negative keep": {Name: "BAD", KeepLast: -1},
+		"long char":     {Name: "BAD", Char: "**"},
+	} {
+		if _, err := NewRedactGenerator(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
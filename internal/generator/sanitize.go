@@ -0,0 +1,373 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Sanitizer names.
+const (
+	// SanitizeStripInvalid removes characters the database's encoding
+	// cannot hold.
+	SanitizeStripInvalid = "strip_invalid"
+
+	// SanitizeNFC normalizes values to Unicode Normalization Form C.
+	SanitizeNFC = "nfc"
+
+	// SanitizeASCII transliterates values to ASCII, removing characters
+	// with no ASCII form.
+	SanitizeASCII = "ascii"
+
+	// SanitizeConvert transliterates the characters the database's
+	// encoding cannot hold to ASCII, removing those with no ASCII form.
+	SanitizeConvert = "convert"
+)
+
+// Sanitizers lists the sanitizer names.
+var Sanitizers = []string{SanitizeStripInvalid, SanitizeNFC, SanitizeASCII,
+	SanitizeConvert}
+
+// DefaultSanitizers returns the sanitizers used for a database whose
+// server encoding is not UTF-8, when none are configured, so that the
+// non-ASCII letters of generated names and addresses do not fail the
+// run when written to it. A UTF-8 database can hold any value, and needs
+// none.
+func DefaultSanitizers(encoding string) []string {
+	if encoding == "UTF8" {
+		return nil
+	}
+	return []string{SanitizeNFC, SanitizeConvert}
+}
+
+// IsSanitizer returns true if name is one of Sanitizers.
+func IsSanitizer(name string) bool {
+	for _, s := range Sanitizers {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// maxSanitizeRetries is the number of times a value that loses characters
+// when sanitized is generated again, in the hope of one that does not,
+// before the sanitized value is used.
+const maxSanitizeRetries = 10
+
+// sanitizeStep cleans a value, reporting whether characters were removed
+// rather than replaced.
+type sanitizeStep func(value string) (string, bool)
+
+// Sanitizer cleans generated values before they are written to the
+// database, applying a chain of steps in order. A nil Sanitizer leaves
+// values as they are.
+type Sanitizer struct {
+	steps []sanitizeStep
+}
+
+// NewSanitizer creates a sanitizer applying the named steps in order. The
+// encoding is the database's server encoding, such as "LATIN1", used by
+// SanitizeStripInvalid.
+func NewSanitizer(names []string, encoding string) (*Sanitizer, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	s := &Sanitizer{}
+	for _, name := range names {
+		switch name {
+		case SanitizeStripInvalid:
+			s.steps = append(s.steps, stripInvalid(encoding))
+		case SanitizeNFC:
+			s.steps = append(s.steps, func(value string) (string, bool) {
+				return norm.NFC.String(value), false
+			})
+		case SanitizeASCII:
+			s.steps = append(s.steps, transliterateASCII)
+		case SanitizeConvert:
+			s.steps = append(s.steps, convertInvalid(encoding))
+		default:
+			return nil, fmt.Errorf("unknown sanitizer %q; must be one of %s",
+				name, strings.Join(Sanitizers, ", "))
+		}
+	}
+	return s, nil
+}
+
+// Sanitize returns the cleaned value, and whether characters were removed
+// from it.
+func (s *Sanitizer) Sanitize(value string) (string, bool) {
+	if s == nil {
+		return value, false
+	}
+	lost := false
+	for _, step := range s.steps {
+		var l bool
+		value, l = step(value)
+		lost = lost || l
+	}
+	return value, lost
+}
+
+// SetSanitizer makes the generators returned by GetForColumn, and
+// personas, pass their values through a sanitizer. Derived values are
+// made from values that have already been sanitized.
+func (m *Manager) SetSanitizer(s *Sanitizer) {
+	m.sanitizer = s
+}
+
+// sanitizedGenerator passes the values of a generator through a
+// sanitizer, generating a value again if the first loses characters.
+type sanitizedGenerator struct {
+	Generator
+	sanitizer *Sanitizer
+}
+
+// Generate returns a sanitized value.
+func (g *sanitizedGenerator) Generate(input string) string {
+	value, lost := g.sanitizer.Sanitize(g.Generator.Generate(input))
+	for i := 0; i < maxSanitizeRetries && lost; i++ {
+		value, lost = g.sanitizer.Sanitize(g.Generator.Generate(input))
+	}
+	return value
+}
+
+// Err returns an error from the wrapped generator.
+func (g *sanitizedGenerator) Err() error {
+	if f, ok := g.Generator.(FallibleGenerator); ok {
+		return f.Err()
+	}
+	return nil
+}
+
+// sanitizedBatchGenerator is a sanitizedGenerator whose generator
+// produces values in batches.
+type sanitizedBatchGenerator struct {
+	sanitizedGenerator
+	batch BatchGenerator
+}
+
+// GenerateBatch returns sanitized values for the inputs.
+func (g *sanitizedBatchGenerator) GenerateBatch(inputs []string) []string {
+	outputs := g.batch.GenerateBatch(inputs)
+	for i, value := range outputs {
+		outputs[i], _ = g.sanitizer.Sanitize(value)
+	}
+	return outputs
+}
+
+// sanitize wraps a generator with the manager's sanitizer, if it has one.
+func (m *Manager) sanitize(g Generator) Generator {
+	if m.sanitizer == nil {
+		return g
+	}
+	sg := sanitizedGenerator{Generator: g, sanitizer: m.sanitizer}
+	if batch, ok := g.(BatchGenerator); ok {
+		return &sanitizedBatchGenerator{sanitizedGenerator: sg, batch: batch}
+	}
+	return &sg
+}
+
+// sanitizePersona sanitizes the fields of a persona, returning whether
+// characters were removed from any of them.
+func (m *Manager) sanitizePersona(p *Persona) bool {
+	lost := false
+	for _, field := range []*string{&p.FirstName, &p.LastName, &p.Email,
+		&p.Phone, &p.Address, &p.City, &p.Postcode} {
+		var l bool
+		*field, l = m.sanitizer.Sanitize(*field)
+		lost = lost || l
+	}
+	return lost
+}
+
+// serverEncodings maps PostgreSQL server encodings to their character
+// sets. UTF8 and SQL_ASCII are handled separately.
+var serverEncodings = map[string]encoding.Encoding{
+	"LATIN1":     charmap.ISO8859_1,
+	"LATIN2":     charmap.ISO8859_2,
+	"LATIN3":     charmap.ISO8859_3,
+	"LATIN4":     charmap.ISO8859_4,
+	"LATIN5":     charmap.ISO8859_9,
+	"LATIN6":     charmap.ISO8859_10,
+	"LATIN7":     charmap.ISO8859_13,
+	"LATIN8":     charmap.ISO8859_14,
+	"LATIN9":     charmap.ISO8859_15,
+	"LATIN10":    charmap.ISO8859_16,
+	"ISO_8859_5": charmap.ISO8859_5,
+	"ISO_8859_6": charmap.ISO8859_6,
+	"ISO_8859_7": charmap.ISO8859_7,
+	"ISO_8859_8": charmap.ISO8859_8,
+	"KOI8R":      charmap.KOI8R,
+	"KOI8U":      charmap.KOI8U,
+	"WIN866":     charmap.CodePage866,
+	"WIN874":     charmap.Windows874,
+	"WIN1250":    charmap.Windows1250,
+	"WIN1251":    charmap.Windows1251,
+	"WIN1252":    charmap.Windows1252,
+	"WIN1253":    charmap.Windows1253,
+	"WIN1254":    charmap.Windows1254,
+	"WIN1255":    charmap.Windows1255,
+	"WIN1256":    charmap.Windows1256,
+	"WIN1257":    charmap.Windows1257,
+	"WIN1258":    charmap.Windows1258,
+	"EUC_JP":     japanese.EUCJP,
+	"EUC_KR":     korean.EUCKR,
+}
+
+// IsKnownEncoding returns true if the characters of a server encoding are
+// known, so that SanitizeStripInvalid can remove those it cannot hold.
+func IsKnownEncoding(name string) bool {
+	_, ok := serverEncodings[name]
+	return ok || name == "UTF8" || name == "SQL_ASCII"
+}
+
+// encodingHolds returns a function reporting whether a server encoding
+// can hold a character, and whether the encoding's characters are known.
+// Legacy SQL_ASCII databases are assumed to hold ASCII only.
+func encodingHolds(name string) (func(r rune) bool, bool) {
+	if name == "UTF8" {
+		return func(r rune) bool { return true }, true
+	}
+	if name == "SQL_ASCII" {
+		return isASCII, true
+	}
+	enc, ok := serverEncodings[name]
+	if !ok {
+		return func(r rune) bool { return true }, false
+	}
+	return func(r rune) bool {
+		_, err := enc.NewEncoder().String(string(r))
+		return err == nil
+	}, true
+}
+
+// isASCII returns true for ASCII characters.
+func isASCII(r rune) bool {
+	return r < utf8.RuneSelf
+}
+
+// stripInvalid returns a step removing the characters a server encoding
+// cannot hold, and NUL characters, which no text value can hold. For an
+// encoding whose characters are not known, only invalid UTF-8 is removed.
+func stripInvalid(name string) sanitizeStep {
+	valid, _ := encodingHolds(name)
+
+	return func(value string) (string, bool) {
+		var b strings.Builder
+		lost := false
+		for i, r := range value {
+			if r == 0 || (r == utf8.RuneError && !validRuneAt(value, i)) ||
+				!valid(r) {
+				lost = true
+				continue
+			}
+			b.WriteRune(r)
+		}
+		return b.String(), lost
+	}
+}
+
+// convertInvalid returns a step replacing the characters a server
+// encoding cannot hold with their ASCII forms, and removing those with
+// none, along with NUL characters and invalid UTF-8. An encoding whose
+// characters are not known is assumed to hold ASCII only.
+func convertInvalid(name string) sanitizeStep {
+	valid, known := encodingHolds(name)
+	if !known {
+		valid = isASCII
+	}
+
+	return func(value string) (string, bool) {
+		var b strings.Builder
+		lost := false
+		for i, r := range value {
+			switch {
+			case r == 0 || (r == utf8.RuneError && !validRuneAt(value, i)):
+				lost = true
+			case valid(r):
+				b.WriteRune(r)
+			default:
+				ascii, ok := asciiForm(r)
+				b.WriteString(ascii)
+				lost = lost || !ok
+			}
+		}
+		return b.String(), lost
+	}
+}
+
+// validRuneAt returns true if the encoded rune at byte i of s is valid
+// UTF-8, rather than an invalid byte decoded as utf8.RuneError.
+func validRuneAt(s string, i int) bool {
+	_, size := utf8.DecodeRuneInString(s[i:])
+	return size > 1
+}
+
+// asciiLetters holds the ASCII forms of letters that do not decompose
+// into an ASCII letter and accents, and of common punctuation.
+var asciiLetters = map[rune]string{
+	'ß': "ss", 'ẞ': "SS", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE",
+	'ø': "o", 'Ø': "O", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D",
+	'ł': "l", 'Ł': "L", 'þ': "th", 'Þ': "Th", 'ı': "i", 'ħ': "h",
+	'Ħ': "H", 'ŋ': "ng", 'Ŋ': "NG",
+	'‘': "'", '’': "'", '‚': "'", '“': "\"", '”': "\"", '„': "\"",
+	'–': "-", '—': "-", '…': "...", ' ': " ",
+}
+
+// transliterateASCII replaces accented letters with their unaccented
+// forms, and other letters and punctuation with ASCII equivalents,
+// removing any character with no ASCII form.
+func transliterateASCII(value string) (string, bool) {
+	var b strings.Builder
+	lost := false
+	for _, r := range value {
+		ascii, ok := asciiForm(r)
+		b.WriteString(ascii)
+		lost = lost || !ok
+	}
+	return b.String(), lost
+}
+
+// asciiForm returns the ASCII form of a character: an accented letter
+// without its accents, or an equivalent from asciiLetters. An accent on
+// its own has an empty form. It returns false if the character, or part
+// of it, has no ASCII form.
+func asciiForm(r rune) (string, bool) {
+	if r < utf8.RuneSelf {
+		return string(r), true
+	}
+	var b strings.Builder
+	ok := true
+	for _, d := range norm.NFD.String(string(r)) {
+		switch {
+		case d < utf8.RuneSelf:
+			b.WriteRune(d)
+		case unicode.Is(unicode.Mn, d):
+			// An accent of the preceding letter
+		case asciiLetters[d] != "":
+			b.WriteString(asciiLetters[d])
+		default:
+			ok = false
+		}
+	}
+	return b.String(), ok
+}
@@ -0,0 +1,130 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// TestSanitizer tests the sanitizer steps
+func TestSanitizer(t *testing.T) {
+	tests := []struct {
+		name     string
+		steps    []string
+		encoding string
+		input    string
+		want     string
+		lost     bool
+	}{
+		{"latin1 keeps accents", []string{SanitizeStripInvalid}, "LATIN1",
+			"Zoë Müller", "Zoë Müller", false},
+		{"latin1 strips hangul", []string{SanitizeStripInvalid}, "LATIN1",
+			"Kim 민준", "Kim ", true},
+		{"sql_ascii", []string{SanitizeStripInvalid}, "SQL_ASCII",
+			"Zoë", "Zo", true},
+		{"utf8 strips nul", []string{SanitizeStripInvalid}, "UTF8",
+			"a\x00b\xffc", "abc", true},
+		{"nfc", []string{SanitizeNFC}, "UTF8", "Rene\u0301e", "Ren\u00e9e",
+			false},
+		{"ascii", []string{SanitizeASCII}, "UTF8",
+			"Zoë Łukasz Straße", "Zoe Lukasz Strasse", false},
+		{"ascii loses", []string{SanitizeASCII}, "UTF8",
+			"Kim 민준", "Kim ", true},
+		{"latin1 converts", []string{SanitizeConvert}, "LATIN1",
+			"Zoë Łukasz 민준", "Zoë Lukasz ", true},
+		{"sql_ascii converts", []string{SanitizeConvert}, "SQL_ASCII",
+			"Zoë Straße", "Zoe Strasse", false},
+		{"unknown encoding converts to ascii", []string{SanitizeConvert},
+			"MULE_INTERNAL", "Zoë", "Zoe", false},
+		{"chain", []string{SanitizeNFC, SanitizeASCII, SanitizeStripInvalid},
+			"SQL_ASCII", "Renée", "Renee", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSanitizer(tt.steps, tt.encoding)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, lost := s.Sanitize(tt.input)
+			if got != tt.want || lost != tt.lost {
+				t.Errorf("got (%q, %v), want (%q, %v)", got, lost, tt.want,
+					tt.lost)
+			}
+		})
+	}
+
+	if _, err := NewSanitizer([]string{"upper"}, "UTF8"); err == nil {
+		t.Error("expected an error for an unknown sanitizer")
+	}
+	if s, err := NewSanitizer(nil, "UTF8"); s != nil || err != nil {
+		t.Errorf("expected no sanitizer, got %v, %v", s, err)
+	}
+}
+
+// TestDefaultSanitizers tests the sanitizers used for each encoding
+func TestDefaultSanitizers(t *testing.T) {
+	if names := DefaultSanitizers("UTF8"); names != nil {
+		t.Errorf("expected no sanitizers for UTF8, got %v", names)
+	}
+	for _, encoding := range []string{"SQL_ASCII", "LATIN1", "EUC_KR"} {
+		names := DefaultSanitizers(encoding)
+		if len(names) == 0 || names[len(names)-1] != SanitizeConvert {
+			t.Errorf("%s: expected the values to be converted, got %v",
+				encoding, names)
+		}
+	}
+}
+
+// sequenceGenerator returns its values in turn.
+type sequenceGenerator struct {
+	BaseGenerator
+	values []string
+	n      int
+}
+
+func (g *sequenceGenerator) Generate(input string) string {
+	v := g.values[g.n%len(g.values)]
+	g.n++
+	return v
+}
+
+// TestSanitizedGenerator tests that values losing characters are
+// generated again
+func TestSanitizedGenerator(t *testing.T) {
+	m := NewManager()
+	m.Register(&sequenceGenerator{BaseGenerator: BaseGenerator{name: "SEQ"},
+		values: []string{"민준", "서연", "Zoë"}})
+	s, err := NewSanitizer([]string{SanitizeStripInvalid}, "LATIN1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetSanitizer(s)
+
+	col := errors.ColumnRef{Schema: "public", Table: "t", Column: "c"}
+	g, ok := m.GetForColumn("SEQ", col)
+	if !ok {
+		t.Fatal("expected the generator")
+	}
+	if got := g.Generate("x"); got != "Zoë" {
+		t.Errorf("got %q, want %q", got, "Zoë")
+	}
+
+	// A generator that never gives a clean value gives its sanitized value
+	m.Register(&sequenceGenerator{BaseGenerator: BaseGenerator{name: "KO"},
+		values: []string{"민준"}})
+	g, _ = m.GetForColumn("KO", col)
+	if got := g.Generate("x"); got != "" {
+		t.Errorf("got %q, want an empty value", got)
+	}
+}
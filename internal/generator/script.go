@@ -0,0 +1,210 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// ScriptTimeout limits how long a script may run for a single value.
+const ScriptTimeout = 5 * time.Second
+
+// ColumnBinder is implemented by generators whose output depends on the
+// column being anonymized. BindColumn returns a generator for that column.
+type ColumnBinder interface {
+	Generator
+	BindColumn(col errors.ColumnRef) Generator
+}
+
+// scriptState is a Lua interpreter running a compiled script, shared by
+// a script generator and the copies bound to each column.
+type scriptState struct {
+	name   string
+	mu     sync.Mutex
+	L      *lua.LState
+	chunk  *lua.LFunction
+	lookup func(name string) (Generator, bool)
+	err    error
+}
+
+// ScriptGenerator generates values with a Lua script. The script runs once
+// per value with the globals value, column, table_name, schema_name,
+// column_type and max_length set (all but value are empty, or zero, unless
+// bound to a column), and must return the replacement string. A fake table gives access to every other generator
+// by pattern name, e.g. fake.EMAIL(value).
+type ScriptGenerator struct {
+	BaseGenerator
+	state  *scriptState
+	column ColumnInfo
+}
+
+// NewScriptGenerator compiles a Lua script into a generator. The lookup
+// function resolves the generators available to the script through fake.
+func NewScriptGenerator(name, script string,
+	lookup func(name string) (Generator, bool)) (*ScriptGenerator, error) {
+
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	openSafeLibs(L)
+
+	chunk, err := L.LoadString(script)
+	if err != nil {
+		L.Close()
+		return nil, fmt.Errorf("pattern %s: invalid script: %w", name, err)
+	}
+
+	state := &scriptState{name: name, L: L, chunk: chunk, lookup: lookup}
+	L.SetGlobal("fake", state.fakeTable())
+
+	return &ScriptGenerator{
+		BaseGenerator: BaseGenerator{name: name},
+		state:         state,
+	}, nil
+}
+
+// openSafeLibs opens the Lua libraries that cannot reach the file system,
+// the environment or other processes.
+func openSafeLibs(L *lua.LState) {
+	for _, lib := range []struct {
+		name string
+		fn   lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.fn))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+
+	// The base library can also load code from files
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+}
+
+// fakeTable returns a table whose fields are functions calling the
+// generator of the same name; the input defaults to the value being
+// anonymized.
+func (s *scriptState) fakeTable() *lua.LTable {
+	L := s.L
+	fake := L.NewTable()
+	meta := L.NewTable()
+	L.SetField(meta, "__index", L.NewFunction(func(L *lua.LState) int {
+		name := L.CheckString(2)
+		gen, ok := s.lookup(name)
+		if !ok {
+			gen, ok = s.lookup(strings.ToUpper(name))
+		}
+		if !ok {
+			L.RaiseError("unknown pattern %q", name)
+			return 0
+		}
+		if sg, isScript := gen.(*ScriptGenerator); isScript && sg.state == s {
+			L.RaiseError("pattern %s cannot call itself", name)
+			return 0
+		}
+		L.Push(L.NewFunction(func(L *lua.LState) int {
+			input := L.OptString(1, L.GetGlobal("value").String())
+			L.Push(lua.LString(gen.Generate(input)))
+			return 1
+		}))
+		return 1
+	}))
+	L.SetMetatable(fake, meta)
+	return fake
+}
+
+// Generate runs the script for a value.
+func (g *ScriptGenerator) Generate(input string) string {
+	return g.state.run(input, g.column)
+}
+
+// BindColumn returns a generator that exposes the column to the script.
+func (g *ScriptGenerator) BindColumn(col errors.ColumnRef) Generator {
+	return g.BindContext(ColumnInfo{Column: col})
+}
+
+// BindContext returns a generator that exposes the column, its data type
+// and its length to the script.
+func (g *ScriptGenerator) BindContext(info ColumnInfo) Generator {
+	return &ScriptGenerator{
+		BaseGenerator: g.BaseGenerator,
+		state:         g.state,
+		column:        info,
+	}
+}
+
+// Err returns the first error raised by the script.
+func (g *ScriptGenerator) Err() error {
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	return g.state.err
+}
+
+// Close releases the interpreter.
+func (g *ScriptGenerator) Close() error {
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	g.state.L.Close()
+	return nil
+}
+
+// run executes the script for one value. After the first error, it
+// returns empty strings without running the script.
+func (s *scriptState) run(input string, info ColumnInfo) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return ""
+	}
+
+	L := s.L
+	L.SetGlobal("value", lua.LString(input))
+	L.SetGlobal("column", lua.LString(info.Column.Column))
+	L.SetGlobal("table_name", lua.LString(info.Column.Table))
+	L.SetGlobal("schema_name", lua.LString(info.Column.Schema))
+	L.SetGlobal("column_type", lua.LString(info.DataType))
+	L.SetGlobal("max_length", lua.LNumber(info.MaxLength))
+
+	ctx, cancel := context.WithTimeout(context.Background(), ScriptTimeout)
+	defer cancel()
+	L.SetContext(ctx)
+	defer L.RemoveContext()
+
+	L.Push(s.chunk)
+	if err := L.PCall(0, 1, nil); err != nil {
+		s.err = fmt.Errorf("pattern %s: script error: %w", s.name, err)
+		return ""
+	}
+
+	ret := L.Get(-1)
+	L.Pop(1)
+
+	switch ret.Type() {
+	case lua.LTString, lua.LTNumber:
+		return ret.String()
+	default:
+		s.err = fmt.Errorf("pattern %s: script returned %s, expected a string",
+			s.name, ret.Type())
+		return ""
+	}
+}
@@ -0,0 +1,190 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// TestScriptGenerator tests generators backed by Lua scripts
+func TestScriptGenerator(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+
+	t.Run("return value", func(t *testing.T) {
+		g, err := NewScriptGenerator("UPPER", "return string.upper(value)", m.Get)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := g.Generate("alice"); got != "ALICE" {
+			t.Errorf("Generate() = %q, want ALICE", got)
+		}
+		if err := g.Err(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("keep domain", func(t *testing.T) {
+		script := `
+local at = value:find("@")
+if not at then
+  return fake.EMAIL()
+end
+return string.lower(fake.PERSON_FIRST_NAME()) .. value:sub(at)
+`
+		g, err := NewScriptGenerator("KEEP_DOMAIN", script, m.Get)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := g.Generate("john.smith@example.org")
+		if !strings.HasSuffix(got, "@example.org") || got == "john.smith@example.org" {
+			t.Errorf("Generate() = %q, want new local part at example.org", got)
+		}
+		if got := g.Generate("no-at-sign"); !strings.Contains(got, "@") {
+			t.Errorf("Generate() = %q, want generated email", got)
+		}
+	})
+
+	t.Run("column binding", func(t *testing.T) {
+		g, err := NewScriptGenerator("COLUMN", "return schema_name .. '.' .. "+
+			"table_name .. '.' .. column", m.Get)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		bound := g.BindColumn(errors.ColumnRef{
+			Schema: "public", Table: "users", Column: "email"})
+		if got := bound.Generate("x"); got != "public.users.email" {
+			t.Errorf("Generate() = %q, want public.users.email", got)
+		}
+		if got := g.Generate("x"); got != ".." {
+			t.Errorf("unbound Generate() = %q, want ..", got)
+		}
+	})
+
+	t.Run("context binding", func(t *testing.T) {
+		g, err := NewScriptGenerator("CONTEXT", "return column_type .. "+
+			"':' .. max_length", m.Get)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		bound := g.BindContext(ColumnInfo{
+			Column:    errors.ColumnRef{Schema: "public", Table: "users", Column: "code"},
+			DataType:  "character varying",
+			MaxLength: 12,
+		})
+		if got := bound.Generate("x"); got != "character varying:12" {
+			t.Errorf("Generate() = %q, want character varying:12", got)
+		}
+	})
+
+	t.Run("compile error", func(t *testing.T) {
+		if _, err := NewScriptGenerator("BAD", "return (", m.Get); err == nil {
+			t.Error("expected error for invalid script")
+		}
+	})
+
+	t.Run("runtime errors are sticky", func(t *testing.T) {
+		g, err := NewScriptGenerator("FAIL", `error("boom")`, m.Get)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := g.Generate("x"); got != "" {
+			t.Errorf("Generate() = %q, want empty string on failure", got)
+		}
+		if g.Err() == nil {
+			t.Error("expected error after script failure")
+		}
+	})
+
+	t.Run("non-string result", func(t *testing.T) {
+		g, err := NewScriptGenerator("NIL", "return nil", m.Get)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		g.Generate("x")
+		if g.Err() == nil {
+			t.Error("expected error for nil result")
+		}
+	})
+
+	t.Run("unknown pattern", func(t *testing.T) {
+		g, err := NewScriptGenerator("UNKNOWN", "return fake.NO_SUCH(value)",
+			m.Get)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		g.Generate("x")
+		if g.Err() == nil {
+			t.Error("expected error for unknown pattern")
+		}
+	})
+
+	t.Run("sandbox", func(t *testing.T) {
+		for _, script := range []string{
+			`return io.open("/etc/passwd"):read("*a")`,
+			`return os.getenv("HOME")`,
+			`return dofile("/etc/passwd")`,
+		} {
+			g, err := NewScriptGenerator("SANDBOX", script, m.Get)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			g.Generate("x")
+			if g.Err() == nil {
+				t.Errorf("expected error for %s", script)
+			}
+		}
+	})
+}
+
+// TestManagerScriptPattern tests registering script patterns with the manager
+func TestManagerScriptPattern(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+
+	if err := m.RegisterScriptPattern(ScriptPatternConfig{
+		Name: "BAD", Script: "return ("}); err == nil {
+		t.Error("expected error for invalid script")
+	}
+
+	err := m.RegisterScriptPattern(ScriptPatternConfig{
+		Name:   "TAGGED",
+		Script: "return column .. ':' .. fake.US_SSN()",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := errors.ColumnRef{Schema: "public", Table: "people", Column: "ssn"}
+	g, ok := m.GetForColumn("TAGGED", col)
+	if !ok {
+		t.Fatal("script pattern not registered")
+	}
+	if got := g.Generate("123-45-6789"); !strings.HasPrefix(got, "ssn:") {
+		t.Errorf("Generate() = %q, want ssn: prefix", got)
+	}
+
+	// Generators that do not depend on the column are returned as is
+	email, _ := m.Get("EMAIL")
+	if g, _ := m.GetForColumn("EMAIL", col); g != email {
+		t.Error("expected unbound generator for EMAIL")
+	}
+
+	if err := m.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data"
+)
+
+// urlDomains are the second level domains reserved for examples (RFC
+// 2606), so that no generated URL points at a real site.
+var urlDomains = []string{"example.com", "example.org", "example.net"}
+
+// URLGenerator generates URLs.
+type URLGenerator struct {
+	BaseGenerator
+}
+
+// NewURLGenerator creates a new URL generator.
+func NewURLGenerator() *URLGenerator {
+	return &URLGenerator{
+		BaseGenerator: BaseGenerator{name: "URL"},
+	}
+}
+
+// Generate produces a URL shaped like the input. The scheme, port, number
+// of path segments, file extension, trailing slash, and query parameter
+// names are kept; the host is replaced with one of a reserved example
+// domain, and the user, path segments, query values, and fragment are
+// replaced with random characters of the same kinds and lengths. Input
+// without a scheme, such as "www.example.com/about", is kept without one.
+func (g *URLGenerator) Generate(input string) string {
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "" {
+		return "https://" + urlHost("") + "/"
+	}
+
+	noScheme := !strings.Contains(trimmed, "://")
+	if noScheme {
+		trimmed = "http://" + trimmed
+	}
+	u, err := url.Parse(trimmed)
+	if err != nil || u.Host == "" {
+		return "https://" + urlHost("") + "/" + replaceChars(
+			strings.TrimPrefix(trimmed, "http://"))
+	}
+
+	if u.User != nil {
+		u.User = url.User(replaceChars(u.User.Username()))
+	}
+	port := u.Port()
+	u.Host = urlHost(u.Hostname())
+	if port != "" {
+		u.Host += ":" + port
+	}
+	u.Path = replacePath(u.Path)
+	u.RawPath = ""
+
+	u.RawQuery = replaceQuery(u.RawQuery)
+	if u.Fragment != "" {
+		u.Fragment = replaceChars(u.Fragment)
+	}
+
+	result := u.String()
+	if noScheme {
+		return strings.TrimPrefix(result, "http://")
+	}
+	return result
+}
+
+// urlHost returns a host of a reserved example domain, keeping a www
+// prefix.
+func urlHost(original string) string {
+	domain := randomString(urlDomains)
+	if strings.HasPrefix(original, "www.") {
+		return "www." + domain
+	}
+	return domain
+}
+
+// replaceQuery replaces the values of the parameters of a query string
+// with random characters of the same kinds and lengths, keeping their
+// names and order.
+func replaceQuery(query string) string {
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		params[i] = name + "=" + url.QueryEscape(replaceChars(value))
+	}
+	return strings.Join(params, "&")
+}
+
+// replacePath replaces each segment of a URL path with random characters
+// of the same kinds and length, keeping the extension of the last.
+func replacePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		ext := ""
+		if i == len(segments)-1 {
+			ext = path.Ext(seg)
+		}
+		segments[i] = replaceChars(strings.TrimSuffix(seg, ext)) + ext
+	}
+	return strings.Join(segments, "/")
+}
+
+// replaceChars replaces each digit of s with a random digit, and each
+// ASCII letter with a random letter of the same case, keeping other
+// characters, such as separators, in place.
+func replaceChars(s string) string {
+	const lower = "abcdefghijklmnopqrstuvwxyz"
+	b := []byte(s)
+	for i, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			b[i] = randomDigit()
+		case c >= 'a' && c <= 'z':
+			b[i] = lower[randomInt(len(lower))]
+		case c >= 'A' && c <= 'Z':
+			b[i] = upperLetters[randomInt(len(upperLetters))]
+		}
+	}
+	return string(b)
+}
+
+// usernameStyle is the shape of a username or handle.
+type usernameStyle struct {
+	prefix string // A leading @, if any
+	sep    string // The separator between names, if any
+	digits int    // The number of digits it ends in
+	length int    // Its length, without the prefix
+	camel  bool   // Whether names start with capitals, as in JohnSmith
+}
+
+// styleOf returns the style of a username or handle, with separators
+// limited to those allowed.
+func styleOf(name, seps string) usernameStyle {
+	var s usernameStyle
+	if strings.HasPrefix(name, "@") {
+		s.prefix = "@"
+		name = name[1:]
+	}
+	s.length = len(name)
+	if i := strings.IndexAny(name, seps); i >= 0 && seps != "" {
+		s.sep = name[i : i+1]
+	}
+	for i := len(name) - 1; i >= 0 && name[i] >= '0' && name[i] <= '9'; i-- {
+		s.digits++
+	}
+	s.camel = name != strings.ToLower(name)
+	return s
+}
+
+// username returns a username built from random names in a style, of at
+// most maxLen characters without its prefix.
+func username(d *data.DataSet, style usernameStyle, maxLen int) string {
+	first := asciiLower(randomString(d.FirstNames), "user")
+	last := asciiLower(randomString(d.LastNames), "name")
+	if style.camel {
+		first = strings.ToUpper(first[:1]) + first[1:]
+		last = strings.ToUpper(last[:1]) + last[1:]
+	}
+
+	name := first + style.sep + last
+	if style.sep == "" && style.length > 0 && style.length <= 8 {
+		name = first[:1] + last
+	}
+
+	// Keep about the input's length, and within the limit
+	limit := maxLen - style.digits
+	if style.length > 0 {
+		limit = min(limit, max(style.length-style.digits, 3))
+	}
+	if len(name) > limit {
+		name = strings.TrimRight(name[:limit], style.sep)
+	}
+	return style.prefix + name + generateDigits(style.digits)
+}
+
+// asciiLower returns the ASCII letters of a name in lower case, or a
+// fallback if it has none.
+func asciiLower(name, fallback string) string {
+	var sb strings.Builder
+	for _, c := range strings.ToLower(name) {
+		if c >= 'a' && c <= 'z' {
+			sb.WriteRune(c)
+		}
+	}
+	if sb.Len() == 0 {
+		return fallback
+	}
+	return sb.String()
+}
+
+// UsernameGenerator generates usernames.
+type UsernameGenerator struct {
+	BaseGenerator
+	data *data.DataSet
+}
+
+// NewUsernameGenerator creates a new username generator.
+func NewUsernameGenerator(d *data.DataSet) *UsernameGenerator {
+	return &UsernameGenerator{
+		BaseGenerator: BaseGenerator{name: "USERNAME"},
+		data:          d,
+	}
+}
+
+// Generate produces a username from random names in the style of the
+// input: joined by its separator (".", "_" or "-"), or without one, as
+// an initial and last name for short usernames, in its case, ending in as
+// many digits, and no longer than the input unless it is shorter than 3
+// letters.
+func (g *UsernameGenerator) Generate(input string) string {
+	return username(g.data, styleOf(strings.TrimSpace(input), "._-"), 64)
+}
+
+// SocialHandleGenerator generates social media handles.
+type SocialHandleGenerator struct {
+	BaseGenerator
+	data   *data.DataSet
+	seps   string // Separators allowed
+	maxLen int    // Most characters, without the @
+}
+
+// NewSocialHandleGenerator creates a new social media handle generator.
+func NewSocialHandleGenerator(d *data.DataSet) *SocialHandleGenerator {
+	return &SocialHandleGenerator{
+		BaseGenerator: BaseGenerator{name: "SOCIAL_HANDLE"},
+		data:          d,
+		seps:          "._-",
+		maxLen:        30,
+	}
+}
+
+// NewTwitterHandleGenerator creates a new generator of X (Twitter)
+// handles: letters, digits and underscores, of at most 15 characters.
+func NewTwitterHandleGenerator(d *data.DataSet) *SocialHandleGenerator {
+	return &SocialHandleGenerator{
+		BaseGenerator: BaseGenerator{name: "TWITTER_HANDLE"},
+		data:          d,
+		seps:          "_",
+		maxLen:        15,
+	}
+}
+
+// NewGitHubHandleGenerator creates a new generator of GitHub usernames:
+// letters, digits and single hyphens, of at most 39 characters.
+func NewGitHubHandleGenerator(d *data.DataSet) *SocialHandleGenerator {
+	return &SocialHandleGenerator{
+		BaseGenerator: BaseGenerator{name: "GITHUB_HANDLE"},
+		data:          d,
+		seps:          "-",
+		maxLen:        39,
+	}
+}
+
+// Generate produces a handle from random names in the style of the input,
+// as for usernames, keeping a leading @, and using only the separators
+// and length the platform allows. A separator the platform does not allow
+// is replaced with one it does.
+func (g *SocialHandleGenerator) Generate(input string) string {
+	style := styleOf(strings.TrimSpace(input), "._-")
+	if style.sep != "" && !strings.Contains(g.seps, style.sep) {
+		style.sep = g.seps[:1]
+	}
+	return username(g.data, style, g.maxLen)
+}
@@ -0,0 +1,87 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data"
+)
+
+func TestURLGenerator(t *testing.T) {
+	g := NewURLGenerator()
+
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"https://www.acme.com/users/jsmith/profile.html",
+			`^https://www\.example\.(com|org|net)/[a-z]{5}/[a-z]{6}/[a-z]{7}\.html$`},
+		{"http://acme.com:8080/a/b/",
+			`^http://example\.(com|org|net):8080/[a-z]/[a-z]/$`},
+		{"https://acme.com/search?q=John+Smith&page=2#Top",
+			`^https://example\.(com|org|net)/[a-z]{6}\?q=[A-Z][a-z]{3}\+[A-Z][a-z]{4}&page=\d#[A-Z][a-z]{2}$`},
+		{"ftp://jsmith@files.acme.com/Report-2024.pdf",
+			`^ftp://[a-z]{6}@example\.(com|org|net)/[A-Z][a-z]{5}-\d{4}\.pdf$`},
+		{"acme.com/about", `^example\.(com|org|net)/[a-z]{5}$`},
+		{"", `^https://example\.(com|org|net)/$`},
+	}
+
+	for _, tt := range tests {
+		result := g.Generate(tt.input)
+		if !regexp.MustCompile(tt.pattern).MatchString(result) {
+			t.Errorf("%q: expected %s to match %s", tt.input, result,
+				tt.pattern)
+		}
+	}
+}
+
+func TestUsernameGenerators(t *testing.T) {
+	d := data.Load()
+
+	tests := []struct {
+		gen     Generator
+		input   string
+		pattern string
+	}{
+		{NewUsernameGenerator(d), "john.smith", `^[a-z]+\.?[a-z]*$`},
+		{NewUsernameGenerator(d), "jsmith42", `^[a-z]{3,6}\d{2}$`},
+		{NewUsernameGenerator(d), "JohnSmith", `^[A-Z][a-z]*[A-Z]?[a-z]*$`},
+		{NewSocialHandleGenerator(d), "@john_smith", `^@[a-z]+_?[a-z]*$`},
+		{NewTwitterHandleGenerator(d), "@john.smith.writes",
+			`^@[a-z]+_?[a-z]*$`},
+		{NewGitHubHandleGenerator(d), "john_smith_1", `^[a-z]+(-[a-z]+)?\d$`},
+	}
+
+	for _, tt := range tests {
+		for range 50 {
+			result := tt.gen.Generate(tt.input)
+			if !regexp.MustCompile(tt.pattern).MatchString(result) {
+				t.Fatalf("%s %q: expected %s to match %s", tt.gen.Name(),
+					tt.input, result, tt.pattern)
+			}
+			if len(result) > len(tt.input) {
+				t.Fatalf("%s %q: %s is longer", tt.gen.Name(), tt.input,
+					result)
+			}
+		}
+	}
+
+	twitter := NewTwitterHandleGenerator(d)
+	for range 50 {
+		if result := twitter.Generate(""); len(result) > 15 ||
+			strings.ContainsAny(result, ".-") {
+			t.Fatalf("invalid Twitter handle %s", result)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSuppressValue is the value sensitive categories are replaced with
+// by default.
+const DefaultSuppressValue = "Prefer not to say"
+
+// SuppressPatternConfig holds configuration for creating a suppressing
+// generator.
+type SuppressPatternConfig struct {
+	Name   string   // Pattern name (becomes generator name)
+	Values []string // Values chosen from, or empty for the default
+}
+
+// SuppressGenerator replaces every value with one of a small set of
+// neutral values, such as "Prefer not to say", for special-category data
+// such as religion or ethnicity, where realistic fake values would be
+// inappropriate. With a single value, nothing of the original category
+// remains.
+type SuppressGenerator struct {
+	BaseGenerator
+	values []string
+}
+
+// NewSuppressGenerator creates a suppressing generator.
+func NewSuppressGenerator(cfg SuppressPatternConfig) (*SuppressGenerator,
+	error) {
+
+	values := cfg.Values
+	if len(values) == 0 {
+		values = []string{DefaultSuppressValue}
+	}
+	for _, v := range values {
+		if v == "" {
+			return nil, fmt.Errorf("pattern %s: suppress values cannot be "+
+				"empty", cfg.Name)
+		}
+	}
+	return &SuppressGenerator{
+		BaseGenerator: BaseGenerator{name: cfg.Name},
+		values:        values,
+	}, nil
+}
+
+// Generate returns one of the values, chosen at random.
+func (g *SuppressGenerator) Generate(input string) string {
+	return randomString(g.values)
+}
+
+// Description lists the values categories are replaced with.
+func (g *SuppressGenerator) Description() string {
+	return "Categories replaced with " + strings.Join(g.values, " or ")
+}
+
+// Category returns the category of suppressing patterns.
+func (g *SuppressGenerator) Category() string {
+	return CategorySuppress
+}
@@ -0,0 +1,43 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import "testing"
+
+// TestSuppressGenerator tests replacing categories with neutral values
+func TestSuppressGenerator(t *testing.T) {
+	g, err := NewSuppressGenerator(SuppressPatternConfig{Name: "S"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, input := range []string{"Catholic", "Muslim", "None"} {
+		if got := g.Generate(input); got != DefaultSuppressValue {
+			t.Errorf("%q: got %q, want %q", input, got, DefaultSuppressValue)
+		}
+	}
+
+	values := []string{"Prefer not to say", "Unknown"}
+	g, err = NewSuppressGenerator(SuppressPatternConfig{Name: "S",
+		Values: values})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if got := g.Generate("Hindu"); !contains(values, got) {
+			t.Fatalf("unexpected value %q", got)
+		}
+	}
+
+	if _, err := NewSuppressGenerator(SuppressPatternConfig{Name: "S",
+		Values: []string{"Unknown", ""}}); err == nil {
+		t.Error("expected an error for an empty value")
+	}
+}
@@ -0,0 +1,232 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data"
+)
+
+// scrubPattern matches the personal data TEXT_SCRUB finds by its shape:
+// email addresses, US Social Security Numbers, and phone numbers with an
+// optional country code and area code in parentheses.
+var scrubPattern = regexp.MustCompile(
+	`(?P<email>[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,})` +
+		`|(?P<ssn>\b\d{3}-\d{2}-\d{4}\b)` +
+		`|(?P<phone>(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,5}\)[ .-]?|\b\d{2,5}[ .-])` +
+		`\d{3,4}[ .-]?\d{3,4}\b)`)
+
+// scrubWordPattern matches the words of a text that may be names.
+var scrubWordPattern = regexp.MustCompile(`\p{L}[\p{L}'’-]*`)
+
+// scrubTitles are the titles that come before a last name.
+var scrubTitles = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "miss": true, "mx": true,
+	"dr": true, "prof": true,
+}
+
+// scrubNameWords are first names that are also common words or places,
+// which are only taken as names when followed by a last name.
+var scrubNameWords = map[string]bool{
+	"april": true, "art": true, "august": true, "austin": true,
+	"bill": true, "carol": true, "chase": true, "dawn": true, "dean": true,
+	"don": true, "faith": true, "frank": true, "grace": true, "guy": true,
+	"holly": true, "hope": true, "ivy": true, "jack": true, "jordan": true,
+	"joy": true, "june": true, "lane": true, "lee": true, "mark": true,
+	"max": true, "may": true, "page": true, "pat": true, "ray": true,
+	"rose": true, "ruby": true, "summer": true, "will": true,
+}
+
+// TextScrubGenerator replaces the personal data embedded in free text,
+// such as notes and comments, leaving the rest of the text as it is.
+type TextScrubGenerator struct {
+	BaseGenerator
+	data       *data.DataSet
+	firstNames map[string]bool
+	email      Generator
+	ssn        Generator
+}
+
+// NewTextScrubGenerator creates a new free text scrubbing generator.
+func NewTextScrubGenerator(d *data.DataSet) *TextScrubGenerator {
+	g := &TextScrubGenerator{
+		BaseGenerator: BaseGenerator{name: "TEXT_SCRUB"},
+		data:          d,
+		firstNames:    make(map[string]bool, len(d.FirstNames)),
+		email:         NewEmailGenerator(d),
+		ssn:           NewSSNGenerator(),
+	}
+	for _, name := range d.FirstNames {
+		g.firstNames[strings.ToLower(name)] = true
+	}
+	return g
+}
+
+// scrubSpan is a part of a text to replace.
+type scrubSpan struct {
+	start, end  int
+	replacement string
+}
+
+// Generate returns the input with the email addresses, Social Security
+// Numbers, phone numbers, and names in it replaced, and the rest of the
+// text kept. Email addresses and SSNs are replaced as by the EMAIL and
+// US_SSN patterns, and phone numbers keep their format and any country
+// code. Names are found with a dictionary of first names: a capitalized
+// first name followed by a capitalized word, a title such as "Dr."
+// followed by one, or a first name alone, unless it is also a common
+// word, such as "Will". The same value appearing more than once in a text
+// is replaced with the same value each time.
+func (g *TextScrubGenerator) Generate(input string) string {
+	seen := make(map[string]string)
+	replace := func(kind, value string, generate func() string) string {
+		key := kind + ":" + value
+		if r, ok := seen[key]; ok {
+			return r
+		}
+		r := generate()
+		seen[key] = r
+		return r
+	}
+
+	var spans []scrubSpan
+	emailIdx := scrubPattern.SubexpIndex("email")
+	ssnIdx := scrubPattern.SubexpIndex("ssn")
+	for _, m := range scrubPattern.FindAllStringSubmatchIndex(input, -1) {
+		value := input[m[0]:m[1]]
+		var r string
+		switch {
+		case m[2*emailIdx] >= 0:
+			r = replace("email", value, func() string {
+				return g.email.Generate(value)
+			})
+		case m[2*ssnIdx] >= 0:
+			r = replace("ssn", value, func() string {
+				return g.ssn.Generate(value)
+			})
+		default:
+			r = replace("phone", value, func() string {
+				return scrubPhone(value)
+			})
+		}
+		spans = append(spans, scrubSpan{m[0], m[1], r})
+	}
+
+	// Names, in the words outside the spans already found
+	var words [][]int
+	next := 0
+	for _, w := range scrubWordPattern.FindAllStringIndex(input, -1) {
+		for next < len(spans) && spans[next].end <= w[0] {
+			next++
+		}
+		if next < len(spans) && spans[next].start < w[1] {
+			continue
+		}
+		words = append(words, w)
+	}
+
+	for i := 0; i < len(words); i++ {
+		word := input[words[i][0]:words[i][1]]
+		if !isCapitalized(word) {
+			continue
+		}
+		lower := strings.ToLower(word)
+
+		var following string
+		if i+1 < len(words) {
+			following = input[words[i+1][0]:words[i+1][1]]
+			gap := input[words[i][1]:words[i+1][0]]
+			if strings.Trim(gap, " \t") != "" &&
+				!(scrubTitles[lower] && strings.Trim(gap, ". \t") == "") {
+				following = ""
+			}
+		}
+
+		lastName := func() string {
+			return replace("last", following, func() string {
+				return matchCase(otherName(g.data.LastNames, following),
+					following)
+			})
+		}
+		switch {
+		case scrubTitles[lower] && isCapitalized(following):
+			spans = append(spans, scrubSpan{words[i+1][0], words[i+1][1],
+				lastName()})
+			i++
+		case g.firstNames[lower] && isCapitalized(following) &&
+			!scrubTitles[strings.ToLower(following)]:
+			spans = append(spans, g.firstName(words[i], word, replace),
+				scrubSpan{words[i+1][0], words[i+1][1], lastName()})
+			i++
+		case g.firstNames[lower] && !scrubNameWords[lower]:
+			spans = append(spans, g.firstName(words[i], word, replace))
+		}
+	}
+
+	if len(spans) == 0 {
+		return input
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	var sb strings.Builder
+	pos := 0
+	for _, s := range spans {
+		sb.WriteString(input[pos:s.start])
+		sb.WriteString(s.replacement)
+		pos = s.end
+	}
+	sb.WriteString(input[pos:])
+	return sb.String()
+}
+
+// firstName returns the span replacing a first name.
+func (g *TextScrubGenerator) firstName(loc []int, word string,
+	replace func(kind, value string, generate func() string) string) scrubSpan {
+	return scrubSpan{loc[0], loc[1], replace("first", word, func() string {
+		return matchCase(otherName(g.data.FirstNames, word), word)
+	})}
+}
+
+// otherName returns a random name other than the original.
+func otherName(names []string, original string) string {
+	for {
+		name := randomString(names)
+		if !strings.EqualFold(name, original) {
+			return name
+		}
+	}
+}
+
+// scrubPhone replaces the digits of a phone number, keeping its format
+// and any country code.
+func scrubPhone(phone string) string {
+	if strings.HasPrefix(phone, "+") {
+		end := strings.IndexAny(phone, " .-(")
+		if end < 0 {
+			end = min(len(phone), 3)
+		}
+		return phone[:end] + replaceDigits(phone[end:])
+	}
+	return replaceDigits(phone)
+}
+
+// isCapitalized reports whether a word starts with a capital letter.
+func isCapitalized(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
@@ -0,0 +1,93 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data"
+)
+
+func TestTextScrubGenerator(t *testing.T) {
+	g := NewTextScrubGenerator(data.Load())
+
+	t.Run("contact details", func(t *testing.T) {
+		input := "Call (555) 123-4567 or +44 20 7946 0958, email " +
+			"jane.doe@acme.com; SSN 123-45-6789."
+		result := g.Generate(input)
+
+		pattern := `^Call \(\d{3}\) \d{3}-\d{4} or \+44 \d{2} \d{4} \d{4}, ` +
+			`email \S+@\S+\.[a-z]+; SSN \d{3}-\d{2}-\d{4}\.$`
+		if !regexp.MustCompile(pattern).MatchString(result) {
+			t.Fatalf("expected %q to match %s", result, pattern)
+		}
+		for _, original := range []string{"123-4567", "7946 0958",
+			"jane.doe@acme.com", "123-45-6789"} {
+			if strings.Contains(result, original) {
+				t.Errorf("expected %s to be replaced in %q", original, result)
+			}
+		}
+	})
+
+	t.Run("names", func(t *testing.T) {
+		tests := []struct {
+			input    string
+			replaced []string
+			kept     string
+		}{
+			{"Spoke to John Kowalski today.", []string{"John", "Kowalski"},
+				"Spoke to "},
+			{"Referred by Dr. Okafor.", []string{"Okafor"}, "Referred by Dr. "},
+			{"Left a message for Sarah.", []string{"Sarah"},
+				"Left a message for "},
+			{"MARIA GARCIA asked for a refund.", []string{"MARIA", "GARCIA"},
+				" asked for a refund."},
+		}
+		for _, tt := range tests {
+			for range 20 {
+				result := g.Generate(tt.input)
+				if !strings.Contains(result, tt.kept) {
+					t.Fatalf("%q: expected %q to keep %q", tt.input, result,
+						tt.kept)
+				}
+				for _, name := range tt.replaced {
+					if regexp.MustCompile(`\b` + name + `\b`).
+						MatchString(result) {
+						t.Fatalf("%q: expected %s to be replaced in %q",
+							tt.input, name, result)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("text kept", func(t *testing.T) {
+		for _, input := range []string{
+			"Will review in May. Order total 1200 units on 2024-01-15.",
+			"no personal data here",
+			"",
+		} {
+			if result := g.Generate(input); result != input {
+				t.Errorf("expected %q to be kept, got %q", input, result)
+			}
+		}
+	})
+
+	t.Run("consistent within a value", func(t *testing.T) {
+		result := g.Generate("Sarah called. Sarah will call again.")
+		name, _, _ := strings.Cut(result, " ")
+		if strings.Count(result, name) != 2 {
+			t.Errorf("expected both names replaced alike, got %q", result)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data"
+)
+
+// userAgentPool is a list of User-Agent strings chosen in proportion to
+// their weights.
+type userAgentPool struct {
+	agents     []string
+	cumulative []int // Running total of the weights
+}
+
+// add adds a User-Agent string with a weight to the pool.
+func (p *userAgentPool) add(agent string, weight int) {
+	total := weight
+	if n := len(p.cumulative); n > 0 {
+		total += p.cumulative[n-1]
+	}
+	p.agents = append(p.agents, agent)
+	p.cumulative = append(p.cumulative, total)
+}
+
+// choose returns a User-Agent string chosen in proportion to its weight.
+func (p *userAgentPool) choose() string {
+	r := randomInt(p.cumulative[len(p.cumulative)-1])
+	return p.agents[sort.SearchInts(p.cumulative, r+1)]
+}
+
+// UserAgentGenerator generates browser User-Agent strings.
+type UserAgentGenerator struct {
+	BaseGenerator
+	desktop, mobile userAgentPool
+}
+
+// NewUserAgentGenerator creates a new User-Agent generator.
+func NewUserAgentGenerator(d *data.DataSet) *UserAgentGenerator {
+	g := &UserAgentGenerator{
+		BaseGenerator: BaseGenerator{name: "USER_AGENT"},
+	}
+	for _, ua := range d.UserAgents {
+		if isMobileUserAgent(ua.Value) {
+			g.mobile.add(ua.Value, ua.Weight)
+		} else {
+			g.desktop.add(ua.Value, ua.Weight)
+		}
+	}
+	return g
+}
+
+// Generate produces the User-Agent string of a current browser, chosen in
+// proportion to how common it is. The browser, version, and platform of
+// the input are replaced, as with the rest of a User-Agent string they can
+// help identify a user, but whether it is of a mobile device is kept, so
+// that the share of mobile traffic survives. Empty input is replaced with
+// a User-Agent string of either kind.
+func (g *UserAgentGenerator) Generate(input string) string {
+	trimmed := strings.TrimSpace(input)
+	switch {
+	case trimmed == "":
+		if randomInt(2) == 0 {
+			return g.mobile.choose()
+		}
+		return g.desktop.choose()
+	case isMobileUserAgent(trimmed):
+		return g.mobile.choose()
+	}
+	return g.desktop.choose()
+}
+
+// isMobileUserAgent reports whether a User-Agent string is of a phone or
+// tablet.
+func isMobileUserAgent(ua string) bool {
+	for _, token := range []string{"Mobi", "Android", "iPhone", "iPad"} {
+		if strings.Contains(ua, token) {
+			return true
+		}
+	}
+	return false
+}
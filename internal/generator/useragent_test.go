@@ -0,0 +1,71 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/generator/data"
+)
+
+func TestUserAgentGenerator(t *testing.T) {
+	d := data.Load()
+	g := NewUserAgentGenerator(d)
+
+	known := make(map[string]bool)
+	for _, ua := range d.UserAgents {
+		known[ua.Value] = true
+	}
+
+	tests := []struct {
+		input  string
+		mobile bool
+	}{
+		{"Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko",
+			false},
+		{"Mozilla/5.0 (Linux; Android 9; SM-G960F) AppleWebKit/537.36 " +
+			"(KHTML, like Gecko) Chrome/74.0.3729.157 Mobile Safari/537.36",
+			true},
+		{"curl/8.4.0", false},
+	}
+
+	for _, tt := range tests {
+		for range 50 {
+			result := g.Generate(tt.input)
+			if !known[result] {
+				t.Fatalf("%q: unexpected User-Agent %q", tt.input, result)
+			}
+			if isMobileUserAgent(result) != tt.mobile {
+				t.Fatalf("%q: expected mobile %v, got %q", tt.input,
+					tt.mobile, result)
+			}
+		}
+	}
+
+	// The most common User-Agent strings should come up most often
+	counts := make(map[string]int)
+	for range 2000 {
+		counts[g.Generate("")]++
+	}
+	windows, linux := 0, 0
+	for ua, n := range counts {
+		if strings.Contains(ua, "Windows") {
+			windows += n
+		} else if strings.Contains(ua, "X11") {
+			linux += n
+		}
+	}
+	if windows <= linux {
+		t.Errorf("expected Windows more often than Linux, got %d and %d",
+			windows, linux)
+	}
+}
@@ -0,0 +1,272 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// vinChars are the characters of VINs: digits and letters other than I, O
+// and Q, which could be mistaken for digits.
+const vinChars = "0123456789ABCDEFGHJKLMNPRSTUVWXYZ"
+
+// vinYears are the model year codes of VINs, in position 10.
+const vinYears = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// vinWMIs are world manufacturer identifiers of high volume manufacturers,
+// by their first character, which identifies the region of manufacture.
+var vinWMIs = map[byte][]string{
+	'1': {"1G1", "1FA", "1HG", "1N4", "1C4"},
+	'2': {"2HG", "2T1", "2FM"},
+	'3': {"3VW", "3FA", "3N1"},
+	'4': {"4T1", "4S3"},
+	'5': {"5YJ", "5NP", "5UX"},
+	'J': {"JHM", "JTD", "JN1", "JM1"},
+	'K': {"KMH", "KNA"},
+	'S': {"SAL", "SAJ", "SCC"},
+	'V': {"VF1", "VF3", "VSS"},
+	'W': {"WVW", "WBA", "WDB", "WAU", "WP0"},
+	'Y': {"YV1", "YS3"},
+	'Z': {"ZFA", "ZAR", "ZFF"},
+}
+
+// vinRegions are the keys of vinWMIs, for values of no known region.
+var vinRegions = "12345JKSVWYZ"
+
+// vinWeights are the weights of the positions of a VIN in its check digit.
+var vinWeights = []int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// VINGenerator generates vehicle identification numbers.
+type VINGenerator struct {
+	BaseGenerator
+}
+
+// NewVINGenerator creates a new VIN generator.
+func NewVINGenerator() *VINGenerator {
+	return &VINGenerator{
+		BaseGenerator: BaseGenerator{name: "VIN"},
+	}
+}
+
+// Generate produces a 17 character VIN (ISO 3779): the identifier of a
+// manufacturer in the same region as the input's, random vehicle
+// attributes, a check digit in position 9, the input's model year, and a
+// random plant and serial number. The check digit is that required in
+// North America, and is computed for all regions alike.
+func (g *VINGenerator) Generate(input string) string {
+	trimmed := strings.ToUpper(strings.TrimSpace(input))
+
+	region := vinRegions[randomInt(len(vinRegions))]
+	if trimmed != "" {
+		if _, ok := vinWMIs[trimmed[0]]; ok {
+			region = trimmed[0]
+		}
+	}
+	year := vinYears[randomInt(len(vinYears))]
+	if len(trimmed) == 17 && strings.IndexByte(vinYears, trimmed[9]) >= 0 {
+		year = trimmed[9]
+	}
+
+	vin := []byte(randomString(vinWMIs[region]))
+	for range 5 {
+		vin = append(vin, vinChars[randomInt(len(vinChars))])
+	}
+	vin = append(vin, '0', year, vinChars[randomInt(len(vinChars))])
+	vin = append(vin, generateDigits(6)...)
+	vin[8] = vinCheckDigit(string(vin))
+	return string(vin)
+}
+
+// vinCheckDigit returns the check digit of a VIN: the sum of the values
+// of its characters, letters transliterated to digits, weighted by
+// position, modulo 11, with 10 written as X.
+func vinCheckDigit(vin string) byte {
+	sum := 0
+	for i := 0; i < len(vin) && i < len(vinWeights); i++ {
+		sum += vinValue(vin[i]) * vinWeights[i]
+	}
+	if sum%11 == 10 {
+		return 'X'
+	}
+	return byte('0' + sum%11)
+}
+
+// vinValue returns the value of a VIN character in its check digit.
+func vinValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'A' && c <= 'I':
+		return int(c-'A') + 1
+	case c >= 'J' && c <= 'R':
+		return int(c-'J') + 1
+	case c >= 'S' && c <= 'Z':
+		return int(c-'S') + 2
+	}
+	return 0
+}
+
+// usPlateFormats are the formats of standard passenger plates of the most
+// populous states, with # for a digit and @ for a letter.
+var usPlateFormats = []string{
+	"#@@@###",  // California
+	"@@@-####", // New York, Texas, Pennsylvania, Ohio, Georgia
+	"@@@ @##",  // Florida
+	"@@ #####", // Illinois
+	"@@@ ####", // Michigan
+	"###-@@@",  // Arizona, North Carolina
+}
+
+// USLicensePlateGenerator generates US vehicle license plate numbers.
+type USLicensePlateGenerator struct {
+	BaseGenerator
+}
+
+// NewUSLicensePlateGenerator creates a new US license plate generator.
+func NewUSLicensePlateGenerator() *USLicensePlateGenerator {
+	return &USLicensePlateGenerator{
+		BaseGenerator: BaseGenerator{name: "US_LICENSE_PLATE"},
+	}
+}
+
+// Generate produces a US license plate number. As plate formats vary by
+// state and no state is known, the letters, digits and separators of the
+// input are kept in place, so a plate of any state is replaced with one
+// of the same format. Input without letters or digits is replaced with a
+// plate of a common state format.
+func (g *USLicensePlateGenerator) Generate(input string) string {
+	trimmed := strings.ToUpper(strings.TrimSpace(input))
+	if strings.IndexAny(trimmed, upperAlnum) < 0 {
+		return fillPlate(randomString(usPlateFormats))
+	}
+	return replacePlate(trimmed)
+}
+
+// fillPlate replaces each # of a plate format with a random digit and
+// each @ with a random letter other than I, O and Q.
+func fillPlate(format string) string {
+	b := []byte(format)
+	for i, c := range b {
+		switch c {
+		case '#':
+			b[i] = randomDigit()
+		case '@':
+			b[i] = plateLetter()
+		}
+	}
+	return string(b)
+}
+
+// replacePlate replaces each digit of a plate with a random digit, and
+// each letter with a random letter other than I, O and Q.
+func replacePlate(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			b[i] = randomDigit()
+		case c >= 'A' && c <= 'Z':
+			b[i] = plateLetter()
+		}
+	}
+	return string(b)
+}
+
+// plateLetter returns a random letter other than I, O and Q, which plates
+// avoid as they could be mistaken for digits.
+func plateLetter() byte {
+	return vinChars[10+randomInt(len(vinChars)-10)]
+}
+
+// ukPlatePattern matches current UK registrations, such as "AB12 CDE": a
+// memory tag, an age identifier, and three random letters.
+var ukPlatePattern = regexp.MustCompile(`^([A-Z]{2})(\d{2})(\s*)([A-Z]{3})$`)
+
+// ukMemoryTags are the first letters of the memory tags of UK
+// registrations, which identify the region of the issuing office.
+const ukMemoryTags = "ABCDEFGHKLMNOPRSVWY"
+
+// UKLicensePlateGenerator generates UK vehicle registration numbers.
+type UKLicensePlateGenerator struct {
+	BaseGenerator
+}
+
+// NewUKLicensePlateGenerator creates a new UK license plate generator.
+func NewUKLicensePlateGenerator() *UKLicensePlateGenerator {
+	return &UKLicensePlateGenerator{
+		BaseGenerator: BaseGenerator{name: "UK_LICENSE_PLATE"},
+	}
+}
+
+// Generate produces a UK registration number in the current format (AB12
+// CDE), keeping the input's age identifier, which gives the half year of
+// first registration, and its spacing. Registrations in older formats
+// keep the positions of their letters and digits.
+func (g *UKLicensePlateGenerator) Generate(input string) string {
+	trimmed := strings.ToUpper(strings.TrimSpace(input))
+
+	age, sep := "", " "
+	if m := ukPlatePattern.FindStringSubmatch(trimmed); m != nil {
+		age, sep = m[2], m[3]
+	} else if strings.IndexAny(trimmed, upperAlnum) >= 0 {
+		return replacePlate(trimmed)
+	} else {
+		// Registrations from March (the year) or September (the year
+		// plus 50) since 2001
+		age = fmt.Sprintf("%02d", 2+randomInt(24)+50*randomInt(2))
+	}
+
+	tag := string(ukMemoryTags[randomInt(len(ukMemoryTags))]) +
+		string(plateLetter())
+	return tag + age + sep + fillPlate("@@@")
+}
+
+// dePlatePattern matches German registrations, such as "M-AB 1234": a
+// district code, one or two letters, up to four digits, and an E for
+// electric or H for historic vehicles.
+var dePlatePattern = regexp.MustCompile(
+	`^(\p{Lu}{1,3})([- ]+)([A-Z]{1,2})(\s*)([1-9]\d{0,3})([EH]?)$`)
+
+// deDistricts are the district codes of the largest German cities.
+var deDistricts = []string{"B", "HH", "M", "K", "F", "S", "D", "DO", "E",
+	"L", "HB", "DD", "H", "N", "DU"}
+
+// DELicensePlateGenerator generates German vehicle registration numbers.
+type DELicensePlateGenerator struct {
+	BaseGenerator
+}
+
+// NewDELicensePlateGenerator creates a new German license plate generator.
+func NewDELicensePlateGenerator() *DELicensePlateGenerator {
+	return &DELicensePlateGenerator{
+		BaseGenerator: BaseGenerator{name: "DE_LICENSE_PLATE"},
+	}
+}
+
+// Generate produces a German registration number (M-AB 1234), keeping the
+// input's district code, separators, the number of its letters and
+// digits, and any E or H suffix. Input not in this format is replaced
+// with a registration of a large city.
+func (g *DELicensePlateGenerator) Generate(input string) string {
+	trimmed := strings.ToUpper(strings.TrimSpace(input))
+
+	m := dePlatePattern.FindStringSubmatch(trimmed)
+	if m == nil {
+		return randomString(deDistricts) + "-" + fillPlate("@@") + " " +
+			string(randomDigitNonZero()) + generateDigits(randomInt(4))
+	}
+
+	letters := fillPlate(strings.Repeat("@", len(m[3])))
+	number := string(randomDigitNonZero()) + generateDigits(len(m[5])-1)
+	return m[1] + m[2] + letters + m[4] + number + m[6]
+}
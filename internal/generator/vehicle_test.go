@@ -0,0 +1,92 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package generator
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestVINCheckDigit(t *testing.T) {
+	// Valid VINs from the NHTSA check digit examples
+	for _, vin := range []string{"1M8GDM9AXKP042788", "11111111111111111",
+		"1HGCM82633A004352"} {
+		if got := vinCheckDigit(vin); got != vin[8] {
+			t.Errorf("%s: expected check digit %c, got %c", vin, vin[8], got)
+		}
+	}
+}
+
+func TestVINGenerator(t *testing.T) {
+	g := NewVINGenerator()
+	re := regexp.MustCompile(`^[0-9A-HJ-NPR-Z]{17}$`)
+
+	tests := []struct {
+		input  string
+		region byte
+		year   byte
+	}{
+		{"1HGCM82633A004352", '1', '3'},
+		{"WVWZZZ1JZXW000001", 'W', 'X'},
+		{"jhmcm56557c404453", 'J', '7'},
+		{"", 0, 0},
+	}
+
+	for _, tt := range tests {
+		for range 50 {
+			result := g.Generate(tt.input)
+			if !re.MatchString(result) {
+				t.Fatalf("%q: invalid VIN %s", tt.input, result)
+			}
+			if vinCheckDigit(result) != result[8] {
+				t.Fatalf("%q: invalid check digit in %s", tt.input, result)
+			}
+			if tt.region != 0 && (result[0] != tt.region ||
+				result[9] != tt.year) {
+				t.Fatalf("%q: expected the region and model year kept in %s",
+					tt.input, result)
+			}
+		}
+	}
+}
+
+func TestLicensePlateGenerators(t *testing.T) {
+	tests := []struct {
+		gen     Generator
+		input   string
+		pattern string
+	}{
+		{NewUSLicensePlateGenerator(), "7ABC123", `^\d[A-Z]{3}\d{3}$`},
+		{NewUSLicensePlateGenerator(), "abc-1234", `^[A-Z]{3}-\d{4}$`},
+		{NewUSLicensePlateGenerator(), "",
+			`^(\d[A-Z]{3}\d{3}|[A-Z]{3}[- ]\d{4}|[A-Z]{3} [A-Z]\d{2}|` +
+				`[A-Z]{2} \d{5}|\d{3}-[A-Z]{3})$`},
+		{NewUKLicensePlateGenerator(), "AB12 CDE", `^[A-Z]{2}12 [A-Z]{3}$`},
+		{NewUKLicensePlateGenerator(), "ab62cde", `^[A-Z]{2}62[A-Z]{3}$`},
+		{NewUKLicensePlateGenerator(), "A123 BCD", `^[A-Z]\d{3} [A-Z]{3}$`},
+		{NewUKLicensePlateGenerator(), "", `^[A-Z]{2}\d{2} [A-Z]{3}$`},
+		{NewDELicensePlateGenerator(), "M-AB 1234", `^M-[A-Z]{2} [1-9]\d{3}$`},
+		{NewDELicensePlateGenerator(), "HH X 12E", `^HH [A-Z] [1-9]\dE$`},
+		{NewDELicensePlateGenerator(), "lö-a 1", `^LÖ-[A-Z] [1-9]$`},
+		{NewDELicensePlateGenerator(), "", `^[A-Z]{1,2}-[A-Z]{2} [1-9]\d{0,3}$`},
+	}
+
+	for _, tt := range tests {
+		re := regexp.MustCompile(tt.pattern)
+		for range 20 {
+			result := tt.gen.Generate(tt.input)
+			if !re.MatchString(result) {
+				t.Fatalf("%s %q: expected %s to match %s", tt.gen.Name(),
+					tt.input, result, tt.pattern)
+			}
+		}
+	}
+}
@@ -15,6 +15,7 @@ package jsonpath
 import (
 	"fmt"
 	"log"
+	"strconv"
 
 	"github.com/ohler55/ojg/jp"
 	"github.com/ohler55/ojg/oj"
@@ -37,8 +38,11 @@ func NewProcessor(quiet bool) *Processor {
 }
 
 // Extract finds all string values matching a JSON path expression.
-// For paths with wildcards (e.g., $.users[*].email), returns all matches.
-// Non-string values (objects, arrays, null) are skipped with a warning.
+// Besides plain dotted paths, expressions may use wildcards
+// ($.users[*].email), recursive descent ($..email), and filter predicates
+// ($.contacts[?(@.type == 'personal')].email). Each match is returned with
+// the concrete path to its location. Non-string values (objects, arrays)
+// are skipped with a warning; nulls are skipped silently.
 func (p *Processor) Extract(jsonData []byte, pathExpr string) ([]PathMatch, error) {
 	// Parse the JSON
 	data, err := oj.Parse(jsonData)
@@ -46,27 +50,35 @@ func (p *Processor) Extract(jsonData []byte, pathExpr string) ([]PathMatch, erro
 		return nil, fmt.Errorf("invalid JSON: %w", err)
 	}
 
+	matches, _, err := p.extract(data, pathExpr)
+	return matches, err
+}
+
+// extract finds all string values matching a JSON path expression in an
+// already parsed document. The second return value reports whether the
+// path resolved to any location at all, whatever the type of its value.
+func (p *Processor) extract(data any, pathExpr string) ([]PathMatch, bool, error) {
 	// Parse the JSON path expression
 	path, err := jp.ParseString(pathExpr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid JSON path %q: %w", pathExpr, err)
+		return nil, false, fmt.Errorf("invalid JSON path %q: %w", pathExpr, err)
 	}
 
-	// Get all matching values
-	results := path.Get(data)
-	if len(results) == 0 {
-		return nil, nil // No matches, not an error
+	// Resolve the expression to the concrete location of every match
+	locations := path.Locate(data, 0)
+	if len(locations) == 0 {
+		return nil, false, nil // No matches, not an error
 	}
 
 	var matches []PathMatch
-	for i, result := range results {
+	for _, loc := range locations {
+		result := loc.First(data)
+
 		// Only process string values
 		switch v := result.(type) {
 		case string:
-			// Build the concrete path for this match
-			concretePath := buildConcretePath(pathExpr, i, len(results))
 			matches = append(matches, PathMatch{
-				Path:  concretePath,
+				Path:  loc.String(),
 				Value: v,
 			})
 		case nil:
@@ -75,13 +87,13 @@ func (p *Processor) Extract(jsonData []byte, pathExpr string) ([]PathMatch, erro
 		default:
 			// Log warning for non-string types
 			if !p.quiet {
-				log.Printf("Warning: path %s[%d] contains %T, expected string, skipping",
-					pathExpr, i, result)
+				log.Printf("Warning: path %s contains %T, expected string, skipping",
+					loc.String(), result)
 			}
 		}
 	}
 
-	return matches, nil
+	return matches, true, nil
 }
 
 // Replace substitutes values in JSON data based on a replacement map.
@@ -120,16 +132,23 @@ func (p *Processor) Replace(jsonData []byte, replacements map[string]string) ([]
 
 // ExtractAndCollect extracts values from multiple paths and returns them
 // grouped by path expression. This is useful for processing multiple
-// json_paths on a single JSON value.
+// json_paths on a single JSON value; the document is parsed only once.
+// Every path that resolves to at least one location has an entry, which is
+// empty if none of the values found there are strings.
 func (p *Processor) ExtractAndCollect(jsonData []byte, pathExprs []string) (map[string][]PathMatch, error) {
+	data, err := oj.Parse(jsonData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
 	result := make(map[string][]PathMatch)
 
 	for _, pathExpr := range pathExprs {
-		matches, err := p.Extract(jsonData, pathExpr)
+		matches, found, err := p.extract(data, pathExpr)
 		if err != nil {
 			return nil, err
 		}
-		if len(matches) > 0 {
+		if found {
 			result[pathExpr] = matches
 		}
 	}
@@ -137,29 +156,43 @@ func (p *Processor) ExtractAndCollect(jsonData []byte, pathExprs []string) (map[
 	return result, nil
 }
 
-// buildConcretePath converts a wildcard path to a concrete path with an index.
-// For example, "$.users[*].email" with index 2 becomes "$.users[2].email"
-func buildConcretePath(pathExpr string, index int, total int) string {
-	// If there's only one result or no wildcard, return the original path
-	if total == 1 {
-		return pathExpr
+// PostgresPath converts a concrete JSON path expression into the text[]
+// path form used by the PostgreSQL #>, #>> and jsonb_set operators. For
+// example, "$.users[0].email" becomes ["users", "0", "email"].
+// The second return value is false if the expression is not concrete, i.e.
+// it contains wildcards, filters, slices, unions, recursive descent, or
+// negative indexes, since those cannot be addressed by a single text[] path.
+func PostgresPath(pathExpr string) ([]string, bool) {
+	path, err := jp.ParseString(pathExpr)
+	if err != nil {
+		return nil, false
 	}
 
-	// Replace the first [*] with the concrete index
-	// This is a simplified approach - for deeply nested wildcards,
-	// we might need more sophisticated tracking
-	result := make([]byte, 0, len(pathExpr)+10)
-	replaced := false
-
-	for i := 0; i < len(pathExpr); i++ {
-		if !replaced && i+2 < len(pathExpr) && pathExpr[i:i+3] == "[*]" {
-			result = append(result, fmt.Sprintf("[%d]", index)...)
-			i += 2 // Skip past [*]
-			replaced = true
-		} else {
-			result = append(result, pathExpr[i])
+	var elems []string
+	for i, frag := range path {
+		switch f := frag.(type) {
+		case jp.Root:
+			if i != 0 {
+				return nil, false
+			}
+		case jp.Bracket:
+			// Notation marker only, e.g. $['name']
+			continue
+		case jp.Child:
+			elems = append(elems, string(f))
+		case jp.Nth:
+			if f < 0 {
+				return nil, false
+			}
+			elems = append(elems, strconv.Itoa(int(f)))
+		default:
+			return nil, false
 		}
 	}
 
-	return string(result)
+	if len(elems) == 0 {
+		return nil, false
+	}
+
+	return elems, true
 }
@@ -80,6 +80,39 @@ func TestExtract(t *testing.T) {
 			path:    "invalid path",
 			wantErr: true,
 		},
+		{
+			name: "nested array wildcards",
+			json: `{"groups": [{"users": [{"email": "a@test.com"}, {"email": "b@test.com"}]}, {"users": [{"email": "c@test.com"}]}]}`,
+			path: "$.groups[*].users[*].email",
+			expected: []PathMatch{
+				{Path: "$.groups[0].users[0].email", Value: "a@test.com"},
+				{Path: "$.groups[0].users[1].email", Value: "b@test.com"},
+				{Path: "$.groups[1].users[0].email", Value: "c@test.com"},
+			},
+		},
+		{
+			name: "recursive descent",
+			json: `{"contacts": [{"email": "a@test.com", "manager": {"email": "m@test.com"}}]}`,
+			path: "$..email",
+			expected: []PathMatch{
+				{Path: "$.contacts[0].email", Value: "a@test.com"},
+				{Path: "$.contacts[0].manager.email", Value: "m@test.com"},
+			},
+		},
+		{
+			name: "filter expression",
+			json: `{"contacts": [{"type": "work", "email": "w@test.com"}, {"type": "personal", "email": "p@test.com"}]}`,
+			path: `$.contacts[?(@.type == "personal")].email`,
+			expected: []PathMatch{
+				{Path: "$.contacts[1].email", Value: "p@test.com"},
+			},
+		},
+		{
+			name:     "filter with no matches",
+			json:     `{"contacts": [{"type": "work", "email": "w@test.com"}]}`,
+			path:     `$.contacts[?(@.type == "personal")].email`,
+			expected: nil,
+		},
 		{
 			name: "simple array values",
 			json: `{"tags": ["tag1", "tag2", "tag3"]}`,
@@ -262,26 +295,65 @@ func TestExtractAndCollect(t *testing.T) {
 	}
 }
 
-func TestBuildConcretePath(t *testing.T) {
+func TestExtractAndCollectFound(t *testing.T) {
+	json := `{"email": null, "contacts": [{"name": "John"}]}`
+
+	p := NewProcessor(true)
+
+	paths := []string{"$.email", "$.contacts", "$.phone"}
+
+	result, err := p.ExtractAndCollect([]byte(json), paths)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Paths that resolve to non-string values have an empty entry
+	for _, path := range []string{"$.email", "$.contacts"} {
+		matches, ok := result[path]
+		if !ok {
+			t.Errorf("expected entry for %s", path)
+		} else if len(matches) != 0 {
+			t.Errorf("expected no matches for %s, got %d", path, len(matches))
+		}
+	}
+
+	// Paths that resolve to nothing have no entry
+	if _, ok := result["$.phone"]; ok {
+		t.Error("expected no entry for $.phone")
+	}
+}
+
+func TestPostgresPath(t *testing.T) {
 	tests := []struct {
 		pathExpr string
-		index    int
-		total    int
-		want     string
+		want     []string
+		ok       bool
 	}{
-		{"$.email", 0, 1, "$.email"},
-		{"$.users[*].email", 0, 2, "$.users[0].email"},
-		{"$.users[*].email", 1, 2, "$.users[1].email"},
-		{"$.data[*]", 5, 10, "$.data[5]"},
-		{"$.a[*].b[*].c", 0, 3, "$.a[0].b[*].c"}, // Only first wildcard replaced
+		{"$.email", []string{"email"}, true},
+		{"$.user.email", []string{"user", "email"}, true},
+		{"$.users[0].email", []string{"users", "0", "email"}, true},
+		{"$['first name']", []string{"first name"}, true},
+		{"$.users[*].email", nil, false},
+		{"$..email", nil, false},
+		{"$.users[-1].email", nil, false},
+		{"$", nil, false},
+		{"not a path", nil, false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.pathExpr, func(t *testing.T) {
-			got := buildConcretePath(tt.pathExpr, tt.index, tt.total)
-			if got != tt.want {
-				t.Errorf("buildConcretePath(%q, %d, %d) = %q, want %q",
-					tt.pathExpr, tt.index, tt.total, got, tt.want)
+			got, ok := PostgresPath(tt.pathExpr)
+			if ok != tt.ok {
+				t.Fatalf("PostgresPath(%q) ok = %v, want %v", tt.pathExpr, ok, tt.ok)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("PostgresPath(%q) = %v, want %v", tt.pathExpr, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("PostgresPath(%q)[%d] = %q, want %q",
+						tt.pathExpr, i, got[i], tt.want[i])
+				}
 			}
 		})
 	}
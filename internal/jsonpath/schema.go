@@ -0,0 +1,238 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaExtension is the JSON Schema keyword naming the pattern used to
+// anonymize the values a subschema describes, e.g. "x-anonymize": "EMAIL".
+const SchemaExtension = "x-anonymize"
+
+// SchemaPath is a JSON path derived from an annotated JSON Schema.
+type SchemaPath struct {
+	Path    string
+	Pattern string
+}
+
+// identifierRe matches property names that can use dot notation.
+var identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// PathsFromSchema walks a JSON Schema and returns a JSON path for every
+// subschema annotated with SchemaExtension. The document is the decoded
+// schema file; pointer is a JSON pointer (e.g. "/components/schemas/User")
+// to the schema describing the column, or "" for the whole document.
+// Local $ref references are followed, with a recursive reference expanded
+// only until it refers back to itself; properties, items, tuple items,
+// additionalProperties, patternProperties, and the allOf, anyOf and oneOf
+// combinators are all walked. Paths are returned in a stable order.
+func PathsFromSchema(document any, pointer string) ([]SchemaPath, error) {
+	schema, err := resolvePointer(document, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &schemaWalker{
+		document: document,
+		active:   make(map[string]bool),
+		patterns: make(map[string]string),
+	}
+	if err := w.walk(schema, "$"); err != nil {
+		return nil, err
+	}
+
+	return w.paths, nil
+}
+
+// schemaWalker holds the state of a single PathsFromSchema call.
+type schemaWalker struct {
+	document any
+	active   map[string]bool   // $refs being walked, to break cycles
+	patterns map[string]string // path -> pattern, to detect conflicts
+	paths    []SchemaPath
+}
+
+// walk collects annotated paths from a subschema located at path.
+func (w *schemaWalker) walk(node any, path string) error {
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return nil // Boolean schemas and malformed entries have no paths
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if err := w.walkRef(ref, path); err != nil {
+			return err
+		}
+	}
+
+	if pattern, ok := schema[SchemaExtension]; ok {
+		name, ok := pattern.(string)
+		if !ok || name == "" {
+			return fmt.Errorf("%s at %s must be a pattern name", SchemaExtension,
+				path)
+		}
+		if err := w.add(path, name); err != nil {
+			return err
+		}
+	}
+
+	if props, ok := schema["properties"].(map[string]any); ok {
+		keys := make([]string, 0, len(props))
+		for key := range props {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := w.walk(props[key], path+childSegment(key)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if props, ok := schema["patternProperties"].(map[string]any); ok {
+		keys := make([]string, 0, len(props))
+		for key := range props {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := w.walk(props[key], path+".*"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := w.walk(schema["additionalProperties"], path+".*"); err != nil {
+		return err
+	}
+
+	// items is a single schema, or a list of tuple schemas in older drafts
+	switch items := schema["items"].(type) {
+	case map[string]any:
+		if err := w.walk(items, path+"[*]"); err != nil {
+			return err
+		}
+	case []any:
+		for i, item := range items {
+			if err := w.walk(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if prefix, ok := schema["prefixItems"].([]any); ok {
+		for i, item := range prefix {
+			if err := w.walk(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		if subs, ok := schema[keyword].([]any); ok {
+			for _, sub := range subs {
+				if err := w.walk(sub, path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// walkRef follows a local $ref, skipping references already being walked
+// so that recursive schemas terminate.
+func (w *schemaWalker) walkRef(ref, path string) error {
+	if !strings.HasPrefix(ref, "#") {
+		return fmt.Errorf("unsupported $ref %q at %s: only references within "+
+			"the same document are supported", ref, path)
+	}
+	if w.active[ref] {
+		return nil
+	}
+
+	target, err := resolvePointer(w.document, strings.TrimPrefix(ref, "#"))
+	if err != nil {
+		return fmt.Errorf("invalid $ref at %s: %w", path, err)
+	}
+
+	w.active[ref] = true
+	defer delete(w.active, ref)
+
+	return w.walk(target, path)
+}
+
+// add records an annotated path, rejecting conflicting patterns for the
+// same path (e.g. from different oneOf branches).
+func (w *schemaWalker) add(path, pattern string) error {
+	if existing, ok := w.patterns[path]; ok {
+		if existing != pattern {
+			return fmt.Errorf("conflicting %s patterns for %s: %s and %s",
+				SchemaExtension, path, existing, pattern)
+		}
+		return nil
+	}
+
+	w.patterns[path] = pattern
+	w.paths = append(w.paths, SchemaPath{Path: path, Pattern: pattern})
+	return nil
+}
+
+// childSegment returns the path segment selecting an object member.
+func childSegment(key string) string {
+	if identifierRe.MatchString(key) {
+		return "." + key
+	}
+	escaped := strings.ReplaceAll(key, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `'`, `\'`)
+	return "['" + escaped + "']"
+}
+
+// resolvePointer returns the value a JSON pointer (RFC 6901) refers to.
+func resolvePointer(document any, pointer string) (any, error) {
+	if pointer == "" {
+		return document, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("JSON pointer %q must start with '/'", pointer)
+	}
+
+	node := document
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(token, "~1", "/")
+		token = strings.ReplaceAll(token, "~0", "~")
+
+		switch n := node.(type) {
+		case map[string]any:
+			next, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+			}
+			node = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+		}
+	}
+
+	return node, nil
+}
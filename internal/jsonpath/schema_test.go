@@ -0,0 +1,176 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPathsFromSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		pointer string
+		want    []SchemaPath
+		wantErr bool
+	}{
+		{
+			name: "nested properties",
+			schema: `{
+                "type": "object",
+                "properties": {
+                    "email": {"type": "string", "x-anonymize": "EMAIL"},
+                    "age": {"type": "integer"},
+                    "address": {
+                        "type": "object",
+                        "properties": {
+                            "street": {"type": "string", "x-anonymize": "ADDRESS"}
+                        }
+                    }
+                }
+            }`,
+			want: []SchemaPath{
+				{Path: "$.address.street", Pattern: "ADDRESS"},
+				{Path: "$.email", Pattern: "EMAIL"},
+			},
+		},
+		{
+			name: "arrays and maps",
+			schema: `{
+                "properties": {
+                    "contacts": {
+                        "type": "array",
+                        "items": {
+                            "properties": {"phone": {"x-anonymize": "US_PHONE"}}
+                        }
+                    },
+                    "pair": {"prefixItems": [{"x-anonymize": "PERSON_NAME"}]},
+                    "aliases": {"additionalProperties": {"x-anonymize": "EMAIL"}}
+                }
+            }`,
+			want: []SchemaPath{
+				{Path: "$.aliases.*", Pattern: "EMAIL"},
+				{Path: "$.contacts[*].phone", Pattern: "US_PHONE"},
+				{Path: "$.pair[0]", Pattern: "PERSON_NAME"},
+			},
+		},
+		{
+			name: "refs and combinators",
+			schema: `{
+                "components": {
+                    "schemas": {
+                        "Contact": {
+                            "properties": {"email": {"x-anonymize": "EMAIL"}}
+                        },
+                        "User": {
+                            "allOf": [{"$ref": "#/components/schemas/Contact"}],
+                            "properties": {
+                                "manager": {"$ref": "#/components/schemas/User"},
+                                "ssn": {"x-anonymize": "US_SSN"}
+                            }
+                        }
+                    }
+                }
+            }`,
+			pointer: "/components/schemas/User",
+			want: []SchemaPath{
+				{Path: "$.manager.ssn", Pattern: "US_SSN"},
+				{Path: "$.manager.email", Pattern: "EMAIL"},
+				{Path: "$.ssn", Pattern: "US_SSN"},
+				{Path: "$.email", Pattern: "EMAIL"},
+			},
+		},
+		{
+			name: "non-identifier property name",
+			schema: `{
+                "properties": {"home email": {"x-anonymize": "EMAIL"}}
+            }`,
+			want: []SchemaPath{
+				{Path: "$['home email']", Pattern: "EMAIL"},
+			},
+		},
+		{
+			name: "conflicting patterns",
+			schema: `{
+                "oneOf": [
+                    {"properties": {"id": {"x-anonymize": "US_SSN"}}},
+                    {"properties": {"id": {"x-anonymize": "UK_NINO"}}}
+                ]
+            }`,
+			wantErr: true,
+		},
+		{
+			name:    "external ref",
+			schema:  `{"properties": {"a": {"$ref": "other.json#/a"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing pointer",
+			schema:  `{"properties": {}}`,
+			pointer: "/components/schemas/User",
+			wantErr: true,
+		},
+		{
+			name:    "non-string annotation",
+			schema:  `{"properties": {"a": {"x-anonymize": true}}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc any
+			if err := json.Unmarshal([]byte(tt.schema), &doc); err != nil {
+				t.Fatalf("invalid test schema: %v", err)
+			}
+
+			got, err := PathsFromSchema(doc, tt.pointer)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathsFromSchemaExtract(t *testing.T) {
+	// Derived paths must be usable by Extract, including quoted members
+	var doc any
+	schema := `{"properties": {"it's": {"x-anonymize": "EMAIL"}}}`
+	if err := json.Unmarshal([]byte(schema), &doc); err != nil {
+		t.Fatalf("invalid test schema: %v", err)
+	}
+
+	paths, err := PathsFromSchema(doc, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p := NewProcessor(true)
+	matches, err := p.Extract([]byte(`{"it's": "a@test.com"}`), paths[0].Path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Value != "a@test.com" {
+		t.Errorf("unexpected matches for %s: %v", paths[0].Path, matches)
+	}
+}
@@ -0,0 +1,337 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package jsonpath
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/ohler55/ojg/jp"
+)
+
+// segmentKind identifies the type of a compiled path segment.
+type segmentKind int
+
+const (
+	segChild    segmentKind = iota // Object member by name
+	segIndex                       // Array element by position
+	segWildcard                    // Any member or element
+	segDescent                     // Zero or more levels (..)
+)
+
+// streamSegment is one step of a path compiled for streaming evaluation.
+type streamSegment struct {
+	kind  segmentKind
+	key   string
+	index int
+}
+
+// streamPath is a path expression compiled for streaming evaluation.
+type streamPath struct {
+	expr     string
+	segments []streamSegment
+}
+
+// pathElem is one step in the location of the value being read.
+type pathElem struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// compileStreamPath compiles a path expression for streaming evaluation.
+// Only expressions that can be decided from the location of a value alone
+// are streamable; filters, slices, unions, and negative indexes need the
+// surrounding document and return false.
+func compileStreamPath(pathExpr string) (streamPath, bool) {
+	path, err := jp.ParseString(pathExpr)
+	if err != nil {
+		return streamPath{}, false
+	}
+
+	sp := streamPath{expr: pathExpr}
+	for i, frag := range path {
+		switch f := frag.(type) {
+		case jp.Root:
+			if i != 0 {
+				return streamPath{}, false
+			}
+		case jp.Bracket:
+			continue
+		case jp.Child:
+			sp.segments = append(sp.segments,
+				streamSegment{kind: segChild, key: string(f)})
+		case jp.Nth:
+			if f < 0 {
+				return streamPath{}, false
+			}
+			sp.segments = append(sp.segments,
+				streamSegment{kind: segIndex, index: int(f)})
+		case jp.Wildcard:
+			sp.segments = append(sp.segments, streamSegment{kind: segWildcard})
+		case jp.Descent:
+			sp.segments = append(sp.segments, streamSegment{kind: segDescent})
+		default:
+			return streamPath{}, false
+		}
+	}
+
+	return sp, true
+}
+
+// matches reports whether the compiled path selects the given location.
+func (sp streamPath) matches(loc []pathElem) bool {
+	return matchSegments(sp.segments, loc)
+}
+
+// matchSegments matches path segments against a location, backtracking
+// over recursive descent.
+func matchSegments(segs []streamSegment, loc []pathElem) bool {
+	if len(segs) == 0 {
+		return len(loc) == 0
+	}
+
+	seg := segs[0]
+	if seg.kind == segDescent {
+		for i := 0; i <= len(loc); i++ {
+			if matchSegments(segs[1:], loc[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(loc) == 0 {
+		return false
+	}
+
+	elem := loc[0]
+	switch seg.kind {
+	case segChild:
+		if elem.isIndex || elem.key != seg.key {
+			return false
+		}
+	case segIndex:
+		if !elem.isIndex || elem.index != seg.index {
+			return false
+		}
+	}
+
+	return matchSegments(segs[1:], loc[1:])
+}
+
+// CanStream reports whether every path expression can be evaluated by
+// StreamReplace.
+func CanStream(pathExprs []string) bool {
+	for _, expr := range pathExprs {
+		if _, ok := compileStreamPath(expr); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// StreamResult summarizes a StreamReplace call.
+type StreamResult struct {
+	Replaced int             // Number of string values replaced
+	Matched  map[string]bool // Path expressions that resolved to any value
+}
+
+// StreamReplace copies a JSON document from r to w, replacing each string
+// value selected by one of the path expressions with the result of the
+// replace callback, which receives the matching expression and the
+// original value. Unlike Extract and Replace, the document is processed
+// token by token and never held in memory as a tree, so that, beyond what
+// r and w hold, memory use stays proportional to the largest single value.
+// Insignificant whitespace is not preserved.
+func (p *Processor) StreamReplace(r io.Reader, w io.Writer, pathExprs []string,
+	replace func(pathExpr, value string) string) (*StreamResult, error) {
+
+	paths := make([]streamPath, len(pathExprs))
+	for i, expr := range pathExprs {
+		sp, ok := compileStreamPath(expr)
+		if !ok {
+			return nil, fmt.Errorf("JSON path %q cannot be evaluated in streaming mode",
+				expr)
+		}
+		paths[i] = sp
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	s := &streamer{
+		processor: p,
+		dec:       dec,
+		out:       bufio.NewWriter(w),
+		paths:     paths,
+		replace:   replace,
+		matched:   make(map[string]bool),
+	}
+
+	if err := s.value(nil); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+
+	if err := s.out.Flush(); err != nil {
+		return nil, err
+	}
+
+	return &StreamResult{Replaced: s.count, Matched: s.matched}, nil
+}
+
+// streamer holds the state of a single StreamReplace call.
+type streamer struct {
+	processor *Processor
+	dec       *json.Decoder
+	out       *bufio.Writer
+	paths     []streamPath
+	replace   func(pathExpr, value string) string
+	count     int
+	matched   map[string]bool
+	scratch   bytes.Buffer
+}
+
+// match returns the first path expression selecting the location.
+func (s *streamer) match(loc []pathElem) (string, bool) {
+	for _, sp := range s.paths {
+		if sp.matches(loc) {
+			return sp.expr, true
+		}
+	}
+	return "", false
+}
+
+// record notes every path expression that selects the location.
+func (s *streamer) record(loc []pathElem) {
+	for _, sp := range s.paths {
+		if !s.matched[sp.expr] && sp.matches(loc) {
+			s.matched[sp.expr] = true
+		}
+	}
+}
+
+// warnNonString logs a warning when a path selects a non-string value,
+// matching the behavior of Extract.
+func (s *streamer) warnNonString(loc []pathElem, kind string) {
+	if s.processor.quiet {
+		return
+	}
+	if expr, ok := s.match(loc); ok {
+		log.Printf("Warning: path %s matched %s, expected string, skipping",
+			expr, kind)
+	}
+}
+
+// value reads one JSON value at the given location and writes it out,
+// recursing into objects and arrays.
+func (s *streamer) value(loc []pathElem) error {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	s.record(loc)
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			s.warnNonString(loc, "an object")
+			s.out.WriteByte('{')
+			first := true
+			for s.dec.More() {
+				keyTok, err := s.dec.Token()
+				if err != nil {
+					return err
+				}
+				key, ok := keyTok.(string)
+				if !ok {
+					return fmt.Errorf("expected object key, got %v", keyTok)
+				}
+				if !first {
+					s.out.WriteByte(',')
+				}
+				first = false
+				if err := s.writeString(key); err != nil {
+					return err
+				}
+				s.out.WriteByte(':')
+				if err := s.value(append(loc, pathElem{key: key})); err != nil {
+					return err
+				}
+			}
+			if _, err := s.dec.Token(); err != nil {
+				return err
+			}
+			s.out.WriteByte('}')
+		case '[':
+			s.warnNonString(loc, "an array")
+			s.out.WriteByte('[')
+			for i := 0; s.dec.More(); i++ {
+				if i > 0 {
+					s.out.WriteByte(',')
+				}
+				elem := pathElem{index: i, isIndex: true}
+				if err := s.value(append(loc, elem)); err != nil {
+					return err
+				}
+			}
+			if _, err := s.dec.Token(); err != nil {
+				return err
+			}
+			s.out.WriteByte(']')
+		default:
+			return fmt.Errorf("unexpected delimiter %v", t)
+		}
+	case string:
+		if expr, ok := s.match(loc); ok {
+			t = s.replace(expr, t)
+			s.count++
+		}
+		return s.writeString(t)
+	case json.Number:
+		s.warnNonString(loc, "a number")
+		s.out.WriteString(t.String())
+	case bool:
+		s.warnNonString(loc, "a boolean")
+		if t {
+			s.out.WriteString("true")
+		} else {
+			s.out.WriteString("false")
+		}
+	case nil:
+		// Skip null values silently
+		s.out.WriteString("null")
+	}
+
+	return nil
+}
+
+// writeString writes a JSON-encoded string without HTML escaping.
+func (s *streamer) writeString(v string) error {
+	s.scratch.Reset()
+	enc := json.NewEncoder(&s.scratch)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	// Encode appends a newline
+	_, err := s.out.Write(bytes.TrimRight(s.scratch.Bytes(), "\n"))
+	return err
+}
@@ -0,0 +1,224 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCanStream(t *testing.T) {
+	tests := []struct {
+		paths []string
+		want  bool
+	}{
+		{[]string{"$.email"}, true},
+		{[]string{"$.users[*].email", "$.users[0].phone"}, true},
+		{[]string{"$..email"}, true},
+		{[]string{"$.a.*"}, true},
+		{[]string{"$.email", `$.contacts[?(@.type == "personal")].email`}, false},
+		{[]string{"$.users[1:3].email"}, false},
+		{[]string{"$.users[-1].email"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanStream(tt.paths); got != tt.want {
+			t.Errorf("CanStream(%v) = %v, want %v", tt.paths, got, tt.want)
+		}
+	}
+}
+
+func TestStreamReplace(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		paths     []string
+		wantJSON  string
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:      "simple field",
+			json:      `{"email": "old@test.com", "age": 42}`,
+			paths:     []string{"$.email"},
+			wantJSON:  `{"email":"X","age":42}`,
+			wantCount: 1,
+		},
+		{
+			name:      "array wildcard",
+			json:      `{"users": [{"email": "a@test.com"}, {"email": "b@test.com"}]}`,
+			paths:     []string{"$.users[*].email"},
+			wantJSON:  `{"users":[{"email":"X"},{"email":"X"}]}`,
+			wantCount: 2,
+		},
+		{
+			name:      "specific index",
+			json:      `{"users": [{"email": "a@test.com"}, {"email": "b@test.com"}]}`,
+			paths:     []string{"$.users[1].email"},
+			wantJSON:  `{"users":[{"email":"a@test.com"},{"email":"X"}]}`,
+			wantCount: 1,
+		},
+		{
+			name:      "recursive descent",
+			json:      `{"email": "a", "nested": {"deep": [{"email": "b"}]}}`,
+			paths:     []string{"$..email"},
+			wantJSON:  `{"email":"X","nested":{"deep":[{"email":"X"}]}}`,
+			wantCount: 2,
+		},
+		{
+			name:      "non-string values untouched",
+			json:      `{"email": null, "phone": 5551234, "tags": ["a"], "ok": true}`,
+			paths:     []string{"$.email", "$.phone", "$.tags"},
+			wantJSON:  `{"email":null,"phone":5551234,"tags":["a"],"ok":true}`,
+			wantCount: 0,
+		},
+		{
+			name:      "top-level string",
+			json:      `"secret"`,
+			paths:     []string{"$..*"},
+			wantJSON:  `"secret"`,
+			wantCount: 0,
+		},
+		{
+			name:    "invalid json",
+			json:    `{"email": `,
+			paths:   []string{"$.email"},
+			wantErr: true,
+		},
+		{
+			name:    "trailing data",
+			json:    `{"email": "a"} {}`,
+			paths:   []string{"$.email"},
+			wantErr: true,
+		},
+		{
+			name:    "filter not streamable",
+			json:    `{"email": "a"}`,
+			paths:   []string{`$[?(@.email == "a")]`},
+			wantErr: true,
+		},
+	}
+
+	p := NewProcessor(true)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			res, err := p.StreamReplace(strings.NewReader(tt.json), &out,
+				tt.paths, func(pathExpr, value string) string { return "X" })
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if out.String() != tt.wantJSON {
+				t.Errorf("got %s, want %s", out.String(), tt.wantJSON)
+			}
+			if res.Replaced != tt.wantCount {
+				t.Errorf("count = %d, want %d", res.Replaced, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestStreamReplaceMatched(t *testing.T) {
+	doc := `{"email": null, "phone": 5551234, "users": [{"name": "a"}]}`
+	paths := []string{"$.email", "$.phone", "$.users[*].name", "$.missing",
+		"$..ssn"}
+
+	p := NewProcessor(true)
+
+	var out bytes.Buffer
+	res, err := p.StreamReplace(strings.NewReader(doc), &out, paths,
+		func(pathExpr, value string) string { return "X" })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]bool{
+		"$.email":         true,
+		"$.phone":         true,
+		"$.users[*].name": true,
+	}
+	for _, path := range paths {
+		if res.Matched[path] != want[path] {
+			t.Errorf("Matched[%s] = %v, want %v", path, res.Matched[path],
+				want[path])
+		}
+	}
+}
+
+func TestStreamReplaceMatchesReplace(t *testing.T) {
+	doc := `{
+        "email": "test@example.com",
+        "note": "<b>keep & escape</b>",
+        "contacts": [
+            {"name": "John", "email": "john@test.com", "score": 1.50},
+            {"name": "Jane", "email": "jane@test.com", "score": 2e3}
+        ]
+    }`
+	paths := []string{"$.email", "$.contacts[*].name"}
+
+	p := NewProcessor(true)
+
+	var out bytes.Buffer
+	_, err := p.StreamReplace(strings.NewReader(doc), &out, paths,
+		func(pathExpr, value string) string { return strings.ToUpper(value) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The same replacements made via the tree-based API
+	matches, err := p.ExtractAndCollect([]byte(doc), paths)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replacements := make(map[string]string)
+	for _, ms := range matches {
+		for _, m := range ms {
+			replacements[m.Path] = strings.ToUpper(m.Value)
+		}
+	}
+	want, err := p.Replace([]byte(doc), replacements)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var gotObj, wantObj interface{}
+	if err := json.Unmarshal(out.Bytes(), &gotObj); err != nil {
+		t.Fatalf("streamed output is not valid JSON: %v", err)
+	}
+	if err := json.Unmarshal(want, &wantObj); err != nil {
+		t.Fatalf("replaced output is not valid JSON: %v", err)
+	}
+	gotNorm, _ := json.Marshal(gotObj)
+	wantNorm, _ := json.Marshal(wantObj)
+	if string(gotNorm) != string(wantNorm) {
+		t.Errorf("streamed %s, want %s", gotNorm, wantNorm)
+	}
+
+	// Numbers are copied verbatim and HTML is not escaped
+	if !strings.Contains(out.String(), `"score":1.50`) {
+		t.Errorf("expected number to be preserved verbatim: %s", out.String())
+	}
+	if !strings.Contains(out.String(), `"<b>keep & escape</b>"`) {
+		t.Errorf("expected string to be written without HTML escaping: %s",
+			out.String())
+	}
+}
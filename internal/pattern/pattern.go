@@ -12,12 +12,17 @@
 package pattern
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"gopkg.in/yaml.v3"
 
+	pgedgeanonymizer "github.com/pgedge/pgedge-anonymizer"
 	"github.com/pgedge/pgedge-anonymizer/internal/errors"
 )
 
@@ -36,6 +41,214 @@ type Pattern struct {
 	Max     int64  `yaml:"max,omitempty"`      // Maximum value for number type
 	MinYear int    `yaml:"min_year,omitempty"` // Minimum year for date type
 	MaxYear int    `yaml:"max_year,omitempty"` // Maximum year for date type
+
+	// External command pattern fields (optional)
+	// When Exec is set, values are piped through the command instead of
+	// using Replacement as a generator name.
+	Exec string   `yaml:"exec,omitempty"` // Command to run
+	Args []string `yaml:"args,omitempty"` // Command arguments
+
+	// Script pattern field (optional)
+	// When Script is set, values are generated by running the Lua script.
+	Script string `yaml:"script,omitempty"`
+
+	// Format preserving encryption pattern field (optional)
+	// When FPE is set, the digits and letters of values are encrypted in
+	// place with FF3-1, so that they can be decrypted with the key.
+	FPE *FPEConfig `yaml:"fpe,omitempty"`
+
+	// Keyed hash pattern field (optional)
+	// When Hash is set, values are replaced with one-way pseudonyms
+	// derived from their HMAC-SHA256 under a secret key.
+	Hash *HashConfig `yaml:"hash,omitempty"`
+
+	// Redaction pattern field (optional)
+	// When Redact is set, the letters and digits of values are masked,
+	// keeping part of the original value.
+	Redact *RedactConfig `yaml:"redact,omitempty"`
+
+	// Suppression pattern field (optional)
+	// When Suppress is set, values are replaced with one of a small set
+	// of neutral values, such as "Prefer not to say".
+	Suppress *SuppressConfig `yaml:"suppress,omitempty"`
+
+	// Epoch timestamp pattern field (optional)
+	// When Epoch is set, integer Unix timestamps, in seconds or
+	// milliseconds, are shifted or regenerated within a window of dates.
+	Epoch *EpochConfig `yaml:"epoch,omitempty"`
+
+	// Geolocation pattern field (optional)
+	// When Geo is set, coordinates are generated within a bounding box,
+	// or moved by a random distance from the original ones.
+	Geo *GeoConfig `yaml:"geo,omitempty"`
+
+	// IP address pattern field (optional)
+	// When IP is set, the network prefix of IP addresses is kept and the
+	// rest of their bits replaced.
+	IP *IPConfig `yaml:"ip,omitempty"`
+
+	// Laplace noise pattern field (optional)
+	// When Noise is set, numbers are given noise drawn from the Laplace
+	// distribution, for differential privacy.
+	Noise *NoiseConfig `yaml:"noise,omitempty"`
+}
+
+// FPEConfig configures a format preserving encryption pattern. The AES
+// key, of 128, 192 or 256 bits in hexadecimal, is read from an environment
+// variable or a file, so that it is kept out of the pattern file.
+type FPEConfig struct {
+	KeyEnv  string `yaml:"key_env,omitempty"`  // Variable holding the key
+	KeyFile string `yaml:"key_file,omitempty"` // File holding the key
+	Tweak   string `yaml:"tweak,omitempty"`    // 56-bit tweak in hexadecimal
+}
+
+// HashConfig configures a keyed hash pattern. The secret key is read from
+// an environment variable or a file, so that it is kept out of the
+// pattern file.
+type HashConfig struct {
+	KeyEnv  string `yaml:"key_env,omitempty"`  // Variable holding the key
+	KeyFile string `yaml:"key_file,omitempty"` // File holding the key
+
+	// Length is the number of hexadecimal digits of the hash kept, from 1
+	// to 64; the default is 16.
+	Length int `yaml:"length,omitempty"`
+
+	// Format is a mask, such as "CUST-########", whose placeholders are
+	// filled from the hash instead.
+	Format string `yaml:"format,omitempty"`
+
+	// CaseInsensitive hashes values differing only in case the same.
+	CaseInsensitive bool `yaml:"case_insensitive,omitempty"`
+}
+
+// RedactConfig configures a redaction pattern, which masks the letters and
+// digits of values rather than replacing them, keeping those at the start
+// and end, and separators such as spaces and dashes.
+type RedactConfig struct {
+	KeepFirst  int    `yaml:"keep_first,omitempty"`  // Leading characters kept
+	KeepLast   int    `yaml:"keep_last,omitempty"`   // Trailing characters kept
+	KeepDomain bool   `yaml:"keep_domain,omitempty"` // Keep email domains
+	Char       string `yaml:"char,omitempty"`        // Mask character; "*"
+}
+
+// SuppressConfig configures a suppression pattern, which replaces the
+// values of sensitive categorical columns with neutral values rather than
+// realistic ones.
+type SuppressConfig struct {
+	// Values are the values chosen from; the default is "Prefer not to
+	// say".
+	Values []string `yaml:"values,omitempty"`
+}
+
+// EpochConfig configures an epoch timestamp pattern. In "shift" mode, the
+// default, values are shifted by up to ShiftDays days either way; in
+// "generate" mode, they are replaced with times between From and To.
+// Values are kept between From and To whenever they are set.
+type EpochConfig struct {
+	Mode      string `yaml:"mode,omitempty"`       // "shift" or "generate"
+	ShiftDays int    `yaml:"shift_days,omitempty"` // Most days shifted; 365
+	From      string `yaml:"from,omitempty"`       // Earliest date, YYYY-MM-DD
+	To        string `yaml:"to,omitempty"`         // Latest date, YYYY-MM-DD
+}
+
+// validateEpoch returns the problem with an epoch pattern's settings.
+func validateEpoch(e *EpochConfig) error {
+	switch e.Mode {
+	case "", "shift":
+	case "generate":
+		if e.From == "" || e.To == "" {
+			return fmt.Errorf("epoch mode 'generate' requires 'from' and " +
+				"'to'")
+		}
+	default:
+		return fmt.Errorf("epoch mode must be 'shift' or 'generate', got %q",
+			e.Mode)
+	}
+	if e.ShiftDays < 0 {
+		return fmt.Errorf("epoch shift_days cannot be negative")
+	}
+	for _, date := range []string{e.From, e.To} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			return fmt.Errorf("epoch date %q must be in YYYY-MM-DD format",
+				date)
+		}
+	}
+	return nil
+}
+
+// GeoConfig configures a geolocation pattern, for latitudes, longitudes,
+// points, or geohashes in degrees of WGS 84. Values are generated
+// anywhere within the bounding box, or, with a jitter radius, moved by up
+// to that distance from the original and kept within the box.
+type GeoConfig struct {
+	Type         string    `yaml:"type,omitempty"`          // "point" (default), "latitude", "longitude" or "geohash"
+	BoundingBox  []float64 `yaml:"bounding_box,omitempty"`  // Min lat, min lon, max lat, max lon
+	JitterMeters float64   `yaml:"jitter_meters,omitempty"` // Most meters moved
+}
+
+// validateGeo returns the problem with a geolocation pattern's settings.
+func validateGeo(g *GeoConfig) error {
+	switch g.Type {
+	case "", "point", "latitude", "longitude", "geohash":
+	default:
+		return fmt.Errorf("geo type must be 'point', 'latitude', "+
+			"'longitude' or 'geohash', got %q", g.Type)
+	}
+	if g.JitterMeters < 0 {
+		return fmt.Errorf("geo jitter_meters cannot be negative")
+	}
+	if box := g.BoundingBox; len(box) > 0 {
+		if len(box) != 4 {
+			return fmt.Errorf("geo bounding_box must have 4 values: min " +
+				"latitude, min longitude, max latitude, max longitude")
+		}
+		if box[0] < -90 || box[2] > 90 || box[1] < -180 || box[3] > 180 ||
+			box[0] > box[2] || box[1] > box[3] {
+			return fmt.Errorf("geo bounding_box %v is not a valid area", box)
+		}
+	}
+	return nil
+}
+
+// IPConfig configures an IP address pattern, which keeps the leading bits
+// of IPv4 and IPv6 addresses, their network prefix, and replaces the rest.
+type IPConfig struct {
+	IPv4Prefix int `yaml:"ipv4_prefix,omitempty"` // Bits kept, 0 to 32; 16
+	IPv6Prefix int `yaml:"ipv6_prefix,omitempty"` // Bits kept, 0 to 128; 48
+}
+
+// validateIP returns the problem with an IP address pattern's settings.
+func validateIP(ip *IPConfig) error {
+	if ip.IPv4Prefix < 0 || ip.IPv4Prefix > 32 {
+		return fmt.Errorf("ip ipv4_prefix must be between 0 and 32, got %d",
+			ip.IPv4Prefix)
+	}
+	if ip.IPv6Prefix < 0 || ip.IPv6Prefix > 128 {
+		return fmt.Errorf("ip ipv6_prefix must be between 0 and 128, got %d",
+			ip.IPv6Prefix)
+	}
+	return nil
+}
+
+// NoiseConfig configures a Laplace noise pattern, which adds noise of a
+// scale of sensitivity / epsilon to numbers, so that each value is
+// epsilon-differentially private with respect to a change of up to the
+// sensitivity.
+type NoiseConfig struct {
+	Epsilon     float64 `yaml:"epsilon"`                // Privacy budget of a value
+	Sensitivity float64 `yaml:"sensitivity"`            // Change to hide
+	NonNegative bool    `yaml:"non_negative,omitempty"` // Keep values >= 0
+}
+
+// validateNoise returns the problem with a noise pattern's settings.
+func validateNoise(n *NoiseConfig) error {
+	if n.Epsilon <= 0 {
+		return fmt.Errorf("noise epsilon must be greater than 0")
+	}
+	if n.Sensitivity <= 0 {
+		return fmt.Errorf("noise sensitivity must be greater than 0")
+	}
+	return nil
 }
 
 // IsFormatPattern returns true if this pattern uses format-based generation.
@@ -43,6 +256,62 @@ func (p Pattern) IsFormatPattern() bool {
 	return p.Format != ""
 }
 
+// IsScriptPattern returns true if this pattern uses a Lua script.
+func (p Pattern) IsScriptPattern() bool {
+	return p.Script != ""
+}
+
+// IsExecPattern returns true if this pattern uses an external command.
+func (p Pattern) IsExecPattern() bool {
+	return p.Exec != ""
+}
+
+// IsFPEPattern returns true if this pattern uses format preserving
+// encryption.
+func (p Pattern) IsFPEPattern() bool {
+	return p.FPE != nil
+}
+
+// IsHashPattern returns true if this pattern uses keyed hashes.
+func (p Pattern) IsHashPattern() bool {
+	return p.Hash != nil
+}
+
+// IsRedactPattern returns true if this pattern masks values.
+func (p Pattern) IsRedactPattern() bool {
+	return p.Redact != nil
+}
+
+// IsSuppressPattern returns true if this pattern replaces values with
+// neutral ones.
+func (p Pattern) IsSuppressPattern() bool {
+	return p.Suppress != nil
+}
+
+// IsEpochPattern returns true if this pattern anonymizes epoch
+// timestamps.
+func (p Pattern) IsEpochPattern() bool {
+	return p.Epoch != nil
+}
+
+// IsGeoPattern returns true if this pattern anonymizes geographic
+// coordinates.
+func (p Pattern) IsGeoPattern() bool {
+	return p.Geo != nil
+}
+
+// IsIPPattern returns true if this pattern anonymizes IP addresses keeping
+// their network prefix.
+func (p Pattern) IsIPPattern() bool {
+	return p.IP != nil
+}
+
+// IsNoisePattern returns true if this pattern adds Laplace noise to
+// numbers.
+func (p Pattern) IsNoisePattern() bool {
+	return p.Noise != nil
+}
+
 // PatternFile represents the YAML file structure.
 type PatternFile struct {
 	Patterns []Pattern `yaml:"patterns"`
@@ -90,6 +359,10 @@ func (r *Registry) Count() int {
 	return len(r.patterns)
 }
 
+// EmbeddedPath is the default patterns path that selects the default
+// patterns embedded in the binary, rather than a file.
+const EmbeddedPath = "embedded"
+
 // Loader handles loading and merging pattern files.
 type Loader struct{}
 
@@ -98,14 +371,25 @@ func NewLoader() *Loader {
 	return &Loader{}
 }
 
-// LoadFile loads patterns from a YAML file.
+// LoadFile loads patterns from a YAML file. The path EmbeddedPath loads
+// the default patterns embedded in the binary.
 func (l *Loader) LoadFile(path string) (*PatternFile, error) {
+	if path == EmbeddedPath {
+		return l.LoadData(path, pgedgeanonymizer.DefaultPatterns)
+	}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, errors.NewPatternError("",
 			fmt.Sprintf("failed to read pattern file %s", path), err)
 	}
 
+	return l.LoadData(path, data)
+}
+
+// LoadData loads patterns from the YAML content of a file; path is used
+// in error messages.
+func (l *Loader) LoadData(path string, data []byte) (*PatternFile, error) {
 	var pf PatternFile
 	if err := yaml.Unmarshal(data, &pf); err != nil {
 		return nil, errors.NewPatternError("",
@@ -118,10 +402,96 @@ func (l *Loader) LoadFile(path string) (*PatternFile, error) {
 			return nil, errors.NewPatternError("",
 				fmt.Sprintf("pattern in %s has empty name", path), nil)
 		}
-		// Either Replacement OR Format must be specified
-		if p.Replacement == "" && p.Format == "" {
+		// One of Replacement, Format, Exec, Script, FPE, Hash, Redact,
+		// Suppress, Epoch, Geo, IP or Noise must be specified, and only one
+		// of the last eleven
+		generators := 0
+		for _, field := range []string{p.Format, p.Exec, p.Script} {
+			if field != "" {
+				generators++
+			}
+		}
+		for _, set := range []bool{p.IsFPEPattern(), p.IsHashPattern(),
+			p.IsRedactPattern(), p.IsSuppressPattern(), p.IsEpochPattern(),
+			p.IsGeoPattern(), p.IsIPPattern(), p.IsNoisePattern()} {
+			if set {
+				generators++
+			}
+		}
+		if p.Replacement == "" && generators == 0 {
+			return nil, errors.NewPatternError(p.Name,
+				"pattern must have a 'replacement', 'format', 'exec', "+
+					"'script', 'fpe', 'hash', 'redact', 'suppress', "+
+					"'epoch', 'geo', 'ip' or 'noise' field", nil)
+		}
+		if generators > 1 {
+			return nil, errors.NewPatternError(p.Name,
+				"pattern can only have one of 'format', 'exec', 'script', "+
+					"'fpe', 'hash', 'redact', 'suppress', 'epoch', 'geo', "+
+					"'ip' and 'noise' fields",
+				nil)
+		}
+		if p.IsFPEPattern() && (p.FPE.KeyEnv == "") == (p.FPE.KeyFile == "") {
+			return nil, errors.NewPatternError(p.Name,
+				"fpe pattern must have one of 'key_env' and 'key_file'", nil)
+		}
+		if p.IsFPEPattern() && p.FPE.Tweak != "" {
+			if tweak, err := hex.DecodeString(p.FPE.Tweak); err != nil ||
+				len(tweak) != 7 {
+				return nil, errors.NewPatternError(p.Name,
+					"fpe tweak must be 7 bytes (14 hexadecimal digits)", nil)
+			}
+		}
+		if p.IsHashPattern() {
+			if (p.Hash.KeyEnv == "") == (p.Hash.KeyFile == "") {
+				return nil, errors.NewPatternError(p.Name,
+					"hash pattern must have one of 'key_env' and 'key_file'",
+					nil)
+			}
+			if p.Hash.Length < 0 || p.Hash.Length > 64 {
+				return nil, errors.NewPatternError(p.Name,
+					"hash length must be between 1 and 64", nil)
+			}
+			if p.Hash.Length != 0 && p.Hash.Format != "" {
+				return nil, errors.NewPatternError(p.Name,
+					"hash pattern can only have one of 'length' and 'format'",
+					nil)
+			}
+		}
+		if p.IsRedactPattern() {
+			if p.Redact.KeepFirst < 0 || p.Redact.KeepLast < 0 {
+				return nil, errors.NewPatternError(p.Name,
+					"redact keep_first and keep_last cannot be negative", nil)
+			}
+			if p.Redact.Char != "" &&
+				utf8.RuneCountInString(p.Redact.Char) != 1 {
+				return nil, errors.NewPatternError(p.Name,
+					"redact char must be a single character", nil)
+			}
+		}
+		if p.IsSuppressPattern() && slices.Contains(p.Suppress.Values, "") {
 			return nil, errors.NewPatternError(p.Name,
-				"pattern must have either 'replacement' or 'format' field", nil)
+				"suppress values cannot be empty", nil)
+		}
+		if p.IsEpochPattern() {
+			if err := validateEpoch(p.Epoch); err != nil {
+				return nil, errors.NewPatternError(p.Name, err.Error(), nil)
+			}
+		}
+		if p.IsGeoPattern() {
+			if err := validateGeo(p.Geo); err != nil {
+				return nil, errors.NewPatternError(p.Name, err.Error(), nil)
+			}
+		}
+		if p.IsIPPattern() {
+			if err := validateIP(p.IP); err != nil {
+				return nil, errors.NewPatternError(p.Name, err.Error(), nil)
+			}
+		}
+		if p.IsNoisePattern() {
+			if err := validateNoise(p.Noise); err != nil {
+				return nil, errors.NewPatternError(p.Name, err.Error(), nil)
+			}
 		}
 	}
 
@@ -199,6 +199,552 @@ patterns:
 			t.Error("expected error for empty replacement")
 		}
 	})
+
+	t.Run("exec pattern", func(t *testing.T) {
+		content := `
+patterns:
+  - name: TOKENIZE
+    exec: /usr/local/bin/tokenize
+    args: ["--realm", "test"]
+`
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "exec.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+
+		pf, err := loader.LoadFile(path)
+		if err != nil {
+			t.Fatalf("failed to load file: %v", err)
+		}
+
+		p := pf.Patterns[0]
+		if !p.IsExecPattern() {
+			t.Error("expected exec pattern")
+		}
+		if p.Exec != "/usr/local/bin/tokenize" || len(p.Args) != 2 {
+			t.Errorf("unexpected exec %q args %v", p.Exec, p.Args)
+		}
+	})
+
+	t.Run("exec and format", func(t *testing.T) {
+		content := `
+patterns:
+  - name: TEST
+    exec: /usr/local/bin/tokenize
+    format: "###"
+`
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "exec_format.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+
+		_, err := loader.LoadFile(path)
+		if err == nil {
+			t.Error("expected error for exec with format")
+		}
+	})
+
+	t.Run("script pattern", func(t *testing.T) {
+		content := `
+patterns:
+  - name: KEEP_DOMAIN
+    script: |
+      local at = value:find("@")
+      return fake.PERSON_FIRST_NAME() .. value:sub(at)
+`
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "script.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+
+		pf, err := loader.LoadFile(path)
+		if err != nil {
+			t.Fatalf("failed to load file: %v", err)
+		}
+		if !pf.Patterns[0].IsScriptPattern() {
+			t.Error("expected script pattern")
+		}
+	})
+
+	t.Run("fpe pattern", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for name, tt := range map[string]struct {
+			content string
+			wantErr bool
+		}{
+			"key env": {`
+patterns:
+  - name: ACCOUNT_FPE
+    fpe:
+      key_env: ACCOUNT_KEY
+      tweak: CBD09280979564
+`, false},
+			"no key": {`
+patterns:
+  - name: ACCOUNT_FPE
+    fpe:
+      tweak: CBD09280979564
+`, true},
+			"two keys": {`
+patterns:
+  - name: ACCOUNT_FPE
+    fpe:
+      key_env: ACCOUNT_KEY
+      key_file: /etc/account.key
+`, true},
+			"short tweak": {`
+patterns:
+  - name: ACCOUNT_FPE
+    fpe:
+      key_env: ACCOUNT_KEY
+      tweak: CBD092
+`, true},
+			"fpe and format": {`
+patterns:
+  - name: ACCOUNT_FPE
+    format: "###"
+    fpe:
+      key_env: ACCOUNT_KEY
+`, true},
+		} {
+			path := filepath.Join(tmpDir, "fpe.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			pf, err := loader.LoadFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("%s: expected error", name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: failed to load file: %v", name, err)
+			}
+			if !pf.Patterns[0].IsFPEPattern() ||
+				pf.Patterns[0].FPE.KeyEnv != "ACCOUNT_KEY" {
+				t.Errorf("%s: unexpected pattern %+v", name, pf.Patterns[0])
+			}
+		}
+	})
+
+	t.Run("hash pattern", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for name, tt := range map[string]struct {
+			content string
+			wantErr bool
+		}{
+			"format": {`
+patterns:
+  - name: CUSTOMER_HASH
+    hash:
+      key_env: CUSTOMER_KEY
+      format: "CUST-########"
+      case_insensitive: true
+`, false},
+			"no key": {`
+patterns:
+  - name: CUSTOMER_HASH
+    hash:
+      length: 12
+`, true},
+			"long length": {`
+patterns:
+  - name: CUSTOMER_HASH
+    hash:
+      key_env: CUSTOMER_KEY
+      length: 65
+`, true},
+			"length and format": {`
+patterns:
+  - name: CUSTOMER_HASH
+    hash:
+      key_env: CUSTOMER_KEY
+      length: 12
+      format: "CUST-####"
+`, true},
+			"hash and fpe": {`
+patterns:
+  - name: CUSTOMER_HASH
+    hash:
+      key_env: CUSTOMER_KEY
+    fpe:
+      key_env: CUSTOMER_KEY
+`, true},
+		} {
+			path := filepath.Join(tmpDir, "hash.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			pf, err := loader.LoadFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("%s: expected error", name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: failed to load file: %v", name, err)
+			}
+			if !pf.Patterns[0].IsHashPattern() ||
+				!pf.Patterns[0].Hash.CaseInsensitive {
+				t.Errorf("%s: unexpected pattern %+v", name, pf.Patterns[0])
+			}
+		}
+	})
+
+	t.Run("redact pattern", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for name, tt := range map[string]struct {
+			content string
+			wantErr bool
+		}{
+			"keep last": {`
+patterns:
+  - name: CARD_LAST4
+    redact:
+      keep_last: 4
+      char: "#"
+`, false},
+			"negative keep": {`
+patterns:
+  - name: CARD_LAST4
+    redact:
+      keep_last: -4
+`, true},
+			"long char": {`
+patterns:
+  - name: CARD_LAST4
+    redact:
+      keep_last: 4
+      char: "##"
+`, true},
+			"redact and format": {`
+patterns:
+  - name: CARD_LAST4
+    format: "####"
+    redact:
+      keep_last: 4
+`, true},
+		} {
+			path := filepath.Join(tmpDir, "redact.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			pf, err := loader.LoadFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("%s: expected error", name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: failed to load file: %v", name, err)
+			}
+			if !pf.Patterns[0].IsRedactPattern() ||
+				pf.Patterns[0].Redact.KeepLast != 4 {
+				t.Errorf("%s: unexpected pattern %+v", name, pf.Patterns[0])
+			}
+		}
+	})
+
+	t.Run("suppress pattern", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for name, tt := range map[string]struct {
+			content string
+			wantErr bool
+		}{
+			"default values": {`
+patterns:
+  - name: RELIGION
+    suppress: {}
+`, false},
+			"values": {`
+patterns:
+  - name: RELIGION
+    suppress:
+      values: ["Prefer not to say", "Unknown"]
+`, false},
+			"empty value": {`
+patterns:
+  - name: RELIGION
+    suppress:
+      values: ["Unknown", ""]
+`, true},
+			"suppress and redact": {`
+patterns:
+  - name: RELIGION
+    suppress: {}
+    redact:
+      keep_first: 1
+`, true},
+		} {
+			path := filepath.Join(tmpDir, "suppress.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			pf, err := loader.LoadFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("%s: expected error", name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: failed to load file: %v", name, err)
+			}
+			if !pf.Patterns[0].IsSuppressPattern() {
+				t.Errorf("%s: unexpected pattern %+v", name, pf.Patterns[0])
+			}
+		}
+	})
+
+	t.Run("epoch pattern", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for name, tt := range map[string]struct {
+			content string
+			wantErr bool
+		}{
+			"default shift": {`
+patterns:
+  - name: EVENT_TIME
+    epoch: {}
+`, false},
+			"window": {`
+patterns:
+  - name: EVENT_TIME
+    epoch:
+      mode: generate
+      from: 2020-01-01
+      to: 2024-12-31
+`, false},
+			"generate without window": {`
+patterns:
+  - name: EVENT_TIME
+    epoch:
+      mode: generate
+      from: 2020-01-01
+`, true},
+			"unknown mode": {`
+patterns:
+  - name: EVENT_TIME
+    epoch:
+      mode: scramble
+`, true},
+			"bad date": {`
+patterns:
+  - name: EVENT_TIME
+    epoch:
+      to: 31/12/2024
+`, true},
+		} {
+			path := filepath.Join(tmpDir, "epoch.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			pf, err := loader.LoadFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("%s: expected error", name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: failed to load file: %v", name, err)
+			}
+			if !pf.Patterns[0].IsEpochPattern() {
+				t.Errorf("%s: unexpected pattern %+v", name, pf.Patterns[0])
+			}
+		}
+	})
+
+	t.Run("geo pattern", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for name, tt := range map[string]struct {
+			content string
+			wantErr bool
+		}{
+			"default point": {`
+patterns:
+  - name: LOCATION
+    geo: {}
+`, false},
+			"bounding box and jitter": {`
+patterns:
+  - name: LOCATION
+    geo:
+      type: latitude
+      bounding_box: [49.9, -8.6, 60.9, 1.8]
+      jitter_meters: 500
+`, false},
+			"unknown type": {`
+patterns:
+  - name: LOCATION
+    geo:
+      type: altitude
+`, true},
+			"short bounding box": {`
+patterns:
+  - name: LOCATION
+    geo:
+      bounding_box: [49.9, -8.6]
+`, true},
+			"inverted bounding box": {`
+patterns:
+  - name: LOCATION
+    geo:
+      bounding_box: [60.9, -8.6, 49.9, 1.8]
+`, true},
+			"negative jitter": {`
+patterns:
+  - name: LOCATION
+    geo:
+      jitter_meters: -1
+`, true},
+		} {
+			path := filepath.Join(tmpDir, "geo.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			pf, err := loader.LoadFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("%s: expected error", name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: failed to load file: %v", name, err)
+			}
+			if !pf.Patterns[0].IsGeoPattern() {
+				t.Errorf("%s: unexpected pattern %+v", name, pf.Patterns[0])
+			}
+		}
+	})
+
+	t.Run("ip pattern", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for name, tt := range map[string]struct {
+			content string
+			wantErr bool
+		}{
+			"default prefixes": {`
+patterns:
+  - name: CLIENT_IP
+    ip: {}
+`, false},
+			"prefixes": {`
+patterns:
+  - name: CLIENT_IP
+    ip:
+      ipv4_prefix: 24
+      ipv6_prefix: 64
+`, false},
+			"ipv4 prefix too long": {`
+patterns:
+  - name: CLIENT_IP
+    ip:
+      ipv4_prefix: 33
+`, true},
+			"negative ipv6 prefix": {`
+patterns:
+  - name: CLIENT_IP
+    ip:
+      ipv6_prefix: -8
+`, true},
+			"ip and geo": {`
+patterns:
+  - name: CLIENT_IP
+    ip: {}
+    geo: {}
+`, true},
+		} {
+			path := filepath.Join(tmpDir, "ip.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			pf, err := loader.LoadFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("%s: expected error", name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: failed to load file: %v", name, err)
+			}
+			if !pf.Patterns[0].IsIPPattern() {
+				t.Errorf("%s: unexpected pattern %+v", name, pf.Patterns[0])
+			}
+		}
+	})
+
+	t.Run("noise pattern", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for name, tt := range map[string]struct {
+			content string
+			wantErr bool
+		}{
+			"epsilon and sensitivity": {`
+patterns:
+  - name: SALARY_NOISE
+    noise:
+      epsilon: 0.5
+      sensitivity: 1000
+      non_negative: true
+`, false},
+			"no epsilon": {`
+patterns:
+  - name: SALARY_NOISE
+    noise:
+      sensitivity: 1000
+`, true},
+			"negative sensitivity": {`
+patterns:
+  - name: SALARY_NOISE
+    noise:
+      epsilon: 1
+      sensitivity: -1
+`, true},
+			"noise and ip": {`
+patterns:
+  - name: SALARY_NOISE
+    noise:
+      epsilon: 1
+      sensitivity: 1
+    ip: {}
+`, true},
+		} {
+			path := filepath.Join(tmpDir, "noise.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			pf, err := loader.LoadFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("%s: expected error", name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: failed to load file: %v", name, err)
+			}
+			if !pf.Patterns[0].IsNoisePattern() {
+				t.Errorf("%s: unexpected pattern %+v", name, pf.Patterns[0])
+			}
+		}
+	})
 }
 
 // TestLoadToRegistry tests loading to registry
@@ -385,6 +931,17 @@ patterns:
 		}
 	})
 
+	t.Run("embedded defaults", func(t *testing.T) {
+		registry, err := LoadPatterns(EmbeddedPath, "", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok := registry.Get("EMAIL"); !ok {
+			t.Error("EMAIL not found in embedded defaults")
+		}
+	})
+
 	t.Run("empty paths", func(t *testing.T) {
 		registry, err := LoadPatterns("", "", false)
 		if err != nil {
@@ -0,0 +1,268 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package plan describes the work a run would do, found from the
+// configuration and the database's catalog and statistics without
+// changing the database, and estimates how long it would take.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Report formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// DefaultRowsPerSecond is the rate a column is assumed to be anonymized at
+// when neither its configuration nor earlier runs tell.
+const DefaultRowsPerSecond = 10000
+
+// Column is the plan of one column, in the order the run processes it.
+type Column struct {
+	Column   string `json:"column"`
+	DataType string `json:"data_type"`
+
+	// Unique is set if a unique constraint or index covers the column,
+	// so that generated values must not collide.
+	Unique bool `json:"unique"`
+
+	// Method is how values are replaced, such as "pattern EMAIL" or
+	// "shuffle", and Strategy how the rows are read and written, such as
+	// "keyset" or "distinct values".
+	Method   string `json:"method"`
+	Strategy string `json:"strategy"`
+
+	// BatchSize is the number of rows read at a time, and Transaction the
+	// number, from 1, of the transaction the column is committed in.
+	BatchSize   int `json:"batch_size"`
+	Transaction int `json:"transaction"`
+
+	// Rows is the planner's estimate of the table's rows, and
+	// SamplePercent the part of them anonymized, or zero for all.
+	Rows          int64   `json:"estimated_rows"`
+	SamplePercent float64 `json:"sample_percent,omitempty"`
+
+	// Analyzed is set if the strategy and batch size were chosen from an
+	// estimate of the column's distinct values, Distinct, from a sample
+	// of its rows if DistinctSampled is set, or else from its planner
+	// statistics.
+	Analyzed        bool  `json:"analyzed,omitempty"`
+	Distinct        int64 `json:"estimated_distinct,omitempty"`
+	DistinctSampled bool  `json:"distinct_sampled,omitempty"`
+
+	// RowsPerSecond is the rate the estimate assumes: Measured is set if
+	// it is configured or learned from earlier runs, and Limited if
+	// max_rows_per_second lowers it.
+	RowsPerSecond float64 `json:"rows_per_second"`
+	Measured      bool    `json:"measured_rate"`
+	Limited       bool    `json:"limited_rate,omitempty"`
+
+	// Skip is the reason the column is skipped, such as "CASCADE target",
+	// or empty if it is anonymized.
+	Skip string `json:"skip,omitempty"`
+
+	Duration time.Duration `json:"-"`
+}
+
+// Plan is the work a run would do on one database.
+type Plan struct {
+	Database        string   `json:"database"`
+	TransactionMode string   `json:"transaction_mode"`
+	Transactions    int      `json:"transactions"`
+	Columns         []Column `json:"columns"`
+
+	// Propagated and Views describe the columns added to the configured
+	// ones, as "from -> to (via)".
+	Propagated []string `json:"propagated,omitempty"`
+	Views      []string `json:"views,omitempty"`
+
+	// Rows is the estimated number of rows read over every column
+	// anonymized, and Duration the estimated time taken to anonymize them.
+	Rows     int64         `json:"estimated_rows"`
+	Duration time.Duration `json:"-"`
+}
+
+// Estimate sets the duration of each column anonymized, from its rows,
+// its rate, lowered to maxRowsPerSecond if that is set, and the pause
+// after each batch, and totals the rows and durations of the plan.
+func (p *Plan) Estimate(maxRowsPerSecond int, batchPause time.Duration) {
+	p.Rows, p.Duration = 0, 0
+	for i := range p.Columns {
+		c := &p.Columns[i]
+		c.Duration = 0
+		if c.Skip != "" {
+			continue
+		}
+
+		rows := c.Rows
+		if c.SamplePercent > 0 {
+			rows = int64(math.Ceil(float64(rows) * c.SamplePercent / 100))
+		}
+		if c.RowsPerSecond <= 0 {
+			c.RowsPerSecond, c.Measured = DefaultRowsPerSecond, false
+		}
+		if maxRowsPerSecond > 0 && c.RowsPerSecond > float64(maxRowsPerSecond) {
+			c.RowsPerSecond, c.Limited = float64(maxRowsPerSecond), true
+		}
+		c.Duration = time.Duration(float64(rows) / c.RowsPerSecond *
+			float64(time.Second))
+		if batchPause > 0 && c.BatchSize > 0 {
+			batches := (rows + int64(c.BatchSize) - 1) / int64(c.BatchSize)
+			c.Duration += time.Duration(batches) * batchPause
+		}
+
+		p.Rows += rows
+		p.Duration += c.Duration
+	}
+}
+
+// Skipped returns the number of columns the run skips.
+func (p *Plan) Skipped() int {
+	n := 0
+	for _, c := range p.Columns {
+		if c.Skip != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// jsonPlan adds the durations, in milliseconds, to the JSON of a plan.
+type jsonPlan struct {
+	*Plan
+	Columns    []jsonColumn `json:"columns"`
+	DurationMS int64        `json:"estimated_duration_ms"`
+}
+
+type jsonColumn struct {
+	Column
+	DurationMS int64 `json:"estimated_duration_ms"`
+}
+
+func toJSON(p *Plan) jsonPlan {
+	jp := jsonPlan{Plan: p, DurationMS: p.Duration.Milliseconds()}
+	for _, c := range p.Columns {
+		jp.Columns = append(jp.Columns, jsonColumn{c,
+			c.Duration.Milliseconds()})
+	}
+	return jp
+}
+
+// Write writes the plans of one or more databases in the given format.
+func Write(w io.Writer, plans []*Plan, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if len(plans) == 1 {
+			return enc.Encode(toJSON(plans[0]))
+		}
+		targets := make([]jsonPlan, 0, len(plans))
+		for _, p := range plans {
+			targets = append(targets, toJSON(p))
+		}
+		return enc.Encode(struct {
+			Targets []jsonPlan `json:"targets"`
+		}{targets})
+	case FormatText, "":
+		for _, p := range plans {
+			writeText(w, p)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid report format %q: must be text or json",
+			format)
+	}
+}
+
+// writeText writes a plan as a list of the columns in processing order.
+func writeText(w io.Writer, p *Plan) {
+	fmt.Fprintf(w, "\nPlan for %s\n", p.Database)
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+
+	fmt.Fprintf(w, "Transaction mode: %s (%d %s)\n", p.TransactionMode,
+		p.Transactions, plural(p.Transactions, "transaction"))
+	for _, v := range p.Views {
+		fmt.Fprintf(w, "View column: %s\n", v)
+	}
+	for _, prop := range p.Propagated {
+		fmt.Fprintf(w, "Propagation: %s\n", prop)
+	}
+
+	fmt.Fprintln(w, "\nProcessing order:")
+	for i, c := range p.Columns {
+		if c.Skip != "" {
+			fmt.Fprintf(w, "  %d. %s (%s - will skip)\n", i+1, c.Column,
+				c.Skip)
+			continue
+		}
+
+		fmt.Fprintf(w, "  %d. %s\n", i+1, c.Column)
+		dataType := c.DataType
+		if c.Unique {
+			dataType += ", unique"
+		}
+		fmt.Fprintf(w, "       type:     %s\n", dataType)
+		fmt.Fprintf(w, "       method:   %s\n", c.Method)
+		fmt.Fprintf(w, "       strategy: %s, batches of %d, transaction %d\n",
+			c.Strategy, c.BatchSize, c.Transaction)
+
+		rows := fmt.Sprintf("~%d", c.Rows)
+		if c.SamplePercent > 0 {
+			rows += fmt.Sprintf(" (%g%% sampled)", c.SamplePercent)
+		}
+		rate := "assumed"
+		switch {
+		case c.Limited:
+			rate = "max_rows_per_second of"
+		case c.Measured:
+			rate = "expected"
+		}
+		fmt.Fprintf(w, "       rows:     %s, ~%s at %s %.0f rows/s\n", rows,
+			formatDuration(c.Duration), rate, c.RowsPerSecond)
+		if c.Analyzed {
+			distinct := fmt.Sprintf("~%d", c.Distinct)
+			if c.DistinctSampled {
+				distinct += " (sampled)"
+			}
+			fmt.Fprintf(w, "       distinct: %s\n", distinct)
+		}
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	fmt.Fprintf(w, "Columns: %d to anonymize, %d skipped\n",
+		len(p.Columns)-p.Skipped(), p.Skipped())
+	fmt.Fprintf(w, "Estimated rows: %d\n", p.Rows)
+	fmt.Fprintf(w, "Estimated duration: %s\n", formatDuration(p.Duration))
+}
+
+// plural returns a noun in the plural unless n is one.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
+
+// formatDuration formats an estimated duration for display, to the second.
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return "<1s"
+	}
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,149 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testPlan() *Plan {
+	return &Plan{
+		Database:        "mydb",
+		TransactionMode: "per_table",
+		Transactions:    2,
+		Columns: []Column{
+			{Column: "public.users.email", DataType: "text", Unique: true,
+				Method: "pattern EMAIL", Strategy: "keyset", BatchSize: 1000,
+				Transaction: 1, Rows: 20000, RowsPerSecond: 4000,
+				Measured: true, Analyzed: true, Distinct: 20000,
+				DistinctSampled: true},
+			{Column: "public.orders.user_email", Transaction: 2,
+				Skip: "CASCADE target", Rows: 1000000},
+			{Column: "public.orders.note", DataType: "text",
+				Method: "nullify", Strategy: "single update", BatchSize: 1000,
+				Transaction: 2, Rows: 50000, SamplePercent: 10},
+		},
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	p := testPlan()
+	p.Estimate(0, 0)
+
+	if got := p.Columns[0].Duration; got != 5*time.Second {
+		t.Errorf("expected 5s at the expected rate, got %s", got)
+	}
+	if got := p.Columns[1].Duration; got != 0 {
+		t.Errorf("expected no time for a skipped column, got %s", got)
+	}
+	// 10% of 50000 rows at the assumed rate
+	if got := p.Columns[2].Duration; got != 500*time.Millisecond {
+		t.Errorf("expected 500ms at the assumed rate, got %s", got)
+	}
+	if p.Columns[2].RowsPerSecond != DefaultRowsPerSecond ||
+		p.Columns[2].Measured {
+		t.Errorf("expected the assumed rate, got %v", p.Columns[2])
+	}
+	if p.Rows != 25000 || p.Duration != 5500*time.Millisecond {
+		t.Errorf("unexpected totals: %d rows, %s", p.Rows, p.Duration)
+	}
+	if p.Skipped() != 1 {
+		t.Errorf("expected 1 skipped column, got %d", p.Skipped())
+	}
+
+	// The rate is lowered to max_rows_per_second, and each batch pauses
+	p = testPlan()
+	p.Estimate(2000, 100*time.Millisecond)
+	if got := p.Columns[0].Duration; got != 12*time.Second {
+		t.Errorf("expected 10s plus 20 pauses, got %s", got)
+	}
+	if !p.Columns[0].Limited || p.Columns[0].RowsPerSecond != 2000 {
+		t.Errorf("expected the limited rate, got %v", p.Columns[0])
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	p := testPlan()
+	p.Estimate(0, 0)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []*Plan{p}, FormatText); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Plan for mydb",
+		"Transaction mode: per_table (2 transactions)",
+		"1. public.users.email",
+		"type:     text, unique",
+		"strategy: keyset, batches of 1000, transaction 1",
+		"rows:     ~20000, ~5s at expected 4000 rows/s",
+		"distinct: ~20000 (sampled)",
+		"2. public.orders.user_email (CASCADE target - will skip)",
+		"rows:     ~50000 (10% sampled), ~<1s at assumed 10000 rows/s",
+		"Columns: 2 to anonymize, 1 skipped",
+		"Estimated rows: 25000",
+		"Estimated duration: 6s",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "distinct:"); n != 1 {
+		t.Errorf("expected only the analyzed column's estimate, got %d", n)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	p := testPlan()
+	p.Estimate(0, 0)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []*Plan{p}, FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded struct {
+		Database   string `json:"database"`
+		DurationMS int64  `json:"estimated_duration_ms"`
+		Columns    []struct {
+			Column     string `json:"column"`
+			Skip       string `json:"skip"`
+			DurationMS int64  `json:"estimated_duration_ms"`
+		} `json:"columns"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if decoded.Database != "mydb" || decoded.DurationMS != 5500 ||
+		len(decoded.Columns) != 3 {
+		t.Errorf("unexpected plan: %+v", decoded)
+	}
+	if decoded.Columns[0].DurationMS != 5000 ||
+		decoded.Columns[1].Skip != "CASCADE target" {
+		t.Errorf("unexpected columns: %+v", decoded.Columns)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, []*Plan{p, p}, FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"targets"`) {
+		t.Errorf("expected plans of targets, got:\n%s", buf.String())
+	}
+
+	if err := Write(&buf, []*Plan{p}, "yaml"); err == nil {
+		t.Error("expected error for an invalid format")
+	}
+}
@@ -0,0 +1,76 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package relay
+
+// Actions of changes decoded from a replication slot.
+const (
+	ActionBegin    = 'B'
+	ActionCommit   = 'C'
+	ActionInsert   = 'I'
+	ActionUpdate   = 'U'
+	ActionDelete   = 'D'
+	ActionTruncate = 'T'
+)
+
+// Table is a table named in a change.
+type Table struct {
+	Schema string
+	Name   string
+}
+
+// String returns the table as schema.name.
+func (t Table) String() string {
+	return t.Schema + "." + t.Name
+}
+
+// Value is the value of a column of a row in a change.
+type Value struct {
+	Column string
+
+	// Text is the value in PostgreSQL's text form, or nil for NULL.
+	Text *string
+
+	// Unchanged is set for a TOASTed value an update left unchanged, whose
+	// value the change does not carry.
+	Unchanged bool
+}
+
+// Change is a change decoded from a replication slot: the start or end of
+// a transaction, or a change to the rows of one or more tables.
+type Change struct {
+	Action byte
+
+	// Table is the table changed, for inserts, updates and deletes.
+	Table Table
+
+	// Row is the new row, for inserts and updates.
+	Row []Value
+
+	// Key is the replica identity of the old row, for updates and
+	// deletes: its primary key, or the columns of its replica identity
+	// index, or all its columns with REPLICA IDENTITY FULL.
+	Key []Value
+
+	// Tables are the tables truncated, for truncates.
+	Tables []Table
+}
+
+// decoder decodes the output of a logical decoding plugin into changes.
+type decoder interface {
+	// query returns the query peeking at the changes of a slot, with the
+	// slot name as $1 and the most changes to read as $2, returning the
+	// LSN and data of each change.
+	query() (string, []any)
+
+	// decode decodes the data of a change; it may return no changes for
+	// output that does not change rows, such as a relation's description.
+	decode(data []byte) ([]Change, error)
+}
@@ -0,0 +1,238 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package relay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// relation is a table described by a pgoutput Relation message.
+type relation struct {
+	table   Table
+	columns []string
+	key     []bool // Whether each column is part of the replica identity
+}
+
+// pgoutputDecoder decodes version 1 of the pgoutput plugin's protocol, the
+// logical replication protocol PostgreSQL uses between publications and
+// subscriptions.
+type pgoutputDecoder struct {
+	publication string
+
+	// relations are the tables described so far, by their OIDs; each
+	// reading of the slot describes a table before its first change
+	relations map[uint32]*relation
+}
+
+// newPgoutputDecoder returns a decoder of the changes of a publication.
+func newPgoutputDecoder(publication string) *pgoutputDecoder {
+	return &pgoutputDecoder{publication: publication,
+		relations: make(map[uint32]*relation)}
+}
+
+// query returns the query peeking at the changes of a slot.
+func (d *pgoutputDecoder) query() (string, []any) {
+	return `SELECT lsn::text, data
+            FROM pg_logical_slot_peek_binary_changes($1, NULL, $2,
+                 'proto_version', '1', 'publication_names', $3)`,
+		[]any{d.publication}
+}
+
+// decode decodes a pgoutput message.
+func (d *pgoutputDecoder) decode(data []byte) ([]Change, error) {
+	m := &message{data: data}
+	kind := m.byte()
+
+	var change Change
+	switch kind {
+	case 'B':
+		change.Action = ActionBegin
+	case 'C':
+		change.Action = ActionCommit
+	case 'R':
+		d.decodeRelation(m)
+		return nil, m.err
+	case 'I':
+		rel, err := d.relation(m.uint32())
+		if err != nil {
+			return nil, err
+		}
+		if m.byte() != 'N' {
+			return nil, fmt.Errorf("malformed insert of %s", rel.table)
+		}
+		change = Change{Action: ActionInsert, Table: rel.table,
+			Row: m.tuple(rel)}
+	case 'U':
+		rel, err := d.relation(m.uint32())
+		if err != nil {
+			return nil, err
+		}
+		change = Change{Action: ActionUpdate, Table: rel.table}
+		tag := m.byte()
+		if tag == 'K' || tag == 'O' {
+			change.Key = keyOf(rel, m.tuple(rel))
+			tag = m.byte()
+		}
+		if tag != 'N' {
+			return nil, fmt.Errorf("malformed update of %s", rel.table)
+		}
+		change.Row = m.tuple(rel)
+		if change.Key == nil {
+			// The key was not changed, so the new row holds it
+			change.Key = keyOf(rel, change.Row)
+		}
+	case 'D':
+		rel, err := d.relation(m.uint32())
+		if err != nil {
+			return nil, err
+		}
+		if tag := m.byte(); tag != 'K' && tag != 'O' {
+			return nil, fmt.Errorf("malformed delete of %s", rel.table)
+		}
+		change = Change{Action: ActionDelete, Table: rel.table,
+			Key: keyOf(rel, m.tuple(rel))}
+	case 'T':
+		change.Action = ActionTruncate
+		n := m.uint32()
+		m.byte() // Options
+		for i := uint32(0); i < n && m.err == nil; i++ {
+			rel, err := d.relation(m.uint32())
+			if err != nil {
+				return nil, err
+			}
+			change.Tables = append(change.Tables, rel.table)
+		}
+	default:
+		// Origin, type, and logical decoding messages change no rows
+		return nil, m.err
+	}
+
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []Change{change}, nil
+}
+
+// decodeRelation records the table a Relation message describes.
+func (d *pgoutputDecoder) decodeRelation(m *message) {
+	id := m.uint32()
+	rel := &relation{table: Table{Schema: m.string(), Name: m.string()}}
+	m.byte() // Replica identity setting
+	n := int(m.uint16())
+	for i := 0; i < n && m.err == nil; i++ {
+		flags := m.byte()
+		rel.columns = append(rel.columns, m.string())
+		rel.key = append(rel.key, flags&1 != 0)
+		m.uint32() // Type OID
+		m.uint32() // Type modifier
+	}
+	if m.err == nil {
+		d.relations[id] = rel
+	}
+}
+
+// relation returns the table of an OID.
+func (d *pgoutputDecoder) relation(id uint32) (*relation, error) {
+	rel, ok := d.relations[id]
+	if !ok {
+		return nil, fmt.Errorf("change to relation %d before its "+
+			"description", id)
+	}
+	return rel, nil
+}
+
+// keyOf returns the values of the replica identity columns of a row.
+func keyOf(rel *relation, row []Value) []Value {
+	key := []Value{}
+	for i, v := range row {
+		if i < len(rel.key) && rel.key[i] {
+			key = append(key, v)
+		}
+	}
+	return key
+}
+
+// message reads the fields of a pgoutput message, recording the first
+// error, after which it reads zero values.
+type message struct {
+	data []byte
+	err  error
+}
+
+// take returns the next n bytes of the message, or zero bytes enough for
+// the fixed size fields after an error.
+func (m *message) take(n int) []byte {
+	if m.err == nil && len(m.data) < n {
+		m.err = fmt.Errorf("truncated pgoutput message")
+	}
+	if m.err != nil {
+		return make([]byte, min(n, 8))
+	}
+	b := m.data[:n]
+	m.data = m.data[n:]
+	return b
+}
+
+func (m *message) byte() byte {
+	return m.take(1)[0]
+}
+
+func (m *message) uint16() uint16 {
+	return binary.BigEndian.Uint16(m.take(2))
+}
+
+func (m *message) uint32() uint32 {
+	return binary.BigEndian.Uint32(m.take(4))
+}
+
+// string reads a NUL-terminated string.
+func (m *message) string() string {
+	if m.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(m.data, 0)
+	if i < 0 {
+		m.err = fmt.Errorf("truncated pgoutput message")
+		return ""
+	}
+	s := string(m.data[:i])
+	m.data = m.data[i+1:]
+	return s
+}
+
+// tuple reads the values of a row of a table.
+func (m *message) tuple(rel *relation) []Value {
+	n := int(m.uint16())
+	if m.err == nil && n != len(rel.columns) {
+		m.err = fmt.Errorf("row of %d columns for %s, which has %d", n,
+			rel.table, len(rel.columns))
+	}
+
+	var row []Value
+	for i := 0; i < n && m.err == nil; i++ {
+		v := Value{Column: rel.columns[i]}
+		switch kind := m.byte(); kind {
+		case 'n':
+		case 'u':
+			v.Unchanged = true
+		case 't':
+			text := string(m.take(int(m.uint32())))
+			v.Text = &text
+		default:
+			m.err = fmt.Errorf("unsupported value kind %q in a row of %s",
+				kind, rel.table)
+		}
+		row = append(row, v)
+	}
+	return row
+}
@@ -0,0 +1,149 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package relay
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// pgoutputMessage builds a pgoutput message from bytes, strings (written
+// NUL-terminated), uint16s, uint32s, and rows.
+func pgoutputMessage(fields ...any) []byte {
+	var b []byte
+	for _, f := range fields {
+		switch v := f.(type) {
+		case byte:
+			b = append(b, v)
+		case string:
+			b = append(append(b, v...), 0)
+		case uint16:
+			b = binary.BigEndian.AppendUint16(b, v)
+		case uint32:
+			b = binary.BigEndian.AppendUint32(b, v)
+		case []*string:
+			b = binary.BigEndian.AppendUint16(b, uint16(len(v)))
+			for _, s := range v {
+				if s == nil {
+					b = append(b, 'n')
+					continue
+				}
+				b = append(b, 't')
+				b = binary.BigEndian.AppendUint32(b, uint32(len(*s)))
+				b = append(b, *s...)
+			}
+		}
+	}
+	return b
+}
+
+// usersRelation describes public.users (id, email), keyed by id.
+func usersRelation() []byte {
+	return pgoutputMessage(byte('R'), uint32(16384), "public", "users",
+		byte('d'), uint16(2),
+		byte(1), "id", uint32(23), uint32(0xffffffff),
+		byte(0), "email", uint32(25), uint32(0xffffffff))
+}
+
+func text(s string) *string {
+	return &s
+}
+
+func TestPgoutputDecode(t *testing.T) {
+	d := newPgoutputDecoder("pub")
+	if changes, err := d.decode(usersRelation()); err != nil ||
+		len(changes) != 0 {
+		t.Fatalf("relation: changes %v, error %v", changes, err)
+	}
+
+	changes, err := d.decode(pgoutputMessage(byte('I'), uint32(16384),
+		byte('N'), []*string{text("1"), text("a@example.com")}))
+	if err != nil {
+		t.Fatalf("insert: unexpected error: %v", err)
+	}
+	c := changes[0]
+	if c.Action != ActionInsert || c.Table.String() != "public.users" {
+		t.Errorf("insert: got %c of %s", c.Action, c.Table)
+	}
+	if len(c.Row) != 2 || c.Row[1].Column != "email" ||
+		*c.Row[1].Text != "a@example.com" {
+		t.Errorf("insert: unexpected row %+v", c.Row)
+	}
+
+	// An update leaving the key alone is keyed by the new row
+	changes, err = d.decode(pgoutputMessage(byte('U'), uint32(16384),
+		byte('N'), []*string{text("1"), nil}))
+	if err != nil {
+		t.Fatalf("update: unexpected error: %v", err)
+	}
+	c = changes[0]
+	if len(c.Key) != 1 || c.Key[0].Column != "id" || *c.Key[0].Text != "1" {
+		t.Errorf("update: unexpected key %+v", c.Key)
+	}
+	if c.Row[1].Text != nil {
+		t.Errorf("update: expected a NULL email, got %q", *c.Row[1].Text)
+	}
+
+	// An update of the key carries the old one
+	changes, err = d.decode(pgoutputMessage(byte('U'), uint32(16384),
+		byte('K'), []*string{text("1"), nil},
+		byte('N'), []*string{text("2"), text("b@example.com")}))
+	if err != nil {
+		t.Fatalf("key update: unexpected error: %v", err)
+	}
+	if c = changes[0]; *c.Key[0].Text != "1" || *c.Row[0].Text != "2" {
+		t.Errorf("key update: key %+v, row %+v", c.Key, c.Row)
+	}
+
+	changes, err = d.decode(pgoutputMessage(byte('D'), uint32(16384),
+		byte('K'), []*string{text("2"), nil}))
+	if err != nil {
+		t.Fatalf("delete: unexpected error: %v", err)
+	}
+	if c = changes[0]; c.Action != ActionDelete || *c.Key[0].Text != "2" {
+		t.Errorf("delete: got %c keyed %+v", c.Action, c.Key)
+	}
+
+	changes, err = d.decode(pgoutputMessage(byte('T'), uint32(1), byte(0),
+		uint32(16384)))
+	if err != nil {
+		t.Fatalf("truncate: unexpected error: %v", err)
+	}
+	if c = changes[0]; len(c.Tables) != 1 || c.Tables[0].Name != "users" {
+		t.Errorf("truncate: unexpected tables %v", c.Tables)
+	}
+}
+
+func TestPgoutputDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"unknown relation", pgoutputMessage(byte('I'), uint32(99),
+			byte('N'), []*string{text("1")})},
+		{"truncated", pgoutputMessage(byte('I'), uint32(16384),
+			byte('N'), uint16(2), byte('t'), uint32(10))},
+		{"wrong column count", pgoutputMessage(byte('I'), uint32(16384),
+			byte('N'), []*string{text("1")})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newPgoutputDecoder("pub")
+			if _, err := d.decode(usersRelation()); err != nil {
+				t.Fatalf("relation: unexpected error: %v", err)
+			}
+			if _, err := d.decode(tt.data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
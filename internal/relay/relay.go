@@ -0,0 +1,664 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package relay keeps an anonymized copy of a database in sync: it reads
+// the changes made to the database from a logical replication slot,
+// anonymizes the configured columns of the changed rows, and applies the
+// changes to the copy.
+package relay
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// Dictionary maps original values to the values they were anonymized to,
+// so that equal values are replaced alike.
+type Dictionary interface {
+	Get(original string) (string, bool)
+	Set(original, anonymized string)
+}
+
+// Options configures a relay.
+type Options struct {
+	// Config holds the database changes are read from, the database in
+	// copy_to they are applied to, the relay section, and the columns to
+	// anonymize.
+	Config *config.Config
+
+	Generators *generator.Manager
+
+	// Dictionary keeps the mapping of original to anonymized values.
+	Dictionary *anonymizer.Dictionary
+
+	// Once applies the changes waiting in the slot and returns, rather
+	// than waiting for more.
+	Once  bool
+	Quiet bool
+}
+
+// Stats counts what a relay has applied.
+type Stats struct {
+	Transactions int64
+	Changes      int64
+	LSN          string // The position the slot was advanced to
+}
+
+// columnRule is how the values of a column are anonymized.
+type columnRule struct {
+	gen      generator.Generator // nil for nullified and constant columns
+	dict     Dictionary
+	nullify  bool
+	constant *string
+
+	// partitionBy is the column whose value selects the dictionary of a
+	// row, from partition, if the mappings are partitioned
+	partitionBy string
+	partition   func(value string) Dictionary
+}
+
+// inPartition returns the rule for a row of a partitioned column, whose
+// mappings are those of the partition the row's partition column value
+// selects, found in the first of the sets of values holding it. NULL
+// shares the partition of the empty value, as in a run.
+func (c *columnRule) inPartition(values ...[]Value) *columnRule {
+	if c.partitionBy == "" {
+		return c
+	}
+	rule := *c
+	rule.dict = c.partition(partitionValue(c.partitionBy, values))
+	return &rule
+}
+
+// partitionValue returns the value of a column in the first of the sets of
+// values holding it, or the empty value for NULL or if none holds it.
+func partitionValue(column string, values [][]Value) string {
+	for _, vs := range values {
+		for _, v := range vs {
+			if v.Column == column && !v.Unchanged {
+				if v.Text == nil {
+					return ""
+				}
+				return *v.Text
+			}
+		}
+	}
+	return ""
+}
+
+// anonymize returns the anonymized form of a value, or nil for NULL.
+// Empty values are left as they are, as in a run.
+func (c *columnRule) anonymize(value *string) *string {
+	switch {
+	case value == nil || c.nullify:
+		return nil
+	case c.constant != nil:
+		return c.constant
+	case *value == "":
+		return value
+	}
+	if anonymized, ok := c.dict.Get(*value); ok {
+		return &anonymized
+	}
+	anonymized := c.gen.Generate(*value)
+	c.dict.Set(*value, anonymized)
+	return &anonymized
+}
+
+// Relay applies the anonymized changes of a replication slot.
+type Relay struct {
+	opts     Options
+	settings *config.RelayConfig
+	source   *database.Connector
+	target   *database.Connector
+	decoder  decoder
+
+	// sourceDB and targetDB are the connected databases changes are read
+	// from and applied to
+	sourceDB *sql.DB
+	targetDB *sql.DB
+
+	// rules are how each configured column is anonymized, by table and
+	// column name
+	rules map[Table]map[string]*columnRule
+
+	// types are the formatted types of the columns of the tables changed,
+	// by table and column name, as the target database has them
+	types map[Table]map[string]string
+
+	stats Stats
+}
+
+// New returns a relay of the changes to the configuration's database.
+func New(opts Options) *Relay {
+	cfg := opts.Config
+	r := &Relay{
+		opts:     opts,
+		settings: cfg.Relay,
+		source:   database.NewConnector(&cfg.Database),
+		types:    make(map[Table]map[string]string),
+	}
+	target := cfg.CopyTarget()
+	r.target = database.NewConnector(&target)
+
+	if cfg.Relay.PluginName() == config.RelayPluginWal2JSON {
+		r.decoder = wal2jsonDecoder{}
+	} else {
+		r.decoder = newPgoutputDecoder(cfg.Relay.Publication)
+	}
+	return r
+}
+
+// Close closes the relay's connections.
+func (r *Relay) Close() {
+	r.source.Close()
+	r.target.Close()
+}
+
+// Stats returns what the relay has applied.
+func (r *Relay) Stats() Stats {
+	return r.stats
+}
+
+// CreateSlot creates the relay's replication slot in the database changes
+// are read from. Changes made after it is created are relayed, so it is
+// created before the copy is made.
+func (r *Relay) CreateSlot(ctx context.Context) error {
+	if err := r.source.Connect(ctx); err != nil {
+		return fmt.Errorf("database connection error: %w", err)
+	}
+	_, err := r.source.DB().ExecContext(ctx,
+		"SELECT pg_create_logical_replication_slot($1, $2)",
+		r.settings.Slot, r.settings.PluginName())
+	if err != nil {
+		return errors.NewDatabaseError("create_slot",
+			fmt.Sprintf("failed to create replication slot %s: %v",
+				r.settings.Slot, err), err)
+	}
+	return nil
+}
+
+// Run applies the changes in the slot, and then those made afterwards as
+// they arrive, until the context is cancelled, which stops it after the
+// changes being applied are committed. With Once, it returns once the
+// changes waiting in the slot are applied.
+func (r *Relay) Run(ctx context.Context) error {
+	if err := r.source.Connect(ctx); err != nil {
+		return fmt.Errorf("database connection error: %w", err)
+	}
+	if err := r.target.Connect(ctx); err != nil {
+		return fmt.Errorf("target database connection error: %w", err)
+	}
+	r.sourceDB, r.targetDB = r.source.DB(), r.target.DB()
+	if err := r.loadRules(ctx); err != nil {
+		return err
+	}
+
+	for {
+		n, err := r.poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if n > 0 {
+			continue
+		}
+		if r.opts.Once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.settings.Interval()):
+		}
+	}
+}
+
+// loadRules looks up how each configured column is anonymized. Only
+// columns anonymized with a pattern, set to NULL, or set to a constant are
+// supported, as the other kinds depend on the rest of the table.
+func (r *Relay) loadRules(ctx context.Context) error {
+	validator := database.NewSchemaValidator(r.sourceDB)
+	columns, err := validator.ExpandWildcards(ctx, r.opts.Config.Columns)
+	if err != nil {
+		return fmt.Errorf("wildcard expansion error: %w", err)
+	}
+	columns, err = anonymizer.ResolvePartitions(ctx, validator, r.opts.Config,
+		columns)
+	if err != nil {
+		return fmt.Errorf("partition error: %w", err)
+	}
+
+	r.rules = make(map[Table]map[string]*columnRule)
+	var unsupported []string
+	for _, colConfig := range columns {
+		col, err := errors.ParseColumnRef(colConfig.Column)
+		if err != nil {
+			return err
+		}
+
+		var rule *columnRule
+		switch {
+		case colConfig.IsNullifyColumn():
+			rule = &columnRule{nullify: true}
+		case colConfig.IsConstantColumn():
+			rule = &columnRule{constant: colConfig.Constant}
+		case colConfig.Pattern != "" && !colConfig.IsJSONColumn() &&
+			!colConfig.IsXMLColumn() && !colConfig.IsCompositeColumn() &&
+			!colConfig.IsDerivedColumn() && !colConfig.IsProfileColumn() &&
+			!colConfig.IsShuffleColumn() && !colConfig.IsDateShiftColumn():
+			if rule, err = r.patternRule(ctx, validator, colConfig,
+				col); err != nil {
+				return err
+			}
+		default:
+			unsupported = append(unsupported, colConfig.Column)
+			continue
+		}
+
+		table := Table{Schema: col.Schema, Name: col.Table}
+		if r.rules[table] == nil {
+			r.rules[table] = make(map[string]*columnRule)
+		}
+		r.rules[table][col.Column] = rule
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("the relay anonymizes columns with a pattern, "+
+			"strategy nullify, or a constant only, not %s",
+			strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// patternRule returns the rule of a column anonymized with a pattern,
+// fitted to the column as in a run.
+func (r *Relay) patternRule(ctx context.Context,
+	validator *database.SchemaValidator, colConfig config.ColumnConfig,
+	col errors.ColumnRef) (*columnRule, error) {
+
+	dataType, err := validator.GetColumnDataType(ctx, col)
+	if err != nil {
+		return nil, err
+	}
+	maxLength, err := validator.GetColumnMaxLength(ctx, col)
+	if err != nil {
+		return nil, err
+	}
+	gen, ok := r.opts.Generators.GetForContext(colConfig.Pattern,
+		generator.ColumnInfo{Column: col, DataType: dataType,
+			MaxLength: maxLength})
+	if !ok {
+		return nil, fmt.Errorf("unknown pattern %q for column %s",
+			colConfig.Pattern, col.String())
+	}
+
+	dict := r.opts.Dictionary.Namespace(anonymizer.ColumnNamespace(
+		r.opts.Config, colConfig, colConfig.Pattern))
+	rule := &columnRule{gen: gen, dict: dict}
+	if colConfig.PartitionBy != "" {
+		rule.partitionBy = colConfig.PartitionBy
+		rule.partition = func(value string) Dictionary {
+			return dict.Partition(value)
+		}
+	}
+	return rule, nil
+}
+
+// poll applies the whole transactions waiting in the slot, in a single
+// transaction of the target database, and then advances the slot past
+// them. It returns the number of transactions applied. If the relay stops
+// between committing the changes and advancing the slot, they are applied
+// again when it restarts, which leaves the same rows.
+func (r *Relay) poll(ctx context.Context) (int, error) {
+	query, extra := r.decoder.query()
+	args := append([]any{r.settings.Slot, r.settings.ChangeLimit()},
+		extra...)
+	rows, err := r.sourceDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, errors.NewDatabaseError("relay_read",
+			fmt.Sprintf("failed to read replication slot %s: %v",
+				r.settings.Slot, err), err)
+	}
+
+	// Only the changes of committed transactions are applied
+	var changes []Change
+	committed, transactions := 0, 0
+	lsn := ""
+	for rows.Next() {
+		var at string
+		var data []byte
+		if err := rows.Scan(&at, &data); err != nil {
+			rows.Close()
+			return 0, errors.NewDatabaseError("relay_read",
+				fmt.Sprintf("failed to scan change: %v", err), err)
+		}
+		decoded, err := r.decoder.decode(data)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("at %s: %w", at, err)
+		}
+		for _, c := range decoded {
+			switch c.Action {
+			case ActionBegin:
+			case ActionCommit:
+				committed = len(changes)
+				transactions++
+				lsn = at
+			default:
+				changes = append(changes, c)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, errors.NewDatabaseError("relay_read",
+			fmt.Sprintf("error reading changes: %v", err), err)
+	}
+	rows.Close()
+
+	if transactions == 0 {
+		return 0, nil
+	}
+	if err := r.applyAll(ctx, changes[:committed]); err != nil {
+		return 0, err
+	}
+
+	if _, err := r.sourceDB.ExecContext(ctx,
+		"SELECT pg_replication_slot_advance($1, $2::pg_lsn)",
+		r.settings.Slot, lsn); err != nil {
+		return 0, errors.NewDatabaseError("relay_advance",
+			fmt.Sprintf("failed to advance replication slot %s: %v",
+				r.settings.Slot, err), err)
+	}
+
+	r.stats.Transactions += int64(transactions)
+	r.stats.Changes += int64(committed)
+	r.stats.LSN = lsn
+	if !r.opts.Quiet {
+		log.Printf("Relayed %d changes in %d transactions, up to %s",
+			committed, transactions, lsn)
+	}
+	return transactions, nil
+}
+
+// applyAll applies changes to the target database in one transaction.
+func (r *Relay) applyAll(ctx context.Context, changes []Change) error {
+	tx, err := r.targetDB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewDatabaseError("begin",
+			fmt.Sprintf("failed to start transaction: %v", err), err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, c := range changes {
+		if err := r.apply(ctx, tx, c); err != nil {
+			return err
+		}
+	}
+
+	// Stop before committing values from a generator that has failed
+	if err := r.opts.Generators.Err(); err != nil {
+		return fmt.Errorf("pattern failed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewDatabaseError("relay_commit",
+			fmt.Sprintf("failed to commit changes: %v", err), err)
+	}
+	return nil
+}
+
+// apply applies a change, with its configured columns anonymized.
+func (r *Relay) apply(ctx context.Context, tx *sql.Tx, c Change) error {
+	var query string
+	var args []any
+	var err error
+
+	if c.Action == ActionTruncate {
+		query = truncateStatement(c.Tables)
+	} else {
+		types, err := r.columnTypes(ctx, tx, c.Table)
+		if err != nil {
+			return err
+		}
+		row := r.anonymize(c.Table, c.Row, c.Key)
+		key := r.anonymize(c.Table, c.Key, c.Row)
+
+		switch c.Action {
+		case ActionInsert:
+			query, args, err = insertStatement(c.Table, row, types)
+		case ActionUpdate:
+			query, args, err = updateStatement(c.Table, row, key, types)
+		case ActionDelete:
+			query, args, err = deleteStatement(c.Table, key, types)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if query == "" {
+		return nil
+	}
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return errors.NewDatabaseError("relay_apply",
+			fmt.Sprintf("failed to apply change to %s: %v", c.Table, err),
+			err)
+	}
+	return nil
+}
+
+// anonymize returns the values of a row of a table with the configured
+// columns anonymized. A key is anonymized as the row it identifies was,
+// so that it matches the row's anonymized key. The partition column of a
+// partitioned column is looked up in the values, and then in the other
+// values of the change, as a key may not hold it.
+func (r *Relay) anonymize(table Table, values, other []Value) []Value {
+	rules := r.rules[table]
+	if len(rules) == 0 {
+		return values
+	}
+
+	anonymized := make([]Value, len(values))
+	for i, v := range values {
+		anonymized[i] = v
+		if rule, ok := rules[v.Column]; ok && !v.Unchanged {
+			anonymized[i].Text = rule.inPartition(values, other).anonymize(
+				v.Text)
+		}
+	}
+	return anonymized
+}
+
+// columnTypes returns the formatted types of the columns of a table in
+// the target database.
+func (r *Relay) columnTypes(ctx context.Context, tx *sql.Tx,
+	table Table) (map[string]string, error) {
+
+	if types, ok := r.types[table]; ok {
+		return types, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT a.attname, format_type(a.atttypid, a.atttypmod)
+        FROM pg_attribute a
+        WHERE a.attrelid = to_regclass($1)
+          AND a.attnum > 0
+          AND NOT a.attisdropped`, quoteTable(table))
+	if err != nil {
+		return nil, errors.NewDatabaseError("relay_types",
+			fmt.Sprintf("failed to get the columns of %s: %v", table, err),
+			err)
+	}
+	defer rows.Close()
+
+	types := make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return nil, errors.NewDatabaseError("relay_types",
+				fmt.Sprintf("failed to scan column: %v", err), err)
+		}
+		types[name] = dataType
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("relay_types",
+			fmt.Sprintf("error iterating columns: %v", err), err)
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("table %s not found in the target database",
+			table)
+	}
+
+	r.types[table] = types
+	return types, nil
+}
+
+// quoteTable returns the quoted name of a table.
+func quoteTable(t Table) string {
+	return pgx.Identifier{t.Schema, t.Name}.Sanitize()
+}
+
+// statement builds a statement's parameters, cast from text to the types
+// of their columns.
+type statement struct {
+	table Table
+	types map[string]string
+	args  []any
+}
+
+// param returns the parameter for the value of a column.
+func (s *statement) param(v Value) (string, error) {
+	dataType, ok := s.types[v.Column]
+	if !ok {
+		return "", fmt.Errorf("column %s of %s not found in the target "+
+			"database", v.Column, s.table)
+	}
+	if v.Text == nil {
+		s.args = append(s.args, nil)
+	} else {
+		s.args = append(s.args, *v.Text)
+	}
+	return fmt.Sprintf("$%d::text::%s", len(s.args), dataType), nil
+}
+
+// where returns the condition matching a row by its key.
+func (s *statement) where(key []Value) (string, error) {
+	if len(key) == 0 {
+		return "", fmt.Errorf("table %s has no replica identity to find "+
+			"changed rows by; give it a primary key or set its REPLICA "+
+			"IDENTITY", s.table)
+	}
+
+	conds := make([]string, 0, len(key))
+	for _, v := range key {
+		col := pgx.Identifier{v.Column}.Sanitize()
+		if v.Text == nil {
+			conds = append(conds, col+" IS NULL")
+			continue
+		}
+		p, err := s.param(v)
+		if err != nil {
+			return "", err
+		}
+		conds = append(conds, col+" = "+p)
+	}
+	return strings.Join(conds, " AND "), nil
+}
+
+// insertStatement returns the statement inserting a row. A row already
+// present, as one copied after the change was made, is left as it is.
+func insertStatement(table Table, row []Value,
+	types map[string]string) (string, []any, error) {
+
+	s := &statement{table: table, types: types}
+	cols := make([]string, 0, len(row))
+	params := make([]string, 0, len(row))
+	for _, v := range row {
+		p, err := s.param(v)
+		if err != nil {
+			return "", nil, err
+		}
+		cols = append(cols, pgx.Identifier{v.Column}.Sanitize())
+		params = append(params, p)
+	}
+
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT DO "+
+		"NOTHING", quoteTable(table), strings.Join(cols, ", "),
+		strings.Join(params, ", ")), s.args, nil
+}
+
+// updateStatement returns the statement updating a row, leaving its
+// unchanged TOASTed values as they are. It returns no statement if every
+// value is unchanged.
+func updateStatement(table Table, row, key []Value,
+	types map[string]string) (string, []any, error) {
+
+	s := &statement{table: table, types: types}
+	sets := make([]string, 0, len(row))
+	for _, v := range row {
+		if v.Unchanged {
+			continue
+		}
+		p, err := s.param(v)
+		if err != nil {
+			return "", nil, err
+		}
+		sets = append(sets, pgx.Identifier{v.Column}.Sanitize()+" = "+p)
+	}
+	if len(sets) == 0 {
+		return "", nil, nil
+	}
+
+	where, err := s.where(key)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("UPDATE %s SET %s WHERE %s", quoteTable(table),
+		strings.Join(sets, ", "), where), s.args, nil
+}
+
+// deleteStatement returns the statement deleting a row.
+func deleteStatement(table Table, key []Value,
+	types map[string]string) (string, []any, error) {
+
+	s := &statement{table: table, types: types}
+	where, err := s.where(key)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s", quoteTable(table), where),
+		s.args, nil
+}
+
+// truncateStatement returns the statement truncating tables.
+func truncateStatement(tables []Table) string {
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = quoteTable(t)
+	}
+	return "TRUNCATE " + strings.Join(names, ", ")
+}
@@ -0,0 +1,256 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package relay
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// mapDictionary is a Dictionary kept in memory.
+type mapDictionary map[string]string
+
+func (d mapDictionary) Get(original string) (string, bool) {
+	v, ok := d[original]
+	return v, ok
+}
+
+func (d mapDictionary) Set(original, anonymized string) {
+	d[original] = anonymized
+}
+
+// prefixGenerator anonymizes values by prefixing them, and counts how
+// often it is called.
+type prefixGenerator struct {
+	calls int
+}
+
+func (g *prefixGenerator) Name() string { return "TEST" }
+
+func (g *prefixGenerator) Generate(input string) string {
+	g.calls++
+	return "anon-" + input
+}
+
+var usersTable = Table{Schema: "public", Name: "users"}
+
+var usersTypes = map[string]string{"id": "integer", "email": "text"}
+
+func TestColumnRuleAnonymize(t *testing.T) {
+	gen := &prefixGenerator{}
+	rule := &columnRule{gen: gen, dict: mapDictionary{}}
+
+	if got := rule.anonymize(text("a@example.com")); *got !=
+		"anon-a@example.com" {
+		t.Errorf("expected an anonymized value, got %q", *got)
+	}
+	rule.anonymize(text("a@example.com"))
+	if gen.calls != 1 {
+		t.Errorf("expected a repeated value from the dictionary, "+
+			"generated %d times", gen.calls)
+	}
+	if got := rule.anonymize(nil); got != nil {
+		t.Errorf("expected NULL to stay NULL, got %q", *got)
+	}
+	if got := rule.anonymize(text("")); *got != "" {
+		t.Errorf("expected an empty value to stay empty, got %q", *got)
+	}
+
+	if got := (&columnRule{nullify: true}).anonymize(text("x")); got != nil {
+		t.Errorf("expected NULL, got %q", *got)
+	}
+	constant := "REDACTED"
+	if got := (&columnRule{constant: &constant}).anonymize(
+		text("x")); *got != constant {
+		t.Errorf("expected the constant, got %q", *got)
+	}
+}
+
+func TestColumnRuleInPartition(t *testing.T) {
+	partitions := make(map[string]mapDictionary)
+	rule := &columnRule{gen: &prefixGenerator{}, dict: mapDictionary{},
+		partitionBy: "tenant_id",
+		partition: func(value string) Dictionary {
+			if partitions[value] == nil {
+				partitions[value] = mapDictionary{}
+			}
+			return partitions[value]
+		}}
+
+	row := []Value{{Column: "tenant_id", Text: text("1")},
+		{Column: "email", Text: text("a@example.com")}}
+	rule.inPartition(row).anonymize(text("a@example.com"))
+	if _, ok := partitions["1"].Get("a@example.com"); !ok {
+		t.Errorf("expected the mapping in the row's partition, got %v",
+			partitions)
+	}
+
+	// A key without the partition column finds it in the row
+	key := []Value{{Column: "email", Text: text("b@example.com")}}
+	rule.inPartition(key, row).anonymize(text("b@example.com"))
+	if _, ok := partitions["1"].Get("b@example.com"); !ok {
+		t.Errorf("expected the key's mapping in the row's partition, got %v",
+			partitions)
+	}
+
+	// NULL shares the partition of the empty value
+	rule.inPartition([]Value{{Column: "tenant_id"}}).anonymize(
+		text("c@example.com"))
+	if _, ok := partitions[""].Get("c@example.com"); !ok {
+		t.Errorf("expected the mapping in the empty partition, got %v",
+			partitions)
+	}
+
+	if plain := (&columnRule{}); plain.inPartition(row) != plain {
+		t.Error("expected an unpartitioned rule to be returned as is")
+	}
+}
+
+func TestStatements(t *testing.T) {
+	row := []Value{{Column: "id", Text: text("1")},
+		{Column: "email", Text: text("a@example.com")}}
+
+	query, args, err := insertStatement(usersTable, row, usersTypes)
+	if err != nil {
+		t.Fatalf("insert: unexpected error: %v", err)
+	}
+	if want := `INSERT INTO "public"."users" ("id", "email") VALUES ` +
+		`($1::text::integer, $2::text::text) ON CONFLICT DO NOTHING`; query != want {
+		t.Errorf("insert:\n got %s\nwant %s", query, want)
+	}
+	if !reflect.DeepEqual(args, []any{"1", "a@example.com"}) {
+		t.Errorf("insert: unexpected args %v", args)
+	}
+
+	// Unchanged values are not set, and NULL keys match with IS NULL
+	query, args, err = updateStatement(usersTable,
+		[]Value{{Column: "id", Unchanged: true},
+			{Column: "email", Text: text("b@example.com")}},
+		[]Value{{Column: "id", Text: text("1")}, {Column: "email"}},
+		usersTypes)
+	if err != nil {
+		t.Fatalf("update: unexpected error: %v", err)
+	}
+	if want := `UPDATE "public"."users" SET "email" = $1::text::text ` +
+		`WHERE "id" = $2::text::integer AND "email" IS NULL`; query != want {
+		t.Errorf("update:\n got %s\nwant %s", query, want)
+	}
+	if !reflect.DeepEqual(args, []any{"b@example.com", "1"}) {
+		t.Errorf("update: unexpected args %v", args)
+	}
+
+	if query, _, _ = updateStatement(usersTable,
+		[]Value{{Column: "email", Unchanged: true}}, row[:1],
+		usersTypes); query != "" {
+		t.Errorf("expected no update of unchanged values, got %s", query)
+	}
+
+	query, _, err = deleteStatement(usersTable, row[:1], usersTypes)
+	if err != nil {
+		t.Fatalf("delete: unexpected error: %v", err)
+	}
+	if want := `DELETE FROM "public"."users" WHERE "id" = ` +
+		`$1::text::integer`; query != want {
+		t.Errorf("delete:\n got %s\nwant %s", query, want)
+	}
+
+	if _, _, err := deleteStatement(usersTable, []Value{},
+		usersTypes); err == nil {
+		t.Error("expected an error deleting without a key")
+	}
+	if _, _, err := insertStatement(usersTable,
+		[]Value{{Column: "missing", Text: text("x")}}, usersTypes); err == nil {
+		t.Error("expected an error for a column missing from the target")
+	}
+
+	if got := truncateStatement([]Table{usersTable,
+		{Schema: "public", Name: "orders"}}); got !=
+		`TRUNCATE "public"."users", "public"."orders"` {
+		t.Errorf("unexpected truncate %s", got)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	source, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer source.Close()
+	target, targetMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer target.Close()
+
+	r := &Relay{
+		opts: Options{Generators: generator.NewManager(), Quiet: true},
+		settings: &config.RelayConfig{Slot: "anonymizer",
+			Plugin: config.RelayPluginWal2JSON},
+		decoder:  wal2jsonDecoder{},
+		sourceDB: source,
+		targetDB: target,
+		rules: map[Table]map[string]*columnRule{usersTable: {
+			"email": {gen: &prefixGenerator{}, dict: mapDictionary{}}}},
+		types: make(map[Table]map[string]string),
+	}
+
+	// The second transaction has not been committed yet, so is left in
+	// the slot
+	insert := `{"action":"I","schema":"public","table":"users",
+		"columns":[{"name":"id","value":1},
+			{"name":"email","value":"a@example.com"}]}`
+	sourceMock.ExpectQuery(`pg_logical_slot_peek_changes`).
+		WithArgs("anonymizer", config.DefaultRelayMaxChanges).
+		WillReturnRows(sqlmock.NewRows([]string{"lsn", "data"}).
+			AddRow("0/1", []byte(`{"action":"B"}`)).
+			AddRow("0/2", []byte(insert)).
+			AddRow("0/3", []byte(`{"action":"C"}`)).
+			AddRow("0/4", []byte(`{"action":"B"}`)).
+			AddRow("0/5", []byte(insert)))
+
+	targetMock.ExpectBegin()
+	targetMock.ExpectQuery(`FROM pg_attribute`).
+		WithArgs(`"public"."users"`).
+		WillReturnRows(sqlmock.NewRows([]string{"attname", "format_type"}).
+			AddRow("id", "integer").AddRow("email", "text"))
+	targetMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "public"."users"`)).
+		WithArgs("1", "anon-a@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	targetMock.ExpectCommit()
+
+	sourceMock.ExpectExec(`pg_replication_slot_advance`).
+		WithArgs("anonymizer", "0/3").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	n, err := r.poll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 transaction applied, got %d", n)
+	}
+	if stats := r.Stats(); stats.Changes != 1 || stats.LSN != "0/3" {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	for _, mock := range []sqlmock.Sqlmock{sourceMock, targetMock} {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	}
+}
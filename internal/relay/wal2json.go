@@ -0,0 +1,108 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package relay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// wal2jsonDecoder decodes version 2 of the wal2json plugin's format, in
+// which each change is a JSON object.
+type wal2jsonDecoder struct{}
+
+// wal2jsonChange is a change in wal2json's format.
+type wal2jsonChange struct {
+	Action   string          `json:"action"`
+	Schema   string          `json:"schema"`
+	Table    string          `json:"table"`
+	Columns  []wal2jsonValue `json:"columns"`
+	Identity []wal2jsonValue `json:"identity"`
+}
+
+// wal2jsonValue is a column value in wal2json's format.
+type wal2jsonValue struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+// query returns the query peeking at the changes of a slot.
+func (wal2jsonDecoder) query() (string, []any) {
+	return `SELECT lsn::text, convert_to(data, 'UTF8')
+            FROM pg_logical_slot_peek_changes($1, NULL, $2,
+                 'format-version', '2', 'include-transaction', 'true')`,
+		nil
+}
+
+// decode decodes a wal2json change.
+func (wal2jsonDecoder) decode(data []byte) ([]Change, error) {
+	var wc wal2jsonChange
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&wc); err != nil {
+		return nil, fmt.Errorf("malformed wal2json change: %w", err)
+	}
+
+	table := Table{Schema: wc.Schema, Name: wc.Table}
+	var change Change
+	switch wc.Action {
+	case "B":
+		change.Action = ActionBegin
+	case "C":
+		change.Action = ActionCommit
+	case "I":
+		change = Change{Action: ActionInsert, Table: table,
+			Row: wal2jsonValues(wc.Columns)}
+	case "U":
+		change = Change{Action: ActionUpdate, Table: table,
+			Row: wal2jsonValues(wc.Columns), Key: wal2jsonValues(wc.Identity)}
+	case "D":
+		change = Change{Action: ActionDelete, Table: table,
+			Key: wal2jsonValues(wc.Identity)}
+	case "T":
+		change = Change{Action: ActionTruncate, Tables: []Table{table}}
+	default:
+		// Logical decoding messages change no rows
+		return nil, nil
+	}
+	return []Change{change}, nil
+}
+
+// wal2jsonValues returns the values of columns in PostgreSQL's text form.
+// Unchanged TOASTed values are left out by wal2json, so every value is
+// known.
+func wal2jsonValues(columns []wal2jsonValue) []Value {
+	values := make([]Value, 0, len(columns))
+	for _, col := range columns {
+		v := Value{Column: col.Name}
+		switch value := col.Value.(type) {
+		case nil:
+		case string:
+			v.Text = &value
+		case json.Number:
+			text := value.String()
+			v.Text = &text
+		case bool:
+			text := "false"
+			if value {
+				text = "true"
+			}
+			v.Text = &text
+		default:
+			b, _ := json.Marshal(value)
+			text := string(b)
+			v.Text = &text
+		}
+		values = append(values, v)
+	}
+	return values
+}
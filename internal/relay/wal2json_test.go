@@ -0,0 +1,67 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package relay
+
+import (
+	"testing"
+)
+
+func TestWal2JSONDecode(t *testing.T) {
+	d := wal2jsonDecoder{}
+
+	changes, err := d.decode([]byte(`{"action":"U","schema":"public",
+		"table":"users","columns":[
+			{"name":"id","type":"integer","value":12345678901},
+			{"name":"email","type":"text","value":"a@example.com"},
+			{"name":"active","type":"boolean","value":true},
+			{"name":"note","type":"text","value":null}],
+		"identity":[{"name":"id","type":"integer","value":1}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := changes[0]
+	if c.Action != ActionUpdate || c.Table.String() != "public.users" {
+		t.Errorf("got %c of %s", c.Action, c.Table)
+	}
+
+	want := []*string{text("12345678901"), text("a@example.com"),
+		text("true"), nil}
+	for i, v := range c.Row {
+		switch {
+		case want[i] == nil && v.Text != nil:
+			t.Errorf("%s: expected NULL, got %q", v.Column, *v.Text)
+		case want[i] != nil && (v.Text == nil || *v.Text != *want[i]):
+			t.Errorf("%s: expected %q, got %v", v.Column, *want[i], v.Text)
+		}
+	}
+	if len(c.Key) != 1 || *c.Key[0].Text != "1" {
+		t.Errorf("unexpected key %+v", c.Key)
+	}
+
+	for data, action := range map[string]byte{
+		`{"action":"B"}`: ActionBegin,
+		`{"action":"C"}`: ActionCommit,
+		`{"action":"T","schema":"public","table":"users"}`: ActionTruncate,
+	} {
+		changes, err := d.decode([]byte(data))
+		if err != nil || len(changes) != 1 || changes[0].Action != action {
+			t.Errorf("%s: got %v, error %v", data, changes, err)
+		}
+	}
+
+	if changes, err := d.decode([]byte(`{"action":"M"}`)); err != nil ||
+		len(changes) != 0 {
+		t.Errorf("message: got %v, error %v", changes, err)
+	}
+	if _, err := d.decode([]byte(`{"action":`)); err == nil {
+		t.Error("expected an error for malformed JSON")
+	}
+}
@@ -0,0 +1,248 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package scaffold generates a starter configuration from the columns of a
+// database, suggesting a pattern for those whose names suggest they hold
+// personal data.
+package scaffold
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+)
+
+// typeClass is a group of data types a pattern's values suit.
+type typeClass int
+
+const (
+	textTypes typeClass = 1 << iota
+	dateTypes
+	inetTypes
+)
+
+// rule suggests a pattern for columns named after one of its names, or
+// with one of them as the first or last word of their name.
+type rule struct {
+	pattern string
+	names   []string
+	types   typeClass
+}
+
+// rules are checked in order, so more specific names come first.
+var rules = []rule{
+	{"EMAIL", []string{"email", "e_mail", "email_address", "mail"}, textTypes},
+	{"PERSON_FIRST_NAME", []string{"first_name", "firstname", "given_name",
+		"forename", "fname"}, textTypes},
+	{"PERSON_LAST_NAME", []string{"last_name", "lastname", "surname",
+		"family_name", "lname"}, textTypes},
+	{"PERSON_NAME", []string{"full_name", "fullname", "person_name",
+		"contact_name", "customer_name", "display_name"}, textTypes},
+	{"US_SSN", []string{"ssn", "social_security_number"}, textTypes},
+	{"CREDIT_CARD_CVV", []string{"cvv", "cvv2", "cvc", "card_cvv"}, textTypes},
+	{"CREDIT_CARD_EXPIRY", []string{"card_expiry", "card_expiration",
+		"card_exp", "expiry_date"}, textTypes},
+	{"CREDIT_CARD", []string{"credit_card", "creditcard", "card_number",
+		"cc_number"}, textTypes},
+	{"PASSPORT", []string{"passport", "passport_number", "passport_no"},
+		textTypes},
+	{"DOB", []string{"dob", "date_of_birth", "birth_date", "birthdate",
+		"birthday"}, textTypes | dateTypes},
+	{"WORLDWIDE_PHONE", []string{"phone", "phone_number", "telephone", "tel",
+		"mobile", "cell", "fax"}, textTypes},
+	{"IPV4_ADDRESS", []string{"ip", "ip_address", "ipaddr", "ip_addr"},
+		textTypes | inetTypes},
+	{"ADDRESS", []string{"address", "street", "street_address", "address1",
+		"address_line1", "address_line_1", "addr"}, textTypes},
+	{"CITY", []string{"city", "town"}, textTypes},
+	{"WORLDWIDE_POSTCODE", []string{"zip", "zipcode", "zip_code", "postcode",
+		"postal_code"}, textTypes},
+	{"HOSTNAME", []string{"hostname", "host_name"}, textTypes},
+	{"LOREMIPSUM", []string{"notes", "note", "comments", "comment", "bio",
+		"remarks"}, textTypes},
+}
+
+// Suggest returns the pattern suggested for a column from its name and
+// data type, or an empty string if there is none.
+func Suggest(column, dataType string) string {
+	class := classify(dataType)
+	if class == 0 {
+		return ""
+	}
+
+	name := normalize(column)
+	for _, r := range rules {
+		if r.types&class == 0 {
+			continue
+		}
+		for _, n := range r.names {
+			if name == n || strings.HasPrefix(name, n+"_") ||
+				strings.HasSuffix(name, "_"+n) {
+				return r.pattern
+			}
+		}
+	}
+	return ""
+}
+
+// classify returns the type class of a data type, or zero if patterns are
+// not suggested for it.
+func classify(dataType string) typeClass {
+	switch dataType {
+	case "text", "character varying", "character", "USER-DEFINED":
+		// USER-DEFINED covers citext and domains over text
+		return textTypes
+	case "date", "timestamp without time zone", "timestamp with time zone":
+		return dateTypes
+	case "inet":
+		return inetTypes
+	}
+	return 0
+}
+
+// normalize lowercases a column name and separates its words, including
+// those of camel-cased names, with underscores.
+func normalize(column string) string {
+	var b strings.Builder
+	prevLower := false
+	for _, r := range column {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			if prevLower {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r + 'a' - 'A')
+			prevLower = false
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+			prevLower = true
+		default:
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+				b.WriteByte('_')
+			}
+			prevLower = false
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// databaseSection is the database section of a starter configuration;
+// unset values are left to the defaults and libpq environment variables,
+// and the password is never written.
+type databaseSection struct {
+	URL      string `yaml:"url,omitempty"`
+	Service  string `yaml:"service,omitempty"`
+	Host     string `yaml:"host,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
+	Database string `yaml:"database,omitempty"`
+	User     string `yaml:"user,omitempty"`
+	SSLMode  string `yaml:"sslmode,omitempty"`
+}
+
+// Write writes a starter configuration for a database and its columns,
+// with an entry for each column that suggest returns a pattern for, and
+// the other columns of each table listed in a comment. All column entries
+// are commented out, for the user to review and uncomment.
+func Write(w io.Writer, db config.DatabaseConfig,
+	columns []database.TableColumn,
+	suggest func(database.TableColumn) string) error {
+
+	section, err := yaml.Marshal(struct {
+		Database databaseSection `yaml:"database"`
+	}{databaseSection{
+		URL:      config.RedactURL(db.URL),
+		Service:  db.Service,
+		Host:     db.Host,
+		Port:     db.Port,
+		Database: db.Database,
+		User:     db.User,
+		SSLMode:  db.SSLMode,
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to encode database settings: %w", err)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, header)
+	bw.Write(section)
+	fmt.Fprintln(bw, "  # The password is not written; set PGPASSWORD, password_file,")
+	fmt.Fprintln(bw, "  # or password_command, or use ~/.pgpass, rather than storing")
+	fmt.Fprintln(bw, "  # it here.")
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "columns:")
+
+	for start := 0; start < len(columns); {
+		table := columns[start].Column
+		end := start
+		for end < len(columns) && columns[end].Column.Schema == table.Schema &&
+			columns[end].Column.Table == table.Table {
+			end++
+		}
+
+		fmt.Fprintf(bw, "  # Table %s.%s\n", table.Schema, table.Table)
+		var others []string
+		for _, tc := range columns[start:end] {
+			pattern := suggest(tc)
+			if pattern == "" {
+				others = append(others,
+					fmt.Sprintf("%s (%s)", tc.Column.Column, tc.DataType))
+				continue
+			}
+			fmt.Fprintf(bw, "  # - column: %s\n", tc.Column.String())
+			fmt.Fprintf(bw, "  #   pattern: %s\n", pattern)
+		}
+		if len(others) > 0 {
+			writeWrapped(bw, "  # Other columns: ", "  #   ",
+				strings.Join(others, ", "))
+		}
+		fmt.Fprintln(bw)
+
+		start = end
+	}
+
+	return bw.Flush()
+}
+
+// header introduces a starter configuration.
+const header = `# pgEdge Anonymizer configuration generated by "pgedge-anonymizer init".
+#
+# Review the column entries below, uncomment those holding personal data,
+# and check their suggested patterns; "pgedge-anonymizer patterns list"
+# shows the patterns available. Patterns are suggested from column names
+# only, so also look for personal data in the other columns listed.
+
+`
+
+// wrapWidth is the width comments are wrapped at.
+const wrapWidth = 76
+
+// writeWrapped writes a comment, wrapping it at spaces to wrapWidth, with
+// the first line prefixed by first and the rest by rest.
+func writeWrapped(w io.Writer, first, rest, text string) {
+	line := first
+	for i, word := range strings.Fields(text) {
+		if i > 0 && len(line)+1+len(word) > wrapWidth {
+			fmt.Fprintln(w, line)
+			line = rest + word
+			continue
+		}
+		if i > 0 {
+			line += " "
+		}
+		line += word
+	}
+	fmt.Fprintln(w, line)
+}
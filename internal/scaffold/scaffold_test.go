@@ -0,0 +1,111 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package scaffold
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		column   string
+		dataType string
+		want     string
+	}{
+		{"email", "text", "EMAIL"},
+		{"CustomerEmail", "character varying", "EMAIL"},
+		{"customer_email", "character varying", "EMAIL"},
+		{"email_verified", "boolean", ""},
+		{"first_name", "text", "PERSON_FIRST_NAME"},
+		{"Last-Name", "text", "PERSON_LAST_NAME"},
+		{"date_of_birth", "date", "DOB"},
+		{"created_at", "timestamp with time zone", ""},
+		{"ip_address", "inet", "IPV4_ADDRESS"},
+		{"billing_address", "text", "ADDRESS"},
+		{"address_id", "integer", ""},
+		{"mobile_phone", "text", "WORLDWIDE_PHONE"},
+		{"card_cvv", "text", "CREDIT_CARD_CVV"},
+		{"status", "text", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Suggest(tt.column, tt.dataType); got != tt.want {
+			t.Errorf("Suggest(%q, %q) = %q, want %q",
+				tt.column, tt.dataType, got, tt.want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	columns := []database.TableColumn{
+		{Column: errors.ColumnRef{Schema: "public", Table: "users",
+			Column: "id"}, DataType: "integer"},
+		{Column: errors.ColumnRef{Schema: "public", Table: "users",
+			Column: "email"}, DataType: "text"},
+		{Column: errors.ColumnRef{Schema: "public", Table: "orders",
+			Column: "total"}, DataType: "numeric"},
+	}
+	db := config.DatabaseConfig{Database: "mydb", User: "admin",
+		Password: "secret"}
+
+	var buf bytes.Buffer
+	err := Write(&buf, db, columns, func(tc database.TableColumn) string {
+		return Suggest(tc.Column.Column, tc.DataType)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"  # Table public.users\n",
+		"  # - column: public.users.email\n  #   pattern: EMAIL\n",
+		"  # Other columns: id (integer)\n",
+		"  # Other columns: total (numeric)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret") {
+		t.Errorf("expected the password not to be written, got:\n%s", out)
+	}
+
+	// The output is a configuration with no columns until entries are
+	// uncommented
+	var cfg config.Config
+	if err := yaml.Unmarshal(buf.Bytes(), &cfg); err != nil {
+		t.Fatalf("output is not valid YAML: %v", err)
+	}
+	if cfg.Database.Database != "mydb" || cfg.Database.User != "admin" ||
+		len(cfg.Columns) != 0 {
+		t.Errorf("unexpected configuration: %+v", cfg)
+	}
+
+	// Uncommenting an entry gives a column
+	uncommented := strings.ReplaceAll(out, "  # - column:", "  - column:")
+	uncommented = strings.ReplaceAll(uncommented, "  #   pattern:", "    pattern:")
+	if err := yaml.Unmarshal([]byte(uncommented), &cfg); err != nil {
+		t.Fatalf("uncommented output is not valid YAML: %v", err)
+	}
+	if len(cfg.Columns) != 1 || cfg.Columns[0].Column != "public.users.email" ||
+		cfg.Columns[0].Pattern != "EMAIL" {
+		t.Errorf("unexpected columns: %+v", cfg.Columns)
+	}
+}
@@ -0,0 +1,305 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package sealed encrypts and authenticates streams with AES-256-GCM, a
+// chunk at a time, so that files too large to hold in memory, such as
+// exported dictionaries, can be sealed and opened as they are written
+// and read. Each chunk is numbered, and the last marked, so that chunks
+// cannot be reordered, dropped, or cut off without it being detected.
+package sealed
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// KeySize is the size of a key, in bytes.
+const KeySize = 32
+
+// magic begins every sealed stream, followed by its version.
+const (
+	magic   = "PGANSEAL"
+	version = 1
+)
+
+// chunkSize is the most plaintext bytes sealed in each chunk.
+const chunkSize = 64 << 10
+
+// finalChunk is set in the length of the last chunk.
+const finalChunk = 1 << 31
+
+// prefixSize is the size of the random prefix of each chunk's nonce,
+// which is followed by the chunk's number.
+const prefixSize = 8
+
+// ErrAuthentication is returned when a stream was sealed with another
+// key, or has been changed since.
+var ErrAuthentication = errors.New("wrong key, or the file has been " +
+	"changed or corrupted")
+
+// IsSealed returns true if data begins as a sealed stream does.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// HeaderSize is the number of bytes IsSealed needs to recognize a sealed
+// stream.
+const HeaderSize = len(magic)
+
+// ReadSecret returns the key held by an environment variable or a file,
+// without surrounding white space, as it is written. Keys are read this
+// way wherever they are configured, including by the keyed patterns.
+func ReadSecret(keyEnv, keyFile string) (string, error) {
+	var key string
+	switch {
+	case keyEnv != "":
+		key = os.Getenv(keyEnv)
+		if key == "" {
+			return "", fmt.Errorf("environment variable %s holding the "+
+				"key is not set", keyEnv)
+		}
+	case keyFile != "":
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read key file: %w", err)
+		}
+		key = string(data)
+	default:
+		return "", fmt.Errorf("a key variable or key file is required")
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("key is empty")
+	}
+	return key, nil
+}
+
+// ReadKey returns the key held, in hexadecimal, by an environment
+// variable or a file.
+func ReadKey(keyEnv, keyFile string) ([]byte, error) {
+	encoded, err := ReadSecret(keyEnv, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("key must be hexadecimal: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bits, got %d", KeySize*8,
+			len(key)*8)
+	}
+	return key, nil
+}
+
+// newAEAD returns the cipher for a key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bits, got %d", KeySize*8,
+			len(key)*8)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of a chunk, and the additional data
+// authenticating whether it is the last.
+func chunkNonce(prefix []byte, n uint32, final bool) ([]byte, []byte) {
+	nonce := binary.BigEndian.AppendUint32(append([]byte(nil), prefix...), n)
+	ad := []byte{0}
+	if final {
+		ad[0] = 1
+	}
+	return nonce, ad
+}
+
+// Writer seals the data written to it. Close must be called to write the
+// last chunk, without which the stream cannot be opened.
+type Writer struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	chunk  uint32
+	buf    []byte
+	closed bool
+}
+
+// NewWriter returns a Writer sealing the data written to w with a key of
+// KeySize bytes.
+func NewWriter(w io.Writer, key []byte) (*Writer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, prefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header := append([]byte(magic), version)
+	if _, err := w.Write(append(header, prefix...)); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, aead: aead, prefix: prefix}, nil
+}
+
+// Write seals the data a chunk at a time, keeping the rest until the
+// next call.
+func (s *Writer) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, fmt.Errorf("write to a closed sealed stream")
+	}
+
+	s.buf = append(s.buf, p...)
+	for len(s.buf) > chunkSize {
+		if err := s.seal(s.buf[:chunkSize], false); err != nil {
+			return 0, err
+		}
+		s.buf = s.buf[chunkSize:]
+	}
+	return len(p), nil
+}
+
+// Close seals the last chunk. It does not close the underlying writer.
+func (s *Writer) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.seal(s.buf, true)
+}
+
+// seal writes a chunk, preceded by its length.
+func (s *Writer) seal(data []byte, final bool) error {
+	if s.chunk == finalChunk-1 {
+		return fmt.Errorf("sealed stream is too long")
+	}
+
+	nonce, ad := chunkNonce(s.prefix, s.chunk, final)
+	sealed := s.aead.Seal(nil, nonce, data, ad)
+	length := uint32(len(sealed))
+	if final {
+		length |= finalChunk
+	}
+
+	if _, err := s.w.Write(binary.BigEndian.AppendUint32(nil,
+		length)); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(sealed); err != nil {
+		return err
+	}
+	s.chunk++
+	return nil
+}
+
+// Reader opens a sealed stream, returning its data a chunk at a time as
+// each is authenticated.
+type Reader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	chunk  uint32
+	buf    []byte
+	done   bool
+}
+
+// NewReader returns a Reader opening the stream sealed with a key of
+// KeySize bytes read from r.
+func NewReader(r io.Reader, key []byte) (*Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, len(magic)+1+prefixSize)
+	if _, err := io.ReadFull(r, header); err != nil || !IsSealed(header) {
+		return nil, fmt.Errorf("not a sealed file")
+	}
+	if v := header[len(magic)]; v != version {
+		return nil, fmt.Errorf("unsupported sealed file version %d", v)
+	}
+	return &Reader{r: r, aead: aead, prefix: header[len(magic)+1:]}, nil
+}
+
+// Read returns the opened data.
+func (s *Reader) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// open reads and authenticates the next chunk.
+func (s *Reader) open() error {
+	var length [4]byte
+	if _, err := io.ReadFull(s.r, length[:]); err != nil {
+		return truncated(err)
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	final := size&finalChunk != 0
+	size &^= finalChunk
+	if size > chunkSize+uint32(s.aead.Overhead()) {
+		return ErrAuthentication
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(s.r, sealed); err != nil {
+		return truncated(err)
+	}
+	nonce, ad := chunkNonce(s.prefix, s.chunk, final)
+	data, err := s.aead.Open(nil, nonce, sealed, ad)
+	if err != nil {
+		return ErrAuthentication
+	}
+	s.chunk++
+	s.buf = data
+
+	if final {
+		// Nothing may follow the last chunk
+		var extra [1]byte
+		if n, _ := s.r.Read(extra[:]); n > 0 {
+			return ErrAuthentication
+		}
+		s.done = true
+	}
+	return nil
+}
+
+// truncated returns the error for a stream ending before its last chunk.
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("sealed file is truncated")
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package sealed
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func seal(t *testing.T, key, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(sealed), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	sizes := []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 7}
+	for _, size := range sizes {
+		data := bytes.Repeat([]byte("0123456789"), size/10+1)[:size]
+		sealed := seal(t, testKey(1), data)
+
+		if !IsSealed(sealed) {
+			t.Errorf("size %d: expected sealed stream to be recognized", size)
+		}
+		if bytes.Contains(sealed, []byte("0123456789")) && size >= 10 {
+			t.Errorf("size %d: sealed stream contains plaintext", size)
+		}
+
+		got, err := open(testKey(1), sealed)
+		if err != nil {
+			t.Fatalf("size %d: unexpected error: %v", size, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("size %d: opened data does not match", size)
+		}
+	}
+}
+
+func TestTampering(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 2*chunkSize+100)
+	sealed := seal(t, testKey(1), data)
+
+	if _, err := open(testKey(2), sealed); !errors.Is(err, ErrAuthentication) {
+		t.Errorf("wrong key: expected authentication error, got %v", err)
+	}
+
+	changed := append([]byte(nil), sealed...)
+	changed[len(changed)-1] ^= 1
+	if _, err := open(testKey(1), changed); !errors.Is(err, ErrAuthentication) {
+		t.Errorf("changed byte: expected authentication error, got %v", err)
+	}
+
+	// Cutting the stream after a whole chunk must not go unnoticed
+	header := len(magic) + 1 + prefixSize
+	firstChunk := header + 4 + chunkSize + 16
+	_, err := open(testKey(1), sealed[:firstChunk])
+	if err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("truncated stream: expected truncation error, got %v", err)
+	}
+
+	extended := append(append([]byte(nil), sealed...), 0)
+	if _, err := open(testKey(1), extended); !errors.Is(err, ErrAuthentication) {
+		t.Errorf("appended data: expected authentication error, got %v", err)
+	}
+
+	if _, err := open(testKey(1), []byte("plain text")); err == nil {
+		t.Error("expected error opening an unsealed stream")
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	encoded := hex.EncodeToString(testKey(7))
+
+	t.Setenv("SEALED_TEST_KEY", encoded)
+	key, err := ReadKey("SEALED_TEST_KEY", "")
+	if err != nil || !bytes.Equal(key, testKey(7)) {
+		t.Errorf("env: unexpected key %x, error %v", key, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte(encoded+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	key, err = ReadKey("", path)
+	if err != nil || !bytes.Equal(key, testKey(7)) {
+		t.Errorf("file: unexpected key %x, error %v", key, err)
+	}
+
+	t.Setenv("SEALED_SHORT_KEY", "abcd")
+	if _, err := ReadKey("SEALED_SHORT_KEY", ""); err == nil {
+		t.Error("expected error for a short key")
+	}
+	if _, err := ReadKey("SEALED_UNSET_KEY", ""); err == nil {
+		t.Error("expected error for an unset variable")
+	}
+	if _, err := ReadKey("", ""); err == nil {
+		t.Error("expected error without a key source")
+	}
+}
+
+func TestReadSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("  not hexadecimal\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := ReadSecret("", path)
+	if err != nil || key != "not hexadecimal" {
+		t.Errorf("unexpected key %q, error %v", key, err)
+	}
+
+	t.Setenv("SEALED_BLANK_KEY", " \n")
+	if _, err := ReadSecret("SEALED_BLANK_KEY", ""); err == nil {
+		t.Error("expected error for a blank key")
+	}
+}
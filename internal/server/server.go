@@ -0,0 +1,218 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package server serves the anonymizer's generators over HTTP, on a Unix
+// socket or a TCP address, so that they can be called from within the
+// database by the SQL functions that ExtensionSQL creates.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// SocketMode is the permission mode of a Unix socket the server listens
+// on, allowing the members of its group, such as the database server's
+// user, to connect.
+const SocketMode = 0660
+
+// ErrUnknownPattern is returned when a pattern is not registered.
+var ErrUnknownPattern = errors.New("unknown pattern")
+
+// ErrNotLoopback is returned when listening on a TCP address other than a
+// loopback address without allowing remote clients.
+var ErrNotLoopback = errors.New("the server does not authenticate its " +
+	"clients, so it only listens on loopback addresses")
+
+// GenerateRequest is the body of a request to generate a value.
+type GenerateRequest struct {
+	Pattern string `json:"pattern"`
+	Value   string `json:"value"`
+}
+
+// GenerateResponse is the body of a response with a generated value.
+type GenerateResponse struct {
+	Value string `json:"value"`
+}
+
+// ErrorResponse is the body of a response to a request that failed.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server generates anonymized values on request, mapping equal original
+// values to the same anonymized value, as in a run.
+type Server struct {
+	mu         sync.Mutex
+	generators *generator.Manager
+	dictionary *anonymizer.Dictionary
+}
+
+// New returns a server generating values with the given generators, and
+// keeping the mapping of original to anonymized values in the dictionary.
+func New(generators *generator.Manager,
+	dictionary *anonymizer.Dictionary) *Server {
+	return &Server{generators: generators, dictionary: dictionary}
+}
+
+// Generate returns the anonymized value for an original value with the
+// named pattern. Empty values are returned as they are. Values are mapped
+// apart for each pattern, as in a run with the pattern dictionary scope,
+// so that an original value is anonymized by each pattern it is passed
+// to, and values equal across patterns are not revealed.
+func (s *Server) Generate(pattern, original string) (string, error) {
+	gen, ok := s.generators.Get(pattern)
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownPattern, pattern)
+	}
+	if original == "" {
+		return "", nil
+	}
+
+	// Generators are not safe for concurrent use
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dict := s.dictionary.Namespace(anonymizer.PatternNamespace(pattern))
+	if anonymized, ok := dict.Get(original); ok {
+		return anonymized, nil
+	}
+	anonymized := gen.Generate(original)
+	if err := s.generators.Err(); err != nil {
+		return "", fmt.Errorf("pattern %s failed: %w", pattern, err)
+	}
+	dict.Set(original, anonymized)
+	return anonymized, nil
+}
+
+// Handler returns the HTTP handler of the server: POST /generate with a
+// GenerateRequest generates a value, and GET /patterns lists the pattern
+// names.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /generate", s.handleGenerate)
+	mux.HandleFunc("GET /patterns", s.handlePatterns)
+	return mux
+}
+
+// handleGenerate generates a value.
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	var req GenerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	value, err := s.Generate(req.Pattern, req.Value)
+	switch {
+	case errors.Is(err, ErrUnknownPattern):
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError,
+			ErrorResponse{Error: err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, GenerateResponse{Value: value})
+	}
+}
+
+// handlePatterns lists the pattern names.
+func (s *Server) handlePatterns(w http.ResponseWriter, r *http.Request) {
+	names := s.generators.List()
+	sort.Strings(names)
+	writeJSON(w, http.StatusOK, names)
+}
+
+// writeJSON writes a response with a JSON body.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Listen listens on a Unix socket, if address is a path, or else on a TCP
+// address such as localhost:8765. As the server does not authenticate
+// its clients, a TCP address must be a loopback address unless
+// allowRemote is set. A socket left by a server that did not shut down
+// cleanly is replaced, but not one a server is still listening on.
+func Listen(address string, allowRemote bool) (net.Listener, error) {
+	if !IsSocketPath(address) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if !allowRemote && !isLoopback(host) {
+			return nil, fmt.Errorf("%w: %s", ErrNotLoopback, address)
+		}
+		return net.Listen("tcp", address)
+	}
+
+	if fi, err := os.Stat(address); err == nil &&
+		fi.Mode()&os.ModeSocket != 0 {
+		if err := removeStaleSocket(address); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, SocketMode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return l, nil
+}
+
+// isLoopback returns true if host is localhost or a loopback IP address;
+// an empty host listens on every interface.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// removeStaleSocket removes a socket no server is listening on, which
+// refuses connections, and fails if a server is still listening on it.
+func removeStaleSocket(path string) error {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("a server is already listening on %s", path)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("failed to check socket %s: %w", path, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	return nil
+}
+
+// IsSocketPath returns true if an address is the path of a Unix socket,
+// rather than a TCP address.
+func IsSocketPath(address string) bool {
+	return strings.ContainsRune(address, os.PathSeparator) ||
+		strings.ContainsRune(address, '/')
+}
@@ -0,0 +1,263 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+)
+
+// newTestServer returns a server with the built-in generators.
+func newTestServer(t *testing.T) *Server {
+	mgr := generator.NewManager()
+	t.Cleanup(func() { mgr.Close() })
+	dict, err := anonymizer.NewDictionary(100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { dict.Close() })
+	return New(mgr, dict)
+}
+
+// TestGenerate tests generating values
+func TestGenerate(t *testing.T) {
+	s := newTestServer(t)
+
+	first, err := s.Generate("EMAIL", "bob@corp.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first == "" || first == "bob@corp.com" {
+		t.Errorf("expected an anonymized value, got %q", first)
+	}
+	if again, _ := s.Generate("EMAIL", "bob@corp.com"); again != first {
+		t.Errorf("expected the same value for the same original, got %q "+
+			"and %q", first, again)
+	}
+
+	// Each pattern anonymizes the value it is passed
+	name, err := s.Generate("PERSON_FIRST_NAME", "John")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	email, err := s.Generate("EMAIL", "John")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if email == name || !strings.Contains(email, "@") {
+		t.Errorf("expected an email address for EMAIL, got %q after %q "+
+			"for PERSON_FIRST_NAME", email, name)
+	}
+	if again, _ := s.Generate("PERSON_FIRST_NAME", "John"); again != name {
+		t.Errorf("expected the same first name for the same original, "+
+			"got %q and %q", name, again)
+	}
+
+	if value, err := s.Generate("EMAIL", ""); err != nil || value != "" {
+		t.Errorf("expected empty value unchanged, got %q, %v", value, err)
+	}
+	if _, err := s.Generate("NO_SUCH_PATTERN", "x"); !errors.Is(err,
+		ErrUnknownPattern) {
+		t.Errorf("expected unknown pattern error, got %v", err)
+	}
+}
+
+// TestHandler tests the HTTP interface
+func TestHandler(t *testing.T) {
+	ts := httptest.NewServer(newTestServer(t).Handler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/generate", "application/json",
+		strings.NewReader(`{"pattern": "US_PHONE", "value": "555-1234"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var gen GenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gen); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || gen.Value == "" {
+		t.Errorf("unexpected response: %d %+v", resp.StatusCode, gen)
+	}
+
+	resp, err = http.Post(ts.URL+"/generate", "application/json",
+		strings.NewReader(`{"pattern": "NO_SUCH_PATTERN", "value": "x"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var failed ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&failed)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || failed.Error == "" {
+		t.Errorf("unexpected response: %d %+v", resp.StatusCode, failed)
+	}
+
+	resp, err = http.Get(ts.URL + "/patterns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	_ = json.NewDecoder(resp.Body).Decode(&names)
+	resp.Body.Close()
+	found := false
+	for _, name := range names {
+		found = found || name == "EMAIL"
+	}
+	if !found {
+		t.Errorf("expected EMAIL in patterns, got %v", names)
+	}
+}
+
+// TestListenSocket tests serving on a Unix socket
+func TestListenSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anon.sock")
+
+	l, err := Listen(path, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fi.Mode().Perm() != SocketMode {
+		t.Errorf("expected mode %o, got %o", SocketMode, fi.Mode().Perm())
+	}
+
+	srv := &http.Server{Handler: newTestServer(t).Handler()}
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Shutdown(context.Background())
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://anonymizer/patterns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	// A socket a server is listening on is not taken from it
+	if _, err := Listen(path, false); err == nil ||
+		!strings.Contains(err.Error(), "already listening") {
+		t.Errorf("expected an error for a live socket, got %v", err)
+	}
+}
+
+// TestListenStaleSocket tests replacing a socket no server listens on
+func TestListenStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anon.sock")
+
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err := Listen(path, false)
+	if err != nil {
+		t.Fatalf("expected the stale socket to be replaced, got %v", err)
+	}
+	l.Close()
+}
+
+// TestListenTCP tests that only loopback addresses are listened on,
+// unless remote clients are allowed
+func TestListenTCP(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:0", "localhost:0",
+		"[::1]:0"} {
+		l, err := Listen(address, false)
+		if err != nil {
+			if address == "[::1]:0" {
+				continue // IPv6 may be unavailable
+			}
+			t.Fatalf("%s: unexpected error: %v", address, err)
+		}
+		l.Close()
+	}
+
+	for _, address := range []string{":0", "0.0.0.0:0", "10.0.0.5:8765"} {
+		if _, err := Listen(address, false); !errors.Is(err,
+			ErrNotLoopback) {
+			t.Errorf("%s: expected ErrNotLoopback, got %v", address, err)
+		}
+	}
+
+	l, err := Listen(":0", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l.Close()
+}
+
+// TestExtensionSQL tests generating the SQL functions
+func TestExtensionSQL(t *testing.T) {
+	sql, err := ExtensionSQL("/var/run/anon.sock", "anon")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		`CREATE OR REPLACE FUNCTION "anon".anon_generate(pattern text, original text)`,
+		`CREATE OR REPLACE FUNCTION "anon".anon_patterns()`,
+		`self.sock.connect("/var/run/anon.sock")`,
+		"LANGUAGE plpython3u",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("SQL missing %q", want)
+		}
+	}
+
+	sql, err = ExtensionSQL("db-host:8765", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(sql, `super().__init__("db-host", 8765, timeout=30)`) ||
+		!strings.Contains(sql, `"public".anon_generate`) {
+		t.Errorf("unexpected SQL for TCP address:\n%s", sql)
+	}
+
+	// Ports that are not numbers in range are rejected, so that nothing
+	// but a number is written into the Python code
+	for _, address := range []string{"h:1);import os;os.system('id')#",
+		"h:0", "h:65536", "h:-1", "db-host"} {
+		if _, err := ExtensionSQL(address, ""); err == nil {
+			t.Errorf("%q: expected an error", address)
+		}
+	}
+
+	// Addresses cannot end the comment line or the function's body
+	sql, err = ExtensionSQL("/tmp/a\nDROP TABLE x;$anon$.sock", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(sql, "\nDROP TABLE") ||
+		!strings.Contains(sql, `x;\u0024anon\u0024.sock")`) {
+		t.Errorf("address escaped the SQL:\n%s", sql)
+	}
+}
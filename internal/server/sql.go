@@ -0,0 +1,161 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultSchema is the schema in which the SQL functions are created.
+const DefaultSchema = "public"
+
+// ExtensionSQL returns SQL creating functions in the given schema that
+// call a server listening on address:
+//
+//   - anon_generate(pattern text, original text) returns the anonymized
+//     value for original, or NULL if original is NULL.
+//   - anon_patterns() returns the names of the patterns.
+//
+// The functions are written in PL/Python (plpython3u), which can connect
+// to the server's socket; the language must be installed, by a superuser,
+// before the SQL is run. A TCP address must have a port from 1 to 65535.
+func ExtensionSQL(address, schema string) (string, error) {
+	if schema == "" {
+		schema = DefaultSchema
+	}
+	ident := `"` + strings.ReplaceAll(schema, `"`, `""`) + `"`
+
+	literal := pythonString(address)
+	connect := fmt.Sprintf(`    class Connection(http.client.HTTPConnection):
+        def __init__(self):
+            super().__init__('localhost', timeout=30)
+
+        def connect(self):
+            self.sock = socket.socket(socket.AF_UNIX, socket.SOCK_STREAM)
+            self.sock.settimeout(self.timeout)
+            self.sock.connect(%s)`, literal)
+	if !IsSocketPath(address) {
+		host, port, err := splitAddress(address)
+		if err != nil {
+			return "", err
+		}
+		connect = fmt.Sprintf(`    class Connection(http.client.HTTPConnection):
+        def __init__(self):
+            super().__init__(%s, %d, timeout=30)`, pythonString(host), port)
+	}
+
+	return fmt.Sprintf(`-- Functions calling the pgEdge Anonymizer server at %[1]s, started
+-- with: pgedge-anonymizer serve --listen %[1]s
+
+CREATE EXTENSION IF NOT EXISTS plpython3u;
+CREATE SCHEMA IF NOT EXISTS %[2]s;
+
+CREATE OR REPLACE FUNCTION %[2]s._anon_request(method text, path text,
+    body text)
+RETURNS text
+LANGUAGE plpython3u
+VOLATILE
+AS $anon$
+import http.client
+import json
+import socket
+
+if 'pgedge_anonymizer' not in GD:
+%[3]s
+
+    GD['pgedge_anonymizer'] = {'class': Connection, 'conn': None}
+
+state = GD['pgedge_anonymizer']
+for attempt in range(2):
+    if state['conn'] is None:
+        state['conn'] = state['class']()
+    try:
+        state['conn'].request(method, path, body,
+                              {'Content-Type': 'application/json'})
+        resp = state['conn'].getresponse()
+        data = resp.read()
+        break
+    except (OSError, http.client.HTTPException) as e:
+        # Reconnect once, in case the server was restarted
+        state['conn'].close()
+        state['conn'] = None
+        if attempt == 1:
+            plpy.error('pgedge-anonymizer: cannot reach server: %%s' %% e)
+
+if resp.status != 200:
+    plpy.error('pgedge-anonymizer: %%s' %% json.loads(data).get('error',
+                                                              resp.reason))
+return data.decode('utf-8')
+$anon$;
+
+CREATE OR REPLACE FUNCTION %[2]s.anon_generate(pattern text, original text)
+RETURNS text
+LANGUAGE sql
+VOLATILE STRICT
+AS $anon$
+    SELECT %[2]s._anon_request('POST', '/generate',
+        json_build_object('pattern', pattern, 'value', original)::text
+    )::json ->> 'value'
+$anon$;
+
+CREATE OR REPLACE FUNCTION %[2]s.anon_patterns()
+RETURNS SETOF text
+LANGUAGE sql
+VOLATILE
+AS $anon$
+    SELECT json_array_elements_text(
+        %[2]s._anon_request('GET', '/patterns', NULL)::json)
+$anon$;
+`, commentText(address), ident, connect), nil
+}
+
+// splitAddress splits a TCP address into its host, localhost if it is
+// empty, and its port, which must be a number from 1 to 65535.
+func splitAddress(address string) (string, int, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid address %q: expected a socket "+
+			"path or host:port", address)
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return "", 0, fmt.Errorf("invalid port %q: must be a number from "+
+			"1 to 65535", port)
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	return host, n, nil
+}
+
+// pythonString returns s as a Python string literal that cannot end the
+// dollar quotes of the function's body: a JSON string, which is also a
+// valid Python string literal, with any $ escaped.
+func pythonString(s string) string {
+	literal, _ := json.Marshal(s)
+	return strings.ReplaceAll(string(literal), "$", `\u0024`)
+}
+
+// commentText returns s with its control characters, such as newlines,
+// replaced, so that it stays within an SQL comment line.
+func commentText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return '?'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,73 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package stats
+
+import (
+	"fmt"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// Coverage measures how much of the data likely to be personal, judged
+// from column names, a configuration anonymizes, as a single figure to
+// track as the configuration and the schema change.
+type Coverage struct {
+	Columns        int // Columns likely to hold personal data
+	CoveredColumns int // Those of them that are configured
+	Tables         int // Tables with columns likely to hold personal data
+	CoveredTables  int // Those of them with all such columns configured
+
+	// Uncovered lists the likely columns that are not configured.
+	Uncovered []errors.ColumnRef
+}
+
+// NewCoverage returns the coverage of the likely columns by the configured
+// ones.
+func NewCoverage(likely, configured []errors.ColumnRef) *Coverage {
+	isConfigured := make(map[string]bool, len(configured))
+	for _, col := range configured {
+		isConfigured[col.String()] = true
+	}
+
+	c := &Coverage{}
+	uncoveredTables := make(map[string]bool)
+	tables := make(map[string]bool)
+	for _, col := range likely {
+		table := col.Schema + "." + col.Table
+		tables[table] = true
+		c.Columns++
+		if isConfigured[col.String()] {
+			c.CoveredColumns++
+			continue
+		}
+		c.Uncovered = append(c.Uncovered, col)
+		uncoveredTables[table] = true
+	}
+	c.Tables = len(tables)
+	c.CoveredTables = len(tables) - len(uncoveredTables)
+	return c
+}
+
+// Percent returns the percentage of likely columns that are configured,
+// or 100 if there are none.
+func (c *Coverage) Percent() float64 {
+	if c.Columns == 0 {
+		return 100
+	}
+	return 100 * float64(c.CoveredColumns) / float64(c.Columns)
+}
+
+// String returns the coverage for display.
+func (c *Coverage) String() string {
+	return fmt.Sprintf("%.1f%% (%d of %d likely personal data columns, "+
+		"%d of %d tables)", c.Percent(), c.CoveredColumns, c.Columns,
+		c.CoveredTables, c.Tables)
+}
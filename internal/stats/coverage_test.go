@@ -0,0 +1,85 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package stats
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+// TestCoverage tests measuring the coverage of likely personal data
+func TestCoverage(t *testing.T) {
+	col := func(table, column string) errors.ColumnRef {
+		return errors.ColumnRef{Schema: "public", Table: table, Column: column}
+	}
+	likely := []errors.ColumnRef{
+		col("users", "email"),
+		col("users", "phone"),
+		col("orders", "address"),
+		col("contacts", "first_name"),
+	}
+	configured := []errors.ColumnRef{
+		col("users", "email"),
+		col("orders", "address"),
+		col("contacts", "first_name"),
+		col("orders", "notes"), // Not a likely column
+	}
+
+	c := NewCoverage(likely, configured)
+	if c.Columns != 4 || c.CoveredColumns != 3 || c.Tables != 3 ||
+		c.CoveredTables != 2 {
+		t.Errorf("unexpected coverage: %+v", c)
+	}
+	if len(c.Uncovered) != 1 || c.Uncovered[0] != col("users", "phone") {
+		t.Errorf("unexpected uncovered columns: %v", c.Uncovered)
+	}
+	want := "75.0% (3 of 4 likely personal data columns, 2 of 3 tables)"
+	if c.String() != want {
+		t.Errorf("got %q, want %q", c.String(), want)
+	}
+
+	if p := NewCoverage(nil, configured).Percent(); p != 100 {
+		t.Errorf("expected full coverage without likely columns, got %v", p)
+	}
+
+	t.Run("report", func(t *testing.T) {
+		s := testStats()
+		s.Coverage = c
+
+		text := NewReporter().String(s)
+		if !strings.Contains(text, "PII coverage: "+want+"\n"+
+			"  Not configured: public.users.phone\n") {
+			t.Errorf("expected coverage in report:\n%s", text)
+		}
+
+		var sb strings.Builder
+		if err := NewReporter().Write(s, FormatJSON, &sb); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got struct {
+			Coverage struct {
+				Percent   float64  `json:"percent"`
+				Columns   int      `json:"columns"`
+				Uncovered []string `json:"uncovered"`
+			} `json:"coverage"`
+		}
+		if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if got.Coverage.Percent != 75 || got.Coverage.Columns != 4 ||
+			len(got.Coverage.Uncovered) != 1 {
+			t.Errorf("unexpected coverage: %+v", got.Coverage)
+		}
+	})
+}
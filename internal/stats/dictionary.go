@@ -0,0 +1,55 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package stats
+
+import "fmt"
+
+// DictionaryStats measures how the dictionary of value mappings used
+// memory and disk, to tune its cache size by.
+type DictionaryStats struct {
+	Mappings int64 // Value mappings stored
+
+	// CacheHits are the lookups answered from memory, DiskHits those
+	// answered from the disk cache, and Misses those of values not yet
+	// mapped.
+	CacheHits int64
+	DiskHits  int64
+	Misses    int64
+
+	// Evictions is the number of mappings spilled over from memory, to
+	// be looked up on disk when next needed.
+	Evictions int64
+
+	// DiskBytes is the size of the disk cache's file, or zero if it is
+	// kept in memory.
+	DiskBytes int64
+	InMemory  bool
+}
+
+// HitRate returns the percentage of the lookups of mapped values answered
+// from memory, or 100 if there were none.
+func (d *DictionaryStats) HitRate() float64 {
+	hits := d.CacheHits + d.DiskHits
+	if hits == 0 {
+		return 100
+	}
+	return 100 * float64(d.CacheHits) / float64(hits)
+}
+
+// String returns the statistics for display.
+func (d *DictionaryStats) String() string {
+	storage := "in memory"
+	if !d.InMemory {
+		storage = formatBytes(d.DiskBytes) + " on disk"
+	}
+	return fmt.Sprintf("%d mappings, %.1f%% cache hit rate, %d spilled "+
+		"over, %s", d.Mappings, d.HitRate(), d.Evictions, storage)
+}
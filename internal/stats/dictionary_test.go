@@ -0,0 +1,66 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package stats
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestDictionaryStats tests reporting how the dictionary used memory and
+// disk
+func TestDictionaryStats(t *testing.T) {
+	d := &DictionaryStats{
+		Mappings:  1200,
+		CacheHits: 900,
+		DiskHits:  100,
+		Misses:    1200,
+		Evictions: 200,
+		DiskBytes: 3 << 20,
+	}
+
+	if rate := d.HitRate(); rate != 90 {
+		t.Errorf("expected a hit rate of 90%%, got %v", rate)
+	}
+	if rate := (&DictionaryStats{Misses: 5}).HitRate(); rate != 100 {
+		t.Errorf("expected a hit rate of 100%% without hits, got %v", rate)
+	}
+
+	s := testStats()
+	s.Dictionary = d
+
+	text := NewReporter().String(s)
+	want := "Dictionary: 1200 mappings, 90.0% cache hit rate, 200 spilled " +
+		"over, 3.0 MiB on disk\n"
+	if !strings.Contains(text, want) {
+		t.Errorf("expected dictionary in report:\n%s", text)
+	}
+
+	var sb strings.Builder
+	if err := NewReporter().Write(s, FormatJSON, &sb); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got struct {
+		Dictionary struct {
+			HitRate   float64 `json:"hit_rate"`
+			Evictions int64   `json:"evictions"`
+			DiskBytes int64   `json:"disk_bytes"`
+		} `json:"dictionary"`
+	}
+	if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.Dictionary.HitRate != 90 || got.Dictionary.Evictions != 200 ||
+		got.Dictionary.DiskBytes != 3<<20 {
+		t.Errorf("unexpected dictionary: %+v", got.Dictionary)
+	}
+}
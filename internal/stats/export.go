@@ -0,0 +1,301 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Report formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// TargetStats holds the statistics for one of several target databases.
+type TargetStats struct {
+	Name  string
+	Stats *Stats
+}
+
+// ValidateFormat returns an error if format is not a known report format.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON, FormatCSV:
+		return nil
+	default:
+		return fmt.Errorf("unknown report format %q (expected text, json, "+
+			"or csv)", format)
+	}
+}
+
+// jsonColumn is the JSON form of ColumnStats.
+type jsonColumn struct {
+	Column             string `json:"column"`
+	RowsProcessed      int64  `json:"rows_processed"`
+	ValuesAnonymized   int64  `json:"values_anonymized"`
+	UniqueValues       int64  `json:"unique_values"`
+	Collisions         int64  `json:"collisions"`
+	CollisionFallbacks int64  `json:"collision_fallbacks"`
+	DurationMS         int64  `json:"duration_ms"`
+	MaxStatementBytes  int64  `json:"max_statement_bytes"`
+	jsonPhases
+}
+
+// jsonPhases is the JSON form of Phases.
+type jsonPhases struct {
+	FetchMS    int64 `json:"fetch_ms"`
+	GenerateMS int64 `json:"generate_ms"`
+	UpdateMS   int64 `json:"update_ms"`
+	WaitMS     int64 `json:"wait_ms"`
+}
+
+// toJSONPhases converts phases to their JSON form.
+func toJSONPhases(p Phases) jsonPhases {
+	return jsonPhases{
+		FetchMS:    p.Fetch.Milliseconds(),
+		GenerateMS: p.Generate.Milliseconds(),
+		UpdateMS:   p.Update.Milliseconds(),
+		WaitMS:     p.Wait.Milliseconds(),
+	}
+}
+
+// jsonDerived is the JSON form of DerivedColumnStats.
+type jsonDerived struct {
+	Column string `json:"column"`
+	Status string `json:"status"`
+	Stale  bool   `json:"stale"`
+}
+
+// jsonTable is the JSON form of TableStats.
+type jsonTable struct {
+	Table            string `json:"table"`
+	Columns          int    `json:"columns"`
+	RowsProcessed    int64  `json:"rows_processed"`
+	ValuesAnonymized int64  `json:"values_anonymized"`
+	DurationMS       int64  `json:"duration_ms"`
+	LockWaitMS       int64  `json:"lock_wait_ms"`
+
+	// RebuiltIndexes are the definitions of the indexes rebuilt
+	RebuiltIndexes []string `json:"rebuilt_indexes,omitempty"`
+	IndexRebuildMS int64    `json:"index_rebuild_ms,omitempty"`
+}
+
+// jsonCoverage is the JSON form of Coverage.
+type jsonCoverage struct {
+	Percent        float64  `json:"percent"`
+	Columns        int      `json:"columns"`
+	CoveredColumns int      `json:"covered_columns"`
+	Tables         int      `json:"tables"`
+	CoveredTables  int      `json:"covered_tables"`
+	Uncovered      []string `json:"uncovered,omitempty"`
+}
+
+// jsonDictionary is the JSON form of DictionaryStats.
+type jsonDictionary struct {
+	Mappings  int64   `json:"mappings"`
+	CacheHits int64   `json:"cache_hits"`
+	DiskHits  int64   `json:"disk_hits"`
+	Misses    int64   `json:"misses"`
+	HitRate   float64 `json:"hit_rate"`
+	Evictions int64   `json:"evictions"`
+	DiskBytes int64   `json:"disk_bytes"`
+	InMemory  bool    `json:"in_memory"`
+}
+
+// jsonStats is the JSON form of Stats.
+type jsonStats struct {
+	Target                  string          `json:"target,omitempty"`
+	Columns                 []jsonColumn    `json:"columns"`
+	Derived                 []jsonDerived   `json:"derived,omitempty"`
+	Tables                  []jsonTable     `json:"tables,omitempty"`
+	TotalRows               int64           `json:"total_rows"`
+	TotalAnonymized         int64           `json:"total_anonymized"`
+	TotalUnique             int64           `json:"total_unique"`
+	TotalCollisions         int64           `json:"total_collisions"`
+	TotalCollisionFallbacks int64           `json:"total_collision_fallbacks"`
+	DurationMS              int64           `json:"duration_ms"`
+	MaxStatementBytes       int64           `json:"max_statement_bytes"`
+	LockWaitMS              int64           `json:"lock_wait_ms"`
+	Coverage                *jsonCoverage   `json:"coverage,omitempty"`
+	Dictionary              *jsonDictionary `json:"dictionary,omitempty"`
+	jsonPhases
+}
+
+// toJSON converts statistics to their JSON form.
+func toJSON(target string, stats *Stats) jsonStats {
+	js := jsonStats{
+		Target:                  target,
+		Columns:                 make([]jsonColumn, 0, len(stats.Columns)),
+		TotalRows:               stats.TotalRows,
+		TotalAnonymized:         stats.TotalAnonymized,
+		TotalUnique:             stats.TotalUnique,
+		TotalCollisions:         stats.TotalCollisions,
+		TotalCollisionFallbacks: stats.TotalCollisionFallbacks,
+		DurationMS:              stats.TotalDuration.Milliseconds(),
+		MaxStatementBytes:       stats.MaxStatementBytes,
+		LockWaitMS:              stats.TotalLockWait.Milliseconds(),
+		jsonPhases:              toJSONPhases(stats.TotalPhases),
+	}
+	for _, col := range stats.Columns {
+		js.Columns = append(js.Columns, jsonColumn{
+			Column:             col.Column.String(),
+			RowsProcessed:      col.RowsProcessed,
+			ValuesAnonymized:   col.ValuesAnonymized,
+			UniqueValues:       col.UniqueValues,
+			Collisions:         col.Collisions,
+			CollisionFallbacks: col.CollisionFallbacks,
+			DurationMS:         col.Duration.Milliseconds(),
+			MaxStatementBytes:  col.MaxStatementBytes,
+			jsonPhases:         toJSONPhases(col.Phases),
+		})
+	}
+	for _, d := range stats.Derived {
+		js.Derived = append(js.Derived, jsonDerived{
+			Column: d.Column.String(),
+			Status: d.Status,
+			Stale:  d.Stale,
+		})
+	}
+	for _, t := range stats.Tables {
+		js.Tables = append(js.Tables, jsonTable{
+			Table:            t.Table,
+			Columns:          t.Columns,
+			RowsProcessed:    t.RowsProcessed,
+			ValuesAnonymized: t.ValuesAnonymized,
+			DurationMS:       t.Duration.Milliseconds(),
+			LockWaitMS:       t.LockWait.Milliseconds(),
+			RebuiltIndexes:   t.RebuiltIndexes,
+			IndexRebuildMS:   t.IndexRebuild.Milliseconds(),
+		})
+	}
+	if c := stats.Coverage; c != nil {
+		js.Coverage = &jsonCoverage{
+			Percent:        c.Percent(),
+			Columns:        c.Columns,
+			CoveredColumns: c.CoveredColumns,
+			Tables:         c.Tables,
+			CoveredTables:  c.CoveredTables,
+		}
+		for _, col := range c.Uncovered {
+			js.Coverage.Uncovered = append(js.Coverage.Uncovered, col.String())
+		}
+	}
+	if d := stats.Dictionary; d != nil {
+		js.Dictionary = &jsonDictionary{
+			Mappings:  d.Mappings,
+			CacheHits: d.CacheHits,
+			DiskHits:  d.DiskHits,
+			Misses:    d.Misses,
+			HitRate:   d.HitRate(),
+			Evictions: d.Evictions,
+			DiskBytes: d.DiskBytes,
+			InMemory:  d.InMemory,
+		}
+	}
+	return js
+}
+
+// Write writes the statistics in the given format: the text table shown
+// by Report, a JSON object, or CSV with a row per column.
+func (r *Reporter) Write(stats *Stats, format string, w io.Writer) error {
+	switch format {
+	case FormatText:
+		r.Report(stats, w)
+		return nil
+	case FormatJSON:
+		return writeJSON(w, toJSON("", stats))
+	case FormatCSV:
+		return writeCSV(w, false, []TargetStats{{Stats: stats}})
+	default:
+		return ValidateFormat(format)
+	}
+}
+
+// WriteTargets writes the statistics for several target databases in the
+// given format: a text table per target, a JSON object with a targets
+// array, or CSV with a leading target column.
+func (r *Reporter) WriteTargets(targets []TargetStats, format string,
+	w io.Writer) error {
+	switch format {
+	case FormatText:
+		for _, t := range targets {
+			fmt.Fprintf(w, "\nTarget: %s\n", t.Name)
+			r.Report(t.Stats, w)
+		}
+		return nil
+	case FormatJSON:
+		out := struct {
+			Targets []jsonStats `json:"targets"`
+		}{Targets: make([]jsonStats, 0, len(targets))}
+		for _, t := range targets {
+			out.Targets = append(out.Targets, toJSON(t.Name, t.Stats))
+		}
+		return writeJSON(w, out)
+	case FormatCSV:
+		return writeCSV(w, true, targets)
+	default:
+		return ValidateFormat(format)
+	}
+}
+
+// writeJSON writes a value as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeCSV writes a header and a row per column, with the target name in
+// the first field if withTarget is set.
+func writeCSV(w io.Writer, withTarget bool, targets []TargetStats) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"column", "rows_processed", "values_anonymized",
+		"unique_values", "duration_ms", "fetch_ms", "generate_ms",
+		"update_ms", "wait_ms"}
+	if withTarget {
+		header = append([]string{"target"}, header...)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, t := range targets {
+		for _, col := range t.Stats.Columns {
+			record := []string{
+				col.Column.String(),
+				strconv.FormatInt(col.RowsProcessed, 10),
+				strconv.FormatInt(col.ValuesAnonymized, 10),
+				strconv.FormatInt(col.UniqueValues, 10),
+				strconv.FormatInt(col.Duration.Milliseconds(), 10),
+				strconv.FormatInt(col.Phases.Fetch.Milliseconds(), 10),
+				strconv.FormatInt(col.Phases.Generate.Milliseconds(), 10),
+				strconv.FormatInt(col.Phases.Update.Milliseconds(), 10),
+				strconv.FormatInt(col.Phases.Wait.Milliseconds(), 10),
+			}
+			if withTarget {
+				record = append([]string{t.Name}, record...)
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,325 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package stats
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+)
+
+func testStats() *Stats {
+	c := NewCollector()
+	c.RecordColumn(ColumnStats{
+		Column:            errors.ColumnRef{Schema: "public", Table: "users", Column: "email"},
+		RowsProcessed:     100,
+		ValuesAnonymized:  90,
+		UniqueValues:      80,
+		Duration:          1500 * time.Millisecond,
+		MaxStatementBytes: 3 << 20,
+		Phases: NewPhases(1500*time.Millisecond, 300*time.Millisecond,
+			200*time.Millisecond, 0),
+	})
+	c.RecordDerived(DerivedColumnStats{
+		Column: errors.ColumnRef{Schema: "public", Table: "users", Column: "tsv"},
+		Status: "refreshed",
+	})
+	c.RecordTable(TableStats{Table: "public.users", LockWait: 250 * time.Millisecond})
+	return c.Finalize(2 * time.Second)
+}
+
+// TestWrite tests writing statistics in each report format
+func TestWrite(t *testing.T) {
+	r := NewReporter()
+
+	t.Run("json", func(t *testing.T) {
+		var sb strings.Builder
+		if err := r.Write(testStats(), FormatJSON, &sb); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var got struct {
+			Columns []struct {
+				Column           string `json:"column"`
+				ValuesAnonymized int64  `json:"values_anonymized"`
+				DurationMS       int64  `json:"duration_ms"`
+				GenerateMS       int64  `json:"generate_ms"`
+			} `json:"columns"`
+			Derived []map[string]any `json:"derived"`
+			Tables  []struct {
+				Table            string `json:"table"`
+				Columns          int    `json:"columns"`
+				ValuesAnonymized int64  `json:"values_anonymized"`
+				LockWaitMS       int64  `json:"lock_wait_ms"`
+			} `json:"tables"`
+			TotalAnonymized   int64 `json:"total_anonymized"`
+			DurationMS        int64 `json:"duration_ms"`
+			MaxStatementBytes int64 `json:"max_statement_bytes"`
+			LockWaitMS        int64 `json:"lock_wait_ms"`
+			FetchMS           int64 `json:"fetch_ms"`
+		}
+		if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, sb.String())
+		}
+		if len(got.Columns) != 1 || got.Columns[0].Column != "public.users.email" ||
+			got.Columns[0].ValuesAnonymized != 90 ||
+			got.Columns[0].DurationMS != 1500 ||
+			got.Columns[0].GenerateMS != 1000 {
+			t.Errorf("unexpected columns: %+v", got.Columns)
+		}
+		if got.TotalAnonymized != 90 || got.DurationMS != 2000 ||
+			got.MaxStatementBytes != 3<<20 || got.FetchMS != 300 {
+			t.Errorf("unexpected totals: %+v", got)
+		}
+		if len(got.Derived) != 1 {
+			t.Errorf("unexpected derived columns: %v", got.Derived)
+		}
+		if len(got.Tables) != 1 || got.Tables[0].Table != "public.users" ||
+			got.Tables[0].LockWaitMS != 250 || got.LockWaitMS != 250 ||
+			got.Tables[0].Columns != 1 || got.Tables[0].ValuesAnonymized != 90 {
+			t.Errorf("unexpected tables: %+v, %d", got.Tables, got.LockWaitMS)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var sb strings.Builder
+		if err := r.Write(testStats(), FormatCSV, &sb); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "column,rows_processed,values_anonymized,unique_values,duration_ms," +
+			"fetch_ms,generate_ms,update_ms,wait_ms\n" +
+			"public.users.email,100,90,80,1500,300,1000,200,0\n"
+		if sb.String() != want {
+			t.Errorf("got:\n%s\nwant:\n%s", sb.String(), want)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var sb strings.Builder
+		if err := r.Write(testStats(), FormatText, &sb); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sb.String() != r.String(testStats()) {
+			t.Error("text format differs from Report")
+		}
+		if !strings.Contains(sb.String(), "Largest update statement: 3.0 MiB") {
+			t.Errorf("expected largest statement size in report:\n%s",
+				sb.String())
+		}
+		if !strings.Contains(sb.String(), "Time by phase: fetch 300ms, "+
+			"generate 1.0s, update 200ms (33% database, 66% generating)\n") {
+			t.Errorf("expected time by phase in report:\n%s", sb.String())
+		}
+		if !strings.Contains(sb.String(), "Lock wait: 250ms\n  public.users: 250ms\n") {
+			t.Errorf("expected lock waits in report:\n%s", sb.String())
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := r.Write(testStats(), "xml", &strings.Builder{}); err == nil {
+			t.Error("expected error for unknown format")
+		}
+	})
+}
+
+// TestTableTotals tests that the columns of each table are totalled
+func TestTableTotals(t *testing.T) {
+	c := NewCollector()
+	c.RecordTable(TableStats{Table: "public.users", LockWait: time.Second})
+	for _, col := range []struct {
+		name   string
+		rows   int64
+		values int64
+	}{{"email", 100, 90}, {"name", 100, 95}} {
+		c.RecordColumn(ColumnStats{
+			Column: errors.ColumnRef{Schema: "public", Table: "users",
+				Column: col.name},
+			RowsProcessed:    col.rows,
+			ValuesAnonymized: col.values,
+			Duration:         time.Second,
+		})
+	}
+	c.RecordColumn(ColumnStats{
+		Column:           errors.ColumnRef{Schema: "public", Table: "orders", Column: "note"},
+		RowsProcessed:    10,
+		ValuesAnonymized: 5,
+	})
+	stats := c.Finalize(3 * time.Second)
+
+	if len(stats.Tables) != 2 {
+		t.Fatalf("expected 2 tables, got %+v", stats.Tables)
+	}
+	users := stats.Tables[0]
+	if users.Table != "public.users" || users.Columns != 2 ||
+		users.RowsProcessed != 100 || users.ValuesAnonymized != 185 ||
+		users.Duration != 2*time.Second || users.LockWait != time.Second {
+		t.Errorf("unexpected table totals: %+v", users)
+	}
+	if stats.Tables[1].Table != "public.orders" || stats.Tables[1].Columns != 1 {
+		t.Errorf("unexpected table totals: %+v", stats.Tables[1])
+	}
+
+	report := NewReporter().String(stats)
+	if !strings.Contains(report, "Tables processed: 2\n  public.users: 2 "+
+		"columns, 100 rows, 185 values anonymized in 2.0s\n") {
+		t.Errorf("expected table totals in report:\n%s", report)
+	}
+}
+
+// TestMerge tests that the statistics of a collector are merged into
+// another's
+func TestMerge(t *testing.T) {
+	c := NewCollector()
+	c.RecordColumn(ColumnStats{RowsProcessed: 10})
+
+	attempt := NewCollector()
+	attempt.RecordTable(TableStats{Table: "public.users"})
+	attempt.RecordColumn(ColumnStats{RowsProcessed: 5})
+	attempt.RecordDerived(DerivedColumnStats{})
+	c.Merge(attempt)
+
+	stats := c.Finalize(time.Second)
+	if stats.TotalRows != 15 || len(stats.Columns) != 2 ||
+		len(stats.Derived) != 1 {
+		t.Errorf("unexpected merged statistics: %+v", stats)
+	}
+}
+
+// TestCollisions tests reporting the collisions of unique columns
+func TestCollisions(t *testing.T) {
+	c := NewCollector()
+	c.RecordColumn(ColumnStats{
+		Column:             errors.ColumnRef{Schema: "public", Table: "users", Column: "login"},
+		RowsProcessed:      100,
+		ValuesAnonymized:   100,
+		Collisions:         7,
+		CollisionFallbacks: 2,
+	})
+	c.RecordColumn(ColumnStats{
+		Column:           errors.ColumnRef{Schema: "public", Table: "users", Column: "notes"},
+		RowsProcessed:    100,
+		ValuesAnonymized: 100,
+	})
+	stats := c.Finalize(time.Second)
+
+	report := NewReporter().String(stats)
+	if !strings.Contains(report, "Unique value collisions: 7 (2 resolved "+
+		"by on_collision)\n  public.users.login: 7 (2)\n") ||
+		strings.Contains(report, "public.users.notes: 0") {
+		t.Errorf("expected collisions in report:\n%s", report)
+	}
+
+	var sb strings.Builder
+	if err := NewReporter().Write(stats, FormatJSON, &sb); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got struct {
+		Columns []struct {
+			Collisions         int64 `json:"collisions"`
+			CollisionFallbacks int64 `json:"collision_fallbacks"`
+		} `json:"columns"`
+		TotalCollisions int64 `json:"total_collisions"`
+	}
+	if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, sb.String())
+	}
+	if got.TotalCollisions != 7 || got.Columns[0].Collisions != 7 ||
+		got.Columns[0].CollisionFallbacks != 2 {
+		t.Errorf("unexpected collisions: %+v", got)
+	}
+
+	if report := NewReporter().String(testStats()); strings.Contains(report,
+		"collisions") {
+		t.Errorf("expected no collisions in report:\n%s", report)
+	}
+}
+
+// TestRebuiltIndexes tests reporting the indexes dropped and recreated
+func TestRebuiltIndexes(t *testing.T) {
+	definition := "CREATE INDEX users_email_idx ON public.users " +
+		"USING btree (email)"
+	c := NewCollector()
+	c.RecordTable(TableStats{Table: "public.users", LockWait: time.Second})
+	c.RecordTable(TableStats{Table: "public.users",
+		RebuiltIndexes: []string{definition}, IndexRebuild: 2 * time.Second})
+	c.RecordColumn(ColumnStats{
+		Column:        errors.ColumnRef{Schema: "public", Table: "users", Column: "email"},
+		RowsProcessed: 100,
+	})
+	stats := c.Finalize(3 * time.Second)
+
+	if len(stats.Tables) != 1 || stats.Tables[0].LockWait != time.Second ||
+		len(stats.Tables[0].RebuiltIndexes) != 1 {
+		t.Fatalf("unexpected table totals: %+v", stats.Tables)
+	}
+	report := NewReporter().String(stats)
+	if !strings.Contains(report, "Indexes rebuilt: 1 in 2.0s\n"+
+		"  public.users: 1 in 2.0s\n") {
+		t.Errorf("expected rebuilt indexes in report:\n%s", report)
+	}
+
+	var sb strings.Builder
+	if err := NewReporter().Write(stats, FormatJSON, &sb); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got struct {
+		Tables []struct {
+			RebuiltIndexes []string `json:"rebuilt_indexes"`
+			IndexRebuildMS int64    `json:"index_rebuild_ms"`
+		} `json:"tables"`
+	}
+	if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, sb.String())
+	}
+	if len(got.Tables) != 1 || got.Tables[0].IndexRebuildMS != 2000 ||
+		len(got.Tables[0].RebuiltIndexes) != 1 ||
+		got.Tables[0].RebuiltIndexes[0] != definition {
+		t.Errorf("unexpected rebuilt indexes: %+v", got)
+	}
+}
+
+// TestWriteTargets tests writing statistics for several targets
+func TestWriteTargets(t *testing.T) {
+	r := NewReporter()
+	targets := []TargetStats{
+		{Name: "tenant_a", Stats: testStats()},
+		{Name: "tenant_b", Stats: testStats()},
+	}
+
+	var sb strings.Builder
+	if err := r.WriteTargets(targets, FormatCSV, &sb); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "target,column,") ||
+		!strings.HasPrefix(lines[2], "tenant_b,public.users.email,") {
+		t.Errorf("unexpected CSV:\n%s", sb.String())
+	}
+
+	sb.Reset()
+	if err := r.WriteTargets(targets, FormatJSON, &sb); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got struct {
+		Targets []struct {
+			Target string `json:"target"`
+		} `json:"targets"`
+	}
+	if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got.Targets) != 2 || got.Targets[1].Target != "tenant_b" {
+		t.Errorf("unexpected targets: %+v", got.Targets)
+	}
+}
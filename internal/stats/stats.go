@@ -29,21 +29,145 @@ type ColumnStats struct {
 	ValuesAnonymized int64
 	UniqueValues     int64
 	Duration         time.Duration
+
+	// Collisions is the number of generated values that had already been
+	// given to another value of a column with a unique constraint, and
+	// CollisionFallbacks the number of those made unique by the column's
+	// on_collision strategy rather than by generating them again.
+	Collisions         int64
+	CollisionFallbacks int64
+
+	// MaxStatementBytes is the size of the largest batch update statement
+	// written for the column.
+	MaxStatementBytes int64
+
+	// Phases breaks the duration down by the phase of processing.
+	Phases Phases
+}
+
+// Phases breaks the time taken to anonymize columns down by the phase of
+// processing, showing whether a run is bound by the database or by
+// generating values.
+type Phases struct {
+	Fetch    time.Duration // Reading rows
+	Generate time.Duration // Generating values, including the dictionary
+	Update   time.Duration // Writing rows back, and committing them
+	Wait     time.Duration // Throttled
+}
+
+// NewPhases returns the phases of a column that took duration in all, of
+// which the given time was spent reading and writing rows and throttled;
+// the rest was spent generating values.
+func NewPhases(duration, fetch, update, wait time.Duration) Phases {
+	return Phases{
+		Fetch:    fetch,
+		Generate: max(duration-fetch-update-wait, 0),
+		Update:   update,
+		Wait:     wait,
+	}
+}
+
+// add adds the time of other phases to p.
+func (p *Phases) add(other Phases) {
+	p.Fetch += other.Fetch
+	p.Generate += other.Generate
+	p.Update += other.Update
+	p.Wait += other.Wait
+}
+
+// total returns the time of all the phases.
+func (p Phases) total() time.Duration {
+	return p.Fetch + p.Generate + p.Update + p.Wait
+}
+
+// String returns the time of each phase, leaving out waits if there were
+// none.
+func (p Phases) String() string {
+	s := fmt.Sprintf("fetch %s, generate %s, update %s",
+		formatDuration(p.Fetch), formatDuration(p.Generate),
+		formatDuration(p.Update))
+	if p.Wait >= time.Millisecond {
+		s += ", wait " + formatDuration(p.Wait)
+	}
+	return s
+}
+
+// DerivedColumnStats records how a column derived from anonymized columns,
+// such as a tsvector column, was brought up to date.
+type DerivedColumnStats struct {
+	Column errors.ColumnRef
+	Status string // How the column was handled, for display
+	Stale  bool   // The column may still hold values from the original data
+}
+
+// TableStats holds statistics for a table with anonymized columns.
+type TableStats struct {
+	Table string // schema.table
+
+	// LockWait is the time taken to lock the table, including any wait
+	// for other sessions holding a conflicting lock.
+	LockWait time.Duration
+
+	// RebuiltIndexes are the definitions of the indexes dropped before
+	// the table was anonymized and created again after, with
+	// drop_indexes, and IndexRebuild the time taken to create them.
+	RebuiltIndexes []string
+	IndexRebuild   time.Duration
+
+	// The totals of the table's columns, set by Finalize. RowsProcessed
+	// is the most rows processed in any one column, as each column reads
+	// the same rows.
+	Columns          int
+	RowsProcessed    int64
+	ValuesAnonymized int64
+	Duration         time.Duration
 }
 
 // Stats holds overall anonymization statistics.
 type Stats struct {
 	Columns         []ColumnStats
+	Derived         []DerivedColumnStats
+	Tables          []TableStats
 	TotalRows       int64
 	TotalAnonymized int64
 	TotalUnique     int64
 	TotalDuration   time.Duration
+
+	// TotalCollisions and TotalCollisionFallbacks are the totals of the
+	// columns' collisions.
+	TotalCollisions         int64
+	TotalCollisionFallbacks int64
+
+	// MaxStatementBytes is the size of the largest batch update statement
+	// written for any column.
+	MaxStatementBytes int64
+
+	// TotalLockWait is the time taken to lock all the tables.
+	TotalLockWait time.Duration
+
+	// TotalRebuiltIndexes is the number of indexes dropped and created
+	// again, and TotalIndexRebuild the time taken to create them.
+	TotalRebuiltIndexes int
+	TotalIndexRebuild   time.Duration
+
+	// TotalPhases is the time taken by each phase across all the columns.
+	TotalPhases Phases
+
+	// Coverage is how many of the columns likely to hold personal data
+	// were configured, or nil if it was not measured.
+	Coverage *Coverage
+
+	// Dictionary is how the dictionary used memory and disk, or nil if it
+	// was not measured.
+	Dictionary *DictionaryStats
 }
 
 // Collector collects statistics during processing.
 type Collector struct {
 	mu      sync.Mutex
 	columns []ColumnStats
+	derived []DerivedColumnStats
+	tables  []TableStats
 }
 
 // NewCollector creates a new statistics collector.
@@ -60,6 +184,31 @@ func (c *Collector) RecordColumn(stats ColumnStats) {
 	c.columns = append(c.columns, stats)
 }
 
+// RecordDerived records how a derived column was handled.
+func (c *Collector) RecordDerived(stats DerivedColumnStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.derived = append(c.derived, stats)
+}
+
+// RecordTable records statistics for a table with anonymized columns.
+func (c *Collector) RecordTable(stats TableStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tables = append(c.tables, stats)
+}
+
+// Merge records the statistics collected by other.
+func (c *Collector) Merge(other *Collector) {
+	other.mu.Lock()
+	defer other.mu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.columns = append(c.columns, other.columns...)
+	c.derived = append(c.derived, other.derived...)
+	c.tables = append(c.tables, other.tables...)
+}
+
 // Finalize calculates totals and returns final statistics.
 func (c *Collector) Finalize(totalDuration time.Duration) *Stats {
 	c.mu.Lock()
@@ -67,6 +216,7 @@ func (c *Collector) Finalize(totalDuration time.Duration) *Stats {
 
 	stats := &Stats{
 		Columns:       c.columns,
+		Derived:       c.derived,
 		TotalDuration: totalDuration,
 	}
 
@@ -74,11 +224,56 @@ func (c *Collector) Finalize(totalDuration time.Duration) *Stats {
 		stats.TotalRows += col.RowsProcessed
 		stats.TotalAnonymized += col.ValuesAnonymized
 		stats.TotalUnique += col.UniqueValues
+		stats.TotalCollisions += col.Collisions
+		stats.TotalCollisionFallbacks += col.CollisionFallbacks
+		stats.MaxStatementBytes = max(stats.MaxStatementBytes,
+			col.MaxStatementBytes)
+		stats.TotalPhases.add(col.Phases)
+	}
+	for _, t := range c.tables {
+		stats.TotalLockWait += t.LockWait
+		stats.TotalRebuiltIndexes += len(t.RebuiltIndexes)
+		stats.TotalIndexRebuild += t.IndexRebuild
 	}
+	stats.Tables = tableTotals(c.tables, c.columns)
 
 	return stats
 }
 
+// tableTotals returns the statistics of each table with its columns'
+// totals added, in the order the tables were recorded, followed by any
+// tables of columns whose table was not recorded. A table recorded more
+// than once has its lock waits added together.
+func tableTotals(tables []TableStats, columns []ColumnStats) []TableStats {
+	totals := make([]TableStats, 0, len(tables))
+	index := make(map[string]int)
+	add := func(name string) *TableStats {
+		i, ok := index[name]
+		if !ok {
+			i = len(totals)
+			index[name] = i
+			totals = append(totals, TableStats{Table: name})
+		}
+		return &totals[i]
+	}
+
+	for _, t := range tables {
+		total := add(t.Table)
+		total.LockWait += t.LockWait
+		total.RebuiltIndexes = append(total.RebuiltIndexes,
+			t.RebuiltIndexes...)
+		total.IndexRebuild += t.IndexRebuild
+	}
+	for _, col := range columns {
+		t := add(col.Column.Schema + "." + col.Column.Table)
+		t.Columns++
+		t.RowsProcessed = max(t.RowsProcessed, col.RowsProcessed)
+		t.ValuesAnonymized += col.ValuesAnonymized
+		t.Duration += col.Duration
+	}
+	return totals
+}
+
 // Reporter formats and displays statistics.
 type Reporter struct{}
 
@@ -160,8 +355,87 @@ func (r *Reporter) Report(stats *Stats, w io.Writer) {
 	// Additional info
 	fmt.Fprintln(w)
 	fmt.Fprintf(w, "Columns processed: %d\n", len(stats.Columns))
+	fmt.Fprintf(w, "Tables processed: %d\n", len(stats.Tables))
+	for _, t := range stats.Tables {
+		if t.Columns > 1 {
+			fmt.Fprintf(w, "  %s: %d columns, %d rows, %d values "+
+				"anonymized in %s\n", t.Table, t.Columns, t.RowsProcessed,
+				t.ValuesAnonymized, formatDuration(t.Duration))
+		}
+	}
 	fmt.Fprintf(w, "Unique values anonymized: %d\n", stats.TotalUnique)
+	if stats.TotalCollisions > 0 {
+		fmt.Fprintf(w, "Unique value collisions: %d (%d resolved by "+
+			"on_collision)\n", stats.TotalCollisions,
+			stats.TotalCollisionFallbacks)
+		for _, col := range stats.Columns {
+			if col.Collisions > 0 {
+				fmt.Fprintf(w, "  %s: %d (%d)\n", col.Column.String(),
+					col.Collisions, col.CollisionFallbacks)
+			}
+		}
+	}
 	fmt.Fprintf(w, "Total duration: %s\n", formatDuration(stats.TotalDuration))
+	if stats.MaxStatementBytes > 0 {
+		fmt.Fprintf(w, "Largest update statement: %s\n",
+			formatBytes(stats.MaxStatementBytes))
+	}
+
+	if total := stats.TotalPhases; total.total() >= time.Millisecond {
+		percent := func(d time.Duration) int {
+			return int(100 * d / total.total())
+		}
+		fmt.Fprintf(w, "Time by phase: %s (%d%% database, %d%% generating)\n",
+			total, percent(total.Fetch+total.Update), percent(total.Generate))
+		if len(stats.Columns) > 1 {
+			for _, col := range stats.Columns {
+				fmt.Fprintf(w, "  %s: %s\n", col.Column.String(), col.Phases)
+			}
+		}
+	}
+
+	if stats.TotalLockWait >= time.Millisecond {
+		fmt.Fprintf(w, "Lock wait: %s\n", formatDuration(stats.TotalLockWait))
+		for _, t := range stats.Tables {
+			if t.LockWait >= time.Millisecond {
+				fmt.Fprintf(w, "  %s: %s\n", t.Table, formatDuration(t.LockWait))
+			}
+		}
+	}
+
+	if stats.TotalRebuiltIndexes > 0 {
+		fmt.Fprintf(w, "Indexes rebuilt: %d in %s\n", stats.TotalRebuiltIndexes,
+			formatDuration(stats.TotalIndexRebuild))
+		for _, t := range stats.Tables {
+			if len(t.RebuiltIndexes) > 0 {
+				fmt.Fprintf(w, "  %s: %d in %s\n", t.Table,
+					len(t.RebuiltIndexes), formatDuration(t.IndexRebuild))
+			}
+		}
+	}
+
+	if d := stats.Dictionary; d != nil {
+		fmt.Fprintf(w, "Dictionary: %s\n", d)
+	}
+
+	if c := stats.Coverage; c != nil {
+		fmt.Fprintf(w, "PII coverage: %s\n", c)
+		for _, col := range c.Uncovered {
+			fmt.Fprintf(w, "  Not configured: %s\n", col.String())
+		}
+	}
+
+	if len(stats.Derived) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Derived columns:")
+		for _, d := range stats.Derived {
+			marker := " "
+			if d.Stale {
+				marker = "!"
+			}
+			fmt.Fprintf(w, "  %s %s: %s\n", marker, d.Column.String(), d.Status)
+		}
+	}
 }
 
 // String returns a string representation of the statistics.
@@ -184,3 +458,17 @@ func formatDuration(d time.Duration) string {
 	}
 	return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
 }
+
+// formatBytes formats a size in bytes for display.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,84 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package stats
+
+import (
+	"io"
+	"time"
+)
+
+// Statuses of a run in its summary.
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+
+	// StatusStopped means the run reached its max_duration, and can be
+	// resumed from its checkpoint.
+	StatusStopped = "stopped"
+)
+
+// Summary is the outcome of a run, written as a single JSON object once
+// the run ends, whether it succeeded or not, for schedulers such as
+// Kubernetes jobs and cron to act on.
+type Summary struct {
+	Status   string `json:"status"`
+	ExitCode int    `json:"exit_code"`
+
+	// Failure is the kind of error the run failed with, such as "config"
+	// or "database", and Errors its messages.
+	Failure string   `json:"failure,omitempty"`
+	Errors  []string `json:"errors"`
+
+	// Columns is the number of columns configured, and ColumnsProcessed
+	// the number anonymized, in every target database.
+	Columns          int   `json:"columns"`
+	ColumnsProcessed int   `json:"columns_processed"`
+	Targets          int   `json:"targets,omitempty"`
+	RowsProcessed    int64 `json:"rows_processed"`
+	ValuesAnonymized int64 `json:"values_anonymized"`
+
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// NewSummary returns the summary of a run started at the given time,
+// which has succeeded until an error is recorded.
+func NewSummary(started time.Time) *Summary {
+	return &Summary{
+		Status:    StatusSucceeded,
+		Errors:    []string{},
+		StartedAt: started,
+	}
+}
+
+// Add adds the statistics of a database the run anonymized.
+func (s *Summary) Add(stats *Stats) {
+	s.ColumnsProcessed += len(stats.Columns)
+	s.RowsProcessed += stats.TotalRows
+	s.ValuesAnonymized += stats.TotalAnonymized
+}
+
+// Fail records the error a run ended with, the kind of error, and the
+// status the process exits with.
+func (s *Summary) Fail(err error, failure string, exitCode int,
+	status string) {
+	s.Status = status
+	s.ExitCode = exitCode
+	s.Failure = failure
+	s.Errors = append(s.Errors, err.Error())
+}
+
+// Write writes the summary as JSON, with the run's duration up to the
+// given time.
+func (s *Summary) Write(w io.Writer, finished time.Time) error {
+	s.DurationMS = finished.Sub(s.StartedAt).Milliseconds()
+	return writeJSON(w, s)
+}
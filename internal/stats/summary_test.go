@@ -0,0 +1,78 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSummaryWrite(t *testing.T) {
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := NewSummary(started)
+	s.Columns = 3
+	s.Add(&Stats{Columns: make([]ColumnStats, 2), TotalRows: 100,
+		TotalAnonymized: 150})
+	s.Add(&Stats{Columns: make([]ColumnStats, 1), TotalRows: 10,
+		TotalAnonymized: 10})
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf, started.Add(1500*time.Millisecond)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	want := map[string]any{
+		"status":            StatusSucceeded,
+		"exit_code":         float64(0),
+		"errors":            []any{},
+		"columns":           float64(3),
+		"columns_processed": float64(3),
+		"rows_processed":    float64(110),
+		"values_anonymized": float64(160),
+		"started_at":        "2026-01-02T03:04:05Z",
+		"duration_ms":       float64(1500),
+	}
+	for key, value := range want {
+		if fmt.Sprint(got[key]) != fmt.Sprint(value) {
+			t.Errorf("%s: expected %v, got %v", key, value, got[key])
+		}
+	}
+	if _, ok := got["failure"]; ok {
+		t.Error("expected no failure in a successful summary")
+	}
+}
+
+func TestSummaryFail(t *testing.T) {
+	s := NewSummary(time.Now())
+	s.Fail(fmt.Errorf("config error: no columns"), "config", 2, StatusFailed)
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got Summary
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.Status != StatusFailed || got.ExitCode != 2 ||
+		got.Failure != "config" || len(got.Errors) != 1 ||
+		got.Errors[0] != "config error: no columns" {
+		t.Errorf("unexpected summary %+v", got)
+	}
+}
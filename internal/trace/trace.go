@@ -0,0 +1,231 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package trace records the values generated during a run to a trace
+// file, and replays them in a later run so that it produces identical
+// output from an identical source.
+//
+// A trace file holds a line of JSON with a Header, followed by a line per
+// generated value. Original values are not stored: each is identified by
+// a salted SHA-256 hash.
+package trace
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Version is the trace file format version.
+const Version = 1
+
+// Header is the first line of a trace file.
+type Header struct {
+	Version    int       `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	ConfigHash string    `json:"config_hash"`
+	Salt       string    `json:"salt"`
+}
+
+// Entry records a value generated for a column.
+type Entry struct {
+	Database string `json:"database"`
+	Column   string `json:"column"`
+	Pattern  string `json:"pattern"`
+	CTID     string `json:"ctid,omitempty"`
+	Hash     string `json:"hash"`
+	Output   string `json:"output"`
+}
+
+// key identifies the value generated for an original value.
+type key struct {
+	database, column, pattern, hash string
+}
+
+// hashValue returns the salted hash identifying an original value.
+func hashValue(salt []byte, value string) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(value))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Recorder writes generated values to a trace file.
+type Recorder struct {
+	mu   sync.Mutex
+	f    *os.File
+	w    *bufio.Writer
+	salt []byte
+	err  error
+}
+
+// Create creates a trace file, readable only by its owner, recording a
+// run with the configuration of the given hash.
+func Create(path, configHash string) (*Recorder, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate trace salt: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace file: %w", err)
+	}
+
+	r := &Recorder{f: f, w: bufio.NewWriter(f), salt: salt}
+	r.write(Header{
+		Version:    Version,
+		CreatedAt:  time.Now().UTC(),
+		ConfigHash: configHash,
+		Salt:       hex.EncodeToString(salt),
+	})
+	if r.err != nil {
+		f.Close()
+		return nil, r.err
+	}
+	return r, nil
+}
+
+// Record writes an entry for a generated value; original is hashed.
+func (r *Recorder) Record(database, column, pattern, ctid, original,
+	output string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.write(Entry{
+		Database: database,
+		Column:   column,
+		Pattern:  pattern,
+		CTID:     ctid,
+		Hash:     hashValue(r.salt, original),
+		Output:   output,
+	})
+}
+
+// write writes a line of JSON, keeping the first error.
+func (r *Recorder) write(v any) {
+	if r.err != nil {
+		return
+	}
+	line, err := json.Marshal(v)
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	if err != nil {
+		r.err = fmt.Errorf("failed to write trace file: %w", err)
+	}
+}
+
+// Err returns the first error writing the trace file.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close flushes and closes the trace file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.w.Flush(); err != nil && r.err == nil {
+		r.err = fmt.Errorf("failed to write trace file: %w", err)
+	}
+	if err := r.f.Close(); err != nil && r.err == nil {
+		r.err = fmt.Errorf("failed to write trace file: %w", err)
+	}
+	return r.err
+}
+
+// Replay looks up the values recorded in a trace file.
+type Replay struct {
+	mu      sync.Mutex
+	header  Header
+	salt    []byte
+	entries map[key]string
+	misses  int64
+}
+
+// Open reads a trace file for replay.
+func Open(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	r := &Replay{entries: make(map[key]string)}
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read trace file: %w", err)
+		}
+		return nil, fmt.Errorf("trace file %s is empty", path)
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &r.header); err != nil {
+		return nil, fmt.Errorf("invalid trace file header: %w", err)
+	}
+	if r.header.Version != Version {
+		return nil, fmt.Errorf("unsupported trace file version %d",
+			r.header.Version)
+	}
+	if r.salt, err = hex.DecodeString(r.header.Salt); err != nil {
+		return nil, fmt.Errorf("invalid trace file salt: %w", err)
+	}
+
+	for line := 2; scanner.Scan(); line++ {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("invalid trace file entry on line %d: %w",
+				line, err)
+		}
+		r.entries[key{e.Database, e.Column, e.Pattern, e.Hash}] = e.Output
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace file: %w", err)
+	}
+
+	return r, nil
+}
+
+// ConfigHash returns the hash of the configuration of the recorded run.
+func (r *Replay) ConfigHash() string {
+	return r.header.ConfigHash
+}
+
+// Lookup returns the value recorded for an original value, counting
+// values that were not recorded.
+func (r *Replay) Lookup(database, column, pattern, original string) (string,
+	bool) {
+	output, ok := r.entries[key{database, column, pattern,
+		hashValue(r.salt, original)}]
+	if !ok {
+		r.mu.Lock()
+		r.misses++
+		r.mu.Unlock()
+	}
+	return output, ok
+}
+
+// Misses returns the number of lookups of values that were not recorded.
+func (r *Replay) Misses() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.misses
+}
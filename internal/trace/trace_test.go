@@ -0,0 +1,94 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package trace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRecordReplay tests replaying the values recorded in a trace file
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.trace")
+
+	rec, err := Create(path, "abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rec.Record("app", "public.users.email", "EMAIL", "(0,1)",
+		"john@example.com", "kate@example.net")
+	rec.Record("app", "public.users.name", "PERSON_NAME", "(0,1)",
+		"John Smith", "Kate Jones")
+	if err := rec.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "john@example.com") {
+		t.Error("trace file contains an original value")
+	}
+
+	replay, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replay.ConfigHash() != "abc123" {
+		t.Errorf("unexpected config hash %q", replay.ConfigHash())
+	}
+
+	if got, ok := replay.Lookup("app", "public.users.email", "EMAIL",
+		"john@example.com"); !ok || got != "kate@example.net" {
+		t.Errorf("Lookup() = %q, %v", got, ok)
+	}
+
+	// The same value in another column, pattern or database is not
+	// recorded
+	for _, k := range [][3]string{
+		{"app", "public.users.name", "EMAIL"},
+		{"app", "public.users.email", "PERSON_NAME"},
+		{"other", "public.users.email", "EMAIL"},
+	} {
+		if _, ok := replay.Lookup(k[0], k[1], k[2],
+			"john@example.com"); ok {
+			t.Errorf("unexpected match for %v", k)
+		}
+	}
+	if replay.Misses() != 3 {
+		t.Errorf("expected 3 misses, got %d", replay.Misses())
+	}
+}
+
+// TestOpenInvalid tests reading invalid trace files
+func TestOpenInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"empty":   "",
+		"version": `{"version":99,"salt":""}` + "\n",
+		"entry":   `{"version":1,"salt":"00"}` + "\nnot json\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		if _, err := Open(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := Open(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
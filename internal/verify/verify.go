@@ -0,0 +1,293 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package verify checks anonymized columns for values that remain from
+// before anonymization, by comparing them with the fingerprints of the
+// original values recorded during a run, and with regular expressions
+// matching personal data.
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/composite"
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/database"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/fingerprint"
+	"github.com/pgedge/pgedge-anonymizer/internal/jsonpath"
+	"github.com/pgedge/pgedge-anonymizer/internal/xmlpath"
+)
+
+// Report formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configures a verification.
+type Options struct {
+	// Fingerprints are those of the original values; nil skips the check.
+	Fingerprints *fingerprint.Set
+
+	// Sample limits the number of rows checked in each column; 0 checks
+	// every row.
+	Sample int64
+
+	// MaxMatchRate is the fraction of values checked in a column that
+	// may match an original value.
+	MaxMatchRate float64
+
+	// PIIPatterns are regular expressions no value may match.
+	PIIPatterns []config.PIIPattern
+}
+
+// ColumnResult is the outcome of checking one column.
+type ColumnResult struct {
+	Column             string           `json:"column"`
+	RowsChecked        int64            `json:"rows_checked"`
+	ValuesChecked      int64            `json:"values_checked"`
+	FingerprintMatches int64            `json:"fingerprint_matches"`
+	PIIMatches         map[string]int64 `json:"pii_matches,omitempty"`
+	Passed             bool             `json:"passed"`
+}
+
+// Report is the outcome of checking all columns of a database.
+type Report struct {
+	Database string         `json:"database"`
+	Columns  []ColumnResult `json:"columns"`
+	Passed   bool           `json:"passed"`
+}
+
+// Verifier checks the values of columns.
+type Verifier struct {
+	opts     Options
+	patterns []*regexp.Regexp
+	json     *jsonpath.Processor
+	xml      *xmlpath.Processor
+}
+
+// New returns a verifier with the given options.
+func New(opts Options) (*Verifier, error) {
+	v := &Verifier{
+		opts: opts,
+		json: jsonpath.NewProcessor(true),
+		xml:  xmlpath.NewProcessor(true),
+	}
+	for _, p := range opts.PIIPatterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PII pattern %s: %w", p.Name, err)
+		}
+		v.patterns = append(v.patterns, re)
+	}
+	return v, nil
+}
+
+// Run checks each configured column of the database.
+func (v *Verifier) Run(ctx context.Context, database string,
+	validator *database.SchemaValidator,
+	columns []config.ColumnConfig) (*Report, error) {
+
+	report := &Report{Database: database, Columns: []ColumnResult{},
+		Passed: true}
+
+	for _, colConfig := range columns {
+		col, err := errors.ParseColumnRef(colConfig.Column)
+		if err != nil {
+			return nil, err
+		}
+
+		result := v.newResult(colConfig.Column)
+		err = validator.ScanValues(ctx, col, v.opts.Sample,
+			func(value string) error {
+				v.check(&result, colConfig, value)
+				return nil
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		v.finish(&result)
+		report.Columns = append(report.Columns, result)
+		report.Passed = report.Passed && result.Passed
+	}
+
+	return report, nil
+}
+
+// newResult returns an empty result for a column.
+func (v *Verifier) newResult(column string) ColumnResult {
+	result := ColumnResult{Column: column}
+	if len(v.patterns) > 0 {
+		result.PIIMatches = make(map[string]int64)
+	}
+	return result
+}
+
+// check checks the value of a column in one row: each anonymized value
+// it holds against the fingerprints, and the whole value against the PII
+// patterns.
+func (v *Verifier) check(result *ColumnResult, colConfig config.ColumnConfig,
+	raw string) {
+
+	result.RowsChecked++
+
+	for _, value := range v.values(colConfig, raw) {
+		result.ValuesChecked++
+		if v.opts.Fingerprints != nil && v.opts.Fingerprints.Contains(value) {
+			result.FingerprintMatches++
+		}
+	}
+
+	for i, re := range v.patterns {
+		if re.MatchString(raw) {
+			result.PIIMatches[v.opts.PIIPatterns[i].Name]++
+		}
+	}
+}
+
+// finish decides whether a column passed.
+func (v *Verifier) finish(result *ColumnResult) {
+	result.Passed = true
+	if result.FingerprintMatches > 0 && float64(result.FingerprintMatches) >
+		v.opts.MaxMatchRate*float64(result.ValuesChecked) {
+		result.Passed = false
+	}
+	for _, n := range result.PIIMatches {
+		if n > 0 {
+			result.Passed = false
+		}
+	}
+}
+
+// values returns the anonymized values held in a column value: the values
+// at the configured paths or fields of documents and composite values,
+// or the value itself. Values that cannot be parsed hold none.
+func (v *Verifier) values(colConfig config.ColumnConfig, raw string) []string {
+	switch {
+	case colConfig.IsJSONColumn():
+		paths := make([]string, len(colConfig.JSONPaths))
+		for i, jp := range colConfig.JSONPaths {
+			paths[i] = jp.Path
+		}
+		matches, err := v.json.ExtractAndCollect([]byte(raw), paths)
+		if err != nil {
+			return nil
+		}
+		var values []string
+		for _, path := range paths {
+			for _, m := range matches[path] {
+				values = append(values, m.Value)
+			}
+		}
+		return values
+
+	case colConfig.IsXMLColumn():
+		paths := make([]string, len(colConfig.XMLPaths))
+		for i, xp := range colConfig.XMLPaths {
+			paths[i] = xp.Path
+		}
+		var values []string
+		_, _, err := v.xml.Replace(raw, paths, func(_, value string) string {
+			values = append(values, value)
+			return value
+		})
+		if err != nil {
+			return nil
+		}
+		return values
+
+	case colConfig.IsCompositeColumn():
+		fields, err := composite.Parse(raw)
+		if err != nil {
+			return nil
+		}
+		var values []string
+		for _, f := range fields {
+			if f != nil && *f != "" {
+				values = append(values, *f)
+			}
+		}
+		return values
+
+	default:
+		if raw == "" {
+			return nil
+		}
+		return []string{raw}
+	}
+}
+
+// Write writes reports in the given format.
+func Write(w io.Writer, reports []*Report, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if len(reports) == 1 {
+			return enc.Encode(reports[0])
+		}
+		return enc.Encode(struct {
+			Targets []*Report `json:"targets"`
+		}{reports})
+	case FormatText, "":
+		for _, r := range reports {
+			writeText(w, r)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid report format %q: must be text or json",
+			format)
+	}
+}
+
+// writeText writes a report as a table.
+func writeText(w io.Writer, r *Report) {
+	fmt.Fprintf(w, "\nVerification of %s\n", r.Database)
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	fmt.Fprintf(w, "%-40s %10s %10s  %s\n", "Column", "Values", "Matches",
+		"Result")
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+
+	for _, c := range r.Columns {
+		status := "PASS"
+		if !c.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%-40s %10d %10d  %s\n", c.Column, c.ValuesChecked,
+			c.FingerprintMatches, status)
+
+		names := make([]string, 0, len(c.PIIMatches))
+		for name, n := range c.PIIMatches {
+			if n > 0 {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "  %d values match PII pattern %q\n",
+				c.PIIMatches[name], name)
+		}
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	if r.Passed {
+		fmt.Fprintln(w, "Result: PASS")
+	} else {
+		fmt.Fprintln(w, "Result: FAIL")
+	}
+}
@@ -0,0 +1,173 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package verify
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/fingerprint"
+)
+
+// testFingerprints returns a set holding the fingerprints of the values.
+func testFingerprints(t *testing.T, values ...string) *fingerprint.Set {
+	path := filepath.Join(t.TempDir(), "run.fingerprints")
+	w, err := fingerprint.Create(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, v := range values {
+		w.Add(v)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s, err := fingerprint.Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return s
+}
+
+// checkColumn checks the values of a column and returns the result.
+func checkColumn(v *Verifier, colConfig config.ColumnConfig,
+	values ...string) ColumnResult {
+	result := v.newResult(colConfig.Column)
+	for _, value := range values {
+		v.check(&result, colConfig, value)
+	}
+	v.finish(&result)
+	return result
+}
+
+// TestCheckFingerprints tests finding original values in columns
+func TestCheckFingerprints(t *testing.T) {
+	v, err := New(Options{Fingerprints: testFingerprints(t,
+		"john@example.com", "555-1234")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	simple := config.ColumnConfig{Column: "public.users.email",
+		Pattern: "EMAIL"}
+	if r := checkColumn(v, simple, "kate@example.net",
+		"liz@example.org"); !r.Passed || r.ValuesChecked != 2 {
+		t.Errorf("expected pass: %+v", r)
+	}
+	if r := checkColumn(v, simple, "kate@example.net",
+		"john@example.com"); r.Passed || r.FingerprintMatches != 1 {
+		t.Errorf("expected failure: %+v", r)
+	}
+
+	doc := config.ColumnConfig{Column: "public.users.profile",
+		JSONPaths: []config.JSONPathConfig{
+			{Path: "$.phone", Pattern: "US_PHONE"}}}
+	if r := checkColumn(v, doc, `{"phone": "555-1234"}`,
+		`{"phone": "555-9876"}`); r.Passed || r.FingerprintMatches != 1 ||
+		r.RowsChecked != 2 {
+		t.Errorf("expected JSON failure: %+v", r)
+	}
+
+	xml := config.ColumnConfig{Column: "public.users.card",
+		XMLPaths: []config.XMLPathConfig{
+			{Path: "//email", Pattern: "EMAIL"}}}
+	if r := checkColumn(v, xml,
+		"<card><email>john@example.com</email></card>"); r.Passed {
+		t.Errorf("expected XML failure: %+v", r)
+	}
+
+	comp := config.ColumnConfig{Column: "public.users.contact",
+		Fields: map[string]string{"email": "EMAIL"}}
+	if r := checkColumn(v, comp, "(john@example.com,)"); r.Passed {
+		t.Errorf("expected composite failure: %+v", r)
+	}
+}
+
+// TestMaxMatchRate tests tolerating some matches
+func TestMaxMatchRate(t *testing.T) {
+	v, err := New(Options{Fingerprints: testFingerprints(t, "1980-01-01"),
+		MaxMatchRate: 0.25})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := config.ColumnConfig{Column: "public.users.dob", Pattern: "DOB"}
+	if r := checkColumn(v, col, "1980-01-01", "1975-03-02", "1990-06-07",
+		"2001-12-31"); !r.Passed {
+		t.Errorf("expected pass at the maximum rate: %+v", r)
+	}
+	if r := checkColumn(v, col, "1980-01-01", "1980-01-01", "1990-06-07",
+		"2001-12-31"); r.Passed {
+		t.Errorf("expected failure above the maximum rate: %+v", r)
+	}
+}
+
+// TestCheckPIIPatterns tests finding values matching PII patterns
+func TestCheckPIIPatterns(t *testing.T) {
+	v, err := New(Options{PIIPatterns: []config.PIIPattern{
+		{Name: "staff email", Regex: `@acme\.com\b`}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	col := config.ColumnConfig{Column: "public.users.notes",
+		Pattern: "LOREMIPSUM"}
+	r := checkColumn(v, col, "contact bob@acme.com", "nothing here")
+	if r.Passed || r.PIIMatches["staff email"] != 1 {
+		t.Errorf("expected failure: %+v", r)
+	}
+
+	if _, err := New(Options{PIIPatterns: []config.PIIPattern{
+		{Name: "bad", Regex: "("}}}); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
+
+// TestWrite tests writing reports
+func TestWrite(t *testing.T) {
+	report := &Report{Database: "app", Passed: false, Columns: []ColumnResult{
+		{Column: "public.users.email", ValuesChecked: 10, Passed: true},
+		{Column: "public.users.notes", ValuesChecked: 5, Passed: false,
+			PIIMatches: map[string]int64{"staff email": 2}},
+	}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []*Report{report}, FormatText); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"public.users.email", "PASS", "FAIL",
+		`2 values match PII pattern "staff email"`, "Result: FAIL"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text report missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := Write(&buf, []*Report{report}, FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Passed || len(decoded.Columns) != 2 {
+		t.Errorf("unexpected JSON report: %+v", decoded)
+	}
+
+	if err := Write(&buf, nil, "csv"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
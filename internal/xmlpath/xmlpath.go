@@ -0,0 +1,179 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package xmlpath provides XPath-based extraction and replacement operations
+// for anonymizing values within XML columns.
+package xmlpath
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+)
+
+// Processor handles XPath operations for anonymization.
+type Processor struct {
+	quiet bool // suppress warnings
+}
+
+// NewProcessor creates a new XPath processor.
+func NewProcessor(quiet bool) *Processor {
+	return &Processor{quiet: quiet}
+}
+
+// Validate checks that an XPath expression compiles.
+func Validate(pathExpr string) error {
+	if _, err := xpath.Compile(pathExpr); err != nil {
+		return fmt.Errorf("invalid XPath %q: %w", pathExpr, err)
+	}
+	return nil
+}
+
+// Replace rewrites an XML document, replacing each value selected by one
+// of the XPath expressions with the result of the replace callback, which
+// receives the matching expression and the original value. Expressions may
+// select attributes (//customer/@email), text nodes (//email/text()), or
+// elements whose content is text only (//customer/email). Elements with
+// child elements are skipped with a warning; empty elements are skipped
+// silently. Returns the modified document and the number of values replaced.
+func (p *Processor) Replace(xmlData string, pathExprs []string,
+	replace func(pathExpr, value string) string) (string, int, error) {
+
+	doc, err := xmlquery.Parse(strings.NewReader(xmlData))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid XML: %w", err)
+	}
+
+	count := 0
+	for _, pathExpr := range pathExprs {
+		expr, err := xpath.Compile(pathExpr)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid XPath %q: %w", pathExpr, err)
+		}
+
+		for _, node := range selectNodes(doc, expr) {
+			if p.replaceNode(node, pathExpr, replace) {
+				count++
+			}
+		}
+	}
+
+	if count == 0 {
+		return xmlData, 0, nil
+	}
+
+	return render(doc, xmlData), count, nil
+}
+
+// selectNodes returns the nodes an expression selects. Selected attributes
+// are detached copies of them, as with xmlquery.QuerySelectorAll, that
+// also keep the attribute's namespace, so that the attribute can be told
+// apart from others of the same local name on its element.
+func selectNodes(doc *xmlquery.Node, expr *xpath.Expr) []*xmlquery.Node {
+	var nodes []*xmlquery.Node
+	it := expr.Select(xmlquery.CreateXPathNavigator(doc))
+	for it.MoveNext() {
+		nav := it.Current().(*xmlquery.NodeNavigator)
+		if nav.NodeType() != xpath.AttributeNode {
+			nodes = append(nodes, nav.Current())
+			continue
+		}
+		text := &xmlquery.Node{Type: xmlquery.TextNode, Data: nav.Value()}
+		nodes = append(nodes, &xmlquery.Node{
+			Parent:       nav.Current(),
+			Type:         xmlquery.AttributeNode,
+			Data:         nav.LocalName(),
+			NamespaceURI: nav.NamespaceURL(),
+			FirstChild:   text,
+			LastChild:    text,
+		})
+	}
+	return nodes
+}
+
+// render serializes a parsed document. When the source has no XML
+// declaration the parser synthesizes one; it is dropped so that the output
+// matches the shape of the input.
+func render(doc *xmlquery.Node, source string) string {
+	skipDecl := !strings.HasPrefix(strings.TrimSpace(source), "<?xml")
+
+	var b strings.Builder
+	for n := doc.FirstChild; n != nil; n = n.NextSibling {
+		if skipDecl && n.Type == xmlquery.DeclarationNode {
+			skipDecl = false
+			continue
+		}
+		b.WriteString(n.OutputXMLWithOptions(
+			xmlquery.WithOutputSelf(), xmlquery.WithPreserveSpace()))
+	}
+	return b.String()
+}
+
+// replaceNode replaces the value of a single selected node, returning true
+// if a value was replaced.
+func (p *Processor) replaceNode(node *xmlquery.Node, pathExpr string,
+	replace func(pathExpr, value string) string) bool {
+
+	switch node.Type {
+	case xmlquery.AttributeNode:
+		// Attribute matches are detached copies; update the owning
+		// element's attribute of the same name and namespace
+		owner := node.Parent
+		for i, attr := range owner.Attr {
+			if attr.Name.Local == node.Data &&
+				attr.NamespaceURI == node.NamespaceURI {
+				owner.Attr[i].Value = replace(pathExpr, node.InnerText())
+				return true
+			}
+		}
+		return false
+
+	case xmlquery.TextNode, xmlquery.CharDataNode:
+		node.Data = replace(pathExpr, node.Data)
+		return true
+
+	case xmlquery.ElementNode:
+		if node.FirstChild == nil {
+			// Skip empty elements silently
+			return false
+		}
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != xmlquery.TextNode && c.Type != xmlquery.CharDataNode {
+				p.warn(pathExpr, "an element with child nodes")
+				return false
+			}
+		}
+
+		// Collapse the text into a single node holding the new value
+		text := &xmlquery.Node{
+			Type: xmlquery.TextNode,
+			Data: replace(pathExpr, node.InnerText()),
+		}
+		node.FirstChild = text
+		node.LastChild = text
+		text.Parent = node
+		return true
+
+	default:
+		p.warn(pathExpr, "a non-text node")
+		return false
+	}
+}
+
+// warn logs a warning about a node that cannot be anonymized.
+func (p *Processor) warn(pathExpr, kind string) {
+	if !p.quiet {
+		log.Printf("Warning: XPath %s matched %s, expected text, skipping",
+			pathExpr, kind)
+	}
+}
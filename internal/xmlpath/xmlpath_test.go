@@ -0,0 +1,179 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package xmlpath
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"//customer/email", false},
+		{"/order/customer/@email", false},
+		{"//contact[@type='personal']/phone/text()", false},
+		{"//customer[", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		err := Validate(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.path, err,
+				tt.wantErr)
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		name      string
+		xml       string
+		paths     []string
+		want      string
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:      "element text",
+			xml:       `<customer><name>John</name><email>john@test.com</email></customer>`,
+			paths:     []string{"/customer/email"},
+			want:      `<customer><name>John</name><email>X</email></customer>`,
+			wantCount: 1,
+		},
+		{
+			name:      "multiple matches",
+			xml:       `<list><email>a@test.com</email><email>b@test.com</email></list>`,
+			paths:     []string{"//email"},
+			want:      `<list><email>X</email><email>X</email></list>`,
+			wantCount: 2,
+		},
+		{
+			name:      "attribute",
+			xml:       `<customer id="1" email="john@test.com"></customer>`,
+			paths:     []string{"//customer/@email"},
+			want:      `<customer id="1" email="X"></customer>`,
+			wantCount: 1,
+		},
+		{
+			name:      "namespaced attribute",
+			xml:       `<c xmlns:a="urn:a" xmlns:b="urn:b"><p b:email="e" a:email="e" email="e"/></c>`,
+			paths:     []string{"//p/@*[local-name()='email' and namespace-uri()='urn:a']"},
+			want:      `<c xmlns:a="urn:a" xmlns:b="urn:b"><p b:email="e" a:email="X" email="e"></p></c>`,
+			wantCount: 1,
+		},
+		{
+			name:      "unqualified attribute beside namespaced ones",
+			xml:       `<c xmlns:a="urn:a"><p a:email="e" email="e"/></c>`,
+			paths:     []string{"//p/@email"},
+			want:      `<c xmlns:a="urn:a"><p a:email="e" email="X"></p></c>`,
+			wantCount: 1,
+		},
+		{
+			name:      "text node",
+			xml:       `<customer><email>john@test.com</email></customer>`,
+			paths:     []string{"//email/text()"},
+			want:      `<customer><email>X</email></customer>`,
+			wantCount: 1,
+		},
+		{
+			name:      "predicate",
+			xml:       `<c><phone type="home">1</phone><phone type="work">2</phone></c>`,
+			paths:     []string{"//phone[@type='home']"},
+			want:      `<c><phone type="home">X</phone><phone type="work">2</phone></c>`,
+			wantCount: 1,
+		},
+		{
+			name:      "declaration and whitespace preserved",
+			xml:       "<?xml version=\"1.0\"?>\n<c>\n  <email> a@test.com </email>\n</c>",
+			paths:     []string{"//email"},
+			want:      "<?xml version=\"1.0\"?>\n<c>\n  <email>X</email>\n</c>",
+			wantCount: 1,
+		},
+		{
+			name:      "element with children skipped",
+			xml:       `<customer><email>a@test.com</email></customer>`,
+			paths:     []string{"/customer"},
+			want:      `<customer><email>a@test.com</email></customer>`,
+			wantCount: 0,
+		},
+		{
+			name:      "empty element skipped",
+			xml:       `<customer><email/></customer>`,
+			paths:     []string{"//email"},
+			want:      `<customer><email/></customer>`,
+			wantCount: 0,
+		},
+		{
+			name:      "no match",
+			xml:       `<customer><name>John</name></customer>`,
+			paths:     []string{"//email"},
+			want:      `<customer><name>John</name></customer>`,
+			wantCount: 0,
+		},
+		{
+			name:    "invalid xml",
+			xml:     `<customer><email>`,
+			paths:   []string{"//email"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid xpath",
+			xml:     `<customer/>`,
+			paths:   []string{"//customer["},
+			wantErr: true,
+		},
+	}
+
+	p := NewProcessor(true)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count, err := p.Replace(tt.xml, tt.paths,
+				func(pathExpr, value string) string { return "X" })
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if count != tt.wantCount {
+				t.Errorf("count = %d, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestReplaceEscaping(t *testing.T) {
+	p := NewProcessor(true)
+
+	got, _, err := p.Replace(`<c note="a"><email>x</email></c>`,
+		[]string{"//email", "/c/@note"},
+		func(pathExpr, value string) string { return `<&">` })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `<c note="&lt;&amp;&#34;&gt;"><email>&lt;&amp;&#34;&gt;</email></c>`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
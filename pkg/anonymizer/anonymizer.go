@@ -0,0 +1,287 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package anonymizer is the public Go API for pgEdge Anonymizer. It lets
+// other Go programs anonymize a database, generate individual anonymized
+// values, and plug in custom generators, without running the CLI.
+//
+// A minimal program loads a configuration and runs it:
+//
+//	cfg, err := anonymizer.LoadConfig("pgedge-anonymizer.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	stats, err := anonymizer.Anonymize(ctx, cfg)
+//
+// Custom generators registered with RegisterGenerator can be referenced by
+// name from the pattern of any column, like the built-in patterns.
+package anonymizer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	internal "github.com/pgedge/pgedge-anonymizer/internal/anonymizer"
+	"github.com/pgedge/pgedge-anonymizer/internal/config"
+	"github.com/pgedge/pgedge-anonymizer/internal/errors"
+	"github.com/pgedge/pgedge-anonymizer/internal/generator"
+	"github.com/pgedge/pgedge-anonymizer/internal/pattern"
+	"github.com/pgedge/pgedge-anonymizer/internal/stats"
+)
+
+// Configuration types; see the configuration reference for their fields.
+type (
+	Config         = config.Config
+	DatabaseConfig = config.DatabaseConfig
+	PatternsConfig = config.PatternsConfig
+	ColumnConfig   = config.ColumnConfig
+	JSONPathConfig = config.JSONPathConfig
+	XMLPathConfig  = config.XMLPathConfig
+	TargetConfig   = config.TargetConfig
+	HooksConfig    = config.HooksConfig
+	HookCommand    = config.HookCommand
+	AuditConfig    = config.AuditConfig
+)
+
+// Result types returned by Anonymize and AnonymizeTargets.
+type (
+	TargetResult       = internal.TargetResult
+	Stats              = stats.Stats
+	ColumnStats        = stats.ColumnStats
+	DerivedColumnStats = stats.DerivedColumnStats
+	ColumnRef          = errors.ColumnRef
+)
+
+// Generator is the extension interface for custom generators. Name returns
+// the pattern name used to reference the generator in configuration, and
+// Generate returns the anonymized replacement for an input value.
+// Implementations must be safe for concurrent use.
+type Generator = generator.Generator
+
+// ContextGenerator is implemented by custom generators whose output
+// depends on the column being anonymized, such as its data type or its
+// length. BindContext is called once for each column the generator is
+// used on, and returns the generator for that column.
+type ContextGenerator = generator.ContextGenerator
+
+// ColumnInfo describes the column a ContextGenerator is bound to.
+type ColumnInfo = generator.ColumnInfo
+
+// funcGenerator adapts a function to the Generator interface.
+type funcGenerator struct {
+	name string
+	fn   func(input string) string
+}
+
+func (g funcGenerator) Name() string                 { return g.name }
+func (g funcGenerator) Generate(input string) string { return g.fn(input) }
+
+// NewGenerator returns a Generator with the given pattern name that calls
+// fn to produce each anonymized value.
+func NewGenerator(name string, fn func(input string) string) Generator {
+	return funcGenerator{name: name, fn: fn}
+}
+
+// Options configures a call to Anonymize.
+type Options struct {
+	// Quiet suppresses progress output on stdout.
+	Quiet bool
+
+	// CacheSize is the number of value mappings kept in memory before
+	// spilling to disk; zero uses the default.
+	CacheSize int
+
+	// Hooks are called at each hook point, after any commands configured
+	// in cfg.Hooks.
+	Hooks map[HookPoint][]Hook
+}
+
+// Hook types; a Hook is called before and after each column or batch of
+// rows, and returning an error stops the run and rolls it back.
+type (
+	Hook      = internal.Hook
+	HookPoint = internal.HookPoint
+	HookEvent = internal.HookEvent
+)
+
+// Hook points.
+const (
+	HookBeforeColumn = internal.HookBeforeColumn
+	HookBeforeBatch  = internal.HookBeforeBatch
+	HookAfterBatch   = internal.HookAfterBatch
+	HookAfterColumn  = internal.HookAfterColumn
+)
+
+var (
+	customMu   sync.RWMutex
+	custom     = make(map[string]Generator)
+	builtins   *generator.Manager
+	builtinsMu sync.Mutex
+)
+
+// RegisterGenerator makes a custom generator available by name to
+// Anonymize and Generate. A custom generator takes precedence over a
+// built-in or pattern file pattern with the same name. It returns an error
+// if the name is empty or another custom generator already uses it.
+func RegisterGenerator(g Generator) error {
+	name := g.Name()
+	if name == "" {
+		return fmt.Errorf("generator name is required")
+	}
+
+	customMu.Lock()
+	defer customMu.Unlock()
+
+	if _, exists := custom[name]; exists {
+		return fmt.Errorf("generator %q is already registered", name)
+	}
+	custom[name] = g
+	return nil
+}
+
+// customGenerators returns the registered custom generators.
+func customGenerators() []Generator {
+	customMu.RLock()
+	defer customMu.RUnlock()
+
+	gens := make([]Generator, 0, len(custom))
+	for _, g := range custom {
+		gens = append(gens, g)
+	}
+	return gens
+}
+
+// Generate returns an anonymized value for input using the named pattern,
+// which may be a built-in pattern or a registered custom generator.
+// Unlike Anonymize, no dictionary is kept, so the same input can produce
+// different values on different calls.
+func Generate(patternName, input string) (string, error) {
+	customMu.RLock()
+	g, ok := custom[patternName]
+	customMu.RUnlock()
+	if ok {
+		return g.Generate(input), nil
+	}
+
+	builtinsMu.Lock()
+	if builtins == nil {
+		builtins = generator.NewManager()
+	}
+	mgr := builtins
+	builtinsMu.Unlock()
+
+	g, ok = mgr.Get(patternName)
+	if !ok {
+		return "", fmt.Errorf("unknown pattern %q", patternName)
+	}
+	return g.Generate(input), nil
+}
+
+// LoadConfig loads and validates a configuration file.
+func LoadConfig(path string) (*Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Anonymize anonymizes the columns described by cfg in a single
+// transaction, exactly as the run command does, and returns statistics
+// about the values changed. Patterns are loaded from the files named in
+// cfg.Patterns, and registered custom generators are available to every
+// column. Configurations listing targets must use AnonymizeTargets.
+func Anonymize(ctx context.Context, cfg *Config, opts ...Options) (*Stats,
+	error) {
+
+	if cfg.HasTargets() {
+		return nil, fmt.Errorf("configuration lists targets; use " +
+			"AnonymizeTargets")
+	}
+
+	anon, err := newAnonymizer(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer anon.Close()
+
+	return anon.Run(ctx)
+}
+
+// AnonymizeTargets anonymizes each database listed in cfg.Targets in
+// turn, as the run command does, and returns statistics for each. If a
+// target fails, the results of the targets already anonymized are
+// returned with the error.
+func AnonymizeTargets(ctx context.Context, cfg *Config,
+	opts ...Options) ([]TargetResult, error) {
+
+	anon, err := newAnonymizer(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer anon.Close()
+
+	return anon.RunTargets(ctx)
+}
+
+// newAnonymizer validates cfg and creates an anonymizer with its patterns
+// and the registered custom generators and hooks.
+func newAnonymizer(cfg *Config, opts []Options) (*internal.Anonymizer,
+	error) {
+
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	registry, err := pattern.LoadPatterns(
+		config.FindDefaultPatternsFile(cfg.Patterns.DefaultPath),
+		cfg.Patterns.UserPath,
+		cfg.Patterns.DisableDefaults,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generators registered in code take precedence over plugin generators
+	generators, err := generator.LoadPlugins(cfg.Patterns.Plugins)
+	if err != nil {
+		return nil, err
+	}
+	generators = append(generators, customGenerators()...)
+
+	anon, err := internal.New(internal.Options{
+		Config:     cfg,
+		Patterns:   registry,
+		Quiet:      o.Quiet,
+		CacheSize:  o.CacheSize,
+		Generators: generators,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, point := range []HookPoint{HookBeforeColumn, HookBeforeBatch,
+		HookAfterBatch, HookAfterColumn} {
+		for _, hook := range o.Hooks[point] {
+			anon.AddHook(point, hook)
+		}
+	}
+
+	return anon, nil
+}
@@ -0,0 +1,72 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Anonymizer
+ *
+ * Copyright (c) 2025 - 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package anonymizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("built-in pattern", func(t *testing.T) {
+		got, err := Generate("EMAIL", "john@example.com")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(got, "@") || got == "john@example.com" {
+			t.Errorf("unexpected anonymized email: %q", got)
+		}
+	})
+
+	t.Run("unknown pattern", func(t *testing.T) {
+		if _, err := Generate("NO_SUCH_PATTERN", "x"); err == nil {
+			t.Error("expected error for unknown pattern")
+		}
+	})
+}
+
+func TestRegisterGenerator(t *testing.T) {
+	gen := NewGenerator("TEST_EMPLOYEE_ID", func(input string) string {
+		return "EMP-" + strings.Repeat("0", len(input))
+	})
+
+	if err := RegisterGenerator(gen); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := Generate("TEST_EMPLOYEE_ID", "12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "EMP-00000" {
+		t.Errorf("Generate() = %q, want EMP-00000", got)
+	}
+
+	// A second registration under the same name is rejected
+	if err := RegisterGenerator(gen); err == nil {
+		t.Error("expected error for duplicate registration")
+	}
+
+	// The generator is passed on to the anonymizer
+	found := false
+	for _, g := range customGenerators() {
+		if g.Name() == "TEST_EMPLOYEE_ID" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected registered generator in custom generators")
+	}
+
+	if err := RegisterGenerator(NewGenerator("", strings.ToUpper)); err == nil {
+		t.Error("expected error for empty generator name")
+	}
+}